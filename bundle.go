@@ -0,0 +1,404 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/analysis"
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/astcache"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/elitwilson/beeflang/internal/parser"
+)
+
+// A bundled executable is a copy of the interpreter with a zip archive of the
+// program appended, followed by a trailer: the archive's size as a big-endian
+// uint64, then bundleMagic. At startup the interpreter looks for the trailer
+// at the end of its own file and, if it is there, runs the packed program.
+//
+// The archive holds bundleManifest, the program's files under program/ and
+// every module they wrangle under modules/, one file per module name - the
+// search path is resolved when bundling, so at run time it is just modules/.
+const (
+	bundleMagic    = "BEEFBNDL"
+	bundleTrailer  = 8 + len(bundleMagic)
+	bundleManifest = "bundle.json"
+	bundleProgram  = "program"
+	bundleModules  = "modules"
+)
+
+// bundleOptions are the run flags baked into a bundle, since a bundled
+// program passes its whole command line to the script.
+type bundleOptions struct {
+	Files         []string `json:"files"` // program files in run order, under program/
+	Script        bool     `json:"script,omitempty"`
+	Strict        bool     `json:"strict,omitempty"`
+	AllowFS       bool     `json:"allow_fs,omitempty"`
+	AllowNet      bool     `json:"allow_net,omitempty"`
+	AllowProcess  bool     `json:"allow_process,omitempty"`
+	AllowEnv      bool     `json:"allow_env,omitempty"`
+	AllowEval     bool     `json:"allow_eval,omitempty"`
+	AllowParallel bool     `json:"allow_parallel,omitempty"`
+	Entry         string   `json:"entry,omitempty"`
+
+	// Defines are the --define conditions. platform isn't kept unless it was
+	// defined, so it is the OS the bundle runs on.
+	Defines map[string]string `json:"defines,omitempty"`
+}
+
+// reportAnalysis prints static analysis warnings before a program runs. A
+// bundled program's warnings were shown when it was bundled, so its users
+// don't see them.
+var reportAnalysis = true
+
+// bundleFiles implements `beeflang bundle [flags] -o <output> <file.beef|dir>...`.
+// It writes a standalone executable that runs the program, with the modules it
+// wrangles packed in, on machines without Beeflang installed. Returns the
+// process exit code.
+func bundleFiles(args []string) int {
+	fs := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	output := fs.String("o", "", "file to write the executable to")
+	var modulePaths pathList
+	fs.Var(&modulePaths, "path", "directory to search for wrangled modules (repeatable; searched before BEEF_PATH)")
+	script := fs.Bool("script", false, "run top-level statements in order without requiring a ChurchOfBeef() entry point")
+	strict := fs.Bool("strict", false, "run the program in strict mode")
+	allowed := capabilityFlags(fs)
+	entry := fs.String("entry", evaluator.DefaultEntryPoint, "name of the function the program starts in")
+	defined := defines{}
+	fs.Var(defined, "define", "set a condition for #when directives, in the bundle and when it runs (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if *output == "" || fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: go run . bundle [--path dir] [--script] [--strict] [--define name[=value]] [--allow-fs] [--allow-net] [--allow-process] [--allow-env] [--allow-eval] [--allow-parallel] [--entry name] -o <output> <file.beef|dir>...")
+		return 1
+	}
+
+	files, rest, err := programFiles(fs.Args())
+	if err != nil {
+		reportError(fs.Arg(0), diagnostics.CodeUnreadableFile, err.Error())
+		return 1
+	}
+	if len(rest) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s is not a %s file or directory\n", rest[0], evaluator.ModuleExtension)
+		return 1
+	}
+	evaluator.Default.SearchPath = mustSearchPath(modulePaths, filepath.Dir(files[0]))
+	evaluator.Default.Strict = *strict
+	for name, value := range defined {
+		evaluator.Default.Conditions[name] = value
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	options := bundleOptions{Script: *script, Strict: *strict, AllowFS: allowed.Filesystem,
+		AllowNet: allowed.Network, AllowProcess: allowed.Process, AllowEnv: allowed.Env, AllowEval: allowed.Eval,
+		AllowParallel: allowed.Parallel}
+	if len(defined) > 0 {
+		options.Defines = defined
+	}
+	if *entry != evaluator.DefaultEntryPoint {
+		options.Entry = *entry
+	}
+
+	// The program's own files, then every module they reach
+	var pending []*ast.Program
+	for _, file := range files {
+		name := filepath.Base(file)
+		for _, existing := range options.Files {
+			if existing == name {
+				fmt.Fprintf(os.Stderr, "Error: two program files are named %s\n", name)
+				return 1
+			}
+		}
+		program, ok := addBundleFile(zw, file, path.Join(bundleProgram, name))
+		if !ok {
+			return 1
+		}
+		options.Files = append(options.Files, name)
+		pending = append(pending, program)
+	}
+
+	bundled := map[string]bool{}
+	modules := 0
+	for len(pending) > 0 {
+		program := pending[0]
+		pending = pending[1:]
+		for _, name := range wrangledModules(program) {
+			if bundled[name] {
+				continue
+			}
+			bundled[name] = true
+			file, searched := evaluator.Default.ModulePath(name)
+			if searched == nil {
+				continue // built in
+			}
+			if file == "" {
+				fmt.Fprintf(os.Stderr, "Error: module not found: %s, searched: %s\n", name, strings.Join(searched, ", "))
+				return 1
+			}
+			module, ok := addBundleFile(zw, file, path.Join(bundleModules, name+evaluator.ModuleExtension))
+			if !ok {
+				return 1
+			}
+			pending = append(pending, module)
+			modules++
+		}
+		// Data files are found in the archive's module directory as they were
+		// on the search path
+		for _, name := range wrangledDataFiles(program) {
+			if bundled[name] {
+				continue
+			}
+			bundled[name] = true
+			file, searched := evaluator.Default.DataFilePath(name)
+			if file == "" {
+				fmt.Fprintf(os.Stderr, "Error: data file not found: %s, searched: %s\n", name, strings.Join(searched, ", "))
+				return 1
+			}
+			if !addBundleData(zw, file, path.Join(bundleModules, name)) {
+				return 1
+			}
+			modules++
+		}
+	}
+
+	manifest, err := zw.Create(bundleManifest)
+	if err == nil {
+		err = json.NewEncoder(manifest).Encode(options)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = writeBundle(*output, archive.Bytes())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Bundled %d file(s) and %d module(s) into %s\n", len(files), modules, *output)
+	return 0
+}
+
+// addBundleFile parses a source file, reporting its syntax errors and
+// warnings, and adds it to the archive under name. It returns the parsed
+// program so its wrangles can be followed.
+func addBundleFile(zw *zip.Writer, file string, name string) (*ast.Program, bool) {
+	source, err := os.ReadFile(file)
+	if err != nil {
+		reportError(file, diagnostics.CodeUnreadableFile, fmt.Sprintf("reading file: %v", err))
+		return nil, false
+	}
+
+	p := parser.New(newLexer(string(source)))
+	program := p.ParseProgram()
+	if len(p.ParseErrors()) > 0 {
+		reportParseErrors(file, p.ParseErrors())
+		return nil, false
+	}
+	if warnings := analysis.Analyze(program); len(warnings) > 0 {
+		reportWarnings(file, warnings)
+		for _, w := range warnings {
+			if strictError(w) {
+				return nil, false
+			}
+		}
+	}
+
+	w, err := zw.Create(name)
+	if err == nil {
+		_, err = w.Write(source)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, false
+	}
+	return program, true
+}
+
+// addBundleData adds a data file to the archive under name, as it is.
+func addBundleData(zw *zip.Writer, file string, name string) bool {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		reportError(file, diagnostics.CodeUnreadableFile, fmt.Sprintf("reading file: %v", err))
+		return false
+	}
+	w, err := zw.Create(name)
+	if err == nil {
+		_, err = w.Write(data)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// wrangledModules lists the modules a program wrangles anywhere, in order of
+// first appearance.
+func wrangledModules(program *ast.Program) []string {
+	var names []string
+	seen := map[string]bool{}
+	ast.Inspect(program, func(n ast.Node) bool {
+		if w, ok := n.(*ast.WrangleStatement); ok && w.ModuleName != nil && !seen[w.ModuleName.Value] {
+			seen[w.ModuleName.Value] = true
+			names = append(names, w.ModuleName.Value)
+		}
+		return true
+	})
+	return names
+}
+
+// wrangledDataFiles lists the data files a program wrangles anywhere, in
+// order of first appearance.
+func wrangledDataFiles(program *ast.Program) []string {
+	var names []string
+	seen := map[string]bool{}
+	ast.Inspect(program, func(n ast.Node) bool {
+		if w, ok := n.(*ast.WrangleStatement); ok && w.DataFile != nil && !seen[w.DataFile.Value] {
+			seen[w.DataFile.Value] = true
+			names = append(names, w.DataFile.Value)
+		}
+		return true
+	})
+	return names
+}
+
+// writeBundle writes a copy of the running interpreter with archive and the
+// bundle trailer appended, as an executable file.
+func writeBundle(output string, archive []byte) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding the interpreter executable: %w", err)
+	}
+	interpreter, err := os.ReadFile(self)
+	if err != nil {
+		return fmt.Errorf("reading the interpreter executable: %w", err)
+	}
+
+	out, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o755)
+	if err != nil {
+		return err
+	}
+	trailer := binary.BigEndian.AppendUint64(nil, uint64(len(archive)))
+	trailer = append(trailer, bundleMagic...)
+	for _, part := range [][]byte{interpreter, archive, trailer} {
+		if _, err := out.Write(part); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// openBundle returns the program packed into the running executable, or nil
+// if this is a plain interpreter.
+func openBundle() (*zip.Reader, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, nil
+	}
+	f, err := os.Open(self)
+	if err != nil {
+		return nil, nil
+	}
+	info, err := f.Stat()
+	if err != nil || info.Size() < int64(bundleTrailer) {
+		f.Close()
+		return nil, nil
+	}
+
+	trailer := make([]byte, bundleTrailer)
+	if _, err := f.ReadAt(trailer, info.Size()-int64(bundleTrailer)); err != nil || string(trailer[8:]) != bundleMagic {
+		f.Close()
+		return nil, nil
+	}
+	size := int64(binary.BigEndian.Uint64(trailer[:8]))
+	start := info.Size() - int64(bundleTrailer) - size
+	if size < 0 || start < 0 {
+		f.Close()
+		return nil, fmt.Errorf("corrupt bundle in %s", self)
+	}
+	// The file stays open for as long as the program runs: modules are read
+	// from it when first wrangled
+	archive, err := zip.NewReader(io.NewSectionReader(f, start, size), size)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("corrupt bundle in %s: %w", self, err)
+	}
+	return archive, nil
+}
+
+// runBundle runs the program packed into the executable. Every command line
+// argument belongs to the script. Returns the process exit code.
+func runBundle(archive *zip.Reader) int {
+	var options bundleOptions
+	manifest, err := archive.Open(bundleManifest)
+	if err == nil {
+		err = json.NewDecoder(manifest).Decode(&options)
+		manifest.Close()
+	}
+	if err != nil || len(options.Files) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: corrupt bundle: missing %s\n", bundleManifest)
+		return 1
+	}
+
+	renderer.Color = diagnostics.ShouldColor(os.Stderr)
+	reportAnalysis = false
+	scriptMode = options.Script
+	evaluator.Default.Strict = options.Strict
+	evaluator.Default.Allowed = evaluator.Capabilities{Filesystem: options.AllowFS, Network: options.AllowNet,
+		Process: options.AllowProcess, Env: options.AllowEnv, Eval: options.AllowEval, Parallel: options.AllowParallel}
+	if options.Entry != "" {
+		evaluator.Default.EntryPoint = options.Entry
+	}
+	for name, value := range options.Defines {
+		evaluator.Default.Conditions[name] = value
+	}
+	evaluator.Default.Args = os.Args
+	evaluator.Default.ModuleFS = archive
+	astcache.Dir = astcache.DefaultDir()
+	evaluator.Default.SearchPath = []string{bundleModules}
+
+	// Keep every source in memory for error excerpts, as for -e code
+	for _, f := range archive.File {
+		if !strings.HasSuffix(f.Name, evaluator.ModuleExtension) {
+			continue
+		}
+		source, err := readBundleFile(archive, f.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: corrupt bundle: %v\n", err)
+			return 1
+		}
+		name := strings.TrimPrefix(f.Name, bundleProgram+"/")
+		inlineSources[name] = source
+	}
+
+	sources := make([]namedSource, len(options.Files))
+	for i, name := range options.Files {
+		sources[i] = namedSource{name, strings.NewReader(inlineSources[name])}
+	}
+	return runInterruptible(func() int { return runSources(sources) })
+}
+
+// readBundleFile returns the contents of a file in the bundle archive.
+func readBundleFile(archive *zip.Reader, name string) (string, error) {
+	f, err := archive.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	source, err := io.ReadAll(f)
+	return string(source), err
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/elitwilson/beeflang/internal/typecheck"
+)
+
+// checkSyntax lexes and parses every given file (directories are searched
+// recursively for .beef files) without executing anything. All diagnostics
+// are reported, not just the first. When typeCheck is set, files that parse
+// are also run through the type checker (the `check` subcommand).
+// Returns the process exit code: 0 when every file is clean, 1 otherwise.
+func checkSyntax(paths []string, typeCheck bool) int {
+	files, err := collectSourceFiles(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	failed := 0
+	diags := []diagnostics.Diagnostic{}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			diags = append(diags, diagnostics.Diagnostic{
+				File:     file,
+				Severity: diagnostics.Error,
+				Message:  fmt.Sprintf("reading file: %v", err),
+				Code:     diagnostics.CodeUnreadableFile,
+			})
+			failed++
+			continue
+		}
+
+		p := parser.New(newLexer(string(source)))
+		program := p.ParseProgram()
+		if len(p.ParseErrors()) > 0 {
+			failed++
+			diags = append(diags, fromParseErrors(file, p.ParseErrors())...)
+			continue
+		}
+
+		if typeCheck {
+			if errs := typecheck.Check(program); len(errs) > 0 {
+				failed++
+				diags = append(diags, fromTypeErrors(file, errs)...)
+			}
+		}
+	}
+
+	if diagnosticsFormat == "json" {
+		// Machine-readable output is the diagnostics only - the exit code carries the verdict
+		diagnostics.WriteJSON(os.Stderr, diags)
+		if failed > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	printDiagnostics(diags)
+	if failed > 0 {
+		fmt.Printf("Checked %d file(s): %d with errors\n", len(files), failed)
+		return 1
+	}
+	if typeCheck {
+		fmt.Printf("Checked %d file(s): no errors\n", len(files))
+		return 0
+	}
+	fmt.Printf("Checked %d file(s): no syntax errors\n", len(files))
+	return 0
+}
+
+// collectSourceFiles expands directories into the .beef files they contain.
+// Plain files are kept as given, whatever their extension.
+func collectSourceFiles(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && filepath.Ext(p) == evaluator.ModuleExtension {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -8,7 +8,7 @@ case "$1" in
       echo "Usage: ./dev.sh run <file.beef>"
       exit 1
     fi
-    go run main.go "$2"
+    go run . "$2"
     ;;
   test)
     go test ./... -v
@@ -22,7 +22,9 @@ case "$1" in
     go test "./internal/$2" -v
     ;;
   build)
-    go build -o beeflang main.go
+    VERSION=$(git describe --tags 2>/dev/null || echo dev)
+    COMMIT=$(git rev-parse --short HEAD 2>/dev/null || echo unknown)
+    go build -ldflags "-X main.version=$VERSION -X main.commit=$COMMIT" -o beeflang .
     echo "Built: ./beeflang"
     ;;
   lex)
@@ -30,7 +32,7 @@ case "$1" in
       echo "Usage: ./dev.sh lex <file.beef>"
       exit 1
     fi
-    go run main.go --dump-tokens "$2"
+    go run . --dump-tokens "$2"
     ;;
   clean)
     rm -f beeflang
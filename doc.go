@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/docgen"
+	"github.com/elitwilson/beeflang/internal/parser"
+)
+
+// generateDocs implements `beeflang doc [--format markdown|html] <file.beef|dir>...`.
+// It parses every file (directories are searched recursively) and prints the
+// documentation for their top-level functions to stdout. Nothing is executed.
+// Returns the process exit code.
+func generateDocs(args []string) int {
+	fs := flag.NewFlagSet("doc", flag.ContinueOnError)
+	format := fs.String("format", "markdown", "output format: markdown or html")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: go run . doc [--format markdown|html] <file.beef|dir>...")
+		return 1
+	}
+	if *format != "markdown" && *format != "html" {
+		fmt.Fprintf(os.Stderr, "Error: unknown --format %q (want markdown or html)\n", *format)
+		return 1
+	}
+
+	files, err := collectSourceFiles(fs.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var modules []*docgen.Module
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+			return 1
+		}
+		p := parser.New(newLexer(string(source)))
+		program := p.ParseProgram()
+		if len(p.ParseErrors()) > 0 {
+			reportParseErrors(file, p.ParseErrors())
+			return 1
+		}
+		// Document the module under the name it is wrangled by
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		modules = append(modules, docgen.New(name, program))
+	}
+
+	if *format == "html" {
+		err = docgen.WriteHTML(os.Stdout, modules)
+	} else {
+		err = docgen.WriteMarkdown(os.Stdout, modules)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -34,7 +34,7 @@ for example in "${examples[@]}"; do
   echo "--------------------------------------------------"
 
   # Run the example and capture output (expecting it to fail)
-  if ! go run "$PROJECT_ROOT/main.go" "$SCRIPT_DIR/$file" 2>&1; then
+  if ! go run "$PROJECT_ROOT" "$SCRIPT_DIR/$file" 2>&1; then
     echo ""
   fi
 
@@ -48,6 +48,7 @@ echo ""
 echo "Notice how each error includes:"
 echo "  • Line number"
 echo "  • Column number"
+echo "  • Error code and underlined source line"
 echo "  • Clear error message"
 echo "  • Execution stops immediately"
 echo ""
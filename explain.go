@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+)
+
+// explainCode implements `beeflang explain [code]`. With a code it prints that
+// code's long-form explanation; without one it lists every known code.
+// Returns the process exit code.
+func explainCode(args []string) int {
+	if len(args) == 0 {
+		for _, exp := range diagnostics.AllExplanations() {
+			fmt.Printf("%s  %s\n", exp.Code, exp.Title)
+		}
+		return 0
+	}
+
+	// Accept "be0002" as well as "BE0002"
+	code := strings.ToUpper(args[0])
+	exp, ok := diagnostics.Explain(code)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown error code %q (run 'explain' with no arguments to list codes)\n", args[0])
+		return 1
+	}
+
+	fmt.Printf("%s: %s\n\n%s\n", exp.Code, exp.Title, exp.Description)
+	return 0
+}
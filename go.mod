@@ -2,10 +2,17 @@ module github.com/elitwilson/beeflang
 
 go 1.24.5
 
-require github.com/stretchr/testify v1.11.1
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/peterh/liner v1.2.2
+	github.com/stretchr/testify v1.11.1
+	golang.org/x/sys v0.0.0-20211117180635-dee7805ff2e1
+	golang.org/x/text v0.34.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/mattn/go-runewidth v0.0.3 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/elitwilson/beeflang/internal/highlight"
+)
+
+// highlightFile implements `beeflang highlight [--format ansi|html] [--page] <file.beef>`.
+// It prints the file with syntax coloring: ANSI escapes for a terminal, or an
+// HTML fragment (a whole page with --page) for documentation sites. Nothing is
+// parsed or executed, so files with errors highlight too. Returns the process
+// exit code.
+func highlightFile(args []string) int {
+	fs := flag.NewFlagSet("highlight", flag.ContinueOnError)
+	format := fs.String("format", "ansi", "output format: ansi or html")
+	page := fs.Bool("page", false, "with --format html, write a standalone page with a stylesheet")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: go run . highlight [--format ansi|html] [--page] <file.beef>")
+		return 1
+	}
+	if *format != "ansi" && *format != "html" {
+		fmt.Fprintf(os.Stderr, "Error: unknown --format %q (want ansi or html)\n", *format)
+		return 1
+	}
+
+	file := fs.Arg(0)
+	source, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+		return 1
+	}
+
+	switch {
+	case *format == "html" && *page:
+		err = highlight.WritePage(os.Stdout, filepath.Base(file), string(source))
+	case *format == "html":
+		err = highlight.WriteHTML(os.Stdout, string(source))
+	default:
+		err = highlight.WriteANSI(os.Stdout, string(source))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,275 @@
+// Package analysis finds likely mistakes in a parsed program without running it.
+//
+// Everything it reports is a warning: the program is still valid and will run,
+// but probably doesn't do what its author meant. The checks are:
+//   - variables declared with 'prep' inside a function but never read
+//   - statements after a 'serve' in the same block (they can never run)
+//   - 'if' / 'feast while' conditions built only from literals
+//   - a name declared twice in the same block, which silently replaces the first
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// Warning is a non-fatal problem found by Analyze, with the position of the
+// token it is about. It mirrors parser.ParseError so both can be reported the same way.
+type Warning struct {
+	Line    int
+	Column  int
+	End     token.Position // just past the source text the warning is about
+	Code    string
+	Message string
+}
+
+// String formats the warning like a parse error: "[line 3, col 7] message"
+func (w Warning) String() string {
+	return fmt.Sprintf("[line %d, col %d] %s", w.Line, w.Column, w.Message)
+}
+
+// scope tracks the 'prep' declarations of one function body.
+// Blocks inside a function (if/while bodies) share the function's scope,
+// matching how the evaluator binds variables.
+type scope struct {
+	parent   *scope
+	declared map[string]*ast.Identifier
+	order    []string // declaration order, so warnings come out in source order
+	read     map[string]bool
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{
+		parent:   parent,
+		declared: map[string]*ast.Identifier{},
+		read:     map[string]bool{},
+	}
+}
+
+type analyzer struct {
+	scope    *scope
+	names    map[string]*ast.Identifier // names declared in the block being analyzed
+	warnings []Warning
+}
+
+// Analyze checks a program and returns its warnings in the order they were found.
+func Analyze(program *ast.Program) []Warning {
+	a := &analyzer{scope: newScope(nil), names: map[string]*ast.Identifier{}}
+	for _, stmt := range program.Statements {
+		a.statement(stmt)
+	}
+	// Top-level variables aren't checked: they are module members and may be
+	// read by other files that wrangle this one.
+	return a.warnings
+}
+
+// warn records a warning spanning the given node
+func (a *analyzer) warn(node ast.Node, code string, format string, args ...interface{}) {
+	start := node.Start()
+	a.warnings = append(a.warnings, Warning{
+		Line:    start.Line,
+		Column:  start.Column,
+		End:     node.End(),
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (a *analyzer) statement(stmt ast.Statement) {
+	switch s := stmt.(type) {
+	case *ast.VariableDeclaration:
+		a.expression(s.Value)
+		a.declare(s.Name)
+		if _, exists := a.scope.declared[s.Name.Value]; !exists {
+			a.scope.order = append(a.scope.order, s.Name.Value)
+		}
+		a.scope.declared[s.Name.Value] = s.Name
+
+	case *ast.AssignmentStatement:
+		// Assigning to a variable doesn't count as reading it
+		a.expression(s.Value)
+
+	case *ast.ReturnStatement:
+		a.expression(s.ReturnValue)
+
+	case *ast.YieldStatement:
+		a.expression(s.Value)
+
+	case *ast.AssertStatement:
+		a.expression(s.Condition)
+		a.expression(s.Message)
+
+	case *ast.StampedeStatement:
+		a.expression(s.Call)
+
+	case *ast.DessertStatement:
+		a.expression(s.Call)
+
+	case *ast.ExpressionStatement:
+		a.expression(s.Expression)
+
+	case *ast.SelectStatement:
+		// A received value bound with 'when name = ...' isn't a 'prep', so it is
+		// never reported as unused
+		for _, c := range s.Cases {
+			a.expression(c.Call)
+			a.block(c.Body)
+		}
+		if s.Default != nil {
+			a.block(s.Default)
+		}
+
+	case *ast.ExposeStatement:
+		for _, module := range s.Modules {
+			a.expression(module)
+		}
+
+	case *ast.UsingStatement:
+		// The name is used when the value is closed, so like a select binding
+		// it is never reported as unused
+		a.expression(s.Value)
+		a.block(s.Body)
+
+	case *ast.IfStatement:
+		a.checkCondition(s.Condition, "if")
+		a.expression(s.Condition)
+		a.block(s.Consequence)
+		if s.Alternative != nil {
+			a.block(s.Alternative)
+		}
+
+	case *ast.WhileLoop:
+		// 'feast while true' is the only way to write a loop that exits via 'serve'
+		if b, ok := s.Condition.(*ast.BooleanLiteral); !ok || !b.Value {
+			a.checkCondition(s.Condition, "feast while")
+		}
+		a.expression(s.Condition)
+		a.block(s.Body)
+
+	case *ast.ForEachLoop:
+		// Like a select binding, the loop variable isn't a 'prep' and is never
+		// reported as unused
+		a.expression(s.Iterable)
+		a.block(s.Body)
+
+	case *ast.FunctionDeclaration:
+		for _, d := range s.Decorators {
+			a.expression(d.Expression)
+		}
+		a.declare(s.Name)
+		a.function(s)
+
+	case *ast.BlockStatement:
+		a.block(s)
+	}
+}
+
+// block analyzes a block's statements and flags the first statement after a 'serve'.
+func (a *analyzer) block(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	outer := a.names
+	a.names = map[string]*ast.Identifier{}
+	defer func() { a.names = outer }()
+
+	served := false
+	for _, stmt := range block.Statements {
+		if served {
+			a.warn(stmt, diagnostics.CodeUnreachableCode, "unreachable code after 'serve'")
+			break
+		}
+		a.statement(stmt)
+		if _, ok := stmt.(*ast.ReturnStatement); ok {
+			served = true
+		}
+	}
+}
+
+// declare records a function or variable declared in the current block and
+// warns if the block already declared that name. Declarations in different
+// blocks (the two branches of an 'if', say) don't clash, and neither does a
+// 'prep' in a loop body running again.
+func (a *analyzer) declare(name *ast.Identifier) {
+	if first, ok := a.names[name.Value]; ok {
+		a.warn(name, diagnostics.CodeRedeclared,
+			"'%s' is already declared at line %d, col %d", name.Value, first.Token.Line, first.Token.Column)
+		return
+	}
+	a.names[name.Value] = name
+}
+
+// function analyzes a function body in its own scope, then reports the
+// variables it declared but never read. Names starting with an underscore are
+// exempt, so a deliberately unused variable can be marked as such.
+func (a *analyzer) function(fn *ast.FunctionDeclaration) {
+	a.scope = newScope(a.scope)
+	a.block(fn.Body)
+
+	fnScope := a.scope
+	a.scope = fnScope.parent
+
+	for _, name := range fnScope.order {
+		if fnScope.read[name] || strings.HasPrefix(name, "_") {
+			continue
+		}
+		ident := fnScope.declared[name]
+		a.warn(ident, diagnostics.CodeUnusedVariable,
+			"variable '%s' is declared but never used", name)
+	}
+
+	// Reads of names this function didn't declare belong to an enclosing scope
+	for name := range fnScope.read {
+		if _, ok := fnScope.declared[name]; !ok {
+			a.scope.read[name] = true
+		}
+	}
+}
+
+// expression records every variable an expression reads.
+func (a *analyzer) expression(expr ast.Expression) {
+	if expr == nil {
+		return
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.Identifier:
+			a.scope.read[e.Value] = true
+		case *ast.MemberAccessExpression:
+			// Only the object is a variable read; the member name is looked up in the module
+			a.expression(e.Object)
+			return false
+		case *ast.IfExpression:
+			// The branches are blocks with their own scopes
+			a.statement(e.If)
+			return false
+		}
+		return true
+	})
+}
+
+// checkCondition warns when a condition's value can't change at runtime.
+func (a *analyzer) checkCondition(cond ast.Expression, keyword string) {
+	if cond == nil || !isConstant(cond) {
+		return
+	}
+	a.warn(cond, diagnostics.CodeConstantCondition,
+		"'%s' condition is always the same value", keyword)
+}
+
+// isConstant reports whether an expression is built only from literals and operators.
+func isConstant(expr ast.Expression) bool {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral, *ast.BigIntegerLiteral, *ast.BooleanLiteral, *ast.StringLiteral:
+		return true
+	case *ast.PrefixExpression:
+		return isConstant(e.Right)
+	case *ast.InfixExpression:
+		return isConstant(e.Left) && isConstant(e.Right)
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper function to parse source and analyze it
+func analyze(t *testing.T, input string) []Warning {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	assert.Empty(t, p.Errors(), "parser errors")
+	return Analyze(program)
+}
+
+func TestCleanProgramHasNoWarnings(t *testing.T) {
+	input := `
+wrangle io
+
+praise add(x, y):
+   prep sum = x + y
+   serve sum
+beef
+
+praise ChurchOfBeef():
+   prep counter = 3
+   feast while counter > 0:
+      io.preach(add(counter, 1))
+      counter = counter - 1
+   beef
+beef
+`
+	assert.Empty(t, analyze(t, input))
+}
+
+func TestSelectCasesAreAnalyzed(t *testing.T) {
+	input := `
+praise wait(inbox):
+   select:
+   when msg = inbox.recv():
+      prep copy = msg
+   else:
+      serve 0
+      prep late = 1
+   beef
+beef
+`
+	warnings := analyze(t, input)
+
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, diagnostics.CodeUnreachableCode, warnings[0].Code)
+	assert.Equal(t, diagnostics.CodeUnusedVariable, warnings[1].Code)
+}
+
+func TestUnusedVariable(t *testing.T) {
+	input := `praise ChurchOfBeef():
+   prep unused = 5
+   prep used = 6
+   serve used
+beef`
+	warnings := analyze(t, input)
+
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, diagnostics.CodeUnusedVariable, warnings[0].Code)
+	assert.Equal(t, "variable 'unused' is declared but never used", warnings[0].Message)
+	assert.Equal(t, 2, warnings[0].Line)
+	assert.Equal(t, 9, warnings[0].Column)
+}
+
+func TestAssignmentIsNotARead(t *testing.T) {
+	input := `praise ChurchOfBeef():
+   prep x = 5
+   x = 6
+beef`
+	warnings := analyze(t, input)
+
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, diagnostics.CodeUnusedVariable, warnings[0].Code)
+}
+
+func TestUnusedVariableExemptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"underscore prefix", "praise f():\n   prep _ignored = 1\nbeef"},
+		{"top-level variable", "prep version = 1"},
+		{"read in nested block", "praise f(n):\n   prep x = 1\n   if n > 0:\n      serve x\n   beef\nbeef"},
+		{"read by nested function", "praise f():\n   prep x = 1\n   praise g():\n      serve x\n   beef\n   serve g\nbeef"},
+		{"read in loop condition", "praise f():\n   prep i = 0\n   feast while i < 3:\n      i = i + 1\n   beef\nbeef"},
+	}
+
+	for _, tt := range tests {
+		assert.Empty(t, analyze(t, tt.input), tt.name)
+	}
+}
+
+func TestUnreachableCode(t *testing.T) {
+	input := `praise half(n):
+   serve n / 2
+   prep wasted = 1
+   serve wasted
+beef`
+	warnings := analyze(t, input)
+
+	// Only the first unreachable statement is reported, and its declarations aren't checked
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, diagnostics.CodeUnreachableCode, warnings[0].Code)
+	assert.Equal(t, 3, warnings[0].Line)
+	assert.Equal(t, 4, warnings[0].Column)
+}
+
+func TestServeInNestedBlockDoesNotHideFollowingCode(t *testing.T) {
+	input := `praise f(n):
+   if n > 0:
+      serve 1
+   beef
+   serve 2
+beef`
+	assert.Empty(t, analyze(t, input))
+}
+
+func TestConstantConditions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"if true:\n   prep a = 1\nbeef", "'if' condition is always the same value"},
+		{"if 1 > 2:\n   prep a = 1\nbeef", "'if' condition is always the same value"},
+		{"if !false:\n   prep a = 1\nbeef", "'if' condition is always the same value"},
+		{"feast while false:\n   prep a = 1\nbeef", "'feast while' condition is always the same value"},
+		{"feast while 1 == 1:\n   prep a = 1\nbeef", "'feast while' condition is always the same value"},
+	}
+
+	for _, tt := range tests {
+		warnings := analyze(t, tt.input)
+		if assert.Len(t, warnings, 1, tt.input) {
+			assert.Equal(t, diagnostics.CodeConstantCondition, warnings[0].Code)
+			assert.Equal(t, tt.expected, warnings[0].Message)
+			assert.Equal(t, 1, warnings[0].Line)
+		}
+	}
+}
+
+func TestNonConstantConditions(t *testing.T) {
+	inputs := []string{
+		"prep x = 1\nif x > 0:\n   prep a = 1\nbeef",
+		"praise f():\n   serve true\nbeef\nif f():\n   prep a = 1\nbeef",
+		// 'feast while true' is the idiom for a loop that exits with serve
+		"praise f():\n   feast while true:\n      serve 1\n   beef\nbeef",
+	}
+
+	for _, input := range inputs {
+		assert.Empty(t, analyze(t, input), input)
+	}
+}
+
+func TestRedeclarations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		line     int
+	}{
+		{"praise f():\nbeef\npraise f():\nbeef", "'f' is already declared at line 1, col 8", 3},
+		{"prep total = 1\npraise total():\nbeef", "'total' is already declared at line 1, col 6", 2},
+		{"praise f():\n   prep x = 1\n   prep x = 2\n   serve x\nbeef", "'x' is already declared at line 2, col 9", 3},
+	}
+
+	for _, tt := range tests {
+		warnings := analyze(t, tt.input)
+		if assert.Len(t, warnings, 1, tt.input) {
+			assert.Equal(t, diagnostics.CodeRedeclared, warnings[0].Code)
+			assert.Equal(t, tt.expected, warnings[0].Message)
+			assert.Equal(t, tt.line, warnings[0].Line)
+		}
+	}
+}
+
+func TestDeclarationsInDifferentBlocksDontClash(t *testing.T) {
+	input := `
+prep x = 1
+praise f(flag):
+   prep x = 2
+   if flag:
+      prep _label = "yes"
+   beef
+   if !flag:
+      prep _label = "no"
+   beef
+   serve x
+beef`
+	assert.Empty(t, analyze(t, input))
+}
@@ -1,10 +1,17 @@
 package ast
 
-import "github.com/elitwilson/beeflang/internal/token"
+import (
+	"math/big"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/token"
+)
 
 // Node is the base interface for all AST nodes
 type Node interface {
 	TokenLiteral() string
+	Start() token.Position // where the node's source text begins
+	End() token.Position   // just past where the node's source text ends
 }
 
 // Statement represents a statement node in the AST
@@ -22,6 +29,9 @@ type Expression interface {
 // Program is the root node of every AST
 type Program struct {
 	Statements []Statement
+	Comments   []*Comment            // every comment in the file, in source order
+	Trivia     map[Statement]*Trivia // comments attached to statements (see AttachComments)
+	Doc        []*Comment            // module doc: the comment block opening the file, if any
 }
 
 func (p *Program) TokenLiteral() string {
@@ -31,14 +41,41 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Start() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Start()
+	}
+	return token.Position{}
+}
+
+func (p *Program) End() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return token.Position{}
+}
+
 // IntegerLiteral represents an integer literal like 42
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
 }
 
-func (il *IntegerLiteral) expressionNode()      {}
-func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) expressionNode()       {}
+func (il *IntegerLiteral) TokenLiteral() string  { return il.Token.Literal }
+func (il *IntegerLiteral) Start() token.Position { return il.Token.Pos() }
+func (il *IntegerLiteral) End() token.Position   { return il.Token.End }
+
+// BigIntegerLiteral represents a big integer literal like 123n
+type BigIntegerLiteral struct {
+	Token token.Token
+	Value *big.Int
+}
+
+func (bl *BigIntegerLiteral) expressionNode()       {}
+func (bl *BigIntegerLiteral) TokenLiteral() string  { return bl.Token.Literal }
+func (bl *BigIntegerLiteral) Start() token.Position { return bl.Token.Pos() }
+func (bl *BigIntegerLiteral) End() token.Position   { return bl.Token.End }
 
 // BooleanLiteral represents a boolean literal like true or false
 type BooleanLiteral struct {
@@ -46,8 +83,10 @@ type BooleanLiteral struct {
 	Value bool
 }
 
-func (bl *BooleanLiteral) expressionNode()      {}
-func (bl *BooleanLiteral) TokenLiteral() string { return bl.Token.Literal }
+func (bl *BooleanLiteral) expressionNode()       {}
+func (bl *BooleanLiteral) TokenLiteral() string  { return bl.Token.Literal }
+func (bl *BooleanLiteral) Start() token.Position { return bl.Token.Pos() }
+func (bl *BooleanLiteral) End() token.Position   { return bl.Token.End }
 
 // StringLiteral represents a string literal like "Hello, Beef!"
 type StringLiteral struct {
@@ -55,8 +94,10 @@ type StringLiteral struct {
 	Value string
 }
 
-func (sl *StringLiteral) expressionNode()      {}
-func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) expressionNode()       {}
+func (sl *StringLiteral) TokenLiteral() string  { return sl.Token.Literal }
+func (sl *StringLiteral) Start() token.Position { return sl.Token.Pos() }
+func (sl *StringLiteral) End() token.Position   { return sl.Token.End }
 
 // Identifier represents a variable or function name
 type Identifier struct {
@@ -64,8 +105,10 @@ type Identifier struct {
 	Value string
 }
 
-func (i *Identifier) expressionNode()      {}
-func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) expressionNode()       {}
+func (i *Identifier) TokenLiteral() string  { return i.Token.Literal }
+func (i *Identifier) Start() token.Position { return i.Token.Pos() }
+func (i *Identifier) End() token.Position   { return i.Token.End }
 
 // PrefixExpression represents prefix operators like -5 or !true
 type PrefixExpression struct {
@@ -74,8 +117,15 @@ type PrefixExpression struct {
 	Right    Expression
 }
 
-func (pe *PrefixExpression) expressionNode()      {}
-func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) expressionNode()       {}
+func (pe *PrefixExpression) TokenLiteral() string  { return pe.Token.Literal }
+func (pe *PrefixExpression) Start() token.Position { return pe.Token.Pos() }
+func (pe *PrefixExpression) End() token.Position {
+	if pe.Right != nil {
+		return pe.Right.End()
+	}
+	return pe.Token.End
+}
 
 // InfixExpression represents binary operators like 5 + 3
 type InfixExpression struct {
@@ -88,15 +138,73 @@ type InfixExpression struct {
 func (ie *InfixExpression) expressionNode()      {}
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal }
 
-// VariableDeclaration represents: prep x = 42
+// Start is where the left operand begins; Token is the operator in the middle.
+func (ie *InfixExpression) Start() token.Position {
+	if ie.Left != nil {
+		return ie.Left.Start()
+	}
+	return ie.Token.Pos()
+}
+
+func (ie *InfixExpression) End() token.Position {
+	if ie.Right != nil {
+		return ie.Right.End()
+	}
+	return ie.Token.End
+}
+
+// PipeExpression represents: value |> f(args), which calls f(value, args).
+// The right side can also be a bare function: value |> f calls f(value).
+type PipeExpression struct {
+	Token token.Token // The '|>' token
+	Left  Expression
+	Right Expression
+}
+
+func (pe *PipeExpression) expressionNode()      {}
+func (pe *PipeExpression) TokenLiteral() string { return pe.Token.Literal }
+
+func (pe *PipeExpression) Start() token.Position {
+	if pe.Left != nil {
+		return pe.Left.Start()
+	}
+	return pe.Token.Pos()
+}
+
+func (pe *PipeExpression) End() token.Position {
+	if pe.Right != nil {
+		return pe.Right.End()
+	}
+	return pe.Token.End
+}
+
+// Call returns the call the pipe stands for, with the left side as its first
+// argument.
+func (pe *PipeExpression) Call() *FunctionCall {
+	if call, ok := pe.Right.(*FunctionCall); ok {
+		return &FunctionCall{Token: call.Token, Function: call.Function,
+			Arguments: append([]Expression{pe.Left}, call.Arguments...), Rparen: call.Rparen}
+	}
+	return &FunctionCall{Token: pe.Token, Function: pe.Right, Arguments: []Expression{pe.Left}, Rparen: pe.Token}
+}
+
+// VariableDeclaration represents: prep x = 42 (or, annotated, prep x: int = 42)
 type VariableDeclaration struct {
 	Token token.Token
 	Name  *Identifier
+	Type  *TypeAnnotation // nil when not annotated
 	Value Expression
 }
 
-func (vd *VariableDeclaration) statementNode()       {}
-func (vd *VariableDeclaration) TokenLiteral() string { return vd.Token.Literal }
+func (vd *VariableDeclaration) statementNode()        {}
+func (vd *VariableDeclaration) TokenLiteral() string  { return vd.Token.Literal }
+func (vd *VariableDeclaration) Start() token.Position { return vd.Token.Pos() }
+func (vd *VariableDeclaration) End() token.Position {
+	if vd.Value != nil {
+		return vd.Value.End()
+	}
+	return vd.Token.End
+}
 
 // AssignmentStatement represents: x = 42 (reassignment, no prep keyword)
 type AssignmentStatement struct {
@@ -105,8 +213,15 @@ type AssignmentStatement struct {
 	Value Expression
 }
 
-func (as *AssignmentStatement) statementNode()       {}
-func (as *AssignmentStatement) TokenLiteral() string { return as.Token.Literal }
+func (as *AssignmentStatement) statementNode()        {}
+func (as *AssignmentStatement) TokenLiteral() string  { return as.Token.Literal }
+func (as *AssignmentStatement) Start() token.Position { return as.Token.Pos() }
+func (as *AssignmentStatement) End() token.Position {
+	if as.Value != nil {
+		return as.Value.End()
+	}
+	return as.Token.End
+}
 
 // ReturnStatement represents: serve x
 type ReturnStatement struct {
@@ -114,8 +229,140 @@ type ReturnStatement struct {
 	ReturnValue Expression
 }
 
-func (rs *ReturnStatement) statementNode()       {}
-func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) statementNode()        {}
+func (rs *ReturnStatement) TokenLiteral() string  { return rs.Token.Literal }
+func (rs *ReturnStatement) Start() token.Position { return rs.Token.Pos() }
+func (rs *ReturnStatement) End() token.Position {
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+	return rs.Token.End
+}
+
+// YieldStatement represents: yield x
+// A function containing one is a generator.
+type YieldStatement struct {
+	Token token.Token // The 'yield' token
+	Value Expression
+}
+
+func (ys *YieldStatement) statementNode()        {}
+func (ys *YieldStatement) TokenLiteral() string  { return ys.Token.Literal }
+func (ys *YieldStatement) Start() token.Position { return ys.Token.Pos() }
+func (ys *YieldStatement) End() token.Position {
+	if ys.Value != nil {
+		return ys.Value.End()
+	}
+	return ys.Token.End
+}
+
+// AssertStatement represents: assert condition, message
+// The message is optional, and only evaluated when the condition is falsy.
+type AssertStatement struct {
+	Token     token.Token // The 'assert' token
+	Condition Expression
+	Message   Expression // nil without a message
+}
+
+func (as *AssertStatement) statementNode()        {}
+func (as *AssertStatement) TokenLiteral() string  { return as.Token.Literal }
+func (as *AssertStatement) Start() token.Position { return as.Token.Pos() }
+func (as *AssertStatement) End() token.Position {
+	if as.Message != nil {
+		return as.Message.End()
+	}
+	if as.Condition != nil {
+		return as.Condition.End()
+	}
+	return as.Token.End
+}
+
+// StampedeStatement represents: stampede f(args)
+// The call runs concurrently, in a task of its own.
+type StampedeStatement struct {
+	Token token.Token // The 'stampede' token
+	Call  *FunctionCall
+}
+
+func (ss *StampedeStatement) statementNode()        {}
+func (ss *StampedeStatement) TokenLiteral() string  { return ss.Token.Literal }
+func (ss *StampedeStatement) Start() token.Position { return ss.Token.Pos() }
+func (ss *StampedeStatement) End() token.Position {
+	if ss.Call != nil {
+		return ss.Call.End()
+	}
+	return ss.Token.End
+}
+
+// DessertStatement represents: dessert f(args)
+// The call runs when the enclosing function returns, however it returns.
+type DessertStatement struct {
+	Token token.Token // The 'dessert' token
+	Call  *FunctionCall
+}
+
+func (ds *DessertStatement) statementNode()        {}
+func (ds *DessertStatement) TokenLiteral() string  { return ds.Token.Literal }
+func (ds *DessertStatement) Start() token.Position { return ds.Token.Pos() }
+func (ds *DessertStatement) End() token.Position {
+	if ds.Call != nil {
+		return ds.Call.End()
+	}
+	return ds.Token.End
+}
+
+// SelectStatement waits until one of several channel operations can go ahead,
+// then runs that case's block:
+//
+//	select:
+//	when msg = inbox.recv():
+//	   ...
+//	when outbox.send(reply):
+//	   ...
+//	else:
+//	   ...  (runs straight away if no case is ready)
+//	beef
+type SelectStatement struct {
+	Token   token.Token // The 'select' token
+	Cases   []*SelectCase
+	Default *BlockStatement // the 'else' block; nil if there is none
+	Closing token.Token     // The closing 'beef'
+}
+
+func (ss *SelectStatement) statementNode()        {}
+func (ss *SelectStatement) TokenLiteral() string  { return ss.Token.Literal }
+func (ss *SelectStatement) Start() token.Position { return ss.Token.Pos() }
+func (ss *SelectStatement) End() token.Position {
+	if ss.Closing.Type == token.BEEF {
+		return ss.Closing.End
+	}
+	return ss.Token.End
+}
+
+// SelectCase is one 'when' of a select: ch.recv() (optionally assigning the
+// value received, name = ch.recv()) or ch.send(value).
+type SelectCase struct {
+	Token token.Token   // The 'when' token
+	Name  *Identifier   // receives the value; nil if not assigned
+	Call  *FunctionCall // ch.recv() or ch.send(value)
+	Body  *BlockStatement
+}
+
+func (sc *SelectCase) TokenLiteral() string  { return sc.Token.Literal }
+func (sc *SelectCase) Start() token.Position { return sc.Token.Pos() }
+func (sc *SelectCase) End() token.Position {
+	if sc.Body != nil {
+		return sc.Body.End()
+	}
+	return sc.Token.End
+}
+
+// Channel returns the expression for the channel the case operates on, and
+// whether the case sends (rather than receives).
+func (sc *SelectCase) Channel() (channel Expression, send bool) {
+	access := sc.Call.Function.(*MemberAccessExpression)
+	return access.Object, access.Member.Value == "send"
+}
 
 // IfStatement represents: if condition: consequence beef else alternative beef
 type IfStatement struct {
@@ -125,8 +372,32 @@ type IfStatement struct {
 	Alternative *BlockStatement
 }
 
-func (is *IfStatement) statementNode()       {}
-func (is *IfStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *IfStatement) statementNode()        {}
+func (is *IfStatement) TokenLiteral() string  { return is.Token.Literal }
+func (is *IfStatement) Start() token.Position { return is.Token.Pos() }
+
+// End is the end of the closing 'beef' (of the else block, if there is one).
+func (is *IfStatement) End() token.Position {
+	if is.Alternative != nil {
+		return is.Alternative.End()
+	}
+	if is.Consequence != nil {
+		return is.Consequence.End()
+	}
+	return is.Token.End
+}
+
+// IfExpression is an if statement used where a value is expected, as in
+// prep label = if hp > 0: "alive" else: "dead" beef. It yields the value of
+// the branch taken, or null when there is none.
+type IfExpression struct {
+	If *IfStatement
+}
+
+func (ie *IfExpression) expressionNode()       {}
+func (ie *IfExpression) TokenLiteral() string  { return ie.If.TokenLiteral() }
+func (ie *IfExpression) Start() token.Position { return ie.If.Start() }
+func (ie *IfExpression) End() token.Position   { return ie.If.End() }
 
 // WhileLoop represents: feast while condition: body beef
 type WhileLoop struct {
@@ -135,38 +406,153 @@ type WhileLoop struct {
 	Body      *BlockStatement
 }
 
-func (wl *WhileLoop) statementNode()       {}
-func (wl *WhileLoop) TokenLiteral() string { return wl.Token.Literal }
+func (wl *WhileLoop) statementNode()        {}
+func (wl *WhileLoop) TokenLiteral() string  { return wl.Token.Literal }
+func (wl *WhileLoop) Start() token.Position { return wl.Token.Pos() }
+func (wl *WhileLoop) End() token.Position {
+	if wl.Body != nil {
+		return wl.Body.End()
+	}
+	return wl.Token.End
+}
+
+// ForEachLoop represents: feast item in items: body beef
+// It loops over an array's elements, a hash's keys or a generator's values.
+type ForEachLoop struct {
+	Token    token.Token // The 'feast' token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fl *ForEachLoop) statementNode()        {}
+func (fl *ForEachLoop) TokenLiteral() string  { return fl.Token.Literal }
+func (fl *ForEachLoop) Start() token.Position { return fl.Token.Pos() }
+func (fl *ForEachLoop) End() token.Position {
+	if fl.Body != nil {
+		return fl.Body.End()
+	}
+	return fl.Token.End
+}
+
+// UsingStatement represents: using name = value: body beef
+// The value's close member is called when the block is left, however it is left.
+type UsingStatement struct {
+	Token token.Token // The 'using' token
+	Name  *Identifier
+	Value Expression
+	Body  *BlockStatement
+}
+
+func (us *UsingStatement) statementNode()        {}
+func (us *UsingStatement) TokenLiteral() string  { return us.Token.Literal }
+func (us *UsingStatement) Start() token.Position { return us.Token.Pos() }
+func (us *UsingStatement) End() token.Position {
+	if us.Body != nil {
+		return us.Body.End()
+	}
+	return us.Token.End
+}
 
 // FunctionDeclaration represents: praise name(params): body beef
+// Annotated form: praise add(a: int, b: int) -> int: body beef
 type FunctionDeclaration struct {
-	Token      token.Token
-	Name       *Identifier
-	Parameters []*Identifier
-	Body       *BlockStatement
+	Token          token.Token
+	Name           *Identifier
+	Parameters     []*Identifier
+	ParameterTypes []*TypeAnnotation // parallel to Parameters; nil entries are unannotated
+	ReturnType     *TypeAnnotation   // nil when not annotated
+	Body           *BlockStatement
+	Doc            []*Comment   // doc comment: the comment lines directly above 'praise' (or its decorators)
+	Decorators     []*Decorator // the @ lines above 'praise', top first
 }
 
 func (fd *FunctionDeclaration) statementNode()       {}
 func (fd *FunctionDeclaration) TokenLiteral() string { return fd.Token.Literal }
 
+// Start is the start of the first decorator, if there is one.
+func (fd *FunctionDeclaration) Start() token.Position {
+	if len(fd.Decorators) > 0 {
+		return fd.Decorators[0].Start()
+	}
+	return fd.Token.Pos()
+}
+func (fd *FunctionDeclaration) End() token.Position {
+	if fd.Body != nil {
+		return fd.Body.End()
+	}
+	return fd.Token.End
+}
+
+// Decorator represents: @expression, on its own line above a function
+// declaration. The expression's value is called with the declared function,
+// and what it serves is bound to the function's name instead.
+type Decorator struct {
+	Token      token.Token // The '@' token
+	Expression Expression
+}
+
+func (d *Decorator) TokenLiteral() string  { return d.Token.Literal }
+func (d *Decorator) Start() token.Position { return d.Token.Pos() }
+func (d *Decorator) End() token.Position {
+	if d.Expression != nil {
+		return d.Expression.End()
+	}
+	return d.Token.End
+}
+
+// TypeAnnotation represents an optional type written after a name (x: int)
+// or after a parameter list (-> int). Annotations are only read by the type
+// checker; the evaluator ignores them.
+type TypeAnnotation struct {
+	Token token.Token // The type name token
+	Name  string      // int, bool, string, fn, null or any
+}
+
+func (ta *TypeAnnotation) TokenLiteral() string  { return ta.Token.Literal }
+func (ta *TypeAnnotation) Start() token.Position { return ta.Token.Pos() }
+func (ta *TypeAnnotation) End() token.Position   { return ta.Token.End }
+
 // FunctionCall represents: preach(42)
 type FunctionCall struct {
-	Token     token.Token
+	Token     token.Token // The '(' token
 	Function  Expression
 	Arguments []Expression
+	Rparen    token.Token // The closing ')' token
 }
 
 func (fc *FunctionCall) expressionNode()      {}
 func (fc *FunctionCall) TokenLiteral() string { return fc.Token.Literal }
 
+// Start is where the callee begins; Token is the '('.
+func (fc *FunctionCall) Start() token.Position {
+	if fc.Function != nil {
+		return fc.Function.Start()
+	}
+	return fc.Token.Pos()
+}
+
+func (fc *FunctionCall) End() token.Position { return fc.Rparen.End }
+
 // BlockStatement represents a block of statements
 type BlockStatement struct {
-	Token      token.Token
+	Token      token.Token // The ':' that opens the block
 	Statements []Statement
+	Closing    token.Token // The token that ended the block: 'beef', 'else' or EOF
 }
 
-func (bs *BlockStatement) statementNode()       {}
-func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) statementNode()        {}
+func (bs *BlockStatement) TokenLiteral() string  { return bs.Token.Literal }
+func (bs *BlockStatement) Start() token.Position { return bs.Token.Pos() }
+
+// End is the end of the closing 'beef'. A block cut short by 'else' (or by the
+// end of the file) ends where that token starts.
+func (bs *BlockStatement) End() token.Position {
+	if bs.Closing.Type == token.BEEF {
+		return bs.Closing.End
+	}
+	return bs.Closing.Pos()
+}
 
 // ExpressionStatement wraps an expression so it can be used as a statement
 type ExpressionStatement struct {
@@ -176,22 +562,167 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Start() token.Position {
+	if es.Expression != nil {
+		return es.Expression.Start()
+	}
+	return es.Token.Pos()
+}
+func (es *ExpressionStatement) End() token.Position {
+	if es.Expression != nil {
+		return es.Expression.End()
+	}
+	return es.Token.End
+}
 
 // WrangleStatement represents: wrangle modulename
+// Optional forms:
+//   - wrangle io as out              (bind the module under a different name)
+//   - wrangle io expose preach, input (bind selected members directly into scope)
+//   - wrangle "enemies.json" as enemies (load a data file; the alias is required)
 type WrangleStatement struct {
-	Token      token.Token // The 'wrangle' token
-	ModuleName *Identifier
+	Token      token.Token    // The 'wrangle' token
+	ModuleName *Identifier    // nil for a data file
+	DataFile   *StringLiteral // nil unless a data file was named
+	Alias      *Identifier    // nil unless 'as' was used
+	Exposed    []*Identifier  // empty unless 'expose' was used
+}
+
+func (ws *WrangleStatement) statementNode()        {}
+func (ws *WrangleStatement) TokenLiteral() string  { return ws.Token.Literal }
+func (ws *WrangleStatement) Start() token.Position { return ws.Token.Pos() }
+func (ws *WrangleStatement) End() token.Position {
+	switch {
+	case len(ws.Exposed) > 0:
+		return ws.Exposed[len(ws.Exposed)-1].End()
+	case ws.Alias != nil:
+		return ws.Alias.End()
+	case ws.ModuleName != nil:
+		return ws.ModuleName.End()
+	case ws.DataFile != nil:
+		return ws.DataFile.End()
+	}
+	return ws.Token.End
+}
+
+// PastureStatement represents: pasture mygame.ai
+// It names the namespace a module file's members are grouped under when the
+// file is wrangled.
+type PastureStatement struct {
+	Token token.Token   // The 'pasture' token
+	Names []*Identifier // mygame, ai
+}
+
+func (ps *PastureStatement) statementNode()        {}
+func (ps *PastureStatement) TokenLiteral() string  { return ps.Token.Literal }
+func (ps *PastureStatement) Start() token.Position { return ps.Token.Pos() }
+func (ps *PastureStatement) End() token.Position {
+	if len(ps.Names) > 0 {
+		return ps.Names[len(ps.Names)-1].End()
+	}
+	return ps.Token.End
 }
 
-func (ws *WrangleStatement) statementNode()       {}
-func (ws *WrangleStatement) TokenLiteral() string { return ws.Token.Literal }
+// Path returns the dotted name of the namespace: "mygame.ai".
+func (ps *PastureStatement) Path() string {
+	names := make([]string, len(ps.Names))
+	for i, name := range ps.Names {
+		names[i] = name.Value
+	}
+	return strings.Join(names, ".")
+}
+
+// ExposeStatement represents: expose math, array
+// It makes the members of modules the file wrangled members of the file's own
+// module, so one wrangle of it brings them all in.
+type ExposeStatement struct {
+	Token   token.Token   // The 'expose' token
+	Modules []*Identifier // math, array
+}
+
+func (es *ExposeStatement) statementNode()        {}
+func (es *ExposeStatement) TokenLiteral() string  { return es.Token.Literal }
+func (es *ExposeStatement) Start() token.Position { return es.Token.Pos() }
+func (es *ExposeStatement) End() token.Position {
+	if len(es.Modules) > 0 {
+		return es.Modules[len(es.Modules)-1].End()
+	}
+	return es.Token.End
+}
 
 // MemberAccessExpression represents: object.member (like io.preach)
 type MemberAccessExpression struct {
-	Token  token.Token // The '.' token
-	Object Expression  // The left side (usually an identifier like 'io')
-	Member *Identifier // The right side (the member name like 'preach')
+	Token    token.Token // The '.' or '?.' token
+	Object   Expression  // The left side (usually an identifier like 'io')
+	Member   *Identifier // The right side (the member name like 'preach')
+	Optional bool        // written '?.': null instead of an error when there's nothing there
 }
 
 func (ma *MemberAccessExpression) expressionNode()      {}
 func (ma *MemberAccessExpression) TokenLiteral() string { return ma.Token.Literal }
+
+// Start is where the object begins; Token is the '.'.
+func (ma *MemberAccessExpression) Start() token.Position {
+	if ma.Object != nil {
+		return ma.Object.Start()
+	}
+	return ma.Token.Pos()
+}
+
+func (ma *MemberAccessExpression) End() token.Position {
+	if ma.Member != nil {
+		return ma.Member.End()
+	}
+	return ma.Token.End
+}
+
+// ArrayLiteral represents: [1, 2, 3]
+type ArrayLiteral struct {
+	Token    token.Token // The '[' token
+	Elements []Expression
+	Rbracket token.Token // The closing ']' token
+}
+
+func (al *ArrayLiteral) expressionNode()       {}
+func (al *ArrayLiteral) TokenLiteral() string  { return al.Token.Literal }
+func (al *ArrayLiteral) Start() token.Position { return al.Token.Pos() }
+func (al *ArrayLiteral) End() token.Position   { return al.Rbracket.End }
+
+// HashLiteral represents: {"name": "brisket", "hours": 12}
+type HashLiteral struct {
+	Token  token.Token // The '{' token
+	Pairs  []HashPair  // in source order
+	Rbrace token.Token // The closing '}' token
+}
+
+// HashPair is one key: value entry of a HashLiteral.
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+func (hl *HashLiteral) expressionNode()       {}
+func (hl *HashLiteral) TokenLiteral() string  { return hl.Token.Literal }
+func (hl *HashLiteral) Start() token.Position { return hl.Token.Pos() }
+func (hl *HashLiteral) End() token.Position   { return hl.Rbrace.End }
+
+// IndexExpression represents: cuts[0] or order["side"]
+type IndexExpression struct {
+	Token    token.Token // The '[' token
+	Left     Expression
+	Index    Expression
+	Rbracket token.Token // The closing ']' token
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+
+// Start is where the indexed value begins; Token is the '['.
+func (ie *IndexExpression) Start() token.Position {
+	if ie.Left != nil {
+		return ie.Left.Start()
+	}
+	return ie.Token.Pos()
+}
+
+func (ie *IndexExpression) End() token.Position { return ie.Rbracket.End }
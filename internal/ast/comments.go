@@ -0,0 +1,170 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// Comment is a '#' comment. Comments aren't part of the grammar: the lexer
+// collects them on the side and AttachComments links them to statements.
+// Walk doesn't visit comments.
+type Comment struct {
+	Token token.Token // The COMMENT token; Literal includes the '#'
+}
+
+func (c *Comment) TokenLiteral() string  { return c.Token.Literal }
+func (c *Comment) Start() token.Position { return c.Token.Pos() }
+func (c *Comment) End() token.Position   { return c.Token.End }
+
+// Text returns the comment without its '#' and surrounding whitespace.
+func (c *Comment) Text() string {
+	return strings.TrimSpace(strings.TrimPrefix(c.Token.Literal, "#"))
+}
+
+// CommentText joins a comment block into plain text, one line per comment.
+// Used for doc comments.
+func CommentText(comments []*Comment) string {
+	lines := make([]string, len(comments))
+	for i, c := range comments {
+		lines[i] = c.Text()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Docstring returns the function's docstring - a string literal standing as
+// the first statement of its body - with each line trimmed like a doc
+// comment's. ok is false when the body doesn't open with one.
+func (fd *FunctionDeclaration) Docstring() (doc string, ok bool) {
+	if fd.Body == nil || len(fd.Body.Statements) == 0 {
+		return "", false
+	}
+	stmt, isExpr := fd.Body.Statements[0].(*ExpressionStatement)
+	if !isExpr {
+		return "", false
+	}
+	str, isString := stmt.Expression.(*StringLiteral)
+	if !isString {
+		return "", false
+	}
+	lines := strings.Split(str.Value, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n"), true
+}
+
+// DocText is the function's documentation: its docstring if it has one,
+// otherwise its doc comment.
+func (fd *FunctionDeclaration) DocText() string {
+	if doc, ok := fd.Docstring(); ok {
+		return doc
+	}
+	return CommentText(fd.Doc)
+}
+
+// Trivia holds the comments attached to a statement.
+type Trivia struct {
+	Leading  []*Comment // own-line comments directly above the statement, no blank line between
+	Trailing *Comment   // a comment after the statement on its last line
+}
+
+// AttachComments records a file's comments on the program and attaches them
+// to the statements they belong to:
+//
+//	# Leading: comment lines directly above a statement
+//	prep total = 0   # Trailing: a comment after the statement on its last line
+//
+// A trailing comment after a block's closing 'beef' belongs to the if/loop/
+// function that the 'beef' ends. Comments that fit neither rule (separated by
+// a blank line, or after a block header like "if x > 0:") stay only in
+// Program.Comments, so a formatter can still put them back.
+//
+// Doc comments are picked out too: a function's leading comments become its
+// Doc, and a comment block that opens the file and is followed by a blank line
+// becomes the module's Doc.
+func AttachComments(program *Program, comments []*Comment) {
+	program.Comments = comments
+	program.Trivia = map[Statement]*Trivia{}
+	if len(comments) == 0 {
+		return
+	}
+
+	// Gather the statements in source order, and for every line the earliest
+	// column where some node starts or ends - anything at or before a comment
+	// means the comment shares its line with code.
+	var statements []Statement
+	codeBefore := map[int]int{}
+	noteCode := func(pos token.Position) {
+		if col, ok := codeBefore[pos.Line]; !ok || pos.Column < col {
+			codeBefore[pos.Line] = pos.Column
+		}
+	}
+	Inspect(program, func(n Node) bool {
+		switch n.(type) {
+		case nil:
+			return false
+		case *Program:
+			return true
+		}
+		noteCode(n.Start())
+		noteCode(n.End())
+		if stmt, ok := n.(Statement); ok {
+			if _, isBlock := n.(*BlockStatement); !isBlock {
+				statements = append(statements, stmt)
+			}
+		}
+		return true
+	})
+
+	trivia := func(stmt Statement) *Trivia {
+		if program.Trivia[stmt] == nil {
+			program.Trivia[stmt] = &Trivia{}
+		}
+		return program.Trivia[stmt]
+	}
+
+	ownLine := map[int]*Comment{}
+	for _, c := range comments {
+		if col, ok := codeBefore[c.Token.Line]; !ok || col > c.Token.Column {
+			ownLine[c.Token.Line] = c
+			continue
+		}
+
+		// Trailing: the statement ending last before the comment on the same line.
+		// Statements are in pre-order, so on a tie the outermost one wins.
+		var owner Statement
+		for _, stmt := range statements {
+			end := stmt.End()
+			if end.Line != c.Token.Line || end.Offset > c.Token.Offset {
+				continue
+			}
+			if owner == nil || end.Offset > owner.End().Offset {
+				owner = stmt
+			}
+		}
+		if owner != nil && trivia(owner).Trailing == nil {
+			trivia(owner).Trailing = c
+		}
+	}
+
+	for _, stmt := range statements {
+		var leading []*Comment
+		for line := stmt.Start().Line - 1; ownLine[line] != nil; line-- {
+			leading = append([]*Comment{ownLine[line]}, leading...)
+			delete(ownLine, line)
+		}
+		if len(leading) > 0 {
+			trivia(stmt).Leading = leading
+			if fn, ok := stmt.(*FunctionDeclaration); ok {
+				fn.Doc = leading
+			}
+		}
+	}
+
+	// Whatever is left on the first lines wasn't claimed by a statement,
+	// so it is separated from the code: that's the module doc
+	for line := 1; ownLine[line] != nil; line++ {
+		program.Doc = append(program.Doc, ownLine[line])
+	}
+}
@@ -0,0 +1,166 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+// texts returns the Text() of each comment
+func texts(comments []*ast.Comment) []string {
+	out := []string{}
+	for _, c := range comments {
+		out = append(out, c.Text())
+	}
+	return out
+}
+
+func TestAttachLeadingAndTrailingComments(t *testing.T) {
+	program := parse(t, `# Adds two numbers.
+# Both must be integers.
+praise add(a, b):
+   # the sum
+   prep sum = a + b   # no overflow checks
+   serve sum
+beef  # end of add
+
+# separated by a blank line
+prep total = add(1, 2)`)
+
+	assert.Len(t, program.Comments, 6)
+
+	fn := program.Statements[0].(*ast.FunctionDeclaration)
+	fnTrivia := program.Trivia[fn]
+	if assert.NotNil(t, fnTrivia) {
+		assert.Equal(t, []string{"Adds two numbers.", "Both must be integers."}, texts(fnTrivia.Leading))
+		assert.Equal(t, "end of add", fnTrivia.Trailing.Text())
+	}
+
+	decl := fn.Body.Statements[0]
+	declTrivia := program.Trivia[decl]
+	if assert.NotNil(t, declTrivia) {
+		assert.Equal(t, []string{"the sum"}, texts(declTrivia.Leading))
+		assert.Equal(t, "no overflow checks", declTrivia.Trailing.Text())
+	}
+
+	// No comments on the serve
+	assert.Nil(t, program.Trivia[fn.Body.Statements[1]])
+
+	// A blank line between comment and statement keeps them apart
+	total := program.Statements[1]
+	if assert.NotNil(t, program.Trivia[total]) {
+		assert.Equal(t, []string{"separated by a blank line"}, texts(program.Trivia[total].Leading))
+	}
+}
+
+func TestUnattachedCommentsStayInProgramComments(t *testing.T) {
+	program := parse(t, `prep x = 1
+
+# floating comment
+
+if x > 0:   # after a header
+   prep y = x
+else:   # after else
+   prep y = 0
+beef
+# at the end of the file`)
+
+	assert.Equal(t, []string{"floating comment", "after a header", "after else", "at the end of the file"},
+		texts(program.Comments))
+
+	for stmt, trivia := range program.Trivia {
+		t.Errorf("unexpected trivia on %T at %v: %+v", stmt, stmt.Start(), trivia)
+	}
+}
+
+func TestTrailingCommentGoesToStatementEndingBeforeIt(t *testing.T) {
+	program := parse(t, `feast while running:
+   tick()
+beef # loop done`)
+
+	loop := program.Statements[0]
+	if assert.NotNil(t, program.Trivia[loop]) {
+		assert.Equal(t, "loop done", program.Trivia[loop].Trailing.Text())
+		assert.Empty(t, program.Trivia[loop].Leading)
+	}
+}
+
+func TestCommentText(t *testing.T) {
+	program := parse(t, "#tight\n#   padded   \nprep x = 1")
+
+	assert.Equal(t, "#tight", program.Comments[0].TokenLiteral())
+	assert.Equal(t, "tight", program.Comments[0].Text())
+	assert.Equal(t, "padded", program.Comments[1].Text())
+	assert.Equal(t, 2, program.Comments[1].Start().Line)
+	assert.Equal(t, 14, program.Comments[1].End().Column)
+}
+
+func TestDocComments(t *testing.T) {
+	program := parse(t, `# Kitchen helpers.
+# Everything here is well done.
+
+# grill cooks a cut.
+praise grill(cut):
+   # not a doc comment: nested statements only get trivia
+   prep done = cut
+   serve done
+beef
+
+praise plain():
+   serve 1
+beef`)
+
+	assert.Equal(t, []string{"Kitchen helpers.", "Everything here is well done."}, texts(program.Doc))
+	assert.Equal(t, "Kitchen helpers.\nEverything here is well done.", ast.CommentText(program.Doc))
+
+	grill := program.Statements[0].(*ast.FunctionDeclaration)
+	assert.Equal(t, "grill cooks a cut.", ast.CommentText(grill.Doc))
+
+	plain := program.Statements[1].(*ast.FunctionDeclaration)
+	assert.Empty(t, plain.Doc)
+}
+
+func TestDocstrings(t *testing.T) {
+	program := parse(t, `# the comment loses to the docstring
+praise grill(cut):
+   "grill cooks a cut.
+      It takes a while."
+   serve cut
+beef
+
+# rest lets a cut sit.
+praise rest(cut):
+   serve cut
+beef
+
+praise plain():
+   prep note = "not a docstring"
+beef`)
+
+	grill := program.Statements[0].(*ast.FunctionDeclaration)
+	doc, ok := grill.Docstring()
+	assert.True(t, ok)
+	assert.Equal(t, "grill cooks a cut.\nIt takes a while.", doc)
+	assert.Equal(t, doc, grill.DocText())
+
+	rest := program.Statements[1].(*ast.FunctionDeclaration)
+	_, ok = rest.Docstring()
+	assert.False(t, ok)
+	assert.Equal(t, "rest lets a cut sit.", rest.DocText())
+
+	plain := program.Statements[2].(*ast.FunctionDeclaration)
+	_, ok = plain.Docstring()
+	assert.False(t, ok)
+	assert.Empty(t, plain.DocText())
+}
+
+func TestCommentAboveFirstStatementIsNotModuleDoc(t *testing.T) {
+	program := parse(t, `# grill cooks a cut.
+praise grill(cut):
+   serve cut
+beef`)
+
+	assert.Empty(t, program.Doc)
+	assert.Equal(t, "grill cooks a cut.", ast.CommentText(program.Statements[0].(*ast.FunctionDeclaration).Doc))
+}
@@ -0,0 +1,108 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Format renders an expression back into Beeflang source, in a canonical
+// layout: single spaces around infix operators and after commas, none inside
+// parentheses. Used to quote code in messages, like failed assertions.
+func Format(expr Expression) string {
+	var b strings.Builder
+	format(&b, expr)
+	return b.String()
+}
+
+func format(b *strings.Builder, expr Expression) {
+	switch e := expr.(type) {
+	case *IntegerLiteral:
+		b.WriteString(strconv.FormatInt(e.Value, 10))
+	case *BigIntegerLiteral:
+		b.WriteString(e.Value.String() + "n")
+	case *BooleanLiteral:
+		b.WriteString(strconv.FormatBool(e.Value))
+	case *StringLiteral:
+		// Strings have no escapes, so the value goes between quotes as is
+		b.WriteString(`"` + e.Value + `"`)
+	case *Identifier:
+		b.WriteString(e.Value)
+	case *PrefixExpression:
+		b.WriteString(e.Operator)
+		format(b, e.Right)
+	case *InfixExpression:
+		format(b, e.Left)
+		b.WriteString(" " + e.Operator + " ")
+		format(b, e.Right)
+	case *PipeExpression:
+		format(b, e.Left)
+		b.WriteString(" |> ")
+		format(b, e.Right)
+	case *FunctionCall:
+		format(b, e.Function)
+		b.WriteString("(")
+		for i, arg := range e.Arguments {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			format(b, arg)
+		}
+		b.WriteString(")")
+	case *MemberAccessExpression:
+		format(b, e.Object)
+		b.WriteString(e.Token.Literal)
+		if e.Member != nil {
+			b.WriteString(e.Member.Value)
+		}
+	case *ArrayLiteral:
+		b.WriteString("[")
+		for i, el := range e.Elements {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			format(b, el)
+		}
+		b.WriteString("]")
+	case *HashLiteral:
+		b.WriteString("{")
+		for i, pair := range e.Pairs {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			format(b, pair.Key)
+			b.WriteString(": ")
+			format(b, pair.Value)
+		}
+		b.WriteString("}")
+	case *IndexExpression:
+		format(b, e.Left)
+		b.WriteString("[")
+		format(b, e.Index)
+		b.WriteString("]")
+	case *IfExpression:
+		b.WriteString("if ")
+		format(b, e.If.Condition)
+		b.WriteString(": ")
+		formatBranch(b, e.If.Consequence)
+		if e.If.Alternative != nil {
+			b.WriteString(" else: ")
+			formatBranch(b, e.If.Alternative)
+		}
+		b.WriteString(" beef")
+	case nil:
+	default:
+		b.WriteString(expr.TokenLiteral())
+	}
+}
+
+// formatBranch writes a branch of an if expression. Only a branch holding a
+// single expression fits on one line; anything longer is elided.
+func formatBranch(b *strings.Builder, block *BlockStatement) {
+	if block != nil && len(block.Statements) == 1 {
+		if stmt, ok := block.Statements[0].(*ExpressionStatement); ok {
+			format(b, stmt.Expression)
+			return
+		}
+	}
+	b.WriteString("...")
+}
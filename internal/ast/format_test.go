@@ -0,0 +1,34 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"total==10", "total == 10"},
+		{"-x  *  2 + 1", "-x * 2 + 1"},
+		{"!done", "!done"},
+		{`io.preach( "beef" ,1)`, `io.preach("beef", 1)`},
+		{"f()(true)", "f()(true)"},
+		{"kitchen.grill.temp >= max", "kitchen.grill.temp >= max"},
+		{`[1,"two" , [ ]]`, `[1, "two", []]`},
+		{`{ "a" :1,2: x,}`, `{"a": 1, 2: x}`},
+		{"cuts[ i + 1 ][0]", "cuts[i + 1][0]"},
+		{"-007n*x", "-7n * x"},
+		{"f(if x>1: 1 else:2 beef)", "f(if x > 1: 1 else: 2 beef)"},
+		{"f(if x: prep y = 1 beef)", "f(if x: ... beef)"},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		assert.Equal(t, tt.expected, ast.Format(stmt.Expression), tt.input)
+	}
+}
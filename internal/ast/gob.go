@@ -0,0 +1,114 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// Every node type that can sit behind a Statement or Expression is registered
+// with encoding/gob, so parsed programs can be saved and loaded again (see
+// package astcache).
+func init() {
+	gob.Register(&IntegerLiteral{})
+	gob.Register(&BigIntegerLiteral{})
+	gob.Register(&BooleanLiteral{})
+	gob.Register(&StringLiteral{})
+	gob.Register(&Identifier{})
+	gob.Register(&PrefixExpression{})
+	gob.Register(&InfixExpression{})
+	gob.Register(&VariableDeclaration{})
+	gob.Register(&AssignmentStatement{})
+	gob.Register(&ReturnStatement{})
+	gob.Register(&AssertStatement{})
+	gob.Register(&StampedeStatement{})
+	gob.Register(&DessertStatement{})
+	gob.Register(&SelectStatement{})
+	gob.Register(&IfStatement{})
+	gob.Register(&IfExpression{})
+	gob.Register(&WhileLoop{})
+	gob.Register(&ForEachLoop{})
+	gob.Register(&YieldStatement{})
+	gob.Register(&UsingStatement{})
+	gob.Register(&FunctionDeclaration{})
+	gob.Register(&FunctionCall{})
+	gob.Register(&PipeExpression{})
+	gob.Register(&BlockStatement{})
+	gob.Register(&ExpressionStatement{})
+	gob.Register(&WrangleStatement{})
+	gob.Register(&PastureStatement{})
+	gob.Register(&ExposeStatement{})
+	gob.Register(&MemberAccessExpression{})
+	gob.Register(&ArrayLiteral{})
+	gob.Register(&HashLiteral{})
+	gob.Register(&IndexExpression{})
+}
+
+// gobFunctionDeclaration is how a FunctionDeclaration is encoded: gob can't
+// encode the nil entries ParameterTypes has for unannotated parameters, so
+// the annotations are sent as values with a flag saying which are real.
+type gobFunctionDeclaration struct {
+	Token          token.Token
+	Name           *Identifier
+	Parameters     []*Identifier
+	ParameterTypes []TypeAnnotation
+	Annotated      []bool
+	ReturnType     *TypeAnnotation
+	Body           *BlockStatement
+	Doc            []*Comment
+	Decorators     []*Decorator
+}
+
+// GobEncode implements gob.GobEncoder.
+func (fd *FunctionDeclaration) GobEncode() ([]byte, error) {
+	g := gobFunctionDeclaration{
+		Token:      fd.Token,
+		Name:       fd.Name,
+		Parameters: fd.Parameters,
+		ReturnType: fd.ReturnType,
+		Body:       fd.Body,
+		Doc:        fd.Doc,
+		Decorators: fd.Decorators,
+	}
+	for _, typ := range fd.ParameterTypes {
+		if typ == nil {
+			g.ParameterTypes = append(g.ParameterTypes, TypeAnnotation{})
+		} else {
+			g.ParameterTypes = append(g.ParameterTypes, *typ)
+		}
+		g.Annotated = append(g.Annotated, typ != nil)
+	}
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(g)
+	return buf.Bytes(), err
+}
+
+// GobDecode implements gob.GobDecoder.
+func (fd *FunctionDeclaration) GobDecode(data []byte) error {
+	var g gobFunctionDeclaration
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&g); err != nil {
+		return err
+	}
+
+	*fd = FunctionDeclaration{
+		Token:      g.Token,
+		Name:       g.Name,
+		Parameters: g.Parameters,
+		ReturnType: g.ReturnType,
+		Body:       g.Body,
+		Doc:        g.Doc,
+		Decorators: g.Decorators,
+	}
+	if len(g.ParameterTypes) > 0 {
+		fd.ParameterTypes = make([]*TypeAnnotation, len(g.ParameterTypes))
+		for i := range g.ParameterTypes {
+			if i < len(g.Annotated) && g.Annotated[i] {
+				typ := g.ParameterTypes[i]
+				fd.ParameterTypes[i] = &typ
+			}
+		}
+	}
+	return nil
+}
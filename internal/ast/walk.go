@@ -0,0 +1,246 @@
+package ast
+
+// Visitor is called by Walk for each node. If Visit returns a non-nil
+// visitor w, Walk visits each child of the node with w, then calls
+// w.Visit(nil) once the children are done.
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order, in source order: it calls
+// v.Visit(node), and unless that returns nil, walks each non-nil child of node
+// with the returned visitor. Modeled on go/ast.Walk.
+//
+// Every node kind is handled here, so tools built on Walk (vet, the type
+// checker, formatters) pick up new syntax by updating this one function.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkStatements(v, n.Statements)
+
+	case *IntegerLiteral, *BigIntegerLiteral, *BooleanLiteral, *StringLiteral, *Identifier, *TypeAnnotation:
+		// Leaves: nothing to walk
+
+	case *PrefixExpression:
+		walkExpression(v, n.Right)
+
+	case *InfixExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Right)
+
+	case *PipeExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Right)
+
+	case *VariableDeclaration:
+		Walk(v, n.Name)
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
+		walkExpression(v, n.Value)
+
+	case *AssignmentStatement:
+		Walk(v, n.Name)
+		walkExpression(v, n.Value)
+
+	case *ReturnStatement:
+		walkExpression(v, n.ReturnValue)
+
+	case *YieldStatement:
+		walkExpression(v, n.Value)
+
+	case *AssertStatement:
+		walkExpression(v, n.Condition)
+		walkExpression(v, n.Message)
+
+	case *StampedeStatement:
+		if n.Call != nil {
+			Walk(v, n.Call)
+		}
+
+	case *DessertStatement:
+		if n.Call != nil {
+			Walk(v, n.Call)
+		}
+
+	case *SelectStatement:
+		for _, c := range n.Cases {
+			Walk(v, c)
+		}
+		if n.Default != nil {
+			Walk(v, n.Default)
+		}
+
+	case *SelectCase:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		if n.Call != nil {
+			Walk(v, n.Call)
+		}
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *IfStatement:
+		walkExpression(v, n.Condition)
+		if n.Consequence != nil {
+			Walk(v, n.Consequence)
+		}
+		if n.Alternative != nil {
+			Walk(v, n.Alternative)
+		}
+
+	case *IfExpression:
+		if n.If != nil {
+			Walk(v, n.If)
+		}
+
+	case *WhileLoop:
+		walkExpression(v, n.Condition)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *ForEachLoop:
+		Walk(v, n.Variable)
+		walkExpression(v, n.Iterable)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *UsingStatement:
+		Walk(v, n.Name)
+		walkExpression(v, n.Value)
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *FunctionDeclaration:
+		for _, d := range n.Decorators {
+			Walk(v, d)
+		}
+		Walk(v, n.Name)
+		for i, param := range n.Parameters {
+			Walk(v, param)
+			if i < len(n.ParameterTypes) && n.ParameterTypes[i] != nil {
+				Walk(v, n.ParameterTypes[i])
+			}
+		}
+		if n.ReturnType != nil {
+			Walk(v, n.ReturnType)
+		}
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *Decorator:
+		walkExpression(v, n.Expression)
+
+	case *FunctionCall:
+		walkExpression(v, n.Function)
+		for _, arg := range n.Arguments {
+			walkExpression(v, arg)
+		}
+
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+
+	case *ExpressionStatement:
+		walkExpression(v, n.Expression)
+
+	case *WrangleStatement:
+		if n.ModuleName != nil {
+			Walk(v, n.ModuleName)
+		}
+		if n.DataFile != nil {
+			Walk(v, n.DataFile)
+		}
+		if n.Alias != nil {
+			Walk(v, n.Alias)
+		}
+		for _, member := range n.Exposed {
+			Walk(v, member)
+		}
+
+	case *PastureStatement:
+		for _, name := range n.Names {
+			Walk(v, name)
+		}
+
+	case *ExposeStatement:
+		for _, module := range n.Modules {
+			Walk(v, module)
+		}
+
+	case *MemberAccessExpression:
+		walkExpression(v, n.Object)
+		if n.Member != nil {
+			Walk(v, n.Member)
+		}
+
+	case *ArrayLiteral:
+		for _, el := range n.Elements {
+			walkExpression(v, el)
+		}
+
+	case *HashLiteral:
+		for _, pair := range n.Pairs {
+			walkExpression(v, pair.Key)
+			walkExpression(v, pair.Value)
+		}
+
+	case *IndexExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Index)
+	}
+
+	v.Visit(nil)
+}
+
+// walkStatements walks a statement list, skipping nil entries.
+func walkStatements(v Visitor, stmts []Statement) {
+	for _, stmt := range stmts {
+		if stmt != nil {
+			Walk(v, stmt)
+		}
+	}
+}
+
+// walkExpression walks an optional expression. Parse errors can leave
+// expressions nil, so a nil child is skipped rather than visited.
+func walkExpression(v Visitor, expr Expression) {
+	if expr != nil {
+		Walk(v, expr)
+	}
+}
+
+// inspector adapts a plain function to the Visitor interface.
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order, calling f(node) for each
+// node. If f returns true, Inspect continues into the node's children, then
+// calls f(nil). Modeled on go/ast.Inspect.
+//
+// Example - collect every identifier read in a function body:
+//
+//	ast.Inspect(fn.Body, func(n ast.Node) bool {
+//	    if ident, ok := n.(*ast.Identifier); ok {
+//	        names = append(names, ident.Value)
+//	    }
+//	    return true
+//	})
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,216 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/elitwilson/beeflang/internal/token"
+	"github.com/stretchr/testify/assert"
+)
+
+// The walker tests parse real source, which the ast package can't import
+// without a cycle - hence the external test package.
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	assert.Empty(t, p.Errors(), "parser errors")
+	return program
+}
+
+func TestInspectVisitsEveryNodeInSourceOrder(t *testing.T) {
+	program := parse(t, `wrangle io as out
+praise add(a: int, b) -> int:
+   prep sum = a + b
+   if sum > 0:
+      out.preach(sum)
+   else:
+      sum = -sum
+   beef
+   serve sum
+beef`)
+
+	var kinds []string
+	ast.Inspect(program, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		switch node := n.(type) {
+		case *ast.Identifier:
+			kinds = append(kinds, "ident:"+node.Value)
+		case *ast.TypeAnnotation:
+			kinds = append(kinds, "type:"+node.Name)
+		case *ast.IntegerLiteral:
+			kinds = append(kinds, "int")
+		default:
+			kinds = append(kinds, n.TokenLiteral())
+		}
+		return true
+	})
+
+	assert.Equal(t, []string{
+		"wrangle", // Program (literal of its first statement)
+		"wrangle", "ident:io", "ident:out",
+		"praise", "ident:add", "ident:a", "type:int", "ident:b", "type:int",
+		":", // function body block
+		"prep", "ident:sum", "+", "ident:a", "ident:b",
+		"if", ">", "ident:sum", "int",
+		":", "out", "(", ".", "ident:out", "ident:preach", "ident:sum",
+		":", "sum", "ident:sum", "-", "ident:sum",
+		"serve", "ident:sum",
+	}, kinds)
+}
+
+func TestInspectVisitsSelectCases(t *testing.T) {
+	program := parse(t, `select:
+when msg = inbox.recv():
+   msg
+else:
+   0
+beef`)
+
+	var kinds []string
+	ast.Inspect(program, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Identifier); ok {
+			kinds = append(kinds, ident.Value)
+		} else if n != nil {
+			kinds = append(kinds, n.TokenLiteral())
+		}
+		return true
+	})
+
+	assert.Equal(t, []string{
+		"select", "select",
+		"when", "msg", "(", ".", "inbox", "recv", ":", "msg", "msg",
+		":", "0", "0",
+	}, kinds)
+}
+
+func TestInspectCanSkipChildren(t *testing.T) {
+	program := parse(t, `praise f(x):
+   serve inner
+beef
+outer`)
+
+	var idents []string
+	ast.Inspect(program, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FunctionDeclaration); ok {
+			return false // don't descend into the function
+		}
+		if ident, ok := n.(*ast.Identifier); ok {
+			idents = append(idents, ident.Value)
+		}
+		return true
+	})
+
+	assert.Equal(t, []string{"outer"}, idents)
+}
+
+// countingVisitor counts visits and how many times a node's children finished
+type countingVisitor struct {
+	visits, ends *int
+}
+
+func (v countingVisitor) Visit(n ast.Node) ast.Visitor {
+	if n == nil {
+		*v.ends++
+		return nil
+	}
+	*v.visits++
+	return v
+}
+
+func TestWalkCallsVisitNilAfterChildren(t *testing.T) {
+	program := parse(t, "prep x = 1 + 2")
+
+	visits, ends := 0, 0
+	ast.Walk(countingVisitor{&visits, &ends}, program)
+
+	// Program, VariableDeclaration, Identifier, InfixExpression, two IntegerLiterals
+	assert.Equal(t, 6, visits)
+	assert.Equal(t, visits, ends)
+}
+
+func TestWalkSkipsMissingChildren(t *testing.T) {
+	// Hand-built nodes with nil children must not panic
+	ifStmt := &ast.IfStatement{Token: token.Token{Type: token.IF, Literal: "if"}}
+	call := &ast.FunctionCall{Token: token.Token{Type: token.LPAREN, Literal: "("}}
+
+	assert.NotPanics(t, func() {
+		ast.Inspect(ifStmt, func(ast.Node) bool { return true })
+		ast.Inspect(call, func(ast.Node) bool { return true })
+	})
+}
+
+func TestStartPositions(t *testing.T) {
+	program := parse(t, `prep total = price * 2
+io.preach(total)`)
+
+	decl := program.Statements[0].(*ast.VariableDeclaration)
+	infix := decl.Value.(*ast.InfixExpression)
+	assert.Equal(t, token.Position{Line: 1, Column: 1, Offset: 0}, decl.Start())
+	// Infix expressions start at their left operand, not the operator
+	assert.Equal(t, token.Position{Line: 1, Column: 14, Offset: 13}, infix.Start())
+
+	// Calls and member access start at the callee / object
+	stmt := program.Statements[1].(*ast.ExpressionStatement)
+	assert.Equal(t, token.Position{Line: 2, Column: 1, Offset: 23}, stmt.Start())
+	assert.Equal(t, token.Position{Line: 2, Column: 1, Offset: 23}, stmt.Expression.Start())
+	assert.Equal(t, token.Position{Line: 1, Column: 1, Offset: 0}, program.Start())
+}
+
+func TestEndPositions(t *testing.T) {
+	input := `prep total = price * 2
+io.preach(total)
+if total > 1:
+   serve "big"
+else:
+   serve -total
+beef`
+	program := parse(t, input)
+
+	// End is exclusive: the source text of a node is input[Start().Offset:End().Offset]
+	text := func(n ast.Node) string {
+		return input[n.Start().Offset:n.End().Offset]
+	}
+
+	decl := program.Statements[0].(*ast.VariableDeclaration)
+	assert.Equal(t, "prep total = price * 2", text(decl))
+	assert.Equal(t, "price * 2", text(decl.Value))
+	assert.Equal(t, token.Position{Line: 1, Column: 23, Offset: 22}, decl.End())
+
+	call := program.Statements[1].(*ast.ExpressionStatement)
+	assert.Equal(t, "io.preach(total)", text(call))
+	member := call.Expression.(*ast.FunctionCall).Function
+	assert.Equal(t, "io.preach", text(member))
+
+	ifStmt := program.Statements[2].(*ast.IfStatement)
+	assert.Equal(t, input[strings.Index(input, "if"):], text(ifStmt))
+	assert.Equal(t, token.Position{Line: 7, Column: 5, Offset: len(input)}, ifStmt.End())
+	// A block cut short by 'else' ends where the 'else' starts
+	assert.Equal(t, ":\n   serve \"big\"\n", text(ifStmt.Consequence))
+
+	ret := ifStmt.Alternative.Statements[0].(*ast.ReturnStatement)
+	assert.Equal(t, "serve -total", text(ret))
+
+	assert.Equal(t, ifStmt.End(), program.End())
+}
+
+func TestWrangleEndPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"wrangle io", "wrangle io"},
+		{"wrangle io as out", "wrangle io as out"},
+		{"wrangle io expose preach, input", "wrangle io expose preach, input"},
+	}
+
+	for _, tt := range tests {
+		program := parse(t, tt.input+"  # trailing comment")
+		stmt := program.Statements[0]
+		assert.Equal(t, tt.expected, tt.input[stmt.Start().Offset:stmt.End().Offset])
+	}
+}
@@ -0,0 +1,118 @@
+// Package astcache keeps parsed modules on disk, so a large module (typically
+// generated data) is parsed once rather than on every run. Each entry is a
+// .beefc file named after a hash of the module's source and the conditions
+// its '#when' directives were read with: an edited module simply hashes to a
+// new entry, and no entry is ever stale.
+//
+// The cache only holds what running a module needs - its statements and doc
+// comment - not the comment layout the formatter uses.
+package astcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/lexer"
+)
+
+// Extension is the file extension of cache entries.
+const Extension = ".beefc"
+
+// format versions the encoding. It is part of every entry's hash, so bumping
+// it when the AST node types change makes entries written by an older
+// interpreter unreachable instead of decoding them wrongly.
+const format = 10
+
+// Dir is the directory entries are kept in; "" turns the cache off. main.go
+// sets it to a directory under the user's cache directory unless --no-cache
+// is given.
+var Dir string
+
+// entry is what a .beefc file holds.
+type entry struct {
+	Statements []ast.Statement
+	Doc        []*ast.Comment
+}
+
+// DefaultDir returns the cache directory for the current user, or "" if the
+// system doesn't have one.
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "beeflang")
+}
+
+// Path returns the file the entry for source, parsed with conditions, is
+// stored in.
+func Path(source []byte, conditions lexer.Conditions) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "beefc %d\n", format)
+	names := make([]string, 0, len(conditions))
+	for name := range conditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(h, "%s=%q\n", name, conditions[name])
+	}
+	h.Write(source)
+	return filepath.Join(Dir, hex.EncodeToString(h.Sum(nil))+Extension)
+}
+
+// Load returns the cached program for source, parsed with conditions, if there
+// is one. A missing or unreadable entry is a cache miss, never an error: the
+// caller parses instead.
+func Load(source []byte, conditions lexer.Conditions) (*ast.Program, bool) {
+	if Dir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(Path(source, conditions))
+	if err != nil {
+		return nil, false
+	}
+	var e entry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+		return nil, false
+	}
+	return &ast.Program{Statements: e.Statements, Doc: e.Doc}, true
+}
+
+// Store saves the program parsed from source with conditions. The entry is written to a
+// temporary file and renamed into place, so a concurrent Load never sees half
+// of it.
+func Store(source []byte, conditions lexer.Conditions, program *ast.Program) error {
+	if Dir == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry{Statements: program.Statements, Doc: program.Doc}); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(Dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(Dir, "*"+Extension+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), Path(source, conditions))
+}
@@ -0,0 +1,126 @@
+package astcache
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// A module using every kind of node
+const source = `# Cuts and prices.
+wrangle io as out expose preach
+wrangle chan
+
+prep cuts = ["brisket", "ribs"]
+prep prices = {"brisket": 12, "ribs": -9, "hog": 99999999999999999999n}
+prep sign = if cuts: "open" else: "closed" beef
+
+# Price of a cut, with tax.
+praise price(cut: string, tax) -> int:
+   assert prices[cut] > 0, "no price"
+   prep total = prices[cut]
+   prep done = false
+   if !done:
+      total = total + tax
+   else:
+      total = 0
+   beef
+   serve total
+beef
+
+@chan.traced
+praise pump(ch):
+   dessert out.preach("done")
+   using inbox = chan.new(1):
+      stampede ch.send(1)
+      select:
+      when msg = inbox.recv():
+         preach(msg)
+      else:
+         feast while false:
+         beef
+      beef
+   beef
+beef
+`
+
+func withDir(t *testing.T) {
+	old := Dir
+	Dir = t.TempDir()
+	t.Cleanup(func() { Dir = old })
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	assert.Empty(t, p.Errors())
+	return program
+}
+
+func TestStoreAndLoad(t *testing.T) {
+	withDir(t)
+	program := parse(t, source)
+
+	_, ok := Load([]byte(source), nil)
+	assert.False(t, ok, "nothing is cached yet")
+
+	assert.NoError(t, Store([]byte(source), nil, program))
+	loaded, ok := Load([]byte(source), nil)
+	if !ok {
+		t.Fatal("stored program was not loaded")
+	}
+	assert.Equal(t, shape(t, program.Statements), shape(t, loaded.Statements))
+	assert.Equal(t, program.Doc, loaded.Doc)
+}
+
+// shape renders nodes as JSON for comparison. Empty lists come back from the
+// cache as nil ones, which mean the same to every user of the AST, so both
+// are rendered as null.
+func shape(t *testing.T, nodes []ast.Statement) string {
+	data, err := json.Marshal(nodes)
+	assert.NoError(t, err)
+	return strings.ReplaceAll(string(data), "[]", "null")
+}
+
+func TestEditedSourceMisses(t *testing.T) {
+	withDir(t)
+	assert.NoError(t, Store([]byte(source), nil, parse(t, source)))
+
+	_, ok := Load([]byte(source+"prep extra = 1\n"), nil)
+	assert.False(t, ok)
+}
+
+func TestOtherConditionsMiss(t *testing.T) {
+	withDir(t)
+	debug := lexer.Conditions{"debug": "true", "platform": "linux"}
+	assert.NoError(t, Store([]byte(source), debug, parse(t, source)))
+
+	_, ok := Load([]byte(source), lexer.Conditions{"platform": "linux", "debug": "true"})
+	assert.True(t, ok, "the same conditions in any order hit")
+	_, ok = Load([]byte(source), lexer.Conditions{"platform": "linux"})
+	assert.False(t, ok)
+}
+
+func TestCorruptEntryMisses(t *testing.T) {
+	withDir(t)
+	assert.NoError(t, os.WriteFile(Path([]byte(source), nil), []byte("not gob"), 0o644))
+
+	_, ok := Load([]byte(source), nil)
+	assert.False(t, ok)
+}
+
+func TestDisabledCache(t *testing.T) {
+	old := Dir
+	Dir = ""
+	t.Cleanup(func() { Dir = old })
+
+	assert.NoError(t, Store([]byte(source), nil, parse(t, source)))
+	_, ok := Load([]byte(source), nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,147 @@
+package conformance
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the fixtures from the evaluator's results")
+
+// outcome is everything a backend must agree on about one run of a program.
+type outcome struct {
+	stdout   string
+	exitCode int
+	err      *object.Error // what stopped the program; nil if nothing did
+}
+
+// status renders how the run ended in the .status fixture format, or "" for
+// a clean exit.
+func (o outcome) status() string {
+	if o.exitCode == 0 && o.err == nil {
+		return ""
+	}
+	status := fmt.Sprintf("exit %d\n", o.exitCode)
+	if o.err != nil {
+		status += fmt.Sprintf("error %s %d:%d %s\n", o.err.Code, o.err.Line, o.err.Column, o.err.Message)
+	}
+	return status
+}
+
+// backends are the ways of running a program. Every one must produce the
+// fixtures' results for every program; the first is the reference -update uses.
+var backends = []struct {
+	name string
+	run  func(source string) outcome
+}{
+	{"evaluator", runEvaluator},
+}
+
+// lockedBuffer collects output written from several tasks at once.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// runEvaluator runs a program on the tree-walking evaluator.
+func runEvaluator(source string) outcome {
+	out := &lockedBuffer{}
+	stdout, stderr := evaluator.Default.Stdout, evaluator.Default.Stderr
+	evaluator.Default.Stdout, evaluator.Default.Stderr = out, out
+	defer func() { evaluator.Default.Stdout, evaluator.Default.Stderr = stdout, stderr }()
+
+	finish := func(result object.Object) outcome {
+		o := outcome{stdout: out.buf.String()}
+		if err, ok := result.(*object.Error); ok {
+			if code, isExit := evaluator.ExitCode(err); isExit {
+				o.exitCode = code
+			} else {
+				o.exitCode, o.err = 1, err
+			}
+		}
+		return o
+	}
+
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if errs := p.ParseErrors(); len(errs) > 0 {
+		return finish(&object.Error{Code: errs[0].Code, Message: errs[0].Message, Line: errs[0].Line, Column: errs[0].Column})
+	}
+
+	env := object.NewEnvironment()
+	result := evaluator.Eval(program, env)
+	if _, isError := result.(*object.Error); !isError {
+		result = object.NULL
+		if _, ok := env.Get(evaluator.DefaultEntryPoint); ok {
+			result = evaluator.CallEntryPoint(env)
+		}
+	}
+	return finish(result)
+}
+
+// readFixture returns a fixture file's contents, or "" if it doesn't exist.
+func readFixture(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ""
+	}
+	assert.NoError(t, err)
+	return string(data)
+}
+
+// writeFixture writes a fixture, or removes it when it would be empty.
+func writeFixture(t *testing.T, path, contents string, keepEmpty bool) {
+	if contents == "" && !keepEmpty {
+		os.Remove(path)
+		return
+	}
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+}
+
+func TestConformance(t *testing.T) {
+	programs, err := filepath.Glob(filepath.Join("testdata", "*.beef"))
+	assert.NoError(t, err)
+	if len(programs) == 0 {
+		t.Fatal("no programs in testdata")
+	}
+
+	for _, program := range programs {
+		base := strings.TrimSuffix(program, ".beef")
+		name := filepath.Base(base)
+		source, err := os.ReadFile(program)
+		assert.NoError(t, err)
+
+		if *update {
+			got := backends[0].run(string(source))
+			writeFixture(t, base+".stdout", got.stdout, true)
+			writeFixture(t, base+".status", got.status(), false)
+		}
+		wantStdout := readFixture(t, base+".stdout")
+		wantStatus := readFixture(t, base+".status")
+
+		for _, backend := range backends {
+			t.Run(name+"/"+backend.name, func(t *testing.T) {
+				got := backend.run(string(source))
+				assert.Equal(t, wantStdout, got.stdout, "stdout")
+				assert.Equal(t, wantStatus, got.status(), "status")
+			})
+		}
+	}
+}
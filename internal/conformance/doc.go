@@ -0,0 +1,15 @@
+// Package conformance holds Beeflang programs with the results they must
+// produce, and a test running each of them on every execution backend. Today
+// the tree-walking evaluator is the only backend; a bytecode VM will have to
+// match it program for program before it can replace it.
+//
+// Each program testdata/NAME.beef comes with fixtures:
+//   - NAME.stdout: everything the program prints
+//   - NAME.status: how it ends, when that isn't exit status 0 - the exit
+//     status on the first line ("exit 1"), then the error that stopped it, if
+//     any ("error BE0003 6:17 type mismatch: INTEGER + BOOLEAN")
+//
+// A program declaring ChurchOfBeef() runs it after the top level, as the CLI
+// does; any other program is a script. Run the test with -update to rewrite
+// the fixtures from the evaluator's results after a deliberate change.
+package conformance
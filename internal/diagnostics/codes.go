@@ -0,0 +1,759 @@
+package diagnostics
+
+import "sort"
+
+// Error codes give every kind of problem a stable, searchable identifier.
+// Codes are never reused or renumbered once released - add new ones at the end
+// of their range instead.
+//
+//	BE00xx - runtime (evaluator) errors
+//	BE01xx - syntax (parser) errors
+//	BE02xx - program setup errors (entry point, files)
+//	BE03xx - warnings from static analysis (vet)
+//	BE04xx - type checker errors (check)
+const (
+	CodeUnknownOperator        = "BE0001"
+	CodeIdentifierNotFound     = "BE0002"
+	CodeTypeMismatch           = "BE0003"
+	CodeNotAFunction           = "BE0004"
+	CodeModuleNotFound         = "BE0005"
+	CodePrivateMember          = "BE0006"
+	CodeNoSuchMember           = "BE0007"
+	CodeCircularWrangle        = "BE0008"
+	CodeModuleLoadFailed       = "BE0009"
+	CodeUndeclaredAssignment   = "BE0010"
+	CodeShadowedVariable       = "BE0011"
+	CodeNullArithmetic         = "BE0012"
+	CodeBadArgument            = "BE0013"
+	CodeClosedChannel          = "BE0014"
+	CodeBadSyncUse             = "BE0015"
+	CodeNotAChannel            = "BE0016"
+	CodeInterrupted            = "BE0017"
+	CodeDessertOutsideFunction = "BE0018"
+	CodeAssertionFailed        = "BE0019"
+	CodeBadIndex               = "BE0020"
+	CodeCapabilityDenied       = "BE0021"
+	CodeProcessFailed          = "BE0022"
+	CodeNetworkError           = "BE0023"
+	CodeBadResponse            = "BE0024"
+	CodeTemplateError          = "BE0025"
+	CodeExit                   = "BE0026"
+	CodeFileError              = "BE0027"
+	CodeYieldOutsideFunction   = "BE0028"
+	CodeNotIterable            = "BE0029"
+	CodeIntegerOverflow        = "BE0030"
+	CodeDivisionByZero         = "BE0031"
+	CodeBadCoroutineUse        = "BE0032"
+	CodePastureConflict        = "BE0033"
+	CodeExposeConflict         = "BE0034"
+	CodeWrongArity             = "BE0035"
+
+	CodeUnexpectedToken = "BE0101"
+	CodeNoPrefixParseFn = "BE0102"
+	CodeInvalidInteger  = "BE0103"
+	CodeNestingTooDeep  = "BE0104"
+	CodeInternalParser  = "BE0105"
+	CodeKeywordMisuse   = "BE0106"
+	CodeBadDirective    = "BE0107"
+
+	CodeNoEntryPoint         = "BE0201"
+	CodeUnreadableFile       = "BE0202"
+	CodeDuplicateDeclaration = "BE0203"
+
+	CodeUnusedVariable    = "BE0301"
+	CodeUnreachableCode   = "BE0302"
+	CodeConstantCondition = "BE0303"
+	CodeRedeclared        = "BE0304"
+
+	CodeAnnotationMismatch = "BE0401"
+	CodeUnknownType        = "BE0402"
+	CodeWrongArgumentCount = "BE0403"
+)
+
+// Explanation is the long-form documentation for an error code,
+// printed by `beeflang explain <code>`.
+type Explanation struct {
+	Code        string
+	Title       string
+	Description string
+}
+
+var explanations = map[string]Explanation{
+	CodeUnknownOperator: {
+		Code:  CodeUnknownOperator,
+		Title: "unknown operator",
+		Description: `An operator was used with operand types it doesn't support.
+
+Arithmetic works on integers, + also joins two strings, and == / != compare
+any two values of the same type. Everything else is an error:
+
+    prep x = true + false    # unknown operator: BOOLEAN + BOOLEAN
+    prep y = -"beef"         # unknown operator: -STRING
+
+Convert or restructure the values so both sides have a supported type.`,
+	},
+	CodeIdentifierNotFound: {
+		Code:  CodeIdentifierNotFound,
+		Title: "identifier not found",
+		Description: `A name was used that isn't defined in the current scope or any enclosing scope.
+
+    praise ChurchOfBeef():
+       io.preach(total)      # identifier not found: total
+    beef
+
+Common causes: a typo, using a variable before its 'prep' declaration, using a
+variable outside the block it was declared in (scoping is block-level), or
+forgetting to 'wrangle' a module before using it.
+
+When a name in scope, a built-in module's member or a keyword is close to the
+unknown name, the error suggests it:
+
+    preach("hi")                 # identifier not found: preach - did you mean
+                                 # io.preach? (after wrangle io)`,
+	},
+	CodeTypeMismatch: {
+		Code:  CodeTypeMismatch,
+		Title: "type mismatch",
+		Description: `An infix operator was applied to two values of different types.
+Beeflang never converts between types implicitly:
+
+    prep label = "HP: " + 10     # type mismatch: STRING + INTEGER
+    prep n = 5 + true            # type mismatch: INTEGER + BOOLEAN
+
+Make both operands the same type first.`,
+	},
+	CodeNotAFunction: {
+		Code:  CodeNotAFunction,
+		Title: "not a function",
+		Description: `Something that isn't a function was called with (...).
+
+    prep x = 5
+    x(1)                         # not a function: INTEGER
+
+Check that the name refers to a function declared with 'praise' (or a module member
+that is a function), and that it hasn't been reassigned to another value.`,
+	},
+	CodeModuleNotFound: {
+		Code:  CodeModuleNotFound,
+		Title: "module not found",
+		Description: `A 'wrangle' named a module that isn't built in and couldn't be found on disk.
+
+    wrangle butcher              # module not found: butcher, searched: ./butcher.beef
+
+Module files are looked up as <name>.beef in, in order: each --path directory,
+each BEEF_PATH entry, then the directory of the script being run. The error
+lists every location that was tried.`,
+	},
+	CodePrivateMember: {
+		Code:  CodePrivateMember,
+		Title: "private module member",
+		Description: `Module members whose names start with an underscore are private to the module.
+The module's own code can use them, but other files can't reach them:
+
+    wrangle kitchen
+    kitchen._season(4)           # member '_season' is private to module 'kitchen'
+
+Use the module's public functions instead, or rename the member if it is meant to be public.`,
+	},
+	CodeNoSuchMember: {
+		Code:  CodeNoSuchMember,
+		Title: "no such module member",
+		Description: `A 'wrangle ... expose' list named a member the module doesn't define.
+
+    wrangle io expose shout      # module 'io' has no member 'shout'
+
+With --strict, reading a missing member (io.shout) is this error too, instead
+of evaluating to NULL.
+
+Check the spelling, or look at the module source for the names it defines.`,
+	},
+	CodeCircularWrangle: {
+		Code:  CodeCircularWrangle,
+		Title: "circular wrangle",
+		Description: `A module wrangled itself, directly or through other modules, while it was still loading:
+
+    # a.beef                     # b.beef
+    wrangle b                    wrangle a
+
+Move the shared code into a third module that both can wrangle.
+
+A module with an init() function also can't be used until init() has finished,
+so the same error is reported when init() uses another module that uses the
+first one back, or when another task uses the module meanwhile.`,
+	},
+	CodeModuleLoadFailed: {
+		Code:  CodeModuleLoadFailed,
+		Title: "module failed to load",
+		Description: `A module file was found but couldn't be read or parsed. The message includes
+the module's own syntax errors - fix those in the module file.`,
+	},
+	CodeUndeclaredAssignment: {
+		Code:  CodeUndeclaredAssignment,
+		Title: "assignment to undeclared variable",
+		Description: `Reported with --strict only. Assigning to a name that was never declared
+normally creates a new variable, which hides typos:
+
+    prep total = 0
+    totl = total + 5             # assignment to undeclared variable 'totl'
+
+Declare new variables with 'prep'.`,
+	},
+	CodeShadowedVariable: {
+		Code:  CodeShadowedVariable,
+		Title: "shadowed variable",
+		Description: `Reported with --strict only. A 'prep' inside a function declared a variable
+with the same name as one in an enclosing scope, hiding the outer one:
+
+    prep count = 0
+    praise bump():
+       prep count = 1            # 'count' shadows a variable from an enclosing scope
+    beef
+
+Pick a different name for the inner variable.`,
+	},
+	CodeNullArithmetic: {
+		Code:  CodeNullArithmetic,
+		Title: "NULL used in arithmetic",
+		Description: `Reported with --strict only. NULL - usually the result of a function that
+doesn't 'serve' a value, or of a missing module member - was used with an
+arithmetic or ordering operator:
+
+    praise noValue():
+    beef
+    prep x = noValue() + 1       # NULL used in arithmetic: NULL + INTEGER
+
+Without --strict this is still an error, but reported as a plain type mismatch.`,
+	},
+	CodeBadArgument: {
+		Code:  CodeBadArgument,
+		Title: "bad argument to built-in function",
+		Description: `A built-in function (one provided by the interpreter, like chan.new or a
+channel's send) was called with the wrong number or kind of arguments.
+
+    wrangle chan
+    prep ch = chan.new("big")    # chan.new: capacity must be an INTEGER, got STRING
+    ch.send()                    # send takes 1 argument, got 0
+
+The message says what the function expected.`,
+	},
+	CodeClosedChannel: {
+		Code:  CodeClosedChannel,
+		Title: "closed channel",
+		Description: `A value was sent on a channel that had already been closed, or a channel was
+closed twice.
+
+    ch.close()
+    ch.send(1)                   # send on closed channel
+
+Close a channel once, from the task that sends on it, after its last send.
+Receiving from a closed channel is fine: recv() returns the values still
+buffered, then NULL.`,
+	},
+	CodeBadSyncUse: {
+		Code:  CodeBadSyncUse,
+		Title: "misused sync primitive",
+		Description: `A mutex or wait group from the sync module was used in a way that can't work:
+
+    prep lock = sync.mutex()
+    lock.unlock()                # unlock of unlocked mutex
+
+    prep group = sync.waitgroup()
+    group.done()                 # waitgroup count would go below zero
+
+Pair every unlock() with an earlier lock(), and call add(n) on a wait group
+before starting the n tasks that will each call done().`,
+	},
+	CodeNotAChannel: {
+		Code:  CodeNotAChannel,
+		Title: "not a channel",
+		Description: `A 'when' case of a select used something other than a channel:
+
+    prep inbox = 5
+    select:
+    when msg = inbox.recv():     # select needs a channel, got INTEGER
+       io.preach(msg)
+    beef
+
+Create channels with chan.new(), or time.after(ms) for a timeout.`,
+	},
+	CodeInterrupted: {
+		Code:  CodeInterrupted,
+		Title: "interrupted",
+		Description: `The program was stopped with Ctrl+C while it was running. The error points at
+the statement that was running, and unwinds the program like any other error
+so cleanup code still runs; the exit code is 130.
+
+A program stuck waiting (on a channel, or for input) can't unwind; press
+Ctrl+C a second time to exit straight away.`,
+	},
+	CodeDessertOutsideFunction: {
+		Code:  CodeDessertOutsideFunction,
+		Title: "dessert outside a function",
+		Description: `'dessert' schedules a call for when the enclosing function returns, so it has
+to be inside a function:
+
+    lock.lock()
+    dessert lock.unlock()        # 'dessert' can only be used inside a function
+
+Move the code into a function, such as ChurchOfBeef().`,
+	},
+	CodeAssertionFailed: {
+		Code:  CodeAssertionFailed,
+		Title: "assertion failed",
+		Description: `An 'assert' statement's condition was false (or NULL). The error quotes the
+condition, followed by the message if the assert has one:
+
+    prep total = 9
+    assert total == 10, "the pan holds 10"
+    # assertion failed: total == 10 - the pan holds 10
+
+Asserts state what must be true at that point of the program; fix whatever
+made the condition false rather than the assert.`,
+	},
+	CodeBadIndex: {
+		Code:  CodeBadIndex,
+		Title: "bad index",
+		Description: `A value was indexed with [...] in a way that can't work:
+
+    prep cuts = ["brisket", "ribs"]
+    cuts["first"]                # array index must be an INTEGER, got STRING
+    prep n = 5
+    n[0]                         # index operator not supported: INTEGER[INTEGER]
+    {[1]: "one"}                 # unusable as hash key: ARRAY
+
+Arrays are indexed by position, starting at 0. Hash keys can be strings,
+integers or booleans.`,
+	},
+	CodeCapabilityDenied: {
+		Code:  CodeCapabilityDenied,
+		Title: "capability denied",
+		Description: `The program used something the interpreter wasn't allowed to give it.
+
+    wrangle process
+    process.run("ls")            # process.run is not allowed; run with --allow-process ...
+
+Each way of reaching outside the interpreter is a capability that is off
+unless the interpreter is started with its flag (or an embedder allows it):
+
+    --allow-fs        the fs module (reading and writing files)
+    --allow-net       the net and http modules
+    --allow-process   the process module (running other programs)
+    --allow-env       os.getenv, os.expand
+    --allow-eval      meta.eval (running code from strings)
+    --allow-parallel  the parallel module (work spread over several CPUs)
+
+Running other programs lets a script do anything you can, so only allow what
+a script needs, and only for scripts you trust.`,
+	},
+	CodeProcessFailed: {
+		Code:  CodeProcessFailed,
+		Title: "process failed to start",
+		Description: `process.run couldn't start the command, usually because it isn't installed
+or isn't on PATH:
+
+    process.run("brisket-smoker")   # could not run brisket-smoker: exec: "brisket-smoker": executable file not found in $PATH
+
+A command that starts and then exits with a non-zero code is not an error;
+check the "code" entry of the result instead.`,
+	},
+	CodeNetworkError: {
+		Code:  CodeNetworkError,
+		Title: "network error",
+		Description: `A net operation failed. The message is the operating system's reason:
+
+    net.dial("localhost", 7777)  # dial tcp [::1]:7777: connect: connection refused
+    net.listen(80)               # listen tcp :80: bind: permission denied
+    conn.write("hi")             # ... use of closed network connection
+
+A connection closed by the other end is not an error: read() and
+read_line() return null once everything sent has been read.`,
+	},
+	CodeBadResponse: {
+		Code:  CodeBadResponse,
+		Title: "bad HTTP response",
+		Description: `An http.serve handler served something that isn't a response:
+
+    praise handle(req):
+      serve 404                        # an http handler must serve a HASH or a STRING, got INTEGER
+    beef
+
+Serve a hash with an optional "status" (an integer, 200 if left out),
+"headers" (a hash) and "body", or just a string to send it with status 200:
+
+    serve {"status": 404, "body": "no such cut"}
+
+The client gets a 500 response, and the error is reported without stopping
+the server.`,
+	},
+	CodeTemplateError: {
+		Code:  CodeTemplateError,
+		Title: "template error",
+		Description: `template.render was given a template it couldn't parse, or one that
+used data the hash doesn't have:
+
+    template.render("Hi {{.name", {})         # template:1: unclosed action
+    template.render("Hi {{.name}}", {})       # ... map has no entry for key "name"
+
+Templates use Go's text/template syntax: {{.key}} inserts a value,
+{{if .key}}...{{else}}...{{end}} chooses, and {{range .list}}{{.}}{{end}}
+repeats for each element.`,
+	},
+	CodeExit: {
+		Code:  CodeExit,
+		Title: "program exited",
+		Description: `Not a mistake: the program called os.exit (or flags.parse handled --help
+or a bad flag). The program unwinds the way it would for an error, so
+'dessert' calls and 'using' blocks still run, and then the interpreter exits
+with the code given:
+
+    wrangle os
+    os.exit(3)                   # exit status 3
+
+You only see this code where the exit can't end the program, such as in the
+REPL.`,
+	},
+	CodeFileError: {
+		Code:  CodeFileError,
+		Title: "file error",
+		Description: `The fs module couldn't read or write a file. The message is the operating
+system's reason:
+
+    wrangle fs
+    fs.read("missing.txt")       # open missing.txt: no such file or directory
+
+Check the path (relative paths start from the directory the interpreter runs
+in) and its permissions. fs.exists tells whether a file is there first.`,
+	},
+	CodeYieldOutsideFunction: {
+		Code:  CodeYieldOutsideFunction,
+		Title: "yield outside a function",
+		Description: `'yield' hands a value to the loop consuming a generator, so it has to be
+inside the generator function producing the values:
+
+    yield 1                      # 'yield' can only be used inside a function
+
+Move it into a function; calling that function returns a generator to loop
+over with 'feast value in ...'.`,
+	},
+	CodeNotIterable: {
+		Code:  CodeNotIterable,
+		Title: "value can't be looped over",
+		Description: `A 'feast ... in' loop was given a value that doesn't hold a sequence of values.
+Loops run over an array's elements, a hash's keys and a generator's values:
+
+    feast letter in "abc":       # cannot loop over STRING
+       io.preach(letter)
+    beef
+
+Put the values in an array, or write a generator function that yields them.`,
+	},
+	CodeIntegerOverflow: {
+		Code:  CodeIntegerOverflow,
+		Title: "integer overflow",
+		Description: `Integers are 64 bits, from -9223372036854775808 to 9223372036854775807.
+Arithmetic whose result falls outside that range stops with an error instead
+of wrapping around to a wrong answer:
+
+    prep score = 9223372036854775807
+    score + 1                    # integer overflow: 9223372036854775807 + 1
+
+Check for values growing without bound, such as a score multiplied every
+turn. Numbers that really do get that large can be big integers, written with
+an n, which have no limit:
+
+    prep score = 9223372036854775807n
+    score + 1                    # 9223372036854775808`,
+	},
+	CodeDivisionByZero: {
+		Code:  CodeDivisionByZero,
+		Title: "division by zero",
+		Description: `An integer was divided by 0, or '%' was given 0 on the right:
+
+    prep guests = 0
+    12 / guests                  # division by zero: 12 / 0
+
+Check the divisor first:
+
+    if guests != 0:
+      prep per_guest = 12 / guests
+    beef`,
+	},
+	CodeBadCoroutineUse: {
+		Code:  CodeBadCoroutineUse,
+		Title: "misused coroutine",
+		Description: `A coroutine from the coroutine module was resumed when it couldn't carry on,
+or yielded from outside its body:
+
+    prep intro = coroutine.new(play_intro)
+    feast while true:
+      intro.resume()             # resume of finished coroutine, once it ends
+    beef
+
+Check done() before resuming a coroutine that may have finished, and only
+call yield() from the function the coroutine runs (or the functions it
+calls).`,
+	},
+	CodePastureConflict: {
+		Code:  CodePastureConflict,
+		Title: "pasture conflict",
+		Description: `A wrangled module's file starts with 'pasture', so its members join a
+namespace, but the namespace can't take them: the name is already something
+else, or another module in the same pasture has a member of the same name.
+
+    prep mygame = 5
+    wrangle pathing              # pathing.beef says: pasture mygame.ai
+                                 # mygame is already taken by a value of type INTEGER
+
+Rename the variable or the member, or wrangle the module under a name of its
+own (wrangle pathing as pathing), which skips the namespace.`,
+	},
+	CodeExposeConflict: {
+		Code:  CodeExposeConflict,
+		Title: "expose conflict",
+		Description: `'expose' copies the members of a wrangled module into the file, but one of
+them has the name of something the file already defines, or of a member of a
+module exposed before it:
+
+    wrangle math
+    wrangle vec
+    expose math
+    expose vec                   # can't expose vec: 'lerp' is already defined here
+
+Expose only some members instead (wrangle vec expose add, scale), or leave the
+module to be reached by its own name (std.vec.lerp).`,
+	},
+	CodeWrongArity: {
+		Code:  CodeWrongArity,
+		Title: "wrong number of arguments",
+		Description: `A function declared with 'praise' was called with more or fewer arguments
+than it has parameters. This is the same mistake 'beeflang check' reports as
+BE0403, found while the program runs: through a pipe, which passes the value
+as the first argument, a task started with 'stampede', or a builtin such as
+cache.memoize calling the function for you.
+
+    praise add(a, b):
+       serve a + b
+    beef
+    prep x = 5 |> add              # add() takes 2 arguments, got 1`,
+	},
+	CodeUnexpectedToken: {
+		Code:  CodeUnexpectedToken,
+		Title: "unexpected token",
+		Description: `The parser needed a specific token next and found something else.
+
+    prep = 5                     # expected next token to be IDENT, got = instead
+    praise greet(name)           # expected next token to be :, got ... instead
+
+Function, loop and conditional headers end with ':', declarations need a name
+and '=', and calls need a closing ')'.`,
+	},
+	CodeNoPrefixParseFn: {
+		Code:  CodeNoPrefixParseFn,
+		Title: "cannot start an expression",
+		Description: `A token appeared where an expression was expected, but no expression can start with it.
+
+    prep x = * 5                 # no prefix parse function for * found
+
+This usually follows another syntax error, or means an operand is missing.`,
+	},
+	CodeInvalidInteger: {
+		Code:  CodeInvalidInteger,
+		Title: "invalid integer literal",
+		Description: `An integer literal couldn't be converted to a number - usually because it is
+larger than a 64-bit integer can hold (9223372036854775807).`,
+	},
+	CodeNestingTooDeep: {
+		Code:  CodeNestingTooDeep,
+		Title: "nesting too deep",
+		Description: `Expressions and blocks can nest at most 1000 levels deep, counting every
+operator in a chain like 1 + 2 + 3 as one level. Parsing stops at the first
+place the limit is exceeded.
+
+Real programs never come close; this usually means the file is generated or
+isn't Beeflang at all. Split long expressions using intermediate variables:
+
+    prep subtotal = a + b + c
+    prep total = subtotal + d + e`,
+	},
+	CodeInternalParser: {
+		Code:  CodeInternalParser,
+		Title: "internal parser error",
+		Description: `The parser hit a bug while reading this file. Parsing stopped at the reported
+position instead of crashing. Please report the error together with the input
+that caused it.`,
+	},
+	CodeKeywordMisuse: {
+		Code:  CodeKeywordMisuse,
+		Title: "keyword misused",
+		Description: `A statement starts with a misspelled keyword, or a keyword is used where a
+name or a value belongs:
+
+    serv total                   # unknown statement 'serv' - did you mean 'serve'?
+    prep in = 5                  # 'in' is a keyword and can't be used as a name
+    prep x = serve               # 'serve' starts a statement and can't be used as a value
+
+Fix the spelling, or pick a different name: keywords such as prep, serve, in
+and yield are reserved.`,
+	},
+	CodeBadDirective: {
+		Code:  CodeBadDirective,
+		Title: "malformed directive",
+		Description: `A #when, #else or #end line is wrong: a condition that can't be read, or
+directives that don't pair up.
+
+    #when platform = "windows"   # "platform = \"windows\"" is not a #when condition
+    #else debug                  # #else takes no condition
+    #end debug                   # #end takes nothing after it
+
+A condition is a name (#when debug), !name, name == "value" or name != "value".
+Every #when needs an #end, with at most one #else between them. Outside a
+#when block, #else and #end lines are ordinary comments.`,
+	},
+	CodeNoEntryPoint: {
+		Code:  CodeNoEntryPoint,
+		Title: "missing entry point",
+		Description: `Programs start by calling the ChurchOfBeef() function (or the one named
+with --entry), and none was found, it isn't a function, or it takes
+parameters that the command line didn't supply.
+
+    praise ChurchOfBeef():
+       # your program starts here
+    beef
+
+For a quick script, run it with --script instead: its top-level statements
+then run in order and no entry point is needed. Code given with -e or piped
+to stdin always runs that way.`,
+	},
+	CodeUnreadableFile: {
+		Code:        CodeUnreadableFile,
+		Title:       "unreadable source file",
+		Description: `The source file given on the command line doesn't exist or couldn't be read.`,
+	},
+	CodeDuplicateDeclaration: {
+		Code:  CodeDuplicateDeclaration,
+		Title: "duplicate declaration",
+		Description: `A program run from several files (go run . src/, or go run . a.beef b.beef)
+declares the same name at the top level of more than one of them:
+
+    # a.beef
+    praise helper():
+    beef
+
+    # b.beef
+    praise helper():             # 'helper' is already declared at a.beef:1:8
+    beef
+
+The files share one environment, so the second declaration would silently
+replace the first. Rename one of them, or move shared code into a module and
+wrangle it.`,
+	},
+	CodeUnusedVariable: {
+		Code:  CodeUnusedVariable,
+		Title: "unused variable",
+		Description: `A variable declared with 'prep' inside a function is never read.
+Assigning to it again doesn't count - only using its value does.
+
+    praise total(a, b):
+       prep sum = a + b        # variable 'sum' is declared but never used
+       serve a + b
+    beef
+
+Remove the variable, or use it. Prefix the name with an underscore (_sum) to
+keep it and silence the warning. Top-level variables are never reported,
+because other files may read them after wrangling the module.`,
+	},
+	CodeUnreachableCode: {
+		Code:  CodeUnreachableCode,
+		Title: "unreachable code",
+		Description: `Statements after a 'serve' in the same block can never run, because 'serve'
+leaves the function immediately.
+
+    praise half(n):
+       serve n / 2
+       io.preach("halved")     # unreachable code after 'serve'
+    beef
+
+Move the statements before the 'serve', or delete them.`,
+	},
+	CodeConstantCondition: {
+		Code:  CodeConstantCondition,
+		Title: "constant condition",
+		Description: `An 'if' or 'feast while' condition is built only from literals, so it has the
+same value every time and one of the branches is dead (or the loop never runs).
+
+    if 1 > 2:                  # 'if' condition is always the same value
+       io.preach("never")
+    beef
+
+'feast while true' is not reported: it is the way to write a loop that ends with 'serve'.`,
+	},
+	CodeRedeclared: {
+		Code:  CodeRedeclared,
+		Title: "name declared twice",
+		Description: `The same block declares a function or variable name a second time, so the
+second declaration silently replaces the first:
+
+    praise price():
+       serve 12
+    beef
+    praise price():            # 'price' is already declared at line 1, col 8
+       serve 15
+    beef
+
+Rename one of them, or assign to the existing variable (name = value) if
+replacing it was the point. With --strict this is an error and the program
+doesn't run. Declarations in different blocks, such as the two branches of an
+'if', don't clash.`,
+	},
+	CodeAnnotationMismatch: {
+		Code:  CodeAnnotationMismatch,
+		Title: "value doesn't match type annotation",
+		Description: `A value whose type is known doesn't match the annotation it is used with.
+Reported by the 'check' subcommand for variables, parameters and return values:
+
+    prep hp: int = "full"          # cannot initialize 'hp' (int) with a string value
+
+    praise double(n: int) -> int:
+       serve n * 2
+    beef
+    double("two")                  # argument 1: cannot use a string value as int
+
+Annotations are optional, and values whose type can't be worked out statically
+(module members, unannotated parameters) are always accepted.`,
+	},
+	CodeUnknownType: {
+		Code:  CodeUnknownType,
+		Title: "unknown type",
+		Description: `A type annotation names a type that doesn't exist.
+The available types are int, bool, string, null, fn and any.
+
+    prep name: str = "Beef"        # unknown type 'str'`,
+	},
+	CodeWrongArgumentCount: {
+		Code:  CodeWrongArgumentCount,
+		Title: "wrong number of arguments",
+		Description: `A function declared with 'praise' was called with more or fewer arguments
+than it has parameters.
+
+    praise add(a, b):
+       serve a + b
+    beef
+    add(1)                         # wrong number of arguments: want 2, got 1`,
+	},
+}
+
+// Explain returns the long-form explanation for an error code.
+func Explain(code string) (Explanation, bool) {
+	exp, ok := explanations[code]
+	return exp, ok
+}
+
+// AllExplanations returns every known error code's explanation, sorted by code.
+func AllExplanations() []Explanation {
+	all := make([]Explanation, 0, len(explanations))
+	for _, exp := range explanations {
+		all = append(all, exp)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Code < all[j].Code })
+	return all
+}
@@ -0,0 +1,64 @@
+// Package diagnostics is the common shape for everything the toolchain reports
+// about a program: parser errors, runtime errors, type errors and warnings.
+//
+// Each stage produces errors in its own native form (parser.ParseError,
+// object.Error); the CLI converts them into Diagnostic records so they can be
+// rendered consistently - as human-readable text or as machine-readable JSON
+// for editors and CI.
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Severity says how serious a diagnostic is.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Diagnostic is one reported problem with its source location.
+// Line and Column are 1-based; 0 means "no specific position" (e.g. a missing entry point).
+// EndLine and EndColumn, when known, mark the position just past the offending
+// source text, so editors can underline the whole range.
+type Diagnostic struct {
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	Column    int      `json:"column"`
+	EndLine   int      `json:"endLine,omitempty"`
+	EndColumn int      `json:"endColumn,omitempty"`
+	Severity  Severity `json:"severity"`
+	Message   string   `json:"message"`
+	Code      string   `json:"code,omitempty"`
+}
+
+// String renders the diagnostic in the conventional compiler style:
+// "file:line:col: severity: message"
+func (d Diagnostic) String() string {
+	location := d.File
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+	severity := string(d.Severity)
+	if d.Code != "" {
+		severity = fmt.Sprintf("%s[%s]", d.Severity, d.Code)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, severity, d.Message)
+}
+
+// WriteJSON writes diagnostics as JSON Lines: one JSON object per line.
+// Each line is a complete record, so consumers can stream them without
+// waiting for (or buffering) the whole output.
+func WriteJSON(w io.Writer, diags []Diagnostic) error {
+	enc := json.NewEncoder(w)
+	for _, d := range diags {
+		if err := enc.Encode(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package diagnostics
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosticString(t *testing.T) {
+	d := Diagnostic{File: "main.beef", Line: 3, Column: 7, Severity: Error, Message: "identifier not found: x"}
+	assert.Equal(t, "main.beef:3:7: error: identifier not found: x", d.String())
+
+	// No position: just the file
+	d = Diagnostic{File: "main.beef", Severity: Error, Message: "no entry point"}
+	assert.Equal(t, "main.beef: error: no entry point", d.String())
+}
+
+func TestWriteJSONEmitsOneRecordPerLine(t *testing.T) {
+	diags := []Diagnostic{
+		{File: "a.beef", Line: 1, Column: 2, Severity: Error, Message: "first"},
+		{File: "b.beef", Line: 3, Column: 4, Severity: Warning, Message: "second", Code: "BE0001"},
+	}
+
+	var buf bytes.Buffer
+	err := WriteJSON(&buf, diags)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var first map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "a.beef", first["file"])
+	assert.Equal(t, float64(1), first["line"])
+	assert.Equal(t, float64(2), first["column"])
+	assert.Equal(t, "error", first["severity"])
+	assert.Equal(t, "first", first["message"])
+	_, hasCode := first["code"]
+	assert.False(t, hasCode, "empty code should be omitted")
+
+	var second Diagnostic
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, diags[1], second)
+}
+
+func TestExplainKnownCode(t *testing.T) {
+	exp, ok := Explain(CodeTypeMismatch)
+	assert.True(t, ok)
+	assert.Equal(t, CodeTypeMismatch, exp.Code)
+	assert.Equal(t, "type mismatch", exp.Title)
+	assert.NotEmpty(t, exp.Description)
+
+	_, ok = Explain("BE9999")
+	assert.False(t, ok)
+}
+
+func TestEveryCodeHasAnExplanation(t *testing.T) {
+	codes := []string{
+		CodeUnknownOperator, CodeIdentifierNotFound, CodeTypeMismatch, CodeNotAFunction,
+		CodeModuleNotFound, CodePrivateMember, CodeNoSuchMember, CodeCircularWrangle,
+		CodeModuleLoadFailed, CodeUndeclaredAssignment, CodeShadowedVariable, CodeNullArithmetic,
+		CodeBadArgument, CodeClosedChannel, CodeBadSyncUse, CodeNotAChannel, CodeInterrupted, CodeDessertOutsideFunction, CodeAssertionFailed, CodeBadIndex, CodeCapabilityDenied, CodeProcessFailed, CodeNetworkError, CodeBadResponse, CodeTemplateError, CodeExit, CodeFileError, CodeYieldOutsideFunction, CodeNotIterable, CodeIntegerOverflow, CodeDivisionByZero, CodeBadCoroutineUse, CodePastureConflict, CodeExposeConflict, CodeWrongArity,
+		CodeUnexpectedToken, CodeNoPrefixParseFn, CodeInvalidInteger, CodeNestingTooDeep, CodeInternalParser, CodeKeywordMisuse, CodeBadDirective,
+		CodeNoEntryPoint, CodeUnreadableFile, CodeDuplicateDeclaration, CodeUnusedVariable, CodeUnreachableCode,
+		CodeConstantCondition, CodeRedeclared, CodeAnnotationMismatch, CodeUnknownType, CodeWrongArgumentCount,
+	}
+	for _, code := range codes {
+		exp, ok := Explain(code)
+		assert.True(t, ok, "missing explanation for %s", code)
+		assert.Equal(t, code, exp.Code)
+	}
+
+	// AllExplanations is sorted by code
+	all := AllExplanations()
+	assert.Len(t, all, len(codes))
+	for i := 1; i < len(all); i++ {
+		assert.Less(t, all[i-1].Code, all[i].Code)
+	}
+}
+
+func TestDiagnosticStringWithCode(t *testing.T) {
+	d := Diagnostic{File: "main.beef", Line: 3, Column: 7, Severity: Error, Message: "identifier not found: x", Code: CodeIdentifierNotFound}
+	assert.Equal(t, "main.beef:3:7: error[BE0002]: identifier not found: x", d.String())
+}
+
+func TestWriteJSONIncludesEndPositionWhenKnown(t *testing.T) {
+	diags := []Diagnostic{
+		{File: "a.beef", Line: 2, Column: 5, EndLine: 2, EndColumn: 11, Severity: Error, Message: "ranged"},
+		{File: "a.beef", Severity: Error, Message: "no position"},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteJSON(&buf, diags))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	var ranged map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &ranged))
+	assert.Equal(t, float64(2), ranged["endLine"])
+	assert.Equal(t, float64(11), ranged["endColumn"])
+
+	var bare map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &bare))
+	_, hasEnd := bare["endLine"]
+	assert.False(t, hasEnd, "unknown end position should be omitted")
+}
@@ -0,0 +1,153 @@
+package diagnostics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ANSI escape sequences used for colored output.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+)
+
+// Renderer formats diagnostics for people: the diagnostic line, then the
+// source line it points at with the offending text underlined by carets.
+//
+//	hello.beef:4:9: warning[BE0301]: variable 'total' is declared but never used
+//	 4 |    prep total = 5
+//	   |         ^^^^^
+//
+// With Color set, severities, locations and carets are colored with ANSI
+// escapes; see ShouldColor for when that is appropriate.
+type Renderer struct {
+	Color bool
+
+	// Source returns line n (1-based, without its newline) of file, for the
+	// excerpt under a diagnostic. When nil, or when it reports false, the
+	// excerpt is left out.
+	Source func(file string, n int) (string, bool)
+}
+
+// Render formats one diagnostic, ending with a newline.
+func (r Renderer) Render(d Diagnostic) string {
+	var b strings.Builder
+
+	location := d.File
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+	severity := string(d.Severity)
+	if d.Code != "" {
+		severity = fmt.Sprintf("%s[%s]", d.Severity, d.Code)
+	}
+	color := r.severityColor(d.Severity)
+	fmt.Fprintf(&b, "%s: %s: %s\n",
+		r.paint(ansiBold, location), r.paint(ansiBold+color, severity), r.paint(ansiBold, d.Message))
+
+	if d.Line > 0 && d.Column > 0 && r.Source != nil {
+		if line, ok := r.Source(d.File, d.Line); ok {
+			r.excerpt(&b, d, line, color)
+		}
+	}
+	return b.String()
+}
+
+// excerpt writes the source line and a caret line underneath it spanning the
+// diagnostic's range. Ranges that run onto later lines are underlined to the
+// end of the first line.
+func (r Renderer) excerpt(b *strings.Builder, d Diagnostic, line string, color string) {
+	line = strings.TrimRight(line, "\r")
+	start := d.Column - 1
+	if start > len(line) {
+		start = len(line)
+	}
+	end := start + 1
+	if d.EndLine == d.Line && d.EndColumn-1 > start {
+		end = d.EndColumn - 1
+	} else if d.EndLine > d.Line {
+		end = len(line)
+	}
+	if end > len(line) && start < len(line) {
+		end = len(line)
+	}
+	if end <= start {
+		end = start + 1
+	}
+
+	// Keep tabs in the padding so the carets line up with the text above
+	padding := []byte(line[:start])
+	for i, ch := range padding {
+		if ch != '\t' {
+			padding[i] = ' '
+		}
+	}
+
+	number := fmt.Sprintf("%d", d.Line)
+	gutter := strings.Repeat(" ", len(number))
+	fmt.Fprintf(b, "%s %s\n", r.paint(ansiBlue, " "+number+" |"), line)
+	fmt.Fprintf(b, "%s %s%s\n", r.paint(ansiBlue, " "+gutter+" |"), padding,
+		r.paint(ansiBold+color, strings.Repeat("^", end-start)))
+}
+
+// Result formats a value echoed back to the user, like a REPL result.
+func (r Renderer) Result(text string) string {
+	return r.paint(ansiGreen, text)
+}
+
+func (r Renderer) severityColor(s Severity) string {
+	if s == Warning {
+		return ansiYellow
+	}
+	return ansiRed
+}
+
+// paint wraps text in an ANSI style when color is on.
+func (r Renderer) paint(style string, text string) string {
+	if !r.Color || text == "" {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+// ShouldColor reports whether output written to f should be colored: only
+// when f is a terminal, and the NO_COLOR environment variable
+// (https://no-color.org) is unset or empty.
+func ShouldColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ReadSourceLine returns line n (1-based) of a file, for Renderer.Source.
+// The file is read afresh, so this is only meant for reporting.
+func ReadSourceLine(file string, n int) (string, bool) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for i := 1; ; i++ {
+		line, err := r.ReadString('\n')
+		if i == n {
+			if err != nil && err != io.EOF {
+				return "", false
+			}
+			return strings.TrimSuffix(line, "\n"), err == nil || line != ""
+		}
+		if err != nil {
+			return "", false
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package diagnostics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper function returning a Renderer.Source serving fixed lines for any file
+func sourceLines(lines ...string) func(string, int) (string, bool) {
+	return func(file string, n int) (string, bool) {
+		if n < 1 || n > len(lines) {
+			return "", false
+		}
+		return lines[n-1], true
+	}
+}
+
+func TestRenderUnderlinesTheSpan(t *testing.T) {
+	r := Renderer{Source: sourceLines("praise f():", "\tprep total = 5")}
+	d := Diagnostic{File: "main.beef", Line: 2, Column: 7, EndLine: 2, EndColumn: 12,
+		Severity: Warning, Message: "variable 'total' is declared but never used", Code: "BE0301"}
+
+	expected := "main.beef:2:7: warning[BE0301]: variable 'total' is declared but never used\n" +
+		" 2 | \tprep total = 5\n" +
+		"   | \t     ^^^^^\n"
+	assert.Equal(t, expected, r.Render(d))
+}
+
+func TestRenderCaretSpans(t *testing.T) {
+	r := Renderer{Source: sourceLines("prep x = 1 +")}
+	tests := []struct {
+		name     string
+		d        Diagnostic
+		expected string
+	}{
+		{"no end", Diagnostic{Line: 1, Column: 6}, "     ^"},
+		{"runs onto later lines", Diagnostic{Line: 1, Column: 10, EndLine: 3, EndColumn: 2}, "         ^^^"},
+		{"past the end of the line", Diagnostic{Line: 1, Column: 13, EndLine: 1, EndColumn: 14}, "            ^"},
+	}
+
+	for _, tt := range tests {
+		tt.d.File, tt.d.Severity, tt.d.Message = "x.beef", Error, "oops"
+		out := r.Render(tt.d)
+		assert.Contains(t, out, "   | "+tt.expected+"\n", tt.name)
+	}
+}
+
+func TestRenderWithoutSource(t *testing.T) {
+	d := Diagnostic{File: "main.beef", Line: 3, Column: 7, Severity: Error, Message: "identifier not found: x"}
+	assert.Equal(t, d.String()+"\n", Renderer{}.Render(d))
+
+	// Problems without a position have no excerpt either
+	d = Diagnostic{File: "main.beef", Severity: Error, Message: "no entry point", Code: "BE0201"}
+	r := Renderer{Source: sourceLines("x")}
+	assert.Equal(t, "main.beef: error[BE0201]: no entry point\n", r.Render(d))
+}
+
+func TestRenderColor(t *testing.T) {
+	r := Renderer{Color: true, Source: sourceLines("nope")}
+	d := Diagnostic{File: "<repl>", Line: 1, Column: 1, EndLine: 1, EndColumn: 5,
+		Severity: Error, Message: "identifier not found: nope", Code: "BE0002"}
+
+	expected := "\x1b[1m<repl>:1:1\x1b[0m: \x1b[1m\x1b[31merror[BE0002]\x1b[0m: \x1b[1midentifier not found: nope\x1b[0m\n" +
+		"\x1b[34m 1 |\x1b[0m nope\n" +
+		"\x1b[34m   |\x1b[0m \x1b[1m\x1b[31m^^^^\x1b[0m\n"
+	assert.Equal(t, expected, r.Render(d))
+
+	d.Severity = Warning
+	assert.Contains(t, r.Render(d), "\x1b[33mwarning[BE0002]")
+
+	assert.Equal(t, "\x1b[32m42\x1b[0m", r.Result("42"))
+	assert.Equal(t, "42", Renderer{}.Result("42"))
+}
+
+func TestShouldColor(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	// A plain file is not a terminal
+	assert.False(t, ShouldColor(f))
+
+	// NO_COLOR wins even for a terminal
+	if tty, err := os.Open("/dev/tty"); err == nil {
+		defer tty.Close()
+		t.Setenv("NO_COLOR", "1")
+		assert.False(t, ShouldColor(tty))
+	}
+}
+
+func TestReadSourceLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.beef")
+	assert.NoError(t, os.WriteFile(path, []byte("first\nsecond\r\nlast"), 0644))
+
+	line, ok := ReadSourceLine(path, 1)
+	assert.True(t, ok)
+	assert.Equal(t, "first", line)
+
+	line, ok = ReadSourceLine(path, 3)
+	assert.True(t, ok)
+	assert.Equal(t, "last", line)
+
+	_, ok = ReadSourceLine(path, 4)
+	assert.False(t, ok)
+
+	_, ok = ReadSourceLine(filepath.Join(t.TempDir(), "missing.beef"), 1)
+	assert.False(t, ok)
+}
@@ -0,0 +1,40 @@
+package diagnostics
+
+// MaxTypoEdits is how many edits (see EditDistance) a suggestion may be from
+// a misspelled name: short names only get close matches, so "x" doesn't
+// suggest "io".
+func MaxTypoEdits(name string) int {
+	switch {
+	case len(name) <= 2:
+		return 0
+	case len(name) <= 5:
+		return 1
+	}
+	return 2
+}
+
+// EditDistance counts the fewest single-byte insertions, deletions,
+// substitutions and swaps of neighbouring bytes turning a into b, so the
+// usual typos - a missed, doubled or transposed letter - are one edit each.
+func EditDistance(a, b string) int {
+	// Rows i-2, i-1 and i of the distance table
+	before, prev, cur := make([]int, len(b)+1), make([]int, len(b)+1), make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], before[j-2]+1)
+			}
+		}
+		before, prev, cur = prev, cur, before
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,21 @@
+package diagnostics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, EditDistance("beef", "beef"))
+	assert.Equal(t, 1, EditDistance("serv", "serve"))
+	assert.Equal(t, 1, EditDistance("totla", "total"))
+	assert.Equal(t, 2, EditDistance("brsikte", "brisket"))
+	assert.Equal(t, 3, EditDistance("", "rib"))
+}
+
+func TestMaxTypoEdits(t *testing.T) {
+	assert.Equal(t, 0, MaxTypoEdits("io"))
+	assert.Equal(t, 1, MaxTypoEdits("iff"))
+	assert.Equal(t, 2, MaxTypoEdits("brisket"))
+}
@@ -0,0 +1,165 @@
+// Package docgen builds reference documentation for Beeflang source files
+// from their doc comments.
+//
+// A doc comment is a block of '#' lines directly above a 'praise' (no blank
+// line between), or a block opening the file and followed by a blank line,
+// which documents the module as a whole:
+//
+//	# Helpers for counting beef.
+//
+//	# add sums two amounts of beef.
+//	praise add(a: int, b: int) -> int:
+//	    serve a + b
+//	beef
+//
+// A function can instead open its body with a docstring, a string literal
+// that documents it; a docstring wins over a doc comment:
+//
+//	praise add(a: int, b: int) -> int:
+//	    "add sums two amounts of beef."
+//	    serve a + b
+//	beef
+//
+// Only public top-level functions are listed - nested functions and names
+// starting with an underscore aren't reachable from other files, so they
+// aren't part of a module's API.
+package docgen
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+)
+
+// Module is the documentation for one source file.
+type Module struct {
+	Name      string
+	Doc       string
+	Functions []Function
+}
+
+// Function is the documentation for one top-level function.
+type Function struct {
+	Name    string
+	Params  []Param
+	Returns string // return type annotation; empty when not annotated
+	Doc     string
+	Line    int
+}
+
+// Param is a function parameter and its type annotation (empty when not annotated).
+type Param struct {
+	Name string
+	Type string
+}
+
+// New collects the documentation for a parsed program. The program must have
+// been parsed without errors, since doc comments are only attached then.
+func New(name string, program *ast.Program) *Module {
+	mod := &Module{Name: name, Doc: ast.CommentText(program.Doc)}
+	for _, stmt := range program.Statements {
+		fn, ok := stmt.(*ast.FunctionDeclaration)
+		if !ok || strings.HasPrefix(fn.Name.Value, "_") {
+			continue
+		}
+		f := Function{
+			Name: fn.Name.Value,
+			Doc:  fn.DocText(),
+			Line: fn.Token.Line,
+		}
+		for i, param := range fn.Parameters {
+			p := Param{Name: param.Value}
+			if i < len(fn.ParameterTypes) && fn.ParameterTypes[i] != nil {
+				p.Type = fn.ParameterTypes[i].Name
+			}
+			f.Params = append(f.Params, p)
+		}
+		if fn.ReturnType != nil {
+			f.Returns = fn.ReturnType.Name
+		}
+		mod.Functions = append(mod.Functions, f)
+	}
+	return mod
+}
+
+// Signature renders the function the way it is declared: "add(a: int, b) -> int"
+func (f Function) Signature() string {
+	params := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		params[i] = p.Name
+		if p.Type != "" {
+			params[i] += ": " + p.Type
+		}
+	}
+	sig := fmt.Sprintf("%s(%s)", f.Name, strings.Join(params, ", "))
+	if f.Returns != "" {
+		sig += " -> " + f.Returns
+	}
+	return sig
+}
+
+// WriteMarkdown writes the modules as a Markdown document, one section per module.
+// Doc text is copied as-is, so doc comments may themselves use Markdown.
+func WriteMarkdown(w io.Writer, modules []*Module) error {
+	var b strings.Builder
+	for i, mod := range modules {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# %s\n\n", mod.Name)
+		if mod.Doc != "" {
+			fmt.Fprintf(&b, "%s\n\n", mod.Doc)
+		}
+		if len(mod.Functions) == 0 {
+			b.WriteString("No functions.\n")
+			continue
+		}
+		b.WriteString("## Functions\n")
+		for _, f := range mod.Functions {
+			fmt.Fprintf(&b, "\n### %s\n\n", f.Name)
+			fmt.Fprintf(&b, "```\npraise %s\n```\n", f.Signature())
+			if f.Doc != "" {
+				fmt.Fprintf(&b, "\n%s\n", f.Doc)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteHTML writes the modules as a standalone HTML page.
+// Doc text is escaped; blank lines in it separate paragraphs.
+func WriteHTML(w io.Writer, modules []*Module) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<title>Beeflang documentation</title>\n</head>\n<body>\n")
+	for _, mod := range modules {
+		fmt.Fprintf(&b, "<h1>%s</h1>\n", html.EscapeString(mod.Name))
+		writeParagraphs(&b, mod.Doc)
+		if len(mod.Functions) == 0 {
+			b.WriteString("<p>No functions.</p>\n")
+			continue
+		}
+		b.WriteString("<h2>Functions</h2>\n")
+		for _, f := range mod.Functions {
+			fmt.Fprintf(&b, "<h3 id=\"%s\">%s</h3>\n", html.EscapeString(f.Name), html.EscapeString(f.Name))
+			fmt.Fprintf(&b, "<pre><code>praise %s</code></pre>\n", html.EscapeString(f.Signature()))
+			writeParagraphs(&b, f.Doc)
+		}
+	}
+	b.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeParagraphs writes doc text as <p> elements, split on blank lines.
+func writeParagraphs(b *strings.Builder, doc string) {
+	for _, para := range strings.Split(doc, "\n\n") {
+		if para = strings.TrimSpace(para); para != "" {
+			fmt.Fprintf(b, "<p>%s</p>\n", html.EscapeString(para))
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package docgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper function to parse source and collect its documentation
+func document(t *testing.T, name, input string) *Module {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	assert.Empty(t, p.Errors(), "parser errors")
+	return New(name, program)
+}
+
+const kitchen = `# Kitchen helpers.
+
+# add sums two amounts of beef.
+# Both must be <int>s.
+praise add(a: int, b: int) -> int:
+   serve a + b
+beef
+
+praise _season(x):
+   serve x
+beef
+
+prep cuts = 3
+
+praise cook(cut, heat):
+   praise inner():
+      serve 1
+   beef
+   serve cut
+beef
+`
+
+func TestNewCollectsPublicTopLevelFunctions(t *testing.T) {
+	mod := document(t, "kitchen", kitchen)
+
+	assert.Equal(t, "kitchen", mod.Name)
+	assert.Equal(t, "Kitchen helpers.", mod.Doc)
+	if assert.Len(t, mod.Functions, 2) {
+		add := mod.Functions[0]
+		assert.Equal(t, "add", add.Name)
+		assert.Equal(t, []Param{{"a", "int"}, {"b", "int"}}, add.Params)
+		assert.Equal(t, "int", add.Returns)
+		assert.Equal(t, "add sums two amounts of beef.\nBoth must be <int>s.", add.Doc)
+		assert.Equal(t, 5, add.Line)
+
+		cook := mod.Functions[1]
+		assert.Equal(t, "cook", cook.Name)
+		assert.Empty(t, cook.Doc)
+	}
+}
+
+func TestDocstringsAreDocumentation(t *testing.T) {
+	mod := document(t, "kitchen", `praise smoke(cut):
+   "smoke cooks a cut low and slow."
+   serve cut
+beef`)
+
+	if assert.Len(t, mod.Functions, 1) {
+		assert.Equal(t, "smoke cooks a cut low and slow.", mod.Functions[0].Doc)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	tests := []struct {
+		fn       Function
+		expected string
+	}{
+		{Function{Name: "main"}, "main()"},
+		{Function{Name: "cook", Params: []Param{{Name: "cut"}, {Name: "heat"}}}, "cook(cut, heat)"},
+		{Function{Name: "add", Params: []Param{{"a", "int"}, {Name: "b"}}, Returns: "int"}, "add(a: int, b) -> int"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.fn.Signature())
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var out strings.Builder
+	err := WriteMarkdown(&out, []*Module{document(t, "kitchen", kitchen)})
+	assert.NoError(t, err)
+
+	expected := "# kitchen\n\n" +
+		"Kitchen helpers.\n\n" +
+		"## Functions\n\n" +
+		"### add\n\n" +
+		"```\npraise add(a: int, b: int) -> int\n```\n\n" +
+		"add sums two amounts of beef.\nBoth must be <int>s.\n\n" +
+		"### cook\n\n" +
+		"```\npraise cook(cut, heat)\n```\n"
+	assert.Equal(t, expected, out.String())
+}
+
+func TestWriteHTMLEscapesDocText(t *testing.T) {
+	var out strings.Builder
+	err := WriteHTML(&out, []*Module{document(t, "kitchen", kitchen)})
+	assert.NoError(t, err)
+
+	html := out.String()
+	assert.Contains(t, html, "<h1>kitchen</h1>")
+	assert.Contains(t, html, "<pre><code>praise add(a: int, b: int) -&gt; int</code></pre>")
+	assert.Contains(t, html, "<p>add sums two amounts of beef.\nBoth must be &lt;int&gt;s.</p>")
+	assert.NotContains(t, html, "_season")
+}
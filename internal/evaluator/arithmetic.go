@@ -0,0 +1,39 @@
+package evaluator
+
+import "math"
+
+// Integer arithmetic is checked: a result that doesn't fit in 64 bits is an
+// error rather than silently wrapping around. Each function reports whether
+// its result fits.
+
+func checkedAdd(a, b int64) (int64, bool) {
+	sum := a + b
+	return sum, (sum > a) == (b > 0)
+}
+
+func checkedSub(a, b int64) (int64, bool) {
+	diff := a - b
+	return diff, (diff < a) == (b > 0)
+}
+
+func checkedMul(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return product, false
+	}
+	return product, product/b == a
+}
+
+func checkedDiv(a, b int64) (int64, bool) {
+	if a == math.MinInt64 && b == -1 {
+		return a, false
+	}
+	return a / b, true
+}
+
+func checkedNeg(a int64) (int64, bool) {
+	return -a, a != math.MinInt64
+}
@@ -0,0 +1,315 @@
+package evaluator
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+func createArrayModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "array",
+		Members: make(map[string]object.Object),
+	}
+
+	// sort - a sorted copy of an array of integers or of strings:
+	//   array.sort([3, 1, 2])  # [1, 2, 3]
+	mod.Set("sort", arrayBuiltin("array.sort", 0, func(arr *object.Array, args []object.Object) object.Object {
+		return sortArray(arr, func(a, b object.Object) (int, *object.Error) {
+			return compareValues("array.sort", a, b)
+		})
+	}))
+
+	// sort_by - a sorted copy of an array, ordered by a function. Given a
+	// function of one argument, elements are ordered by what it returns
+	// for them (an integer or a string); given one of two arguments, it
+	// compares a and b, returning a negative integer if a comes first, a
+	// positive one if b does, and 0 if either may:
+	//   array.sort_by(orders, get_weight)
+	//   array.sort_by(orders, by_weight_then_name)
+	mod.Set("sort_by", arrayBuiltin("array.sort_by", 1, func(arr *object.Array, args []object.Object) object.Object {
+		fn := args[0]
+		if f, ok := fn.(*object.Function); ok {
+			switch len(f.Parameters) {
+			case 1:
+			case 2:
+				return sortArray(arr, func(a, b object.Object) (int, *object.Error) {
+					return callComparator(fn, a, b)
+				})
+			default:
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("array.sort_by: expected a function of 1 or 2 parameters, got %d", len(f.Parameters))}
+			}
+		}
+		return sortByKey(arr, fn)
+	}))
+
+	// push - add a value to the end of an array
+	mod.Set("push", arrayBuiltin("array.push", 1, func(arr *object.Array, args []object.Object) object.Object {
+		arr.Push(args[0])
+		return object.NULL
+	}))
+
+	// pop - remove and return the last element, or null if there is none
+	mod.Set("pop", arrayBuiltin("array.pop", 0, func(arr *object.Array, args []object.Object) object.Object {
+		var last object.Object = object.NULL
+		arr.Update(func(elements []object.Object) []object.Object {
+			n := len(elements)
+			if n == 0 {
+				return elements
+			}
+			last = elements[n-1]
+			elements[n-1] = nil
+			return elements[:n-1]
+		})
+		return last
+	}))
+
+	// shift - remove and return the first element, or null if there is none
+	mod.Set("shift", arrayBuiltin("array.shift", 0, func(arr *object.Array, args []object.Object) object.Object {
+		var first object.Object = object.NULL
+		arr.Update(func(elements []object.Object) []object.Object {
+			if len(elements) == 0 {
+				return elements
+			}
+			first = elements[0]
+			return slices.Delete(elements, 0, 1)
+		})
+		return first
+	}))
+
+	// insert - put a value at an index, moving later elements along.
+	// Inserting at the array's length, or at -1, adds to the end.
+	mod.Set("insert", arrayBuiltin("array.insert", 2, func(arr *object.Array, args []object.Object) object.Object {
+		var err *object.Error
+		arr.Update(func(elements []object.Object) []object.Object {
+			var i int
+			if i, err = arrayIndex("array.insert", args[0], len(elements), len(elements)+1); err != nil {
+				return elements
+			}
+			return slices.Insert(elements, i, args[1])
+		})
+		if err != nil {
+			return err
+		}
+		return object.NULL
+	}))
+
+	// remove - remove and return the element at an index; negative
+	// indexes count from the end, as with items[-1]
+	mod.Set("remove", arrayBuiltin("array.remove", 1, func(arr *object.Array, args []object.Object) object.Object {
+		var removed object.Object
+		var err *object.Error
+		arr.Update(func(elements []object.Object) []object.Object {
+			var i int
+			if i, err = arrayIndex("array.remove", args[0], len(elements), len(elements)); err != nil {
+				return elements
+			}
+			removed = elements[i]
+			return slices.Delete(elements, i, i+1)
+		})
+		if err != nil {
+			return err
+		}
+		return removed
+	}))
+
+	// index_of - the index of the first element equal to a value, or -1.
+	// Values are compared as by the in operator.
+	mod.Set("index_of", arrayBuiltin("array.index_of", 1, func(arr *object.Array, args []object.Object) object.Object {
+		for i, el := range arr.Elements() {
+			if sameValue(el, args[0]) {
+				return &object.Integer{Value: int64(i)}
+			}
+		}
+		return &object.Integer{Value: -1}
+	}))
+
+	// contains - whether an array has an element equal to a value
+	mod.Set("contains", arrayBuiltin("array.contains", 1, func(arr *object.Array, args []object.Object) object.Object {
+		return nativeBoolToBooleanObject(slices.ContainsFunc(arr.Elements(), func(el object.Object) bool {
+			return sameValue(el, args[0])
+		}))
+	}))
+
+	// reverse - a reversed copy of an array
+	mod.Set("reverse", arrayBuiltin("array.reverse", 0, func(arr *object.Array, args []object.Object) object.Object {
+		reversed := arr.Elements()
+		slices.Reverse(reversed)
+		return object.NewArray(reversed)
+	}))
+
+	// slice - a copy of the elements from start up to, but not including,
+	// end. Negative positions count from the end, and positions past
+	// either end are clamped:
+	//   array.slice([1, 2, 3, 4], 1, -1)  # [2, 3]
+	mod.Set("slice", arrayBuiltin("array.slice", 2, func(arr *object.Array, args []object.Object) object.Object {
+		start, err := object.IntegerArg("array.slice", args[0])
+		if err != nil {
+			return err
+		}
+		end, err := object.IntegerArg("array.slice", args[1])
+		if err != nil {
+			return err
+		}
+		elements := arr.Elements()
+		n := int64(len(elements))
+		start, end = clampPosition(start, n), clampPosition(end, n)
+		if start >= end {
+			return object.NewArray([]object.Object{})
+		}
+		return object.NewArray(elements[start:end])
+	}))
+
+	return mod
+}
+
+// arrayBuiltin makes a builtin that takes an array followed by argc more
+// arguments.
+func arrayBuiltin(name string, argc int, fn func(arr *object.Array, args []object.Object) object.Object) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount(name, args, argc+1); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("%s: expected an ARRAY, got %s", name, args[0].Type())}
+			}
+			return fn(arr, args[1:])
+		},
+	}
+}
+
+// arrayIndex checks an index argument against an array of length n, where
+// limit is one past the largest index allowed. Negative indexes count back
+// from limit.
+func arrayIndex(name string, arg object.Object, n, limit int) (int, *object.Error) {
+	i, err := object.IntegerArg(name, arg)
+	if err != nil {
+		return 0, err
+	}
+	index := i
+	if index < 0 {
+		index += int64(limit)
+	}
+	if index < 0 || index >= int64(limit) {
+		return 0, &object.Error{Code: diagnostics.CodeBadIndex,
+			Message: fmt.Sprintf("%s: index %d is out of range for an array of length %d", name, i, n)}
+	}
+	return int(index), nil
+}
+
+// clampPosition resolves a slice position, which may be negative to count
+// from the end, to one between 0 and n.
+func clampPosition(pos, n int64) int64 {
+	if pos < 0 {
+		pos += n
+	}
+	return max(0, min(pos, n))
+}
+
+// sortArray returns a copy of arr stably sorted by compare, or the first
+// error compare returned.
+func sortArray(arr *object.Array, compare func(a, b object.Object) (int, *object.Error)) object.Object {
+	sorted := arr.Elements()
+
+	var failed *object.Error
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if failed != nil {
+			return false
+		}
+		order, err := compare(sorted[i], sorted[j])
+		if err != nil {
+			failed = err
+			return false
+		}
+		return order < 0
+	})
+	if failed != nil {
+		return failed
+	}
+	return object.NewArray(sorted)
+}
+
+// sortByKey sorts a copy of arr by the keys keyFn gives its elements. Each
+// element's key is worked out once.
+func sortByKey(arr *object.Array, keyFn object.Object) object.Object {
+	type keyed struct{ key, el object.Object }
+	elements := arr.Elements()
+	pairs := make([]keyed, len(elements))
+	for i, el := range elements {
+		key := applyFunction(token.Token{}, keyFn, []object.Object{el})
+		if isError(key) {
+			return key
+		}
+		pairs[i] = keyed{key, el}
+	}
+
+	var failed *object.Error
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if failed != nil {
+			return false
+		}
+		order, err := compareValues("array.sort_by", pairs[i].key, pairs[j].key)
+		if err != nil {
+			failed = err
+			return false
+		}
+		return order < 0
+	})
+	if failed != nil {
+		return failed
+	}
+
+	sorted := make([]object.Object, len(pairs))
+	for i, p := range pairs {
+		sorted[i] = p.el
+	}
+	return object.NewArray(sorted)
+}
+
+// callComparator calls a comparison function for sort_by.
+func callComparator(fn, a, b object.Object) (int, *object.Error) {
+	result := applyFunction(token.Token{}, fn, []object.Object{a, b})
+	if errObj, ok := result.(*object.Error); ok {
+		return 0, errObj
+	}
+	n, ok := result.(*object.Integer)
+	if !ok {
+		return 0, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("array.sort_by: the comparison function must serve an INTEGER, got %s", result.Type())}
+	}
+	return int(max(min(n.Value, 1), -1)), nil
+}
+
+// compareValues orders two integers or two strings.
+func compareValues(name string, a, b object.Object) (int, *object.Error) {
+	switch a := a.(type) {
+	case *object.Integer:
+		if b, ok := b.(*object.Integer); ok {
+			return compareOrdered(a.Value, b.Value), nil
+		}
+	case *object.String:
+		if b, ok := b.(*object.String); ok {
+			return compareOrdered(a.Value, b.Value), nil
+		}
+	}
+	return 0, &object.Error{Code: diagnostics.CodeBadArgument,
+		Message: fmt.Sprintf("%s: can only compare integers with integers and strings with strings, got %s and %s", name, a.Type(), b.Type())}
+}
+
+func compareOrdered[T int64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,167 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArraySort(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`array.sort([3, -1, 2, 0])`, "[-1, 0, 2, 3]"},
+		{`array.sort(["rib", "brisket", "chuck"])`, `["brisket", "chuck", "rib"]`},
+		{`array.sort([])`, "[]"},
+		{`prep a = [2, 1]
+prep b = array.sort(a)
+a`, "[2, 1]"},
+		// Key function
+		{`praise lbs(cut):
+  serve cut[1]
+beef
+array.sort_by([["rib", 3], ["chuck", 1], ["brisket", 3], ["flank", 2]], lbs)`,
+			`[["chuck", 1], ["flank", 2], ["rib", 3], ["brisket", 3]]`},
+		// Comparator
+		{`praise desc(a, b):
+  serve b - a
+beef
+array.sort_by([1, 3, 2], desc)`, "[3, 2, 1]"},
+		{`praise by_len(a, b):
+  serve 0
+beef
+array.sort_by(["c", "a", "b"], by_len)`, `["c", "a", "b"]`},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle array\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestArraySortErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		code     string
+	}{
+		{`array.sort([1, "two"])`, "array.sort: can only compare integers with integers and strings with strings", diagnostics.CodeBadArgument},
+		{`array.sort("rib")`, "array.sort: expected an ARRAY, got STRING", diagnostics.CodeBadArgument},
+		{`praise name(a, b):
+  serve "x"
+beef
+array.sort_by([1, 2], name)`, "array.sort_by: the comparison function must serve an INTEGER, got STRING", diagnostics.CodeBadArgument},
+		{`praise key():
+  serve 1
+beef
+array.sort_by([1, 2], key)`, "array.sort_by: expected a function of 1 or 2 parameters, got 0", diagnostics.CodeBadArgument},
+		{`praise key(a):
+  serve [a]
+beef
+array.sort_by([1, 2], key)`, "array.sort_by: can only compare integers with integers and strings with strings, got ARRAY and ARRAY", diagnostics.CodeBadArgument},
+		{`praise key(a):
+  serve a + "x"
+beef
+array.sort_by([1, 2], key)`, "type mismatch: INTEGER + STRING", diagnostics.CodeTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle array\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !assert.True(t, ok, "%s: got %s", tt.input, result.Inspect()) {
+			continue
+		}
+		assert.Contains(t, errObj.Message, tt.expected, tt.input)
+		assert.Equal(t, tt.code, errObj.Code, tt.input)
+	}
+}
+
+func TestArrayMutation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`prep a = [1, 2]
+array.push(a, 3)
+a`, "[1, 2, 3]"},
+		{`prep a = [1, 2, 3]
+prep last = array.pop(a)
+[last, a]`, "[3, [1, 2]]"},
+		{`array.pop([])`, "null"},
+		{`prep a = [1, 2, 3]
+prep first = array.shift(a)
+[first, a]`, "[1, [2, 3]]"},
+		{`array.shift([])`, "null"},
+		{`prep a = ["rib", "chuck"]
+array.insert(a, 1, "brisket")
+array.insert(a, 3, "flank")
+array.insert(a, 0, "tri-tip")
+a`, `["tri-tip", "rib", "brisket", "chuck", "flank"]`},
+		{`prep a = [1, 2]
+array.insert(a, -1, 3)
+a`, "[1, 2, 3]"},
+		{`prep a = [1, 2, 3, 4]
+prep gone = array.remove(a, 1)
+prep last = array.remove(a, -1)
+[gone, last, a]`, "[2, 4, [1, 3]]"},
+		{`array.index_of(["rib", "chuck", "rib"], "rib")`, "0"},
+		{`array.index_of([1, 2], "1")`, "-1"},
+		{`array.contains([1, 2], 2)`, "true"},
+		{`array.contains([[1]], [1])`, "false"},
+		{`prep a = [1, 2, 3]
+prep r = array.reverse(a)
+[r, a]`, "[[3, 2, 1], [1, 2, 3]]"},
+		{`array.slice([1, 2, 3, 4], 1, 3)`, "[2, 3]"},
+		{`array.slice([1, 2, 3, 4], 1, -1)`, "[2, 3]"},
+		{`array.slice([1, 2, 3, 4], -2, 10)`, "[3, 4]"},
+		{`array.slice([1, 2, 3, 4], 3, 1)`, "[]"},
+		// Mutation is seen through every reference to the array
+		{`praise add_cut(cuts):
+  array.push(cuts, "brisket")
+beef
+prep cuts = []
+add_cut(cuts)
+cuts`, `["brisket"]`},
+		// An array or hash inside itself shows as [...] or {...}
+		{`prep a = [1]
+array.push(a, a)
+a`, "[1, [...]]"},
+		{`prep list = []
+prep owner = {"list": list}
+array.push(list, owner)
+owner`, `{"list": [{...}]}`},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle array\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestArrayMutationErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		code     string
+	}{
+		{`array.insert([1], 2, 0)`, "array.insert: index 2 is out of range for an array of length 1", diagnostics.CodeBadIndex},
+		{`array.remove([1], 1)`, "array.remove: index 1 is out of range for an array of length 1", diagnostics.CodeBadIndex},
+		{`array.remove([], -1)`, "array.remove: index -1 is out of range for an array of length 0", diagnostics.CodeBadIndex},
+		{`array.remove([1], "0")`, "array.remove: expected an INTEGER, got STRING", diagnostics.CodeBadArgument},
+		{`array.push({}, 1)`, "array.push: expected an ARRAY, got HASH", diagnostics.CodeBadArgument},
+		{`array.slice([1], 0)`, "array.slice takes 3 arguments, got 2", diagnostics.CodeBadArgument},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle array\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !assert.True(t, ok, "%s: got %s", tt.input, result.Inspect()) {
+			continue
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+		assert.Equal(t, tt.code, errObj.Code, tt.input)
+	}
+}
@@ -0,0 +1,159 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+)
+
+// Benchmarks for the evaluator's hot paths. Compare a change against them with
+//
+//	go test ./internal/evaluator -run '^$' -bench . -benchmem -count 10 > old.txt
+//	(make the change, then the same into new.txt)
+//	benchstat old.txt new.txt
+//
+// Baseline, Go 1.27 on linux/amd64 (Intel Xeon, 1 CPU):
+//
+//	BenchmarkFib                  20 ms/op   10070534 B/op   186078 allocs/op
+//	BenchmarkStringBuilding      1.5 ms/op    2740716 B/op     6006 allocs/op
+//	BenchmarkLoop                4.6 ms/op     480472 B/op    60007 allocs/op
+//	BenchmarkMapFilter           2.7 ms/op    1005033 B/op    21983 allocs/op
+//	BenchmarkEnvironmentLookup   1.0 ms/op      66144 B/op     8028 allocs/op
+//
+// Only the run is timed: each program is parsed once, then evaluated in a
+// fresh environment per iteration.
+
+// benchmarkProgram parses source, then evaluates it b.N times.
+func benchmarkProgram(b *testing.B, source string) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if errs := p.ParseErrors(); len(errs) > 0 {
+		b.Fatal(errs[0].String())
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runBenchmarkProgram(b, program)
+	}
+}
+
+func runBenchmarkProgram(b *testing.B, program *ast.Program) {
+	if err, ok := Eval(program, NewEnvironment()).(*object.Error); ok {
+		b.Fatal(err.Message)
+	}
+}
+
+// Function calls: recursion, argument binding and returns
+func BenchmarkFib(b *testing.B) {
+	benchmarkProgram(b, `
+praise fib(n):
+   if n < 2:
+      serve n
+   beef
+   serve fib(n - 1) + fib(n - 2)
+beef
+fib(20)
+`)
+}
+
+// String concatenation in a loop, which copies the string every time
+func BenchmarkStringBuilding(b *testing.B) {
+	benchmarkProgram(b, `
+prep s = ""
+prep i = 0
+feast while i < 1000:
+   s = s + "beef "
+   i = i + 1
+beef
+`)
+}
+
+// Integer arithmetic, comparisons and assignment with no calls
+func BenchmarkLoop(b *testing.B) {
+	benchmarkProgram(b, `
+prep total = 0
+prep i = 0
+feast while i < 10000:
+   total = total + i % 7
+   i = i + 1
+beef
+`)
+}
+
+// Higher-order functions over an array: indexing and calls through
+// function values. Arrays can't grow or report their length yet, so map and
+// filter take the length and fold as they go.
+func BenchmarkMapFilter(b *testing.B) {
+	benchmarkProgram(b, `
+praise double(x):
+   serve x * 2
+beef
+
+praise is_even(x):
+   serve x % 2 == 0
+beef
+
+praise map_sum(items, n, f):
+   prep total = 0
+   prep i = 0
+   feast while i < n:
+      total = total + f(items[i])
+      i = i + 1
+   beef
+   serve total
+beef
+
+praise filter_count(items, n, keep):
+   prep count = 0
+   prep i = 0
+   feast while i < n:
+      if keep(items[i]):
+         count = count + 1
+      beef
+      i = i + 1
+   beef
+   serve count
+beef
+
+prep cuts = [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20]
+prep round = 0
+feast while round < 50:
+   map_sum(cuts, 20, double)
+   filter_count(cuts, 20, is_even)
+   round = round + 1
+beef
+`)
+}
+
+// Reading names through a chain of enclosing environments
+func BenchmarkEnvironmentLookup(b *testing.B) {
+	benchmarkProgram(b, `
+prep a = 1
+prep b = 2
+prep c = 3
+
+praise outer():
+   prep d = 4
+   praise middle():
+      prep e = 5
+      praise inner():
+         prep total = 0
+         prep i = 0
+         feast while i < 1000:
+            total = total + a + b + c + d + e
+            i = i + 1
+         beef
+         serve total
+      beef
+      serve inner()
+   beef
+   serve middle()
+beef
+
+outer()
+`)
+}
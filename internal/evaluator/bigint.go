@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"math/big"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// isBigArithmetic reports whether an infix expression works on big integers:
+// both sides are integers, and at least one is big.
+func isBigArithmetic(left, right object.Object) bool {
+	_, leftBig := left.(*object.BigInteger)
+	_, rightBig := right.(*object.BigInteger)
+	return (leftBig || rightBig) && isInteger(left) && isInteger(right)
+}
+
+func isInteger(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Integer, *object.BigInteger:
+		return true
+	}
+	return false
+}
+
+// bigValue returns an integer or big integer as a big.Int, which must not be
+// changed.
+func bigValue(obj object.Object) *big.Int {
+	if n, ok := obj.(*object.Integer); ok {
+		return big.NewInt(n.Value)
+	}
+	return obj.(*object.BigInteger).Value
+}
+
+// evalBigIntegerInfixExpression handles arithmetic and comparison where
+// either side is a big integer. The result of arithmetic is always big, so
+// a value that starts big never overflows.
+func evalBigIntegerInfixExpression(tok token.Token, operator string, left, right object.Object) object.Object {
+	leftVal, rightVal := bigValue(left), bigValue(right)
+
+	switch operator {
+	// Arithmetic
+	case "+":
+		return &object.BigInteger{Value: new(big.Int).Add(leftVal, rightVal)}
+	case "-":
+		return &object.BigInteger{Value: new(big.Int).Sub(leftVal, rightVal)}
+	case "*":
+		return &object.BigInteger{Value: new(big.Int).Mul(leftVal, rightVal)}
+	case "/", "%":
+		if rightVal.Sign() == 0 {
+			return newError(tok, diagnostics.CodeDivisionByZero, "division by zero: %s %s 0", leftVal, operator)
+		}
+		// Quo and Rem round toward zero, as integer / and % do
+		if operator == "/" {
+			return &object.BigInteger{Value: new(big.Int).Quo(leftVal, rightVal)}
+		}
+		return &object.BigInteger{Value: new(big.Int).Rem(leftVal, rightVal)}
+
+	// Comparison
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+
+	default:
+		return newError(tok, diagnostics.CodeUnknownOperator, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
@@ -0,0 +1,71 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBigIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"123n", "123"},
+		{"9223372036854775807n + 1", "9223372036854775808"},
+		{"1 + 9223372036854775807n", "9223372036854775808"},
+		{"99999999999999999999n * 99999999999999999999n", "9999999999999999999800000000000000000001"},
+		{"5n - 7", "-2"},
+		{"-7n / 2", "-3"},
+		{"-7n % 2", "-1"},
+		{"prep n = 5n\nprep m = -n\nm", "-5"},
+		{"10n > 9", "true"},
+		{"10n == 10", "true"},
+		{"10n != 10n", "false"},
+		{"99999999999999999999n <= 1", "false"},
+		// Big integers stay big, so a loop can keep growing one
+		{`prep score = 1n
+prep i = 0
+feast while i < 100:
+  score = score * 2
+  i = i + 1
+beef
+score`, "1267650600228229401496703205376"},
+		{`wrangle io
+io.format("{:>25}", 12345678901234567890n)`, "     12345678901234567890"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+
+	big, ok := testEval("1n + 1").(*object.BigInteger)
+	if assert.True(t, ok, "mixing in a big integer gives a big integer") {
+		assert.Equal(t, "BIGINT", big.Type())
+	}
+}
+
+func TestBigIntegerErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		code     string
+	}{
+		{"5n / 0", "division by zero: 5 / 0", diagnostics.CodeDivisionByZero},
+		{"5 % 0n", "division by zero: 5 % 0", diagnostics.CodeDivisionByZero},
+		{"5n + \"x\"", "type mismatch: BIGINT + STRING", diagnostics.CodeTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		errObj, ok := result.(*object.Error)
+		if !assert.True(t, ok, "%s: got %s", tt.input, result.Inspect()) {
+			continue
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+		assert.Equal(t, tt.code, errObj.Code, tt.input)
+	}
+}
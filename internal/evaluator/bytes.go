@@ -0,0 +1,167 @@
+package evaluator
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+func createBytesModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "bytes",
+		Members: make(map[string]object.Object),
+	}
+
+	// from_string, to_string - a string's UTF-8 bytes, and back. Bytes that
+	// aren't UTF-8 text can't be turned into a string:
+	//   conn.write_bytes(bytes.from_string("HELLO"))
+	mod.Set("from_string", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("bytes.from_string", args, 1); err != nil {
+				return err
+			}
+			s, err := object.StringArg("bytes.from_string", args[0])
+			if err != nil {
+				return err
+			}
+			return &object.Bytes{Value: []byte(s)}
+		},
+	})
+	mod.Set("to_string", bytesBuiltin("bytes.to_string", 0, func(data []byte, args []object.Object) object.Object {
+		if !utf8.Valid(data) {
+			return &object.Error{Code: diagnostics.CodeBadArgument, Message: "bytes.to_string: the bytes aren't UTF-8 text"}
+		}
+		return &object.String{Value: string(data)}
+	}))
+
+	// from_hex, to_hex - bytes written as hex digits, two to a byte:
+	//   bytes.to_hex(crc)    # "1f8b0800"
+	mod.Set("from_hex", decodeBuiltin("bytes.from_hex", hex.DecodeString))
+	mod.Set("to_hex", bytesBuiltin("bytes.to_hex", 0, func(data []byte, args []object.Object) object.Object {
+		return &object.String{Value: hex.EncodeToString(data)}
+	}))
+
+	// from_base64, to_base64 - bytes in standard base64, for sending binary
+	// data where only text will do
+	mod.Set("from_base64", decodeBuiltin("bytes.from_base64", base64.StdEncoding.DecodeString))
+	mod.Set("to_base64", bytesBuiltin("bytes.to_base64", 0, func(data []byte, args []object.Object) object.Object {
+		return &object.String{Value: base64.StdEncoding.EncodeToString(data)}
+	}))
+
+	// from_array, to_array - bytes from an array of integers from 0 to 255,
+	// and back:
+	//   prep header = bytes.from_array([66, 69, 69, 70])
+	mod.Set("from_array", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("bytes.from_array", args, 1); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("bytes.from_array: expected an ARRAY, got %s", args[0].Type())}
+			}
+			elements := arr.Elements()
+			data := make([]byte, len(elements))
+			for i, el := range elements {
+				n, err := object.IntegerArg("bytes.from_array", el)
+				if err != nil {
+					return err
+				}
+				if n < 0 || n > 255 {
+					return &object.Error{Code: diagnostics.CodeBadArgument,
+						Message: fmt.Sprintf("bytes.from_array: %d at index %d is not a byte (0 to 255)", n, i)}
+				}
+				data[i] = byte(n)
+			}
+			return &object.Bytes{Value: data}
+		},
+	})
+	mod.Set("to_array", bytesBuiltin("bytes.to_array", 0, func(data []byte, args []object.Object) object.Object {
+		elements := make([]object.Object, len(data))
+		for i, c := range data {
+			elements[i] = &object.Integer{Value: int64(c)}
+		}
+		return object.NewArray(elements)
+	}))
+
+	// slice - the bytes from start up to end, like array.slice: negative
+	// positions count from the end, and positions past either end are
+	// clamped:
+	//   prep body = bytes.slice(message, 4, -1)
+	mod.Set("slice", bytesBuiltin("bytes.slice", 2, func(data []byte, args []object.Object) object.Object {
+		start, err := object.IntegerArg("bytes.slice", args[0])
+		if err != nil {
+			return err
+		}
+		end, err := object.IntegerArg("bytes.slice", args[1])
+		if err != nil {
+			return err
+		}
+		n := int64(len(data))
+		start, end = clampPosition(start, n), clampPosition(end, n)
+		if start >= end {
+			return &object.Bytes{Value: []byte{}}
+		}
+		return &object.Bytes{Value: data[start:end:end]}
+	}))
+
+	// concat - the bytes of its arguments, one after another:
+	//   conn.write_bytes(bytes.concat(header, body))
+	mod.Set("concat", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			data := []byte{}
+			for _, arg := range args {
+				part, err := object.BytesArg("bytes.concat", arg)
+				if err != nil {
+					return err
+				}
+				data = append(data, part...)
+			}
+			return &object.Bytes{Value: data}
+		},
+	})
+
+	return mod
+}
+
+// bytesBuiltin makes a builtin that takes bytes followed by argc more
+// arguments.
+func bytesBuiltin(name string, argc int, fn func(data []byte, args []object.Object) object.Object) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount(name, args, argc+1); err != nil {
+				return err
+			}
+			data, err := object.BytesArg(name, args[0])
+			if err != nil {
+				return err
+			}
+			return fn(data, args[1:])
+		},
+	}
+}
+
+// decodeBuiltin makes a builtin that turns a string into bytes with decode.
+func decodeBuiltin(name string, decode func(string) ([]byte, error)) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount(name, args, 1); err != nil {
+				return err
+			}
+			s, err := object.StringArg(name, args[0])
+			if err != nil {
+				return err
+			}
+			data, decodeErr := decode(s)
+			if decodeErr != nil {
+				return &object.Error{Code: diagnostics.CodeBadArgument, Message: fmt.Sprintf("%s: %s", name, decodeErr)}
+			}
+			return &object.Bytes{Value: data}
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`bytes.from_string("BEEF")`, "<bytes 42 45 45 46>"},
+		{`bytes.to_string(bytes.from_array([104, 105]))`, "hi"},
+		{`bytes.from_array([])`, "<bytes>"},
+		{`bytes.to_array(bytes.from_hex("00ff7F"))`, "[0, 255, 127]"},
+		{`bytes.to_hex(bytes.from_string("hi"))`, "6869"},
+		{`bytes.to_base64(bytes.from_array([0, 1, 2, 253]))`, "AAEC/Q=="},
+		{`bytes.to_array(bytes.from_base64("AAEC/Q=="))`, "[0, 1, 2, 253]"},
+		{`bytes.from_string("0123456789abcdefXY")`, "<bytes 30 31 32 33 34 35 36 37 38 39 61 62 63 64 65 66 ... (18 bytes)>"},
+		{`bytes.from_string("smoke").length`, "5"},
+		// Indexing gives integers, like arrays give elements
+		{`prep b = bytes.from_array([1, 2, 200])` + "\n[b[0], b[-1], b[3]]", "[1, 200, null]"},
+		{`bytes.slice(bytes.from_string("brisket"), 1, -2)`, "<bytes 72 69 73 6b>"},
+		{`bytes.slice(bytes.from_string("ribs"), 3, 1)`, "<bytes>"},
+		{`bytes.concat(bytes.from_string("a"), bytes.from_array([0]), bytes.from_string("b"))`, "<bytes 61 00 62>"},
+		{`bytes.concat()`, "<bytes>"},
+		// Bytes compare by contents
+		{`bytes.from_string("hi") == bytes.from_hex("6869")`, "true"},
+		{`bytes.from_string("hi") != bytes.from_string("ho")`, "true"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle bytes\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestBytesSliceKeepsTheOriginal(t *testing.T) {
+	// A slice can share its bytes with the original since neither can change,
+	// but appending to it mustn't write into the original
+	result := testEval(`wrangle bytes
+prep b = bytes.from_string("abcd")
+prep joined = bytes.concat(bytes.slice(b, 0, 2), bytes.from_string("XY"))
+[b, joined]`)
+	assert.Equal(t, "[<bytes 61 62 63 64>, <bytes 61 62 58 59>]", result.Inspect())
+}
+
+func TestBytesErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`bytes.to_string(bytes.from_array([255]))`, "bytes.to_string: the bytes aren't UTF-8 text"},
+		{`bytes.from_array([1, 256])`, "bytes.from_array: 256 at index 1 is not a byte (0 to 255)"},
+		{`bytes.from_array("abc")`, "bytes.from_array: expected an ARRAY, got STRING"},
+		{`bytes.from_hex("abc")`, "bytes.from_hex: encoding/hex: odd length hex string"},
+		{`bytes.from_base64("!!")`, "bytes.from_base64: illegal base64 data at input byte 0"},
+		{`bytes.to_hex("abc")`, "bytes.to_hex: expected BYTES, got STRING"},
+		{`bytes.concat(bytes.from_string("a"), 1)`, "bytes.concat: expected BYTES, got INTEGER"},
+		{`bytes.slice(bytes.from_string("a"), 0)`, "bytes.slice takes 3 arguments, got 2"},
+		{`bytes.from_string("a")["x"]`, "bytes index must be an INTEGER, got STRING"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle bytes\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
@@ -0,0 +1,91 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+func createCacheModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "cache",
+		Members: make(map[string]object.Object),
+	}
+
+	// memoize - wrap a function so each set of arguments is only worked out
+	// once; later calls with equal arguments give back the saved result:
+	//   prep cost = cache.memoize(slow_cost)
+	mod.Set("memoize", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("cache.memoize", args, 1); err != nil {
+				return err
+			}
+			switch args[0].(type) {
+			case *object.Function, *object.Builtin:
+				return memoize(args[0])
+			}
+			return &object.Error{Code: diagnostics.CodeBadArgument,
+				Message: fmt.Sprintf("cache.memoize: expected a function, got %s", args[0].Type())}
+		},
+	})
+
+	return mod
+}
+
+// memoize wraps fn in a builtin that saves its results by argument values.
+// Calls with an argument that can't be a hash key (an array, say) always go
+// through to fn, as do calls that fail, so errors aren't remembered.
+//
+// The lock isn't held while fn runs: a recursive function calls the wrapper
+// again, and tasks calling it at once may both work out the same result,
+// which is harmless for the pure functions memoize is meant for.
+func memoize(fn object.Object) *object.Builtin {
+	var mu sync.Mutex
+	results := make(map[string]object.Object)
+
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			key, ok := memoKey(args)
+			if !ok {
+				return applyFunction(token.Token{}, fn, args)
+			}
+
+			mu.Lock()
+			result, found := results[key]
+			mu.Unlock()
+			if found {
+				return result
+			}
+
+			result = applyFunction(token.Token{}, fn, args)
+			if isError(result) {
+				return result
+			}
+			mu.Lock()
+			results[key] = result
+			mu.Unlock()
+			return result
+		},
+	}
+}
+
+// memoKey joins the hash keys of a call's arguments, reporting false if any
+// argument isn't hashable. The argument count is part of the key, and each
+// part is quoted, so no two different calls give the same key.
+func memoKey(args []object.Object) (string, bool) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d", len(args))
+	for _, arg := range args {
+		hashable, ok := arg.(object.Hashable)
+		if !ok {
+			return "", false
+		}
+		k := hashable.HashKey()
+		fmt.Fprintf(&b, " %s:%q", k.Type, k.Value)
+	}
+	return b.String(), true
+}
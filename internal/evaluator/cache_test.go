@@ -0,0 +1,90 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheMemoize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// The function only runs once per set of arguments
+		{`prep calls = []
+praise double(n):
+  array.push(calls, n)
+  serve n * 2
+beef
+prep fast = cache.memoize(double)
+[fast(2), fast(3), fast(2), calls]`, "[4, 6, 4, [2, 3]]"},
+		// Recursive calls through the memoized name are cached too
+		{`praise slow_fib(n):
+  if n < 2:
+    serve n
+  beef
+  serve fib(n - 1) + fib(n - 2)
+beef
+prep fib = cache.memoize(slow_fib)
+fib(80)`, "23416728348467685"},
+		// Arguments that differ only in type are different calls
+		{`prep calls = []
+praise show(v):
+  array.push(calls, v)
+  serve v
+beef
+prep fast = cache.memoize(show)
+fast(1)
+fast("1")
+fast(1)
+calls`, `[1, "1"]`},
+		// Unhashable arguments skip the cache
+		{`prep calls = []
+praise first(items):
+  array.push(calls, items[0])
+  serve items[0]
+beef
+prep fast = cache.memoize(first)
+fast([7])
+fast([7])
+calls`, "[7, 7]"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle cache\nwrangle array\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestCacheMemoizeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"cache.memoize(5)", "cache.memoize: expected a function, got INTEGER"},
+		{"cache.memoize()", "cache.memoize takes 1 argument, got 0"},
+		// Errors aren't saved, so a failing call fails every time
+		{`praise half(n):
+  serve 10 / n
+beef
+prep fast = cache.memoize(half)
+fast(0)
+fast(0)`, "division by zero: 10 / 0"},
+		{`praise add(a, b):
+  serve a + b
+beef
+prep fast = cache.memoize(add)
+fast(1)`, "add() takes 2 arguments, got 1"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle cache\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
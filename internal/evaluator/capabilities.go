@@ -0,0 +1,49 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// Capabilities are the ways a program can reach outside the interpreter. Each
+// is off unless allowed, on the command line (--allow-fs, --allow-net,
+// --allow-process, --allow-env, --allow-eval, --allow-parallel) or by an
+// embedder, so running an untrusted script can't touch files, the network,
+// other programs or the environment, run code it wasn't shipped with, or take
+// over every CPU, unless it was meant to. A builtin that needs a capability it
+// hasn't been given fails with a "capability denied" error.
+type Capabilities struct {
+	Filesystem bool // the fs module
+	Network    bool // the net and http modules
+	Process    bool // the process module
+	Env        bool // os.getenv, os.expand
+	Eval       bool // meta.eval
+	Parallel   bool // the parallel module
+}
+
+// capability describes one capability for error messages.
+type capability struct {
+	flag string // the command-line flag that allows it
+	what string
+}
+
+var (
+	capFilesystem = capability{"--allow-fs", "file access"}
+	capNetwork    = capability{"--allow-net", "network access"}
+	capProcess    = capability{"--allow-process", "the process module"}
+	capEnv        = capability{"--allow-env", "environment variables"}
+	capEval       = capability{"--allow-eval", "running code from strings"}
+	capParallel   = capability{"--allow-parallel", "work spread over several CPUs"}
+)
+
+// checkCapability returns a capability denied error for the builtin called
+// name unless allowed is set.
+func checkCapability(allowed bool, c capability, name string) *object.Error {
+	if allowed {
+		return nil
+	}
+	return &object.Error{Code: diagnostics.CodeCapabilityDenied,
+		Message: fmt.Sprintf("%s is not allowed; run with %s to enable %s", name, c.flag, c.what)}
+}
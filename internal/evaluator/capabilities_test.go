@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// allow gives programs the capabilities for the rest of the test
+func allow(t *testing.T, capabilities Capabilities) {
+	previous := Default.Allowed
+	Default.Allowed = capabilities
+	t.Cleanup(func() { Default.Allowed = previous })
+}
+
+func TestCapabilitiesAreDeniedByDefault(t *testing.T) {
+	tests := []struct {
+		input string
+		flag  string
+	}{
+		{"wrangle fs\nfs.read(\"x\")", "--allow-fs"},
+		{"wrangle fs\nfs.exists(\"x\")", "--allow-fs"},
+		{"wrangle net\nnet.listen(0)", "--allow-net"},
+		{"wrangle net\nnet.dial(\"localhost\", 1)", "--allow-net"},
+		{"wrangle http\nhttp.serve(0, 1)", "--allow-net"},
+		{"wrangle process\nprocess.pid()", "--allow-process"},
+		{"wrangle os\nos.getenv(\"HOME\")", "--allow-env"},
+		{"wrangle os\nos.expand(\"${HOME}\")", "--allow-env"},
+		{"wrangle meta\nmeta.eval(\"1\")", "--allow-eval"},
+		{"wrangle parallel\nparallel.map([1], 1)", "--allow-parallel"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if assert.True(t, ok, "expected an error for %q", tt.input) {
+			assert.Equal(t, diagnostics.CodeCapabilityDenied, errObj.Code)
+			assert.Contains(t, errObj.Message, tt.flag)
+		}
+	}
+}
+
+func TestCapabilitiesAreIndependent(t *testing.T) {
+	allow(t, Capabilities{Env: true})
+
+	assert.Equal(t, "BUILTIN", testEval("wrangle os\nos.getenv").Type())
+	t.Setenv("BEEF_CUT", "brisket")
+	assert.Equal(t, "brisket", testEval("wrangle os\nos.getenv(\"BEEF_CUT\")").Inspect())
+	assert.Equal(t, "null", testEval("wrangle os\nos.getenv(\"BEEF_NOT_SET\")").Inspect())
+	assert.Equal(t, "brisket/$cut/", testEval("wrangle os\nos.expand(\"${BEEF_CUT}/$cut/\")").Inspect())
+
+	errObj, ok := testEval("wrangle fs\nfs.exists(\".\")").(*object.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, diagnostics.CodeCapabilityDenied, errObj.Code)
+	}
+}
+
+func TestOSExpandErrors(t *testing.T) {
+	allow(t, Capabilities{Env: true})
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`os.expand("${BEEF_NOT_SET}/maps")`, "os.expand: environment variable BEEF_NOT_SET is not set"},
+		{`os.expand("${HOME")`, `os.expand: "${HOME" has a ${ with no }`},
+		{`os.expand("${1UP}")`, `os.expand: "1UP" is not an environment variable name`},
+		{`os.expand(7)`, "os.expand: expected a STRING, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval("wrangle os\n" + tt.input).(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q", tt.input)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
+
+func TestFSModule(t *testing.T) {
+	allow(t, Capabilities{Filesystem: true})
+	path := filepath.Join(t.TempDir(), "save.txt")
+	t.Chdir(filepath.Dir(path))
+
+	assert.Equal(t, "false", testEval("wrangle fs\nfs.exists(\"save.txt\")").Inspect())
+	assert.Equal(t, "null", testEval("wrangle fs\nfs.write(\"save.txt\", \"level 3\")").Inspect())
+	assert.Equal(t, "true", testEval("wrangle fs\nfs.exists(\"save.txt\")").Inspect())
+	assert.Equal(t, "level 3", testEval("wrangle fs\nfs.read(\"save.txt\")").Inspect())
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "level 3", string(data))
+
+	errObj, ok := testEval("wrangle fs\nfs.read(\"missing.txt\")").(*object.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, diagnostics.CodeFileError, errObj.Code)
+		assert.Contains(t, errObj.Message, "missing.txt")
+	}
+	errObj, ok = testEval("wrangle fs\nfs.write(\"save.txt\", 3)").(*object.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, diagnostics.CodeBadArgument, errObj.Code)
+	}
+}
+
+func TestFSModuleBinaryFiles(t *testing.T) {
+	allow(t, Capabilities{Filesystem: true})
+	t.Chdir(t.TempDir())
+
+	assert.Equal(t, "null", testEval("wrangle fs\nwrangle bytes\nfs.write_bytes(\"save.dat\", bytes.from_array([0, 255, 10]))").Inspect())
+	data, err := os.ReadFile("save.dat")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 255, 10}, data)
+	assert.Equal(t, "<bytes 00 ff 0a>", testEval("wrangle fs\nfs.read_bytes(\"save.dat\")").Inspect())
+
+	errObj, ok := testEval("wrangle fs\nfs.write_bytes(\"save.dat\", \"text\")").(*object.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, "fs.write_bytes: expected BYTES, got STRING", errObj.Message)
+	}
+}
@@ -0,0 +1,143 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+func evalArrayLiteral(array *ast.ArrayLiteral, env *Environment) object.Object {
+	elements := make([]object.Object, 0, len(array.Elements))
+	for _, el := range array.Elements {
+		value := Eval(el, env)
+		if isError(value) {
+			return value
+		}
+		elements = append(elements, value)
+	}
+	return object.NewArray(elements)
+}
+
+func evalHashLiteral(hash *ast.HashLiteral, env *Environment) object.Object {
+	result := object.NewHash()
+	for _, pair := range hash.Pairs {
+		key := Eval(pair.Key, env)
+		if isError(key) {
+			return key
+		}
+		hashable, ok := key.(object.Hashable)
+		if !ok {
+			return newError(hash.Token, diagnostics.CodeBadIndex, "unusable as hash key: %s", key.Type())
+		}
+
+		value := Eval(pair.Value, env)
+		if isError(value) {
+			return value
+		}
+		result.Set(hashable, value)
+	}
+	return result
+}
+
+// evalIndexExpression reads array[i], bytes[i] or hash[key]. An index past
+// either end of an array or bytes, or a key the hash doesn't have, gives NULL.
+// Negative indexes count from the end: cuts[-1] is the last element.
+func evalIndexExpression(expr *ast.IndexExpression, env *Environment) object.Object {
+	left := Eval(expr.Left, env)
+	if isError(left) {
+		return left
+	}
+	index := Eval(expr.Index, env)
+	if isError(index) {
+		return index
+	}
+
+	switch container := left.(type) {
+	case *object.Array:
+		i, ok := index.(*object.Integer)
+		if !ok {
+			return newError(expr.Token, diagnostics.CodeBadIndex, "array index must be an INTEGER, got %s", index.Type())
+		}
+		n := i.Value
+		if n < 0 {
+			n += int64(container.Len())
+		}
+		if el, ok := container.At(int(n)); ok {
+			return el
+		}
+		return object.NULL
+
+	case *object.Bytes:
+		i, ok := index.(*object.Integer)
+		if !ok {
+			return newError(expr.Token, diagnostics.CodeBadIndex, "bytes index must be an INTEGER, got %s", index.Type())
+		}
+		n := i.Value
+		if n < 0 {
+			n += int64(len(container.Value))
+		}
+		if n < 0 || n >= int64(len(container.Value)) {
+			return object.NULL
+		}
+		return &object.Integer{Value: int64(container.Value[n])}
+
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError(expr.Token, diagnostics.CodeBadIndex, "unusable as hash key: %s", index.Type())
+		}
+		if value, found := container.Lookup(key); found {
+			return value
+		}
+		return object.NULL
+	}
+
+	return newError(expr.Token, diagnostics.CodeBadIndex, "index operator not supported: %s[%s]", left.Type(), index.Type())
+}
+
+// evalInExpression tests membership: an element of an array, a key of a
+// hash, or a substring of a string. Elements are compared like hash keys, so
+// 1 in [1] but not "1" in [1]; other values, such as arrays, only match
+// themselves.
+func evalInExpression(tok token.Token, left, right object.Object) object.Object {
+	switch container := right.(type) {
+	case *object.Array:
+		for _, el := range container.Elements() {
+			if sameValue(left, el) {
+				return object.TRUE
+			}
+		}
+		return object.FALSE
+
+	case *object.Hash:
+		key, ok := left.(object.Hashable)
+		if !ok {
+			// It couldn't have been added
+			return object.FALSE
+		}
+		_, found := container.Lookup(key)
+		return nativeBoolToBooleanObject(found)
+
+	case *object.String:
+		sub, ok := left.(*object.String)
+		if !ok {
+			return newError(tok, diagnostics.CodeTypeMismatch, "type mismatch: %s in STRING", left.Type())
+		}
+		return nativeBoolToBooleanObject(strings.Contains(container.Value, sub.Value))
+	}
+
+	return newError(tok, diagnostics.CodeUnknownOperator, "unknown operator: %s in %s", left.Type(), right.Type())
+}
+
+// sameValue reports whether a and b are equal as hash keys, or are the same value.
+func sameValue(a, b object.Object) bool {
+	ak, aok := a.(object.Hashable)
+	bk, bok := b.(object.Hashable)
+	if aok && bok {
+		return ak.HashKey() == bk.HashKey()
+	}
+	return a == b
+}
@@ -0,0 +1,148 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrayLiterals(t *testing.T) {
+	result := testEval(`prep n = 2
+[1, n * 3, "ribs", [true]]`)
+
+	array, ok := result.(*object.Array)
+	if assert.True(t, ok, "expected *object.Array, got %T", result) {
+		assert.Equal(t, `[1, 6, "ribs", [true]]`, array.Inspect())
+	}
+}
+
+func TestHashLiterals(t *testing.T) {
+	result := testEval(`prep cut = "brisket"
+{cut: 12, 1: "one", true: [], "cut": cut}`)
+
+	hash, ok := result.(*object.Hash)
+	if assert.True(t, ok, "expected *object.Hash, got %T", result) {
+		assert.Equal(t, `{"brisket": 12, 1: "one", true: [], "cut": "brisket"}`, hash.Inspect())
+	}
+}
+
+func TestIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[10, 20, 30][0]", "10"},
+		{"[10, 20, 30][2]", "30"},
+		{"[10, 20, 30][-1]", "30"},
+		{"[10, 20, 30][3]", "null"},
+		{"[10, 20, 30][-4]", "null"},
+		{"prep i = 1\n[10, 20, 30][i + 1]", "30"},
+		{`{"cut": "ribs"}["cut"]`, "ribs"},
+		{`{"cut": "ribs"}["weight"]`, "null"},
+		{`{1: "one"}[1]`, "one"},
+		{`{true: "yes"}[1 < 2]`, "yes"},
+		{`{"grill": [1, {"temp": 225}]}["grill"][1]["temp"]`, "225"},
+		// String keys can also be read as members
+		{`prep h = {"cut": "ribs"}` + "\nh.cut", "ribs"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+}
+
+func TestIndexErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, 2]["first"]`, "array index must be an INTEGER, got STRING"},
+		{"prep n = 5\nn[0]", "index operator not supported: INTEGER[INTEGER]"},
+		{`{"a": 1}[[1]]`, "unusable as hash key: ARRAY"},
+		{`{[1]: "one"}`, "unusable as hash key: ARRAY"},
+		{"[1, nope]", "identifier not found: nope"},
+		{`{"a": nope}`, "identifier not found: nope"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if assert.True(t, ok, "expected an error for %q", tt.input) {
+			assert.Equal(t, tt.expected, errObj.Message, tt.input)
+		}
+	}
+
+	errObj := testEval(`[1, 2]["first"]`).(*object.Error)
+	assert.Equal(t, diagnostics.CodeBadIndex, errObj.Code)
+	assert.Equal(t, 1, errObj.Line)
+	assert.Equal(t, 7, errObj.Column)
+}
+
+func TestOptionalMemberAccess(t *testing.T) {
+	config := "prep config = {\"db\": {\"host\": \"smoker\"}}\nprep missing = config[\"cache\"]\n"
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"config?.db?.host", "smoker"},
+		{"config?.cache", "null"},
+		{"config?.cache?.host", "null"},
+		{"missing?.host", "null"},
+		{"missing?.flush()", "null"},
+		// The arguments of a call that doesn't happen aren't evaluated
+		{"missing?.flush(undefined_name)", "null"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(config + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+
+	// Without the '?', a hash without the key is still an error
+	errObj, ok := testEval(config + "config.cache").(*object.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, diagnostics.CodeNoSuchMember, errObj.Code)
+	}
+}
+
+func TestInOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"2 in [1, 2, 3]", true},
+		{"4 in [1, 2, 3]", false},
+		{"\"1\" in [1, 2, 3]", false},
+		{"\"rib\" in [\"brisket\", \"rib\"]", true},
+		{"true in [false]", false},
+		{"prep a = [1]\na in [a]", true},
+		{"[1] in [[1]]", false},
+		{"\"cut\" in {\"cut\": \"brisket\"}", true},
+		{"\"brisket\" in {\"cut\": \"brisket\"}", false},
+		{"[1] in {1: 2}", false},
+		{"\"ris\" in \"brisket\"", true},
+		{"\"\" in \"\"", true},
+		{"\"rib\" in \"brisket\"", false},
+		{"1 + 1 in [2]", true},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		assert.Equal(t, nativeBoolToBooleanObject(tt.expected), result, "%s: got %s", tt.input, result.Inspect())
+	}
+
+	errors := []struct {
+		input string
+		code  string
+	}{
+		{"1 in \"abc\"", diagnostics.CodeTypeMismatch},
+		{"1 in 2", diagnostics.CodeUnknownOperator},
+	}
+	for _, tt := range errors {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if assert.True(t, ok, tt.input) {
+			assert.Equal(t, tt.code, errObj.Code, tt.input)
+		}
+	}
+}
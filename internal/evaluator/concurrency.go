@@ -0,0 +1,164 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// evalStampedeStatement starts a call on a goroutine of its own and returns
+// immediately. The callee and its arguments are evaluated first, in the
+// caller's scope; the call itself then runs in a fresh environment enclosed
+// by the function's closure, exactly like a normal call, so tasks never
+// write to each other's scopes.
+func evalStampedeStatement(stmt *ast.StampedeStatement, env *Environment) object.Object {
+	call := stmt.Call
+	function := Eval(call.Function, env)
+	if isError(function) {
+		return function
+	}
+
+	args := evalExpressions(call.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+
+	switch function.(type) {
+	case *object.Function, *object.Builtin:
+	default:
+		return newError(call.Token, diagnostics.CodeNotAFunction, "not a function: %s", function.Type())
+	}
+
+	go func() {
+		if errObj, ok := applyFunction(call.Token, function, args).(*object.Error); ok {
+			runtimeOf(env).taskError(errObj)
+		}
+	}()
+
+	return object.NULL
+}
+
+// evalSelectStatement waits for the first ready case of a select and runs its
+// block, or runs the 'else' block straight away if no case is ready. Every
+// channel and value to send is evaluated up front, in source order.
+func evalSelectStatement(stmt *ast.SelectStatement, env *Environment) object.Object {
+	cases := make([]object.SelectCase, len(stmt.Cases))
+	for i, c := range stmt.Cases {
+		expr, send := c.Channel()
+		value := Eval(expr, env)
+		if isError(value) {
+			return value
+		}
+		ch, ok := value.(*object.Channel)
+		if !ok {
+			return newError(c.Call.Token, diagnostics.CodeNotAChannel, "select needs a channel, got %s", value.Type())
+		}
+		cases[i] = object.SelectCase{Channel: ch, Send: send}
+
+		if send {
+			cases[i].Value = Eval(c.Call.Arguments[0], env)
+			if isError(cases[i].Value) {
+				return cases[i].Value
+			}
+		}
+	}
+
+	chosen, received, err := object.Select(cases, stmt.Default != nil)
+	if err != nil {
+		// Go doesn't say which send failed, so point at the select
+		return newError(stmt.Token, err.Code, "%s", err.Message)
+	}
+	if chosen < 0 {
+		return Eval(stmt.Default, env)
+	}
+
+	c := stmt.Cases[chosen]
+	if c.Name != nil {
+		env.Set(c.Name.Value, received)
+	}
+	return Eval(c.Body, env)
+}
+
+func createChanModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "chan",
+		Members: make(map[string]object.Object),
+	}
+
+	// new - create a channel, optionally buffered: chan.new() or chan.new(10)
+	mod.Set("new", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return object.NewChannel(0)
+			}
+			if len(args) > 1 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("chan.new takes at most 1 argument, got %d", len(args))}
+			}
+			capacity, ok := args[0].(*object.Integer)
+			if !ok {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("chan.new: capacity must be an INTEGER, got %s", args[0].Type())}
+			}
+			if capacity.Value < 0 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("chan.new: capacity must not be negative, got %d", capacity.Value)}
+			}
+			return object.NewChannel(int(capacity.Value))
+		},
+	})
+
+	return mod
+}
+
+func createSyncModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "sync",
+		Members: make(map[string]object.Object),
+	}
+
+	// mutex - create an unlocked mutex
+	mod.Set("mutex", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("sync.mutex takes no arguments, got %d", len(args))}
+			}
+			return object.NewMutex()
+		},
+	})
+
+	// waitgroup - create a wait group with nothing to wait for yet
+	mod.Set("waitgroup", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("sync.waitgroup takes no arguments, got %d", len(args))}
+			}
+			return &object.WaitGroup{}
+		},
+	})
+
+	// counter - create an atomic counter: sync.counter() starts at 0, sync.counter(n) at n
+	mod.Set("counter", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return object.NewCounter(0)
+			}
+			if len(args) > 1 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("sync.counter takes at most 1 argument, got %d", len(args))}
+			}
+			start, ok := args[0].(*object.Integer)
+			if !ok {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("sync.counter: start must be an INTEGER, got %s", args[0].Type())}
+			}
+			return object.NewCounter(start.Value)
+		},
+	})
+
+	return mod
+}
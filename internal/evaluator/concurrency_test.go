@@ -0,0 +1,383 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStampedeAndChannels(t *testing.T) {
+	input := `
+wrangle chan
+praise produce(ch, n):
+   prep i = 1
+   feast while i <= n:
+      ch.send(i)
+      i = i + 1
+   beef
+   ch.close()
+beef
+prep ch = chan.new()
+stampede produce(ch, 4)
+prep total = 0
+prep v = ch.recv()
+feast while v != null_value():
+   total = total + v
+   v = ch.recv()
+beef
+total
+`
+	// There is no null literal: a function without 'serve' returns NULL
+	result := testEval("praise null_value():\nbeef\n" + input)
+
+	integer, ok := result.(*object.Integer)
+	assert.True(t, ok, "expected INTEGER, got %s", result.Inspect())
+	assert.Equal(t, int64(10), integer.Value)
+}
+
+func TestStampedeTasksRunConcurrently(t *testing.T) {
+	// Unbuffered sends wait for a receiver, so this only finishes if the task
+	// runs while the program waits on it
+	input := `
+wrangle chan
+praise shout(requests, replies):
+   replies.send(requests.recv() + "!")
+beef
+prep requests = chan.new()
+prep replies = chan.new()
+stampede shout(requests, replies)
+requests.send("beef")
+replies.recv()
+`
+	result := testEval(input)
+
+	str, ok := result.(*object.String)
+	assert.True(t, ok, "expected STRING, got %s", result.Inspect())
+	assert.Equal(t, "beef!", str.Value)
+}
+
+func TestBufferedChannel(t *testing.T) {
+	input := `
+wrangle chan
+prep ch = chan.new(2)
+ch.send(1)
+ch.send(2)
+ch.close()
+ch.recv() + ch.recv()
+`
+	result := testEval(input)
+
+	integer, ok := result.(*object.Integer)
+	assert.True(t, ok, "expected INTEGER, got %s", result.Inspect())
+	assert.Equal(t, int64(3), integer.Value)
+}
+
+func TestRecvFromClosedChannelIsNull(t *testing.T) {
+	result := testEval("wrangle chan\nprep ch = chan.new()\nch.close()\nch.recv()")
+
+	assert.Equal(t, object.NULL, result)
+}
+
+func TestChannelErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		code    string
+		message string
+		line    int
+	}{
+		{"wrangle chan\nprep ch = chan.new(1)\nch.close()\nch.send(1)", diagnostics.CodeClosedChannel, "send on closed channel", 4},
+		{"wrangle chan\nprep ch = chan.new()\nch.close()\nch.close()", diagnostics.CodeClosedChannel, "close of closed channel", 4},
+		{"wrangle chan\nchan.new(\"big\")", diagnostics.CodeBadArgument, "chan.new: capacity must be an INTEGER, got STRING", 2},
+		{"wrangle chan\nchan.new(-1)", diagnostics.CodeBadArgument, "chan.new: capacity must not be negative, got -1", 2},
+		{"wrangle chan\nprep ch = chan.new(1)\nch.send()", diagnostics.CodeBadArgument, "send takes 1 argument, got 0", 3},
+		{"wrangle chan\nprep ch = chan.new(1)\nch.push(1)", diagnostics.CodeNoSuchMember, "CHANNEL has no member 'push'", 3},
+		{"prep x = 1\nstampede x()", diagnostics.CodeNotAFunction, "not a function: INTEGER", 2},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if assert.True(t, ok, "input %q should fail", tt.input) {
+			assert.Equal(t, tt.code, errObj.Code, tt.input)
+			assert.Equal(t, tt.message, errObj.Message, tt.input)
+			assert.Equal(t, tt.line, errObj.Line, tt.input)
+		}
+	}
+}
+
+func TestTaskErrorsAreReported(t *testing.T) {
+	var mu sync.Mutex
+	var reported []*object.Error
+	done := make(chan bool)
+	defer func(saved func(*object.Error)) { Default.OnTaskError = saved }(Default.OnTaskError)
+	Default.OnTaskError = func(err *object.Error) {
+		mu.Lock()
+		reported = append(reported, err)
+		mu.Unlock()
+		done <- true
+	}
+
+	result := testEval("praise fail():\n   serve missing\nbeef\nstampede fail()\n1")
+	<-done
+
+	// The task's error doesn't stop the program that started it
+	assert.Equal(t, int64(1), result.(*object.Integer).Value)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, reported, 1)
+	assert.Equal(t, diagnostics.CodeIdentifierNotFound, reported[0].Code)
+}
+
+func TestTaskWithTheWrongNumberOfArgumentsIsReported(t *testing.T) {
+	reported := make(chan *object.Error, 1)
+	defer func(saved func(*object.Error)) { Default.OnTaskError = saved }(Default.OnTaskError)
+	Default.OnTaskError = func(err *object.Error) { reported <- err }
+
+	testEval("praise cook(cut):\n   serve cut\nbeef\nstampede cook()\n")
+	err := <-reported
+
+	assert.Equal(t, diagnostics.CodeWrongArity, err.Code)
+	assert.Equal(t, "cook() takes 1 argument, got 0", err.Message)
+	assert.Equal(t, 4, err.Line)
+}
+
+func TestStampedeArgumentsAreEvaluatedByTheCaller(t *testing.T) {
+	// x changes after the task starts, but the task got the value it had then
+	input := `
+wrangle chan
+praise echo(ch, value):
+   ch.send(value)
+beef
+prep ch = chan.new()
+prep x = 1
+stampede echo(ch, x)
+x = 2
+ch.recv()
+`
+	result := testEval(input)
+
+	assert.Equal(t, int64(1), result.(*object.Integer).Value)
+}
+
+func TestSyncModule(t *testing.T) {
+	// Ten tasks each add to a shared total under a mutex and bump a counter;
+	// the wait group holds the program until all of them are done
+	input := `
+wrangle chan
+wrangle sync
+prep lock = sync.mutex()
+prep group = sync.waitgroup()
+prep count = sync.counter()
+prep totals = chan.new(1)
+totals.send(0)
+praise work(n):
+   lock.lock()
+   totals.send(totals.recv() + n)
+   lock.unlock()
+   count.add()
+   group.done()
+beef
+prep i = 1
+group.add(10)
+feast while i <= 10:
+   stampede work(i)
+   i = i + 1
+beef
+group.wait()
+totals.recv() * 100 + count.get()
+`
+	result := testEval(input)
+
+	integer, ok := result.(*object.Integer)
+	assert.True(t, ok, "expected INTEGER, got %s", result.Inspect())
+	assert.Equal(t, int64(55*100+10), integer.Value)
+}
+
+func TestStampedeTasksShareArraysAndHashes(t *testing.T) {
+	// Tasks push to one array and delete from one hash while the others read
+	// them; run with -race to check
+	keys := make([]string, 2000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%d: %d", i, i)
+	}
+	input := `
+wrangle array
+wrangle hash
+wrangle sync
+prep seen = []
+prep stock = {` + strings.Join(keys, ", ") + `}
+prep group = sync.waitgroup()
+praise take(first):
+   prep i = first
+   feast while i < 2000:
+      array.push(seen, hash.delete(stock, i))
+      i = i + 4
+   beef
+   group.done()
+beef
+praise look():
+   prep i = 0
+   feast while i < 2000:
+      array.push(looked, hash.has(stock, i))
+      i = i + 1
+   beef
+   group.done()
+beef
+prep looked = []
+group.add(8)
+prep task = 0
+feast while task < 4:
+   stampede take(task)
+   stampede look()
+   task = task + 1
+beef
+group.wait()
+[array.sort(seen), hash.keys(stock)]
+`
+	result := testEval(input)
+
+	values := make([]string, len(keys))
+	for i := range values {
+		values[i] = fmt.Sprint(i)
+	}
+	assert.Equal(t, "[["+strings.Join(values, ", ")+"], []]", result.Inspect())
+}
+
+func TestSyncModuleErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		code    string
+		message string
+	}{
+		{"wrangle sync\nsync.mutex().unlock()", diagnostics.CodeBadSyncUse, "unlock of unlocked mutex"},
+		{"wrangle sync\nsync.waitgroup().done()", diagnostics.CodeBadSyncUse, "waitgroup count would go below zero"},
+		{"wrangle sync\nsync.counter(\"one\")", diagnostics.CodeBadArgument, "sync.counter: start must be an INTEGER, got STRING"},
+		{"wrangle sync\nsync.mutex(1)", diagnostics.CodeBadArgument, "sync.mutex takes no arguments, got 1"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if assert.True(t, ok, "input %q should fail", tt.input) {
+			assert.Equal(t, tt.code, errObj.Code, tt.input)
+			assert.Equal(t, tt.message, errObj.Message, tt.input)
+		}
+	}
+}
+
+func TestSelectReceivesFromTheReadyChannel(t *testing.T) {
+	input := `
+wrangle chan
+prep quiet = chan.new()
+prep busy = chan.new(1)
+busy.send("beef")
+prep got = "nothing"
+select:
+when msg = quiet.recv():
+   got = "quiet"
+when msg = busy.recv():
+   got = msg
+beef
+got
+`
+	result := testEval(input)
+
+	str, ok := result.(*object.String)
+	assert.True(t, ok, "expected STRING, got %s", result.Inspect())
+	assert.Equal(t, "beef", str.Value)
+}
+
+func TestSelectSendAndDefault(t *testing.T) {
+	input := `
+wrangle chan
+prep full = chan.new(1)
+full.send(1)
+prep free = chan.new(1)
+prep first = ""
+select:
+when full.send(2):
+   first = "full"
+when free.send(3):
+   first = "free"
+beef
+prep second = ""
+select:
+when full.send(4):
+   second = "sent"
+else:
+   second = "would block"
+beef
+prep sent = free.recv()
+if sent == 3:
+   first + " " + second
+beef
+`
+	result := testEval(input)
+
+	assert.Equal(t, "free would block", result.Inspect())
+}
+
+func TestSelectTimeout(t *testing.T) {
+	input := `
+wrangle chan
+wrangle time
+prep never = chan.new()
+prep result = "waiting"
+select:
+when never.recv():
+   result = "impossible"
+when time.after(20).recv():
+   result = "timed out"
+beef
+result
+`
+	start := time.Now()
+	result := testEval(input)
+
+	assert.Equal(t, "timed out", result.Inspect())
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestSelectServesFromTheCase(t *testing.T) {
+	input := `
+wrangle chan
+praise first(ch):
+   select:
+   when v = ch.recv():
+      serve v * 2
+   beef
+   serve 0
+beef
+prep ch = chan.new(1)
+ch.send(21)
+first(ch)
+`
+	result := testEval(input)
+
+	assert.Equal(t, int64(42), result.(*object.Integer).Value)
+}
+
+func TestSelectErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		code    string
+		message string
+	}{
+		{"prep ch = 5\nselect:\nwhen ch.recv():\nbeef", diagnostics.CodeNotAChannel, "select needs a channel, got INTEGER"},
+		{"wrangle chan\nprep ch = chan.new(1)\nch.close()\nselect:\nwhen ch.send(1):\nbeef", diagnostics.CodeClosedChannel, "send on closed channel"},
+		{"wrangle time\ntime.after(-1)", diagnostics.CodeBadArgument, "time.after: milliseconds must not be negative, got -1"},
+		{"wrangle time\ntime.sleep()", diagnostics.CodeBadArgument, "time.sleep takes 1 argument (milliseconds), got 0"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if assert.True(t, ok, "input %q should fail", tt.input) {
+			assert.Equal(t, tt.code, errObj.Code, tt.input)
+			assert.Equal(t, tt.message, errObj.Message, tt.input)
+		}
+	}
+}
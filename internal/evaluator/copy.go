@@ -0,0 +1,28 @@
+package evaluator
+
+import (
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+func createCopyModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "copy",
+		Members: make(map[string]object.Object),
+	}
+
+	// clone - a deep copy of a value, so changing the copy never changes
+	// the original. Arrays and hashes are copied all the way down, and one
+	// that contains itself gives a copy that contains itself. Functions,
+	// channels and other shared values are not copied:
+	//   prep saved = copy.clone(game_state)
+	mod.Set("clone", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("copy.clone", args, 1); err != nil {
+				return err
+			}
+			return object.Clone(args[0])
+		},
+	})
+
+	return mod
+}
@@ -0,0 +1,37 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyClone(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`prep state = {"hp": 10, "bag": ["rib"]}
+prep saved = copy.clone(state)
+array.push(state["bag"], "chuck")
+[state, saved]`, `[{"hp": 10, "bag": ["rib", "chuck"]}, {"hp": 10, "bag": ["rib"]}]`},
+		{`copy.clone(5)`, "5"},
+		{`copy.clone("rib")`, "rib"},
+		// The same array twice is still the same array in the copy
+		{`prep bag = []
+prep saved = copy.clone([bag, bag])
+array.push(saved[0], 1)
+saved`, "[[1], [1]]"},
+		// Functions are shared, not copied
+		{`praise heal(hp):
+  serve hp + 1
+beef
+prep saved = copy.clone({"heal": heal})
+saved["heal"](1)`, "2"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle copy\nwrangle array\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
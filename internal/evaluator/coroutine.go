@@ -0,0 +1,35 @@
+package evaluator
+
+import (
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+func createCoroutineModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "coroutine",
+		Members: make(map[string]object.Object),
+	}
+
+	// new - make a coroutine that runs a function a piece at a time: each
+	// co.resume(value) runs it until it calls co.yield(value), and the next
+	// resume carries on from there. The function gets the value of the first
+	// resume, if it takes a parameter:
+	//   prep intro = coroutine.new(play_intro)
+	//   intro.resume()
+	mod.Set("new", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("coroutine.new", args, 1); err != nil {
+				return err
+			}
+			if err := checkHandler("coroutine.new", args[0]); err != nil {
+				return err
+			}
+			fn := args[0]
+			return object.NewCoroutine(func(first object.Object) object.Object {
+				return callHandler(fn, first)
+			})
+		},
+	})
+
+	return mod
+}
@@ -0,0 +1,108 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoroutines(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`praise patrol():
+  co.yield("left")
+  co.yield("right")
+  serve "home"
+beef
+prep co = coroutine.new(patrol)
+[co.resume(), co.resume(), co.done(), co.resume(), co.done()]`, `["left", "right", false, "home", true]`},
+		// resume hands yield its value, and the first resume the function's
+		// parameter
+		{`praise total(first):
+  prep sum = first
+  feast while true:
+    prep n = co.yield(sum)
+    if n == 0:
+      serve sum
+    beef
+    sum = sum + n
+  beef
+beef
+prep co = coroutine.new(total)
+[co.resume(10), co.resume(5), co.resume(1), co.resume(0)]`, "[10, 15, 16, 16]"},
+		// Coroutines can resume other coroutines
+		{`praise inner():
+  a.yield(1)
+  serve 2
+beef
+praise outer():
+  b.yield(a.resume() * 10)
+  serve a.resume() * 10
+beef
+prep a = coroutine.new(inner)
+prep b = coroutine.new(outer)
+[b.resume(), b.resume()]`, "[10, 20]"},
+		{`praise nothing():
+beef
+prep co = coroutine.new(nothing)
+[co.done(), co.resume(), co.done()]`, "[false, null, true]"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle coroutine\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestCoroutineErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`coroutine.new(1)`, "coroutine.new: expected a function, got INTEGER"},
+		{`praise add(a, b):
+  serve a + b
+beef
+coroutine.new(add)`, "coroutine.new: expected a function of 1 parameter, got one of 2"},
+		{`praise once():
+  serve 1
+beef
+prep co = coroutine.new(once)
+co.resume()
+co.resume()`, "resume of finished coroutine"},
+		{`praise again():
+  co.resume()
+beef
+prep co = coroutine.new(again)
+co.resume()`, "resume of running coroutine"},
+		{`praise wait():
+  co.yield(1)
+beef
+prep co = coroutine.new(wait)
+co.yield(1)`, "yield outside the coroutine's body"},
+		{`praise broken():
+  co.yield(1)
+  serve missing
+beef
+prep co = coroutine.new(broken)
+co.resume()
+co.resume()`, "identifier not found: missing"},
+		{`praise wait():
+  co.yield(1)
+beef
+prep co = coroutine.new(wait)
+co.resume(1, 2)`, "resume takes 0 or 1 arguments, got 2"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle coroutine\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Contains(t, errObj.Message, tt.expected, tt.input)
+	}
+}
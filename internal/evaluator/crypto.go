@@ -0,0 +1,88 @@
+package evaluator
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+func createCryptoModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "crypto",
+		Members: make(map[string]object.Object),
+	}
+
+	// sha256 - the SHA-256 digest of a string, in lowercase hex
+	mod.Set("sha256", digestBuiltin("crypto.sha256", sha256.New))
+
+	// md5 - the MD5 digest of a string, in lowercase hex. Fine for checksums,
+	// but not for anything an attacker could tamper with; use sha256 or hmac.
+	mod.Set("md5", digestBuiltin("crypto.md5", md5.New))
+
+	// hmac - the HMAC-SHA256 of a string with a secret key, in lowercase hex:
+	//   prep signature = crypto.hmac(secret, payload)
+	mod.Set("hmac", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			key, message, err := twoStrings("crypto.hmac", args)
+			if err != nil {
+				return err
+			}
+			mac := hmac.New(sha256.New, []byte(key))
+			mac.Write([]byte(message))
+			return &object.String{Value: hex.EncodeToString(mac.Sum(nil))}
+		},
+	})
+
+	// equal - compare two strings in constant time, so checking a signature
+	// doesn't leak how much of it was right through its timing
+	mod.Set("equal", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			a, b, err := twoStrings("crypto.equal", args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1)
+		},
+	})
+
+	return mod
+}
+
+// digestBuiltin is a builtin returning the hex digest of its string argument.
+func digestBuiltin(name string, newHash func() hash.Hash) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount(name, args, 1); err != nil {
+				return err
+			}
+			s, err := object.StringArg(name, args[0])
+			if err != nil {
+				return err
+			}
+			h := newHash()
+			h.Write([]byte(s))
+			return &object.String{Value: hex.EncodeToString(h.Sum(nil))}
+		},
+	}
+}
+
+// twoStrings reads the arguments of a builtin taking two strings.
+func twoStrings(name string, args []object.Object) (string, string, *object.Error) {
+	if err := object.CheckArgCount(name, args, 2); err != nil {
+		return "", "", err
+	}
+	a, err := object.StringArg(name, args[0])
+	if err != nil {
+		return "", "", err
+	}
+	b, err := object.StringArg(name, args[1])
+	if err != nil {
+		return "", "", err
+	}
+	return a, b, nil
+}
@@ -0,0 +1,50 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCryptoModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`crypto.sha256("beef")`, "aa415c4e8890cf0fec7826aec962ffbcc04534faefd2b3266c54f690d40d6e82"},
+		{`crypto.sha256("")`, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{`crypto.md5("")`, "d41d8cd98f00b204e9800998ecf8427e"},
+		// RFC 4231 test case 2
+		{`crypto.hmac("Jefe", "what do ya want for nothing?")`, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{`crypto.equal("abc", "abc")`, "true"},
+		{`crypto.equal("abc", "abd")`, "false"},
+		{`crypto.equal("abc", "abcd")`, "false"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle crypto\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestCryptoErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+	}{
+		{`crypto.sha256(5)`, "crypto.sha256: expected a STRING, got INTEGER"},
+		{`crypto.md5()`, "crypto.md5 takes 1 argument, got 0"},
+		{`crypto.hmac("key")`, "crypto.hmac takes 2 arguments, got 1"},
+		{`crypto.equal("a", true)`, "crypto.equal: expected a STRING, got BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval("wrangle crypto\n" + tt.input).(*object.Error)
+		if assert.True(t, ok, "input %q should fail", tt.input) {
+			assert.Equal(t, diagnostics.CodeBadArgument, errObj.Code, tt.input)
+			assert.Equal(t, tt.message, errObj.Message, tt.input)
+		}
+	}
+}
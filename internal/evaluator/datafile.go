@@ -0,0 +1,214 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// loadDataFile returns the module for wrangle "enemies.json" as enemies: a
+// member for each key of the object the file holds. A relative path is found
+// like a module, in the SearchPath directories. The file is read once; later
+// wrangles of it share the module.
+func (rt *Runtime) loadDataFile(stmt *ast.WrangleStatement) object.Object {
+	name := stmt.DataFile.Value
+	if strings.ToLower(filepath.Ext(name)) != ".json" {
+		return newError(stmt.DataFile.Token, diagnostics.CodeModuleLoadFailed,
+			"can't wrangle %s: only .json data files can be wrangled", name)
+	}
+
+	path, searched := rt.DataFilePath(name)
+	if path == "" {
+		return newError(stmt.DataFile.Token, diagnostics.CodeModuleNotFound, "data file not found: %s, searched: %s",
+			name, strings.Join(searched, ", "))
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil || rt.ModuleFS != nil {
+		absPath = path
+	}
+	modules := &rt.modules
+	modules.mu.Lock()
+	if mod, ok := modules.loaded[absPath]; ok {
+		modules.mu.Unlock()
+		return mod
+	}
+	modules.files[absPath] = true
+	modules.mu.Unlock()
+
+	file, err := rt.openModuleFile(path)
+	if err != nil {
+		return newError(stmt.DataFile.Token, diagnostics.CodeModuleLoadFailed, "could not read data file %s: %v", name, err)
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return newError(stmt.DataFile.Token, diagnostics.CodeModuleLoadFailed, "could not read data file %s: %v", name, err)
+	}
+
+	value, errObj := decodeJSON(data)
+	if errObj != nil {
+		errObj.File = path
+		return errObj
+	}
+	hash, ok := value.(*object.Hash)
+	if !ok {
+		return newError(stmt.DataFile.Token, diagnostics.CodeModuleLoadFailed,
+			"data file %s holds %s, not an object: only an object's keys can be module members", name, value.Type())
+	}
+	mod := &object.Module{Name: name, Members: make(map[string]object.Object)}
+	for _, pair := range hash.Pairs() {
+		mod.Set(pair.Key.(*object.String).Value, pair.Value)
+	}
+
+	modules.mu.Lock()
+	modules.loaded[absPath] = mod
+	modules.mu.Unlock()
+	return mod
+}
+
+// DataFilePath returns the file that wrangling the data file name would
+// load, and the locations searched for it, as ModulePath does for modules.
+// An absolute name is used as is, outside ModuleFS.
+func (rt *Runtime) DataFilePath(name string) (string, []string) {
+	if !filepath.IsAbs(name) || rt.ModuleFS != nil {
+		return rt.findFile(name)
+	}
+	if info, err := os.Stat(name); err == nil && !info.IsDir() {
+		return name, []string{name}
+	}
+	return "", []string{name}
+}
+
+// decodeJSON parses a JSON document into Beeflang values: objects become
+// hashes with string keys, arrays arrays, and numbers integers (BigIntegers
+// when they don't fit). Beeflang has no fractions, so a number with a
+// fraction or an exponent is an error. Errors are positioned in the
+// document, for the caller to name the file.
+func decodeJSON(data []byte) (object.Object, *object.Error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	d := &jsonDecoder{dec: dec, data: data}
+
+	value, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	rest := data[dec.InputOffset():]
+	if trimmed := bytes.TrimLeft(rest, " \t\r\n"); len(trimmed) > 0 {
+		return nil, d.errorAt(int64(len(data)-len(trimmed)), "unexpected text after the end of the document")
+	}
+	return value, nil
+}
+
+// jsonDecoder reads Beeflang values from the tokens of a JSON document.
+type jsonDecoder struct {
+	dec    *json.Decoder
+	data   []byte
+	offset int64 // where the last token started
+}
+
+func (d *jsonDecoder) token() (json.Token, *object.Error) {
+	d.offset = d.dec.InputOffset()
+	tok, err := d.dec.Token()
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		switch {
+		case errors.As(err, &syntaxErr):
+			// The offset is just past the byte the decoder choked on
+			return nil, d.errorAt(syntaxErr.Offset-1, "%s", strings.TrimPrefix(syntaxErr.Error(), "json: "))
+		case err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF):
+			return nil, d.errorAt(int64(len(d.data)), "unexpected end of the document")
+		}
+		return nil, d.errorAt(d.offset, "%v", err)
+	}
+	// InputOffset is where the previous token ended, so skip the separators
+	// and space that come before this one
+	for d.offset < int64(len(d.data)) && strings.IndexByte(" \t\r\n,:", d.data[d.offset]) >= 0 {
+		d.offset++
+	}
+	return tok, nil
+}
+
+func (d *jsonDecoder) value() (object.Object, *object.Error) {
+	tok, err := d.token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			return d.array()
+		}
+		return d.object()
+	case string:
+		return &object.String{Value: tok}, nil
+	case json.Number:
+		if n, convErr := strconv.ParseInt(string(tok), 10, 64); convErr == nil {
+			return &object.Integer{Value: n}, nil
+		}
+		if n, ok := new(big.Int).SetString(string(tok), 10); ok {
+			return &object.BigInteger{Value: n}, nil
+		}
+		return nil, d.errorAt(d.offset, "%s is not an integer, and Beeflang numbers are integers", tok)
+	case bool:
+		return nativeBoolToBooleanObject(tok), nil
+	}
+	return object.NULL, nil
+}
+
+func (d *jsonDecoder) array() (object.Object, *object.Error) {
+	arr := object.NewArray([]object.Object{})
+	for d.dec.More() {
+		el, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr.Push(el)
+	}
+	if _, err := d.token(); err != nil { // ]
+		return nil, err
+	}
+	return arr, nil
+}
+
+func (d *jsonDecoder) object() (object.Object, *object.Error) {
+	hash := object.NewHash()
+	for d.dec.More() {
+		key, err := d.token()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		hash.Set(&object.String{Value: key.(string)}, value)
+	}
+	if _, err := d.token(); err != nil { // }
+		return nil, err
+	}
+	return hash, nil
+}
+
+// errorAt makes an error at a byte offset into the document.
+func (d *jsonDecoder) errorAt(offset int64, format string, a ...any) *object.Error {
+	offset = min(max(offset, 0), int64(len(d.data)))
+	before := d.data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := utf8.RuneCount(before[bytes.LastIndexByte(before, '\n')+1:]) + 1
+	return &object.Error{Code: diagnostics.CodeModuleLoadFailed, Message: fmt.Sprintf(format, a...),
+		Line: line, Column: column}
+}
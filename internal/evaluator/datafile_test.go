@@ -0,0 +1,123 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeDataFile creates dir/name with the given contents
+func writeDataFile(t *testing.T, dir, name, contents string) string {
+	file := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(file, []byte(contents), 0o644))
+	return file
+}
+
+func TestWrangleDataFile(t *testing.T) {
+	dir := t.TempDir()
+	writeDataFile(t, dir, "enemies.json", `{
+  "goblin": {"hp": 7, "loot": ["coin", "dagger"], "boss": false},
+  "dragon": {"hp": 12345678901234567890, "lair": null},
+  "count": -2
+}`)
+	withSearchPath(t, dir)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`enemies.goblin`, `{"hp": 7, "loot": ["coin", "dagger"], "boss": false}`},
+		{`enemies.goblin.loot[1]`, "dagger"},
+		{`enemies.dragon["hp"]`, "12345678901234567890"},
+		{`enemies.dragon.lair`, "null"},
+		{`enemies.count`, "-2"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle \"enemies.json\" as enemies\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+	assert.Equal(t, "7", testEval("wrangle \"enemies.json\" as e expose goblin\ngoblin.hp").Inspect())
+}
+
+func TestWrangleDataFileIsCached(t *testing.T) {
+	dir := t.TempDir()
+	file := writeDataFile(t, dir, "tuning.json", `{"speed": 3}`)
+	withSearchPath(t, dir)
+
+	assert.Equal(t, "3", testEval("wrangle \"tuning.json\" as tuning\ntuning.speed").Inspect())
+	writeDataFile(t, dir, "tuning.json", `{"speed": 4}`)
+	assert.Equal(t, "3", testEval("wrangle \"tuning.json\" as tuning\ntuning.speed").Inspect())
+	assert.Contains(t, Default.LoadedModuleFiles(), file)
+
+	// Forgetting it, as watch mode does when the file changes, reads it again
+	Default.ForgetModule(file)
+	assert.Equal(t, "4", testEval("wrangle \"tuning.json\" as tuning\ntuning.speed").Inspect())
+}
+
+func TestWrangleDataFileFromModuleFS(t *testing.T) {
+	withSearchPath(t, "modules")
+	Default.ModuleFS = fstest.MapFS{"modules/data/items.json": {Data: []byte(`{"sword": 10}`)}}
+	t.Cleanup(func() { Default.ModuleFS = nil })
+
+	assert.Equal(t, "10", testEval("wrangle \"data/items.json\" as items\nitems.sword").Inspect())
+}
+
+func TestWrangleDataFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeDataFile(t, dir, "list.json", `[1, 2]`)
+	writeDataFile(t, dir, "enemies.csv", "name,hp\n")
+	withSearchPath(t, dir)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`wrangle "list.json" as list`, "data file list.json holds ARRAY, not an object: only an object's keys can be module members"},
+		{`wrangle "enemies.csv" as enemies`, "can't wrangle enemies.csv: only .json data files can be wrangled"},
+		{`wrangle "missing.json" as missing`, "data file not found: missing.json, searched: " + filepath.Join(dir, "missing.json")},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q", tt.input)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+		assert.Equal(t, 1, errObj.Line, "points at the wrangle")
+	}
+}
+
+func TestWrangleDataFileParseErrorsPointIntoTheFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		contents string
+		line     int
+		column   int
+		message  string
+	}{
+		{"{\n  \"goblin\": {\"hp\": 7 \"loot\": []}\n}", 2, 22, `invalid character '"' after object key:value pair`},
+		{"{\n  \"speed\": 2.5\n}", 2, 12, "2.5 is not an integer, and Beeflang numbers are integers"},
+		{"{\"a\": 1} x", 1, 10, "unexpected text after the end of the document"},
+		{"{\"é\": \"ü\" ]", 1, 11, "invalid character ']' after object key:value pair"},
+	}
+
+	for i, tt := range tests {
+		withSearchPath(t, dir)
+		file := writeDataFile(t, dir, "bad.json", tt.contents)
+		errObj, ok := testEval(`wrangle "bad.json" as bad`).(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for case %d", i)
+		}
+		assert.Equal(t, diagnostics.CodeModuleLoadFailed, errObj.Code)
+		assert.Equal(t, tt.message, errObj.Message)
+		assert.Equal(t, file, errObj.File)
+		assert.Equal(t, tt.line, errObj.Line, tt.contents)
+		assert.Equal(t, tt.column, errObj.Column, tt.contents)
+	}
+}
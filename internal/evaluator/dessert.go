@@ -0,0 +1,54 @@
+package evaluator
+
+import (
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// evalDessertStatement schedules a call to run when the enclosing function
+// returns. Like 'stampede', the callee and arguments are evaluated now; only
+// the call itself waits. Blocks don't open scopes of their own, so the current
+// environment is the function call's.
+func evalDessertStatement(stmt *ast.DessertStatement, env *Environment) object.Object {
+	if env.Outer() == nil {
+		return newError(stmt.Token, diagnostics.CodeDessertOutsideFunction,
+			"'dessert' can only be used inside a function")
+	}
+
+	call := stmt.Call
+	function := Eval(call.Function, env)
+	if isError(function) {
+		return function
+	}
+
+	args := evalExpressions(call.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+
+	switch fn := function.(type) {
+	case *object.Function:
+		// Skips applyFunction's interrupt check, so cleanup still runs after Ctrl+C
+		env.Defer(func() object.Object { return CallFunction(fn, args...) })
+	case *object.Builtin:
+		env.Defer(func() object.Object { return applyFunction(call.Token, fn, args) })
+	default:
+		return newError(call.Token, diagnostics.CodeNotAFunction, "not a function: %s", function.Type())
+	}
+
+	return object.NULL
+}
+
+// runDesserts runs the calls a function scheduled with 'dessert', most recent
+// first, once its body has finished with result. They all run even if some
+// fail. An error from the body wins over theirs; otherwise the first error
+// from a dessert becomes the function's result.
+func runDesserts(env *Environment, result object.Object) object.Object {
+	for _, call := range env.TakeDeferred() {
+		if errObj, ok := call().(*object.Error); ok && !isError(result) {
+			result = errObj
+		}
+	}
+	return result
+}
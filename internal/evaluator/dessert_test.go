@@ -0,0 +1,127 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// Desserts record what they see in a counter, since assignments inside a
+// function can't change the caller's variables
+const dessertLog = `
+wrangle sync
+prep log = sync.counter()
+praise note(digit):
+   log.set(log.get() * 10 + digit)
+beef
+`
+
+func TestDessertRunsWhenTheFunctionReturns(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int64
+	}{
+		{
+			"most recent first",
+			"praise f():\n   dessert note(1)\n   dessert note(2)\n   note(3)\nbeef\nf()",
+			321,
+		},
+		{
+			"after serve",
+			"praise f():\n   dessert note(1)\n   if true:\n      serve note(2)\n   beef\n   note(3)\nbeef\nf()",
+			21,
+		},
+		{
+			"arguments are evaluated straight away",
+			"praise f():\n   prep d = 1\n   dessert note(d)\n   d = 2\n   note(d)\nbeef\nf()",
+			21,
+		},
+		{
+			"inside loops, once per pass",
+			"praise f():\n   prep i = 0\n   feast while i < 3:\n      i = i + 1\n      dessert note(i)\n   beef\nbeef\nf()",
+			321,
+		},
+		{
+			"each call has its own",
+			"praise inner():\n   dessert note(1)\nbeef\npraise outer():\n   dessert note(2)\n   inner()\n   note(3)\nbeef\nouter()",
+			132,
+		},
+	}
+
+	for _, tt := range tests {
+		result := testEval(dessertLog + tt.input + "\nlog.get()")
+
+		integer, ok := result.(*object.Integer)
+		if assert.True(t, ok, "%s: expected INTEGER, got %s", tt.name, result.Inspect()) {
+			assert.Equal(t, tt.expected, integer.Value, tt.name)
+		}
+	}
+}
+
+func TestDessertDoesNotChangeTheResult(t *testing.T) {
+	input := "praise nothing():\nbeef\npraise f():\n   dessert nothing()\n   serve 42\nbeef\nf()"
+
+	assert.Equal(t, int64(42), testEval(input).(*object.Integer).Value)
+}
+
+func TestDessertErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		code    string
+		message string
+		line    int
+	}{
+		// An error from a dessert fails the call...
+		{"praise f():\n   dessert g()\n   serve 1\nbeef\npraise g():\n   serve 1 + true\nbeef\nf()",
+			diagnostics.CodeTypeMismatch, "type mismatch: INTEGER + BOOLEAN", 6},
+		// ...but the body's own error is the one reported
+		{"praise f():\n   dessert g()\n   nope\nbeef\npraise g():\n   serve 1 + true\nbeef\nf()",
+			diagnostics.CodeIdentifierNotFound, "identifier not found: nope", 3},
+		{"praise f():\n   dessert 5()\nbeef\nf()", diagnostics.CodeNotAFunction, "not a function: INTEGER", 2},
+		{"dessert f()", diagnostics.CodeDessertOutsideFunction, "'dessert' can only be used inside a function", 1},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if assert.True(t, ok, "input %q should fail", tt.input) {
+			assert.Equal(t, tt.code, errObj.Code, tt.input)
+			assert.Equal(t, tt.message, errObj.Message, tt.input)
+			assert.Equal(t, tt.line, errObj.Line, tt.input)
+		}
+	}
+}
+
+// evalIn evaluates input in env, so a test can look around after an error
+func evalIn(env *Environment, input string) object.Object {
+	return Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+}
+
+func TestDessertRunsAfterAnError(t *testing.T) {
+	env := NewEnvironment()
+	evalIn(env, dessertLog+"praise f():\n   dessert note(1)\n   1 + true\n   note(2)\nbeef")
+
+	result := evalIn(env, "f()")
+	assert.Equal(t, diagnostics.CodeTypeMismatch, result.(*object.Error).Code)
+	assert.Equal(t, "1", evalIn(env, "log.get()").Inspect())
+}
+
+func TestDessertRunsAfterAnInterrupt(t *testing.T) {
+	t.Cleanup(Default.ResetInterrupt)
+	env := NewEnvironment()
+	env.Set("interrupt", &object.Builtin{Fn: func(args ...object.Object) object.Object {
+		Default.Interrupt()
+		return object.NULL
+	}})
+	evalIn(env, dessertLog+"praise f():\n   dessert note(7)\n   feast while true:\n      interrupt()\n   beef\nbeef")
+
+	result := evalIn(env, "f()")
+	assert.Equal(t, diagnostics.CodeInterrupted, result.(*object.Error).Code)
+
+	Default.ResetInterrupt()
+	assert.Equal(t, "7", evalIn(env, "log.get()").Inspect())
+}
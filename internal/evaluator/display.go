@@ -0,0 +1,56 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+func init() {
+	object.CallStr = func(fn object.Object, h *object.Hash) (string, bool) {
+		s, ok := callStr(fn, h).(*object.String)
+		if !ok {
+			return "", false
+		}
+		return s.Value, true
+	}
+}
+
+// callStr calls a hash's __str function, which must serve a string.
+func callStr(fn object.Object, h *object.Hash) object.Object {
+	var args []object.Object
+	f, ok := fn.(*object.Function)
+	switch {
+	case ok && len(f.Parameters) > 1:
+		return &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s must take the hash or nothing, got a function of %d parameters", object.StrMember, len(f.Parameters))}
+	case !ok || len(f.Parameters) == 1:
+		args = []object.Object{h}
+	}
+	result := applyFunction(token.Token{}, fn, args)
+	if isError(result) {
+		return result
+	}
+	if _, ok := result.(*object.String); !ok {
+		return &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s must serve a STRING, got %s", object.StrMember, result.Type())}
+	}
+	return result
+}
+
+// displayValue resolves the value io.preach and io.format show for obj: the
+// text a hash's __str function serves, or obj itself. Unlike Inspect, which
+// falls back to the usual rendering, it reports a __str function's error.
+func displayValue(obj object.Object) object.Object {
+	h, ok := obj.(*object.Hash)
+	if !ok {
+		return obj
+	}
+	fn, ok := h.Get(object.StrMember)
+	if !ok {
+		return obj
+	}
+	return callStr(fn, h)
+}
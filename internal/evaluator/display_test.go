@@ -0,0 +1,108 @@
+package evaluator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+const player = `wrangle io
+praise show_player(p):
+  serve p["name"] + " the " + p["class"]
+beef
+prep bubba = {"name": "Bubba", "class": "Pitmaster", "__str": show_player}
+`
+
+func TestStrMember(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"io.preach(bubba)", "Bubba the Pitmaster\n"},
+		{"io.preach([bubba, 1])", "[Bubba the Pitmaster, 1]\n"},
+		{"io.preach({\"leader\": bubba})", "{\"leader\": Bubba the Pitmaster}\n"},
+		{"io.preachf(\"[{:>22}]\", bubba)", "[   Bubba the Pitmaster]\n"},
+		// A __str function can also take no parameters and use its closure
+		{`praise hidden():
+  serve "???"
+beef
+io.preach({"name": "Earl", "__str": hidden})`, "???\n"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		old := Default.Stdout
+		Default.Stdout = &out
+		result := testEval(player + tt.input)
+		Default.Stdout = old
+		assert.False(t, isError(result), "%s: %s", tt.input, result.Inspect())
+		assert.Equal(t, tt.expected, out.String(), tt.input)
+	}
+}
+
+func TestStrMemberErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`praise bad(p):
+  serve 5
+beef
+io.preach({"__str": bad})`, "__str must serve a STRING, got INTEGER"},
+		{`praise bad(p):
+  serve p["missing"] + 1
+beef
+io.format("{}", {"__str": bad})`, "type mismatch: NULL + INTEGER"},
+		{`praise bad(a, b):
+  serve a
+beef
+io.preach({"__str": bad})`, "__str must take the hash or nothing, got a function of 2 parameters"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle io\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if assert.True(t, ok, "%s: got %s", tt.input, result.Inspect()) {
+			assert.Contains(t, errObj.Message, tt.expected, tt.input)
+		}
+	}
+
+	// Inspect can't report errors, so it shows the hash as usual instead
+	result := testEval(`praise bad(p):
+  serve 5
+beef
+{"hp": 1, "__str": bad}`)
+	assert.Equal(t, `{"hp": 1, "__str": <function bad(p)>}`, result.Inspect())
+}
+
+func TestFunctionInspect(t *testing.T) {
+	result := testEval(`praise add(x, y):
+  serve x + y
+beef
+add`)
+	assert.Equal(t, "<function add(x, y)>", result.Inspect())
+}
+
+func TestIOInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`io.inspect("1")`, `"1"`},
+		{`io.inspect(1)`, "1"},
+		{`io.inspect(5n)`, "5n"},
+		{`io.inspect(["rib", 2, [true]])`, `["rib", 2, [true]]`},
+		{`io.inspect(bubba)`, `{"name": "Bubba", "class": "Pitmaster", "__str": <function show_player(p)>}`},
+		{`io.inspect(io)`, "<module 'io'>"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(player + tt.input)
+		str, ok := result.(*object.String)
+		if assert.True(t, ok, "%s: got %s", tt.input, result.Inspect()) {
+			assert.Equal(t, tt.expected, str.Value, tt.input)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// DefaultEntryPoint is the function programs start in unless told otherwise.
+const DefaultEntryPoint = "ChurchOfBeef"
+
+// CallEntryPoint calls the entry point function of a program whose top level
+// has been evaluated in env, including its 'dessert' calls. An entry point
+// taking parameters needs exactly one argument for each; one taking none is
+// called without arguments whatever args holds, since a program's command
+// line is always there for it as os.args.
+func CallEntryPoint(env *Environment, args ...object.Object) object.Object {
+	entryPoint := runtimeOf(env).EntryPoint
+	value, ok := env.Get(entryPoint)
+	if !ok {
+		return &object.Error{Code: diagnostics.CodeNoEntryPoint,
+			Message: fmt.Sprintf("no %s() entry point function found", entryPoint)}
+	}
+	fn, ok := value.(*object.Function)
+	if !ok {
+		return &object.Error{Code: diagnostics.CodeNoEntryPoint,
+			Message: fmt.Sprintf("%s is not a function", entryPoint)}
+	}
+
+	if len(fn.Parameters) == 0 {
+		return CallFunction(fn)
+	}
+	if len(args) != len(fn.Parameters) {
+		return &object.Error{Code: diagnostics.CodeNoEntryPoint,
+			Message: fmt.Sprintf("%s() takes %d arguments, got %d", entryPoint, len(fn.Parameters), len(args))}
+	}
+	return CallFunction(fn, args...)
+}
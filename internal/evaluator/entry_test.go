@@ -0,0 +1,58 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// withEntryPoint sets Default.EntryPoint for the rest of the test
+func withEntryPoint(t *testing.T, name string) {
+	Default.EntryPoint = name
+	t.Cleanup(func() { Default.EntryPoint = DefaultEntryPoint })
+}
+
+const scenarios = `praise ChurchOfBeef():
+   serve "main"
+beef
+praise smoke(cut, hours):
+   serve cut + " for " + hours + " hours"
+beef
+prep notAFunction = 1`
+
+func TestCallEntryPoint(t *testing.T) {
+	env := NewEnvironment()
+	evalIn(env, scenarios)
+
+	// An entry point without parameters ignores the arguments
+	assert.Equal(t, "main", CallEntryPoint(env, &object.String{Value: "extra"}).Inspect())
+
+	withEntryPoint(t, "smoke")
+	result := CallEntryPoint(env, &object.String{Value: "brisket"}, &object.String{Value: "12"})
+	assert.Equal(t, "brisket for 12 hours", result.Inspect())
+}
+
+func TestCallEntryPointErrors(t *testing.T) {
+	env := NewEnvironment()
+	evalIn(env, scenarios)
+
+	tests := []struct {
+		entry    string
+		expected string
+	}{
+		{"smoke", "smoke() takes 2 arguments, got 0"},
+		{"missing", "no missing() entry point function found"},
+		{"notAFunction", "notAFunction is not a function"},
+	}
+
+	for _, tt := range tests {
+		withEntryPoint(t, tt.entry)
+		errObj, ok := CallEntryPoint(env).(*object.Error)
+		if assert.True(t, ok, "expected an error for %s", tt.entry) {
+			assert.Equal(t, diagnostics.CodeNoEntryPoint, errObj.Code)
+			assert.Equal(t, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package evaluator
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/elitwilson/beeflang/internal/object"
@@ -83,3 +85,48 @@ func TestEnvironmentShadowing(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, int64(10), x.(*object.Integer).Value, "Outer scope should still have original value")
 }
+
+func TestEnvironmentConcurrentAccess(t *testing.T) {
+	// Tasks read shared outer scopes while the program keeps writing to them;
+	// run with -race to check
+	outer := NewEnvironment()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			inner := NewEnclosedEnvironment(outer)
+			for j := 0; j < 100; j++ {
+				outer.Set(fmt.Sprintf("x%d", i), &object.Integer{Value: int64(j)})
+				inner.Set("y", &object.Integer{Value: int64(j)})
+				inner.Get("x0")
+				outer.Bindings()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, outer.Bindings(), 8)
+}
+
+func TestEnvironmentSnapshot(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &object.Integer{Value: 1})
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("y", &object.Integer{Value: 2})
+
+	snapshot := inner.Snapshot()
+	snapshot.Set("y", &object.Integer{Value: 20})
+	snapshot.Outer().Set("x", &object.Integer{Value: 10})
+	outer.Set("z", &object.Integer{Value: 3})
+
+	// Neither side sees the other's changes
+	y, _ := inner.Get("y")
+	x, _ := inner.Get("x")
+	assert.Equal(t, int64(2), y.(*object.Integer).Value)
+	assert.Equal(t, int64(1), x.(*object.Integer).Value)
+	x, _ = snapshot.Get("x")
+	assert.Equal(t, int64(10), x.(*object.Integer).Value)
+	_, ok := snapshot.Get("z")
+	assert.False(t, ok)
+}
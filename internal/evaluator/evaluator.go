@@ -1,11 +1,14 @@
 package evaluator
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
-	"os"
+	"math/big"
+	"sort"
+	"strings"
 
 	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
 	"github.com/elitwilson/beeflang/internal/object"
 	"github.com/elitwilson/beeflang/internal/token"
 )
@@ -13,6 +16,18 @@ import (
 // Eval evaluates an AST node and returns the resulting runtime object.
 // This is the core of the interpreter - it walks the AST and executes the code.
 func Eval(node ast.Node, env *Environment) object.Object {
+	rt := runtimeOf(env)
+	if s := rt.slicer.Load(); s != nil {
+		s.step()
+	}
+	if rt.CollectStats {
+		return evalCounted(rt, node, env)
+	}
+	return evalNode(node, env)
+}
+
+// evalNode does the work of Eval.
+func evalNode(node ast.Node, env *Environment) object.Object {
 	switch n := node.(type) {
 
 	// Program: evaluate all statements and return the last result
@@ -23,12 +38,24 @@ func Eval(node ast.Node, env *Environment) object.Object {
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: n.Value}
 
+	case *ast.BigIntegerLiteral:
+		return &object.BigInteger{Value: n.Value}
+
 	case *ast.BooleanLiteral:
 		return nativeBoolToBooleanObject(n.Value)
 
 	case *ast.StringLiteral:
 		return &object.String{Value: n.Value}
 
+	case *ast.ArrayLiteral:
+		return evalArrayLiteral(n, env)
+
+	case *ast.HashLiteral:
+		return evalHashLiteral(n, env)
+
+	case *ast.IndexExpression:
+		return evalIndexExpression(n, env)
+
 	// Identifiers: look up variable in environment
 	case *ast.Identifier:
 		return evalIdentifier(n, env)
@@ -50,10 +77,16 @@ func Eval(node ast.Node, env *Environment) object.Object {
 		if isError(right) {
 			return right
 		}
+		if err := checkStrictOperands(n.Token, n.Operator, left, right, env); err != nil {
+			return err
+		}
 		return evalInfixExpression(n.Token, n.Operator, left, right)
 
 	// Statements
 	case *ast.VariableDeclaration:
+		if err := checkStrictDeclaration(n, env); err != nil {
+			return err
+		}
 		val := Eval(n.Value, env)
 		env.Set(n.Name.Value, val)
 		return val
@@ -67,9 +100,18 @@ func Eval(node ast.Node, env *Environment) object.Object {
 	case *ast.IfStatement:
 		return evalIfStatement(n, env)
 
+	case *ast.IfExpression:
+		return evalIfExpression(n, env)
+
 	case *ast.WhileLoop:
 		return evalWhileLoop(n, env)
 
+	case *ast.ForEachLoop:
+		return evalForEachLoop(n, env)
+
+	case *ast.YieldStatement:
+		return evalYieldStatement(n, env)
+
 	case *ast.FunctionDeclaration:
 		return evalFunctionDeclaration(n, env)
 
@@ -79,9 +121,34 @@ func Eval(node ast.Node, env *Environment) object.Object {
 	case *ast.FunctionCall:
 		return evalFunctionCall(n, env)
 
+	case *ast.PipeExpression:
+		return evalPipeExpression(n, env)
+
+	case *ast.StampedeStatement:
+		return evalStampedeStatement(n, env)
+
+	case *ast.SelectStatement:
+		return evalSelectStatement(n, env)
+
+	case *ast.AssertStatement:
+		return evalAssertStatement(n, env)
+
+	case *ast.DessertStatement:
+		return evalDessertStatement(n, env)
+
+	case *ast.UsingStatement:
+		return evalUsingStatement(n, env)
+
 	case *ast.WrangleStatement:
 		return evalWrangleStatement(n, env)
 
+	case *ast.PastureStatement:
+		// Read when the file is loaded as a module (see loadModuleFile)
+		return object.NULL
+
+	case *ast.ExposeStatement:
+		return evalExposeStatement(n, env)
+
 	case *ast.MemberAccessExpression:
 		return evalMemberAccessExpression(n, env)
 
@@ -93,11 +160,23 @@ func Eval(node ast.Node, env *Environment) object.Object {
 	return nil
 }
 
-// evalProgram evaluates all statements in a program and returns the last result
+// evalProgram evaluates all statements in a program and returns the last result.
+// Top-level functions are hoisted, so declaration order doesn't matter. A
+// decorated function isn't: its decorators can use anything declared above
+// it, so it's declared when its declaration is reached.
 func evalProgram(program *ast.Program, env *Environment) object.Object {
 	var result object.Object
+	hoisted := hoistFunctions(program, env)
 
 	for _, statement := range program.Statements {
+		// A hoisted function is declared again where it appears, so a later
+		// declaration of the same name still wins as it would in one pass
+		if decl, ok := statement.(*ast.FunctionDeclaration); ok && len(decl.Decorators) == 0 {
+			env.Set(decl.Name.Value, hoisted[decl])
+			result = hoisted[decl]
+			continue
+		}
+
 		result = Eval(statement, env)
 
 		// Stop evaluation if we hit an error
@@ -114,11 +193,32 @@ func evalProgram(program *ast.Program, env *Environment) object.Object {
 	return result
 }
 
+// hoistFunctions declares every top-level function of program in env before
+// any other statement runs. A function already declared from the same
+// declaration is reused, so hoisting a program twice is harmless.
+func hoistFunctions(program *ast.Program, env *Environment) map[*ast.FunctionDeclaration]*object.Function {
+	hoisted := map[*ast.FunctionDeclaration]*object.Function{}
+	for _, stmt := range program.Statements {
+		decl, ok := stmt.(*ast.FunctionDeclaration)
+		if !ok || len(decl.Decorators) > 0 {
+			continue
+		}
+		if existing, ok := env.GetLocal(decl.Name.Value); ok {
+			if fn, ok := existing.(*object.Function); ok && fn.Body == decl.Body {
+				hoisted[decl] = fn
+				continue
+			}
+		}
+		hoisted[decl] = evalFunctionDeclaration(decl, env).(*object.Function)
+	}
+	return hoisted
+}
+
 // evalIdentifier looks up a variable in the environment
 func evalIdentifier(node *ast.Identifier, env *Environment) object.Object {
 	val, ok := env.Get(node.Value)
 	if !ok {
-		return newError(node.Token, "identifier not found: %s", node.Value)
+		return newError(node.Token, diagnostics.CodeIdentifierNotFound, "identifier not found: %s%s", node.Value, suggestName(node.Value, env))
 	}
 	return val
 }
@@ -131,7 +231,7 @@ func evalPrefixExpression(tok token.Token, operator string, right object.Object)
 	case "-":
 		return evalMinusPrefixOperator(tok, right)
 	default:
-		return newError(tok, "unknown operator: %s%s", operator, right.Type())
+		return newError(tok, diagnostics.CodeUnknownOperator, "unknown operator: %s%s", operator, right.Type())
 	}
 }
 
@@ -151,25 +251,51 @@ func evalBangOperator(right object.Object) object.Object {
 
 // evalMinusPrefixOperator implements the - (negation) operator
 func evalMinusPrefixOperator(tok token.Token, right object.Object) object.Object {
+	if n, ok := right.(*object.BigInteger); ok {
+		return &object.BigInteger{Value: new(big.Int).Neg(n.Value)}
+	}
 	if right.Type() != "INTEGER" {
-		return newError(tok, "unknown operator: -%s", right.Type())
+		return newError(tok, diagnostics.CodeUnknownOperator, "unknown operator: -%s", right.Type())
 	}
 
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+	value, ok := checkedNeg(right.(*object.Integer).Value)
+	if !ok {
+		return newError(tok, diagnostics.CodeIntegerOverflow, "integer overflow: -(%d)", right.(*object.Integer).Value)
+	}
+	return &object.Integer{Value: value}
 }
 
 // evalInfixExpression evaluates infix expressions like 5 + 3 or 10 > 5
 func evalInfixExpression(tok token.Token, operator string, left, right object.Object) object.Object {
 	switch {
+	// Membership: x in items
+	case operator == "in":
+		return evalInExpression(tok, left, right)
+
 	// Integer operations
 	case left.Type() == "INTEGER" && right.Type() == "INTEGER":
 		return evalIntegerInfixExpression(tok, operator, left, right)
+	case isBigArithmetic(left, right):
+		return evalBigIntegerInfixExpression(tok, operator, left, right)
 
 	// String concatenation
 	case left.Type() == "STRING" && right.Type() == "STRING":
 		return evalStringInfixExpression(tok, operator, left, right)
 
+	// Vectors are values, equal when their components are
+	case left.Type() == "VECTOR" && right.Type() == "VECTOR" && (operator == "==" || operator == "!="):
+		equal := left.(*object.Vector).Equal(right.(*object.Vector))
+		return nativeBoolToBooleanObject(equal == (operator == "=="))
+
+	// So are bytes, equal when their contents are
+	case left.Type() == "BYTES" && right.Type() == "BYTES" && (operator == "==" || operator == "!="):
+		equal := bytes.Equal(left.(*object.Bytes).Value, right.(*object.Bytes).Value)
+		return nativeBoolToBooleanObject(equal == (operator == "=="))
+
+	// Durations combine with each other and scale by integers
+	case isDurationArithmetic(left, right):
+		return evalDurationInfixExpression(tok, operator, left, right)
+
 	// Boolean comparison (using pointer equality optimization)
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right)
@@ -178,10 +304,10 @@ func evalInfixExpression(tok token.Token, operator string, left, right object.Ob
 
 	// Type mismatch
 	case left.Type() != right.Type():
-		return newError(tok, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
+		return newError(tok, diagnostics.CodeTypeMismatch, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
 
 	default:
-		return newError(tok, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newError(tok, diagnostics.CodeUnknownOperator, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -192,16 +318,33 @@ func evalIntegerInfixExpression(tok token.Token, operator string, left, right ob
 
 	switch operator {
 	// Arithmetic
-	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
-	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
-	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
-	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
-	case "%":
-		return &object.Integer{Value: leftVal % rightVal}
+	case "+", "-", "*":
+		var result int64
+		var ok bool
+		switch operator {
+		case "+":
+			result, ok = checkedAdd(leftVal, rightVal)
+		case "-":
+			result, ok = checkedSub(leftVal, rightVal)
+		case "*":
+			result, ok = checkedMul(leftVal, rightVal)
+		}
+		if !ok {
+			return newError(tok, diagnostics.CodeIntegerOverflow, "integer overflow: %d %s %d", leftVal, operator, rightVal)
+		}
+		return &object.Integer{Value: result}
+	case "/", "%":
+		if rightVal == 0 {
+			return newError(tok, diagnostics.CodeDivisionByZero, "division by zero: %d %s 0", leftVal, operator)
+		}
+		if operator == "%" {
+			return &object.Integer{Value: leftVal % rightVal}
+		}
+		result, ok := checkedDiv(leftVal, rightVal)
+		if !ok {
+			return newError(tok, diagnostics.CodeIntegerOverflow, "integer overflow: %d / %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: result}
 
 	// Comparison
 	case "<":
@@ -218,7 +361,7 @@ func evalIntegerInfixExpression(tok token.Token, operator string, left, right ob
 		return nativeBoolToBooleanObject(leftVal >= rightVal)
 
 	default:
-		return newError(tok, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newError(tok, diagnostics.CodeUnknownOperator, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -235,7 +378,7 @@ func evalStringInfixExpression(tok token.Token, operator string, left, right obj
 	case "!=":
 		return nativeBoolToBooleanObject(leftVal != rightVal)
 	default:
-		return newError(tok, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newError(tok, diagnostics.CodeUnknownOperator, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
@@ -273,6 +416,9 @@ func evalBlockStatement(block *ast.BlockStatement, env *Environment) object.Obje
 // evalIfStatement evaluates an if/else statement
 func evalIfStatement(ifStmt *ast.IfStatement, env *Environment) object.Object {
 	condition := Eval(ifStmt.Condition, env)
+	if isError(condition) {
+		return condition
+	}
 
 	if isTruthy(condition) {
 		return Eval(ifStmt.Consequence, env)
@@ -283,6 +429,16 @@ func evalIfStatement(ifStmt *ast.IfStatement, env *Environment) object.Object {
 	}
 }
 
+// evalIfExpression evaluates an if used as a value. A branch yields the value
+// of its last statement, and an empty branch yields null like a missing one.
+func evalIfExpression(ie *ast.IfExpression, env *Environment) object.Object {
+	result := evalIfStatement(ie.If, env)
+	if result == nil {
+		return object.NULL
+	}
+	return result
+}
+
 // isTruthy determines if an object is "truthy" for conditionals
 // In Beeflang: false and null are falsy, everything else is truthy
 func isTruthy(obj object.Object) bool {
@@ -304,6 +460,11 @@ func evalFunctionDeclaration(fn *ast.FunctionDeclaration, env *Environment) obje
 		Parameters: fn.Parameters,
 		Body:       fn.Body,
 		Env:        env, // Capture current environment (closure)
+		Name:       fn.Name.Value,
+		Doc:        fn.DocText(),
+	}
+	if len(fn.Decorators) > 0 {
+		return decorate(fn, function, env)
 	}
 
 	// Store the function in the environment by its name
@@ -312,13 +473,77 @@ func evalFunctionDeclaration(fn *ast.FunctionDeclaration, env *Environment) obje
 	return function
 }
 
+// decorate passes a declared function through its decorators and binds what
+// comes out to the function's name. Every decorator expression is evaluated,
+// top to bottom, before any is called; then they're called bottom to top, so
+// the one nearest 'praise' wraps the function first. The name is bound only
+// once they have all succeeded.
+func decorate(decl *ast.FunctionDeclaration, function *object.Function, env *Environment) object.Object {
+	decorators := make([]object.Object, len(decl.Decorators))
+	for i, d := range decl.Decorators {
+		decorators[i] = Eval(d.Expression, env)
+		if isError(decorators[i]) {
+			return decorators[i]
+		}
+		if fn, ok := decorators[i].(*object.Function); ok && len(fn.Parameters) != 1 {
+			return newError(d.Token, diagnostics.CodeBadArgument,
+				"a decorator must be a function of 1 parameter, got one of %d", len(fn.Parameters))
+		}
+	}
+
+	var result object.Object = function
+	for i := len(decorators) - 1; i >= 0; i-- {
+		result = applyFunction(decl.Decorators[i].Token, decorators[i], []object.Object{result})
+		if isError(result) {
+			return result
+		}
+	}
+
+	env.Set(decl.Name.Value, result)
+	return result
+}
+
 // evalReturnStatement evaluates a return statement
 func evalReturnStatement(stmt *ast.ReturnStatement, env *Environment) object.Object {
 	val := Eval(stmt.ReturnValue, env)
+	if isError(val) {
+		return val
+	}
 	// Wrap in ReturnValue to signal this is an early return
 	return &object.ReturnValue{Value: val}
 }
 
+// evalAssertStatement fails with an error quoting the condition (and the
+// message, if there is one) when the condition is falsy.
+func evalAssertStatement(stmt *ast.AssertStatement, env *Environment) object.Object {
+	condition := Eval(stmt.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+	if isTruthy(condition) {
+		return object.NULL
+	}
+
+	message := "assertion failed: " + ast.Format(stmt.Condition)
+	if stmt.Message != nil {
+		value := Eval(stmt.Message, env)
+		if isError(value) {
+			return value
+		}
+		message += " - " + value.Inspect()
+	}
+
+	start, end := stmt.Condition.Start(), stmt.Condition.End()
+	return &object.Error{
+		Code:      diagnostics.CodeAssertionFailed,
+		Message:   message,
+		Line:      start.Line,
+		Column:    start.Column,
+		EndLine:   end.Line,
+		EndColumn: end.Column,
+	}
+}
+
 // evalFunctionCall evaluates a function call expression
 func evalFunctionCall(call *ast.FunctionCall, env *Environment) object.Object {
 	// Evaluate the function expression (usually an identifier or member access)
@@ -326,6 +551,10 @@ func evalFunctionCall(call *ast.FunctionCall, env *Environment) object.Object {
 	if isError(function) {
 		return function
 	}
+	// obj?.method() is null, arguments unevaluated, when obj?.method is
+	if member, ok := call.Function.(*ast.MemberAccessExpression); ok && member.Optional && function == object.NULL {
+		return object.NULL
+	}
 
 	// Evaluate all arguments
 	args := evalExpressions(call.Arguments, env)
@@ -334,32 +563,121 @@ func evalFunctionCall(call *ast.FunctionCall, env *Environment) object.Object {
 		return args[0]
 	}
 
+	return applyFunctionIn(env, call.Token, function, args)
+}
+
+// evalPipeExpression calls the right side of value |> f(args) with value
+// first: f(value, args). The value is evaluated before anything else, as it
+// comes first in the source.
+func evalPipeExpression(pipe *ast.PipeExpression, env *Environment) object.Object {
+	value := Eval(pipe.Left, env)
+	if isError(value) {
+		return value
+	}
+
+	call := pipe.Call()
+	function := Eval(call.Function, env)
+	if isError(function) {
+		return function
+	}
+
+	args := evalExpressions(call.Arguments[1:], env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+
+	return applyFunctionIn(env, call.Token, function, append([]object.Object{value}, args...))
+}
+
+// applyFunctionIn is applyFunction for a call written in the program, made
+// in env: a builtin with an EnvFn gets env too.
+func applyFunctionIn(env *Environment, tok token.Token, function object.Object, args []object.Object) object.Object {
+	if builtin, ok := function.(*object.Builtin); ok && builtin.EnvFn != nil {
+		return locateBuiltinError(tok, builtin.EnvFn(env, args...))
+	}
+	return applyFunction(tok, function, args)
+}
+
+// applyFunction calls a function (or builtin) with already evaluated arguments.
+// tok locates the call for errors.
+func applyFunction(tok token.Token, function object.Object, args []object.Object) object.Object {
 	// Check if it's a builtin function
 	if builtin, ok := function.(*object.Builtin); ok {
-		return builtin.Fn(args...)
+		return locateBuiltinError(tok, builtin.Fn(args...))
 	}
 
 	// Check if it's a user-defined function
 	fn, ok := function.(*object.Function)
 	if !ok {
 		// Not a function - error
-		return newError(call.Token, "not a function: %s", function.Type())
+		return newError(tok, diagnostics.CodeNotAFunction, "not a function: %s", function.Type())
+	}
+
+	if err := runtimeOf(fn.Env).checkInterrupt(tok); err != nil {
+		return err
+	}
+
+	return callFunction(tok, fn, args)
+}
+
+// locateBuiltinError gives an error a builtin returned the position of the
+// call, since builtins don't know where they were called from.
+func locateBuiltinError(tok token.Token, result object.Object) object.Object {
+	if errObj, ok := result.(*object.Error); ok && errObj.Line == 0 {
+		errObj.Line, errObj.Column = tok.Line, tok.Column
+		errObj.EndLine, errObj.EndColumn = tok.End.Line, tok.End.Column
+	}
+	return result
+}
+
+// CallFunction calls a user-defined function with one argument per parameter,
+// as a call in the program would: the body runs in a new scope enclosed by the
+// function's closure, followed by any calls it scheduled with 'dessert'. A
+// call with the wrong number of arguments is an error at the declaration.
+func CallFunction(fn *object.Function, args ...object.Object) object.Object {
+	return callFunction(fn.Body.Token, fn, args)
+}
+
+// callFunction is CallFunction for a call at tok, which locates the error if
+// the number of arguments is wrong.
+func callFunction(tok token.Token, fn *object.Function, args []object.Object) object.Object {
+	if len(args) != len(fn.Parameters) {
+		err := object.CheckArgCount(fn.Name+"()", args, len(fn.Parameters))
+		return newError(tok, diagnostics.CodeWrongArity, "%s", err.Message)
 	}
 
 	// Create new environment for function execution (enclosed by function's closure env)
 	fnEnv := object.NewEnclosedEnvironment(fn.Env)
+	if rt := runtimeOf(fnEnv); rt.CollectStats {
+		rt.countScope(fnEnv)
+	}
 
 	// Bind parameters to arguments
 	for i, param := range fn.Parameters {
 		fnEnv.Set(param.Value, args[i])
 	}
 
-	// Execute function body
+	// A generator function's body runs as its values are asked for
+	if isGenerator(fn) {
+		return newGenerator(fn, fnEnv)
+	}
+
+	return runFunctionBody(fn, fnEnv)
+}
+
+// runFunctionBody runs a function's body in fnEnv, the scope of the call,
+// and returns the call's result.
+func runFunctionBody(fn *object.Function, fnEnv *Environment) object.Object {
+	// Execute function body, then its desserts
 	result := Eval(fn.Body, fnEnv)
+	result = runDesserts(fnEnv, result)
 
-	// Propagate errors from function body
-	if isError(result) {
-		return result
+	// Propagate errors from function body, saying which file they came from
+	if errObj, ok := result.(*object.Error); ok {
+		if errObj.File == "" {
+			errObj.File = fn.File
+		}
+		return errObj
 	}
 
 	// Only return a value if there was an explicit "serve" statement
@@ -373,11 +691,16 @@ func evalFunctionCall(call *ast.FunctionCall, env *Environment) object.Object {
 }
 
 // evalExpressions evaluates a list of expressions (used for function arguments)
+// in order. If one fails, the rest aren't evaluated and the result is just
+// the error, so callers check for a single error.
 func evalExpressions(exps []ast.Expression, env *Environment) []object.Object {
 	result := []object.Object{}
 
 	for _, exp := range exps {
 		evaluated := Eval(exp, env)
+		if isError(evaluated) {
+			return []object.Object{evaluated}
+		}
 		result = append(result, evaluated)
 	}
 
@@ -386,6 +709,9 @@ func evalExpressions(exps []ast.Expression, env *Environment) []object.Object {
 
 // evalAssignmentStatement handles variable reassignment (x = value)
 func evalAssignmentStatement(stmt *ast.AssignmentStatement, env *Environment) object.Object {
+	if err := checkStrictAssignment(stmt, env); err != nil {
+		return err
+	}
 	val := Eval(stmt.Value, env)
 	env.Set(stmt.Name.Value, val)
 	return val
@@ -396,7 +722,14 @@ func evalWhileLoop(loop *ast.WhileLoop, env *Environment) object.Object {
 	var result object.Object = object.NULL
 
 	for {
+		if err := runtimeOf(env).checkInterrupt(loop.Token); err != nil {
+			return err
+		}
+
 		condition := Eval(loop.Condition, env)
+		if isError(condition) {
+			return condition
+		}
 
 		if !isTruthy(condition) {
 			break
@@ -404,8 +737,8 @@ func evalWhileLoop(loop *ast.WhileLoop, env *Environment) object.Object {
 
 		result = Eval(loop.Body, env)
 
-		// Check for early return from within the loop
-		if result != nil && result.Type() == "RETURN_VALUE" {
+		// Check for early return or an error from within the loop
+		if isError(result) || (result != nil && result.Type() == "RETURN_VALUE") {
 			return result
 		}
 	}
@@ -414,81 +747,219 @@ func evalWhileLoop(loop *ast.WhileLoop, env *Environment) object.Object {
 }
 
 func evalWrangleStatement(stmt *ast.WrangleStatement, env *Environment) object.Object {
-	// Load module by name
-	moduleName := stmt.ModuleName.Value
-	mod := loadModule(moduleName)
+	// Load module by name (built-in or from the module search path)
+	loaded := runtimeOf(env).loadModule(stmt)
+	if isError(loaded) {
+		return loaded
+	}
+	mod := loaded.(*object.Module)
+	if len(stmt.Exposed) > 0 || (stmt.Alias == nil && mod.Pasture != "") {
+		if errObj := runtimeOf(env).readyModule(mod, stmt.Token); errObj != nil {
+			return errObj
+		}
+	}
+
+	// wrangle io expose preach, input - bind the chosen members directly
+	for _, name := range stmt.Exposed {
+		if isPrivateMember(name.Value) {
+			return newError(name.Token, diagnostics.CodePrivateMember, "member '%s' is private to module '%s'", name.Value, mod.Name)
+		}
+		member, found := mod.Get(name.Value)
+		if !found {
+			return newError(name.Token, diagnostics.CodeNoSuchMember, "module '%s' has no member '%s'", mod.Name, name.Value)
+		}
+		env.Set(name.Value, member)
+	}
 
-	// Store module in environment
-	env.Set(moduleName, mod)
+	// Store module in environment, under its alias if one was given.
+	// A plain expose list only brings in the listed members, and a module
+	// in a pasture goes into its namespace.
+	if stmt.Alias != nil {
+		env.Set(stmt.Alias.Value, mod)
+	} else if len(stmt.Exposed) == 0 && mod.Pasture != "" {
+		if errObj := bindPasture(stmt, env, mod); errObj != nil {
+			return errObj
+		}
+	} else if len(stmt.Exposed) == 0 {
+		env.Set(stmt.ModuleName.Value, mod)
+	}
 
 	return mod
 }
 
-func evalMemberAccessExpression(expr *ast.MemberAccessExpression, env *Environment) object.Object {
-	// Evaluate the object (left side)
-	obj := Eval(expr.Object, env)
+// bindPasture adds the members of a module whose file declared
+// 'pasture mygame.ai' to the namespace mygame.ai, binding mygame if it isn't
+// bound yet. Modules in the same pasture share it, so two of them can't both
+// have a member called the same. What the module wrangled itself stays its
+// own.
+func bindPasture(stmt *ast.WrangleStatement, env *Environment, mod *object.Module) *object.Error {
+	parts := strings.Split(mod.Pasture, ".")
+	conflict := func(name string, existing object.Object) *object.Error {
+		return newError(stmt.ModuleName.Token, diagnostics.CodePastureConflict,
+			"can't put module %s in pasture %s: %s is already taken by a value of type %s", mod.Name, mod.Pasture, name, existing.Type())
+	}
 
-	// Check if it's a module
-	if mod, ok := obj.(*object.Module); ok {
-		member, found := mod.Get(expr.Member.Value)
-		if !found {
-			return object.NULL
+	var ns *object.Namespace
+	existing, ok := env.GetLocal(parts[0])
+	switch existing := existing.(type) {
+	case *object.Namespace:
+		ns = existing
+	default:
+		if ok {
+			return conflict(parts[0], existing)
+		}
+		ns = &object.Namespace{Name: parts[0], Members: map[string]object.Object{}}
+		env.Set(parts[0], ns)
+	}
+	for i, part := range parts[1:] {
+		switch member := ns.Members[part].(type) {
+		case *object.Namespace:
+			ns = member
+		case nil:
+			child := &object.Namespace{Name: strings.Join(parts[:i+2], "."), Members: map[string]object.Object{}}
+			ns.Members[part] = child
+			ns = child
+		default:
+			return conflict(strings.Join(parts[:i+2], "."), member)
 		}
-		return member
 	}
 
-	return object.NULL
+	// Check every member before adding any, so a conflict leaves the
+	// namespace as it was
+	names := sharedMembers(mod.Members)
+	for _, name := range names {
+		if existing, ok := ns.Members[name]; ok && existing != mod.Members[name] {
+			return newError(stmt.ModuleName.Token, diagnostics.CodePastureConflict,
+				"can't put module %s in pasture %s: it already has a member '%s'", mod.Name, mod.Pasture, name)
+		}
+	}
+	for _, name := range names {
+		ns.Members[name] = mod.Members[name]
+	}
+	return nil
 }
 
-// loadModule creates and returns a module by name
-// For now, this is hardcoded - later we can make it extensible
-func loadModule(name string) *object.Module {
-	switch name {
-	case "io":
-		return createIOModule()
-	default:
-		// Return empty module for unknown modules
-		return &object.Module{
-			Name:    name,
-			Members: make(map[string]object.Object),
+// sharedMembers returns, sorted, the names of the members a module passes on
+// to a namespace or a module exposing it: not private ones, and not the
+// modules it wrangled for itself.
+func sharedMembers(members map[string]object.Object) []string {
+	var names []string
+	for name, member := range members {
+		switch member.(type) {
+		case *object.Module, *object.Namespace:
+			continue
+		}
+		if !isPrivateMember(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// evalExposeStatement binds the members of each module named in
+// 'expose math, array' in the environment, where they become members of the
+// module the file is loaded as. A name already bound to something else is an
+// error, checked before any member is bound.
+func evalExposeStatement(stmt *ast.ExposeStatement, env *Environment) object.Object {
+	for _, name := range stmt.Modules {
+		obj := evalIdentifier(name, env)
+		if isError(obj) {
+			return obj
+		}
+		var members map[string]object.Object
+		switch obj := obj.(type) {
+		case *object.Module:
+			if errObj := runtimeOf(env).readyModule(obj, name.Token); errObj != nil {
+				return errObj
+			}
+			members = obj.Members
+		case *object.Namespace:
+			members = obj.Members
+		default:
+			return newError(name.Token, diagnostics.CodeTypeMismatch,
+				"expose needs a wrangled module, but %s is a %s", name.Value, obj.Type())
+		}
+
+		names := sharedMembers(members)
+		for _, member := range names {
+			if existing, ok := env.GetLocal(member); ok && existing != members[member] {
+				return newError(name.Token, diagnostics.CodeExposeConflict,
+					"can't expose %s: '%s' is already defined here", name.Value, member)
+			}
+		}
+		for _, member := range names {
+			env.Set(member, members[member])
 		}
 	}
+	return object.NULL
 }
 
-func createIOModule() *object.Module {
-	mod := &object.Module{
-		Name:    "io",
-		Members: make(map[string]object.Object),
+func evalMemberAccessExpression(expr *ast.MemberAccessExpression, env *Environment) object.Object {
+	// Evaluate the object (left side)
+	obj := Eval(expr.Object, env)
+	if isError(obj) {
+		return obj
 	}
 
-	// preach - print to stdout with newline
-	mod.Set("preach", &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
-			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+	// config?.name: nothing to look in, or a hash without the key
+	if expr.Optional {
+		if obj == object.NULL {
+			return object.NULL
+		}
+		if hash, ok := obj.(*object.Hash); ok {
+			if member, found := hash.Get(expr.Member.Value); found {
+				return member
 			}
 			return object.NULL
-		},
-	})
-
-	// input - read line from stdin
-	mod.Set("input", &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
-			// Optional: first argument is prompt
-			if len(args) > 0 {
-				fmt.Print(args[0].Inspect())
+		}
+	}
+
+	// Check if it's a module
+	if mod, ok := obj.(*object.Module); ok {
+		// Names starting with an underscore are helpers kept private to the module
+		if isPrivateMember(expr.Member.Value) {
+			return newError(expr.Member.Token, diagnostics.CodePrivateMember, "member '%s' is private to module '%s'",
+				expr.Member.Value, mod.Name)
+		}
+		if errObj := runtimeOf(env).readyModule(mod, expr.Member.Token); errObj != nil {
+			return errObj
+		}
+
+		member, found := mod.Get(expr.Member.Value)
+		if !found {
+			if runtimeOf(env).Strict {
+				return newError(expr.Member.Token, diagnostics.CodeNoSuchMember,
+					"module '%s' has no member '%s' (strict mode)", mod.Name, expr.Member.Value)
 			}
+			return object.NULL
+		}
+		return member
+	}
 
-			scanner := bufio.NewScanner(os.Stdin)
-			if scanner.Scan() {
-				return &object.String{Value: scanner.Text()}
+	// mygame.ai: a namespace of modules declared in a pasture
+	if ns, ok := obj.(*object.Namespace); ok {
+		member, found := ns.Get(expr.Member.Value)
+		if !found {
+			if runtimeOf(env).Strict {
+				return newError(expr.Member.Token, diagnostics.CodeNoSuchMember,
+					"namespace '%s' has no member '%s' (strict mode)", ns.Name, expr.Member.Value)
 			}
+			return object.NULL
+		}
+		return member
+	}
 
-			return &object.String{Value: ""}
-		},
-	})
+	// Other values with members, like channels (ch.send)
+	if container, ok := obj.(object.Container); ok {
+		if member, found := container.Get(expr.Member.Value); found {
+			return member
+		}
+		return newError(expr.Member.Token, diagnostics.CodeNoSuchMember, "%s has no member '%s'",
+			obj.Type(), expr.Member.Value)
+	}
 
-	return mod
+	return object.NULL
 }
 
 // ========================================
@@ -496,14 +967,18 @@ func createIOModule() *object.Module {
 // ========================================
 
 // newError creates an Error object with a formatted message and location information.
-// The token provides line and column numbers for helpful error messages.
+// The token provides line and column numbers for helpful error messages, and the
+// code (see internal/diagnostics) identifies the kind of error.
 //
-// Usage: return newError(node.Token, "type mismatch: %s + %s", left.Type(), right.Type())
-func newError(tok token.Token, format string, a ...interface{}) *object.Error {
+// Usage: return newError(node.Token, diagnostics.CodeTypeMismatch, "type mismatch: %s + %s", left.Type(), right.Type())
+func newError(tok token.Token, code string, format string, a ...interface{}) *object.Error {
 	return &object.Error{
-		Message: fmt.Sprintf(format, a...),
-		Line:    tok.Line,
-		Column:  tok.Column,
+		Code:      code,
+		Message:   fmt.Sprintf(format, a...),
+		Line:      tok.Line,
+		Column:    tok.Column,
+		EndLine:   tok.End.Line,
+		EndColumn: tok.End.Column,
 		// File is set by main.go when running from a file
 	}
 }
@@ -3,6 +3,7 @@ package evaluator
 import (
 	"testing"
 
+	"github.com/elitwilson/beeflang/internal/diagnostics"
 	"github.com/elitwilson/beeflang/internal/lexer"
 	"github.com/elitwilson/beeflang/internal/object"
 	"github.com/elitwilson/beeflang/internal/parser"
@@ -284,6 +285,66 @@ func TestEvalIfStatement(t *testing.T) {
 	}
 }
 
+func TestEvalIfExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{} // int64, string, or nil (for NULL)
+	}{
+		{`prep hp = 3
+prep label = if hp > 0: "alive" else: "dead" beef
+label`, "alive"},
+		{`prep hp = 0
+prep label = if hp > 0: "alive" else: "dead" beef
+label`, "dead"},
+		// No branch taken, or an empty one, is null
+		{"prep x = if false: 1 beef\nx", nil},
+		{"prep x = if true: beef\nx", nil},
+		// A branch yields its last statement's value
+		{`prep x = if true:
+  prep y = 4
+  y * 2
+beef
+x`, int64(8)},
+		{"[if true: 1 else: 2 beef, if false: 1 else: 2 beef][1]", int64(2)},
+		// serve in a branch still returns from the function
+		{`praise sign(n):
+  prep s = if n < 0: serve "neg" else: "pos" beef
+  serve s
+beef
+sign(-1)`, "neg"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int64:
+			integer, ok := result.(*object.Integer)
+			if !ok {
+				t.Fatalf("expected an Integer for input %q, got %T (%+v)", tt.input, result, result)
+			}
+			assert.Equal(t, expected, integer.Value, "Input: %s", tt.input)
+		case string:
+			str, ok := result.(*object.String)
+			if !ok {
+				t.Fatalf("expected a String for input %q, got %T (%+v)", tt.input, result, result)
+			}
+			assert.Equal(t, expected, str.Value, "Input: %s", tt.input)
+		case nil:
+			assert.Equal(t, object.NULL, result, "Input: %s", tt.input)
+		}
+	}
+}
+
+func TestEvalIfConditionError(t *testing.T) {
+	result := testEval("if missing: 1 else: 2 beef")
+	errObj, ok := result.(*object.Error)
+	if !ok {
+		t.Fatalf("expected an error, got %T (%+v)", result, result)
+	}
+	assert.Contains(t, errObj.Message, "identifier not found: missing")
+}
+
 // Phase 3: Functions - Real failing tests
 
 func TestEvalFunctionDeclaration(t *testing.T) {
@@ -351,6 +412,193 @@ a + b
 	}
 }
 
+func TestFunctionsAreHoisted(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Top-level code can call a function declared further down
+		{`
+prep x = helper()
+praise helper():
+   serve 41
+beef
+x + 1
+`, "42"},
+		// Functions declared later can call each other
+		{`
+prep n = is_even(10)
+praise is_even(n):
+   if n == 0:
+      serve true
+   beef
+   serve is_odd(n - 1)
+beef
+praise is_odd(n):
+   if n == 0:
+      serve false
+   beef
+   serve is_even(n - 1)
+beef
+n
+`, "true"},
+		// A later declaration of the same name still replaces an earlier value
+		{`
+prep f = 1
+prep before = f
+praise f():
+   serve 2
+beef
+before + f()
+`, "3"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+}
+
+func TestDecorators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// The name is bound to whatever the decorator serves
+		{`
+praise double_result(fn):
+   praise wrapped(n):
+      serve fn(n) * 2
+   beef
+   serve wrapped
+beef
+@double_result
+praise inc(n):
+   serve n + 1
+beef
+inc(4)
+`, "10"},
+		// Decorator expressions run top to bottom, then the decorators are
+		// applied bottom to top
+		{`
+wrangle array
+prep log = []
+praise tag(name):
+   array.push(log, "made " + name)
+   praise decorator(fn):
+      array.push(log, "applied " + name)
+      serve fn
+   beef
+   serve decorator
+beef
+@tag("outer")
+@tag("inner")
+praise f():
+beef
+log
+`, `["made outer", "made inner", "applied inner", "applied outer"]`},
+		// Recursive calls go through the decorated name
+		{`
+wrangle cache
+wrangle array
+prep calls = []
+@cache.memoize
+praise fib(n):
+   array.push(calls, n)
+   if n < 2:
+      serve n
+   beef
+   serve fib(n - 1) + fib(n - 2)
+beef
+[fib(10), calls]
+`, "[55, [10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0]]"},
+		// Functions declared in a block can be decorated too
+		{`
+praise twice(fn):
+   praise wrapped(n):
+      serve fn(fn(n))
+   beef
+   serve wrapped
+beef
+praise outer():
+   @twice
+   praise inc(n):
+      serve n + 1
+   beef
+   serve inc(0)
+beef
+outer()
+`, "2"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestDecoratorErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Decorated functions aren't hoisted, since their decorators may
+		// need what's declared above them
+		{`
+prep x = f()
+praise same(fn):
+   serve fn
+beef
+@same
+praise f():
+   serve 1
+beef
+`, "identifier not found: f"},
+		{`
+@5
+praise f():
+beef
+`, "not a function: INTEGER"},
+		{`
+praise pair(a, b):
+   serve a
+beef
+@pair
+praise f():
+beef
+`, "a decorator must be a function of 1 parameter, got one of 2"},
+		{`
+@missing
+praise f():
+beef
+`, "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Contains(t, errObj.Message, tt.expected, tt.input)
+	}
+}
+
+func TestTypeAnnotationsAreIgnoredAtRuntime(t *testing.T) {
+	// Annotations are only for the type checker - even a wrong one doesn't change evaluation
+	input := `
+praise add(a: int, b: int) -> int:
+   prep sum: string = a + b
+   serve sum
+beef
+add(2, 3)
+`
+	result := testEval(input)
+
+	integer, ok := result.(*object.Integer)
+	assert.True(t, ok, "Result should be an Integer, got %T", result)
+	assert.Equal(t, int64(5), integer.Value)
+}
+
 func TestEvalFunctionWithoutReturn(t *testing.T) {
 	// Function without explicit serve should return NULL
 	input := `
@@ -462,6 +710,20 @@ result
 	}
 }
 
+func TestWhileLoopStopsOnErrors(t *testing.T) {
+	tests := []string{
+		"feast while true:\n   prep x = missing\nbeef",
+		"feast while missing:\nbeef",
+	}
+
+	for _, input := range tests {
+		errObj, ok := testEval(input).(*object.Error)
+		if assert.True(t, ok, "Input: %s", input) {
+			assert.Equal(t, diagnostics.CodeIdentifierNotFound, errObj.Code, "Input: %s", input)
+		}
+	}
+}
+
 // ========================================
 // Error Infrastructure Tests
 // ========================================
@@ -518,6 +780,70 @@ func TestTypeErrorMessages(t *testing.T) {
 	}
 }
 
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedCode string
+	}{
+		{"5 + true", diagnostics.CodeTypeMismatch},
+		{"true + false", diagnostics.CodeUnknownOperator},
+		{"-true", diagnostics.CodeUnknownOperator},
+		{"foobar", diagnostics.CodeIdentifierNotFound},
+		{"prep x = 5\nx(1)", diagnostics.CodeNotAFunction},
+		{"wrangle io expose shout", diagnostics.CodeNoSuchMember},
+		{"9223372036854775807 + 1", diagnostics.CodeIntegerOverflow},
+		{"5 / 0", diagnostics.CodeDivisionByZero},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+
+		errObj, ok := result.(*object.Error)
+		if assert.True(t, ok, "Expected error for input: %s", tt.input) {
+			assert.Equal(t, tt.expectedCode, errObj.Code, "Input: %s", tt.input)
+		}
+	}
+}
+
+func TestIntegerOverflow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "integer overflow: 9223372036854775807 + 1"},
+		{"-9223372036854775807 - 2", "integer overflow: -9223372036854775807 - 2"},
+		{"3037000500 * 3037000500", "integer overflow: 3037000500 * 3037000500"},
+		{"prep min = -9223372036854775807 - 1\nmin * -1", "integer overflow: -9223372036854775808 * -1"},
+		{"prep min = -9223372036854775807 - 1\nmin / -1", "integer overflow: -9223372036854775808 / -1"},
+		{"prep min = -9223372036854775807 - 1\nprep neg = -min", "integer overflow: -(-9223372036854775808)"},
+		{"5 / 0", "division by zero: 5 / 0"},
+		{"5 % 0", "division by zero: 5 % 0"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		errObj, ok := result.(*object.Error)
+		if assert.True(t, ok, "%s: got %s", tt.input, result.Inspect()) {
+			assert.Equal(t, tt.expected, errObj.Message, tt.input)
+		}
+	}
+
+	// Results right at the limits are fine
+	fits := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775806 + 1", "9223372036854775807"},
+		{"-9223372036854775807 - 1", "-9223372036854775808"},
+		{"3037000499 * 3037000499", "9223372030926249001"},
+		{"-4611686018427387904 * 2", "-9223372036854775808"},
+		{"prep min = -9223372036854775807 - 1\nmin % -1", "0"},
+	}
+	for _, tt := range fits {
+		assert.Equal(t, tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+}
+
 func TestUndefinedVariableError(t *testing.T) {
 	input := "foobar"
 	result := testEval(input)
@@ -526,6 +852,11 @@ func TestUndefinedVariableError(t *testing.T) {
 	assert.True(t, ok, "Expected error object")
 	assert.Contains(t, errObj.Message, "identifier not found")
 	assert.Contains(t, errObj.Message, "foobar")
+
+	// The error covers the whole identifier
+	assert.Equal(t, 1, errObj.Column)
+	assert.Equal(t, 1, errObj.EndLine)
+	assert.Equal(t, 7, errObj.EndColumn)
 }
 
 func TestUnknownOperatorError(t *testing.T) {
@@ -596,3 +927,99 @@ prep result = badFunc()
 	assert.True(t, ok, "Expected error object")
 	assert.Contains(t, errObj.Message, "type mismatch")
 }
+
+func TestAssertStatement(t *testing.T) {
+	tests := []struct {
+		input   string
+		code    string // "" when the assertion holds
+		message string
+		column  int
+	}{
+		{"assert 1 < 2", "", "", 0},
+		// The message is only evaluated when the assertion fails
+		{"assert 1 < 2, nope", "", "", 0},
+		{"prep total = 9\nassert total == 10", diagnostics.CodeAssertionFailed, "assertion failed: total == 10", 8},
+		{"prep total = 9\nassert total==10 , \"the pan holds 10\"", diagnostics.CodeAssertionFailed,
+			"assertion failed: total == 10 - the pan holds 10", 8},
+		{"praise nothing():\nbeef\nassert nothing(), 42", diagnostics.CodeAssertionFailed, "assertion failed: nothing() - 42", 8},
+		{"assert false, \"HP: \" + 10", diagnostics.CodeTypeMismatch, "type mismatch: STRING + INTEGER", 22},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		if tt.code == "" {
+			assert.Equal(t, object.NULL, result, tt.input)
+			continue
+		}
+
+		errObj, ok := result.(*object.Error)
+		if assert.True(t, ok, "input %q should fail", tt.input) {
+			assert.Equal(t, tt.code, errObj.Code, tt.input)
+			assert.Equal(t, tt.message, errObj.Message, tt.input)
+			assert.Equal(t, tt.column, errObj.Column, tt.input)
+		}
+	}
+}
+
+func TestPipeExpression(t *testing.T) {
+	functions := "praise double(x):\n   serve x * 2\nbeef\npraise minus(x, y):\n   serve x - y\nbeef\n"
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"3 |> double", 6},
+		{"10 |> minus(4)", 6},
+		{"3 |> double |> minus(1) |> double", 10},
+		{"1 + 2 |> double", 6},
+		{"prep n = 2\nn |> double |> minus(1)", 3},
+	}
+
+	for _, tt := range tests {
+		result := testEval(functions + tt.input)
+		integer, ok := result.(*object.Integer)
+		if assert.True(t, ok, "Input: %s, got %s", tt.input, result.Inspect()) {
+			assert.Equal(t, tt.expected, integer.Value, "Input: %s", tt.input)
+		}
+	}
+
+	errObj, ok := testEval("3 |> 4").(*object.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, diagnostics.CodeNotAFunction, errObj.Code)
+	}
+}
+
+func TestArgumentErrorsStopTheCall(t *testing.T) {
+	first := "praise first(a, b, c):\n   serve a\nbeef\n"
+	for _, input := range []string{"first(1, missing, 3)", "first(1, 2, missing)", "1 |> first(missing, 3)", "1 |> first(2, missing)"} {
+		errObj, ok := testEval(first + input).(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q", input)
+		}
+		assert.Equal(t, diagnostics.CodeIdentifierNotFound, errObj.Code, input)
+	}
+}
+
+func TestWrongNumberOfArguments(t *testing.T) {
+	add := "praise add(a, b):\n   serve a + b\nbeef\n"
+	tests := []struct {
+		input    string
+		expected string
+		column   int
+	}{
+		{"add(1)", "add() takes 2 arguments, got 1", 4},
+		{"add(1, 2, 3)", "add() takes 2 arguments, got 3", 4},
+		// The piped value is the first argument, so add still gets only one
+		{"prep x = 5 |> add", "add() takes 2 arguments, got 1", 12},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(add + tt.input).(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q", tt.input)
+		}
+		assert.Equal(t, diagnostics.CodeWrongArity, errObj.Code, tt.input)
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+		assert.Equal(t, 4, errObj.Line, tt.input)
+		assert.Equal(t, tt.column, errObj.Column, tt.input)
+	}
+}
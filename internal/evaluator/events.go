@@ -0,0 +1,184 @@
+package evaluator
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// eventQueue holds a Runtime's event handlers, by event name, and the events
+// emitted since they were last dispatched. Events can be emitted from any
+// goroutine - the host's, or a task's - but handlers only run when the queue
+// is dispatched, on the goroutine that dispatches it.
+type eventQueue struct {
+	mu       sync.Mutex
+	handlers map[string][]object.Object
+	queued   []event
+}
+
+type event struct {
+	name    string
+	payload object.Object
+}
+
+// Emit queues an event for the handlers programs registered with events.on.
+// They run when the events are next dispatched (see DispatchEvents). It is
+// safe to call from any goroutine, while a program runs.
+func (rt *Runtime) Emit(name string, payload object.Object) {
+	rt.events.mu.Lock()
+	defer rt.events.mu.Unlock()
+	rt.events.queued = append(rt.events.queued, event{name, payload})
+}
+
+// DispatchEvents calls the handlers of the events queued so far, in the order
+// they were emitted, and returns how many events it dispatched. Events that
+// handlers emit wait for the next dispatch, so a handler that emits its own
+// event doesn't loop forever. A handler that fails stops the dispatch and
+// its error is returned; the events after its event stay queued.
+func (rt *Runtime) DispatchEvents() object.Object {
+	rt.events.mu.Lock()
+	queued := rt.events.queued
+	rt.events.queued = nil
+	rt.events.mu.Unlock()
+
+	for i, ev := range queued {
+		rt.events.mu.Lock()
+		handlers := slices.Clone(rt.events.handlers[ev.name])
+		rt.events.mu.Unlock()
+
+		for _, handler := range handlers {
+			if result := callHandler(handler, ev.payload); isError(result) {
+				rt.events.mu.Lock()
+				rt.events.queued = append(queued[i+1:len(queued):len(queued)], rt.events.queued...)
+				rt.events.mu.Unlock()
+				return result
+			}
+		}
+	}
+	return &object.Integer{Value: int64(len(queued))}
+}
+
+func createEventsModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "events",
+		Members: make(map[string]object.Object),
+	}
+
+	// on - call a function with the payload of every event of a name, such as
+	// the ones the host game emits:
+	//   events.on("enemy_killed", award_xp)
+	// Handlers run in the order they were added, when the events are
+	// dispatched - by events.dispatch, or by the host.
+	mod.Set("on", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("events.on", args, 2); err != nil {
+				return err
+			}
+			name, err := object.StringArg("events.on", args[0])
+			if err != nil {
+				return err
+			}
+			if err := checkHandler("events.on", args[1]); err != nil {
+				return err
+			}
+			rt.events.mu.Lock()
+			defer rt.events.mu.Unlock()
+			if rt.events.handlers == nil {
+				rt.events.handlers = make(map[string][]object.Object)
+			}
+			rt.events.handlers[name] = append(rt.events.handlers[name], args[1])
+			return object.NULL
+		},
+	})
+
+	// off - stop calling a function for an event; returns whether it was a
+	// handler of the event:
+	//   events.off("enemy_killed", award_xp)
+	mod.Set("off", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("events.off", args, 2); err != nil {
+				return err
+			}
+			name, err := object.StringArg("events.off", args[0])
+			if err != nil {
+				return err
+			}
+			rt.events.mu.Lock()
+			defer rt.events.mu.Unlock()
+			handlers := rt.events.handlers[name]
+			i := slices.Index(handlers, args[1])
+			if i < 0 {
+				return object.FALSE
+			}
+			rt.events.handlers[name] = slices.Delete(slices.Clone(handlers), i, i+1)
+			return object.TRUE
+		},
+	})
+
+	// emit - queue an event from the program itself, with an optional payload
+	// (null if there isn't one):
+	//   events.emit("door_opened", {"room": room})
+	mod.Set("emit", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("events.emit takes 1 or 2 arguments, got %d", len(args))}
+			}
+			name, err := object.StringArg("events.emit", args[0])
+			if err != nil {
+				return err
+			}
+			var payload object.Object = object.NULL
+			if len(args) == 2 {
+				payload = args[1]
+			}
+			rt.Emit(name, payload)
+			return object.NULL
+		},
+	})
+
+	// dispatch - call the handlers of the events queued so far, from a game
+	// loop say, and return how many events there were:
+	//   events.dispatch()
+	mod.Set("dispatch", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("events.dispatch", args, 0); err != nil {
+				return err
+			}
+			return rt.DispatchEvents()
+		},
+	})
+
+	return mod
+}
+
+// checkHandler checks that fn can be called with a single value that it may
+// ignore, such as an event's payload: a builtin, or a function of no
+// parameters or one.
+func checkHandler(what string, fn object.Object) *object.Error {
+	switch fn := fn.(type) {
+	case *object.Function:
+		if len(fn.Parameters) > 1 {
+			return &object.Error{Code: diagnostics.CodeBadArgument,
+				Message: fmt.Sprintf("%s: expected a function of 1 parameter, got one of %d", what, len(fn.Parameters))}
+		}
+	case *object.Builtin:
+	default:
+		return &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: expected a function, got %s", what, fn.Type())}
+	}
+	return nil
+}
+
+// callHandler calls a function checkHandler accepted with value, leaving it
+// out for a function of no parameters.
+func callHandler(fn object.Object, value object.Object) object.Object {
+	if fn, ok := fn.(*object.Function); ok && len(fn.Parameters) == 0 {
+		return applyFunction(token.Token{}, fn, nil)
+	}
+	return applyFunction(token.Token{}, fn, []object.Object{value})
+}
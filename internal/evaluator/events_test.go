@@ -0,0 +1,126 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvents(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`prep seen = []
+praise killed(enemy):
+  array.push(seen, enemy["kind"])
+beef
+events.on("enemy_killed", killed)
+events.emit("enemy_killed", {"kind": "goblin"})
+events.emit("enemy_killed", {"kind": "troll"})
+events.emit("door_opened")
+[events.dispatch(), seen]`, `[3, ["goblin", "troll"]]`},
+		// Handlers run in the order they were added, and without a
+		// parameter ignore the payload
+		{`prep seen = []
+praise first(n):
+  array.push(seen, n)
+beef
+praise second():
+  array.push(seen, "second")
+beef
+events.on("tick", first)
+events.on("tick", second)
+events.emit("tick", 1)
+events.dispatch()
+seen`, `[1, "second"]`},
+		// An event emitted by a handler waits for the next dispatch
+		{`prep runs = []
+praise again():
+  array.push(runs, "again")
+  events.emit("again")
+beef
+events.on("again", again)
+events.emit("again")
+prep first = events.dispatch()
+prep after_first = array.slice(runs, 0, 10)
+[first, after_first, events.dispatch(), runs]`, `[1, ["again"], 1, ["again", "again"]]`},
+		{`prep runs = []
+praise counted():
+  array.push(runs, "tick")
+beef
+events.on("tick", counted)
+events.emit("tick")
+events.dispatch()
+prep removed = events.off("tick", counted)
+events.emit("tick")
+events.dispatch()
+[removed, events.off("tick", counted), runs]`, `[true, false, ["tick"]]`},
+		{"events.dispatch()", "0"},
+	}
+
+	for _, tt := range tests {
+		// A runtime of its own, so the handlers of one test don't see the
+		// events of the next
+		result := evalIn(NewRuntime().NewEnvironment(), "wrangle events\nwrangle array\n"+tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestEventsFailingHandler(t *testing.T) {
+	rt := NewRuntime()
+	env := rt.NewEnvironment()
+	result := evalIn(env, `wrangle events
+wrangle array
+prep handled = []
+praise handle(n):
+  if n == 2:
+    serve missing
+  beef
+  array.push(handled, n)
+beef
+events.on("n", handle)`)
+	if isError(result) {
+		t.Fatalf("setup failed: %s", result.Inspect())
+	}
+
+	for n := int64(1); n <= 3; n++ {
+		rt.Emit("n", &object.Integer{Value: n})
+	}
+	errObj, ok := rt.DispatchEvents().(*object.Error)
+	if !ok {
+		t.Fatal("expected the failing handler's error")
+	}
+	assert.Contains(t, errObj.Message, "identifier not found: missing")
+
+	// The event after the failing one is still queued
+	assert.Equal(t, "1", rt.DispatchEvents().Inspect())
+	handled, _ := env.Get("handled")
+	assert.Equal(t, "[1, 3]", handled.Inspect())
+}
+
+func TestEventsErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`events.on("tick", 1)`, "events.on: expected a function, got INTEGER"},
+		{`events.on(1, io.preach)`, "events.on: expected a STRING, got INTEGER"},
+		{`praise add(a, b):
+  serve a + b
+beef
+events.on("tick", add)`, "events.on: expected a function of 1 parameter, got one of 2"},
+		{`events.emit()`, "events.emit takes 1 or 2 arguments, got 0"},
+		{`events.dispatch(1)`, "events.dispatch takes no arguments, got 1"},
+	}
+
+	for _, tt := range tests {
+		result := evalIn(NewRuntime().NewEnvironment(), "wrangle events\nwrangle io\n"+tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
@@ -0,0 +1,179 @@
+package evaluator
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// scriptFlags are the flags a script declares through one wrangle of the
+// flags module, parsed from the Runtime's Args with Go's flag package.
+type scriptFlags struct {
+	rt     *Runtime
+	mu     sync.Mutex
+	set    *flag.FlagSet
+	values []func() (string, object.Object) // each declared flag's name and parsed value, in declaration order
+}
+
+func createFlagsModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "flags",
+		Members: make(map[string]object.Object),
+	}
+
+	name := "script"
+	if len(rt.Args) > 0 {
+		name = rt.Args[0]
+	}
+	f := &scriptFlags{rt: rt, set: flag.NewFlagSet(name, flag.ContinueOnError)}
+	f.set.SetOutput(io.Discard)
+
+	// string, int, bool - declare a flag with a default value and help text:
+	//   flags.string("cut", "brisket", "what to smoke")
+	//   flags.int("temp", 225, "smoker temperature")
+	//   flags.bool("verbose", false, "say what's happening")
+	mod.Set("string", f.declare("flags.string", func(name string, def object.Object, help string) (func() object.Object, *object.Error) {
+		s, err := object.StringArg("flags.string", def)
+		if err != nil {
+			return nil, err
+		}
+		value := f.set.String(name, s, help)
+		return func() object.Object { return &object.String{Value: *value} }, nil
+	}))
+	mod.Set("int", f.declare("flags.int", func(name string, def object.Object, help string) (func() object.Object, *object.Error) {
+		n, err := object.IntegerArg("flags.int", def)
+		if err != nil {
+			return nil, err
+		}
+		value := f.set.Int64(name, n, help)
+		return func() object.Object { return &object.Integer{Value: *value} }, nil
+	}))
+	mod.Set("bool", f.declare("flags.bool", func(name string, def object.Object, help string) (func() object.Object, *object.Error) {
+		b, ok := def.(*object.Boolean)
+		if !ok {
+			return nil, &object.Error{Code: diagnostics.CodeBadArgument,
+				Message: fmt.Sprintf("flags.bool: expected a BOOLEAN, got %s", def.Type())}
+		}
+		value := f.set.Bool(name, b.Value, help)
+		return func() object.Object { return nativeBoolToBooleanObject(*value) }, nil
+	}))
+
+	// parse - read the declared flags from the command line and return their
+	// values as a hash: {"cut": "brisket", "temp": 225, "verbose": false}.
+	// --help prints the usage message and exits; a bad flag prints the
+	// problem and the usage message to stderr and exits with code 2.
+	mod.Set("parse", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("flags.parse", args, 0); err != nil {
+				return err
+			}
+			return f.parse()
+		},
+	})
+
+	// args - the command-line arguments left over after the flags
+	mod.Set("args", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("flags.args", args, 0); err != nil {
+				return err
+			}
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return stringArray(f.set.Args())
+		},
+	})
+
+	// usage - the usage message, listing every declared flag
+	mod.Set("usage", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("flags.usage", args, 0); err != nil {
+				return err
+			}
+			return &object.String{Value: f.usage()}
+		},
+	})
+
+	return mod
+}
+
+// declare makes a builtin declaring a flag of one type; define registers it
+// with the flag set and returns a function reading its value.
+func (f *scriptFlags) declare(builtin string, define func(name string, def object.Object, help string) (func() object.Object, *object.Error)) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount(builtin, args, 3); err != nil {
+				return err
+			}
+			name, err := object.StringArg(builtin, args[0])
+			if err != nil {
+				return err
+			}
+			help, err := object.StringArg(builtin, args[2])
+			if err != nil {
+				return err
+			}
+
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if f.set.Lookup(name) != nil {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("%s: flag --%s is already declared", builtin, name)}
+			}
+			value, err := define(name, args[1], help)
+			if err != nil {
+				return err
+			}
+			f.values = append(f.values, func() (string, object.Object) { return name, value() })
+			return object.NULL
+		},
+	}
+}
+
+func (f *scriptFlags) parse() object.Object {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var args []string
+	if len(f.rt.Args) > 1 {
+		args = f.rt.Args[1:]
+	}
+	if err := f.set.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(f.rt.Stdout, f.usageLocked())
+			return exitError(0)
+		}
+		fmt.Fprintf(f.rt.Stderr, "%v\n%s", err, f.usageLocked())
+		return exitError(2)
+	}
+
+	result := object.NewHash()
+	for _, value := range f.values {
+		name, val := value()
+		result.Set(&object.String{Value: name}, val)
+	}
+	return result
+}
+
+func (f *scriptFlags) usage() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.usageLocked()
+}
+
+func (f *scriptFlags) usageLocked() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "Usage: %s [flags] [args]\n", f.set.Name())
+	if len(f.values) > 0 {
+		out.WriteString("\nFlags:\n")
+		f.set.SetOutput(&out)
+		f.set.PrintDefaults()
+		f.set.SetOutput(io.Discard)
+	}
+	return out.String()
+}
@@ -0,0 +1,106 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// withArgs sets the script's command line for the rest of the test
+func withArgs(t *testing.T, args ...string) {
+	saved := Default.Args
+	Default.Args = args
+	t.Cleanup(func() { Default.Args = saved })
+}
+
+const smokerFlags = `wrangle flags
+flags.string("cut", "brisket", "what to smoke")
+flags.int("temp", 225, "smoker temperature")
+flags.bool("verbose", false, "say what's happening")
+`
+
+func TestOSArgs(t *testing.T) {
+	withArgs(t, "smoker.beef", "--temp", "250")
+
+	assert.Equal(t, `["smoker.beef", "--temp", "250"]`, testEval("wrangle os\nos.args").Inspect())
+}
+
+func TestOSExit(t *testing.T) {
+	env := NewEnvironment()
+	evalIn(env, dessertLog+"wrangle os\npraise f():\n   dessert note(1)\n   os.exit(3)\n   note(2)\nbeef")
+
+	result := evalIn(env, "f()")
+	errObj, ok := result.(*object.Error)
+	if assert.True(t, ok, "expected an exit, got %s", result.Inspect()) {
+		code, isExit := ExitCode(errObj)
+		assert.True(t, isExit)
+		assert.Equal(t, 3, code)
+	}
+	// The program unwinds, running its desserts on the way
+	assert.Equal(t, "1", evalIn(env, "log.get()").Inspect())
+
+	_, isExit := ExitCode(&object.Error{Code: diagnostics.CodeTypeMismatch, Message: "exit status 3"})
+	assert.False(t, isExit)
+}
+
+func TestFlagsParse(t *testing.T) {
+	withArgs(t, "smoker.beef", "--temp", "250", "-verbose", "ribs", "wings")
+
+	env := NewEnvironment()
+	result := evalIn(env, smokerFlags+"flags.parse()")
+	assert.Equal(t, `{"cut": "brisket", "temp": 250, "verbose": true}`, result.Inspect())
+	assert.Equal(t, `["ribs", "wings"]`, evalIn(env, "flags.args()").Inspect())
+}
+
+func TestFlagsHelpPrintsUsageAndExits(t *testing.T) {
+	withArgs(t, "smoker.beef", "--help")
+
+	var result object.Object
+	out := captureStdout(t, func() {
+		result = testEval(smokerFlags + "flags.parse()")
+	})
+
+	code, isExit := ExitCode(result.(*object.Error))
+	assert.True(t, isExit)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, `Usage: smoker.beef [flags] [args]
+
+Flags:
+  -cut string
+    	what to smoke (default "brisket")
+  -temp int
+    	smoker temperature (default 225)
+  -verbose
+    	say what's happening
+`, out)
+}
+
+func TestFlagsBadFlagExitsWithCode2(t *testing.T) {
+	withArgs(t, "smoker.beef", "--temp", "hot")
+
+	code, isExit := ExitCode(testEval(smokerFlags + "flags.parse()").(*object.Error))
+	assert.True(t, isExit)
+	assert.Equal(t, 2, code)
+}
+
+func TestFlagsErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`flags.string("cut", 1, "")`, "flags.string: expected a STRING, got INTEGER"},
+		{`flags.int("temp", "hot", "")`, "flags.int: expected an INTEGER, got STRING"},
+		{`flags.bool("verbose", 1, "")`, "flags.bool: expected a BOOLEAN, got INTEGER"},
+		{`flags.int("temp")`, "flags.int takes 3 arguments, got 1"},
+		{`flags.int("n", 1, "")` + "\n" + `flags.string("n", "", "")`, "flags.string: flag --n is already declared"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval("wrangle flags\n" + tt.input).(*object.Error)
+		if assert.True(t, ok, "expected an error for %q", tt.input) {
+			assert.Equal(t, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// formatValues fills in the {} placeholders of a format string with values,
+// in order, for io.format and io.preachf. A placeholder can carry a spec
+// after a colon, {:[align][0][width][.precision]}:
+//
+//	{:8}   at least 8 characters wide: strings padded on the right, numbers on the left
+//	{:<8}  {:>8}  {:^8}  left, right or centered in 8 characters
+//	{:05}  numbers padded with zeros
+//	{:.3}  strings cut to 3 characters, numbers given at least 3 digits
+//
+// {{ and }} stand for literal braces. name prefixes the errors, which report
+// a bad placeholder or a number of values that doesn't match.
+func formatValues(name, format string, values []object.Object) (string, *object.Error) {
+	var b strings.Builder
+	next := 0
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		switch {
+		case c == '{' && strings.HasPrefix(format[i:], "{{"):
+			b.WriteByte('{')
+			i++
+		case c == '}' && strings.HasPrefix(format[i:], "}}"):
+			b.WriteByte('}')
+			i++
+		case c == '{':
+			end := strings.IndexByte(format[i:], '}')
+			if end < 0 {
+				return "", formatError("%s: unclosed { in format", name)
+			}
+			placeholder := format[i : i+end+1]
+			if next >= len(values) {
+				return "", formatError("%s: not enough values: the format has more than %d placeholders", name, len(values))
+			}
+			text, ok := formatValue(placeholder, values[next])
+			if !ok {
+				return "", formatError("%s: bad placeholder %s", name, placeholder)
+			}
+			b.WriteString(text)
+			next++
+			i += end
+		case c == '}':
+			return "", formatError("%s: unmatched } in format (write }} for a brace)", name)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if next < len(values) {
+		return "", formatError("%s: too many values: the format has %d placeholders, got %d values", name, next, len(values))
+	}
+	return b.String(), nil
+}
+
+// formatValue formats one value for a placeholder such as {} or {:>5}, or
+// reports that the placeholder's spec is invalid.
+func formatValue(placeholder string, val object.Object) (string, bool) {
+	spec := strings.TrimSuffix(strings.TrimPrefix(placeholder, "{"), "}")
+	if spec != "" && !strings.HasPrefix(spec, ":") {
+		return "", false
+	}
+	spec = strings.TrimPrefix(spec, ":")
+
+	digits, isInt := integerText(val)
+	align := byte('<')
+	if isInt {
+		align = '>'
+	}
+	if spec != "" && strings.IndexByte("<>^", spec[0]) >= 0 {
+		align, spec = spec[0], spec[1:]
+	}
+	zeros := false
+	if strings.HasPrefix(spec, "0") {
+		zeros, spec = true, spec[1:]
+	}
+	widthText, precisionText, hasPrecision := strings.Cut(spec, ".")
+	width, precision := 0, -1
+	var err error
+	if widthText != "" {
+		if width, err = strconv.Atoi(widthText); err != nil || width < 0 {
+			return "", false
+		}
+	}
+	if hasPrecision {
+		if precision, err = strconv.Atoi(precisionText); err != nil || precision < 0 {
+			return "", false
+		}
+	}
+
+	var text string
+	switch {
+	case isInt && precision >= 0:
+		sign := ""
+		if strings.HasPrefix(digits, "-") {
+			sign, digits = "-", digits[1:]
+		}
+		text = sign + strings.Repeat("0", max(precision-len(digits), 0)) + digits
+	case isInt:
+		text = digits
+	case zeros:
+		return "", false // zero padding is for numbers
+	default:
+		text = val.Inspect()
+		if precision >= 0 && utf8.RuneCountInString(text) > precision {
+			text = string([]rune(text)[:precision])
+		}
+	}
+
+	pad := width - utf8.RuneCountInString(text)
+	if pad <= 0 {
+		return text, true
+	}
+	switch {
+	case zeros:
+		if strings.HasPrefix(text, "-") {
+			return "-" + strings.Repeat("0", pad) + text[1:], true
+		}
+		return strings.Repeat("0", pad) + text, true
+	case align == '>':
+		return strings.Repeat(" ", pad) + text, true
+	case align == '^':
+		return strings.Repeat(" ", pad/2) + text + strings.Repeat(" ", pad-pad/2), true
+	}
+	return text + strings.Repeat(" ", pad), true
+}
+
+// integerText returns the digits of an integer or big integer.
+func integerText(val object.Object) (string, bool) {
+	switch n := val.(type) {
+	case *object.Integer:
+		return strconv.FormatInt(n.Value, 10), true
+	case *object.BigInteger:
+		return n.Value.String(), true
+	}
+	return "", false
+}
+
+// formatBuiltin implements builtins taking a format string and its values.
+func formatBuiltin(name string, args []object.Object) object.Object {
+	if len(args) == 0 {
+		return formatError("%s takes a format string and values, got no arguments", name)
+	}
+	format, err := object.StringArg(name, args[0])
+	if err != nil {
+		return err
+	}
+	values := make([]object.Object, len(args)-1)
+	for i, arg := range args[1:] {
+		values[i] = displayValue(arg)
+		if isError(values[i]) {
+			return values[i]
+		}
+	}
+	formatted, err := formatValues(name, format, values)
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: formatted}
+}
+
+func formatError(format string, args ...any) *object.Error {
+	return &object.Error{Code: diagnostics.CodeBadArgument, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,83 @@
+package evaluator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`io.format("HP: {}/{}", 7, 10)`, "HP: 7/10"},
+		{`io.format("no placeholders")`, "no placeholders"},
+		{`io.format("{} {} {}", "cut", true, [1, "two"])`, `cut true [1, "two"]`},
+		{`io.format("{{}} {}", 1)`, "{} 1"},
+		// Width: strings pad on the right, numbers on the left
+		{`io.format("[{:6}]", "rib")`, "[rib   ]"},
+		{`io.format("[{:6}]", 42)`, "[    42]"},
+		{`io.format("[{:>6}]", "rib")`, "[   rib]"},
+		{`io.format("[{:<6}]", 42)`, "[42    ]"},
+		{`io.format("[{:^7}]", "rib")`, "[  rib  ]"},
+		{`io.format("[{:2}]", "brisket")`, "[brisket]"},
+		{`io.format("[{:05}]", -42)`, "[-0042]"},
+		// Precision: strings are cut, numbers get at least that many digits
+		{`io.format("[{:.3}]", "brisket")`, "[bri]"},
+		{`io.format("[{:.3}]", 7)`, "[007]"},
+		{`io.format("[{:>6.3}]", -7)`, "[  -007]"},
+		{`io.format("[{:6.2}]", "brisket")`, "[br    ]"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle io\n" + tt.input)
+		str, ok := result.(*object.String)
+		if assert.True(t, ok, "%s: got %s", tt.input, result.Inspect()) {
+			assert.Equal(t, tt.expected, str.Value, tt.input)
+		}
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`io.format("{} and {}", 1)`, "io.format: not enough values: the format has more than 1 placeholders"},
+		{`io.format("{}", 1, 2)`, "io.format: too many values: the format has 1 placeholders, got 2 values"},
+		{`io.format("{:x}", 1)`, "io.format: bad placeholder {:x}"},
+		{`io.format("{name}", 1)`, "io.format: bad placeholder {name}"},
+		{`io.format("{:05}", "rib")`, "io.format: bad placeholder {:05}"},
+		{`io.format("HP {", 1)`, "io.format: unclosed { in format"},
+		{`io.format("}", 1)`, "io.format: unmatched } in format (write }} for a brace)"},
+		{`io.format(5)`, "io.format: expected a STRING, got INTEGER"},
+		{`io.format()`, "io.format takes a format string and values, got no arguments"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval("wrangle io\n" + tt.input).(*object.Error)
+		if assert.True(t, ok, tt.input) {
+			assert.Equal(t, diagnostics.CodeBadArgument, errObj.Code, tt.input)
+			assert.Equal(t, tt.expected, errObj.Message, tt.input)
+			assert.Equal(t, 2, errObj.Line, tt.input)
+		}
+	}
+}
+
+func TestPreachf(t *testing.T) {
+	var out bytes.Buffer
+	stdout := Default.Stdout
+	Default.Stdout = &out
+	t.Cleanup(func() { Default.Stdout = stdout })
+
+	result := testEval("wrangle io expose preachf\npreachf(\"{:<8}{:>3}\", \"brisket\", 12)\npreachf(\"done\")")
+	assert.Equal(t, object.NULL, result)
+	assert.Equal(t, "brisket  12\ndone\n", out.String())
+
+	_, failed := testEval("wrangle io\nio.preachf(\"{}\")").(*object.Error)
+	assert.True(t, failed)
+}
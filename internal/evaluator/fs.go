@@ -0,0 +1,126 @@
+package evaluator
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+func createFSModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "fs",
+		Members: make(map[string]object.Object),
+	}
+
+	// read - the whole contents of a file, as a string:
+	//   prep level = fs.read("levels/1.txt")
+	mod.Set("read", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			path, err := fsPathArg(rt, "fs.read", args, 1)
+			if err != nil {
+				return err
+			}
+			data, readErr := os.ReadFile(path)
+			if readErr != nil {
+				return fileError(readErr)
+			}
+			return &object.String{Value: string(data)}
+		},
+	})
+
+	// write - replace a file's contents with a string, creating the file if
+	// it doesn't exist:
+	//   fs.write("save.txt", "level 3")
+	mod.Set("write", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			path, err := fsPathArg(rt, "fs.write", args, 2)
+			if err != nil {
+				return err
+			}
+			text, err := object.StringArg("fs.write", args[1])
+			if err != nil {
+				return err
+			}
+			if writeErr := os.WriteFile(path, []byte(text), 0o644); writeErr != nil {
+				return fileError(writeErr)
+			}
+			return object.NULL
+		},
+	})
+
+	// read_bytes - the whole contents of a file, as bytes, for files that
+	// aren't text:
+	//   prep save = fs.read_bytes("save.dat")
+	mod.Set("read_bytes", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			path, err := fsPathArg(rt, "fs.read_bytes", args, 1)
+			if err != nil {
+				return err
+			}
+			data, readErr := os.ReadFile(path)
+			if readErr != nil {
+				return fileError(readErr)
+			}
+			return &object.Bytes{Value: data}
+		},
+	})
+
+	// write_bytes - replace a file's contents with bytes, creating the file if
+	// it doesn't exist:
+	//   fs.write_bytes("save.dat", bytes.from_array([1, level]))
+	mod.Set("write_bytes", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			path, err := fsPathArg(rt, "fs.write_bytes", args, 2)
+			if err != nil {
+				return err
+			}
+			data, err := object.BytesArg("fs.write_bytes", args[1])
+			if err != nil {
+				return err
+			}
+			if writeErr := os.WriteFile(path, data, 0o644); writeErr != nil {
+				return fileError(writeErr)
+			}
+			return object.NULL
+		},
+	})
+
+	// exists - whether there is a file or directory at a path
+	mod.Set("exists", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			path, err := fsPathArg(rt, "fs.exists", args, 1)
+			if err != nil {
+				return err
+			}
+			_, statErr := os.Stat(path)
+			if errors.Is(statErr, fs.ErrNotExist) {
+				return object.FALSE
+			}
+			if statErr != nil {
+				return fileError(statErr)
+			}
+			return object.TRUE
+		},
+	})
+
+	return mod
+}
+
+// fsPathArg checks that the fs module may be used in rt and that a call got
+// count arguments, and returns the first, a path.
+func fsPathArg(rt *Runtime, name string, args []object.Object, count int) (string, *object.Error) {
+	if err := checkCapability(rt.Allowed.Filesystem, capFilesystem, name); err != nil {
+		return "", err
+	}
+	if err := object.CheckArgCount(name, args, count); err != nil {
+		return "", err
+	}
+	return object.StringArg(name, args[0])
+}
+
+func fileError(err error) *object.Error {
+	return &object.Error{Code: diagnostics.CodeFileError, Message: err.Error()}
+}
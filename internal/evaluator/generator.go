@@ -0,0 +1,125 @@
+package evaluator
+
+import (
+	"sync"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// generatorBodies remembers which function bodies contain a 'yield', so
+// calls don't walk the body every time.
+var generatorBodies sync.Map // *ast.BlockStatement -> bool
+
+// isGenerator reports whether fn is a generator function: whether its body
+// yields, not counting functions declared inside it.
+func isGenerator(fn *object.Function) bool {
+	if known, ok := generatorBodies.Load(fn.Body); ok {
+		return known.(bool)
+	}
+	yields := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.YieldStatement:
+			yields = true
+		case *ast.FunctionDeclaration:
+			return false
+		}
+		return !yields
+	})
+	generatorBodies.Store(fn.Body, yields)
+	return yields
+}
+
+// newGenerator returns the generator a call to fn produces, with its body to
+// run in fnEnv.
+func newGenerator(fn *object.Function, fnEnv *Environment) *object.Generator {
+	g := object.NewGenerator(func() object.Object {
+		return runFunctionBody(fn, fnEnv)
+	})
+	fnEnv.SetGenerator(g)
+	return g
+}
+
+// evalYieldStatement hands a value to the loop consuming the generator and
+// waits for it to want another. Blocks don't open scopes, so the current
+// environment is the generator function call's.
+func evalYieldStatement(stmt *ast.YieldStatement, env *Environment) object.Object {
+	g := env.Generator()
+	if g == nil {
+		return newError(stmt.Token, diagnostics.CodeYieldOutsideFunction,
+			"'yield' can only be used inside a function")
+	}
+
+	val := Eval(stmt.Value, env)
+	if isError(val) {
+		return val
+	}
+	if !g.Yield(val) {
+		// The loop is over: unwind the body, running its desserts. Nobody
+		// sees this error.
+		return newError(stmt.Token, diagnostics.CodeInterrupted, "generator stopped")
+	}
+	return object.NULL
+}
+
+// evalForEachLoop handles: feast item in items: body beef
+// The loop variable is bound in the current scope, like a 'prep'.
+func evalForEachLoop(loop *ast.ForEachLoop, env *Environment) object.Object {
+	iterable := Eval(loop.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	var result object.Object = object.NULL
+	rt := runtimeOf(env)
+	// each runs the body for one value, and reports whether to carry on
+	each := func(val object.Object) bool {
+		if err := rt.checkInterrupt(loop.Token); err != nil {
+			result = err
+			return false
+		}
+		env.Set(loop.Variable.Value, val)
+		result = Eval(loop.Body, env)
+		return !isError(result) && (result == nil || result.Type() != "RETURN_VALUE")
+	}
+
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		for _, el := range iterable.Elements() {
+			if !each(el) {
+				break
+			}
+		}
+
+	case *object.Hash:
+		for _, pair := range iterable.Pairs() {
+			if !each(pair.Key) {
+				break
+			}
+		}
+
+	case *object.Generator:
+		// Leaving the loop early stops the generator, so its body doesn't
+		// wait at a yield forever
+		defer iterable.Stop()
+		for {
+			val, ok := iterable.Next()
+			if !ok {
+				if err := iterable.Err(); err != nil {
+					return err
+				}
+				break
+			}
+			if !each(val) {
+				break
+			}
+		}
+
+	default:
+		return newError(loop.Token, diagnostics.CodeNotIterable, "cannot loop over %s", iterable.Type())
+	}
+
+	return result
+}
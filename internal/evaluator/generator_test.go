@@ -0,0 +1,129 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachLoop(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int64
+	}{
+		{"array elements", "prep sum = 0\nfeast n in [1, 2, 3]:\n   sum = sum + n\nbeef\nsum", 6},
+		{"hash keys", "prep h = {3: \"c\", 4: \"d\"}\nprep sum = 0\nfeast k in h:\n   sum = sum + k\nbeef\nsum", 7},
+		{"empty array", "prep sum = 0\nfeast n in []:\n   sum = sum + 1\nbeef\nsum", 0},
+		{"variable outlives the loop", "feast n in [1, 2, 3]:\nbeef\nn", 3},
+		{"serve leaves the loop", "praise find():\n   feast n in [5, 6, 7]:\n      if n > 5:\n         serve n\n      beef\n   beef\nbeef\nfind()", 6},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		assert.Equal(t, "INTEGER", result.Type(), "%s: %s", tt.name, result.Inspect())
+		assert.Equal(t, tt.expected, result.(*object.Integer).Value, tt.name)
+	}
+}
+
+const counting = `
+praise count(from, to):
+   prep i = from
+   feast while i < to:
+      yield i
+      i = i + 1
+   beef
+beef
+`
+
+func TestGenerators(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"yields in order", "prep sum = 0\nfeast n in count(1, 5):\n   sum = sum * 10 + n\nbeef\nsum", "1234"},
+		{"calling one returns a generator", "count(0, 3)", "<generator>"},
+		{"nothing to yield", "prep sum = 0\nfeast n in count(5, 1):\n   sum = sum + 1\nbeef\nsum", "0"},
+		{
+			"infinite, until the loop stops",
+			"praise naturals():\n   prep i = 0\n   feast while true:\n      yield i\n      i = i + 1\n   beef\nbeef\n" +
+				"praise first(limit):\n   feast n in naturals():\n      if n == limit:\n         serve n\n      beef\n   beef\nbeef\nfirst(1000)",
+			"1000",
+		},
+		{
+			"nested generators",
+			"praise doubled(g):\n   feast n in g:\n      yield n * 2\n   beef\nbeef\nprep sum = 0\nfeast n in doubled(count(1, 4)):\n   sum = sum + n\nbeef\nsum",
+			"12",
+		},
+	}
+
+	for _, tt := range tests {
+		result := testEval(counting + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.name)
+	}
+}
+
+func TestGeneratorsAreLazy(t *testing.T) {
+	input := dessertLog + `
+praise noisy():
+   note(1)
+   yield 0
+   note(2)
+   yield 0
+   note(3)
+beef
+praise take_one():
+   feast x in noisy():
+      serve x
+   beef
+beef
+prep g = noisy()
+take_one()
+log.get()`
+	// Nothing ran for g; take_one's generator stopped at its first yield
+	assert.Equal(t, "1", testEval(input).Inspect())
+}
+
+func TestStoppedGeneratorRunsDesserts(t *testing.T) {
+	input := dessertLog + counting + `
+praise logged():
+   dessert note(9)
+   feast n in count(1, 100):
+      yield n
+   beef
+beef
+praise first():
+   feast n in logged():
+      serve n
+   beef
+beef
+first()
+log.get()`
+	assert.Equal(t, "9", testEval(input).Inspect())
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+		line  int
+	}{
+		{"yield 1", diagnostics.CodeYieldOutsideFunction, 1},
+		{"feast c in \"abc\":\nbeef", diagnostics.CodeNotIterable, 1},
+		// An error in the body ends the loop consuming it
+		{"praise broken():\n   yield 1\n   yield missing\nbeef\nfeast x in broken():\nbeef", diagnostics.CodeIdentifierNotFound, 3},
+		// And one in the loop stops the generator
+		{counting + "feast x in count(0, 10):\n   prep y = missing\nbeef", diagnostics.CodeIdentifierNotFound, 10},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if assert.True(t, ok, tt.input) {
+			assert.Equal(t, tt.code, errObj.Code, tt.input)
+			assert.Equal(t, tt.line, errObj.Line, tt.input)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+func createGridModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "grid",
+		Members: make(map[string]object.Object),
+	}
+
+	// new - a width by height grid with every cell set to fill, or null if
+	// it's left out. Cells are read and changed with its methods:
+	//   prep level = grid.new(40, 25, "wall")
+	//   level.fill(1, 1, 38, 23, "floor")
+	//   prep route = level.path(1, 1, 30, 20, "wall")
+	mod.Set("new", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("grid.new takes 2 or 3 arguments, got %d", len(args))}
+			}
+			width, err := object.IntegerArg("grid.new", args[0])
+			if err != nil {
+				return err
+			}
+			height, err := object.IntegerArg("grid.new", args[1])
+			if err != nil {
+				return err
+			}
+			if width <= 0 || height <= 0 || width > object.MaxGridCells/height {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("grid.new: can't make a %dx%d grid", width, height)}
+			}
+			var fill object.Object = object.NULL
+			if len(args) == 3 {
+				fill = args[2]
+			}
+			return object.NewGrid(int(width), int(height), fill)
+		},
+	})
+
+	return mod
+}
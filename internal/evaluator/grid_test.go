@@ -0,0 +1,93 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// A small level: # is wall, . is floor, and the two rooms are joined
+// through the gap at (2, 3)
+const gridLevel = `wrangle grid
+prep level = grid.new(5, 5, ".")
+level.fill(2, 0, 1, 5, "#")
+level.set(2, 3, ".")
+`
+
+func TestGrid(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"level", "<grid 5x5>"},
+		{"[level.width, level.height]", "[5, 5]"},
+		{"[level.get(2, 0), level.get(2, 3), level.get(4, 4)]", `["#", ".", "."]`},
+		{"grid.new(2, 2).get(1, 1)", "null"},
+		{"[level.in_bounds(4, 4), level.in_bounds(5, 0), level.in_bounds(0, -1)]", "[true, false, false]"},
+		{"level.neighbors(0, 0)", "[vec(1, 0), vec(0, 1)]"},
+		{"level.neighbors(1, 1, true)", "[vec(0, 0), vec(1, 0), vec(2, 0), vec(0, 1), vec(2, 1), vec(0, 2), vec(1, 2), vec(2, 2)]"},
+		// The fill is clipped to the grid
+		{"level.fill(3, 3, 10, 10, 7)\n[level.get(3, 3), level.get(4, 4), level.get(3, 2)]", `[7, 7, "."]`},
+		{"level.fill(0)\nlevel.get(2, 0)", "0"},
+		{`[level.flood(0, 0, "x"), level.get(4, 0), level.get(2, 0)]`, `[21, "x", "#"]`},
+		{"level.region(2, 0)", "[vec(2, 0), vec(2, 1), vec(2, 2)]"},
+		{`[level.flood(2, 4, "door"), level.get(2, 4), level.get(2, 2)]`, `[1, "door", "#"]`},
+		// Cells that aren't integers, strings or booleans match only themselves
+		{"prep a = []\nprep g = grid.new(3, 1, a)\ng.set(2, 0, [])\ng.flood(0, 0, 1)", "2"},
+		{`level.path(0, 0, 4, 0, "#")`, "[vec(0, 0), vec(1, 0), vec(1, 1), vec(1, 2), vec(1, 3), vec(2, 3), vec(3, 3), vec(3, 2), vec(3, 1), vec(3, 0), vec(4, 0)]"},
+		{`level.path(1, 1, 1, 1, "#")`, "[vec(1, 1)]"},
+		{`level.path(0, 0, 4, 0, ["#", "."])`, "null"},
+		{`level.set(2, 3, "#")` + "\n" + `level.path(0, 0, 4, 0, "#")`, "null"},
+		{`level.path(0, 0, 2, 0, "#")`, "null"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(gridLevel + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestGridPathIsShortest(t *testing.T) {
+	// An open 30x30 grid: the path is as long as the Manhattan distance, and
+	// every step moves one cell up, down, left or right
+	result := testEval("wrangle grid\ngrid.new(30, 30, 0).path(3, 27, 25, 2, 1)")
+	path, ok := result.(*object.Array)
+	if !ok {
+		t.Fatalf("expected an array, got %T (%+v)", result, result)
+	}
+	steps := path.Elements()
+	assert.Len(t, steps, 22+25+1)
+	for i := 1; i < len(steps); i++ {
+		from := steps[i-1].(*object.Vector).Components
+		to := steps[i].(*object.Vector).Components
+		dx, dy := to[0]-from[0], to[1]-from[1]
+		assert.Equal(t, int64(1), dx*dx+dy*dy, "step %d", i)
+	}
+}
+
+func TestGridErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"grid.new(5)", "grid.new takes 2 or 3 arguments, got 1"},
+		{"grid.new(0, 5)", "grid.new: can't make a 0x5 grid"},
+		{"grid.new(100000, 100000)", "grid.new: can't make a 100000x100000 grid"},
+		{"level.get(5, 0)", "get: (5, 0) is outside a 5x5 grid"},
+		{`level.set(0, "1", 2)`, "set: expected an INTEGER, got STRING"},
+		{"level.set(0, 1)", "set takes 3 arguments, got 2"},
+		{"level.fill(0, 0, 1)", "fill takes 1 or 5 arguments (value, or x, y, width, height, value), got 3"},
+		{`level.path(0, 0, 9, 9, "#")`, "path: (9, 9) is outside a 5x5 grid"},
+		{"level.depth", "GRID has no member 'depth'"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(gridLevel + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
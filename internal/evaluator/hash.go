@@ -0,0 +1,102 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// The hash module works in a hash's insertion order: keys and values come
+// back in the order the keys were first added, so output is the same from
+// run to run.
+func createHashModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "hash",
+		Members: make(map[string]object.Object),
+	}
+
+	// keys - an array of a hash's keys
+	mod.Set("keys", hashBuiltin("hash.keys", 0, func(h *object.Hash, args []object.Object) object.Object {
+		pairs := h.Pairs()
+		keys := make([]object.Object, len(pairs))
+		for i, pair := range pairs {
+			keys[i] = pair.Key
+		}
+		return object.NewArray(keys)
+	}))
+
+	// values - an array of a hash's values, in the same order as keys
+	mod.Set("values", hashBuiltin("hash.values", 0, func(h *object.Hash, args []object.Object) object.Object {
+		pairs := h.Pairs()
+		values := make([]object.Object, len(pairs))
+		for i, pair := range pairs {
+			values[i] = pair.Value
+		}
+		return object.NewArray(values)
+	}))
+
+	// has - whether a hash has a key; the same as key in h
+	mod.Set("has", hashBuiltin("hash.has", 1, func(h *object.Hash, args []object.Object) object.Object {
+		key, ok := args[0].(object.Hashable)
+		if !ok {
+			return object.FALSE
+		}
+		_, found := h.Lookup(key)
+		return nativeBoolToBooleanObject(found)
+	}))
+
+	// delete - remove a key from a hash, returning its value, or null if
+	// the hash didn't have it
+	mod.Set("delete", hashBuiltin("hash.delete", 1, func(h *object.Hash, args []object.Object) object.Object {
+		key, ok := args[0].(object.Hashable)
+		if !ok {
+			return &object.Error{Code: diagnostics.CodeBadArgument,
+				Message: fmt.Sprintf("hash.delete: unusable as hash key: %s", args[0].Type())}
+		}
+		if value, found := h.Delete(key); found {
+			return value
+		}
+		return object.NULL
+	}))
+
+	// merge - a new hash with the entries of both. Where both have a key,
+	// the second hash's value wins but the key keeps its place from the
+	// first:
+	//   hash.merge(defaults, options)
+	mod.Set("merge", hashBuiltin("hash.merge", 1, func(h *object.Hash, args []object.Object) object.Object {
+		other, ok := args[0].(*object.Hash)
+		if !ok {
+			return &object.Error{Code: diagnostics.CodeBadArgument,
+				Message: fmt.Sprintf("hash.merge: expected a HASH, got %s", args[0].Type())}
+		}
+		merged := object.NewHash()
+		for _, pair := range h.Pairs() {
+			merged.Set(pair.Key, pair.Value)
+		}
+		for _, pair := range other.Pairs() {
+			merged.Set(pair.Key, pair.Value)
+		}
+		return merged
+	}))
+
+	return mod
+}
+
+// hashBuiltin makes a builtin that takes a hash followed by argc more
+// arguments.
+func hashBuiltin(name string, argc int, fn func(h *object.Hash, args []object.Object) object.Object) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount(name, args, argc+1); err != nil {
+				return err
+			}
+			h, ok := args[0].(*object.Hash)
+			if !ok {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("%s: expected a HASH, got %s", name, args[0].Type())}
+			}
+			return fn(h, args[1:])
+		},
+	}
+}
@@ -0,0 +1,58 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`hash.keys({"rib": 3, "chuck": 1, 7: true})`, `["rib", "chuck", 7]`},
+		{`hash.values({"rib": 3, "chuck": 1, 7: true})`, "[3, 1, true]"},
+		{`hash.keys({})`, "[]"},
+		{`hash.has({"rib": 3}, "rib")`, "true"},
+		{`hash.has({"rib": 3}, "chuck")`, "false"},
+		{`hash.has({1: 3}, "1")`, "false"},
+		{`hash.has({"rib": 3}, [1])`, "false"},
+		{`prep h = {"rib": 3, "chuck": 1, "flank": 2}
+prep gone = hash.delete(h, "chuck")
+[gone, h]`, `[1, {"rib": 3, "flank": 2}]`},
+		{`hash.delete({"rib": 3}, "chuck")`, "null"},
+		{`prep defaults = {"cut": "brisket", "temp": 225, "wood": "oak"}
+prep merged = hash.merge(defaults, {"temp": 250, "hours": 12})
+[merged, defaults]`, `[{"cut": "brisket", "temp": 250, "wood": "oak", "hours": 12}, {"cut": "brisket", "temp": 225, "wood": "oak"}]`},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle hash\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestHashModuleErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`hash.keys([1])`, "hash.keys: expected a HASH, got ARRAY"},
+		{`hash.merge({}, [1])`, "hash.merge: expected a HASH, got ARRAY"},
+		{`hash.delete({}, [1])`, "hash.delete: unusable as hash key: ARRAY"},
+		{`hash.has({})`, "hash.has takes 2 arguments, got 1"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle hash\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !assert.True(t, ok, "%s: got %s", tt.input, result.Inspect()) {
+			continue
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+		assert.Equal(t, diagnostics.CodeBadArgument, errObj.Code, tt.input)
+	}
+}
@@ -0,0 +1,184 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+func createHTTPModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "http",
+		Members: make(map[string]object.Object),
+	}
+
+	// serve - answer HTTP requests on a port with a handler function, until
+	// the program is interrupted:
+	//   praise handle(req):
+	//     serve {"status": 200, "body": "you asked for " + req.path}
+	//   beef
+	//   http.serve(8080, handle)
+	mod.Set("serve", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkCapability(rt.Allowed.Network, capNetwork, "http.serve"); err != nil {
+				return err
+			}
+			if err := object.CheckArgCount("http.serve", args, 2); err != nil {
+				return err
+			}
+			port, err := portArg("http.serve", args[0])
+			if err != nil {
+				return err
+			}
+			handler, ok := args[1].(*object.Function)
+			if !ok || len(handler.Parameters) != 1 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("http.serve: expected a function taking a request, got %s", describeHandler(args[1]))}
+			}
+
+			listener, listenErr := net.Listen("tcp", ":"+strconv.Itoa(port))
+			if listenErr != nil {
+				return &object.Error{Code: diagnostics.CodeNetworkError, Message: listenErr.Error()}
+			}
+			return serveHTTP(rt, listener, handler)
+		},
+	})
+
+	return mod
+}
+
+// describeHandler names what was passed to http.serve instead of a handler.
+func describeHandler(arg object.Object) string {
+	if fn, ok := arg.(*object.Function); ok {
+		return fmt.Sprintf("a function taking %d parameters", len(fn.Parameters))
+	}
+	return arg.Type()
+}
+
+// serveHTTP answers requests on listener until the program is interrupted.
+func serveHTTP(rt *Runtime, listener net.Listener, handler *object.Function) object.Object {
+	server := &http.Server{Handler: httpHandler(rt, handler)}
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(listener) }()
+
+	select {
+	case err := <-done:
+		return &object.Error{Code: diagnostics.CodeNetworkError, Message: err.Error()}
+	case <-rt.interruptChan():
+		server.Shutdown(context.Background())
+		return &object.Error{Code: diagnostics.CodeInterrupted, Message: "interrupted"}
+	}
+}
+
+// httpHandler calls a Beeflang function for each request. Every call runs on
+// its own snapshot of the function's environment, so requests served at the
+// same time can't see each other's assignments. Arrays and hashes reached
+// through the variables are shared, as between tasks - a global hash of
+// scores is how handlers keep state - and lock themselves. A handler that
+// fails gets a 500 response, and its error is reported like a failed task's.
+func httpHandler(rt *Runtime, fn *object.Function) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, err := requestHash(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		snapshot := &object.Function{Parameters: fn.Parameters, Body: fn.Body, Env: fn.Env.Snapshot(), Name: fn.Name, Doc: fn.Doc}
+		result := CallFunction(snapshot, request)
+		if errObj, ok := result.(*object.Error); ok {
+			rt.taskError(errObj)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		if errObj := writeResponse(w, result); errObj != nil {
+			rt.taskError(errObj)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}
+	})
+}
+
+// requestHash turns a request into the hash handlers receive:
+//
+//	{"method": "POST", "path": "/scores", "query": {"level": "2"},
+//	 "headers": {"content-type": "text/plain"}, "body": "..."}
+//
+// Header names are lowercase; a query parameter or header given more than
+// once has its first value.
+func requestHash(r *http.Request) (*object.Hash, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	query := object.NewHash()
+	for name, values := range r.URL.Query() {
+		query.Set(&object.String{Value: name}, &object.String{Value: values[0]})
+	}
+	headers := object.NewHash()
+	for name, values := range r.Header {
+		headers.Set(&object.String{Value: strings.ToLower(name)}, &object.String{Value: values[0]})
+	}
+
+	request := object.NewHash()
+	request.Set(&object.String{Value: "method"}, &object.String{Value: r.Method})
+	request.Set(&object.String{Value: "path"}, &object.String{Value: r.URL.Path})
+	request.Set(&object.String{Value: "query"}, query)
+	request.Set(&object.String{Value: "headers"}, headers)
+	request.Set(&object.String{Value: "body"}, &object.String{Value: string(body)})
+	return request, nil
+}
+
+// writeResponse sends what a handler returned: a hash with an optional
+// "status" (default 200), "headers" and "body", or just a string for a 200
+// response with that body.
+func writeResponse(w http.ResponseWriter, result object.Object) *object.Error {
+	if s, ok := result.(*object.String); ok {
+		io.WriteString(w, s.Value)
+		return nil
+	}
+	response, ok := result.(*object.Hash)
+	if !ok {
+		return badResponse("an http handler must serve a HASH or a STRING, got %s", result.Type())
+	}
+
+	status := http.StatusOK
+	if value, found := response.Lookup(&object.String{Value: "status"}); found {
+		code, ok := value.(*object.Integer)
+		if !ok || code.Value < 100 || code.Value > 999 {
+			return badResponse("response status must be an INTEGER status code, got %s", value.Inspect())
+		}
+		status = int(code.Value)
+	}
+
+	if value, found := response.Lookup(&object.String{Value: "headers"}); found {
+		headers, ok := value.(*object.Hash)
+		if !ok {
+			return badResponse("response headers must be a HASH, got %s", value.Type())
+		}
+		for _, pair := range headers.Pairs() {
+			w.Header().Set(pair.Key.Inspect(), pair.Value.Inspect())
+		}
+	}
+
+	body := ""
+	if value, found := response.Lookup(&object.String{Value: "body"}); found {
+		body = value.Inspect()
+	}
+
+	w.WriteHeader(status)
+	io.WriteString(w, body)
+	return nil
+}
+
+func badResponse(format string, a ...interface{}) *object.Error {
+	return &object.Error{Code: diagnostics.CodeBadResponse, Message: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,173 @@
+package evaluator
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// handlerFor evaluates input and returns an http.Handler calling its function named handle
+func handlerFor(t *testing.T, input string) http.Handler {
+	env := NewEnvironment()
+	result := evalIn(env, input)
+	if errObj, ok := result.(*object.Error); ok {
+		t.Fatal(errObj.Inspect())
+	}
+	fn, _ := env.Get("handle")
+	return httpHandler(Default, fn.(*object.Function))
+}
+
+// request sends a request to h and returns the response
+func request(h http.Handler, method, target, body string) *http.Response {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("X-Smoker", "offset")
+	h.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func responseBody(resp *http.Response) string {
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestHTTPHandlerSeesTheRequest(t *testing.T) {
+	h := handlerFor(t, `praise handle(req):
+   serve req.method + " " + req.path + " level=" + req.query.level + " smoker=" + req.headers["x-smoker"] + " body=" + req.body
+beef`)
+
+	resp := request(h, "POST", "/scores?level=2", "9000")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "POST /scores level=2 smoker=offset body=9000", responseBody(resp))
+}
+
+func TestHTTPResponseHash(t *testing.T) {
+	h := handlerFor(t, `praise handle(req):
+   serve {"status": 201, "headers": {"Content-Type": "application/json"}, "body": "{}"}
+beef`)
+
+	resp := request(h, "GET", "/", "")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "{}", responseBody(resp))
+
+	// Everything in the hash is optional
+	h = handlerFor(t, "praise handle(req):\n   serve {}\nbeef")
+	resp = request(h, "GET", "/", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "", responseBody(resp))
+}
+
+func TestHTTPHandlerErrors(t *testing.T) {
+	var reported []string
+	defer func(saved func(*object.Error)) { Default.OnTaskError = saved }(Default.OnTaskError)
+	Default.OnTaskError = func(err *object.Error) { reported = append(reported, err.Code) }
+
+	tests := []struct {
+		body         string
+		expectedCode string
+	}{
+		{"serve missing", diagnostics.CodeIdentifierNotFound},
+		{"serve 404", diagnostics.CodeBadResponse},
+		{`serve {"status": "ok"}`, diagnostics.CodeBadResponse},
+		{`serve {"headers": 1}`, diagnostics.CodeBadResponse},
+	}
+
+	for _, tt := range tests {
+		reported = nil
+		h := handlerFor(t, "praise handle(req):\n   "+tt.body+"\nbeef")
+		resp := request(h, "GET", "/", "")
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode, tt.body)
+		assert.Equal(t, []string{tt.expectedCode}, reported, tt.body)
+	}
+}
+
+func TestHTTPServeStopsWhenInterrupted(t *testing.T) {
+	t.Cleanup(Default.ResetInterrupt)
+	env := NewEnvironment()
+	evalIn(env, "praise handle(req):\n   serve \"ok\"\nbeef")
+	fn, _ := env.Get("handle")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan object.Object)
+	go func() { done <- serveHTTP(Default, listener, fn.(*object.Function)) }()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ok", responseBody(resp))
+		resp.Body.Close()
+	}
+
+	Default.Interrupt()
+	select {
+	case result := <-done:
+		assert.Equal(t, diagnostics.CodeInterrupted, result.(*object.Error).Code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("http.serve did not stop")
+	}
+}
+
+func TestHTTPServeArguments(t *testing.T) {
+	allowNetwork(t)
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"http.serve(8080)", "http.serve takes 2 arguments, got 1"},
+		{"http.serve(8080, 1)", "http.serve: expected a function taking a request, got INTEGER"},
+		{"praise f():\nbeef\nhttp.serve(8080, f)", "http.serve: expected a function taking a request, got a function taking 0 parameters"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval("wrangle http\n" + tt.input).(*object.Error)
+		if assert.True(t, ok, "expected an error for %q", tt.input) {
+			assert.Equal(t, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestHTTPHandlersShareArraysAndHashes(t *testing.T) {
+	// Concurrent requests record themselves in one global array and read a
+	// global hash; run with -race to check
+	env := NewEnvironment()
+	evalIn(env, `wrangle array
+wrangle hash
+prep hits = []
+prep scores = {"/score": 12}
+praise handle(req):
+   array.push(hits, req.path)
+   if hash.has(scores, req.path):
+      serve "found"
+   beef
+   serve "missing"
+beef`)
+	fn, _ := env.Get("handle")
+	h := httpHandler(Default, fn.(*object.Function))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				assert.Equal(t, "found", responseBody(request(h, "GET", "/score", "")))
+			}
+		}()
+	}
+	wg.Wait()
+
+	hits, _ := env.Get("hits")
+	assert.Equal(t, 200, hits.(*object.Array).Len())
+}
@@ -0,0 +1,58 @@
+package evaluator
+
+import (
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// Interrupt asks the program running in rt to stop, e.g. on Ctrl+C. Loops and
+// function calls check for it before each step and fail with an "interrupted"
+// error, which unwinds the program like any other runtime error. It is safe
+// to call from any goroutine, and more than once.
+func (rt *Runtime) Interrupt() {
+	rt.interruptMu.Lock()
+	defer rt.interruptMu.Unlock()
+	select {
+	case <-rt.interrupt:
+	default:
+		close(rt.interrupt)
+	}
+}
+
+// Interrupted reports whether Interrupt has been called since the last ResetInterrupt.
+func (rt *Runtime) Interrupted() bool {
+	select {
+	case <-rt.interruptChan():
+		return true
+	default:
+		return false
+	}
+}
+
+// ResetInterrupt lets programs run again after an Interrupt, for hosts that
+// keep going afterwards like the REPL.
+func (rt *Runtime) ResetInterrupt() {
+	rt.interruptMu.Lock()
+	defer rt.interruptMu.Unlock()
+	select {
+	case <-rt.interrupt:
+		rt.interrupt = make(chan struct{})
+	default:
+	}
+}
+
+func (rt *Runtime) interruptChan() <-chan struct{} {
+	rt.interruptMu.Lock()
+	defer rt.interruptMu.Unlock()
+	return rt.interrupt
+}
+
+// checkInterrupt returns the error that stops the program at tok once it has
+// been interrupted, and nil otherwise.
+func (rt *Runtime) checkInterrupt(tok token.Token) *object.Error {
+	if !rt.Interrupted() {
+		return nil
+	}
+	return newError(tok, diagnostics.CodeInterrupted, "interrupted")
+}
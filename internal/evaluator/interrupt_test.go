@@ -0,0 +1,50 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterruptStopsLoops(t *testing.T) {
+	t.Cleanup(Default.ResetInterrupt)
+	time.AfterFunc(20*time.Millisecond, Default.Interrupt)
+
+	result := testEval("prep n = 0\nfeast while true:\n   n = n + 1\nbeef")
+
+	errObj, ok := result.(*object.Error)
+	if assert.True(t, ok, "expected an error, got %s", result.Inspect()) {
+		assert.Equal(t, diagnostics.CodeInterrupted, errObj.Code)
+		assert.Equal(t, "interrupted", errObj.Message)
+		assert.Equal(t, 2, errObj.Line)
+	}
+}
+
+func TestInterruptStopsRecursionAndSleep(t *testing.T) {
+	t.Cleanup(Default.ResetInterrupt)
+	Default.Interrupt()
+
+	tests := []string{
+		"praise down(n):\n   serve down(n + 1)\nbeef\ndown(0)",
+		"wrangle time\ntime.sleep(60000)",
+	}
+	for _, input := range tests {
+		errObj, ok := testEval(input).(*object.Error)
+		if assert.True(t, ok, input) {
+			assert.Equal(t, diagnostics.CodeInterrupted, errObj.Code, input)
+		}
+	}
+}
+
+func TestResetInterrupt(t *testing.T) {
+	Default.Interrupt()
+	Default.Interrupt()
+	assert.True(t, Default.Interrupted())
+
+	Default.ResetInterrupt()
+	assert.False(t, Default.Interrupted())
+	assert.Equal(t, int64(3), testEval("praise three():\n   serve 3\nbeef\nthree()").(*object.Integer).Value)
+}
@@ -0,0 +1,156 @@
+package evaluator
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// The intl module works on text the way readers of a language expect, using
+// the Unicode CLDR data in golang.org/x/text. Locales are BCP 47 tags: "en",
+// "de-AT", "sv", "tr", "pt-BR".
+func createIntlModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "intl",
+		Members: make(map[string]object.Object),
+	}
+
+	// compare - -1, 0 or 1 as a comes before, with or after b in a locale's
+	// alphabetical order, which byte order often isn't: "ä" sorts with "a"
+	// in German but after "z" in Swedish, and "B" comes before "a" by bytes
+	mod.Set("compare", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("intl.compare", args, 3); err != nil {
+				return err
+			}
+			a, err := object.StringArg("intl.compare", args[0])
+			if err != nil {
+				return err
+			}
+			b, err := object.StringArg("intl.compare", args[1])
+			if err != nil {
+				return err
+			}
+			tag, err := localeArg("intl.compare", args[2])
+			if err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(collate.New(tag).CompareString(a, b))}
+		},
+	})
+
+	// sort - a copy of an array of strings in a locale's alphabetical order:
+	//   prep menu = intl.sort(item_names, player_locale)
+	mod.Set("sort", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("intl.sort", args, 2); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("intl.sort: expected an ARRAY, got %s", args[0].Type())}
+			}
+			tag, err := localeArg("intl.sort", args[1])
+			if err != nil {
+				return err
+			}
+			elements := arr.Elements()
+			for i, el := range elements {
+				if _, ok := el.(*object.String); !ok {
+					return &object.Error{Code: diagnostics.CodeBadArgument,
+						Message: fmt.Sprintf("intl.sort: element %d is %s, not a STRING", i, el.Type())}
+				}
+			}
+			c := collate.New(tag)
+			sorted := elements
+			slices.SortStableFunc(sorted, func(a, b object.Object) int {
+				return c.CompareString(a.(*object.String).Value, b.(*object.String).Value)
+			})
+			return object.NewArray(sorted)
+		},
+	})
+
+	// fold - a string with case differences folded away, to compare text
+	// regardless of case: intl.fold("STRASSE") == intl.fold("straße")
+	mod.Set("fold", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("intl.fold", args, 1); err != nil {
+				return err
+			}
+			s, err := object.StringArg("intl.fold", args[0])
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: cases.Fold().String(s)}
+		},
+	})
+
+	// lower, upper - a string in lower or upper case by a locale's rules:
+	// in Turkish the capital of "i" is "İ"
+	mod.Set("lower", caseBuiltin("intl.lower", cases.Lower))
+	mod.Set("upper", caseBuiltin("intl.upper", cases.Upper))
+
+	// format_number - an integer with a locale's digit grouping:
+	//   intl.format_number(1234567, "de")    # "1.234.567"
+	mod.Set("format_number", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("intl.format_number", args, 2); err != nil {
+				return err
+			}
+			n, err := object.IntegerArg("intl.format_number", args[0])
+			if err != nil {
+				return err
+			}
+			tag, err := localeArg("intl.format_number", args[1])
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: message.NewPrinter(tag).Sprint(n)}
+		},
+	})
+
+	return mod
+}
+
+// caseBuiltin makes a builtin that changes the case of a string by a
+// locale's rules.
+func caseBuiltin(name string, caser func(language.Tag, ...cases.Option) cases.Caser) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount(name, args, 2); err != nil {
+				return err
+			}
+			s, err := object.StringArg(name, args[0])
+			if err != nil {
+				return err
+			}
+			tag, err := localeArg(name, args[1])
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: caser(tag).String(s)}
+		},
+	}
+}
+
+// localeArg returns the value of an argument that must be a locale tag.
+func localeArg(name string, arg object.Object) (language.Tag, *object.Error) {
+	s, err := object.StringArg(name, arg)
+	if err != nil {
+		return language.Und, err
+	}
+	tag, parseErr := language.Parse(s)
+	if parseErr != nil {
+		return language.Und, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: %q is not a locale", name, s)}
+	}
+	return tag, nil
+}
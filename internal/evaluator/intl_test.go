@@ -0,0 +1,61 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntl(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// German sorts ä with a; Swedish puts it after z
+		{`intl.sort(["zebra", "äpple", "Apfel", "banana"], "de")`, `["Apfel", "äpple", "banana", "zebra"]`},
+		{`intl.sort(["zebra", "äpple", "Apfel", "banana"], "sv")`, `["Apfel", "banana", "zebra", "äpple"]`},
+		{`intl.sort([], "en")`, "[]"},
+		{`[intl.compare("B", "a", "en"), intl.compare("a", "B", "en"), intl.compare("x", "x", "en")]`, "[1, -1, 0]"},
+		{`intl.fold("STRASSE") == intl.fold("straße")`, "true"},
+		{`intl.upper("istanbul", "tr")`, "İSTANBUL"},
+		{`intl.upper("istanbul", "en")`, "ISTANBUL"},
+		{`intl.lower("DİYARBAKIR", "tr")`, "diyarbakır"},
+		{`intl.format_number(1234567, "de")`, "1.234.567"},
+		{`intl.format_number(-1234567, "en-US")`, "-1,234,567"},
+		{`intl.format_number(1234567, "hi")`, "12,34,567"},
+		{`intl.format_number(999, "en")`, "999"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle intl\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestIntlSortLeavesTheOriginal(t *testing.T) {
+	result := testEval("wrangle intl\nprep names = [\"b\", \"a\"]\nintl.sort(names, \"en\")\nnames")
+	assert.Equal(t, `["b", "a"]`, result.Inspect())
+}
+
+func TestIntlErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`intl.compare("a", "b", "not a locale!")`, `intl.compare: "not a locale!" is not a locale`},
+		{`intl.sort(["a", 1], "en")`, "intl.sort: element 1 is INTEGER, not a STRING"},
+		{`intl.sort("ab", "en")`, "intl.sort: expected an ARRAY, got STRING"},
+		{`intl.upper("a")`, "intl.upper takes 2 arguments, got 1"},
+		{`intl.format_number("1", "en")`, "intl.format_number: expected an INTEGER, got STRING"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle intl\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
@@ -0,0 +1,199 @@
+package evaluator
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/terminal"
+)
+
+// escapeWait is how long a lone Escape byte waits for the rest of an escape
+// sequence (arrow keys and the like arrive as ESC [ A, all at once).
+const escapeWait = 25 * time.Millisecond
+
+// keyboard reads standard input a byte at a time for io.getch and
+// io.poll_key. It starts on first use: a goroutine reads stdin into a channel
+// so keys can be polled without blocking, and when stdin is a terminal it is
+// put into cbreak mode until RestoreTerminal.
+type keyboard struct {
+	mu      sync.Mutex
+	bytes   chan byte // closed at end of input
+	restore func() error
+}
+
+// keyBytes starts the keyboard if needed and returns its byte channel.
+func (rt *Runtime) keyBytes() chan byte {
+	keyboard := &rt.keyboard
+	keyboard.mu.Lock()
+	defer keyboard.mu.Unlock()
+
+	if keyboard.bytes == nil {
+		keyboard.bytes = make(chan byte, 64)
+		go readKeyBytes(rt.Stdin, keyboard.bytes)
+	}
+	if stdin := streamFile(rt.Stdin); keyboard.restore == nil && terminal.IsTerminal(stdin) {
+		// Without cbreak mode keys still arrive, just a line at a time
+		keyboard.restore, _ = terminal.Cbreak(stdin)
+	}
+	return keyboard.bytes
+}
+
+func readKeyBytes(r io.Reader, bytes chan<- byte) {
+	buf := make([]byte, 1)
+	for {
+		if _, err := r.Read(buf); err != nil {
+			close(bytes)
+			return
+		}
+		bytes <- buf[0]
+	}
+}
+
+// RestoreTerminal takes the terminal out of cbreak mode if io.getch or
+// io.poll_key put it there. Call it before the program exits.
+func (rt *Runtime) RestoreTerminal() {
+	keyboard := &rt.keyboard
+	keyboard.mu.Lock()
+	defer keyboard.mu.Unlock()
+	if keyboard.restore != nil {
+		keyboard.restore()
+		keyboard.restore = nil
+	}
+}
+
+// keyboardLine reads a line for io.input once the keyboard has started, since
+// its goroutine now owns stdin. The terminal goes back to its usual mode for
+// the line, so it is echoed and can be edited. ok is false when the keyboard
+// hasn't started and stdin can be read directly.
+func (rt *Runtime) keyboardLine() (line string, ok bool) {
+	keyboard := &rt.keyboard
+	keyboard.mu.Lock()
+	bytes := keyboard.bytes
+	if bytes == nil {
+		keyboard.mu.Unlock()
+		return "", false
+	}
+	if keyboard.restore != nil {
+		keyboard.restore()
+		keyboard.restore = nil
+	}
+	keyboard.mu.Unlock()
+
+	var buf []byte
+	for b := range bytes {
+		if b == '\n' {
+			break
+		}
+		buf = append(buf, b)
+	}
+	return strings.TrimSuffix(string(buf), "\r"), true
+}
+
+// getch waits for the next key, and returns "" at the end of input.
+func (rt *Runtime) getch() object.Object {
+	bytes := rt.keyBytes()
+	select {
+	case b, ok := <-bytes:
+		if !ok {
+			return &object.String{Value: ""}
+		}
+		return &object.String{Value: decodeKey(b, bytes)}
+	case <-rt.interruptChan():
+		return &object.Error{Code: diagnostics.CodeInterrupted, Message: "interrupted"}
+	}
+}
+
+// pollKey returns the next key if one has been pressed, and "" otherwise.
+func (rt *Runtime) pollKey() object.Object {
+	bytes := rt.keyBytes()
+	select {
+	case b, ok := <-bytes:
+		if !ok {
+			return &object.String{Value: ""}
+		}
+		return &object.String{Value: decodeKey(b, bytes)}
+	default:
+		return &object.String{Value: ""}
+	}
+}
+
+// escapeKeys names the keys that send an escape sequence, without its ESC.
+var escapeKeys = map[string]string{
+	"[A": "up", "[B": "down", "[C": "right", "[D": "left",
+	"OA": "up", "OB": "down", "OC": "right", "OD": "left",
+	"[H": "home", "[F": "end", "OH": "home", "OF": "end",
+	"[1~": "home", "[4~": "end", "[2~": "insert", "[3~": "delete",
+	"[5~": "page_up", "[6~": "page_down",
+}
+
+// decodeKey turns the first byte of a key, and whatever else belongs to it on
+// bytes, into the key's name: the character itself for printable keys, or
+// "enter", "tab", "backspace", "escape", "up", "ctrl+a" and so on.
+func decodeKey(first byte, bytes <-chan byte) string {
+	switch {
+	case first == '\r' || first == '\n':
+		return "enter"
+	case first == '\t':
+		return "tab"
+	case first == 127 || first == '\b':
+		return "backspace"
+	case first == 27:
+		return decodeEscape(bytes)
+	case first == 0:
+		return "ctrl+space"
+	case first <= 26:
+		return "ctrl+" + string(rune('a'+first-1))
+	case first < 32:
+		return "unknown"
+	case first < utf8.RuneSelf:
+		return string(rune(first))
+	}
+
+	// The rest of a multi-byte character follows straight away
+	buf := []byte{first}
+	for !utf8.FullRune(buf) {
+		b, ok := <-bytes
+		if !ok {
+			break
+		}
+		buf = append(buf, b)
+	}
+	return string(buf)
+}
+
+// decodeEscape reads the rest of an escape sequence. A lone ESC, with nothing
+// following it quickly, is the Escape key.
+func decodeEscape(bytes <-chan byte) string {
+	var seq []byte
+	timeout := time.After(escapeWait)
+	for {
+		select {
+		case b, ok := <-bytes:
+			if !ok {
+				return "escape"
+			}
+			seq = append(seq, b)
+			if len(seq) == 1 && b != '[' && b != 'O' {
+				// Alt+key sends ESC then the key
+				return "alt+" + decodeKey(b, bytes)
+			}
+			// Sequences end with a letter or '~'
+			if len(seq) > 1 && (b == '~' || b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z') {
+				if name, ok := escapeKeys[string(seq)]; ok {
+					return name
+				}
+				return "unknown"
+			}
+		case <-timeout:
+			if len(seq) == 0 {
+				return "escape"
+			}
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// withKeys makes the keyboard read input instead of stdin, followed by the
+// end of input when end is set
+func withKeys(t *testing.T, input string, end bool) {
+	bytes := make(chan byte, len(input))
+	for i := 0; i < len(input); i++ {
+		bytes <- input[i]
+	}
+	if end {
+		close(bytes)
+	}
+	Default.keyboard.bytes = bytes
+	t.Cleanup(func() { Default.keyboard.bytes = nil })
+}
+
+func TestGetchDecodesKeys(t *testing.T) {
+	withKeys(t, "a \r\x7f\t\x1b[A\x1bOD\x1b[3~\x01é\x1bx\x1b", true)
+
+	var keys []string
+	for {
+		key := testEval("wrangle io\nio.getch()").(*object.String).Value
+		if key == "" {
+			break
+		}
+		keys = append(keys, key)
+	}
+
+	assert.Equal(t, []string{
+		"a", " ", "enter", "backspace", "tab", "up", "left", "delete", "ctrl+a", "é", "alt+x", "escape",
+	}, keys)
+}
+
+func TestPollKeyDoesNotWait(t *testing.T) {
+	withKeys(t, "q", false)
+
+	assert.Equal(t, "q", testEval("wrangle io\nio.poll_key()").Inspect())
+	assert.Equal(t, "", testEval("wrangle io\nio.poll_key()").Inspect())
+}
+
+func TestInputReadsLinesAfterKeys(t *testing.T) {
+	withKeys(t, "yBrisket\r\nmore", true)
+
+	assert.Equal(t, "y", testEval("wrangle io\nio.getch()").Inspect())
+	assert.Equal(t, "Brisket", testEval("wrangle io\nio.input()").Inspect())
+	assert.Equal(t, "more", testEval("wrangle io\nio.input()").Inspect())
+}
+
+func TestGetchIsInterruptible(t *testing.T) {
+	t.Cleanup(Default.ResetInterrupt)
+	withKeys(t, "", false)
+	Default.Interrupt()
+
+	result := testEval("wrangle io\nio.getch()")
+	assert.Equal(t, "interrupted", result.(*object.Error).Message)
+}
+
+func TestKeyFunctionsTakeNoArguments(t *testing.T) {
+	result := testEval("wrangle io\nio.getch(1)")
+	assert.Equal(t, "io.getch takes no arguments, got 1", result.(*object.Error).Message)
+}
@@ -0,0 +1,169 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+func createMathModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "math",
+		Members: make(map[string]object.Object),
+	}
+
+	// lerp - the number t thousandths of the way from a to b, rounded:
+	//   math.lerp(0, 200, 250)    # 50
+	// t outside 0 to 1000 goes past either end.
+	mod.Set("lerp", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			n, err := integerArgs("math.lerp", args, 3)
+			if err != nil {
+				return err
+			}
+			result, ok := lerp(n[0], n[1], n[2])
+			if !ok {
+				return &object.Error{Code: diagnostics.CodeIntegerOverflow, Message: "integer overflow in math.lerp"}
+			}
+			return &object.Integer{Value: result}
+		},
+	})
+
+	// clamp - x kept between lo and hi:
+	//   hp = math.clamp(hp + heal, 0, max_hp)
+	mod.Set("clamp", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			n, err := integerArgs("math.clamp", args, 3)
+			if err != nil {
+				return err
+			}
+			if n[1] > n[2] {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("math.clamp: lower bound %d is above upper bound %d", n[1], n[2])}
+			}
+			return &object.Integer{Value: min(max(n[0], n[1]), n[2])}
+		},
+	})
+
+	// smoothstep - how far x is from edge0 to edge1, in thousandths, eased so
+	// it starts and ends slowly; 0 before edge0 and 1000 after edge1:
+	//   prep fade = math.smoothstep(0, 60, frame)
+	mod.Set("smoothstep", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			n, err := integerArgs("math.smoothstep", args, 3)
+			if err != nil {
+				return err
+			}
+			edge0, edge1, x := n[0], n[1], n[2]
+			if edge0 == edge1 {
+				if x < edge0 {
+					return &object.Integer{Value: 0}
+				}
+				return &object.Integer{Value: fractionScale}
+			}
+			t := (float64(x) - float64(edge0)) / (float64(edge1) - float64(edge0))
+			t = min(max(t, 0), 1)
+			return &object.Integer{Value: int64(math.Round(t * t * (3 - 2*t) * fractionScale))}
+		},
+	})
+
+	// noise - smooth 2D Perlin noise between -1000 and 1000, the same every
+	// time for the same point and seed (0 if left out). x and y are in
+	// thousandths of the noise's cells, so features are about 1000 apart -
+	// multiply tile positions to choose their size:
+	//   prep height = math.noise(tx * 50, ty * 50, world_seed)
+	mod.Set("noise", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("math.noise takes 2 or 3 arguments, got %d", len(args))}
+			}
+			n, err := integerArgs("math.noise", args, len(args))
+			if err != nil {
+				return err
+			}
+			var seed int64
+			if len(n) == 3 {
+				seed = n[2]
+			}
+			value := perlin(float64(n[0])/fractionScale, float64(n[1])/fractionScale, seed)
+			return &object.Integer{Value: int64(math.Round(min(max(value, -1), 1) * fractionScale))}
+		},
+	})
+
+	return mod
+}
+
+// integerArgs returns the values of want arguments that must all be
+// integers.
+func integerArgs(name string, args []object.Object, want int) ([]int64, *object.Error) {
+	if err := object.CheckArgCount(name, args, want); err != nil {
+		return nil, err
+	}
+	values := make([]int64, len(args))
+	for i, arg := range args {
+		n, err := object.IntegerArg(name, arg)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = n
+	}
+	return values, nil
+}
+
+// perlin returns 2D gradient noise at (x, y), roughly between -1 and 1 and 0
+// at every point with integer coordinates. Each of those points has a
+// gradient picked by hashing its coordinates with the seed, so no table has
+// to be built per seed.
+func perlin(x, y float64, seed int64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	ix, iy := int64(x0), int64(y0)
+
+	u, v := fade(fx), fade(fy)
+	bottom := lerpFloat(gradient(ix, iy, seed, fx, fy), gradient(ix+1, iy, seed, fx-1, fy), u)
+	top := lerpFloat(gradient(ix, iy+1, seed, fx, fy-1), gradient(ix+1, iy+1, seed, fx-1, fy-1), u)
+	return lerpFloat(bottom, top, v)
+}
+
+// gradient returns the dot product of the gradient at lattice point (ix, iy)
+// with the offset (dx, dy) from it.
+func gradient(ix, iy, seed int64, dx, dy float64) float64 {
+	h := uint64(ix)*0x9E3779B97F4A7C15 ^ uint64(iy)*0xC2B2AE3D27D4EB4F ^ uint64(seed)*0x165667B19E3779F9
+	// The splitmix64 finalizer, so that nearby points get unrelated bits
+	h ^= h >> 30
+	h *= 0xBF58476D1CE4E5B9
+	h ^= h >> 27
+	h *= 0x94D049BB133111EB
+	h ^= h >> 31
+
+	switch h & 7 {
+	case 0:
+		return dx + dy
+	case 1:
+		return -dx + dy
+	case 2:
+		return dx - dy
+	case 3:
+		return -dx - dy
+	case 4:
+		return dx
+	case 5:
+		return -dx
+	case 6:
+		return dy
+	default:
+		return -dy
+	}
+}
+
+// fade is Perlin's easing curve, 6t⁵ - 15t⁴ + 10t³.
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func lerpFloat(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"math.lerp(0, 200, 250)", "50"},
+		{"math.lerp(10, 0, 500)", "5"},
+		{"math.lerp(0, 3, 500)", "2"},
+		{"math.lerp(0, 100, 1500)", "150"},
+		{"math.clamp(15, 0, 10)", "10"},
+		{"math.clamp(-3, 0, 10)", "0"},
+		{"math.clamp(4, 0, 10)", "4"},
+		{"math.smoothstep(0, 100, -5)", "0"},
+		{"math.smoothstep(0, 100, 50)", "500"},
+		{"math.smoothstep(0, 100, 25)", "156"},
+		{"math.smoothstep(0, 100, 200)", "1000"},
+		{"math.smoothstep(5, 5, 5)", "1000"},
+		// Noise is 0 on the corners of its cells, and the same every time
+		{"math.noise(3000, -2000)", "0"},
+		{"math.noise(1234, 5678, 9) == math.noise(1234, 5678, 9)", "true"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle math\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestPerlinNoise(t *testing.T) {
+	differs := false
+	for x := -2.0; x < 2; x += 0.07 {
+		for y := -2.0; y < 2; y += 0.07 {
+			value := perlin(x, y, 1)
+			assert.LessOrEqual(t, value, 1.0)
+			assert.GreaterOrEqual(t, value, -1.0)
+			// Smooth: a small step changes the value a little
+			assert.InDelta(t, value, perlin(x+0.001, y, 1), 0.01)
+			if value != perlin(x, y, 2) {
+				differs = true
+			}
+		}
+	}
+	assert.True(t, differs, "another seed gives other noise")
+}
+
+func TestMathErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"math.clamp(1, 10, 0)", "math.clamp: lower bound 10 is above upper bound 0"},
+		{"math.clamp(1, 2)", "math.clamp takes 3 arguments, got 2"},
+		{`math.lerp(0, "10", 5)`, "math.lerp: expected an INTEGER, got STRING"},
+		{"math.lerp(0, 9223372036854775807, 2000)", "integer overflow in math.lerp"},
+		{"math.noise(1)", "math.noise takes 2 or 3 arguments, got 1"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle math\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
@@ -0,0 +1,133 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+)
+
+// The meta module looks at the program itself rather than at its data.
+func createMetaModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "meta",
+		Members: make(map[string]object.Object),
+	}
+
+	// doc - a function's docstring (or doc comment), or a wrangled file's
+	// module doc; null when there is none:
+	//   io.preach(meta.doc(cost))
+	mod.Set("doc", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("meta.doc", args, 1); err != nil {
+				return err
+			}
+			var doc string
+			switch v := args[0].(type) {
+			case *object.Function:
+				doc = v.Doc
+			case *object.Module:
+				doc = v.Doc
+			case *object.Builtin:
+			default:
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("meta.doc: expected a function or module, got %s", args[0].Type())}
+			}
+			if doc == "" {
+				return object.NULL
+			}
+			return &object.String{Value: doc}
+		},
+	})
+
+	// eval - run a string of Beeflang code (needs --allow-eval):
+	//   prep result = meta.eval("hp * 2")
+	// The code runs in the scope of the call, so it sees and can change the
+	// caller's variables. Given a hash of names, it runs in a fresh scope
+	// holding only those instead:
+	//   meta.eval("x + 1", {"x": 41})
+	// Returns a hash with "value", the value of the code's last statement
+	// (or what it served), and "error", the message of the syntax or runtime
+	// error that stopped it or null. An error doesn't stop the caller, so a
+	// console can report a typo and carry on.
+	mod.Set("eval", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return evalCode(rt, nil, args...)
+		},
+		EnvFn: func(env *Environment, args ...object.Object) object.Object {
+			return evalCode(rt, env, args...)
+		},
+	})
+
+	return mod
+}
+
+// evalCode implements meta.eval, running the code in env. Called from Go,
+// by another builtin, env is nil and the code runs in a fresh scope of rt.
+func evalCode(rt *Runtime, env *Environment, args ...object.Object) object.Object {
+	if err := checkCapability(rt.Allowed.Eval, capEval, "meta.eval"); err != nil {
+		return err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("meta.eval takes 1 or 2 arguments, got %d", len(args))}
+	}
+	code, err := object.StringArg("meta.eval", args[0])
+	if err != nil {
+		return err
+	}
+	if len(args) == 2 || env == nil {
+		env = rt.NewEnvironment()
+	}
+	if len(args) == 2 {
+		names, ok := args[1].(*object.Hash)
+		if !ok {
+			return &object.Error{Code: diagnostics.CodeBadArgument,
+				Message: fmt.Sprintf("meta.eval: expected a hash of names, got %s", args[1].Type())}
+		}
+		for _, pair := range names.Pairs() {
+			name, ok := pair.Key.(*object.String)
+			if !ok {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("meta.eval: names must be strings, got %s", pair.Key.Type())}
+			}
+			env.Set(name.Value, pair.Value)
+		}
+	}
+
+	l := lexer.New(code)
+	l.SetConditions(rt.Conditions)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return evalResult(object.NULL, strings.Join(p.Errors(), "; "))
+	}
+
+	result := Eval(program, env)
+	if errObj, ok := result.(*object.Error); ok {
+		// Stopping the whole program isn't the code's failure to report
+		if errObj.Code == diagnostics.CodeInterrupted || errObj.Code == diagnostics.CodeExit {
+			return errObj
+		}
+		return evalResult(object.NULL, fmt.Sprintf("[line %d, col %d] %s", errObj.Line, errObj.Column, errObj.Message))
+	}
+	if result == nil {
+		result = object.NULL
+	}
+	return evalResult(result, "")
+}
+
+// evalResult is the hash meta.eval returns.
+func evalResult(value object.Object, message string) *object.Hash {
+	var errValue object.Object = object.NULL
+	if message != "" {
+		errValue = &object.String{Value: message}
+	}
+	result := object.NewHash()
+	result.Set(&object.String{Value: "value"}, value)
+	result.Set(&object.String{Value: "error"}, errValue)
+	return result
+}
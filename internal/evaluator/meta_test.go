@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetaDoc(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`praise add(a, b):
+   "add sums two amounts of beef."
+   serve a + b
+beef
+meta.doc(add)`, "add sums two amounts of beef."},
+		// The docstring is still just a statement, so the function runs as before
+		{`praise add(a, b):
+   "add sums two amounts of beef."
+   serve a + b
+beef
+add(2, 3)`, "5"},
+		{`# half divides by two.
+praise half(n):
+   serve n / 2
+beef
+meta.doc(half)`, "half divides by two."},
+		{`praise plain():
+beef
+meta.doc(plain)`, "null"},
+		{"wrangle io\nmeta.doc(io.preach)", "null"},
+		{"wrangle io\nmeta.doc(io)", "null"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle meta\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestMetaDocErrors(t *testing.T) {
+	result := testEval("wrangle meta\nmeta.doc(5)")
+	errObj, ok := result.(*object.Error)
+	if !ok {
+		t.Fatalf("expected an error, got %T (%+v)", result, result)
+	}
+	assert.Equal(t, "meta.doc: expected a function or module, got INTEGER", errObj.Message)
+}
+
+func TestMetaEval(t *testing.T) {
+	allow(t, Capabilities{Eval: true})
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`meta.eval("1 + 2")`, `{"value": 3, "error": null}`},
+		// The code runs in the caller's scope
+		{`prep hp = 10
+meta.eval("hp = hp * 2")
+hp`, "20"},
+		{`praise heal(hp):
+   serve meta.eval("hp + 1")["value"]
+beef
+heal(4)`, "5"},
+		{`meta.eval("prep made = 1")
+made`, "1"},
+		// Or, given names, in a fresh scope with only those
+		{`meta.eval("x + 1", {"x": 41})`, `{"value": 42, "error": null}`},
+		{`prep hp = 10
+meta.eval("hp", {})`, `{"value": null, "error": "[line 1, col 1] identifier not found: hp"}`},
+		{`meta.eval("serve 7")`, `{"value": 7, "error": null}`},
+		{`meta.eval("")`, `{"value": null, "error": null}`},
+		// Errors are returned, not raised
+		{`meta.eval("prep = 1")["error"]`, "[line 1, col 6] expected next token to be IDENT, got = instead; " +
+			"[line 1, col 6] no prefix parse function for = found"},
+		{`meta.eval("1 / 0")["error"]`, "[line 1, col 3] division by zero: 1 / 0"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle meta\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestMetaEvalErrors(t *testing.T) {
+	allow(t, Capabilities{Eval: true})
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`meta.eval()`, "meta.eval takes 1 or 2 arguments, got 0"},
+		{`meta.eval(5)`, "meta.eval: expected a STRING, got INTEGER"},
+		{`meta.eval("1", [1])`, "meta.eval: expected a hash of names, got ARRAY"},
+		{`meta.eval("1", {1: 2})`, "meta.eval: names must be strings, got INTEGER"},
+		// Stopping the program still stops it
+		{"wrangle os\nmeta.eval(\"os.exit(3)\")", "exit status 3"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle meta\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
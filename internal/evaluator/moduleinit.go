@@ -0,0 +1,84 @@
+package evaluator
+
+import (
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// InitFunction is the name of the function a module file can declare to set
+// itself up the first time it is used, rather than when it is wrangled.
+const InitFunction = "init"
+
+// moduleInit is the init() of a loaded module and whether it has run.
+type moduleInit struct {
+	fn      *object.Function
+	running bool
+	err     *object.Error // what init() failed with, once it has run
+}
+
+// takeInit removes a module file's init() from its members, so only the
+// runtime calls it, and registers it to run before the module is first used.
+// A non-function called init is an ordinary member.
+func (rt *Runtime) takeInit(mod *object.Module) *object.Error {
+	fn, ok := mod.Members[InitFunction].(*object.Function)
+	if !ok {
+		return nil
+	}
+	if len(fn.Parameters) > 0 {
+		return newError(fn.Parameters[0].Token, diagnostics.CodeBadArgument,
+			"init() in module %s takes no parameters: it's called for you, the first time the module is used", mod.Name)
+	}
+	delete(mod.Members, InitFunction)
+
+	modules := &rt.modules
+	modules.mu.Lock()
+	modules.inits[mod] = &moduleInit{fn: fn}
+	modules.mu.Unlock()
+	modules.pendingInits.Add(1)
+	return nil
+}
+
+// readyModule runs a module's init(), if it has one that hasn't run, before
+// the module's members are used: read with module.member, bound by an expose
+// list, or copied into a namespace or by an expose statement. init() runs
+// once per loaded module, however many files wrangle it; if it fails, that
+// use and every later one fail with its error.
+//
+// Like a module's top level, an init() still running can't be waited for: a
+// use of the module from inside it (through another module) or from another
+// task is an error. Use such modules before stampeding.
+func (rt *Runtime) readyModule(mod *object.Module, tok token.Token) *object.Error {
+	modules := &rt.modules
+	if modules.pendingInits.Load() == 0 {
+		return nil
+	}
+	modules.mu.Lock()
+	init, ok := modules.inits[mod]
+	switch {
+	case !ok:
+		modules.mu.Unlock()
+		return nil
+	case init.running:
+		modules.mu.Unlock()
+		return newError(tok, diagnostics.CodeCircularWrangle, "module %s was used while its init() was running", mod.Name)
+	case init.err != nil:
+		modules.mu.Unlock()
+		return init.err
+	}
+	init.running = true
+	modules.mu.Unlock()
+
+	result := CallFunction(init.fn)
+
+	modules.mu.Lock()
+	defer modules.mu.Unlock()
+	init.running = false
+	if errObj, ok := result.(*object.Error); ok {
+		init.err = errObj
+		return errObj
+	}
+	delete(modules.inits, mod)
+	modules.pendingInits.Add(-1)
+	return nil
+}
@@ -0,0 +1,128 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// withInitModules writes a module whose init() records that it ran in
+// record.runs, and points the loader at it
+func withInitModules(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "record", `prep runs = []`)
+	writeModule(t, dir, "tables", `
+wrangle array
+wrangle record
+prep sines = []
+
+praise init():
+   array.push(record.runs, "init")
+   array.push(sines, 0)
+   array.push(sines, 17)
+beef
+
+praise sine(i):
+   serve sines[i]
+beef
+`)
+	writeModule(t, dir, "menu", `
+wrangle tables
+praise first():
+   serve tables.sine(1)
+beef
+`)
+	withSearchPath(t, dir)
+}
+
+func TestModuleInitRunsOnFirstUse(t *testing.T) {
+	withInitModules(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Wrangling alone doesn't run it
+		{"wrangle record\nwrangle tables\nrecord.runs", "[]"},
+		{"wrangle record\nwrangle tables\ntables.sine(1)", "17"},
+		// Once per loaded module, whoever uses it
+		{"wrangle record\nwrangle menu\nmenu.first()\nrecord.runs", `["init"]`},
+		// It isn't a member
+		{"wrangle tables\ntables.init", "null"},
+	}
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestModuleInitRunsWhenMembersAreExposed(t *testing.T) {
+	withInitModules(t)
+
+	result := testEval(`
+wrangle record
+wrangle tables expose sine
+record.runs
+`)
+	assert.Equal(t, `["init"]`, result.Inspect())
+}
+
+func TestModuleInitErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "broken", `
+praise init():
+   serve 1 / 0
+beef
+prep value = 1
+`)
+	writeModule(t, dir, "needy", `
+praise init(settings):
+beef
+`)
+	withSearchPath(t, dir)
+
+	// A failed init() fails every use of the module, not just the first
+	for i := 0; i < 2; i++ {
+		result := testEval("wrangle broken\nbroken.value")
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error, got %v", result)
+		}
+		assert.Equal(t, diagnostics.CodeDivisionByZero, errObj.Code)
+	}
+
+	result := testEval("wrangle needy")
+	errObj, ok := result.(*object.Error)
+	if !ok {
+		t.Fatalf("expected an error, got %v", result)
+	}
+	assert.Equal(t, "init() in module needy takes no parameters: it's called for you, the first time the module is used", errObj.Message)
+}
+
+func TestModuleInitCantUseItsOwnModule(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "world", `
+prep size = 10
+praise init():
+   wrangle tiles
+   tiles.count()
+beef
+`)
+	writeModule(t, dir, "tiles", `
+wrangle world
+praise count():
+   serve world.size * world.size
+beef
+`)
+	withSearchPath(t, dir)
+
+	result := testEval("wrangle world\nworld.size")
+	errObj, ok := result.(*object.Error)
+	if !ok {
+		t.Fatalf("expected an error, got %v", result)
+	}
+	assert.Equal(t, diagnostics.CodeCircularWrangle, errObj.Code)
+	assert.Equal(t, "module world was used while its init() was running", errObj.Message)
+}
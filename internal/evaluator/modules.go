@@ -0,0 +1,463 @@
+package evaluator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/astcache"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+)
+
+// ModuleExtension is the file extension of Beeflang source modules.
+const ModuleExtension = ".beef"
+
+// moduleCache holds the modules a Runtime has loaded from disk. Its maps are
+// guarded by mu, since tasks started with 'stampede' may wrangle modules
+// concurrently; mu is only held while they are read or written, never while
+// a module's code runs.
+type moduleCache struct {
+	mu sync.Mutex
+	// loaded holds modules by absolute file path. A module's top-level code
+	// runs only once no matter how many times it is wrangled.
+	loaded map[string]*object.Module
+	// loading tracks modules whose top-level code is currently being
+	// evaluated, so that a module wrangling itself (directly or indirectly)
+	// reports an error instead of recursing forever.
+	loading map[string]bool
+	// files records every module file the loader has tried to load,
+	// including ones that failed to parse, so watch mode can wait for a fix.
+	files map[string]bool
+	// inits holds the init() functions of loaded modules that haven't run
+	// yet or failed, and pendingInits counts them, so using a module costs
+	// no locking once every init() has run (see readyModule).
+	inits        map[*object.Module]*moduleInit
+	pendingInits atomic.Int64
+}
+
+func newModuleCache() moduleCache {
+	return moduleCache{loaded: map[string]*object.Module{}, loading: map[string]bool{}, files: map[string]bool{},
+		inits: map[*object.Module]*moduleInit{}}
+}
+
+// BuildSearchPath combines the module search sources in precedence order:
+//  1. directories passed with --path (in the order given)
+//  2. entries of the BEEF_PATH environment variable (os.PathListSeparator-separated)
+//  3. the directory containing the script being run
+//
+// Empty entries are dropped and duplicates keep their first (highest precedence) position.
+func BuildSearchPath(flagPaths []string, beefPath string, scriptDir string) []string {
+	var candidates []string
+	candidates = append(candidates, flagPaths...)
+	if beefPath != "" {
+		candidates = append(candidates, filepath.SplitList(beefPath)...)
+	}
+	candidates = append(candidates, scriptDir)
+
+	seen := map[string]bool{}
+	dirs := []string{}
+	for _, dir := range candidates {
+		if dir == "" || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// LoadedModuleFiles returns the absolute paths of every module and data file loaded so far
+// (successfully or not), sorted. Watch mode uses it to know which files a program depends on.
+func (rt *Runtime) LoadedModuleFiles() []string {
+	rt.modules.mu.Lock()
+	defer rt.modules.mu.Unlock()
+	files := make([]string, 0, len(rt.modules.files))
+	for path := range rt.modules.files {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ResetModuleCache forgets every loaded module, so the next wrangle re-reads
+// and re-runs the module file. Used when re-running a program after an edit.
+func (rt *Runtime) ResetModuleCache() {
+	rt.modules.mu.Lock()
+	defer rt.modules.mu.Unlock()
+	rt.modules.loaded = map[string]*object.Module{}
+	rt.modules.files = map[string]bool{}
+	rt.modules.inits = map[*object.Module]*moduleInit{}
+	rt.modules.pendingInits.Store(0)
+}
+
+// ForgetModule drops one module file from the cache, so the next wrangle of
+// it re-reads and re-runs the file. Hot reload uses it for modules that were
+// edited, leaving the others (and their state) alone.
+func (rt *Runtime) ForgetModule(path string) {
+	rt.modules.mu.Lock()
+	defer rt.modules.mu.Unlock()
+	delete(rt.modules.loaded, path)
+}
+
+// isPrivateMember reports whether a module member is private.
+// By convention, top-level names starting with an underscore are helpers
+// for the module's own code and can't be reached through module.member.
+func isPrivateMember(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
+
+// loadModule returns the module for a wrangle statement.
+// Built-in modules (like io) take priority; otherwise the name is resolved
+// against SearchPath and the matching .beef file is parsed and evaluated.
+func (rt *Runtime) loadModule(stmt *ast.WrangleStatement) object.Object {
+	if stmt.DataFile != nil {
+		return rt.loadDataFile(stmt)
+	}
+	name := stmt.ModuleName.Value
+
+	if create := builtinModule(name); create != nil {
+		return create(rt)
+	}
+
+	path, searched := rt.findModuleFile(name)
+	if path == "" {
+		return newError(stmt.Token, diagnostics.CodeModuleNotFound, "module not found: %s, searched: %s",
+			name, strings.Join(searched, ", "))
+	}
+
+	return rt.loadModuleFile(stmt, name, path)
+}
+
+// builtinModule returns the constructor of a built-in module, or nil if no
+// built-in module has that name.
+func builtinModule(name string) func(rt *Runtime) *object.Module {
+	switch name {
+	case "io":
+		return createIOModule
+	case "chan":
+		return createChanModule
+	case "sync":
+		return createSyncModule
+	case "time":
+		return createTimeModule
+	case "term":
+		return createTermModule
+	case "crypto":
+		return createCryptoModule
+	case "process":
+		return createProcessModule
+	case "net":
+		return createNetModule
+	case "http":
+		return createHTTPModule
+	case "template":
+		return createTemplateModule
+	case "os":
+		return createOSModule
+	case "flags":
+		return createFlagsModule
+	case "fs":
+		return createFSModule
+	case "array":
+		return createArrayModule
+	case "hash":
+		return createHashModule
+	case "copy":
+		return createCopyModule
+	case "cache":
+		return createCacheModule
+	case "meta":
+		return createMetaModule
+	case "parallel":
+		return createParallelModule
+	case "events":
+		return createEventsModule
+	case "timer":
+		return createTimerModule
+	case "coroutine":
+		return createCoroutineModule
+	case "vec":
+		return createVecModule
+	case "math":
+		return createMathModule
+	case "grid":
+		return createGridModule
+	case "bytes":
+		return createBytesModule
+	case "pack":
+		return createPackModule
+	case "intl":
+		return createIntlModule
+	}
+	return nil
+}
+
+// ModulePath returns the file that wrangling name would load, and the
+// locations searched for it. The path is "" if no directory on SearchPath has
+// the module; both are empty for a built-in module.
+func (rt *Runtime) ModulePath(name string) (string, []string) {
+	if builtinModule(name) != nil {
+		return "", nil
+	}
+	return rt.findModuleFile(name)
+}
+
+// findModuleFile resolves a bare module name against SearchPath (inside
+// ModuleFS when it is set). It returns the path of the first match (or "")
+// and every location it tried, so "module not found" errors can show exactly
+// where we looked.
+func (rt *Runtime) findModuleFile(name string) (string, []string) {
+	return rt.findFile(name + ModuleExtension)
+}
+
+// findFile looks for a relative path in each SearchPath directory, as
+// findModuleFile does. ${NAME} in a directory is replaced by that
+// environment variable; a directory naming one that isn't set is skipped.
+func (rt *Runtime) findFile(rel string) (string, []string) {
+	searched := []string{}
+	for _, entry := range rt.SearchPath {
+		dir, err := expandVars(entry)
+		if err != nil {
+			searched = append(searched, fmt.Sprintf("%s (%v)", entry, err))
+			continue
+		}
+		if rt.ModuleFS != nil {
+			candidate := path.Join(dir, rel)
+			searched = append(searched, candidate)
+			if info, err := fs.Stat(rt.ModuleFS, candidate); err == nil && !info.IsDir() {
+				return candidate, searched
+			}
+			continue
+		}
+
+		candidate := filepath.Join(dir, rel)
+		searched = append(searched, candidate)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, searched
+		}
+	}
+	return "", searched
+}
+
+// loadModuleFile parses and evaluates a module source file in its own global
+// environment. Every top-level binding becomes a member of the returned module.
+func (rt *Runtime) loadModuleFile(stmt *ast.WrangleStatement, name string, path string) object.Object {
+	absPath, err := filepath.Abs(path)
+	if err != nil || rt.ModuleFS != nil {
+		absPath = path
+	}
+
+	modules := &rt.modules
+	modules.mu.Lock()
+	if mod, ok := modules.loaded[absPath]; ok {
+		modules.mu.Unlock()
+		return mod
+	}
+	modules.files[absPath] = true
+	// A module still loading is usually wrangling itself, but may also be
+	// loading in another task right now. Waiting could deadlock on a real
+	// cycle, so both are errors: wrangle modules before stampeding.
+	if modules.loading[absPath] {
+		modules.mu.Unlock()
+		return newError(stmt.Token, diagnostics.CodeCircularWrangle, "circular wrangle of module %s (%s)", name, path)
+	}
+	modules.loading[absPath] = true
+	modules.mu.Unlock()
+	defer func() {
+		modules.mu.Lock()
+		delete(modules.loading, absPath)
+		modules.mu.Unlock()
+	}()
+	if rt.CollectStats {
+		start := time.Now()
+		defer func() { rt.countModuleTime(name, time.Since(start)) }()
+	}
+
+	program, errObj := rt.parseModuleFile(stmt, name, path)
+	if errObj != nil {
+		return errObj
+	}
+
+	env := rt.NewEnvironment()
+	result := evalFile(path, program, env)
+	if errObj, ok := result.(*object.Error); ok {
+		return errObj
+	}
+
+	mod := &object.Module{Name: name, Members: env.Bindings(), Doc: ast.CommentText(program.Doc)}
+	for _, stmt := range program.Statements {
+		if pasture, ok := stmt.(*ast.PastureStatement); ok {
+			mod.Pasture = pasture.Path()
+		}
+	}
+	if errObj := rt.takeInit(mod); errObj != nil {
+		errObj.File = path
+		return errObj
+	}
+	modules.mu.Lock()
+	modules.loaded[absPath] = mod
+	modules.mu.Unlock()
+	return mod
+}
+
+// parseModuleFile reads and parses a module file. With the parse cache on
+// (astcache.Dir set), a module parsed before is loaded from the cache instead.
+func (rt *Runtime) parseModuleFile(stmt *ast.WrangleStatement, name string, path string) (*ast.Program, *object.Error) {
+	file, err := rt.openModuleFile(path)
+	if err != nil {
+		return nil, newError(stmt.Token, diagnostics.CodeModuleLoadFailed, "could not read module %s: %v", name, err)
+	}
+	defer file.Close()
+
+	var l *lexer.Lexer
+	var source []byte
+	if astcache.Dir == "" {
+		// Modules may be large generated data files, so stream them through the lexer
+		l = lexer.NewReader(file)
+	} else {
+		// The cache is keyed by the source, so it has to be read first
+		source, err = io.ReadAll(file)
+		if err != nil {
+			return nil, newError(stmt.Token, diagnostics.CodeModuleLoadFailed, "could not read module %s: %v", name, err)
+		}
+		if program, ok := astcache.Load(source, rt.Conditions); ok {
+			return program, nil
+		}
+		l = lexer.New(string(source))
+	}
+	l.SetConditions(rt.Conditions)
+
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if err := l.Err(); err != nil {
+		return nil, newError(stmt.Token, diagnostics.CodeModuleLoadFailed, "could not read module %s: %v", name, err)
+	}
+	if len(p.Errors()) > 0 {
+		return nil, newError(stmt.Token, diagnostics.CodeModuleLoadFailed, "parse errors in module %s (%s): %s",
+			name, path, strings.Join(p.Errors(), "; "))
+	}
+
+	// A cache that can't be written only costs the next run a parse
+	if source != nil {
+		astcache.Store(source, rt.Conditions, program)
+	}
+	return program, nil
+}
+
+// openModuleFile opens a module file found by findModuleFile.
+func (rt *Runtime) openModuleFile(path string) (io.ReadCloser, error) {
+	if rt.ModuleFS != nil {
+		return rt.ModuleFS.Open(path)
+	}
+	return os.Open(path)
+}
+
+func createIOModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "io",
+		Members: make(map[string]object.Object),
+	}
+
+	// preach - print to stdout with newline
+	mod.Set("preach", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			for _, arg := range args {
+				val := displayValue(arg)
+				if isError(val) {
+					return val
+				}
+				fmt.Fprintln(rt.Stdout, val.Inspect())
+			}
+			return object.NULL
+		},
+	})
+
+	// inspect - a value as an unambiguous, literal-like string for
+	// debugging: inspect("1") is "\"1\"" where preach would show 1
+	mod.Set("inspect", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("io.inspect", args, 1); err != nil {
+				return err
+			}
+			return &object.String{Value: object.Repr(args[0])}
+		},
+	})
+
+	// format - fill in a format string's {} placeholders: format("HP: {}/{}", hp, max)
+	mod.Set("format", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return formatBuiltin("io.format", args)
+		},
+	})
+
+	// preachf - print a formatted string with newline
+	mod.Set("preachf", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			formatted := formatBuiltin("io.preachf", args)
+			if isError(formatted) {
+				return formatted
+			}
+			fmt.Fprintln(rt.Stdout, formatted.Inspect())
+			return object.NULL
+		},
+	})
+
+	// input - read line from stdin
+	mod.Set("input", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			// Optional: first argument is prompt
+			if len(args) > 0 {
+				fmt.Fprint(rt.Stdout, args[0].Inspect())
+			}
+
+			if line, ok := rt.keyboardLine(); ok {
+				return &object.String{Value: line}
+			}
+
+			scanner := bufio.NewScanner(rt.Stdin)
+			if scanner.Scan() {
+				return &object.String{Value: scanner.Text()}
+			}
+
+			return &object.String{Value: ""}
+		},
+	})
+
+	// getch - wait for a single keypress, without Enter. Printable keys come
+	// back as themselves; others by name: "enter", "tab", "backspace",
+	// "escape", "up", "down", "left", "right", "ctrl+a"... "" at end of input.
+	mod.Set("getch", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("io.getch", args, 0); err != nil {
+				return err
+			}
+			return rt.getch()
+		},
+	})
+
+	// poll_key - the next keypress like getch, or "" straight away if no key
+	// has been pressed
+	mod.Set("poll_key", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("io.poll_key", args, 0); err != nil {
+				return err
+			}
+			return rt.pollKey()
+		},
+	})
+
+	return mod
+}
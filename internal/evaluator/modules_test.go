@@ -0,0 +1,510 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/elitwilson/beeflang/internal/astcache"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeModule creates <dir>/<name>.beef with the given source
+func writeModule(t *testing.T, dir, name, source string) {
+	err := os.WriteFile(filepath.Join(dir, name+ModuleExtension), []byte(source), 0o644)
+	assert.NoError(t, err)
+}
+
+// withSearchPath points the module loader at dirs for the duration of a test
+func withSearchPath(t *testing.T, dirs ...string) {
+	oldPath := Default.SearchPath
+	Default.SearchPath = dirs
+	Default.ResetModuleCache()
+	t.Cleanup(func() {
+		Default.SearchPath = oldPath
+		Default.ResetModuleCache()
+	})
+}
+
+func TestBuildSearchPathPrecedence(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	dirs := BuildSearchPath([]string{"flagA", "flagB"}, "envA"+sep+sep+"envB"+sep+"flagA", "scripts")
+
+	assert.Equal(t, []string{"flagA", "flagB", "envA", "envB", "scripts"}, dirs)
+}
+
+func TestWrangleModuleFromSearchPath(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "butcher", `
+praise double(x):
+   serve x * 2
+beef
+prep cuts = 3
+`)
+	withSearchPath(t, dir)
+
+	result := testEval(`
+wrangle butcher
+butcher.double(butcher.cuts)
+`)
+
+	integer, ok := result.(*object.Integer)
+	assert.True(t, ok, "Result should be an Integer, got %v", result)
+	assert.Equal(t, int64(6), integer.Value)
+}
+
+func TestWrangleModuleFromModuleFS(t *testing.T) {
+	withSearchPath(t, "modules")
+	Default.ModuleFS = fstest.MapFS{
+		"modules/butcher.beef": {Data: []byte("wrangle util\nprep cuts = util.three()")},
+		"modules/util.beef":    {Data: []byte("praise three():\n   serve 3\nbeef")},
+	}
+	t.Cleanup(func() { Default.ModuleFS = nil })
+
+	assert.Equal(t, "3", testEval("wrangle butcher\nbutcher.cuts").Inspect())
+
+	path, searched := Default.ModulePath("butcher")
+	assert.Equal(t, "modules/butcher.beef", path)
+	assert.Equal(t, []string{"modules/butcher.beef"}, searched)
+
+	// Built-in modules never come from files
+	path, searched = Default.ModulePath("io")
+	assert.Empty(t, path)
+	assert.Nil(t, searched)
+}
+
+func TestWrangleUsesParseCache(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "butcher", "praise double(x):\n   serve x * 2\nbeef")
+	withSearchPath(t, dir)
+	astcache.Dir = t.TempDir()
+	t.Cleanup(func() { astcache.Dir = "" })
+
+	assert.Equal(t, "8", testEval("wrangle butcher\nbutcher.double(4)").Inspect())
+	entries, _ := filepath.Glob(filepath.Join(astcache.Dir, "*"+astcache.Extension))
+	assert.Len(t, entries, 1, "the parsed module is cached")
+
+	// The next run loads the module from the cache
+	Default.ResetModuleCache()
+	assert.Equal(t, "10", testEval("wrangle butcher\nbutcher.double(5)").Inspect())
+
+	// An edited module is parsed again
+	writeModule(t, dir, "butcher", "praise double(x):\n   serve x + x + 1\nbeef")
+	Default.ResetModuleCache()
+	assert.Equal(t, "11", testEval("wrangle butcher\nbutcher.double(5)").Inspect())
+	entries, _ = filepath.Glob(filepath.Join(astcache.Dir, "*"+astcache.Extension))
+	assert.Len(t, entries, 2)
+}
+
+func TestWrangleFirstSearchPathMatchWins(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	writeModule(t, first, "cut", `prep name = "ribeye"`)
+	writeModule(t, second, "cut", `prep name = "brisket"`)
+	withSearchPath(t, first, second)
+
+	result := testEval(`
+wrangle cut
+cut.name
+`)
+
+	str, ok := result.(*object.String)
+	assert.True(t, ok, "Result should be a String")
+	assert.Equal(t, "ribeye", str.Value)
+}
+
+func TestWrangleModuleNotFoundListsSearchedLocations(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	withSearchPath(t, first, second)
+
+	result := testEval("wrangle missing")
+
+	errObj, ok := result.(*object.Error)
+	assert.True(t, ok, "Expected error object")
+	assert.Contains(t, errObj.Message, "module not found: missing")
+	assert.Contains(t, errObj.Message, filepath.Join(first, "missing.beef"))
+	assert.Contains(t, errObj.Message, filepath.Join(second, "missing.beef"))
+}
+
+func TestWrangleExpandsEnvironmentVariablesInSearchPath(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "scripts"), 0o755))
+	writeModule(t, filepath.Join(dir, "scripts"), "cut", `prep name = "ribeye"`)
+	t.Setenv("BEEF_CONTENT", dir)
+	withSearchPath(t, "${BEEF_UNSET_DIR}/scripts", "${BEEF_CONTENT}/scripts")
+
+	assert.Equal(t, "ribeye", testEval("wrangle cut\ncut.name").Inspect())
+
+	_, searched := Default.ModulePath("missing")
+	assert.Equal(t, []string{
+		"${BEEF_UNSET_DIR}/scripts (environment variable BEEF_UNSET_DIR is not set)",
+		filepath.Join(dir, "scripts", "missing.beef"),
+	}, searched)
+}
+
+func TestWrangleModuleRunsTopLevelOnce(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "counter", `prep hits = 1`)
+	withSearchPath(t, dir)
+
+	testEval("wrangle counter")
+	cached := Default.modules.loaded
+
+	assert.Len(t, cached, 1, "module should be cached after first wrangle")
+
+	result := testEval(`
+wrangle counter
+counter.hits
+`)
+	integer, ok := result.(*object.Integer)
+	assert.True(t, ok, "Result should be an Integer")
+	assert.Equal(t, int64(1), integer.Value)
+}
+
+func TestLoadedModuleFilesIncludesFailedModules(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "good", `prep x = 1`)
+	writeModule(t, dir, "broken", `prep = 1`)
+	withSearchPath(t, dir)
+
+	testEval("wrangle good")
+	testEval("wrangle broken")
+
+	files := Default.LoadedModuleFiles()
+	assert.Len(t, files, 2)
+	assert.Equal(t, "broken.beef", filepath.Base(files[0]))
+	assert.Equal(t, "good.beef", filepath.Base(files[1]))
+
+	Default.ResetModuleCache()
+	assert.Empty(t, Default.LoadedModuleFiles())
+}
+
+func TestWrangleCircularModule(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "ouroboros", `wrangle ouroboros`)
+	withSearchPath(t, dir)
+
+	result := testEval("wrangle ouroboros")
+
+	errObj, ok := result.(*object.Error)
+	assert.True(t, ok, "Expected error object")
+	assert.Contains(t, errObj.Message, "circular wrangle")
+}
+
+func TestPrivateModuleMemberIsRejected(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "kitchen", `
+praise _season(x):
+   serve x + 1
+beef
+praise cook(x):
+   serve _season(x) * 2
+beef
+`)
+	withSearchPath(t, dir)
+
+	// Public functions can still use private helpers internally
+	result := testEval(`
+wrangle kitchen
+kitchen.cook(4)
+`)
+	integer, ok := result.(*object.Integer)
+	assert.True(t, ok, "Result should be an Integer, got %v", result)
+	assert.Equal(t, int64(10), integer.Value)
+
+	// But callers can't reach the helper
+	result = testEval(`
+wrangle kitchen
+kitchen._season(4)
+`)
+	errObj, ok := result.(*object.Error)
+	assert.True(t, ok, "Expected error object")
+	assert.Equal(t, "member '_season' is private to module 'kitchen'", errObj.Message)
+	assert.Equal(t, 3, errObj.Line)
+}
+
+func TestWrangleWithAlias(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "butcher", `prep cuts = 7`)
+	withSearchPath(t, dir)
+
+	result := testEval(`
+wrangle butcher as b
+b.cuts
+`)
+	integer, ok := result.(*object.Integer)
+	assert.True(t, ok, "Result should be an Integer, got %v", result)
+	assert.Equal(t, int64(7), integer.Value)
+
+	// The original name is not bound when an alias is used
+	result = testEval(`
+wrangle butcher as b
+butcher.cuts
+`)
+	errObj, ok := result.(*object.Error)
+	assert.True(t, ok, "Expected error object")
+	assert.Contains(t, errObj.Message, "identifier not found: butcher")
+}
+
+func TestWrangleExposeBindsMembersDirectly(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "butcher", `
+praise double(x):
+   serve x * 2
+beef
+prep cuts = 3
+`)
+	withSearchPath(t, dir)
+
+	result := testEval(`
+wrangle butcher expose double, cuts
+double(cuts)
+`)
+	integer, ok := result.(*object.Integer)
+	assert.True(t, ok, "Result should be an Integer, got %v", result)
+	assert.Equal(t, int64(6), integer.Value)
+
+	// Only the exposed members come in - not the module itself
+	result = testEval(`
+wrangle butcher expose double
+butcher
+`)
+	_, ok = result.(*object.Error)
+	assert.True(t, ok, "module name should not be bound by a plain expose list")
+}
+
+func TestWrangleExposeErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "butcher", `prep _secret = 1`)
+	withSearchPath(t, dir)
+
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"wrangle butcher expose missing", "module 'butcher' has no member 'missing'"},
+		{"wrangle butcher expose _secret", "member '_secret' is private to module 'butcher'"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+
+		errObj, ok := result.(*object.Error)
+		assert.True(t, ok, "Expected error for input: %s", tt.input)
+		assert.Equal(t, tt.expectedMessage, errObj.Message, "Input: %s", tt.input)
+	}
+}
+
+func TestWrangleBuiltinModuleWithAliasAndExpose(t *testing.T) {
+	result := testEval(`
+wrangle io as out expose preach
+out.input
+`)
+	_, ok := result.(*object.Builtin)
+	assert.True(t, ok, "alias should give access to all module members, got %v", result)
+
+	result = testEval(`
+wrangle io as out expose preach
+preach
+`)
+	_, ok = result.(*object.Builtin)
+	assert.True(t, ok, "exposed member should be bound directly, got %v", result)
+}
+
+func TestWranglePastureModulesShareANamespace(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "pathing", `
+pasture mygame.ai
+wrangle math
+praise route(n):
+   serve n * 2
+beef
+prep _cache = 0
+`)
+	writeModule(t, dir, "targeting", `
+pasture mygame.ai
+prep range = 5
+`)
+	writeModule(t, dir, "scores", `
+pasture mygame
+prep best = 100
+`)
+	withSearchPath(t, dir)
+
+	result := testEval(`
+wrangle pathing
+wrangle targeting
+wrangle scores
+mygame.ai.route(mygame.ai.range) + mygame.best
+`)
+	integer, ok := result.(*object.Integer)
+	assert.True(t, ok, "Result should be an Integer, got %v", result)
+	assert.Equal(t, int64(110), integer.Value)
+
+	// The module name isn't bound
+	result = testEval("wrangle pathing\npathing")
+	_, ok = result.(*object.Error)
+	assert.True(t, ok, "module name should not be bound, got %v", result)
+
+	// Private members and the modules the file wrangled stay in the module
+	for _, member := range []string{"_cache", "math"} {
+		result = testEval("wrangle pathing\nmygame.ai." + member)
+		assert.Equal(t, object.NULL, result, member)
+	}
+
+	// An alias gets the module itself
+	result = testEval(`
+wrangle pathing as p
+p.route(4)
+`)
+	integer, ok = result.(*object.Integer)
+	assert.True(t, ok, "Result should be an Integer, got %v", result)
+	assert.Equal(t, int64(8), integer.Value)
+
+	result = testEval("wrangle pathing\nmygame.ai")
+	assert.Equal(t, "<namespace 'mygame.ai'>", result.Inspect())
+}
+
+func TestWranglePastureConflicts(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "pathing", "pasture mygame.ai\nprep speed = 1")
+	writeModule(t, dir, "steering", "pasture mygame.ai\nprep speed = 2")
+	writeModule(t, dir, "ai", "pasture mygame\nprep ai = 3")
+	withSearchPath(t, dir)
+
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"wrangle pathing\nwrangle steering", "can't put module steering in pasture mygame.ai: it already has a member 'speed'"},
+		{"prep mygame = 1\nwrangle pathing", "can't put module pathing in pasture mygame.ai: mygame is already taken by a value of type INTEGER"},
+		{"wrangle ai\nwrangle pathing", "can't put module pathing in pasture mygame.ai: mygame.ai is already taken by a value of type INTEGER"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %v", tt.input, result)
+		}
+		assert.Equal(t, diagnostics.CodePastureConflict, errObj.Code)
+		assert.Equal(t, tt.expectedMessage, errObj.Message, "Input: %s", tt.input)
+	}
+
+	// Wrangling the same module twice is fine
+	result := testEval("wrangle pathing\nwrangle pathing\nmygame.ai.speed")
+	assert.Equal(t, "1", result.Inspect())
+}
+
+func TestExposeReExportsWrangledModules(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "std", `
+wrangle math
+wrangle array
+wrangle vec expose add
+expose math, array
+prep _private = 1
+`)
+	writeModule(t, dir, "kitchen", `
+praise _season(x):
+   serve x
+beef
+prep cuts = 3
+`)
+	writeModule(t, dir, "menu", `
+wrangle kitchen
+expose kitchen
+`)
+	withSearchPath(t, dir)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"wrangle std\nstd.clamp(15, 0, 10)", "10"},
+		{"wrangle std\nstd.sort([3, 1, 2])", "[1, 2, 3]"},
+		{"wrangle std\nwrangle vec\nstd.add(vec.new(1, 2), vec.new(1, 2))", "vec(2, 4)"},
+		{"wrangle std\nstd.math", "<module 'math'>"},
+		{"wrangle std expose clamp, sort\nsort([clamp(9, 0, 5), 1])", "[1, 5]"},
+		{"wrangle menu\nmenu.cuts", "3"},
+		{"wrangle menu\nmenu._season", "member '_season' is private to module 'menu'"},
+	}
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		if errObj, ok := result.(*object.Error); ok {
+			assert.Equal(t, tt.expected, errObj.Message, tt.input)
+			continue
+		}
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestExposeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		code     string
+		expected string
+	}{
+		{"wrangle math\nwrangle vec\nexpose math\nexpose vec", diagnostics.CodeExposeConflict, "can't expose vec: 'lerp' is already defined here"},
+		{"wrangle math\npraise clamp(x):\n   serve x\nbeef\nexpose math", diagnostics.CodeExposeConflict, "can't expose math: 'clamp' is already defined here"},
+		{"prep speed = 1\nexpose speed", diagnostics.CodeTypeMismatch, "expose needs a wrangled module, but speed is a INTEGER"},
+		{"expose math", diagnostics.CodeIdentifierNotFound, "identifier not found: math"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %v", tt.input, result)
+		}
+		assert.Equal(t, tt.code, errObj.Code, tt.input)
+		assert.Contains(t, errObj.Message, tt.expected, tt.input)
+	}
+
+	// Exposing a module twice binds the same values again
+	result := testEval("wrangle math\nexpose math\nexpose math\nclamp(-1, 0, 5)")
+	assert.Equal(t, "0", result.Inspect())
+}
+
+func TestWrangledModuleKeepsDocComments(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "butcher", `# Cuts and portions.
+
+# double doubles a portion.
+praise double(x):
+   serve x * 2
+beef
+`)
+	withSearchPath(t, dir)
+
+	result := testEval(`
+wrangle butcher
+butcher
+`)
+	mod, ok := result.(*object.Module)
+	if assert.True(t, ok, "Result should be a Module, got %v", result) {
+		assert.Equal(t, "Cuts and portions.", mod.Doc)
+		fn, ok := mod.Members["double"].(*object.Function)
+		assert.True(t, ok)
+		assert.Equal(t, "double doubles a portion.", fn.Doc)
+	}
+}
+
+func TestWrangledModulesAreParsedWithConditions(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir, "settings", "#when debug\nprep level = \"debug\"\n#else\nprep level = \"release\"\n#end\n")
+	withSearchPath(t, dir)
+
+	assert.Equal(t, "release", testEval("wrangle settings\nsettings.level").Inspect())
+
+	Default.Conditions["debug"] = "true"
+	defer delete(Default.Conditions, "debug")
+	Default.ResetModuleCache()
+	assert.Equal(t, "debug", testEval("wrangle settings\nsettings.level").Inspect())
+}
@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+func createNetModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "net",
+		Members: make(map[string]object.Object),
+	}
+
+	// dial - open a TCP connection:
+	//   prep conn = net.dial("localhost", 7777)
+	//   conn.write("ping")
+	//   io.preach(conn.read())
+	mod.Set("dial", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkCapability(rt.Allowed.Network, capNetwork, "net.dial"); err != nil {
+				return err
+			}
+			if err := object.CheckArgCount("net.dial", args, 2); err != nil {
+				return err
+			}
+			host, err := object.StringArg("net.dial", args[0])
+			if err != nil {
+				return err
+			}
+			port, err := portArg("net.dial", args[1])
+			if err != nil {
+				return err
+			}
+			conn, dialErr := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+			if dialErr != nil {
+				return &object.Error{Code: diagnostics.CodeNetworkError, Message: dialErr.Error()}
+			}
+			return object.NewConnection(conn)
+		},
+	})
+
+	// listen - accept TCP connections on a port, on every network interface.
+	// Port 0 picks a free port; the listener's port() says which.
+	//   prep server = net.listen(7777)
+	//   feast while true:
+	//     stampede handle(server.accept())
+	//   beef
+	mod.Set("listen", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkCapability(rt.Allowed.Network, capNetwork, "net.listen"); err != nil {
+				return err
+			}
+			if err := object.CheckArgCount("net.listen", args, 1); err != nil {
+				return err
+			}
+			port, err := portArg("net.listen", args[0])
+			if err != nil {
+				return err
+			}
+			listener, listenErr := net.Listen("tcp", ":"+strconv.Itoa(port))
+			if listenErr != nil {
+				return &object.Error{Code: diagnostics.CodeNetworkError, Message: listenErr.Error()}
+			}
+			return object.NewListener(listener)
+		},
+	})
+
+	return mod
+}
+
+// portArg returns the value of an argument that must be a port number.
+func portArg(name string, arg object.Object) (int, *object.Error) {
+	port, err := object.IntegerArg(name, arg)
+	if err != nil {
+		return 0, err
+	}
+	if port < 0 || port > 65535 {
+		return 0, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: port must be between 0 and 65535, got %d", name, port)}
+	}
+	return int(port), nil
+}
@@ -0,0 +1,68 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// allowNetwork turns on the net and http modules for the rest of the test
+func allowNetwork(t *testing.T) {
+	allow(t, Capabilities{Network: true})
+}
+
+func TestNetEchoServer(t *testing.T) {
+	allowNetwork(t)
+	input := `wrangle net
+
+praise serve_one(server):
+   prep conn = server.accept()
+   conn.write("you said: " + conn.read_line() + "
+")
+   conn.close()
+beef
+
+praise talk():
+   prep server = net.listen(0)
+   stampede serve_one(server)
+
+   prep conn = net.dial("127.0.0.1", server.port())
+   conn.write("brisket
+")
+   prep reply = conn.read_line()
+   prep after = conn.read_line()
+   conn.close()
+   server.close()
+   serve [reply, after]
+beef
+
+talk()`
+
+	// read_line returns null once the server has hung up
+	assert.Equal(t, `["you said: brisket", null]`, testEval(input).Inspect())
+}
+
+func TestNetErrors(t *testing.T) {
+	allowNetwork(t)
+	tests := []struct {
+		input        string
+		expectedCode string
+	}{
+		{`net.dial("127.0.0.1")`, diagnostics.CodeBadArgument},
+		{`net.dial(1, 2)`, diagnostics.CodeBadArgument},
+		{`net.dial("127.0.0.1", 70000)`, diagnostics.CodeBadArgument},
+		{`net.listen(-1)`, diagnostics.CodeBadArgument},
+		// Nothing listens on port 1 in the test environment
+		{`net.dial("127.0.0.1", 1)`, diagnostics.CodeNetworkError},
+		{"prep s = net.listen(0)\ns.close()\ns.accept()", diagnostics.CodeNetworkError},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval("wrangle net\n" + tt.input).(*object.Error)
+		if assert.True(t, ok, "expected an error for %q", tt.input) {
+			assert.Equal(t, tt.expectedCode, errObj.Code, tt.input)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package evaluator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// exitPrefix starts the message of the error os.exit unwinds the program with.
+const exitPrefix = "exit status "
+
+func createOSModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "os",
+		Members: make(map[string]object.Object),
+	}
+
+	// args - the script's path followed by its command-line arguments:
+	//   beeflang smoker.beef --temp 225  →  ["smoker.beef", "--temp", "225"]
+	mod.Set("args", stringArray(rt.Args))
+
+	// exit - stop the program with an exit code. The program unwinds as it
+	// would for an error, so 'dessert' calls and 'using' blocks still run.
+	mod.Set("exit", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("os.exit", args, 1); err != nil {
+				return err
+			}
+			code, err := object.IntegerArg("os.exit", args[0])
+			if err != nil {
+				return err
+			}
+			return exitError(int(code))
+		},
+	})
+
+	// getenv - the value of an environment variable, or null if it isn't set.
+	// Needs --allow-env: the environment often holds secrets.
+	mod.Set("getenv", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkCapability(rt.Allowed.Env, capEnv, "os.getenv"); err != nil {
+				return err
+			}
+			if err := object.CheckArgCount("os.getenv", args, 1); err != nil {
+				return err
+			}
+			name, err := object.StringArg("os.getenv", args[0])
+			if err != nil {
+				return err
+			}
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				return object.NULL
+			}
+			return &object.String{Value: value}
+		},
+	})
+
+	// expand - a string with each ${NAME} replaced by that environment
+	// variable, for paths that differ between machines:
+	//   fs.read(os.expand("${GAME_CONTENT}/enemies.json"))
+	// A variable that isn't set is an error. Needs --allow-env.
+	mod.Set("expand", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkCapability(rt.Allowed.Env, capEnv, "os.expand"); err != nil {
+				return err
+			}
+			if err := object.CheckArgCount("os.expand", args, 1); err != nil {
+				return err
+			}
+			s, err := object.StringArg("os.expand", args[0])
+			if err != nil {
+				return err
+			}
+			expanded, expandErr := expandVars(s)
+			if expandErr != nil {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("os.expand: %v", expandErr)}
+			}
+			return &object.String{Value: expanded}
+		},
+	})
+
+	return mod
+}
+
+// expandVars replaces each ${NAME} in s with the value of the environment
+// variable NAME. Other dollar signs are left alone, so only the braced form
+// is special. It fails on a variable that isn't set rather than leave a
+// hole in a path.
+func expandVars(s string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("%q has a ${ with no }", s)
+		}
+		name := s[start+2 : start+end]
+		if !isEnvName(name) {
+			return "", fmt.Errorf("%q is not an environment variable name", name)
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		b.WriteString(s[:start])
+		b.WriteString(value)
+		s = s[start+end+1:]
+	}
+}
+
+// isEnvName reports whether name is a letter or underscore followed by
+// letters, digits and underscores.
+func isEnvName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, c := range name {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// exitError is the error that ends a program with code, see ExitCode.
+func exitError(code int) *object.Error {
+	return &object.Error{Code: diagnostics.CodeExit, Message: fmt.Sprintf("%s%d", exitPrefix, code)}
+}
+
+// ExitCode reports whether err is the program calling os.exit, and with
+// which code. Hosts should exit with it instead of reporting an error.
+func ExitCode(err *object.Error) (int, bool) {
+	if err.Code != diagnostics.CodeExit {
+		return 0, false
+	}
+	code, convErr := strconv.Atoi(strings.TrimPrefix(err.Message, exitPrefix))
+	if convErr != nil {
+		return 1, true
+	}
+	return code, true
+}
+
+// stringArray makes an array of strings.
+func stringArray(values []string) *object.Array {
+	elements := make([]object.Object, len(values))
+	for i, v := range values {
+		elements[i] = &object.String{Value: v}
+	}
+	return object.NewArray(elements)
+}
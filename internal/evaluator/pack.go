@@ -0,0 +1,362 @@
+package evaluator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"unicode/utf8"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// A pack format lists the fields of a binary record, like Python's struct
+// module: an optional byte order, < for little-endian (the default) or > or !
+// for big-endian, then one letter per field, each optionally after a count:
+//
+//	b B   8-bit integer, signed and unsigned
+//	h H   16-bit integer
+//	i I   32-bit integer
+//	q Q   64-bit integer (Q values past the largest integer are big integers)
+//	f d   32-bit and 64-bit float, as an integer in thousandths
+//	s     string of the count's length in bytes, padded with zero bytes
+//	x     zero byte, with no value
+//
+// A count repeats a field - "3i" is three 32-bit integers - except for s,
+// where it is the string's length: "16s" is one string of 16 bytes. Spaces
+// are ignored.
+type packField struct {
+	code  byte
+	count int
+}
+
+// maxPackCount is the largest count a format can give a field.
+const maxPackCount = 1 << 24
+
+// packSizes is the size in bytes of each field code.
+var packSizes = map[byte]int{
+	'b': 1, 'B': 1, 'h': 2, 'H': 2, 'i': 4, 'I': 4, 'q': 8, 'Q': 8,
+	'f': 4, 'd': 8, 's': 1, 'x': 1,
+}
+
+// packFormat is a parsed format.
+type packFormat struct {
+	order interface {
+		binary.ByteOrder
+		binary.AppendByteOrder
+	}
+	fields []packField
+	size   int // in bytes
+	values int // how many values the fields hold
+}
+
+func parsePackFormat(name, format string) (*packFormat, *object.Error) {
+	f := &packFormat{order: binary.LittleEndian}
+	rest := format
+	if rest != "" {
+		switch rest[0] {
+		case '<':
+			rest = rest[1:]
+		case '>', '!':
+			f.order = binary.BigEndian
+			rest = rest[1:]
+		}
+	}
+	for i := 0; i < len(rest); i++ {
+		c := rest[i]
+		if c == ' ' {
+			continue
+		}
+		count, digits := 0, false
+		for ; i < len(rest) && rest[i] >= '0' && rest[i] <= '9'; i++ {
+			count = count*10 + int(rest[i]-'0')
+			digits = true
+			if count > maxPackCount {
+				return nil, packFormatError(name, format, "count too large")
+			}
+		}
+		if i == len(rest) {
+			return nil, packFormatError(name, format, "count with no field after it")
+		}
+		c = rest[i]
+		size, ok := packSizes[c]
+		if !ok {
+			return nil, packFormatError(name, format, fmt.Sprintf("unknown field %q", c))
+		}
+		if !digits {
+			count = 1
+		}
+		f.fields = append(f.fields, packField{code: c, count: count})
+		f.size += size * count
+		switch c {
+		case 'x':
+		case 's':
+			f.values++
+		default:
+			f.values += count
+		}
+	}
+	return f, nil
+}
+
+func packFormatError(name, format, problem string) *object.Error {
+	return &object.Error{Code: diagnostics.CodeBadArgument, Message: fmt.Sprintf("%s: bad format %q: %s", name, format, problem)}
+}
+
+// packLimits are the smallest and largest values of the integer fields.
+var packLimits = map[byte][2]*big.Int{
+	'b': {big.NewInt(math.MinInt8), big.NewInt(math.MaxInt8)},
+	'B': {big.NewInt(0), big.NewInt(math.MaxUint8)},
+	'h': {big.NewInt(math.MinInt16), big.NewInt(math.MaxInt16)},
+	'H': {big.NewInt(0), big.NewInt(math.MaxUint16)},
+	'i': {big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)},
+	'I': {big.NewInt(0), big.NewInt(math.MaxUint32)},
+	'q': {big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)},
+	'Q': {big.NewInt(0), new(big.Int).SetUint64(math.MaxUint64)},
+}
+
+func createPackModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "pack",
+		Members: make(map[string]object.Object),
+	}
+
+	// encode - bytes holding an array of values laid out by a format (see
+	// above), one value per field:
+	//   fs.write_bytes("save.dat", pack.encode("<4s H i", ["BEEF", 3, score]))
+	mod.Set("encode", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("pack.encode", args, 2); err != nil {
+				return err
+			}
+			format, err := object.StringArg("pack.encode", args[0])
+			if err != nil {
+				return err
+			}
+			f, err := parsePackFormat("pack.encode", format)
+			if err != nil {
+				return err
+			}
+			arr, ok := args[1].(*object.Array)
+			if !ok {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("pack.encode: expected an ARRAY, got %s", args[1].Type())}
+			}
+			elements := arr.Elements()
+			if len(elements) != f.values {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("pack.encode: format %q takes %d values, got %d", format, f.values, len(elements))}
+			}
+			return f.encode(elements)
+		},
+	})
+
+	// decode - the values in bytes laid out by a format, as an array. The
+	// format is read from the start of the bytes, or from an offset, and
+	// anything after it is ignored:
+	//   prep header = pack.decode("<4s H i", save)    # ["BEEF", 3, 1200]
+	mod.Set("decode", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("pack.decode takes 2 or 3 arguments, got %d", len(args))}
+			}
+			format, err := object.StringArg("pack.decode", args[0])
+			if err != nil {
+				return err
+			}
+			f, err := parsePackFormat("pack.decode", format)
+			if err != nil {
+				return err
+			}
+			data, err := object.BytesArg("pack.decode", args[1])
+			if err != nil {
+				return err
+			}
+			var offset int64
+			if len(args) == 3 {
+				if offset, err = object.IntegerArg("pack.decode", args[2]); err != nil {
+					return err
+				}
+			}
+			if offset < 0 || offset > int64(len(data)) || int64(len(data))-offset < int64(f.size) {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("pack.decode: format %q needs %d bytes at offset %d, but there are %d bytes", format, f.size, offset, len(data))}
+			}
+			return f.decode(data[offset:])
+		},
+	})
+
+	// size - how many bytes a format takes, to step through records:
+	//   offset = offset + pack.size("<hh")
+	mod.Set("size", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("pack.size", args, 1); err != nil {
+				return err
+			}
+			format, err := object.StringArg("pack.size", args[0])
+			if err != nil {
+				return err
+			}
+			f, err := parsePackFormat("pack.size", format)
+			if err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(f.size)}
+		},
+	})
+
+	return mod
+}
+
+func (f *packFormat) encode(values []object.Object) object.Object {
+	data := make([]byte, 0, f.size)
+	for _, field := range f.fields {
+		switch field.code {
+		case 'x':
+			data = append(data, make([]byte, field.count)...)
+			continue
+		case 's':
+			s, err := object.StringArg("pack.encode", values[0])
+			if err != nil {
+				return err
+			}
+			values = values[1:]
+			if len(s) > field.count {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("pack.encode: %q is longer than %d bytes", s, field.count)}
+			}
+			data = append(data, s...)
+			data = append(data, make([]byte, field.count-len(s))...)
+			continue
+		}
+		for range field.count {
+			var err *object.Error
+			if data, err = f.appendValue(data, field.code, values[0]); err != nil {
+				return err
+			}
+			values = values[1:]
+		}
+	}
+	return &object.Bytes{Value: data}
+}
+
+// appendValue appends one integer or float field to data.
+func (f *packFormat) appendValue(data []byte, code byte, value object.Object) ([]byte, *object.Error) {
+	if !isInteger(value) {
+		return nil, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("pack.encode: expected an INTEGER for '%c', got %s", code, value.Type())}
+	}
+	n := bigValue(value)
+
+	switch code {
+	case 'f', 'd':
+		thousandths, _ := new(big.Float).SetInt(n).Float64()
+		x := thousandths / fractionScale
+		if math.IsInf(x, 0) || (code == 'f' && math.Abs(x) > math.MaxFloat32) {
+			return nil, &object.Error{Code: diagnostics.CodeBadArgument,
+				Message: fmt.Sprintf("pack.encode: %s thousandths is out of range for '%c'", n, code)}
+		}
+		if code == 'f' {
+			return f.order.AppendUint32(data, math.Float32bits(float32(x))), nil
+		}
+		return f.order.AppendUint64(data, math.Float64bits(x)), nil
+	}
+
+	limits := packLimits[code]
+	if n.Cmp(limits[0]) < 0 || n.Cmp(limits[1]) > 0 {
+		return nil, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("pack.encode: %s is out of range for '%c' (%s to %s)", n, code, limits[0], limits[1])}
+	}
+	// Two's complement: a negative value's low bits are those of the
+	// unsigned value 2^64 above it
+	bits := n.Uint64()
+	if n.Sign() < 0 {
+		bits = uint64(n.Int64())
+	}
+	switch packSizes[code] {
+	case 1:
+		return append(data, byte(bits)), nil
+	case 2:
+		return f.order.AppendUint16(data, uint16(bits)), nil
+	case 4:
+		return f.order.AppendUint32(data, uint32(bits)), nil
+	default:
+		return f.order.AppendUint64(data, bits), nil
+	}
+}
+
+func (f *packFormat) decode(data []byte) object.Object {
+	values := make([]object.Object, 0, f.values)
+	pos := 0
+	for _, field := range f.fields {
+		switch field.code {
+		case 'x':
+			pos += field.count
+			continue
+		case 's':
+			raw := data[pos : pos+field.count]
+			pos += field.count
+			end := len(raw)
+			for end > 0 && raw[end-1] == 0 {
+				end--
+			}
+			if !utf8.Valid(raw[:end]) {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("pack.decode: the string at byte %d isn't UTF-8 text", pos-field.count)}
+			}
+			values = append(values, &object.String{Value: string(raw[:end])})
+			continue
+		}
+		for range field.count {
+			value, err := f.readValue(data[pos:], field.code, pos)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+			pos += packSizes[field.code]
+		}
+	}
+	return object.NewArray(values)
+}
+
+// readValue reads one integer or float field from the start of data, which
+// is at pos in the bytes being decoded.
+func (f *packFormat) readValue(data []byte, code byte, pos int) (object.Object, *object.Error) {
+	switch code {
+	case 'b':
+		return &object.Integer{Value: int64(int8(data[0]))}, nil
+	case 'B':
+		return &object.Integer{Value: int64(data[0])}, nil
+	case 'h':
+		return &object.Integer{Value: int64(int16(f.order.Uint16(data)))}, nil
+	case 'H':
+		return &object.Integer{Value: int64(f.order.Uint16(data))}, nil
+	case 'i':
+		return &object.Integer{Value: int64(int32(f.order.Uint32(data)))}, nil
+	case 'I':
+		return &object.Integer{Value: int64(f.order.Uint32(data))}, nil
+	case 'q':
+		return &object.Integer{Value: int64(f.order.Uint64(data))}, nil
+	case 'Q':
+		n := f.order.Uint64(data)
+		if n > math.MaxInt64 {
+			return &object.BigInteger{Value: new(big.Int).SetUint64(n)}, nil
+		}
+		return &object.Integer{Value: int64(n)}, nil
+	}
+
+	var x float64
+	if code == 'f' {
+		x = float64(math.Float32frombits(f.order.Uint32(data)))
+	} else {
+		x = math.Float64frombits(f.order.Uint64(data))
+	}
+	thousandths := math.Round(x * fractionScale)
+	if math.IsNaN(thousandths) || thousandths >= math.MaxInt64 || thousandths < math.MinInt64 {
+		return nil, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("pack.decode: the float at byte %d is %v, which has no integer value in thousandths", pos, x)}
+	}
+	return &object.Integer{Value: int64(thousandths)}, nil
+}
@@ -0,0 +1,76 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPack(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`pack.encode("<hI", [-2, 1])`, "<bytes fe ff 01 00 00 00>"},
+		{`pack.encode(">hI", [-2, 1])`, "<bytes ff fe 00 00 00 01>"},
+		{`pack.encode("!H", [258])`, "<bytes 01 02>"},
+		{`pack.encode("bB", [-1, 255])`, "<bytes ff ff>"},
+		{`pack.encode("3B 2x", [1, 2, 3])`, "<bytes 01 02 03 00 00>"},
+		{`pack.encode("4s", ["ab"])`, "<bytes 61 62 00 00>"},
+		{`pack.encode("q", [-1])`, "<bytes ff ff ff ff ff ff ff ff>"},
+		{`pack.encode("Q", [18446744073709551615n])`, "<bytes ff ff ff ff ff ff ff ff>"},
+		// Floats are given and read in thousandths
+		{`pack.encode("<f", [1500])`, "<bytes 00 00 c0 3f>"},
+		{`pack.encode(">d", [-250])`, "<bytes bf d0 00 00 00 00 00 00>"},
+		{`pack.decode("<f", bytes.from_hex("0000c03f"))`, "[1500]"},
+		{`pack.decode("<f", bytes.from_hex("cdcc8c3f"))`, "[1100]"},
+		{`pack.decode("<hI", bytes.from_hex("feff01000000"))`, "[-2, 1]"},
+		{`pack.decode(">i", bytes.from_hex("ffffff85"))`, "[-123]"},
+		{`pack.decode("Q", bytes.from_hex("ffffffffffffffff"))`, "[18446744073709551615]"},
+		{`pack.decode("Q", bytes.from_hex("ffffffffffffffff"))[0] == 18446744073709551615n`, "true"},
+		{`pack.decode("8s", bytes.from_hex("4245454600000000"))`, `["BEEF"]`},
+		// Everything after the format is ignored, and reading can start at an offset
+		{`pack.decode("2x B", bytes.from_array([9, 9, 7, 1, 2]))`, "[7]"},
+		{`pack.decode("B", bytes.from_array([9, 8, 7]), 2)`, "[7]"},
+		{`pack.decode("", bytes.from_array([]))`, "[]"},
+		{`pack.size("<4s H i 2d")`, "26"},
+		{`prep record = ["BEEF", 3, -1200, 70000, 5]
+pack.decode("<4s H i d 2x b", pack.encode("<4s H i d 2x b", record))`, `["BEEF", 3, -1200, 70000, 5]`},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle pack\nwrangle bytes\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestPackErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`pack.encode("<z", [1])`, `pack.encode: bad format "<z": unknown field 'z'`},
+		{`pack.size("3")`, `pack.size: bad format "3": count with no field after it`},
+		{`pack.encode("hh", [1])`, `pack.encode: format "hh" takes 2 values, got 1`},
+		{`pack.encode("h", 1)`, "pack.encode: expected an ARRAY, got INTEGER"},
+		{`pack.encode("B", [256])`, "pack.encode: 256 is out of range for 'B' (0 to 255)"},
+		{`pack.encode("I", [-1])`, "pack.encode: -1 is out of range for 'I' (0 to 4294967295)"},
+		{`pack.encode("h", ["1"])`, "pack.encode: expected an INTEGER for 'h', got STRING"},
+		{`pack.encode("2s", ["abc"])`, `pack.encode: "abc" is longer than 2 bytes`},
+		{`pack.encode("2s", [1])`, "pack.encode: expected a STRING, got INTEGER"},
+		{`pack.decode("I", bytes.from_array([1, 2]))`, `pack.decode: format "I" needs 4 bytes at offset 0, but there are 2 bytes`},
+		{`pack.decode("B", bytes.from_array([1, 2]), 5)`, `pack.decode: format "B" needs 1 bytes at offset 5, but there are 2 bytes`},
+		{`pack.decode("<f", bytes.from_hex("0000c07f"))`, "pack.decode: the float at byte 0 is NaN, which has no integer value in thousandths"},
+		{`pack.decode("2s", bytes.from_array([255, 0]))`, "pack.decode: the string at byte 0 isn't UTF-8 text"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle pack\nwrangle bytes\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
@@ -0,0 +1,112 @@
+package evaluator
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+func createParallelModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "parallel",
+		Members: make(map[string]object.Object),
+	}
+
+	// map - call a function on every element of an array at once, spread
+	// over the CPUs, and collect what it serves (needs --allow-parallel):
+	//   prep sprites = parallel.map(entries, convert_entry)
+	// The results are in the order of the elements, however the calls were
+	// scheduled. Each call runs on its own copy of the function's variables,
+	// arrays and hashes included, with its own copy of the element, so calls
+	// can't see each other's changes and none reach the caller. If calls
+	// fail, the error of the first failing element is returned; elements
+	// after it that haven't started yet never are.
+	mod.Set("map", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkCapability(rt.Allowed.Parallel, capParallel, "parallel.map"); err != nil {
+				return err
+			}
+			if err := object.CheckArgCount("parallel.map", args, 2); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("parallel.map: expected an ARRAY, got %s", args[0].Type())}
+			}
+			switch fn := args[1].(type) {
+			case *object.Function:
+				if len(fn.Parameters) != 1 {
+					return &object.Error{Code: diagnostics.CodeBadArgument,
+						Message: fmt.Sprintf("parallel.map: expected a function of 1 parameter, got one of %d", len(fn.Parameters))}
+				}
+			case *object.Builtin:
+			default:
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("parallel.map: expected a function, got %s", args[1].Type())}
+			}
+			return parallelMap(arr.Elements(), args[1])
+		},
+	})
+
+	return mod
+}
+
+// parallelMap calls fn on each element on up to GOMAXPROCS goroutines, which
+// take the elements in order. Once a call fails no later element is started,
+// so every element before the first failure has run and which error is
+// returned doesn't depend on scheduling.
+func parallelMap(elements []object.Object, fn object.Object) object.Object {
+	results := make([]object.Object, len(elements))
+	var next atomic.Int64
+	var failed atomic.Int64 // the lowest index whose call failed
+	failed.Store(int64(len(elements)))
+
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(elements)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := next.Add(1) - 1
+				if i >= int64(len(elements)) || i > failed.Load() {
+					return
+				}
+				results[i] = applyFunction(token.Token{}, isolatedFunction(fn), []object.Object{object.Clone(elements[i])})
+				if isError(results[i]) {
+					for {
+						lowest := failed.Load()
+						if i >= lowest || failed.CompareAndSwap(lowest, i) {
+							break
+						}
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if i := failed.Load(); i < int64(len(elements)) {
+		return results[i]
+	}
+	return object.NewArray(results)
+}
+
+// isolatedFunction returns a copy of a user function running on a clone of
+// its environment, so what one call assigns or pushes no other call sees.
+// Channels, counters and modules are still shared, as Clone shares them.
+// Builtins are returned as they are.
+func isolatedFunction(fn object.Object) object.Object {
+	f, ok := fn.(*object.Function)
+	if !ok {
+		return fn
+	}
+	isolated := *f
+	isolated.Env = f.Env.Clone()
+	return &isolated
+}
@@ -0,0 +1,100 @@
+package evaluator
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelMap(t *testing.T) {
+	allow(t, Capabilities{Parallel: true})
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`praise square(n):
+  serve n * n
+beef
+parallel.map([1, 2, 3, 4, 5], square)`, "[1, 4, 9, 16, 25]"},
+		{"parallel.map([], array.sort)", "[]"},
+		{"parallel.map([[3, 1], [2, 1]], array.sort)", "[[1, 3], [1, 2]]"},
+		// Calls change their own copy of the variables, and their own copy
+		// of the element
+		{`prep total = 0
+praise add(entry):
+  total = total + entry[0]
+  array.push(entry, total)
+  serve total
+beef
+prep entries = [[1], [2], [3]]
+[parallel.map(entries, add), total, entries]`, "[[1, 2, 3], 0, [[1], [2], [3]]]"},
+		// Arrays the function reaches through its variables are copied too
+		{`prep seen = []
+praise note(n):
+  array.push(seen, n)
+  serve seen
+beef
+[parallel.map([1, 2, 3], note), seen]`, "[[[1], [2], [3]], []]"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle parallel\nwrangle array\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestParallelMapKeepsOrder(t *testing.T) {
+	allow(t, Capabilities{Parallel: true})
+	numbers := make([]string, 1000)
+	doubled := make([]string, len(numbers))
+	for i := range numbers {
+		numbers[i] = strconv.Itoa(i)
+		doubled[i] = strconv.Itoa(i * 2)
+	}
+
+	result := testEval(`wrangle parallel
+praise double(n):
+  serve n * 2
+beef
+parallel.map([` + strings.Join(numbers, ", ") + `], double)`)
+	assert.Equal(t, "["+strings.Join(doubled, ", ")+"]", result.Inspect())
+}
+
+func TestParallelMapErrors(t *testing.T) {
+	allow(t, Capabilities{Parallel: true})
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"parallel.map(1, array.sort)", "parallel.map: expected an ARRAY, got INTEGER"},
+		{"parallel.map([1], 1)", "parallel.map: expected a function, got INTEGER"},
+		{"parallel.map([1])", "parallel.map takes 2 arguments, got 1"},
+		{`praise add(a, b):
+  serve a + b
+beef
+parallel.map([1], add)`, "parallel.map: expected a function of 1 parameter, got one of 2"},
+		// The first failing element's error, however the calls ran
+		{`praise check(n):
+  if n == 2:
+    serve missing_two
+  beef
+  if n == 3:
+    serve missing_three
+  beef
+  serve n
+beef
+parallel.map([1, 2, 3, 2, 3], check)`, "identifier not found: missing_two"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle parallel\nwrangle array\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Contains(t, errObj.Message, tt.expected, tt.input)
+	}
+}
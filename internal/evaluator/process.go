@@ -0,0 +1,124 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+func createProcessModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "process",
+		Members: make(map[string]object.Object),
+	}
+
+	// run - run a command and wait for it to finish:
+	//   prep result = process.run("git", ["status", "--short"])
+	// Returns a hash with "stdout", "stderr" and "code" (the exit code). A
+	// command that exits non-zero is not an error; one that can't be started is.
+	mod.Set("run", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkProcessAllowed(rt, "process.run"); err != nil {
+				return err
+			}
+			if len(args) != 1 && len(args) != 2 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("process.run takes 1 or 2 arguments, got %d", len(args))}
+			}
+			name, err := object.StringArg("process.run", args[0])
+			if err != nil {
+				return err
+			}
+			var cmdArgs []string
+			if len(args) == 2 {
+				cmdArgs, err = stringElements("process.run", args[1])
+				if err != nil {
+					return err
+				}
+			}
+			return runProcess(rt, name, cmdArgs)
+		},
+	})
+
+	// pid - the process id of the interpreter itself
+	mod.Set("pid", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkProcessAllowed(rt, "process.pid"); err != nil {
+				return err
+			}
+			if err := object.CheckArgCount("process.pid", args, 0); err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(os.Getpid())}
+		},
+	})
+
+	return mod
+}
+
+// checkProcessAllowed denies the process module unless rt.Allowed.Process is
+// set: a script that can start programs can do anything the user running it
+// can.
+func checkProcessAllowed(rt *Runtime, name string) *object.Error {
+	return checkCapability(rt.Allowed.Process, capProcess, name)
+}
+
+// runProcess runs a command to completion, killing it if the program is interrupted.
+func runProcess(rt *Runtime, name string, args []string) object.Object {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-rt.interruptChan():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if rt.Interrupted() {
+		return &object.Error{Code: diagnostics.CodeInterrupted, Message: "interrupted"}
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return &object.Error{Code: diagnostics.CodeProcessFailed,
+			Message: fmt.Sprintf("could not run %s: %v", name, err)}
+	}
+
+	result := object.NewHash()
+	result.Set(&object.String{Value: "stdout"}, &object.String{Value: stdout.String()})
+	result.Set(&object.String{Value: "stderr"}, &object.String{Value: stderr.String()})
+	result.Set(&object.String{Value: "code"}, &object.Integer{Value: int64(cmd.ProcessState.ExitCode())})
+	return result
+}
+
+// stringElements reads an argument that must be an array of strings.
+func stringElements(name string, arg object.Object) ([]string, *object.Error) {
+	array, ok := arg.(*object.Array)
+	if !ok {
+		return nil, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: expected an ARRAY, got %s", name, arg.Type())}
+	}
+	elements := array.Elements()
+	values := make([]string, len(elements))
+	for i, el := range elements {
+		s, ok := el.(*object.String)
+		if !ok {
+			return nil, &object.Error{Code: diagnostics.CodeBadArgument,
+				Message: fmt.Sprintf("%s: expected an array of STRINGs, element %d is %s", name, i, el.Type())}
+		}
+		values[i] = s.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,69 @@
+package evaluator
+
+import (
+	"os"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// allowProcess turns on the process module for the rest of the test
+func allowProcess(t *testing.T) {
+	allow(t, Capabilities{Process: true})
+}
+
+func TestProcessIsDeniedByDefault(t *testing.T) {
+	for _, input := range []string{`process.run("echo")`, "process.pid()"} {
+		errObj, ok := testEval("wrangle process\n" + input).(*object.Error)
+		if assert.True(t, ok, "expected an error for %q", input) {
+			assert.Equal(t, diagnostics.CodeCapabilityDenied, errObj.Code)
+			assert.Contains(t, errObj.Message, "--allow-process")
+		}
+	}
+}
+
+func TestProcessRun(t *testing.T) {
+	allowProcess(t)
+
+	result := testEval(`wrangle process
+process.run("sh", ["-c", "echo brisket; echo smoke >&2; exit 3"])`)
+
+	hash, ok := result.(*object.Hash)
+	if assert.True(t, ok, "expected *object.Hash, got %s", result.Inspect()) {
+		assert.Equal(t, `{"stdout": "brisket`+"\n"+`", "stderr": "smoke`+"\n"+`", "code": 3}`, hash.Inspect())
+	}
+
+	assert.Equal(t, "0", testEval("wrangle process\nprocess.run(\"true\").code").Inspect())
+}
+
+func TestProcessPid(t *testing.T) {
+	allowProcess(t)
+
+	result := testEval("wrangle process\nprocess.pid()")
+	assert.Equal(t, &object.Integer{Value: int64(os.Getpid())}, result)
+}
+
+func TestProcessErrors(t *testing.T) {
+	allowProcess(t)
+
+	tests := []struct {
+		input        string
+		expectedCode string
+	}{
+		{`process.run("beeflang-no-such-command")`, diagnostics.CodeProcessFailed},
+		{"process.run()", diagnostics.CodeBadArgument},
+		{"process.run(1)", diagnostics.CodeBadArgument},
+		{`process.run("echo", "hi")`, diagnostics.CodeBadArgument},
+		{`process.run("echo", ["hi", 2])`, diagnostics.CodeBadArgument},
+		{"process.pid(1)", diagnostics.CodeBadArgument},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval("wrangle process\n" + tt.input).(*object.Error)
+		if assert.True(t, ok, "expected an error for %q", tt.input) {
+			assert.Equal(t, tt.expectedCode, errObj.Code, tt.input)
+		}
+	}
+}
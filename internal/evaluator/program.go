@@ -0,0 +1,115 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// SourceFile is one parsed file of a program.
+type SourceFile struct {
+	Name    string // file name, as errors should show it
+	Program *ast.Program
+}
+
+// EvalFiles evaluates the top level of a program spread over several files,
+// one after another in a shared environment, as if they were one file. A
+// name declared at the top level of more than one file is an error, reported
+// before anything runs. Functions are hoisted across all the files. The result
+// is the last file's, or the first error.
+func EvalFiles(env *Environment, files []SourceFile) object.Object {
+	if err := checkDuplicateFiles(files); err != nil {
+		return err
+	}
+
+	// Hoist every file's functions first, so top-level code in one file can
+	// call a function declared in a file that comes after it
+	for _, file := range files {
+		hoistFunctions(file.Program, env)
+	}
+
+	var result object.Object = object.NULL
+	for _, file := range files {
+		result = evalFile(file.Name, file.Program, env)
+		if isError(result) {
+			return result
+		}
+	}
+	return result
+}
+
+// evalFile evaluates the top level of one file in env. Errors, and the
+// functions the file declares, are labeled with its name, so an error raised
+// in one of those functions later on still points into the right file.
+func evalFile(name string, program *ast.Program, env *Environment) object.Object {
+	// Label the functions before anything runs too, since tasks the file
+	// starts may report errors from them while the file is still running
+	hoistFunctions(program, env)
+	labelFunctions(name, program, env)
+	result := Eval(program, env)
+	if errObj, ok := result.(*object.Error); ok && errObj.File == "" {
+		errObj.File = name
+	}
+	labelFunctions(name, program, env)
+	return result
+}
+
+// labelFunctions records the file name in the functions a file declares.
+func labelFunctions(name string, program *ast.Program, env *Environment) {
+	for _, stmt := range program.Statements {
+		decl, ok := stmt.(*ast.FunctionDeclaration)
+		if !ok {
+			continue
+		}
+		if fn, ok := env.GetLocal(decl.Name.Value); ok {
+			if fn, ok := fn.(*object.Function); ok && fn.Body == decl.Body && fn.File == "" {
+				fn.File = name
+			}
+		}
+	}
+}
+
+// declaredName is a name declared at the top level of a file.
+type declaredName struct {
+	file  string
+	ident *ast.Identifier
+}
+
+// checkDuplicateFiles reports the first top-level name declared in more than
+// one file, at its second declaration.
+func checkDuplicateFiles(files []SourceFile) *object.Error {
+	seen := map[string]declaredName{}
+	for _, file := range files {
+		local := map[string]bool{}
+		for _, stmt := range file.Program.Statements {
+			var ident *ast.Identifier
+			switch s := stmt.(type) {
+			case *ast.FunctionDeclaration:
+				ident = s.Name
+			case *ast.VariableDeclaration:
+				ident = s.Name
+			default:
+				continue
+			}
+
+			if first, ok := seen[ident.Value]; ok && !local[ident.Value] {
+				err := newError(ident.Token, diagnostics.CodeDuplicateDeclaration,
+					"'%s' is already declared at %s", ident.Value, first)
+				err.File = file.Name
+				return err
+			}
+			if !local[ident.Value] {
+				seen[ident.Value] = declaredName{file: file.Name, ident: ident}
+				local[ident.Value] = true
+			}
+		}
+	}
+	return nil
+}
+
+// String describes where a name was declared, for messages.
+func (d declaredName) String() string {
+	return fmt.Sprintf("%s:%d:%d", d.file, d.ident.Token.Line, d.ident.Token.Column)
+}
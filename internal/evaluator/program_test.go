@@ -0,0 +1,85 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// sourceFile parses one file of a multi-file program
+func sourceFile(t *testing.T, name, input string) SourceFile {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	assert.Empty(t, p.Errors(), "parser errors in %s", name)
+	return SourceFile{Name: name, Program: program}
+}
+
+func TestEvalFilesSharesOneEnvironment(t *testing.T) {
+	env := NewEnvironment()
+	result := EvalFiles(env, []SourceFile{
+		sourceFile(t, "main.beef", "prep greeting = \"Howdy\"\npraise ChurchOfBeef():\n   serve greet(\"Bubba\")\nbeef"),
+		sourceFile(t, "greet.beef", "praise greet(name):\n   serve greeting + \", \" + name\nbeef"),
+	})
+	assert.False(t, isError(result), result.Inspect())
+
+	assert.Equal(t, "Howdy, Bubba", CallEntryPoint(env).Inspect())
+}
+
+func TestEvalFilesHoistsAcrossFiles(t *testing.T) {
+	env := NewEnvironment()
+	result := EvalFiles(env, []SourceFile{
+		sourceFile(t, "main.beef", "prep answer = later()"),
+		sourceFile(t, "later.beef", "praise later():\n   serve 42\nbeef"),
+	})
+	assert.False(t, isError(result), result.Inspect())
+
+	answer, _ := env.Get("answer")
+	assert.Equal(t, "42", answer.Inspect())
+}
+
+func TestErrorsPointIntoTheFileTheyCameFrom(t *testing.T) {
+	env := NewEnvironment()
+	EvalFiles(env, []SourceFile{
+		sourceFile(t, "main.beef", "praise ChurchOfBeef():\n   broken()\nbeef"),
+		sourceFile(t, "broken.beef", "\npraise broken():\n   serve 1 + true\nbeef"),
+	})
+
+	errObj, ok := CallEntryPoint(env).(*object.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, "broken.beef", errObj.File)
+		assert.Equal(t, 3, errObj.Line)
+	}
+
+	// Errors in a file's top level too
+	errObj, ok = EvalFiles(NewEnvironment(), []SourceFile{
+		sourceFile(t, "ok.beef", "prep x = 1"),
+		sourceFile(t, "bad.beef", "prep y = x + nope"),
+	}).(*object.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, "bad.beef", errObj.File)
+	}
+}
+
+func TestEvalFilesRejectsDuplicateDeclarations(t *testing.T) {
+	env := NewEnvironment()
+	result := EvalFiles(env, []SourceFile{
+		sourceFile(t, "a.beef", "wrangle io\npraise helper():\nbeef\nio.preach(1)"),
+		sourceFile(t, "b.beef", "prep total = 1\n\nprep helper = 2"),
+	})
+
+	errObj, ok := result.(*object.Error)
+	if assert.True(t, ok, "expected an error, got %s", result.Inspect()) {
+		assert.Equal(t, diagnostics.CodeDuplicateDeclaration, errObj.Code)
+		assert.Equal(t, "'helper' is already declared at a.beef:2:8", errObj.Message)
+		assert.Equal(t, "b.beef", errObj.File)
+		assert.Equal(t, 3, errObj.Line)
+	}
+
+	// Nothing ran
+	_, ok = env.Get("total")
+	assert.False(t, ok)
+}
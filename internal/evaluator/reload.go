@@ -0,0 +1,64 @@
+package evaluator
+
+import (
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// Reload applies an edited version of a program to the environment it is
+// running in, without restarting it. Top-level functions are declared again
+// from the new source, so the next call to one runs the new code, and wrangle
+// statements run again, picking up modules dropped with ForgetModule. A
+// variable the environment already has keeps its value; only variables new
+// to the source are initialized. The program's other top-level statements
+// ran when it started and don't run again. Functions removed from the source
+// stay declared.
+func Reload(env *Environment, files []SourceFile) object.Object {
+	if err := checkDuplicateFiles(files); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		hoistFunctions(file.Program, env)
+	}
+	for _, file := range files {
+		if err := reloadFile(file, env); err != nil {
+			return err
+		}
+	}
+	return object.NULL
+}
+
+// reloadFile runs the wrangles, decorated function declarations and new
+// variable declarations of one file.
+func reloadFile(file SourceFile, env *Environment) *object.Error {
+	for _, stmt := range file.Program.Statements {
+		var result object.Object
+		switch stmt := stmt.(type) {
+		case *ast.WrangleStatement:
+			result = Eval(stmt, env)
+		case *ast.FunctionDeclaration:
+			// Decorated functions aren't hoisted; they're declared in order,
+			// after the wrangles above them
+			if len(stmt.Decorators) == 0 {
+				continue
+			}
+			result = Eval(stmt, env)
+		case *ast.VariableDeclaration:
+			if _, ok := env.GetLocal(stmt.Name.Value); ok {
+				continue
+			}
+			result = Eval(stmt, env)
+		default:
+			continue
+		}
+		if errObj, ok := result.(*object.Error); ok {
+			if errObj.File == "" {
+				errObj.File = file.Name
+			}
+			return errObj
+		}
+	}
+	labelFunctions(file.Name, file.Program, env)
+	return nil
+}
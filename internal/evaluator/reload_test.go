@@ -0,0 +1,38 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadRebindsFunctionsAndKeepsState(t *testing.T) {
+	env := NewEnvironment()
+	result := EvalFiles(env, []SourceFile{sourceFile(t, "ai.beef",
+		"prep hp = 10\nprep turns = 0\nturns = 5\npraise decide():\n   serve \"attack\"\nbeef")})
+	assert.False(t, isError(result), result.Inspect())
+
+	result = Reload(env, []SourceFile{sourceFile(t, "ai.beef",
+		"prep hp = 99\nprep mood = \"calm\"\nprep turns = 0\npraise decide():\n   serve \"flee \" + mood\nbeef")})
+	assert.False(t, isError(result), result.Inspect())
+
+	hp, _ := env.Get("hp")
+	assert.Equal(t, "10", hp.Inspect(), "existing variables keep their values")
+	turns, _ := env.Get("turns")
+	assert.Equal(t, "5", turns.Inspect(), "top-level statements don't run again")
+	decide, _ := env.Get("decide")
+	assert.Equal(t, "flee calm", CallFunction(decide.(*object.Function)).Inspect())
+	assert.Equal(t, "ai.beef", decide.(*object.Function).File)
+}
+
+func TestReloadErrorsNameTheFile(t *testing.T) {
+	env := NewEnvironment()
+	EvalFiles(env, []SourceFile{sourceFile(t, "ai.beef", "prep hp = 10")})
+
+	errObj, ok := Reload(env, []SourceFile{sourceFile(t, "ai.beef", "prep hp = 10\nprep speed = hp + nope")}).(*object.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, "ai.beef", errObj.File)
+		assert.Equal(t, 2, errObj.Line)
+	}
+}
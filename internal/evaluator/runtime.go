@@ -0,0 +1,153 @@
+package evaluator
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// Runtime is what a program runs with: its standard streams and settings,
+// and the state it shares with the builtins it calls - the modules it has
+// wrangled, its stats, whether it has been interrupted. Every global
+// environment belongs to a Runtime (see NewEnvironment), and so do the scopes
+// it encloses and the modules its programs wrangle. Programs in different
+// Runtimes share nothing, so they can run at the same time on different
+// goroutines - one per NPC script, say. A Runtime's settings shouldn't change
+// while a program runs in it.
+type Runtime struct {
+	// The program's standard streams: io.preach and term write to Stdout,
+	// task errors and flag errors go to Stderr, and io.input and the
+	// keyboard read Stdin.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// ModuleFS, when set, holds the module files instead of the OS file
+	// system: SearchPath directories are then paths inside it. A bundled
+	// program (see the bundle command) runs with the files packed into its
+	// executable.
+	ModuleFS fs.FS
+
+	// SearchPath lists the directories searched, in order, when a wrangle
+	// statement names a module that isn't built in. The first directory that
+	// contains <name>.beef wins. main.go fills this in from --path, BEEF_PATH,
+	// and the directory of the script being run (see BuildSearchPath), then
+	// the packages of the project's beef.toml.
+	// Directories may use ${NAME} for an environment variable.
+	SearchPath []string
+
+	// Args holds the script's command line, like os.Args in Go: the script's
+	// path followed by the arguments after it. Scripts read it as os.args.
+	Args []string
+
+	// EntryPoint names the function CallEntryPoint starts a program in (set
+	// by --entry). Choosing another lets one file hold several runnable
+	// scenarios.
+	EntryPoint string
+
+	// Strict turns lenient behaviors into runtime errors (set by --strict):
+	//   - assigning to a variable that was never declared with 'prep'
+	//   - 'prep' of a name that already exists in an enclosing scope (shadowing)
+	//   - NULL as an operand of arithmetic or ordering operators
+	//   - reading a module member that doesn't exist (normally NULL)
+	//
+	// The interpreter also refuses to run a program that declares a name
+	// twice in one block (analysis warning BE0304) when Strict is set.
+	Strict bool
+
+	// Conditions are what '#when' directives test in the program's code (set
+	// by --define): main.go parses the program's files with them, and
+	// wrangled modules and meta.eval code are parsed with them too.
+	// NewRuntime sets platform to the operating system, as Go names it
+	// ("windows", "darwin", "linux").
+	Conditions lexer.Conditions
+
+	// Location is the time zone the time module reads and writes dates in.
+	// If it is nil, the machine's local time zone is used.
+	Location *time.Location
+
+	// Allowed holds the capabilities programs have.
+	Allowed Capabilities
+
+	// CollectStats turns on the counting behind ReadStats (set by --stats).
+	// It costs a little on every step, so it is off unless asked for.
+	CollectStats bool
+
+	// OnTaskError is called with the error that ended a task started by
+	// 'stampede'. Nobody waits for a task's result, so its errors can't
+	// travel up the call stack; instead they are reported as they happen and
+	// the rest of the program carries on. If it is nil the error is printed
+	// to Stderr; main.go sets it to report task errors like any other
+	// runtime error.
+	OnTaskError func(err *object.Error)
+
+	modules  moduleCache
+	stats    statCounters
+	slicer   atomic.Pointer[Slicer] // of the program running now, if any
+	keyboard keyboard
+	events   eventQueue
+	timers   timerQueue
+
+	interruptMu sync.Mutex
+	// interrupt is closed by Interrupt; everything that waits selects on it
+	interrupt chan struct{}
+}
+
+// NewRuntime returns a Runtime on the process's standard streams, with no
+// capabilities and nothing loaded yet.
+func NewRuntime() *Runtime {
+	return &Runtime{
+		Stdin:      os.Stdin,
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+		EntryPoint: DefaultEntryPoint,
+		Conditions: lexer.Conditions{"platform": runtime.GOOS},
+		modules:    newModuleCache(),
+		stats:      statCounters{moduleTime: map[string]time.Duration{}},
+		interrupt:  make(chan struct{}),
+	}
+}
+
+// Default is the Runtime of environments made without one, with
+// object.NewEnvironment: the command line's.
+var Default = NewRuntime()
+
+// NewEnvironment returns a global environment whose programs run in rt.
+func (rt *Runtime) NewEnvironment() *Environment {
+	env := object.NewEnvironment()
+	env.SetHost(rt)
+	return env
+}
+
+// location returns the time zone dates are read and written in.
+func (rt *Runtime) location() *time.Location {
+	if rt.Location != nil {
+		return rt.Location
+	}
+	return time.Local
+}
+
+// runtimeOf returns the Runtime env's programs run in.
+func runtimeOf(env *Environment) *Runtime {
+	if rt, ok := env.Host().(*Runtime); ok {
+		return rt
+	}
+	return Default
+}
+
+// taskError reports the error that ended a 'stampede' task.
+func (rt *Runtime) taskError(err *object.Error) {
+	if rt.OnTaskError != nil {
+		rt.OnTaskError(err)
+		return
+	}
+	fmt.Fprintln(rt.Stderr, err.Inspect())
+}
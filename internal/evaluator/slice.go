@@ -0,0 +1,141 @@
+package evaluator
+
+import (
+	"math"
+	"sync"
+
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// Slicer runs a program a bounded number of steps at a time, for game loops
+// that give scripts a slice of every frame. The program runs on a goroutine
+// of its own; once it has evaluated a slice's worth of AST nodes it waits,
+// in the middle of whatever it was doing, until Resume gives it another
+// slice. Its environment and call stack stay as they were in between.
+//
+// Tasks started with 'stampede' draw on the same budget, and pause along
+// with the rest of the program.
+type Slicer struct {
+	rt    *Runtime
+	steps int64 // per slice
+
+	mu     sync.Mutex
+	resume *sync.Cond
+	left   int64 // steps left in this slice
+	slice  int   // counts slices, so paused steps know when a new one starts
+	paused chan struct{}
+	done   chan object.Object
+	result object.Object
+	over   bool
+}
+
+// NewSlicer returns a Slicer giving programs running in rt steps AST nodes
+// per slice.
+func NewSlicer(rt *Runtime, steps int64) *Slicer {
+	s := &Slicer{rt: rt, steps: steps, slice: -1, paused: make(chan struct{}, 1), done: make(chan object.Object, 1)}
+	s.resume = sync.NewCond(&s.mu)
+	return s
+}
+
+// Start runs run on a goroutine once Resume is first called. run does its
+// evaluation through Run, to have it sliced.
+func (s *Slicer) Start(run func() object.Object) {
+	go func() {
+		s.mu.Lock()
+		s.wait(-1)
+		over := s.over
+		s.mu.Unlock()
+		if !over {
+			s.done <- run()
+		}
+	}()
+}
+
+// Run evaluates f a slice at a time.
+func (s *Slicer) Run(f func() object.Object) object.Object {
+	s.rt.slicer.Store(s)
+	defer s.rt.slicer.CompareAndSwap(s, nil)
+	return f()
+}
+
+// Unsliced evaluates f without counting its steps, for the host to call into
+// a program between slices (a Reload, say) without waiting for the next one.
+func (rt *Runtime) Unsliced(f func() object.Object) object.Object {
+	s := rt.slicer.Swap(nil)
+	defer rt.slicer.CompareAndSwap(nil, s)
+	return f()
+}
+
+// Resume runs the program for another slice. It returns once the slice is
+// used up, with done false, or once the program has ended, with done true
+// and the program's result.
+func (s *Slicer) Resume() (done bool, result object.Object) {
+	s.mu.Lock()
+	if s.over {
+		s.mu.Unlock()
+		return true, s.result
+	}
+	s.left = s.steps
+	s.slice++
+	s.resume.Broadcast()
+	s.mu.Unlock()
+
+	select {
+	case <-s.paused:
+		return false, nil
+	case result := <-s.done:
+		s.mu.Lock()
+		s.over, s.result = true, result
+		s.mu.Unlock()
+		return true, result
+	}
+}
+
+// Stop interrupts the program and lets it run, without pausing, until it has
+// unwound. A program that never had a slice doesn't run at all.
+func (s *Slicer) Stop() {
+	s.mu.Lock()
+	if s.over {
+		s.mu.Unlock()
+		return
+	}
+	if s.slice < 0 {
+		s.over = true
+		s.slice++
+		s.resume.Broadcast()
+		s.mu.Unlock()
+		return
+	}
+	s.steps = math.MaxInt64
+	s.mu.Unlock()
+
+	s.rt.Interrupt()
+	for {
+		if done, _ := s.Resume(); done {
+			return
+		}
+	}
+}
+
+// step takes one step from the slice, waiting for the next slice if this one
+// is used up.
+func (s *Slicer) step() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.left <= 0 {
+		select {
+		case s.paused <- struct{}{}:
+		default:
+			// Another task already said the slice is used up
+		}
+		s.wait(s.slice)
+	}
+	s.left--
+}
+
+// wait blocks, with s.mu held, until a slice after slice starts.
+func (s *Slicer) wait(slice int) {
+	for s.slice <= slice {
+		s.resume.Wait()
+	}
+}
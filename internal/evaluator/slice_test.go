@@ -0,0 +1,76 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// slicedEval starts evaluating input steps at a time, in env.
+func slicedEval(input string, env *Environment, steps int64) *Slicer {
+	program := parser.New(lexer.New(input)).ParseProgram()
+	s := NewSlicer(Default, steps)
+	s.Start(func() object.Object {
+		return s.Run(func() object.Object { return Eval(program, env) })
+	})
+	return s
+}
+
+func TestSlicerPausesBetweenSlices(t *testing.T) {
+	env := NewEnvironment()
+	s := slicedEval("prep n = 0\nfeast while n < 100:\n   n = n + 1\nbeef\nn", env, 50)
+
+	slices := 0
+	last := int64(-1)
+	for {
+		done, result := s.Resume()
+		slices++
+		if done {
+			assert.Equal(t, "100", result.Inspect())
+			break
+		}
+		// Paused mid-loop, with its progress kept
+		n, _ := env.Get("n")
+		count := n.(*object.Integer).Value
+		assert.Greater(t, count, last)
+		last = count
+	}
+	assert.Greater(t, slices, 10)
+
+	done, result := s.Resume()
+	assert.True(t, done, "a finished program stays finished")
+	assert.Equal(t, "100", result.Inspect())
+}
+
+func TestSlicerStop(t *testing.T) {
+	t.Cleanup(Default.ResetInterrupt)
+	s := slicedEval("feast while true:\n   prep x = 1\nbeef", NewEnvironment(), 10)
+
+	done, _ := s.Resume()
+	assert.False(t, done)
+	s.Stop()
+
+	done, result := s.Resume()
+	assert.True(t, done)
+	errObj, ok := result.(*object.Error)
+	if assert.True(t, ok, "expected an error, got %v", result) {
+		assert.Equal(t, diagnostics.CodeInterrupted, errObj.Code)
+	}
+}
+
+func TestUnslicedRunsBetweenSlices(t *testing.T) {
+	t.Cleanup(Default.ResetInterrupt)
+	env := NewEnvironment()
+	s := slicedEval("prep n = 0\nfeast while n < 100:\n   n = n + 1\nbeef", env, 10)
+	done, _ := s.Resume()
+	assert.False(t, done)
+
+	// Would wait for the next slice if its steps were counted
+	result := Default.Unsliced(func() object.Object { return testEval("1 + 2") })
+	assert.Equal(t, "3", result.Inspect())
+	s.Stop()
+}
@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// Stats are what running a program has cost so far, for budgeting scripts -
+// say, how much AI code a game can afford per frame.
+type Stats struct {
+	Steps        int64 // AST nodes evaluated
+	Allocations  int64 // values created by literals, operators and declarations, plus one scope per function call
+	PeakEnvDepth int   // the deepest chain of nested scopes a function call ran in (the global scope is 1; 0 if nothing was called)
+
+	// ModuleTime is the time spent loading each module file wrangled -
+	// parsing it and running its top-level code, modules it wrangles
+	// included - by module name
+	ModuleTime map[string]time.Duration
+}
+
+// statCounters are a Runtime's counts behind Stats.
+type statCounters struct {
+	steps        atomic.Int64
+	allocations  atomic.Int64
+	peakEnvDepth atomic.Int64
+
+	modulesMu  sync.Mutex
+	moduleTime map[string]time.Duration
+}
+
+// ReadStats returns the counts since the last ResetStats.
+func (rt *Runtime) ReadStats() Stats {
+	c := &rt.stats
+	c.modulesMu.Lock()
+	defer c.modulesMu.Unlock()
+	moduleTime := make(map[string]time.Duration, len(c.moduleTime))
+	for name, d := range c.moduleTime {
+		moduleTime[name] = d
+	}
+	return Stats{
+		Steps:        c.steps.Load(),
+		Allocations:  c.allocations.Load(),
+		PeakEnvDepth: int(c.peakEnvDepth.Load()),
+		ModuleTime:   moduleTime,
+	}
+}
+
+// ResetStats starts counting from zero.
+func (rt *Runtime) ResetStats() {
+	c := &rt.stats
+	c.steps.Store(0)
+	c.allocations.Store(0)
+	c.peakEnvDepth.Store(0)
+	c.modulesMu.Lock()
+	c.moduleTime = map[string]time.Duration{}
+	c.modulesMu.Unlock()
+}
+
+// evalCounted is Eval with CollectStats on.
+func evalCounted(rt *Runtime, node ast.Node, env *Environment) object.Object {
+	rt.stats.steps.Add(1)
+	result := evalNode(node, env)
+
+	switch node.(type) {
+	case *ast.IntegerLiteral, *ast.BigIntegerLiteral, *ast.StringLiteral, *ast.ArrayLiteral, *ast.HashLiteral, *ast.FunctionDeclaration:
+		rt.stats.allocations.Add(1)
+	case *ast.PrefixExpression, *ast.InfixExpression:
+		// Booleans and null are shared, never created
+		switch result.(type) {
+		case *object.Integer, *object.BigInteger, *object.String:
+			rt.stats.allocations.Add(1)
+		}
+	}
+	return result
+}
+
+// countScope counts the scope of a function call.
+func (rt *Runtime) countScope(env *Environment) {
+	rt.stats.allocations.Add(1)
+	depth := int64(0)
+	for e := env; e != nil; e = e.Outer() {
+		depth++
+	}
+	for {
+		peak := rt.stats.peakEnvDepth.Load()
+		if depth <= peak || rt.stats.peakEnvDepth.CompareAndSwap(peak, depth) {
+			return
+		}
+	}
+}
+
+// countModuleTime adds to the time spent loading a module.
+func (rt *Runtime) countModuleTime(name string, d time.Duration) {
+	rt.stats.modulesMu.Lock()
+	defer rt.stats.modulesMu.Unlock()
+	rt.stats.moduleTime[name] += d
+}
@@ -0,0 +1,57 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collectStats counts from zero for the rest of the test
+func collectStats(t *testing.T) {
+	Default.CollectStats = true
+	Default.ResetStats()
+	t.Cleanup(func() {
+		Default.CollectStats = false
+		Default.ResetStats()
+	})
+}
+
+func TestStatsCountStepsAndAllocations(t *testing.T) {
+	collectStats(t)
+
+	testEval("prep x = 1 + 2")
+	stats := Default.ReadStats()
+	// Program, declaration, infix and two literals
+	assert.Equal(t, int64(5), stats.Steps)
+	// Two literals and the sum
+	assert.Equal(t, int64(3), stats.Allocations)
+
+	Default.ResetStats()
+	testEval("prep t = 1 == 1")
+	assert.Equal(t, int64(2), Default.ReadStats().Allocations, "booleans are shared")
+}
+
+func TestStatsPeakEnvDepth(t *testing.T) {
+	collectStats(t)
+
+	testEval("praise outer():\n   praise inner():\n      serve 1\n   beef\n   serve inner()\nbeef\nouter()")
+	assert.Equal(t, 3, Default.ReadStats().PeakEnvDepth)
+}
+
+func TestStatsModuleTime(t *testing.T) {
+	collectStats(t)
+	dir := t.TempDir()
+	writeModule(t, dir, "kitchen", "prep cuts = 3")
+	withSearchPath(t, dir)
+
+	testEval("wrangle kitchen")
+	stats := Default.ReadStats()
+	assert.Contains(t, stats.ModuleTime, "kitchen")
+	assert.Positive(t, stats.ModuleTime["kitchen"])
+}
+
+func TestStatsAreOffByDefault(t *testing.T) {
+	Default.ResetStats()
+	testEval("prep x = 1 + 2")
+	assert.Zero(t, Default.ReadStats().Steps)
+}
@@ -0,0 +1,12 @@
+package evaluator
+
+import (
+	"os"
+)
+
+// streamFile returns the file behind a stream, or nil if it has been replaced
+// by something else. The terminal helpers treat nil as "not a terminal".
+func streamFile(stream any) *os.File {
+	f, _ := stream.(*os.File)
+	return f
+}
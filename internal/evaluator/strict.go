@@ -0,0 +1,54 @@
+package evaluator
+
+import (
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// checkStrictDeclaration rejects a 'prep' that would hide a variable from an
+// enclosing scope. Re-declaring in the same scope (e.g. inside a loop body) is fine.
+func checkStrictDeclaration(decl *ast.VariableDeclaration, env *Environment) *object.Error {
+	if !runtimeOf(env).Strict {
+		return nil
+	}
+	if _, local := env.GetLocal(decl.Name.Value); local {
+		return nil
+	}
+	if env.Outer() == nil {
+		return nil
+	}
+	if _, outer := env.Outer().Get(decl.Name.Value); outer {
+		return newError(decl.Name.Token, diagnostics.CodeShadowedVariable,
+			"'%s' shadows a variable from an enclosing scope (strict mode)", decl.Name.Value)
+	}
+	return nil
+}
+
+// checkStrictAssignment rejects assignment to a name that doesn't exist yet,
+// which outside strict mode silently creates a new variable.
+func checkStrictAssignment(stmt *ast.AssignmentStatement, env *Environment) *object.Error {
+	if !runtimeOf(env).Strict {
+		return nil
+	}
+	if _, ok := env.Get(stmt.Name.Value); !ok {
+		return newError(stmt.Name.Token, diagnostics.CodeUndeclaredAssignment,
+			"assignment to undeclared variable '%s' - declare it with 'prep' (strict mode)", stmt.Name.Value)
+	}
+	return nil
+}
+
+// checkStrictOperands rejects NULL in arithmetic and ordering operators,
+// so a missing value is reported where it is first used.
+func checkStrictOperands(tok token.Token, operator string, left, right object.Object, env *Environment) *object.Error {
+	if !runtimeOf(env).Strict || (left != object.NULL && right != object.NULL) {
+		return nil
+	}
+	switch operator {
+	case "+", "-", "*", "/", "%", "<", ">", "<=", ">=":
+		return newError(tok, diagnostics.CodeNullArithmetic,
+			"NULL used in arithmetic: %s %s %s (strict mode)", left.Type(), operator, right.Type())
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// withStrict enables strict mode for the duration of a test
+func withStrict(t *testing.T) {
+	Default.Strict = true
+	t.Cleanup(func() { Default.Strict = false })
+}
+
+func TestStrictModeErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		expectedCode string
+		expected     string
+	}{
+		{
+			"undeclared assignment",
+			"prep total = 0\ntotl = total + 5",
+			diagnostics.CodeUndeclaredAssignment,
+			"assignment to undeclared variable 'totl' - declare it with 'prep' (strict mode)",
+		},
+		{
+			"shadowing",
+			"prep count = 0\npraise bump():\n   prep count = 1\nbeef\nbump()",
+			diagnostics.CodeShadowedVariable,
+			"'count' shadows a variable from an enclosing scope (strict mode)",
+		},
+		{
+			"NULL arithmetic",
+			"praise noValue():\n   prep x = 1\nbeef\nnoValue() + 1",
+			diagnostics.CodeNullArithmetic,
+			"NULL used in arithmetic: NULL + INTEGER (strict mode)",
+		},
+		{
+			"missing module member",
+			"wrangle io\nio.shout",
+			diagnostics.CodeNoSuchMember,
+			"module 'io' has no member 'shout' (strict mode)",
+		},
+	}
+
+	withStrict(t)
+	for _, tt := range tests {
+		result := testEval(tt.input)
+
+		errObj, ok := result.(*object.Error)
+		if assert.True(t, ok, "%s: expected error, got %T", tt.name, result) {
+			assert.Equal(t, tt.expectedCode, errObj.Code, tt.name)
+			assert.Equal(t, tt.expected, errObj.Message, tt.name)
+		}
+	}
+}
+
+func TestStrictModeAllowsDisciplinedCode(t *testing.T) {
+	input := `
+wrangle io
+prep total = 0
+praise add(n):
+   prep doubled = n * 2
+   serve doubled
+beef
+prep i = 0
+feast while i < 3:
+   prep step = add(i)
+   total = total + step
+   i = i + 1
+beef
+total
+`
+	withStrict(t)
+	result := testEval(input)
+
+	integer, ok := result.(*object.Integer)
+	if assert.True(t, ok, "expected integer, got %T (%s)", result, result.Inspect()) {
+		assert.Equal(t, int64(6), integer.Value)
+	}
+}
+
+func TestLenientBehaviorWithoutStrictMode(t *testing.T) {
+	// Without --strict these are all allowed
+	assert.Equal(t, int64(5), testEval("x = 5\nx").(*object.Integer).Value)
+	assert.Equal(t, int64(1), testEval("prep c = 0\npraise f():\n   prep c = 1\n   serve c\nbeef\nf()").(*object.Integer).Value)
+	assert.Equal(t, object.NULL, testEval("wrangle io\nio.shout"))
+}
@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// builtinModuleNames lists the modules builtinModule knows, for suggestions.
+var builtinModuleNames = []string{
+	"io", "chan", "sync", "time", "term", "crypto", "process", "net", "http",
+	"template", "os", "flags", "fs", "array", "hash", "copy", "cache",
+	"meta", "parallel", "events", "timer", "coroutine", "vec",
+	"math", "grid", "bytes", "pack", "intl",
+}
+
+var (
+	builtinMembersOnce sync.Once
+	builtinMemberIndex map[string][]string
+)
+
+// builtinMembers maps each public member name of the built-in modules to the
+// modules that have it: "preach" to ["io"].
+func builtinMembers() map[string][]string {
+	builtinMembersOnce.Do(func() {
+		builtinMemberIndex = map[string][]string{}
+		rt := NewRuntime()
+		for _, name := range builtinModuleNames {
+			for member := range builtinModule(name)(rt).Members {
+				if !strings.HasPrefix(member, "_") {
+					builtinMemberIndex[member] = append(builtinMemberIndex[member], name)
+				}
+			}
+		}
+	})
+	return builtinMemberIndex
+}
+
+// suggestName finds what an unknown identifier was probably meant to be: a
+// name in scope, a built-in module's member or a keyword, within a couple of
+// edits. It returns the text to append to the error, or "".
+func suggestName(name string, env *Environment) string {
+	best, bestDistance, bestRank := "", diagnostics.MaxTypoEdits(name)+1, 0
+	consider := func(candidate, spelled string, rank int) {
+		d := diagnostics.EditDistance(name, candidate)
+		if d < bestDistance || (d == bestDistance && (rank < bestRank || rank == bestRank && spelled < best)) {
+			best, bestDistance, bestRank = spelled, d, rank
+		}
+	}
+
+	for scope := env; scope != nil; scope = scope.Outer() {
+		for candidate := range scope.Bindings() {
+			consider(candidate, candidate, 0)
+		}
+	}
+	for member, modules := range builtinMembers() {
+		for _, mod := range modules {
+			consider(member, mod+"."+member, 1)
+		}
+	}
+	for _, keyword := range token.Keywords() {
+		consider(keyword, keyword, 2)
+	}
+
+	if best == "" {
+		return ""
+	}
+	hint := " - did you mean " + best + "?"
+	if mod, _, ok := strings.Cut(best, "."); ok && bestRank == 1 {
+		if _, wrangled := env.Get(mod); !wrangled {
+			hint += " (after wrangle " + mod + ")"
+		}
+	}
+	return hint
+}
@@ -0,0 +1,41 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentifierSuggestions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"preach(1)", "identifier not found: preach - did you mean io.preach? (after wrangle io)"},
+		{"wrangle io\npreach(1)", "identifier not found: preach - did you mean io.preach?"},
+		{"prep total = 1\ntotla", "identifier not found: totla - did you mean total?"},
+		{"praise cook_brisket():\nbeef\ncook_briskit()", "identifier not found: cook_briskit - did you mean cook_brisket?"},
+		// Inside a function, globals and the function's own names are both in reach
+		{"prep count = 1\npraise f(amount):\n  serve amout + cout\nbeef\nf(1)", "identifier not found: amout - did you mean amount?"},
+		{"tru", "identifier not found: tru - did you mean true?"},
+		// Nothing close enough
+		{"foobar", "identifier not found: foobar"},
+		{"q", "identifier not found: q"},
+	}
+
+	for _, tt := range tests {
+		result := testEval(tt.input)
+		errObj, ok := result.(*object.Error)
+		if assert.True(t, ok, "%s: got %s", tt.input, result.Inspect()) {
+			assert.Equal(t, tt.expected, errObj.Message, tt.input)
+		}
+	}
+}
+
+func TestBuiltinModuleNamesAreModules(t *testing.T) {
+	for _, name := range builtinModuleNames {
+		assert.NotNil(t, builtinModule(name), name)
+	}
+	assert.Equal(t, []string{"io"}, builtinMembers()["preach"])
+}
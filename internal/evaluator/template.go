@@ -0,0 +1,93 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+func createTemplateModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "template",
+		Members: make(map[string]object.Object),
+	}
+
+	// render - fill in a template from a hash, using Go's text/template syntax:
+	//   template.render("{{.name}} has {{len .cuts}} cuts:{{range .cuts}} {{.}}{{end}}",
+	//                   {"name": "Bubba", "cuts": ["brisket", "ribs"]})
+	// Conditionals are {{if .hungry}}...{{else}}...{{end}}. Using a key the
+	// hash doesn't have is an error rather than silently printing nothing.
+	mod.Set("render", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("template.render", args, 2); err != nil {
+				return err
+			}
+			text, err := object.StringArg("template.render", args[0])
+			if err != nil {
+				return err
+			}
+			data, ok := args[1].(*object.Hash)
+			if !ok {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("template.render: expected a HASH, got %s", args[1].Type())}
+			}
+			return renderTemplate(text, data)
+		},
+	})
+
+	return mod
+}
+
+func renderTemplate(text string, data *object.Hash) object.Object {
+	tmpl, err := template.New("template").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return templateError(err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, templateValue(data)); err != nil {
+		return templateError(err)
+	}
+	return &object.String{Value: out.String()}
+}
+
+// templateValue converts a Beeflang value to the Go value a template sees.
+// Hash keys become strings, so {"1": x} and {1: x} both read as .1 - only
+// string keys can be written as .name in a template anyway.
+func templateValue(obj object.Object) interface{} {
+	switch v := obj.(type) {
+	case *object.Integer:
+		return v.Value
+	case *object.BigInteger:
+		return v.Value
+	case *object.Boolean:
+		return v.Value
+	case *object.String:
+		return v.Value
+	case *object.Null:
+		return nil
+	case *object.Array:
+		elements := v.Elements()
+		values := make([]interface{}, len(elements))
+		for i, el := range elements {
+			values[i] = templateValue(el)
+		}
+		return values
+	case *object.Hash:
+		values := make(map[string]interface{}, v.Len())
+		for _, pair := range v.Pairs() {
+			values[pair.Key.Inspect()] = templateValue(pair.Value)
+		}
+		return values
+	}
+	return obj.Inspect()
+}
+
+// templateError reports a template that doesn't parse or can't be filled in.
+// Go's messages start "template: template:LINE:"; the first part is dropped,
+// leaving the line (and column) within the template.
+func templateError(err error) *object.Error {
+	return &object.Error{Code: diagnostics.CodeTemplateError, Message: strings.TrimPrefix(err.Error(), "template: ")}
+}
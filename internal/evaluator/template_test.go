@@ -0,0 +1,54 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateRender(t *testing.T) {
+	tests := []struct {
+		template string
+		data     string
+		expected string
+	}{
+		{"Howdy, {{.name}}!", `{"name": "Bubba"}`, "Howdy, Bubba!"},
+		{"{{.count}} {{.done}}", `{"count": 3, "done": false}`, "3 false"},
+		{"{{if .hungry}}Eat{{else}}Nap{{end}}", `{"hungry": true}`, "Eat"},
+		{"{{if .hungry}}Eat{{else}}Nap{{end}}", `{"hungry": false}`, "Nap"},
+		{"{{range .cuts}}[{{.}}]{{end}}", `{"cuts": ["brisket", "ribs"]}`, "[brisket][ribs]"},
+		{"{{range $i, $c := .cuts}}{{$i}}={{$c}} {{end}}", `{"cuts": ["a", "b"]}`, "0=a 1=b "},
+		{"{{len .cuts}}", `{"cuts": [1, 2, 3]}`, "3"},
+		{"{{.order.cut}} x{{.order.qty}}", `{"order": {"cut": "tri-tip", "qty": 2}}`, "tri-tip x2"},
+		{"{{index .cuts 1}}", `{"cuts": ["brisket", "ribs"]}`, "ribs"},
+	}
+
+	for _, tt := range tests {
+		input := "wrangle template\ntemplate.render(\"" + tt.template + "\", " + tt.data + ")"
+		assert.Equal(t, tt.expected, testEval(input).Inspect(), tt.template)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		code     string
+		expected string
+	}{
+		{`template.render("Hi {{.name", {})`, diagnostics.CodeTemplateError, "template:1: unclosed action"},
+		{`template.render("Hi {{.name}}", {})`, diagnostics.CodeTemplateError,
+			`template:1:5: executing "template" at <.name>: map has no entry for key "name"`},
+		{`template.render("Hi", 1)`, diagnostics.CodeBadArgument, "template.render: expected a HASH, got INTEGER"},
+		{`template.render(1, {})`, diagnostics.CodeBadArgument, "template.render: expected a STRING, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval("wrangle template\n" + tt.input).(*object.Error)
+		if assert.True(t, ok, "expected an error for %q", tt.input) {
+			assert.Equal(t, tt.code, errObj.Code)
+			assert.Equal(t, tt.expected, errObj.Message)
+		}
+	}
+}
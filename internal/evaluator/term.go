@@ -0,0 +1,183 @@
+package evaluator
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/terminal"
+)
+
+// termColors maps color names to their ANSI foreground codes; backgrounds
+// are 10 higher. "bright_" names are the high-intensity variants.
+var termColors = map[string]int{
+	"black": 30, "red": 31, "green": 32, "yellow": 33,
+	"blue": 34, "magenta": 35, "cyan": 36, "white": 37,
+	"bright_black": 90, "bright_red": 91, "bright_green": 92, "bright_yellow": 93,
+	"bright_blue": 94, "bright_magenta": 95, "bright_cyan": 96, "bright_white": 97,
+}
+
+// termStyles are the text styles, each a function of the term module.
+var termStyles = map[string]int{
+	"bold": 1, "dim": 2, "italic": 3, "underline": 4, "reverse": 7,
+}
+
+func createTermModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "term",
+		Members: make(map[string]object.Object),
+	}
+
+	// color - text in a foreground color, optionally on a background color:
+	//   io.preach(term.color("HP 3", "red"))
+	//   io.preach(term.color(" @ ", "black", "bright_yellow"))
+	mod.Set("color", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 2 || len(args) > 3 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("term.color takes 2 or 3 arguments (text, color, background), got %d", len(args))}
+			}
+			codes := make([]int, 0, 2)
+			for i, arg := range args[1:] {
+				name, ok := arg.(*object.String)
+				if !ok {
+					return &object.Error{Code: diagnostics.CodeBadArgument,
+						Message: fmt.Sprintf("term.color: color names are STRINGs, got %s", arg.Type())}
+				}
+				code, ok := termColors[name.Value]
+				if !ok {
+					return &object.Error{Code: diagnostics.CodeBadArgument,
+						Message: fmt.Sprintf("term.color: unknown color '%s' (want %s)", name.Value, colorNames())}
+				}
+				if i == 1 {
+					code += 10
+				}
+				codes = append(codes, code)
+			}
+			return styled(args[0], codes...)
+		},
+	})
+
+	for name, code := range termStyles {
+		mod.Set(name, &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := object.CheckArgCount("term."+name, args, 1); err != nil {
+					return err
+				}
+				return styled(args[0], code)
+			},
+		})
+	}
+
+	// write - print values without a newline, e.g. after term.move
+	mod.Set("write", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			for _, arg := range args {
+				fmt.Fprint(rt.Stdout, arg.Inspect())
+			}
+			return object.NULL
+		},
+	})
+
+	// move - put the cursor at row, col (both start at 1, top left)
+	mod.Set("move", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("term.move", args, 2); err != nil {
+				return err
+			}
+			row, err := object.IntegerArg("term.move", args[0])
+			if err != nil {
+				return err
+			}
+			col, err := object.IntegerArg("term.move", args[1])
+			if err != nil {
+				return err
+			}
+			if row < 1 || col < 1 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("term.move: row and column start at 1, got %d, %d", row, col)}
+			}
+			fmt.Fprintf(rt.Stdout, "\x1b[%d;%dH", row, col)
+			return object.NULL
+		},
+	})
+
+	// Screen and cursor control, each a fixed escape sequence
+	controls := map[string]string{
+		"clear":       "\x1b[2J\x1b[H", // clear the screen and move to the top left
+		"clear_line":  "\x1b[2K\r",     // clear the cursor's line and move to its start
+		"hide_cursor": "\x1b[?25l",
+		"show_cursor": "\x1b[?25h",
+	}
+	for name, sequence := range controls {
+		mod.Set(name, &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := object.CheckArgCount("term."+name, args, 0); err != nil {
+					return err
+				}
+				fmt.Fprint(rt.Stdout, sequence)
+				return object.NULL
+			},
+		})
+	}
+
+	// width, height - the size of the terminal in characters (80x24, or
+	// COLUMNS and LINES, when output isn't a terminal)
+	mod.Set("width", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("term.width", args, 0); err != nil {
+				return err
+			}
+			width, _ := terminal.Size(streamFile(rt.Stdout))
+			return &object.Integer{Value: int64(width)}
+		},
+	})
+	mod.Set("height", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("term.height", args, 0); err != nil {
+				return err
+			}
+			_, height := terminal.Size(streamFile(rt.Stdout))
+			return &object.Integer{Value: int64(height)}
+		},
+	})
+
+	// is_terminal - whether output goes to a terminal rather than a file or pipe
+	mod.Set("is_terminal", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("term.is_terminal", args, 0); err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(terminal.IsTerminal(streamFile(rt.Stdout)))
+		},
+	})
+
+	return mod
+}
+
+// styled wraps a value's text in ANSI SGR codes, and resets them after. The
+// text comes back unchanged when the NO_COLOR environment variable is set.
+func styled(value object.Object, codes ...int) object.Object {
+	text := value.Inspect()
+	if os.Getenv("NO_COLOR") != "" {
+		return &object.String{Value: text}
+	}
+	params := make([]string, len(codes))
+	for i, code := range codes {
+		params[i] = fmt.Sprint(code)
+	}
+	return &object.String{Value: "\x1b[" + strings.Join(params, ";") + "m" + text + "\x1b[0m"}
+}
+
+// colorNames lists the color names for error messages.
+func colorNames() string {
+	names := make([]string, 0, len(termColors))
+	for name := range termColors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,96 @@
+package evaluator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureStdout runs fn and returns what it printed
+func captureStdout(t *testing.T, fn func()) string {
+	var out bytes.Buffer
+	stdout := Default.Stdout
+	Default.Stdout = &out
+	defer func() { Default.Stdout = stdout }()
+
+	fn()
+	return out.String()
+}
+
+func TestTermStyles(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`term.color("HP", "red")`, "\x1b[31mHP\x1b[0m"},
+		{`term.color(" @ ", "black", "bright_yellow")`, "\x1b[30;103m @ \x1b[0m"},
+		{`term.bold(42)`, "\x1b[1m42\x1b[0m"},
+		{`term.underline(term.color("x", "cyan"))`, "\x1b[4m\x1b[36mx\x1b[0m\x1b[0m"},
+		{`term.reverse("x")`, "\x1b[7mx\x1b[0m"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle term\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestTermStylesRespectNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	result := testEval("wrangle term\nterm.bold(term.color(\"HP\", \"red\"))")
+	assert.Equal(t, "HP", result.Inspect())
+}
+
+func TestTermScreenControl(t *testing.T) {
+	out := captureStdout(t, func() {
+		testEval(`wrangle term
+term.clear()
+term.move(3, 10)
+term.write("@", 1)
+term.clear_line()
+term.hide_cursor()
+term.show_cursor()`)
+	})
+
+	assert.Equal(t, "\x1b[2J\x1b[H\x1b[3;10H@1\x1b[2K\r\x1b[?25l\x1b[?25h", out)
+}
+
+func TestTermSize(t *testing.T) {
+	t.Setenv("COLUMNS", "100")
+	t.Setenv("LINES", "")
+
+	// Tests don't run in a terminal, so the size comes from COLUMNS and the default
+	assert.Equal(t, int64(100), testEval("wrangle term\nterm.width()").(*object.Integer).Value)
+	assert.Equal(t, int64(24), testEval("wrangle term\nterm.height()").(*object.Integer).Value)
+	assert.Equal(t, object.FALSE, testEval("wrangle term\nterm.is_terminal()"))
+}
+
+func TestTermErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+	}{
+		{`term.color("x")`, "term.color takes 2 or 3 arguments (text, color, background), got 1"},
+		{`term.color("x", "beige")`, "term.color: unknown color 'beige' (want black, blue, bright_black, bright_blue, bright_cyan, bright_green, bright_magenta, bright_red, bright_white, bright_yellow, cyan, green, magenta, red, white, yellow)"},
+		{`term.color("x", "red", 1)`, "term.color: color names are STRINGs, got INTEGER"},
+		{`term.bold()`, "term.bold takes 1 argument, got 0"},
+		{`term.move(1)`, "term.move takes 2 arguments, got 1"},
+		{`term.move(1, "x")`, "term.move: expected an INTEGER, got STRING"},
+		{`term.move(0, 1)`, "term.move: row and column start at 1, got 0, 1"},
+		{`term.clear(1)`, "term.clear takes no arguments, got 1"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval("wrangle term\n" + tt.input).(*object.Error)
+		if assert.True(t, ok, "input %q should fail", tt.input) {
+			assert.Equal(t, diagnostics.CodeBadArgument, errObj.Code, tt.input)
+			assert.Equal(t, tt.message, errObj.Message, tt.input)
+			assert.Equal(t, 2, errObj.Line, tt.input)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package evaluator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+func createTimeModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "time",
+		Members: make(map[string]object.Object),
+	}
+
+	// after - a channel that receives true once ms milliseconds have passed.
+	// Select on it to give up waiting for something else:
+	//   when time.after(500).recv():
+	mod.Set("after", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			d, err := durationArg("time.after", args)
+			if err != nil {
+				return err
+			}
+			// Buffered, so the timer never blocks if nobody is listening any more
+			ch := object.NewChannel(1)
+			time.AfterFunc(d, func() { ch.Send(object.TRUE) })
+			return ch
+		},
+	})
+
+	// sleep - pause the current task for ms milliseconds
+	mod.Set("sleep", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			d, err := durationArg("time.sleep", args)
+			if err != nil {
+				return err
+			}
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+				return object.NULL
+			case <-rt.interruptChan():
+				return &object.Error{Code: diagnostics.CodeInterrupted, Message: "interrupted"}
+			}
+		},
+	})
+
+	// duration - a length of time, from milliseconds or from text like "1.5s",
+	// "250ms" or "1h30m" (units ns, us, ms, s, m and h). Durations add,
+	// subtract and compare with each other, multiply and divide by integers,
+	// and work wherever milliseconds do:
+	//   prep cooldown = time.duration("2.5s")
+	mod.Set("duration", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("time.duration", args, 1); err != nil {
+				return err
+			}
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				d, ok := checkedMul(arg.Value, int64(time.Millisecond))
+				if !ok {
+					return &object.Error{Code: diagnostics.CodeIntegerOverflow,
+						Message: fmt.Sprintf("time.duration: %d milliseconds is too long", arg.Value)}
+				}
+				return &object.Duration{Value: time.Duration(d)}
+			case *object.String:
+				d, err := time.ParseDuration(arg.Value)
+				if err != nil {
+					return timeError("time.duration", err)
+				}
+				return &object.Duration{Value: d}
+			}
+			return &object.Error{Code: diagnostics.CodeBadArgument,
+				Message: fmt.Sprintf("time.duration: expected an INTEGER or STRING, got %s", args[0].Type())}
+		},
+	})
+
+	// stopwatch - a stopwatch started now. elapsed() is the time since, as a
+	// duration, and reset() starts it again, returning the time it had:
+	//   prep sw = time.stopwatch()
+	//   if sw.elapsed() >= cooldown:
+	mod.Set("stopwatch", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("time.stopwatch", args, 0); err != nil {
+				return err
+			}
+			return object.NewStopwatch()
+		},
+	})
+
+	// now - the current time, as a timestamp: milliseconds since the start of
+	// 1970 (UTC). The other time functions take timestamps:
+	//   prep saved_at = time.now()
+	mod.Set("now", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("time.now", args, 0); err != nil {
+				return err
+			}
+			return &object.Integer{Value: time.Now().UnixMilli()}
+		},
+	})
+
+	// format - a timestamp written out in a layout of tokens like YYYY, MM,
+	// DD, HH, mm and ss (see timelayout.go), in the local time zone:
+	//   time.format(saved_at, "D MMM YYYY, HH:mm")    # "4 Jul 2025, 18:30"
+	mod.Set("format", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("time.format", args, 2); err != nil {
+				return err
+			}
+			ts, err := object.IntegerArg("time.format", args[0])
+			if err != nil {
+				return err
+			}
+			layout, err := object.StringArg("time.format", args[1])
+			if err != nil {
+				return err
+			}
+			text, formatErr := formatTime(time.UnixMilli(ts).In(rt.location()), layout)
+			if formatErr != nil {
+				return timeError("time.format", formatErr)
+			}
+			return &object.String{Value: text}
+		},
+	})
+
+	// parse - the timestamp of a date written in a layout, as for format.
+	// Fields the layout leaves out are the earliest they can be, so a date
+	// alone is its midnight:
+	//   time.parse("2025-07-04", "YYYY-MM-DD")
+	mod.Set("parse", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("time.parse", args, 2); err != nil {
+				return err
+			}
+			text, err := object.StringArg("time.parse", args[0])
+			if err != nil {
+				return err
+			}
+			layout, err := object.StringArg("time.parse", args[1])
+			if err != nil {
+				return err
+			}
+			t, parseErr := parseTime(text, layout, rt.location())
+			if parseErr != nil {
+				return timeError("time.parse", parseErr)
+			}
+			return &object.Integer{Value: t.UnixMilli()}
+		},
+	})
+
+	// year, month, day, weekday, hour, minute, second - one part of a
+	// timestamp's date and time, in the local time zone. Months go from 1
+	// (January) to 12, weekdays from 0 (Sunday) to 6:
+	//   if time.weekday(time.now()) == 5:
+	mod.Set("year", timePart(rt, "time.year", time.Time.Year))
+	mod.Set("month", timePart(rt, "time.month", func(t time.Time) int { return int(t.Month()) }))
+	mod.Set("day", timePart(rt, "time.day", time.Time.Day))
+	mod.Set("weekday", timePart(rt, "time.weekday", func(t time.Time) int { return int(t.Weekday()) }))
+	mod.Set("hour", timePart(rt, "time.hour", time.Time.Hour))
+	mod.Set("minute", timePart(rt, "time.minute", time.Time.Minute))
+	mod.Set("second", timePart(rt, "time.second", time.Time.Second))
+
+	return mod
+}
+
+// timePart makes a builtin returning part of a timestamp's local time.
+func timePart(rt *Runtime, name string, part func(time.Time) int) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount(name, args, 1); err != nil {
+				return err
+			}
+			ts, err := object.IntegerArg(name, args[0])
+			if err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(part(time.UnixMilli(ts).In(rt.location())))}
+		},
+	}
+}
+
+func timeError(name string, err error) *object.Error {
+	return &object.Error{Code: diagnostics.CodeBadArgument, Message: fmt.Sprintf("%s: %s", name, err)}
+}
+
+// durationArg reads the single argument of a time function, milliseconds or
+// a duration.
+func durationArg(name string, args []object.Object) (time.Duration, *object.Error) {
+	if len(args) != 1 {
+		return 0, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s takes 1 argument (milliseconds), got %d", name, len(args))}
+	}
+	if d, ok := args[0].(*object.Duration); ok {
+		if d.Value < 0 {
+			return 0, &object.Error{Code: diagnostics.CodeBadArgument,
+				Message: fmt.Sprintf("%s: duration must not be negative, got %s", name, d.Value)}
+		}
+		return d.Value, nil
+	}
+	ms, ok := args[0].(*object.Integer)
+	if !ok {
+		return 0, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: milliseconds must be an INTEGER, got %s", name, args[0].Type())}
+	}
+	if ms.Value < 0 {
+		return 0, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: milliseconds must not be negative, got %d", name, ms.Value)}
+	}
+	return time.Duration(ms.Value) * time.Millisecond, nil
+}
+
+// isDurationArithmetic reports whether an infix expression works on
+// durations: two durations, or a duration and an integer.
+func isDurationArithmetic(left, right object.Object) bool {
+	_, leftDuration := left.(*object.Duration)
+	_, rightDuration := right.(*object.Duration)
+	_, leftInt := left.(*object.Integer)
+	_, rightInt := right.(*object.Integer)
+	return (leftDuration && (rightDuration || rightInt)) || (leftInt && rightDuration)
+}
+
+// evalDurationInfixExpression handles durations: adding, subtracting and
+// comparing two, how many times one fits in another (an integer, with the
+// rest from %), and scaling one by an integer.
+func evalDurationInfixExpression(tok token.Token, operator string, left, right object.Object) object.Object {
+	leftVal, leftIsDuration := durationValue(left)
+	rightVal, rightIsDuration := durationValue(right)
+	overflow := func() object.Object {
+		return newError(tok, diagnostics.CodeIntegerOverflow, "integer overflow: %s %s %s", left.Inspect(), operator, right.Inspect())
+	}
+
+	if leftIsDuration && rightIsDuration {
+		switch operator {
+		case "+", "-":
+			result, ok := checkedAdd(leftVal, rightVal)
+			if operator == "-" {
+				result, ok = checkedSub(leftVal, rightVal)
+			}
+			if !ok {
+				return overflow()
+			}
+			return &object.Duration{Value: time.Duration(result)}
+		case "/", "%":
+			if rightVal == 0 {
+				return newError(tok, diagnostics.CodeDivisionByZero, "division by zero: %s %s 0s", left.Inspect(), operator)
+			}
+			if operator == "%" {
+				return &object.Duration{Value: time.Duration(leftVal % rightVal)}
+			}
+			result, ok := checkedDiv(leftVal, rightVal)
+			if !ok {
+				return overflow()
+			}
+			return &object.Integer{Value: result}
+		case "<":
+			return nativeBoolToBooleanObject(leftVal < rightVal)
+		case ">":
+			return nativeBoolToBooleanObject(leftVal > rightVal)
+		case "==":
+			return nativeBoolToBooleanObject(leftVal == rightVal)
+		case "!=":
+			return nativeBoolToBooleanObject(leftVal != rightVal)
+		case "<=":
+			return nativeBoolToBooleanObject(leftVal <= rightVal)
+		case ">=":
+			return nativeBoolToBooleanObject(leftVal >= rightVal)
+		}
+	} else {
+		switch {
+		case operator == "*":
+			result, ok := checkedMul(leftVal, rightVal)
+			if !ok {
+				return overflow()
+			}
+			return &object.Duration{Value: time.Duration(result)}
+		case operator == "/" && leftIsDuration:
+			if rightVal == 0 {
+				return newError(tok, diagnostics.CodeDivisionByZero, "division by zero: %s / 0", left.Inspect())
+			}
+			result, ok := checkedDiv(leftVal, rightVal)
+			if !ok {
+				return overflow()
+			}
+			return &object.Duration{Value: time.Duration(result)}
+		}
+	}
+	return newError(tok, diagnostics.CodeUnknownOperator, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+// durationValue returns the value of a duration in nanoseconds, or of an
+// integer, and whether it was a duration.
+func durationValue(obj object.Object) (int64, bool) {
+	if d, ok := obj.(*object.Duration); ok {
+		return int64(d.Value), true
+	}
+	return obj.(*object.Integer).Value, false
+}
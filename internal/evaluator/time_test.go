@@ -0,0 +1,148 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// evalInZone evaluates input in a runtime whose dates are in loc.
+func evalInZone(loc *time.Location, input string) object.Object {
+	rt := NewRuntime()
+	rt.Location = loc
+	return evalIn(rt.NewEnvironment(), "wrangle time\n"+input)
+}
+
+func TestTimeFormatAndParse(t *testing.T) {
+	// 2025-07-04 18:30:05.250 UTC, a Friday
+	const ts = "1751653805250"
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`time.format(` + ts + `, "YYYY-MM-DD HH:mm:ss.SSS")`, "2025-07-04 18:30:05.250"},
+		{`time.format(` + ts + `, "dddd D MMMM YY, h:mm a")`, "Friday 4 July 25, 6:30 pm"},
+		{`time.format(` + ts + `, "ddd, MMM D [at] hh A")`, "Fri, Jul 4 at 06 PM"},
+		{`time.format(` + ts + `, "YYYY-MM-DD[T]HH:mm:ssZ")`, "2025-07-04T18:30:05Z"},
+		{`time.format(0, "YYYY")`, "1970"},
+		{`time.parse("2025-07-04 18:30:05.250", "YYYY-MM-DD HH:mm:ss.SSS")`, ts},
+		{`time.parse("2025-07-04", "YYYY-MM-DD")`, "1751587200000"},
+		{`time.parse("Thu, 29 feb 2024 7:05 AM", "ddd, D MMM YYYY h:mm A")`, "1709190300000"},
+		{`time.parse("2025-07-04T20:30:05+02:00", "YYYY-MM-DD[T]HH:mm:ssZ")`, "1751653805000"},
+		{`[time.year(` + ts + `), time.month(` + ts + `), time.day(` + ts + `), time.weekday(` + ts + `)]`, "[2025, 7, 4, 5]"},
+		{`[time.hour(` + ts + `), time.minute(` + ts + `), time.second(` + ts + `)]`, "[18, 30, 5]"},
+		{`time.now() > 1751653805250`, "true"},
+	}
+
+	for _, tt := range tests {
+		result := evalInZone(time.UTC, tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestTimeUsesTheRuntimesZone(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	assert.Equal(t, "2025-07-05 03:30", evalInZone(tokyo, `time.format(1751653805250, "YYYY-MM-DD HH:mm")`).Inspect())
+	assert.Equal(t, "[5, 6, 3]", evalInZone(tokyo, `[time.day(1751653805250), time.weekday(1751653805250), time.hour(1751653805250)]`).Inspect())
+	assert.Equal(t, "1751653800000", evalInZone(tokyo, `time.parse("2025-07-05 03:30", "YYYY-MM-DD HH:mm")`).Inspect())
+	assert.Equal(t, "+09:00", evalInZone(tokyo, `time.format(0, "Z")`).Inspect())
+}
+
+func TestTimeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`time.parse("2025-02-30", "YYYY-MM-DD")`, `time.parse: "2025-02-30" is not a real date and time`},
+		{`time.parse("2025-7-04", "YYYY-MM-DD")`, `time.parse: "2025-7-04" doesn't match layout "YYYY-MM-DD": expected MM (a number) at "7-04"`},
+		{`time.parse("2025-07-04!", "YYYY-MM-DD")`, `time.parse: "2025-07-04!" doesn't match layout "YYYY-MM-DD": "!" left over`},
+		{`time.parse("Smarch 1", "MMMM D")`, `time.parse: "Smarch 1" doesn't match layout "MMMM D": expected a month at "Smarch 1"`},
+		{`time.parse("13:00 PM", "hh:mm A")`, `time.parse: "13:00 PM": hour 13 is not on a 12-hour clock`},
+		{`time.format(0, "[YYYY")`, `time.format: layout "[YYYY" has a [ with no ]`},
+		{`time.format("now", "YYYY")`, "time.format: expected an INTEGER, got STRING"},
+		{`time.year()`, "time.year takes 1 argument, got 0"},
+	}
+
+	for _, tt := range tests {
+		result := evalInZone(time.UTC, tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
+
+func TestDurations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`time.duration(1500)`, "1.5s"},
+		{`time.duration("1h30m")`, "1h30m0s"},
+		{`time.duration("250ms") + time.duration(1000)`, "1.25s"},
+		{`time.duration("1s") - time.duration("1m")`, "-59s"},
+		{`time.duration("250ms") * 4`, "1s"},
+		{`3 * time.duration("2s")`, "6s"},
+		{`time.duration("1s") / 3`, "333.333333ms"},
+		{`time.duration("1m") / time.duration("7s")`, "8"},
+		{`time.duration("1m") % time.duration("7s")`, "4s"},
+		{`time.duration("1s") == time.duration(1000)`, "true"},
+		{`[time.duration("2s") > time.duration(1999), time.duration("2s") <= time.duration("1s")]`, "[true, false]"},
+		{`prep d = time.duration("2h45m30.9s")` + "\n[d.hours, d.minutes, d.seconds, d.ms]", "[2, 165, 9930, 9930900]"},
+		{`time.sleep(time.duration("1ms"))`, "null"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle time\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestStopwatch(t *testing.T) {
+	env := NewRuntime().NewEnvironment()
+	evalIn(env, "wrangle time\nprep sw = time.stopwatch()\ntime.sleep(20)")
+
+	elapsed, ok := evalIn(env, "sw.elapsed()").(*object.Duration)
+	if !ok {
+		t.Fatal("elapsed() should return a duration")
+	}
+	assert.GreaterOrEqual(t, elapsed.Value, 20*time.Millisecond)
+
+	lap, ok := evalIn(env, "sw.reset()").(*object.Duration)
+	if !ok {
+		t.Fatal("reset() should return a duration")
+	}
+	assert.GreaterOrEqual(t, lap.Value, elapsed.Value)
+	assert.Equal(t, "true", evalIn(env, "sw.elapsed() < time.duration(20)").Inspect(), "reset starts from zero")
+	assert.Equal(t, "true", evalIn(env, "sw.elapsed().ms >= 0").Inspect())
+}
+
+func TestDurationErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`time.duration("soon")`, `time.duration: time: invalid duration "soon"`},
+		{`time.duration(true)`, "time.duration: expected an INTEGER or STRING, got BOOLEAN"},
+		{`time.duration(9223372036854775807)`, "time.duration: 9223372036854775807 milliseconds is too long"},
+		{`time.duration("1s") + 1`, "unknown operator: DURATION + INTEGER"},
+		{`2 / time.duration("1s")`, "unknown operator: INTEGER / DURATION"},
+		{`time.duration("1s") / 0`, "division by zero: 1s / 0"},
+		{`time.duration("1s") % time.duration(0)`, "division by zero: 1s % 0s"},
+		{`time.duration("2562047h") * 2`, "integer overflow: 2562047h0m0s * 2"},
+		{`time.sleep(time.duration("-1s"))`, "time.sleep: duration must not be negative, got -1s"},
+		{`time.stopwatch().lap()`, "STOPWATCH has no member 'lap'"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle time\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
@@ -0,0 +1,307 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts for time.format and time.parse spell out a date the way it is
+// written, with these tokens:
+//
+//	YYYY  2006      YY  06
+//	MMMM  January   MMM  Jan   MM  01   M  1
+//	DD    02        D   2
+//	dddd  Monday    ddd  Mon
+//	HH    15        H   15 (not padded)
+//	hh    03        h   3 (12-hour clock)
+//	mm    04        ss  05     SSS  milliseconds
+//	A     PM        a   pm
+//	Z     +07:00, or Z for UTC
+//
+// Anything else is copied as is. Text in [brackets] is never read as tokens,
+// for letters that would be: "YYYY-MM-DD[T]HH:mm:ss", "[Day] D".
+var layoutTokens = []string{
+	"YYYY", "YY", "MMMM", "MMM", "MM", "M", "DD", "D", "dddd", "ddd",
+	"HH", "H", "hh", "h", "mm", "ss", "SSS", "A", "a", "Z",
+}
+
+// layoutPart is a token of a layout, or a run of literal text.
+type layoutPart struct {
+	token   string
+	literal string
+}
+
+func parseLayout(layout string) ([]layoutPart, error) {
+	var parts []layoutPart
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			parts = append(parts, layoutPart{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+	for rest := layout; rest != ""; {
+		if rest[0] == '[' {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("layout %q has a [ with no ]", layout)
+			}
+			literal.WriteString(rest[1:end])
+			rest = rest[end+1:]
+			continue
+		}
+		token := ""
+		for _, t := range layoutTokens {
+			if strings.HasPrefix(rest, t) {
+				token = t
+				break
+			}
+		}
+		if token == "" {
+			literal.WriteByte(rest[0])
+			rest = rest[1:]
+			continue
+		}
+		flush()
+		parts = append(parts, layoutPart{token: token})
+		rest = rest[len(token):]
+	}
+	flush()
+	return parts, nil
+}
+
+// formatTime writes t in a layout.
+func formatTime(t time.Time, layout string) (string, error) {
+	parts, err := parseLayout(layout)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, part := range parts {
+		switch part.token {
+		case "":
+			b.WriteString(part.literal)
+		case "YYYY":
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case "YY":
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case "MMMM":
+			b.WriteString(t.Month().String())
+		case "MMM":
+			b.WriteString(t.Month().String()[:3])
+		case "MM":
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case "M":
+			fmt.Fprintf(&b, "%d", int(t.Month()))
+		case "DD":
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case "D":
+			fmt.Fprintf(&b, "%d", t.Day())
+		case "dddd":
+			b.WriteString(t.Weekday().String())
+		case "ddd":
+			b.WriteString(t.Weekday().String()[:3])
+		case "HH":
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case "H":
+			fmt.Fprintf(&b, "%d", t.Hour())
+		case "hh":
+			fmt.Fprintf(&b, "%02d", twelveHour(t.Hour()))
+		case "h":
+			fmt.Fprintf(&b, "%d", twelveHour(t.Hour()))
+		case "mm":
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case "ss":
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case "SSS":
+			fmt.Fprintf(&b, "%03d", t.Nanosecond()/int(time.Millisecond))
+		case "A", "a":
+			half := "AM"
+			if t.Hour() >= 12 {
+				half = "PM"
+			}
+			if part.token == "a" {
+				half = strings.ToLower(half)
+			}
+			b.WriteString(half)
+		case "Z":
+			b.WriteString(t.Format("Z07:00"))
+		}
+	}
+	return b.String(), nil
+}
+
+func twelveHour(hour int) int {
+	if hour%12 == 0 {
+		return 12
+	}
+	return hour % 12
+}
+
+// parseTime reads a time written in a layout. Fields the layout leaves out
+// are the earliest they can be: a layout of only a date gives midnight. The
+// time is in loc unless the layout has a Z.
+func parseTime(s, layout string, loc *time.Location) (time.Time, error) {
+	parts, err := parseLayout(layout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	year, month, day := 1, 1, 1
+	hour, minute, second, millis := 0, 0, 0, 0
+	pm := -1 // unknown: the hour is on the 24-hour clock
+	twelve := false
+	rest := s
+	fail := func(what string) (time.Time, error) {
+		return time.Time{}, fmt.Errorf("%q doesn't match layout %q: expected %s at %q", s, layout, what, rest)
+	}
+
+	for _, part := range parts {
+		var n int
+		var ok bool
+		switch part.token {
+		case "":
+			if !strings.HasPrefix(rest, part.literal) {
+				return fail(strconv.Quote(part.literal))
+			}
+			rest = rest[len(part.literal):]
+			continue
+		case "MMMM", "MMM", "dddd", "ddd":
+			names, what := monthNames, "a month"
+			if part.token[0] == 'd' {
+				names, what = weekdayNames, "a weekday"
+			}
+			i := matchName(&rest, names, len(part.token) == 3)
+			if i < 0 {
+				return fail(what)
+			}
+			if part.token[0] == 'M' {
+				month = i + 1
+			}
+			continue
+		case "A", "a":
+			switch {
+			case strings.HasPrefix(strings.ToUpper(rest), "AM"):
+				pm = 0
+			case strings.HasPrefix(strings.ToUpper(rest), "PM"):
+				pm = 1
+			default:
+				return fail("AM or PM")
+			}
+			rest = rest[2:]
+			continue
+		case "Z":
+			if strings.HasPrefix(rest, "Z") {
+				loc, rest = time.UTC, rest[1:]
+				continue
+			}
+			if len(rest) < 6 || (rest[0] != '+' && rest[0] != '-') || rest[3] != ':' {
+				return fail("a time zone offset")
+			}
+			hours, errH := strconv.Atoi(rest[1:3])
+			minutes, errM := strconv.Atoi(rest[4:6])
+			if errH != nil || errM != nil {
+				return fail("a time zone offset")
+			}
+			offset := hours*3600 + minutes*60
+			if rest[0] == '-' {
+				offset = -offset
+			}
+			loc, rest = time.FixedZone("", offset), rest[6:]
+			continue
+		case "YYYY":
+			n, ok = readDigits(&rest, 4, 4)
+			year = n
+		case "YY":
+			// As in Go and C: 69 to 99 are 1969 to 1999
+			n, ok = readDigits(&rest, 2, 2)
+			year = 2000 + n
+			if n >= 69 {
+				year = 1900 + n
+			}
+		case "MM", "M":
+			n, ok = readDigits(&rest, len(part.token), 2)
+			month = n
+		case "DD", "D":
+			n, ok = readDigits(&rest, len(part.token), 2)
+			day = n
+		case "HH", "H":
+			n, ok = readDigits(&rest, len(part.token), 2)
+			hour = n
+		case "hh", "h":
+			n, ok = readDigits(&rest, len(part.token), 2)
+			hour, twelve = n, true
+		case "mm":
+			n, ok = readDigits(&rest, 2, 2)
+			minute = n
+		case "ss":
+			n, ok = readDigits(&rest, 2, 2)
+			second = n
+		case "SSS":
+			n, ok = readDigits(&rest, 3, 3)
+			millis = n
+		}
+		if !ok {
+			return fail(fmt.Sprintf("%s (a number)", part.token))
+		}
+	}
+	if rest != "" {
+		return time.Time{}, fmt.Errorf("%q doesn't match layout %q: %q left over", s, layout, rest)
+	}
+
+	if twelve {
+		if hour < 1 || hour > 12 {
+			return time.Time{}, fmt.Errorf("%q: hour %d is not on a 12-hour clock", s, hour)
+		}
+		hour %= 12
+		if pm == 1 {
+			hour += 12
+		}
+	} else if pm == 1 && hour < 12 {
+		hour += 12
+	}
+	t := time.Date(year, time.Month(month), day, hour, minute, second, millis*int(time.Millisecond), loc)
+	// time.Date moves fields that are out of range along (February 30 to
+	// March 2); they are mistakes here
+	if t.Year() != year || int(t.Month()) != month || t.Day() != day ||
+		t.Hour() != hour || t.Minute() != minute || t.Second() != second {
+		return time.Time{}, fmt.Errorf("%q is not a real date and time", s)
+	}
+	return t, nil
+}
+
+var (
+	monthNames = []string{"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"}
+	weekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+)
+
+// matchName reads one of names, or their first three letters if short, from
+// the start of s, ignoring case, and returns its index or -1.
+func matchName(s *string, names []string, short bool) int {
+	for i, name := range names {
+		if short {
+			name = name[:3]
+		}
+		if len(*s) >= len(name) && strings.EqualFold((*s)[:len(name)], name) {
+			*s = (*s)[len(name):]
+			return i
+		}
+	}
+	return -1
+}
+
+// readDigits reads from fewest to most decimal digits from the start of s.
+func readDigits(s *string, fewest, most int) (int, bool) {
+	i := 0
+	for i < len(*s) && i < most && (*s)[i] >= '0' && (*s)[i] <= '9' {
+		i++
+	}
+	if i < fewest {
+		return 0, false
+	}
+	n, _ := strconv.Atoi((*s)[:i])
+	*s = (*s)[i:]
+	return n, true
+}
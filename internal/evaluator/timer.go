@@ -0,0 +1,222 @@
+package evaluator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// timerQueue holds the functions a Runtime's programs scheduled with
+// timer.after and timer.every. Timers go by their own clock, which only moves
+// when it is ticked - by the program with timer.tick, or by the host (see
+// Tick) - so a paused game pauses its timers too, and the functions run on the
+// goroutine that ticks.
+type timerQueue struct {
+	mu        sync.Mutex
+	elapsed   time.Duration // the clock: how far it has been ticked
+	lastTick  time.Time     // when timer.tick() last read the real time
+	nextID    int64
+	scheduled map[int64]*scheduledTimer
+}
+
+type scheduledTimer struct {
+	due   time.Duration // on the timers' clock
+	every time.Duration // 0 for a timer that runs once
+	fn    object.Object
+}
+
+// Tick moves the timers' clock on by elapsed and calls the functions that
+// have come due, earliest first, returning how many calls it made. A
+// repeating timer runs once for every interval that has passed. Timers
+// scheduled by those functions wait for the next tick. A function that fails
+// stops the tick and its error is returned; the clock still moves on, and
+// the timers it left due run on the next tick.
+func (rt *Runtime) Tick(elapsed time.Duration) object.Object {
+	q := &rt.timers
+	q.mu.Lock()
+	target := q.elapsed + max(elapsed, 0)
+	last := q.nextID // timers numbered after it were scheduled during this tick
+	q.mu.Unlock()
+
+	calls := 0
+	for {
+		q.mu.Lock()
+		id, t := q.nextDue(target, last)
+		if t == nil {
+			q.elapsed = target
+			q.mu.Unlock()
+			return &object.Integer{Value: int64(calls)}
+		}
+		q.elapsed = t.due
+		if t.every > 0 {
+			t.due += t.every
+		} else {
+			delete(q.scheduled, id)
+		}
+		q.mu.Unlock()
+
+		calls++
+		if result := applyFunction(token.Token{}, t.fn, nil); isError(result) {
+			q.mu.Lock()
+			q.elapsed = target
+			q.mu.Unlock()
+			return result
+		}
+	}
+}
+
+// nextDue returns the timer that comes due first by target, among those
+// numbered up to last. Timers due at the same time run in the order they were
+// scheduled.
+func (q *timerQueue) nextDue(target time.Duration, last int64) (int64, *scheduledTimer) {
+	var firstID int64
+	var first *scheduledTimer
+	for id, t := range q.scheduled {
+		if id > last || t.due > target {
+			continue
+		}
+		if first == nil || t.due < first.due || (t.due == first.due && id < firstID) {
+			firstID, first = id, t
+		}
+	}
+	return firstID, first
+}
+
+// schedule adds a timer that comes due after d, repeating every d if
+// repeat is set, and returns its id.
+func (q *timerQueue) schedule(d time.Duration, repeat bool, fn object.Object) int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.scheduled == nil {
+		q.scheduled = make(map[int64]*scheduledTimer)
+		q.lastTick = time.Now()
+	}
+	t := &scheduledTimer{due: q.elapsed + d, fn: fn}
+	if repeat {
+		t.every = d
+	}
+	q.nextID++
+	q.scheduled[q.nextID] = t
+	return q.nextID
+}
+
+func createTimerModule(rt *Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "timer",
+		Members: make(map[string]object.Object),
+	}
+
+	// after - call a function once, ms milliseconds from now on the timers'
+	// clock; returns an id for timer.cancel:
+	//   timer.after(3000, respawn)
+	mod.Set("after", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			d, fn, err := timerArgs("timer.after", args)
+			if err != nil {
+				return err
+			}
+			return &object.Integer{Value: rt.timers.schedule(d, false, fn)}
+		},
+	})
+
+	// every - call a function every ms milliseconds on the timers' clock,
+	// until cancelled; returns an id for timer.cancel:
+	//   prep regen = timer.every(1000, regenerate)
+	mod.Set("every", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			d, fn, err := timerArgs("timer.every", args)
+			if err != nil {
+				return err
+			}
+			if d == 0 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: "timer.every: milliseconds must be positive, got 0"}
+			}
+			return &object.Integer{Value: rt.timers.schedule(d, true, fn)}
+		},
+	})
+
+	// cancel - stop a timer from running again; returns whether it was still
+	// scheduled:
+	//   timer.cancel(regen)
+	mod.Set("cancel", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("timer.cancel", args, 1); err != nil {
+				return err
+			}
+			id, err := object.IntegerArg("timer.cancel", args[0])
+			if err != nil {
+				return err
+			}
+			rt.timers.mu.Lock()
+			defer rt.timers.mu.Unlock()
+			if _, ok := rt.timers.scheduled[id]; !ok {
+				return object.FALSE
+			}
+			delete(rt.timers.scheduled, id)
+			return object.TRUE
+		},
+	})
+
+	// tick - move the timers' clock on and run what has come due, from a
+	// game loop; returns how many calls it made. With no argument the clock
+	// follows real time since the last tick; with one it moves on by that
+	// many milliseconds, such as a frame's length:
+	//   timer.tick()
+	//   timer.tick(16)
+	mod.Set("tick", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) > 1 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("timer.tick takes 0 or 1 arguments (milliseconds), got %d", len(args))}
+			}
+			var step time.Duration
+			if len(args) == 1 {
+				d, err := durationArg("timer.tick", args)
+				if err != nil {
+					return err
+				}
+				step = d
+			}
+
+			now := time.Now()
+			rt.timers.mu.Lock()
+			if len(args) == 0 && !rt.timers.lastTick.IsZero() {
+				step = now.Sub(rt.timers.lastTick)
+			}
+			rt.timers.lastTick = now
+			rt.timers.mu.Unlock()
+			return rt.Tick(step)
+		},
+	})
+
+	return mod
+}
+
+// timerArgs reads the milliseconds and function of timer.after and
+// timer.every. The function is called with no arguments.
+func timerArgs(name string, args []object.Object) (time.Duration, object.Object, *object.Error) {
+	if err := object.CheckArgCount(name, args, 2); err != nil {
+		return 0, nil, err
+	}
+	d, err := durationArg(name, args[:1])
+	if err != nil {
+		return 0, nil, err
+	}
+	switch fn := args[1].(type) {
+	case *object.Function:
+		if len(fn.Parameters) > 0 {
+			return 0, nil, &object.Error{Code: diagnostics.CodeBadArgument,
+				Message: fmt.Sprintf("%s: expected a function of no parameters, got one of %d", name, len(fn.Parameters))}
+		}
+	case *object.Builtin:
+	default:
+		return 0, nil, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: expected a function, got %s", name, args[1].Type())}
+	}
+	return d, args[1], nil
+}
@@ -0,0 +1,112 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// timerLog collects what timer callbacks ran, as log.
+const timerLog = `wrangle timer
+wrangle array
+prep log = []
+praise spawn():
+  array.push(log, "spawn")
+beef
+praise regen():
+  array.push(log, "regen")
+beef
+`
+
+func TestTimers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`timer.after(100, spawn)
+[timer.tick(99), array.slice(log, 0, 9), timer.tick(1), log]`, `[0, [], 1, ["spawn"]]`},
+		// A repeating timer runs once per interval passed, in order with the
+		// others
+		{`timer.every(100, regen)
+timer.after(150, spawn)
+[timer.tick(350), log]`, `[4, ["regen", "spawn", "regen", "regen"]]`},
+		// Timers due together run in the order they were scheduled
+		{`timer.after(10, regen)
+timer.after(10, spawn)
+timer.tick(10)
+log`, `["regen", "spawn"]`},
+		{`prep id = timer.every(100, regen)
+timer.tick(100)
+[timer.cancel(id), timer.cancel(id), timer.tick(1000), log]`, `[true, false, 0, ["regen"]]`},
+		// A timer scheduled by a timer waits for the next tick, even if it
+		// is already due
+		{`praise chain():
+  array.push(log, "chain")
+  timer.after(0, chain)
+beef
+timer.after(0, chain)
+[timer.tick(0), timer.tick(0), log]`, `[1, 1, ["chain", "chain"]]`},
+		{`timer.after(0, spawn)
+[timer.tick(), log]`, `[1, ["spawn"]]`},
+		{"timer.tick(5)", "0"},
+	}
+
+	for _, tt := range tests {
+		result := evalIn(NewRuntime().NewEnvironment(), timerLog+tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestTimerFailingFunction(t *testing.T) {
+	rt := NewRuntime()
+	env := rt.NewEnvironment()
+	result := evalIn(env, timerLog+`praise broken():
+  serve missing
+beef
+timer.after(10, broken)
+timer.after(20, spawn)`)
+	if isError(result) {
+		t.Fatalf("setup failed: %s", result.Inspect())
+	}
+
+	errObj, ok := rt.Tick(30 * time.Millisecond).(*object.Error)
+	if !ok {
+		t.Fatal("expected the failing timer's error")
+	}
+	assert.Contains(t, errObj.Message, "identifier not found: missing")
+
+	// The timer due after the failing one runs on the next tick
+	assert.Equal(t, "1", rt.Tick(0).Inspect())
+	log, _ := env.Get("log")
+	assert.Equal(t, `["spawn"]`, log.Inspect())
+}
+
+func TestTimerErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"timer.after(10, 1)", "timer.after: expected a function, got INTEGER"},
+		{"timer.after(-1, spawn)", "timer.after: milliseconds must not be negative, got -1"},
+		{`timer.every("soon", spawn)`, "timer.every: milliseconds must be an INTEGER, got STRING"},
+		{"timer.every(0, spawn)", "timer.every: milliseconds must be positive, got 0"},
+		{"timer.after(10)", "timer.after takes 2 arguments, got 1"},
+		{`praise hit(n):
+  serve n
+beef
+timer.every(10, hit)`, "timer.every: expected a function of no parameters, got one of 1"},
+		{`timer.cancel("regen")`, "timer.cancel: expected an INTEGER, got STRING"},
+		{"timer.tick(1, 2)", "timer.tick takes 0 or 1 arguments (milliseconds), got 2"},
+	}
+
+	for _, tt := range tests {
+		result := evalIn(NewRuntime().NewEnvironment(), timerLog+tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
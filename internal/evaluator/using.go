@@ -0,0 +1,49 @@
+package evaluator
+
+import (
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// evalUsingStatement binds a resource, runs the block, then calls the
+// resource's close member - also when the block serves a value or fails. The
+// close member is looked up before the block runs, so a value that can't be
+// closed is an error straight away rather than after the work is done.
+func evalUsingStatement(stmt *ast.UsingStatement, env *Environment) object.Object {
+	value := Eval(stmt.Value, env)
+	if isError(value) {
+		return value
+	}
+
+	var closer object.Object
+	if container, ok := value.(object.Container); ok {
+		closer, _ = container.Get("close")
+	}
+	closable := false
+	switch c := closer.(type) {
+	case *object.Function:
+		closable = len(c.Parameters) == 0
+	case *object.Builtin:
+		closable = true
+	}
+	if !closable {
+		return newError(stmt.Name.Token, diagnostics.CodeNoSuchMember,
+			"'using' needs a value with a close() member taking no arguments, got %s", value.Type())
+	}
+	env.Set(stmt.Name.Value, value)
+
+	result := Eval(stmt.Body, env)
+
+	// Like a dessert, closing skips the interrupt check so it happens after Ctrl+C too
+	var closed object.Object
+	if fn, ok := closer.(*object.Function); ok {
+		closed = CallFunction(fn)
+	} else {
+		closed = applyFunction(stmt.Token, closer, nil)
+	}
+	if isError(closed) && !isError(result) {
+		return closed
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsingClosesTheValue(t *testing.T) {
+	input := `
+wrangle chan
+prep got = 0
+using ch = chan.new(1):
+   ch.send(5)
+   got = ch.recv()
+beef
+ch.send(got)
+`
+	errObj, ok := testEval(input).(*object.Error)
+	if assert.True(t, ok, "sending after the block should fail") {
+		assert.Equal(t, "send on closed channel", errObj.Message)
+		assert.Equal(t, 8, errObj.Line)
+	}
+}
+
+func TestUsingClosesAfterServe(t *testing.T) {
+	input := `
+wrangle chan
+praise first(ch):
+   using c = ch:
+      serve c.recv()
+   beef
+beef
+prep ch = chan.new(1)
+ch.send(42)
+first(ch) + ch.recv()
+`
+	// recv on the closed channel gives NULL
+	errObj, ok := testEval(input).(*object.Error)
+	if assert.True(t, ok) {
+		assert.Equal(t, "type mismatch: INTEGER + NULL", errObj.Message)
+	}
+}
+
+func TestUsingClosesAfterAnError(t *testing.T) {
+	env := NewEnvironment()
+	evalIn(env, "wrangle chan\nprep ch = chan.new(1)")
+
+	result := evalIn(env, "using c = ch:\n   nope\nbeef")
+	assert.Equal(t, "identifier not found: nope", result.(*object.Error).Message)
+
+	result = evalIn(env, "ch.send(1)")
+	assert.Equal(t, diagnostics.CodeClosedChannel, result.(*object.Error).Code)
+}
+
+func TestUsingErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		code    string
+		message string
+		line    int
+	}{
+		{"using n = 5:\nbeef", diagnostics.CodeNoSuchMember,
+			"'using' needs a value with a close() member taking no arguments, got INTEGER", 1},
+		{"wrangle sync\nusing lock = sync.mutex():\nbeef", diagnostics.CodeNoSuchMember,
+			"'using' needs a value with a close() member taking no arguments, got MUTEX", 2},
+		// Closing fails when the block already closed it
+		{"wrangle chan\nusing ch = chan.new():\n   ch.close()\nbeef", diagnostics.CodeClosedChannel, "close of closed channel", 2},
+		// The block's own error wins over one from closing
+		{"wrangle chan\nusing ch = chan.new():\n   ch.close()\n   ch.send(1)\nbeef", diagnostics.CodeClosedChannel, "send on closed channel", 4},
+		{"using ch = nope:\nbeef", diagnostics.CodeIdentifierNotFound, "identifier not found: nope", 1},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if assert.True(t, ok, "input %q should fail", tt.input) {
+			assert.Equal(t, tt.code, errObj.Code, tt.input)
+			assert.Equal(t, tt.message, errObj.Message, tt.input)
+			assert.Equal(t, tt.line, errObj.Line, tt.input)
+		}
+	}
+}
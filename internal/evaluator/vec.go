@@ -0,0 +1,268 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// Beeflang has no fractions, so where the vec and math functions need one -
+// how far to interpolate, the length of a unit vector - it is given in
+// thousandths: 500 is a half, 1000 is one.
+const fractionScale = 1000
+
+func createVecModule(*Runtime) *object.Module {
+	mod := &object.Module{
+		Name:    "vec",
+		Members: make(map[string]object.Object),
+	}
+
+	// new - a 2D or 3D vector, whose components read as v.x, v.y and v.z:
+	//   prep pos = vec.new(10, 4)
+	mod.Set("new", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("vec.new takes 2 or 3 arguments, got %d", len(args))}
+			}
+			components := make([]int64, len(args))
+			for i, arg := range args {
+				n, err := object.IntegerArg("vec.new", arg)
+				if err != nil {
+					return err
+				}
+				components[i] = n
+			}
+			return &object.Vector{Components: components}
+		},
+	})
+
+	// add, sub - the sum or difference of two vectors of the same size:
+	//   pos = vec.add(pos, velocity)
+	mod.Set("add", vectorOp("vec.add", checkedAdd))
+	mod.Set("sub", vectorOp("vec.sub", checkedSub))
+
+	// scale - a vector times an integer:
+	//   vec.scale(direction, speed)
+	mod.Set("scale", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("vec.scale", args, 2); err != nil {
+				return err
+			}
+			v, err := vectorArg("vec.scale", args[0])
+			if err != nil {
+				return err
+			}
+			n, err := object.IntegerArg("vec.scale", args[1])
+			if err != nil {
+				return err
+			}
+			components := make([]int64, len(v.Components))
+			for i, c := range v.Components {
+				product, ok := checkedMul(c, n)
+				if !ok {
+					return vectorOverflow("vec.scale")
+				}
+				components[i] = product
+			}
+			return &object.Vector{Components: components}
+		},
+	})
+
+	// dot - the dot product of two vectors of the same size
+	mod.Set("dot", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			a, b, err := vectorPair("vec.dot", args)
+			if err != nil {
+				return err
+			}
+			sum := new(big.Int)
+			for i := range a.Components {
+				sum.Add(sum, new(big.Int).Mul(big.NewInt(a.Components[i]), big.NewInt(b.Components[i])))
+			}
+			if !sum.IsInt64() {
+				return vectorOverflow("vec.dot")
+			}
+			return &object.Integer{Value: sum.Int64()}
+		},
+	})
+
+	// length - a vector's length, rounded to the nearest integer:
+	//   vec.length(vec.new(3, 4))    # 5
+	mod.Set("length", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("vec.length", args, 1); err != nil {
+				return err
+			}
+			v, err := vectorArg("vec.length", args[0])
+			if err != nil {
+				return err
+			}
+			length, ok := vectorLength(v)
+			if !ok {
+				return vectorOverflow("vec.length")
+			}
+			return &object.Integer{Value: length}
+		},
+	})
+
+	// normalize - a vector pointing the same way with a length of 1000, or of
+	// the length given, each component rounded; the zero vector stays zero:
+	//   vec.normalize(vec.new(3, 4))        # vec(600, 800)
+	//   vec.normalize(vec.new(3, 4), 10)    # vec(6, 8)
+	mod.Set("normalize", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return &object.Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("vec.normalize takes 1 or 2 arguments, got %d", len(args))}
+			}
+			v, err := vectorArg("vec.normalize", args[0])
+			if err != nil {
+				return err
+			}
+			length := int64(fractionScale)
+			if len(args) == 2 {
+				if length, err = object.IntegerArg("vec.normalize", args[1]); err != nil {
+					return err
+				}
+			}
+
+			var squares float64
+			for _, c := range v.Components {
+				squares += float64(c) * float64(c)
+			}
+			components := make([]int64, len(v.Components))
+			if squares == 0 {
+				return &object.Vector{Components: components}
+			}
+			norm := math.Sqrt(squares)
+			for i, c := range v.Components {
+				scaled := math.Round(float64(c) / norm * float64(length))
+				if scaled >= math.MaxInt64 || scaled < math.MinInt64 {
+					return vectorOverflow("vec.normalize")
+				}
+				components[i] = int64(scaled)
+			}
+			return &object.Vector{Components: components}
+		},
+	})
+
+	// lerp - the point t thousandths of the way from a to b, rounded:
+	//   vec.lerp(start, target, 250)    # a quarter of the way
+	// t outside 0 to 1000 goes past either end.
+	mod.Set("lerp", &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := object.CheckArgCount("vec.lerp", args, 3); err != nil {
+				return err
+			}
+			a, b, err := vectorPair("vec.lerp", args[:2])
+			if err != nil {
+				return err
+			}
+			t, err := object.IntegerArg("vec.lerp", args[2])
+			if err != nil {
+				return err
+			}
+			components := make([]int64, len(a.Components))
+			for i := range a.Components {
+				c, ok := lerp(a.Components[i], b.Components[i], t)
+				if !ok {
+					return vectorOverflow("vec.lerp")
+				}
+				components[i] = c
+			}
+			return &object.Vector{Components: components}
+		},
+	})
+
+	return mod
+}
+
+// vectorOp returns a builtin combining two vectors component by component.
+func vectorOp(name string, op func(a, b int64) (int64, bool)) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			a, b, err := vectorPair(name, args)
+			if err != nil {
+				return err
+			}
+			components := make([]int64, len(a.Components))
+			for i := range a.Components {
+				c, ok := op(a.Components[i], b.Components[i])
+				if !ok {
+					return vectorOverflow(name)
+				}
+				components[i] = c
+			}
+			return &object.Vector{Components: components}
+		},
+	}
+}
+
+// vectorArg returns an argument that must be a vector.
+func vectorArg(name string, arg object.Object) (*object.Vector, *object.Error) {
+	v, ok := arg.(*object.Vector)
+	if !ok {
+		return nil, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: expected a VECTOR, got %s", name, arg.Type())}
+	}
+	return v, nil
+}
+
+// vectorPair returns the two arguments of a function of two vectors of the
+// same size.
+func vectorPair(name string, args []object.Object) (*object.Vector, *object.Vector, *object.Error) {
+	if err := object.CheckArgCount(name, args, 2); err != nil {
+		return nil, nil, err
+	}
+	a, err := vectorArg(name, args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := vectorArg(name, args[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(a.Components) != len(b.Components) {
+		return nil, nil, &object.Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: can't combine a %dD vector with a %dD one", name, len(a.Components), len(b.Components))}
+	}
+	return a, b, nil
+}
+
+func vectorOverflow(name string) *object.Error {
+	return &object.Error{Code: diagnostics.CodeIntegerOverflow, Message: fmt.Sprintf("integer overflow in %s", name)}
+}
+
+// vectorLength returns the length of v rounded to the nearest integer,
+// worked out exactly, and whether it fits in 64 bits.
+func vectorLength(v *object.Vector) (int64, bool) {
+	squares := new(big.Int)
+	for _, c := range v.Components {
+		squares.Add(squares, new(big.Int).Mul(big.NewInt(c), big.NewInt(c)))
+	}
+	root := new(big.Int).Sqrt(squares)
+	// The length rounds up when squares is past (root + 1/2)², that is
+	// root² + root + 1/4
+	rest := new(big.Int).Sub(squares, new(big.Int).Mul(root, root))
+	if rest.Cmp(root) > 0 {
+		root.Add(root, big.NewInt(1))
+	}
+	return root.Int64(), root.IsInt64()
+}
+
+// lerp returns a + (b - a) * t / 1000, rounded half away from zero, and
+// whether it fits in 64 bits.
+func lerp(a, b, t int64) (int64, bool) {
+	offset := new(big.Int).Mul(new(big.Int).Sub(big.NewInt(b), big.NewInt(a)), big.NewInt(t))
+	half := big.NewInt(fractionScale / 2)
+	if offset.Sign() < 0 {
+		half.Neg(half)
+	}
+	offset.Quo(offset.Add(offset, half), big.NewInt(fractionScale))
+	result := offset.Add(offset, big.NewInt(a))
+	return result.Int64(), result.IsInt64()
+}
@@ -0,0 +1,66 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVec(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"vec.new(1, 2)", "vec(1, 2)"},
+		{"vec.new(1, 2, 3).z", "3"},
+		{"prep v = vec.new(7, -2)\n[v.x, v.y]", "[7, -2]"},
+		{"vec.add(vec.new(1, 2), vec.new(10, 20))", "vec(11, 22)"},
+		{"vec.sub(vec.new(1, 2, 3), vec.new(3, 2, 1))", "vec(-2, 0, 2)"},
+		{"vec.scale(vec.new(1, -2), 3)", "vec(3, -6)"},
+		{"vec.dot(vec.new(1, 2, 3), vec.new(4, 5, 6))", "32"},
+		{"vec.length(vec.new(3, 4))", "5"},
+		{"vec.length(vec.new(1, 1))", "1"},
+		{"vec.length(vec.new(1, 2))", "2"},
+		{"vec.length(vec.new(0, 0, 0))", "0"},
+		{"vec.normalize(vec.new(3, 4))", "vec(600, 800)"},
+		{"vec.normalize(vec.new(0, -5), 10)", "vec(0, -10)"},
+		{"vec.normalize(vec.new(1, 1, 1))", "vec(577, 577, 577)"},
+		{"vec.normalize(vec.new(0, 0))", "vec(0, 0)"},
+		{"vec.lerp(vec.new(0, 10), vec.new(100, 20), 250)", "vec(25, 13)"},
+		{"vec.lerp(vec.new(0, 0), vec.new(-3, 3), 500)", "vec(-2, 2)"},
+		{"vec.lerp(vec.new(0, 0), vec.new(10, 10), 2000)", "vec(20, 20)"},
+		// Vectors compare by value
+		{"vec.new(1, 2) == vec.add(vec.new(0, 1), vec.new(1, 1))", "true"},
+		{"vec.new(1, 2) != vec.new(1, 2, 0)", "true"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle vec\n" + tt.input)
+		assert.Equal(t, tt.expected, result.Inspect(), tt.input)
+	}
+}
+
+func TestVecErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"vec.new(1)", "vec.new takes 2 or 3 arguments, got 1"},
+		{`vec.new(1, "2")`, "vec.new: expected an INTEGER, got STRING"},
+		{"vec.add(vec.new(1, 2), 3)", "vec.add: expected a VECTOR, got INTEGER"},
+		{"vec.dot(vec.new(1, 2), vec.new(1, 2, 3))", "vec.dot: can't combine a 2D vector with a 3D one"},
+		{"vec.scale(vec.new(9223372036854775807, 0), 2)", "integer overflow in vec.scale"},
+		{"vec.new(1, 2).z", "VECTOR has no member 'z'"},
+		{"vec.lerp(vec.new(1, 2), vec.new(1, 2))", "vec.lerp takes 3 arguments, got 2"},
+	}
+
+	for _, tt := range tests {
+		result := testEval("wrangle vec\n" + tt.input)
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Fatalf("expected an error for %q, got %T (%+v)", tt.input, result, result)
+		}
+		assert.Equal(t, tt.expected, errObj.Message, tt.input)
+	}
+}
@@ -0,0 +1,172 @@
+// Package highlight renders Beeflang source with syntax coloring, as HTML for
+// documentation sites or as ANSI-colored text for terminals.
+//
+// Highlighting is purely lexical: each token is classified by its type alone,
+// so it works on files that don't parse and never changes the text - the
+// output shows the source byte for byte, including whitespace and comments.
+package highlight
+
+import (
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// Class is the highlighting category of a token. It is also the suffix of the
+// CSS class in HTML output: class="beef-keyword".
+type Class string
+
+const (
+	Plain       Class = ""            // whitespace between tokens
+	Keyword     Class = "keyword"     // praise, prep, if, ...
+	Identifier  Class = "identifier"  // names
+	Number      Class = "number"      // integer literals
+	String      Class = "string"      // string literals, quotes included
+	Constant    Class = "constant"    // true and false
+	Comment     Class = "comment"     // '#' comments
+	Operator    Class = "operator"    // + == && -> ...
+	Punctuation Class = "punctuation" // ( ) [ ] { } : , .
+	Invalid     Class = "invalid"     // characters the lexer doesn't accept
+)
+
+// Span is a run of source text in one class.
+type Span struct {
+	Text  string
+	Class Class
+}
+
+// Classify returns the highlighting class for a token type.
+func Classify(t token.TokenType) Class {
+	switch t {
+	case token.IDENT:
+		return Identifier
+	case token.INT, token.BIGINT:
+		return Number
+	case token.STRING:
+		return String
+	case token.TRUE, token.FALSE:
+		return Constant
+	case token.COMMENT:
+		return Comment
+	case token.LPAREN, token.RPAREN, token.LBRACKET, token.RBRACKET, token.LBRACE, token.RBRACE,
+		token.COLON, token.COMMA, token.DOT, token.OPT_DOT:
+		return Punctuation
+	case token.ILLEGAL:
+		return Invalid
+	case token.EOF:
+		return Plain
+	}
+	if token.IsKeyword(t) {
+		return Keyword
+	}
+	return Operator
+}
+
+// Spans splits source into classified spans that, joined, give back source
+// exactly. Whitespace between tokens is a Plain span. A keyword right after
+// a '.' is a member name (http.serve) and is classed as an Identifier.
+func Spans(source string) []Span {
+	var spans []Span
+	pos := 0
+	prev := token.TokenType("")
+	for _, tok := range lexer.New(source).AllTokens() {
+		if tok.Type == token.EOF {
+			break
+		}
+		// Tokens never overlap, but skip any that would rather than garble the output
+		if tok.Offset < pos || tok.End.Offset > len(source) {
+			continue
+		}
+		if tok.Offset > pos {
+			spans = append(spans, Span{Text: source[pos:tok.Offset], Class: Plain})
+		}
+		class := Classify(tok.Type)
+		if (prev == token.DOT || prev == token.OPT_DOT) && token.IsKeyword(tok.Type) {
+			class = Identifier
+		}
+		spans = append(spans, Span{Text: source[tok.Offset:tok.End.Offset], Class: class})
+		pos = tok.End.Offset
+		if tok.Type != token.COMMENT {
+			prev = tok.Type
+		}
+	}
+	if pos < len(source) {
+		spans = append(spans, Span{Text: source[pos:], Class: Plain})
+	}
+	return spans
+}
+
+// CSS is a default stylesheet for WriteHTML's classes, included in the
+// standalone page written by WritePage.
+const CSS = `pre.beef { background: #fdf6e3; color: #333; padding: 1em; }
+.beef-keyword { color: #a626a4; font-weight: bold; }
+.beef-number, .beef-constant { color: #0184bc; }
+.beef-string { color: #50a14f; }
+.beef-comment { color: #a0a1a7; font-style: italic; }
+.beef-operator { color: #c18401; }
+.beef-invalid { color: #e45649; text-decoration: underline wavy; }
+`
+
+// WriteHTML writes source as an HTML fragment: a <pre class="beef"> block in
+// which every token is a <span class="beef-CLASS">. Whitespace and identifiers
+// are left unwrapped.
+func WriteHTML(w io.Writer, source string) error {
+	if _, err := io.WriteString(w, `<pre class="beef"><code>`); err != nil {
+		return err
+	}
+	for _, span := range Spans(source) {
+		text := html.EscapeString(span.Text)
+		if span.Class != Plain && span.Class != Identifier {
+			text = fmt.Sprintf(`<span class="beef-%s">%s</span>`, span.Class, text)
+		}
+		if _, err := io.WriteString(w, text); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "</code></pre>\n")
+	return err
+}
+
+// WritePage writes a standalone HTML page showing source, styled with CSS.
+func WritePage(w io.Writer, title string, source string) error {
+	_, err := fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n",
+		html.EscapeString(title), CSS)
+	if err != nil {
+		return err
+	}
+	if err := WriteHTML(w, source); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "</body>\n</html>\n")
+	return err
+}
+
+// ANSI escape sequences for each class in terminal output.
+var ansiStyles = map[Class]string{
+	Keyword:  "\x1b[1;35m",
+	Number:   "\x1b[36m",
+	Constant: "\x1b[36m",
+	String:   "\x1b[32m",
+	Comment:  "\x1b[90m",
+	Operator: "\x1b[33m",
+	Invalid:  "\x1b[1;4;31m",
+}
+
+const ansiReset = "\x1b[0m"
+
+// WriteANSI writes source with ANSI color escapes, for terminals.
+func WriteANSI(w io.Writer, source string) error {
+	for _, span := range Spans(source) {
+		text := span.Text
+		if style, ok := ansiStyles[span.Class]; ok {
+			text = style + text + ansiReset
+		}
+		if _, err := io.WriteString(w, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		tokenType token.TokenType
+		expected  Class
+	}{
+		{token.PRAISE, Keyword},
+		{token.FEAST_WHILE, Keyword},
+		{token.AND_WORD, Keyword},
+		{token.IDENT, Identifier},
+		{token.INT, Number},
+		{token.STRING, String},
+		{token.TRUE, Constant},
+		{token.COMMENT, Comment},
+		{token.PLUS, Operator},
+		{token.ARROW, Operator},
+		{token.AND, Operator},
+		{token.LPAREN, Punctuation},
+		{token.DOT, Punctuation},
+		{token.ILLEGAL, Invalid},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Classify(tt.tokenType), "token type %s", tt.tokenType)
+	}
+}
+
+func TestSpansReproduceTheSource(t *testing.T) {
+	inputs := []string{
+		"praise f(a: int) -> int:\n\tserve a + 1 # add one\nbeef\n",
+		"prep s = \"two\nlines\"",
+		"prep s = \"unclosed",
+		"  @ $\n",
+		"",
+	}
+
+	for _, input := range inputs {
+		var b strings.Builder
+		for _, span := range Spans(input) {
+			b.WriteString(span.Text)
+		}
+		assert.Equal(t, input, b.String())
+	}
+}
+
+func TestSpans(t *testing.T) {
+	expected := []Span{
+		{"prep", Keyword}, {" ", Plain}, {"x", Identifier}, {" ", Plain}, {"=", Operator}, {" ", Plain},
+		{`"hi"`, String}, {" ", Plain}, {"# greet", Comment}, {"\n", Plain},
+	}
+	assert.Equal(t, expected, Spans("prep x = \"hi\" # greet\n"))
+}
+
+func TestKeywordMemberNamesAreIdentifiers(t *testing.T) {
+	expected := []Span{
+		{"serve", Keyword}, {" ", Plain}, {"http", Identifier}, {".", Punctuation}, {"serve", Identifier},
+	}
+	assert.Equal(t, expected, Spans("serve http.serve"))
+}
+
+func TestWriteHTML(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, WriteHTML(&b, `io.preach("<b>" + x)`))
+
+	expected := `<pre class="beef"><code>io<span class="beef-punctuation">.</span>preach` +
+		`<span class="beef-punctuation">(</span><span class="beef-string">&#34;&lt;b&gt;&#34;</span> ` +
+		`<span class="beef-operator">+</span> x<span class="beef-punctuation">)</span></code></pre>` + "\n"
+	assert.Equal(t, expected, b.String())
+}
+
+func TestWritePage(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, WritePage(&b, "a<b>.beef", "beef"))
+
+	out := b.String()
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"))
+	assert.Contains(t, out, "<title>a&lt;b&gt;.beef</title>")
+	assert.Contains(t, out, CSS)
+	assert.Contains(t, out, `<span class="beef-keyword">beef</span>`)
+}
+
+func TestWriteANSI(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, WriteANSI(&b, "serve x + 1"))
+
+	assert.Equal(t, "\x1b[1;35mserve\x1b[0m x \x1b[33m+\x1b[0m \x1b[36m1\x1b[0m", b.String())
+}
@@ -0,0 +1,209 @@
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// Conditions are the values '#when' directives test, by name. The command
+// line sets them with --define (debug, platform=windows) and embedders with
+// their options; platform defaults to the operating system the interpreter
+// runs on.
+//
+//	#when debug
+//	io.preach("frame took ", elapsed)
+//	#else
+//	io.preach("ready")
+//	#end
+//
+// A line inside a '#when' whose condition doesn't hold is skipped by the
+// lexer as if it were a comment, so the parser never sees it.
+type Conditions map[string]string
+
+// DirectiveError is a malformed directive. The parser reports it as a
+// syntax error.
+type DirectiveError struct {
+	Token   token.Token // the directive line
+	Message string
+	// Unclosed is set for a #when still open at the end of the input, which
+	// more input could close.
+	Unclosed bool
+}
+
+// SetConditions sets what '#when' directives test. Without it, no name is
+// set, so only the #else branches of '#when name' are kept.
+func (l *Lexer) SetConditions(c Conditions) {
+	l.conditions = c
+}
+
+// DirectiveErrors returns the malformed directives read so far.
+func (l *Lexer) DirectiveErrors() []DirectiveError {
+	return l.directiveErrors
+}
+
+// openWhen is a '#when' block the lexer is inside.
+type openWhen struct {
+	token   token.Token
+	sawElse bool
+}
+
+// directiveWord returns the directive a comment is - "when", "else" or
+// "end" - and the rest of its line, or "" if it is an ordinary comment.
+func directiveWord(comment string) (string, string) {
+	for _, word := range []string{"when", "else", "end"} {
+		rest, ok := strings.CutPrefix(comment, "#"+word)
+		if ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r') {
+			return word, strings.TrimSpace(rest)
+		}
+	}
+	return "", ""
+}
+
+// directive handles a directive line the lexer has just read, skipping the
+// lines after it if they are left out.
+func (l *Lexer) directive(tok token.Token, word, rest string) {
+	l.inactive = append(l.inactive, tok)
+	switch word {
+	case "when":
+		holds, err := l.conditions.holds(rest)
+		if err != nil {
+			l.directiveError(tok, "%v", err)
+		}
+		l.whens = append(l.whens, openWhen{token: tok})
+		if !holds {
+			l.skipInactive()
+		}
+	case "else":
+		if l.elseOf(tok, rest) {
+			// The #when's lines were kept, so the #else's are left out
+			l.skipInactive()
+		}
+	case "end":
+		l.closeWhen(tok, rest)
+	}
+}
+
+// elseOf records an #else for the innermost open #when. It returns false
+// for a second #else, which is misplaced.
+func (l *Lexer) elseOf(tok token.Token, rest string) bool {
+	if rest != "" {
+		l.directiveError(tok, "#else takes no condition: nest a #when inside it instead")
+	}
+	when := &l.whens[len(l.whens)-1]
+	if when.sawElse {
+		l.directiveError(tok, "a #when can only have one #else")
+		return false
+	}
+	when.sawElse = true
+	return true
+}
+
+// closeWhen ends the innermost open #when at an #end.
+func (l *Lexer) closeWhen(tok token.Token, rest string) {
+	if rest != "" {
+		l.directiveError(tok, "#end takes nothing after it")
+	}
+	l.whens = l.whens[:len(l.whens)-1]
+}
+
+// skipInactive skips the lines after a directive up to the #else or #end that
+// matches the innermost open #when, recording them as inactive. Directives
+// in the lines skipped only count for their nesting.
+func (l *Lexer) skipInactive() {
+	depth := 0
+	for !l.atEOF() {
+		l.readChar() // the newline ending the line before
+		for l.ch == ' ' || l.ch == '\t' || l.ch == '\r' {
+			l.readChar()
+		}
+		if l.ch == '\n' || l.atEOF() {
+			continue
+		}
+
+		start := l.offset()
+		l.trimWindow(start)
+		line := token.Token{Type: token.COMMENT, Line: l.line, Column: l.column, Offset: start}
+		l.skipComment()
+		line.Literal = l.text(start, l.offset())
+		line.End = l.endPosition(line, start)
+
+		word, rest := directiveWord(line.Literal)
+		l.inactive = append(l.inactive, line)
+		switch {
+		case word == "when":
+			depth++
+		case word == "end" && depth > 0:
+			depth--
+		case word == "end":
+			l.closeWhen(line, rest)
+			return
+		case word == "else" && depth == 0:
+			if l.elseOf(line, rest) {
+				return // the #when's lines were left out, so the #else's are kept
+			}
+		}
+	}
+}
+
+// checkClosed reports the #when blocks still open at the end of the input.
+func (l *Lexer) checkClosed() {
+	for _, when := range l.whens {
+		l.directiveErrors = append(l.directiveErrors,
+			DirectiveError{Token: when.token, Message: "#when without an #end", Unclosed: true})
+	}
+	l.whens = nil
+}
+
+func (l *Lexer) directiveError(tok token.Token, format string, a ...any) {
+	l.directiveErrors = append(l.directiveErrors, DirectiveError{Token: tok, Message: fmt.Sprintf(format, a...)})
+}
+
+// holds reports whether a '#when' condition is true. A condition is a name,
+// which holds when it is set to anything but "" or "false"; !name, the
+// opposite; or name == "value" or name != "value", comparing the value a
+// name is set to ("" if it isn't).
+func (c Conditions) holds(condition string) (bool, error) {
+	if condition == "" {
+		return false, fmt.Errorf(`#when needs a condition, like #when debug or #when platform == "windows"`)
+	}
+	for _, op := range []string{"==", "!="} {
+		name, value, ok := strings.Cut(condition, op)
+		if !ok {
+			continue
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !isConditionName(name) {
+			return false, fmt.Errorf("%q is not a name to test in #when", name)
+		}
+		unquoted, err := strconv.Unquote(value)
+		if err != nil || !strings.HasPrefix(value, `"`) {
+			return false, fmt.Errorf(`#when compares %s with a quoted string, like %s %s "windows"`, name, name, op)
+		}
+		return (c[name] == unquoted) == (op == "=="), nil
+	}
+
+	name, negated := strings.CutPrefix(condition, "!")
+	name = strings.TrimSpace(name)
+	if !isConditionName(name) {
+		return false, fmt.Errorf("%q is not a #when condition: use a name, !name, name == \"value\" or name != \"value\"", condition)
+	}
+	set := c[name] != "" && c[name] != "false"
+	return set != negated, nil
+}
+
+// isConditionName reports whether s can name a condition: letters, digits
+// and underscores, not starting with a digit.
+func isConditionName(s string) bool {
+	if s == "" || isDigit(s[0]) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isLetter(s[i]) && !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,13 @@
 package lexer
 
-import "github.com/elitwilson/beeflang/internal/token"
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/token"
+)
 
 // Lexer performs lexical analysis (tokenization) on source code.
 // Lexical analysis is the first phase of an interpreter/compiler - it reads
@@ -13,34 +20,85 @@ import "github.com/elitwilson/beeflang/internal/token"
 // - position: points to the current character being examined
 // - readPosition: points to the next character (lookahead for multi-char tokens like "==")
 //
+// Source is read from an io.Reader a byte at a time, so multi-megabyte files
+// are never held in memory whole: the lexer only keeps the bytes of the token
+// it is scanning (see window).
+//
 // Position tracking (line/column) is maintained throughout for error reporting.
 // When we encounter a syntax error later, we can say "error at line 5, column 12"
 // instead of just "syntax error somewhere".
 type Lexer struct {
-	input        string // the entire source code as a string
-	position     int    // current position in input (current char)
-	readPosition int    // next reading position (lookahead position)
-	ch           byte   // current character under examination
-	line         int    // current line number (starts at 1)
-	column       int    // current column number (starts at 1)
+	input        *bufio.Reader
+	position     int  // current position in input (current char)
+	readPosition int  // next reading position (lookahead position)
+	ch           byte // current character under examination
+	line         int  // current line number (starts at 1)
+	column       int  // current column number (starts at 1)
+
+	// window holds the input from offset windowStart up to readPosition (plus
+	// the peeked byte, if any). It is trimmed at the start of every token, so
+	// it only ever holds the token being scanned.
+	window      []byte
+	windowStart int
+	size        int   // length of the input once the reader is exhausted, -1 before
+	err         error // read error other than io.EOF, if any
+
+	comments []token.Token // every comment skipped so far, in source order
+
+	// '#when' directives (see Conditions)
+	conditions      Conditions
+	lastLine        int // the line the last token ended on: a directive must start its line
+	whens           []openWhen
+	inactive        []token.Token
+	directiveErrors []DirectiveError
 }
 
-// New creates a new Lexer instance and initializes it by reading the first character
+// New creates a new Lexer instance for source held in a string
 func New(input string) *Lexer {
+	return NewReader(strings.NewReader(input))
+}
+
+// NewReader creates a Lexer that reads its source from r as it goes, with
+// buffered lookahead. Positions (line, column and byte offset) are the same
+// as for the same source passed to New. A read error ends the input early;
+// it is reported by Err.
+func NewReader(r io.Reader) *Lexer {
 	l := &Lexer{
-		input:  input,
+		input:  bufio.NewReader(r),
 		line:   1,
 		column: 0,
+		size:   -1,
 	}
 	l.readChar() // Initialize by reading first character
 	return l
 }
 
-// NextToken reads the next token from the input and returns it
+// Err returns the error that stopped reading the source early, or nil if the
+// whole input was read (or hasn't been yet).
+func (l *Lexer) Err() error {
+	return l.err
+}
+
+// NextToken reads the next token from the input and returns it,
+// with its start offset and end position filled in.
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
+	l.skipWhitespaceAndComments()
 
-	l.skipWhitespace()
+	start := l.offset()
+	l.trimWindow(start)
+	tok := l.scanToken()
+	tok.Offset = start
+	tok.End = l.endPosition(tok, start)
+	l.lastLine = tok.End.Line
+	if tok.Type == token.EOF {
+		l.checkClosed()
+	}
+	return tok
+}
+
+// scanToken reads the token starting at the current character.
+func (l *Lexer) scanToken() token.Token {
+	var tok token.Token
 
 	// Capture current position for this token
 	tok.Line = l.line
@@ -58,7 +116,13 @@ func (l *Lexer) NextToken() token.Token {
 	case '+':
 		tok = l.newToken(token.PLUS, l.ch)
 	case '-':
-		tok = l.newToken(token.MINUS, l.ch)
+		if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: string(ch) + string(l.ch), Line: tok.Line, Column: tok.Column}
+		} else {
+			tok = l.newToken(token.MINUS, l.ch)
+		}
 	case '*':
 		tok = l.newToken(token.ASTERISK, l.ch)
 	case '/':
@@ -102,6 +166,10 @@ func (l *Lexer) NextToken() token.Token {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.OR, Literal: string(ch) + string(l.ch), Line: tok.Line, Column: tok.Column}
+		} else if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.PIPE, Literal: string(ch) + string(l.ch), Line: tok.Line, Column: tok.Column}
 		} else {
 			tok = l.newToken(token.ILLEGAL, l.ch)
 		}
@@ -109,20 +177,40 @@ func (l *Lexer) NextToken() token.Token {
 		tok = l.newToken(token.LPAREN, l.ch)
 	case ')':
 		tok = l.newToken(token.RPAREN, l.ch)
+	case '[':
+		tok = l.newToken(token.LBRACKET, l.ch)
+	case ']':
+		tok = l.newToken(token.RBRACKET, l.ch)
+	case '{':
+		tok = l.newToken(token.LBRACE, l.ch)
+	case '}':
+		tok = l.newToken(token.RBRACE, l.ch)
 	case ':':
 		tok = l.newToken(token.COLON, l.ch)
 	case ',':
 		tok = l.newToken(token.COMMA, l.ch)
+	case '@':
+		tok = l.newToken(token.AT, l.ch)
 	case '.':
 		tok = l.newToken(token.DOT, l.ch)
+	case '?':
+		if l.peekChar() == '.' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.OPT_DOT, Literal: string(ch) + string(l.ch), Line: tok.Line, Column: tok.Column}
+		} else {
+			tok = l.newToken(token.ILLEGAL, l.ch)
+		}
 	case '"':
 		tok.Type = token.STRING
 		tok.Literal = l.readString()
 		return tok // Early return
-	case '#':
-		l.skipComment()
-		return l.NextToken() // Recursively get next token after comment
 	case 0:
+		if !l.atEOF() {
+			// A NUL byte in the source, not the end of input
+			tok = l.newToken(token.ILLEGAL, l.ch)
+			break
+		}
 		tok.Literal = ""
 		tok.Type = token.EOF
 	default:
@@ -133,6 +221,12 @@ func (l *Lexer) NextToken() token.Token {
 		} else if isDigit(l.ch) {
 			tok.Type = token.INT
 			tok.Literal = l.readNumber()
+			// An n straight after the digits makes a big integer: 123n
+			if l.ch == 'n' && !isLetter(l.peekChar()) && !isDigit(l.peekChar()) {
+				l.readChar()
+				tok.Type = token.BIGINT
+				tok.Literal += "n"
+			}
 			return tok // Early return - readNumber already advanced
 		} else {
 			tok = l.newToken(token.ILLEGAL, l.ch)
@@ -145,10 +239,10 @@ func (l *Lexer) NextToken() token.Token {
 
 // readChar advances the lexer position and reads the next character
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
-		l.ch = 0 // ASCII NUL - signals EOF
+	if ch, ok := l.byteAt(l.readPosition); ok {
+		l.ch = ch
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch = 0 // ASCII NUL - signals EOF
 	}
 	l.position = l.readPosition
 	l.readPosition++
@@ -163,10 +257,44 @@ func (l *Lexer) readChar() {
 
 // peekChar looks ahead at the next character without advancing position
 func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
-		return 0
+	ch, _ := l.byteAt(l.readPosition)
+	return ch
+}
+
+// byteAt returns the input byte at offset, reading it into the window if it
+// hasn't been read yet. Offsets are only ever requested in order, at most one
+// past the last byte read, so a single read is always enough.
+func (l *Lexer) byteAt(offset int) (byte, bool) {
+	if i := offset - l.windowStart; i < len(l.window) {
+		return l.window[i], true
+	}
+	if l.size >= 0 {
+		return 0, false
+	}
+	ch, err := l.input.ReadByte()
+	if err != nil {
+		if err != io.EOF {
+			l.err = err
+		}
+		l.size = l.windowStart + len(l.window)
+		return 0, false
+	}
+	l.window = append(l.window, ch)
+	return ch, true
+}
+
+// text returns the input between two offsets; both must still be in the window.
+func (l *Lexer) text(start, end int) string {
+	return string(l.window[start-l.windowStart : end-l.windowStart])
+}
+
+// trimWindow forgets the input before offset, which no token will need again.
+// Only the current and peeked bytes remain at that point, so the copy is tiny.
+func (l *Lexer) trimWindow(offset int) {
+	if n := offset - l.windowStart; n > 0 {
+		l.window = append(l.window[:0], l.window[n:]...)
+		l.windowStart = offset
 	}
-	return l.input[l.readPosition]
 }
 
 // readIdentifier reads an identifier or keyword (letters, underscores, and digits)
@@ -176,7 +304,7 @@ func (l *Lexer) readIdentifier() string {
 	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return l.text(position, l.position)
 }
 
 // readNumber reads an integer literal
@@ -185,7 +313,7 @@ func (l *Lexer) readNumber() string {
 	for isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return l.text(position, l.position)
 }
 
 // skipWhitespace skips over whitespace characters (space, tab, newline, carriage return)
@@ -195,25 +323,108 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
+// skipWhitespaceAndComments skips everything between tokens.
+// Comments are recorded on the side so tools like the formatter can keep them.
+// A comment starting its line with #when is a directive, as are #else and
+// #end inside a #when block; outside one they are ordinary comments.
+func (l *Lexer) skipWhitespaceAndComments() {
+	l.skipWhitespace()
+	for l.ch == '#' {
+		start := l.offset()
+		l.trimWindow(start)
+		comment := token.Token{Type: token.COMMENT, Line: l.line, Column: l.column, Offset: start}
+		l.skipComment()
+		comment.Literal = l.text(start, l.offset())
+		comment.End = l.endPosition(comment, start)
+		if word, rest := directiveWord(comment.Literal); word != "" && comment.Line > l.lastLine && (word == "when" || len(l.whens) > 0) {
+			l.directive(comment, word, rest)
+		} else {
+			l.comments = append(l.comments, comment)
+		}
+
+		l.skipWhitespace()
+	}
+}
+
+// Comments returns the comments read so far (the whole file's once NextToken
+// has returned EOF). Each literal includes the leading '#'.
+func (l *Lexer) Comments() []token.Token {
+	return l.comments
+}
+
+// AllTokens reads every remaining token, up to and including EOF, with the
+// comments merged in where they occur (and the directive lines and the code
+// they left out, as comments). Tools that show the source as written
+// (token dumps, syntax highlighting) want comments as much as the code.
+func (l *Lexer) AllTokens() []token.Token {
+	var tokens []token.Token
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+	tokens = append(tokens, l.comments...)
+	tokens = append(tokens, l.inactive...)
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].Offset < tokens[j].Offset
+	})
+	return tokens
+}
+
+// offset returns the byte offset of the current character,
+// clamped to the end of the input once EOF has been reached.
+func (l *Lexer) offset() int {
+	if l.size >= 0 && l.position > l.size {
+		return l.size
+	}
+	return l.position
+}
+
+// endPosition works out where a token that started at byte offset start ends,
+// now that the lexer has moved past it. The end is exclusive: for "prep" at
+// column 1 it is column 5. Counting through the token's own text keeps this
+// right for string literals that span lines.
+func (l *Lexer) endPosition(tok token.Token, start int) token.Position {
+	end := l.offset()
+	text := l.text(start, end)
+
+	pos := token.Position{Line: tok.Line, Column: tok.Column + len(text), Offset: end}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			pos.Line++
+			pos.Column = len(text) - i
+		}
+	}
+	return pos
+}
+
 // skipComment skips from '#' to the end of the line
 func (l *Lexer) skipComment() {
-	for l.ch != '\n' && l.ch != 0 {
+	for l.ch != '\n' && !l.atEOF() {
 		l.readChar()
 	}
 }
 
+// atEOF reports whether the whole input has been read. l.ch is also 0 for a
+// NUL byte in the input, so it can't tell the two apart on its own.
+func (l *Lexer) atEOF() bool {
+	return l.size >= 0 && l.position >= l.size
+}
+
 // readString reads a string literal (content between quotes, without the quotes)
 func (l *Lexer) readString() string {
 	// Move past the opening quote
 	l.readChar()
 
 	position := l.position
-	for l.ch != '"' && l.ch != 0 {
+	for l.ch != '"' && !l.atEOF() {
 		l.readChar()
 	}
 
 	// Extract the string content (without quotes)
-	str := l.input[position:l.position]
+	str := l.text(position, l.position)
 
 	// Move past the closing quote (if we found one)
 	if l.ch == '"' {
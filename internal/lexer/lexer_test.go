@@ -1,7 +1,14 @@
 package lexer
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/elitwilson/beeflang/internal/token"
 	"github.com/stretchr/testify/assert"
@@ -23,6 +30,32 @@ func TestTokenizeIntegers(t *testing.T) {
 	assert.Equal(t, token.EOF, tok.Type)
 }
 
+func TestTokenizeBigIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.Token
+	}{
+		{"123n", []token.Token{{Type: token.BIGINT, Literal: "123n"}}},
+		{"99999999999999999999n+1", []token.Token{
+			{Type: token.BIGINT, Literal: "99999999999999999999n"},
+			{Type: token.PLUS, Literal: "+"},
+			{Type: token.INT, Literal: "1"},
+		}},
+		// The n has to end the number
+		{"12nx", []token.Token{{Type: token.INT, Literal: "12"}, {Type: token.IDENT, Literal: "nx"}}},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		for _, expected := range tt.expected {
+			tok := l.NextToken()
+			assert.Equal(t, expected.Type, tok.Type, tt.input)
+			assert.Equal(t, expected.Literal, tok.Literal, tt.input)
+		}
+		assert.Equal(t, token.EOF, l.NextToken().Type, tt.input)
+	}
+}
+
 func TestTokenizeStringLiterals(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -147,6 +180,32 @@ func TestTokenizeWrangleKeyword(t *testing.T) {
 	assert.Equal(t, token.EOF, tok.Type)
 }
 
+func TestTokenizeWrangleAliasAndExpose(t *testing.T) {
+	input := "wrangle io as out expose preach, input"
+	l := New(input)
+
+	expectedTokens := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.WRANGLE, "wrangle"},
+		{token.IDENT, "io"},
+		{token.AS, "as"},
+		{token.IDENT, "out"},
+		{token.EXPOSE, "expose"},
+		{token.IDENT, "preach"},
+		{token.COMMA, ","},
+		{token.IDENT, "input"},
+		{token.EOF, ""},
+	}
+
+	for i, expected := range expectedTokens {
+		tok := l.NextToken()
+		assert.Equal(t, expected.expectedType, tok.Type, "token %d type mismatch", i)
+		assert.Equal(t, expected.expectedLiteral, tok.Literal, "token %d literal mismatch", i)
+	}
+}
+
 func TestTokenizeHerdKeyword(t *testing.T) {
 	input := "herd"
 	l := New(input)
@@ -376,3 +435,366 @@ func TestEOFToken(t *testing.T) {
 	tok := l.NextToken()
 	assert.Equal(t, token.EOF, tok.Type)
 }
+
+func TestTokenizeArrow(t *testing.T) {
+	input := "-> - >"
+	l := New(input)
+
+	expected := []token.TokenType{token.ARROW, token.MINUS, token.GT, token.EOF}
+	for _, tt := range expected {
+		tok := l.NextToken()
+		assert.Equal(t, tt, tok.Type)
+	}
+}
+
+func TestTokenizePipe(t *testing.T) {
+	l := New("|> || |")
+
+	expected := []token.TokenType{token.PIPE, token.OR, token.ILLEGAL, token.EOF}
+	for _, tt := range expected {
+		tok := l.NextToken()
+		assert.Equal(t, tt, tok.Type)
+	}
+}
+
+func TestTokenizeDecorator(t *testing.T) {
+	l := New("@cache.memoize\npraise f():")
+
+	expected := []token.TokenType{token.AT, token.IDENT, token.DOT, token.IDENT, token.PRAISE, token.IDENT,
+		token.LPAREN, token.RPAREN, token.COLON, token.EOF}
+	for _, tt := range expected {
+		tok := l.NextToken()
+		assert.Equal(t, tt, tok.Type)
+	}
+}
+
+func TestTokenizeOptionalDot(t *testing.T) {
+	l := New("a?.b . ?")
+
+	expected := []token.TokenType{token.IDENT, token.OPT_DOT, token.IDENT, token.DOT, token.ILLEGAL, token.EOF}
+	for _, tt := range expected {
+		tok := l.NextToken()
+		assert.Equal(t, tt, tok.Type)
+	}
+}
+
+func TestTokenOffsetsAndEnds(t *testing.T) {
+	input := "prep name = \"Beef\" # comment\n  x >= 10"
+	l := New(input)
+
+	tests := []struct {
+		literal string
+		offset  int
+		end     token.Position
+	}{
+		{"prep", 0, token.Position{Line: 1, Column: 5, Offset: 4}},
+		{"name", 5, token.Position{Line: 1, Column: 10, Offset: 9}},
+		{"=", 10, token.Position{Line: 1, Column: 12, Offset: 11}},
+		{"Beef", 12, token.Position{Line: 1, Column: 19, Offset: 18}}, // includes the quotes
+		{"x", 31, token.Position{Line: 2, Column: 4, Offset: 32}},
+		{">=", 33, token.Position{Line: 2, Column: 7, Offset: 35}},
+		{"10", 36, token.Position{Line: 2, Column: 10, Offset: 38}},
+		{"", 38, token.Position{Line: 2, Column: 10, Offset: 38}}, // EOF is empty
+	}
+
+	for _, tt := range tests {
+		tok := l.NextToken()
+		assert.Equal(t, tt.literal, tok.Literal)
+		assert.Equal(t, tt.offset, tok.Offset, "offset of %q", tt.literal)
+		assert.Equal(t, tt.end, tok.End, "end of %q", tt.literal)
+	}
+
+	// Reading past EOF keeps returning an empty EOF token at the end of the input
+	tok := l.NextToken()
+	assert.Equal(t, token.EOF, tok.Type)
+	assert.Equal(t, len(input), tok.Offset)
+}
+
+func TestMultilineStringEnd(t *testing.T) {
+	l := New("\"one\ntwo\" x")
+
+	tok := l.NextToken()
+	assert.Equal(t, token.STRING, tok.Type)
+	assert.Equal(t, token.Position{Line: 2, Column: 5, Offset: 9}, tok.End)
+}
+
+func TestCommentsAreCollected(t *testing.T) {
+	input := "# header\nprep x = 1 # trailing\n#last"
+	l := New(input)
+
+	// Comments never show up in the token stream
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		assert.NotEqual(t, token.COMMENT, tok.Type)
+	}
+
+	comments := l.Comments()
+	assert.Len(t, comments, 3)
+	assert.Equal(t, "# header", comments[0].Literal)
+	assert.Equal(t, token.COMMENT, comments[0].Type)
+	assert.Equal(t, 1, comments[0].Line)
+	assert.Equal(t, "# trailing", comments[1].Literal)
+	assert.Equal(t, 2, comments[1].Line)
+	assert.Equal(t, 12, comments[1].Column)
+	assert.Equal(t, 11+9, comments[1].Offset)
+	assert.Equal(t, "#last", comments[2].Literal)
+	assert.Equal(t, len(input), comments[2].End.Offset)
+}
+
+func TestAllTokensMergesComments(t *testing.T) {
+	tokens := New("# header\nprep x = 1 # trailing\n#last").AllTokens()
+
+	var types []token.TokenType
+	for _, tok := range tokens {
+		types = append(types, tok.Type)
+	}
+	expected := []token.TokenType{
+		token.COMMENT, token.PREP, token.IDENT, token.ASSIGN, token.INT, token.COMMENT, token.COMMENT, token.EOF,
+	}
+	assert.Equal(t, expected, types)
+	assert.Equal(t, "# trailing", tokens[5].Literal)
+}
+
+func TestNulByteIsIllegalNotEOF(t *testing.T) {
+	l := New("a\x00b")
+
+	assert.Equal(t, token.IDENT, l.NextToken().Type)
+	assert.Equal(t, token.ILLEGAL, l.NextToken().Type)
+	b := l.NextToken()
+	assert.Equal(t, token.IDENT, b.Type)
+	assert.Equal(t, "b", b.Literal)
+	assert.Equal(t, token.EOF, l.NextToken().Type)
+}
+
+func TestNulByteInsideStringAndComment(t *testing.T) {
+	l := New("\"a\x00b\" # c\x00d\nx")
+
+	str := l.NextToken()
+	assert.Equal(t, token.STRING, str.Type)
+	assert.Equal(t, "a\x00b", str.Literal)
+	assert.Equal(t, "x", l.NextToken().Literal)
+	assert.Equal(t, "# c\x00d", l.Comments()[0].Literal)
+}
+
+// ========================================
+// Streaming Input
+// ========================================
+
+// lexAll returns every token up to and including EOF
+func lexAll(l *Lexer) []token.Token {
+	var tokens []token.Token
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+		if tok.Type == token.EOF {
+			return tokens
+		}
+	}
+}
+
+func TestNewReaderMatchesNew(t *testing.T) {
+	paths, _ := filepath.Glob("../../examples/*.beef")
+	assert.NotEmpty(t, paths)
+	paths = append(paths, "") // plus the inline source below
+
+	for _, path := range paths {
+		source := "prep s = \"multi\nline\" # comment\nprep x = 1 -> y != 2 # last"
+		if path != "" {
+			data, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			source = string(data)
+		}
+
+		expected := New(source)
+		// OneByteReader makes every read return a single byte, exercising the lookahead
+		streamed := NewReader(iotest.OneByteReader(strings.NewReader(source)))
+
+		assert.Equal(t, lexAll(expected), lexAll(streamed), path)
+		assert.Equal(t, expected.Comments(), streamed.Comments(), path)
+		assert.NoError(t, streamed.Err())
+	}
+}
+
+func TestNewReaderLargeInput(t *testing.T) {
+	const lines = 100000
+	var b strings.Builder
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&b, "prep beef_%d = %d # cut %d\n", i, i, i)
+	}
+
+	l := NewReader(strings.NewReader(b.String()))
+	count := 0
+	var last token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		count++
+		last = tok
+		// Only the token being scanned is kept in memory
+		assert.LessOrEqual(t, len(l.window), 16)
+	}
+
+	assert.Equal(t, lines*4, count)
+	assert.Equal(t, lines, last.Line)
+	assert.Equal(t, fmt.Sprint(lines-1), last.Literal)
+	assert.Len(t, l.Comments(), lines)
+}
+
+func TestNewReaderReportsReadErrors(t *testing.T) {
+	failing := io.MultiReader(strings.NewReader("prep x = 1\n"), iotest.ErrReader(errors.New("disk on fire")))
+	l := NewReader(failing)
+
+	tokens := lexAll(l)
+	// The input read before the error is still lexed
+	assert.Len(t, tokens, 5)
+	assert.EqualError(t, l.Err(), "disk on fire")
+}
+
+// ========================================
+// Fuzzing
+// ========================================
+
+// FuzzLexer feeds arbitrary bytes to the lexer: it must never panic, must
+// always reach EOF, every token must lie within the input, and streaming the
+// input must give the same tokens as lexing it from a string.
+// Run with: go test ./internal/lexer -fuzz=FuzzLexer
+func FuzzLexer(f *testing.F) {
+	seeds, _ := filepath.Glob("../../examples/*.beef")
+	for _, path := range seeds {
+		if source, err := os.ReadFile(path); err == nil {
+			f.Add(string(source))
+		}
+	}
+	f.Add("prep s = \"unterminated")
+	f.Add("# only a comment")
+	f.Add("x\x00y")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		l := New(input)
+		// Every token consumes at least one byte, so EOF must come within len+1 tokens
+		for i := 0; i <= len(input)+1; i++ {
+			tok := l.NextToken()
+			if tok.Offset < 0 || tok.End.Offset > len(input) || tok.Offset > tok.End.Offset {
+				t.Fatalf("token %v has offsets [%d, %d) outside input of length %d",
+					tok.Type, tok.Offset, tok.End.Offset, len(input))
+			}
+			if tok.Type == token.EOF {
+				streamed := NewReader(iotest.OneByteReader(strings.NewReader(input)))
+				assert.Equal(t, lexAll(New(input)), lexAll(streamed))
+				return
+			}
+		}
+		t.Fatalf("lexer did not reach EOF")
+	})
+}
+
+// ========================================
+// Directives
+// ========================================
+
+// identifiers returns the names of the IDENT tokens l reads, in order.
+func identifiers(l *Lexer) []string {
+	var names []string
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		if tok.Type == token.IDENT {
+			names = append(names, tok.Literal)
+		}
+	}
+	return names
+}
+
+func TestWhenDirectives(t *testing.T) {
+	input := "a\n#when debug\nb\n  #when level == \"2\"\n  c\n  #else\n  d\n  #end\n#else\ne\n#end\nf # #when debug\n"
+	tests := []struct {
+		conditions Conditions
+		expected   []string
+	}{
+		{nil, []string{"a", "e", "f"}},
+		{Conditions{"debug": "false"}, []string{"a", "e", "f"}},
+		{Conditions{"debug": "true"}, []string{"a", "b", "d", "f"}},
+		{Conditions{"debug": "1", "level": "2"}, []string{"a", "b", "c", "f"}},
+	}
+
+	for _, tt := range tests {
+		l := New(input)
+		l.SetConditions(tt.conditions)
+		assert.Equal(t, tt.expected, identifiers(l), "conditions %v", tt.conditions)
+		assert.Empty(t, l.DirectiveErrors())
+		// A directive after code on its line is an ordinary comment
+		assert.Equal(t, "# #when debug", l.Comments()[len(l.Comments())-1].Literal)
+	}
+}
+
+func TestWhenConditions(t *testing.T) {
+	conditions := Conditions{"platform": "linux", "debug": "true"}
+	tests := map[string]bool{
+		"debug":                 true,
+		"!debug":                false,
+		"trace":                 false,
+		"! trace":               true,
+		`platform == "linux"`:   true,
+		`platform != "linux"`:   false,
+		`platform == "windows"`: false,
+		`trace == ""`:           true,
+	}
+
+	for condition, expected := range tests {
+		l := New("#when " + condition + "\nyes\n#end\n")
+		l.SetConditions(conditions)
+		assert.Equal(t, expected, len(identifiers(l)) == 1, "#when %s", condition)
+	}
+}
+
+func TestMalformedDirectives(t *testing.T) {
+	tests := []struct {
+		input    string
+		message  string
+		unclosed bool
+	}{
+		{"#when\n#end", "#when needs a condition", false},
+		{"#when 2x\n#end", "is not a #when condition", false},
+		{"#when platform == linux\n#end", "quoted string", false},
+		{"#when debug\n#else\n#else\n#end", "only have one #else", false},
+		{"#when debug\n#end debug", "#end takes nothing after it", false},
+		{"a\n#when debug\nb", "#when without an #end", true},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		identifiers(l)
+		errs := l.DirectiveErrors()
+		if len(errs) != 1 {
+			t.Fatalf("input %q: expected 1 directive error, got %v", tt.input, errs)
+		}
+		assert.Contains(t, errs[0].Message, tt.message, "input %q", tt.input)
+		assert.Equal(t, tt.unclosed, errs[0].Unclosed, "input %q", tt.input)
+	}
+}
+
+func TestElseAndEndOutsideWhenAreComments(t *testing.T) {
+	tests := []struct {
+		input   string
+		comment string
+	}{
+		{"#else\na", "#else"},
+		{"#end\na", "#end"},
+		{"#end of imports\na", "#end of imports"},
+		{"#when debug\n#end\n#end of imports\na", "#end of imports"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		assert.Equal(t, []string{"a"}, identifiers(l), "input %q", tt.input)
+		assert.Empty(t, l.DirectiveErrors(), "input %q", tt.input)
+		comments := l.Comments()
+		if assert.NotEmpty(t, comments, "input %q", tt.input) {
+			assert.Equal(t, tt.comment, comments[len(comments)-1].Literal, "input %q", tt.input)
+		}
+	}
+}
+
+func TestAllTokensShowsInactiveLinesAsComments(t *testing.T) {
+	var types []token.TokenType
+	for _, tok := range New("#when debug\nprep x = 1\n#end\ny").AllTokens() {
+		types = append(types, tok.Type)
+	}
+	expected := []token.TokenType{token.COMMENT, token.COMMENT, token.COMMENT, token.IDENT, token.EOF}
+	assert.Equal(t, expected, types)
+}
@@ -0,0 +1,170 @@
+package manifest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// LockFileName is the name of the file, next to the manifest, that pins each
+// dependency to the exact code installed: the commit its version resolved to
+// and a hash of its files. Install checks new fetches against it, and Verify
+// checks beef_modules before a program runs, so everyone working on a project
+// runs the same dependency code.
+const LockFileName = "beef.lock"
+
+const lockHeader = "# Written by `beeflang install` and `beeflang get`; commit it, and don't edit it.\n\n"
+
+// Lock is a project's beef.lock.
+type Lock struct {
+	Packages []LockedPackage `toml:"package"`
+}
+
+// LockedPackage is what a dependency was resolved to when it was installed.
+type LockedPackage struct {
+	Name    string `toml:"name"`
+	Git     string `toml:"git"`
+	Version string `toml:"version"`
+	Commit  string `toml:"commit"` // the commit the version named
+	Hash    string `toml:"hash"`   // of the installed files; see HashDir
+}
+
+// LoadLock reads the lock file at path. A lock file that doesn't exist is
+// an empty Lock.
+func LoadLock(path string) (*Lock, error) {
+	var lock Lock
+	if _, err := toml.DecodeFile(path, &lock); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &Lock{}, nil
+		}
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &lock, nil
+}
+
+// Write writes the lock file to path, packages in name order.
+func (l *Lock) Write(path string) error {
+	sort.Slice(l.Packages, func(i, j int) bool { return l.Packages[i].Name < l.Packages[j].Name })
+	var buf bytes.Buffer
+	buf.WriteString(lockHeader)
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(l); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Find returns the locked package called name, or nil.
+func (l *Lock) Find(name string) *LockedPackage {
+	for i := range l.Packages {
+		if l.Packages[i].Name == name {
+			return &l.Packages[i]
+		}
+	}
+	return nil
+}
+
+// set records a package, replacing any entry with its name.
+func (l *Lock) set(pkg LockedPackage) {
+	if existing := l.Find(pkg.Name); existing != nil {
+		*existing = pkg
+		return
+	}
+	l.Packages = append(l.Packages, pkg)
+}
+
+// Prune drops the packages the manifest no longer lists.
+func (l *Lock) Prune(m *Manifest) {
+	kept := l.Packages[:0]
+	for _, pkg := range l.Packages {
+		if _, ok := m.Dependencies[pkg.Name]; ok {
+			kept = append(kept, pkg)
+		}
+	}
+	l.Packages = kept
+}
+
+// HashDir hashes the files under dir: their paths, relative to dir, and
+// their contents. Two directories hash the same exactly when they hold the
+// same files.
+func HashDir(dir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	// Each file is a line of its own hash and path, and the lines are hashed
+	// together, so no file's contents can pass for another's path
+	summary := sha256.New()
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		var data []byte
+		info, err := os.Lstat(path)
+		if err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			var target string
+			target, err = os.Readlink(path)
+			data = []byte("symlink " + target)
+		} else if err == nil {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(summary, "%x  %s\n", sum, file)
+	}
+	return "sha256:" + hex.EncodeToString(summary.Sum(nil)), nil
+}
+
+// Verify checks that every dependency is installed in beef_modules as
+// beef.lock records it, and reports the first that isn't.
+func (m *Manifest) Verify() error {
+	if len(m.Dependencies) == 0 {
+		return nil
+	}
+	lock, err := LoadLock(filepath.Join(m.Dir, LockFileName))
+	if err != nil {
+		return err
+	}
+	for _, name := range m.DependencyNames() {
+		dep := m.Dependencies[name]
+		pkg := lock.Find(name)
+		if pkg == nil || pkg.Git != dep.Git || pkg.Version != dep.Version {
+			return fmt.Errorf("%s %s isn't in %s: run `beeflang install`", name, dep.Version, LockFileName)
+		}
+		dir := filepath.Join(m.Dir, VendorDir, name)
+		if _, err := os.Stat(dir); err != nil {
+			return fmt.Errorf("%s isn't installed in %s: run `beeflang install`", name, VendorDir)
+		}
+		hash, err := HashDir(dir)
+		if err != nil {
+			return err
+		}
+		if hash != pkg.Hash {
+			return fmt.Errorf("%s doesn't match %s: its files were changed; run `beeflang install` to restore them",
+				filepath.Join(VendorDir, name), LockFileName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+	lock, err := LoadLock(path)
+	assert.NoError(t, err)
+	assert.Empty(t, lock.Packages, "a missing lock file is empty")
+
+	lock.set(LockedPackage{Name: "pathfinding", Git: "https://example.com/pf", Version: "v1", Commit: "abc", Hash: "sha256:1"})
+	lock.set(LockedPackage{Name: "dialog", Git: "https://example.com/dialog", Version: "v2", Commit: "def", Hash: "sha256:2"})
+	lock.set(LockedPackage{Name: "pathfinding", Git: "https://example.com/pf", Version: "v1.1", Commit: "123", Hash: "sha256:3"})
+	assert.NoError(t, lock.Write(path))
+
+	read, err := LoadLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []LockedPackage{
+		{Name: "dialog", Git: "https://example.com/dialog", Version: "v2", Commit: "def", Hash: "sha256:2"},
+		{Name: "pathfinding", Git: "https://example.com/pf", Version: "v1.1", Commit: "123", Hash: "sha256:3"},
+	}, read.Packages)
+
+	read.Prune(&Manifest{Dependencies: map[string]Dependency{"dialog": {}}})
+	assert.Len(t, read.Packages, 1)
+	assert.Nil(t, read.Find("pathfinding"))
+}
+
+func TestHashDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "lib"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "lib", "a.beef"), []byte("prep a = 1"), 0o644))
+	first, err := HashDir(dir)
+	assert.NoError(t, err)
+
+	// The same files elsewhere hash the same
+	other := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(other, "lib"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(other, "lib", "a.beef"), []byte("prep a = 1"), 0o644))
+	same, _ := HashDir(other)
+	assert.Equal(t, first, same)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "lib", "a.beef"), []byte("prep a = 2"), 0o644))
+	changed, _ := HashDir(dir)
+	assert.NotEqual(t, first, changed)
+
+	assert.NoError(t, os.Rename(filepath.Join(other, "lib", "a.beef"), filepath.Join(other, "lib", "b.beef")))
+	renamed, _ := HashDir(other)
+	assert.NotEqual(t, first, renamed)
+}
+
+func TestInstallChecksTheLock(t *testing.T) {
+	url, git := packageRepo(t)
+	project := t.TempDir()
+	dep := Dependency{Git: url, Version: "v1.0.0"}
+	lock := &Lock{}
+	assert.NoError(t, Install(project, "pathfinding", dep, lock))
+	locked := *lock.Find("pathfinding")
+
+	// Installing again from the lock gets the same code
+	assert.NoError(t, Install(project, "pathfinding", dep, lock))
+	assert.Equal(t, locked, *lock.Find("pathfinding"))
+
+	// A tag moved to other code is refused, and the installed files stay
+	writePackageFile(t, url[len("file://"):], "prep version = 2")
+	git("commit", "--quiet", "-am", "v2")
+	git("tag", "--force", "v1.0.0")
+	err := Install(project, "pathfinding", dep, lock)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "the version was moved since it was locked")
+	}
+	assert.Equal(t, locked, *lock.Find("pathfinding"))
+	source, _ := os.ReadFile(filepath.Join(project, VendorDir, "pathfinding", "pathfinding.beef"))
+	assert.Equal(t, "prep version = 1", string(source))
+
+	// Without the lock (install --update) the new code is taken
+	assert.NoError(t, Install(project, "pathfinding", dep, &Lock{}))
+}
+
+func TestVerify(t *testing.T) {
+	url, _ := packageRepo(t)
+	project := t.TempDir()
+	path := writeManifest(t, project, "[dependencies]\npathfinding = { git = \""+url+"\", version = \"v1.0.0\" }\n")
+	m, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Verify()
+	if assert.Error(t, err) {
+		assert.Equal(t, "pathfinding v1.0.0 isn't in beef.lock: run `beeflang install`", err.Error())
+	}
+
+	lock := &Lock{}
+	assert.NoError(t, Install(project, "pathfinding", m.Dependencies["pathfinding"], lock))
+	assert.NoError(t, lock.Write(filepath.Join(project, LockFileName)))
+	assert.NoError(t, m.Verify())
+
+	writePackageFile(t, filepath.Join(project, VendorDir, "pathfinding"), "prep version = 99")
+	err = m.Verify()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "doesn't match beef.lock: its files were changed")
+	}
+
+	assert.NoError(t, os.RemoveAll(filepath.Join(project, VendorDir)))
+	err = m.Verify()
+	if assert.Error(t, err) {
+		assert.Equal(t, "pathfinding isn't installed in beef_modules: run `beeflang install`", err.Error())
+	}
+
+	// A version edited in beef.toml needs installing
+	m.Dependencies["pathfinding"] = Dependency{Git: url, Version: "v2.0.0"}
+	err = m.Verify()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "pathfinding v2.0.0 isn't in beef.lock")
+	}
+}
@@ -0,0 +1,275 @@
+// Package manifest reads beef.toml, the file at the root of a Beeflang
+// project that names it, says which file it runs, and lists the packages it
+// depends on:
+//
+//	name = "mygame"
+//	entry = "main.beef"
+//
+//	[dependencies]
+//	pathfinding = { git = "https://github.com/someone/pathfinding", version = "v1.2.0" }
+//
+// Dependencies are fetched with git into beef_modules/<name> next to the
+// manifest (see Install) and pinned in beef.lock (see Lock), and each of those
+// directories joins the module search path, so a program wrangles a
+// package's modules by name.
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	// FileName is the name of a project's manifest.
+	FileName = "beef.toml"
+	// VendorDir is where dependencies are installed, next to the manifest.
+	VendorDir = "beef_modules"
+)
+
+// Manifest is a project's beef.toml.
+type Manifest struct {
+	Name         string                `toml:"name"`
+	Entry        string                `toml:"entry"` // the program file, relative to Dir
+	Dependencies map[string]Dependency `toml:"dependencies"`
+
+	Dir string `toml:"-"` // the directory holding the manifest
+}
+
+// Dependency is a package a project uses: a git repository, at a tag or
+// branch.
+type Dependency struct {
+	Git     string `toml:"git"`
+	Version string `toml:"version"`
+}
+
+// Load reads and checks the manifest at path.
+func Load(path string) (*Manifest, error) {
+	var m Manifest
+	meta, err := toml.DecodeFile(path, &m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("%s: unknown key %s", path, undecoded[0])
+	}
+	for _, name := range m.DependencyNames() {
+		if err := checkDependency(name, m.Dependencies[name]); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	m.Dir = filepath.Dir(path)
+	return &m, nil
+}
+
+// Find loads the manifest of the project dir is in: the first beef.toml in
+// dir or one of its parents. It returns nil if there is none.
+func Find(dir string) (*Manifest, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return Load(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// DependencyNames returns the names of the dependencies, sorted.
+func (m *Manifest) DependencyNames() []string {
+	names := make([]string, 0, len(m.Dependencies))
+	for name := range m.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ModuleDirs returns the directory each dependency is installed in, in name
+// order, for the module search path.
+func (m *Manifest) ModuleDirs() []string {
+	var dirs []string
+	for _, name := range m.DependencyNames() {
+		dirs = append(dirs, filepath.Join(m.Dir, VendorDir, name))
+	}
+	return dirs
+}
+
+// checkDependency reports what is wrong with a dependency, if anything.
+func checkDependency(name string, dep Dependency) error {
+	switch {
+	case name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`):
+		return fmt.Errorf("%q can't be a dependency name: it names its directory in %s", name, VendorDir)
+	case dep.Git == "":
+		return fmt.Errorf("dependency %s has no git URL", name)
+	case dep.Version == "":
+		return fmt.Errorf("dependency %s has no version (a git tag or branch)", name)
+	}
+	return nil
+}
+
+// AddDependency adds a dependency to the manifest at path, creating the
+// manifest if there isn't one. The file is edited rather than rewritten, so
+// its comments and layout survive. A dependency already listed is an error:
+// its version is changed by editing the file.
+func AddDependency(path, name string, dep Dependency) error {
+	if err := checkDependency(name, dep); err != nil {
+		return err
+	}
+	source, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		project := filepath.Base(filepath.Dir(path))
+		if abs, err := filepath.Abs(filepath.Dir(path)); err == nil {
+			project = filepath.Base(abs)
+		}
+		source = []byte("name = " + strconv.Quote(project) + "\n")
+	} else if err != nil {
+		return err
+	} else {
+		var m Manifest
+		if _, err := toml.Decode(string(source), &m); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if _, ok := m.Dependencies[name]; ok {
+			return fmt.Errorf("%s already depends on %s; edit it to change the version", path, name)
+		}
+	}
+
+	entry := fmt.Sprintf("%s = { git = %s, version = %s }\n", tomlKey(name), strconv.Quote(dep.Git), strconv.Quote(dep.Version))
+	lines := strings.SplitAfter(string(source), "\n")
+	section := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "[dependencies]" {
+			section = i
+			break
+		}
+	}
+	var out string
+	if section < 0 {
+		if len(source) > 0 && !bytes.HasSuffix(source, []byte("\n")) {
+			source = append(source, '\n')
+		}
+		out = string(source) + "\n[dependencies]\n" + entry
+	} else {
+		// After the section's last entry, before the blank lines and the
+		// next table
+		at := section + 1
+		for i := section + 1; i < len(lines); i++ {
+			trimmed := strings.TrimSpace(lines[i])
+			if strings.HasPrefix(trimmed, "[") {
+				break
+			}
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				at = i + 1
+			}
+		}
+		if at > 0 && !strings.HasSuffix(lines[at-1], "\n") {
+			lines[at-1] += "\n"
+		}
+		out = strings.Join(lines[:at], "") + entry + strings.Join(lines[at:], "")
+	}
+	return os.WriteFile(path, []byte(out), 0o644)
+}
+
+// tomlKey writes a dependency name as a TOML key, quoting it unless it is
+// bare.
+func tomlKey(name string) string {
+	for _, c := range name {
+		if !(c == '-' || c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
+
+// Install fetches a dependency into dir/beef_modules/<name>, replacing what
+// was there. It clones the repository at the dependency's version with the
+// git command, and drops the clone's history: beef_modules holds only the
+// package's files.
+//
+// If lock has the dependency at the same git URL and version, the fetch must
+// be the commit and files it records - a tag moved since is an error, and
+// the installed files are left as they were. Otherwise the dependency is
+// recorded in lock.
+func Install(dir, name string, dep Dependency, lock *Lock) error {
+	if err := checkDependency(name, dep); err != nil {
+		return err
+	}
+	vendor := filepath.Join(dir, VendorDir)
+	if err := os.MkdirAll(vendor, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(vendor, "."+name+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	if _, err := git("clone", "--quiet", "--depth", "1", "--branch", dep.Version, "--", dep.Git, tmp); err != nil {
+		return fmt.Errorf("fetching %s %s from %s: %w", name, dep.Version, dep.Git, err)
+	}
+	commit, err := git("-C", tmp, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("fetching %s %s from %s: %w", name, dep.Version, dep.Git, err)
+	}
+	if err := os.RemoveAll(filepath.Join(tmp, ".git")); err != nil {
+		return err
+	}
+	hash, err := HashDir(tmp)
+	if err != nil {
+		return err
+	}
+
+	locked := lock.Find(name)
+	if locked != nil && locked.Git == dep.Git && locked.Version == dep.Version {
+		switch {
+		case commit != locked.Commit:
+			return fmt.Errorf("%s %s is now commit %s, but %s has %s: the version was moved since it was locked; "+
+				"check the new code, then run `beeflang install --update` to accept it", name, dep.Version, commit, LockFileName, locked.Commit)
+		case hash != locked.Hash:
+			return fmt.Errorf("%s %s doesn't hash to what %s has: check the code, then run `beeflang install --update` to accept it",
+				name, dep.Version, LockFileName)
+		}
+	}
+
+	target := filepath.Join(vendor, name)
+	if err := os.RemoveAll(target); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return err
+	}
+	lock.set(LockedPackage{Name: name, Git: dep.Git, Version: dep.Version, Commit: commit, Hash: hash})
+	return nil
+}
+
+// git runs the git command, returning its output without the final newline,
+// or an error with what it printed on failure.
+func git(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-c", "advice.detachedHead=false"}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
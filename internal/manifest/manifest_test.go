@@ -0,0 +1,189 @@
+package manifest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeManifest creates dir/beef.toml with the given source
+func writeManifest(t *testing.T, dir, source string) string {
+	path := filepath.Join(dir, FileName)
+	assert.NoError(t, os.WriteFile(path, []byte(source), 0o644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := writeManifest(t, dir, `
+name = "mygame"
+entry = "main.beef"
+
+[dependencies]
+pathfinding = { git = "https://example.com/pathfinding", version = "v1.2.0" }
+"dialog-kit" = { git = "https://example.com/dialog", version = "main" }
+`)
+
+	m, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "mygame", m.Name)
+	assert.Equal(t, "main.beef", m.Entry)
+	assert.Equal(t, dir, m.Dir)
+	assert.Equal(t, Dependency{Git: "https://example.com/pathfinding", Version: "v1.2.0"}, m.Dependencies["pathfinding"])
+	assert.Equal(t, []string{"dialog-kit", "pathfinding"}, m.DependencyNames())
+	assert.Equal(t, []string{filepath.Join(dir, VendorDir, "dialog-kit"), filepath.Join(dir, VendorDir, "pathfinding")}, m.ModuleDirs())
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{`nmae = "typo"`, "unknown key nmae"},
+		{"[dependencies]\npf = { git = \"https://example.com/pf\" }", "dependency pf has no version (a git tag or branch)"},
+		{"[dependencies]\npf = { version = \"v1\" }", "dependency pf has no git URL"},
+		{"[dependencies]\n\"..\" = { git = \"x\", version = \"v1\" }", `".." can't be a dependency name: it names its directory in beef_modules`},
+		{`name = `, "toml: line 1"},
+	}
+
+	for _, tt := range tests {
+		path := writeManifest(t, t.TempDir(), tt.source)
+		_, err := Load(path)
+		if err == nil {
+			t.Fatalf("expected an error for %q", tt.source)
+		}
+		assert.Contains(t, err.Error(), tt.expected, tt.source)
+	}
+}
+
+func TestFindLooksInParentDirectories(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir, `name = "mygame"`)
+	scripts := filepath.Join(dir, "scripts", "ai")
+	assert.NoError(t, os.MkdirAll(scripts, 0o755))
+
+	m, err := Find(scripts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.NotNil(t, m) {
+		assert.Equal(t, "mygame", m.Name)
+	}
+
+	// Without a manifest there is no project (t.TempDir() has no beef.toml above it)
+	m, err = Find(t.TempDir())
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+}
+
+func TestAddDependency(t *testing.T) {
+	dir := t.TempDir()
+	path := writeManifest(t, dir, `# The game
+name = "mygame"
+
+[dependencies]
+pathfinding = { git = "https://example.com/pathfinding", version = "v1.2.0" }
+
+[tools]
+`)
+
+	assert.NoError(t, AddDependency(path, "dialog", Dependency{Git: "https://example.com/dialog", Version: "v2"}))
+	source, _ := os.ReadFile(path)
+	assert.Equal(t, `# The game
+name = "mygame"
+
+[dependencies]
+pathfinding = { git = "https://example.com/pathfinding", version = "v1.2.0" }
+dialog = { git = "https://example.com/dialog", version = "v2" }
+
+[tools]
+`, string(source))
+
+	err := AddDependency(path, "dialog", Dependency{Git: "https://example.com/dialog", Version: "v3"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "already depends on dialog")
+	}
+}
+
+func TestAddDependencyCreatesTheManifest(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mygame")
+	assert.NoError(t, os.Mkdir(dir, 0o755))
+	path := filepath.Join(dir, FileName)
+
+	assert.NoError(t, AddDependency(path, "dialog.kit", Dependency{Git: "https://example.com/dialog", Version: "v2"}))
+	m, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "mygame", m.Name)
+	assert.Equal(t, Dependency{Git: "https://example.com/dialog", Version: "v2"}, m.Dependencies["dialog.kit"])
+}
+
+// packageRepo creates a git repository holding pathfinding.beef, committed
+// and tagged v1.0.0, and returns its URL and a function running git in it.
+func packageRepo(t *testing.T) (string, func(args ...string)) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=beef", "-c", "user.email=beef@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "--quiet")
+	writePackageFile(t, repo, "prep version = 1")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "v1")
+	git("tag", "v1.0.0")
+	return "file://" + repo, git
+}
+
+// writePackageFile replaces the contents of dir/pathfinding.beef
+func writePackageFile(t *testing.T, dir, source string) {
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pathfinding.beef"), []byte(source), 0o644))
+}
+
+func TestInstall(t *testing.T) {
+	url, git := packageRepo(t)
+	writePackageFile(t, url[len("file://"):], "prep version = 2")
+	git("commit", "--quiet", "-am", "v2")
+
+	project := t.TempDir()
+	lock := &Lock{}
+	assert.NoError(t, Install(project, "pathfinding", Dependency{Git: url, Version: "v1.0.0"}, lock))
+
+	installed := filepath.Join(project, VendorDir, "pathfinding")
+	source, err := os.ReadFile(filepath.Join(installed, "pathfinding.beef"))
+	assert.NoError(t, err)
+	assert.Equal(t, "prep version = 1", string(source), "the tagged version is installed")
+	assert.NoDirExists(t, filepath.Join(installed, ".git"))
+	if assert.Len(t, lock.Packages, 1) {
+		pkg := lock.Packages[0]
+		assert.Equal(t, "v1.0.0", pkg.Version)
+		assert.Len(t, pkg.Commit, 40)
+		hash, _ := HashDir(installed)
+		assert.Equal(t, hash, pkg.Hash)
+	}
+
+	err = Install(project, "pathfinding", Dependency{Git: url, Version: "v9.9.9"}, lock)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "fetching pathfinding v9.9.9")
+	}
+
+	// Installing another version replaces the files
+	git("tag", "v2.0.0")
+	assert.NoError(t, Install(project, "pathfinding", Dependency{Git: url, Version: "v2.0.0"}, lock))
+	source, _ = os.ReadFile(filepath.Join(installed, "pathfinding.beef"))
+	assert.Equal(t, "prep version = 2", string(source))
+	assert.Equal(t, "v2.0.0", lock.Find("pathfinding").Version)
+	entries, _ := os.ReadDir(filepath.Join(project, VendorDir))
+	assert.Len(t, entries, 1, "no temporary directories are left behind")
+}
@@ -0,0 +1,59 @@
+package object
+
+import (
+	"fmt"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+)
+
+// Argument checks shared by builtin functions and the methods of built-in
+// values. The errors have no position; the evaluator adds the call's.
+
+// CheckArgCount reports a builtin function or method called with the wrong
+// number of arguments.
+func CheckArgCount(name string, args []Object, want int) *Error {
+	if len(args) == want {
+		return nil
+	}
+	var takes string
+	switch want {
+	case 0:
+		takes = "no arguments"
+	case 1:
+		takes = "1 argument"
+	default:
+		takes = fmt.Sprintf("%d arguments", want)
+	}
+	return &Error{Code: diagnostics.CodeBadArgument,
+		Message: fmt.Sprintf("%s takes %s, got %d", name, takes, len(args))}
+}
+
+// IntegerArg returns the value of an argument that must be an integer.
+func IntegerArg(name string, arg Object) (int64, *Error) {
+	n, ok := arg.(*Integer)
+	if !ok {
+		return 0, &Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: expected an INTEGER, got %s", name, arg.Type())}
+	}
+	return n.Value, nil
+}
+
+// StringArg returns the value of an argument that must be a string.
+func StringArg(name string, arg Object) (string, *Error) {
+	s, ok := arg.(*String)
+	if !ok {
+		return "", &Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: expected a STRING, got %s", name, arg.Type())}
+	}
+	return s.Value, nil
+}
+
+// BytesArg returns the value of an argument that must be bytes.
+func BytesArg(name string, arg Object) ([]byte, *Error) {
+	b, ok := arg.(*Bytes)
+	if !ok {
+		return nil, &Error{Code: diagnostics.CodeBadArgument,
+			Message: fmt.Sprintf("%s: expected BYTES, got %s", name, arg.Type())}
+	}
+	return b.Value, nil
+}
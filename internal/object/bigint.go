@@ -0,0 +1,23 @@
+package object
+
+import "math/big"
+
+// BigInteger is an integer of any size, written 123n. The Value is never
+// changed once the BigInteger exists, so it can be shared.
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (b *BigInteger) Type() string {
+	return "BIGINT"
+}
+
+func (b *BigInteger) Inspect() string {
+	return b.Value.String()
+}
+
+// HashKey keeps big integers apart from integers with the same value, as
+// 1 and "1" are kept apart.
+func (b *BigInteger) HashKey() HashKey {
+	return HashKey{Type: b.Type(), Value: b.Value.String()}
+}
@@ -0,0 +1,46 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+// inspectBytes is how many bytes Inspect shows before cutting the rest short.
+const inspectBytes = 16
+
+// Bytes is a run of binary data - a save file, an image, a network message -
+// made by the bytes module or read with fs.read_bytes and a connection's
+// read_bytes(). Like strings, bytes values never change: slicing and joining
+// them make new ones, and == compares contents. b[i] is the byte at i, as an
+// integer from 0 to 255.
+type Bytes struct {
+	Value []byte
+}
+
+func (b *Bytes) Type() string {
+	return "BYTES"
+}
+
+// Inspect shows the bytes in hex: <bytes 01 ff 2a>. Past the first few it
+// gives the length instead of the rest.
+func (b *Bytes) Inspect() string {
+	var out strings.Builder
+	out.WriteString("<bytes")
+	for i, c := range b.Value {
+		if i == inspectBytes {
+			fmt.Fprintf(&out, " ... (%d bytes)", len(b.Value))
+			break
+		}
+		fmt.Fprintf(&out, " %02x", c)
+	}
+	out.WriteString(">")
+	return out.String()
+}
+
+// Get returns the number of bytes, as length.
+func (b *Bytes) Get(name string) (Object, bool) {
+	if name == "length" {
+		return &Integer{Value: int64(len(b.Value))}, true
+	}
+	return nil, false
+}
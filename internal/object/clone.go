@@ -0,0 +1,107 @@
+package object
+
+// cloner deep-copies environments and the values in them. It remembers what
+// it has copied, so values reachable along several paths - and functions
+// whose closure is the environment being copied - are copied once and keep
+// pointing at each other.
+type cloner struct {
+	envs   map[*Environment]*Environment
+	values map[Object]Object
+	// shareFunctions leaves functions, and so their closures, uncopied
+	shareFunctions bool
+}
+
+func newCloner() *cloner {
+	return &cloner{envs: map[*Environment]*Environment{}, values: map[Object]Object{}}
+}
+
+// Clone returns a deep copy of this scope and every scope it encloses. Unlike
+// Snapshot, arrays, hashes, grids and functions are copied too, and a copied
+// function closes over the copy of its environment. Values that are shared by
+// nature (modules, channels, counters, builtins) are still shared.
+func (e *Environment) Clone() *Environment {
+	return newCloner().env(e)
+}
+
+// Clone returns a deep copy of a value: arrays, hashes and grids are copied
+// all the way down, while functions and the values Environment.Clone shares are
+// shared. An array or hash reachable along several paths, including one that
+// contains itself, is copied once, so the copy has the same shape.
+func Clone(obj Object) Object {
+	c := newCloner()
+	c.shareFunctions = true
+	return c.value(obj)
+}
+
+// CloneInto copies the variables of this scope into dst, as Clone does, and
+// returns dst. Functions that closed over this scope close over dst instead,
+// so restoring a clone into a live environment leaves no references to the
+// clone behind.
+func (e *Environment) CloneInto(dst *Environment) *Environment {
+	c := newCloner()
+	c.envs[e] = dst
+	for name, val := range e.Bindings() {
+		dst.Set(name, c.value(val))
+	}
+	return dst
+}
+
+func (c *cloner) env(e *Environment) *Environment {
+	if e == nil {
+		return nil
+	}
+	if copied, ok := c.envs[e]; ok {
+		return copied
+	}
+	copied := NewEnvironment()
+	c.envs[e] = copied
+	copied.outer = c.env(e.outer)
+	copied.host = e.host
+	for name, val := range e.Bindings() {
+		copied.store[name] = c.value(val)
+	}
+	return copied
+}
+
+func (c *cloner) value(obj Object) Object {
+	if copied, ok := c.values[obj]; ok {
+		return copied
+	}
+	switch obj := obj.(type) {
+	case *Array:
+		elements := obj.Elements()
+		copied := NewArray(make([]Object, len(elements)))
+		c.values[obj] = copied
+		for i, el := range elements {
+			copied.elements[i] = c.value(el)
+		}
+		return copied
+	case *Hash:
+		copied := NewHash()
+		c.values[obj] = copied
+		for _, pair := range obj.Pairs() {
+			copied.Set(pair.Key, c.value(pair.Value))
+		}
+		return copied
+	case *Grid:
+		cells := obj.Cells()
+		copied := &Grid{Width: obj.Width, Height: obj.Height, cells: make([]Object, len(cells))}
+		c.values[obj] = copied
+		for i, cell := range cells {
+			copied.cells[i] = c.value(cell)
+		}
+		return copied
+	case *Function:
+		if c.shareFunctions {
+			return obj
+		}
+		copied := *obj
+		c.values[obj] = &copied
+		copied.Env = c.env(obj.Env)
+		return &copied
+	default:
+		// Integers, strings and booleans never change, and the rest are
+		// shared on purpose
+		return obj
+	}
+}
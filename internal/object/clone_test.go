@@ -0,0 +1,93 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloneCopiesValuesDeeply(t *testing.T) {
+	env := NewEnvironment()
+	inventory := NewArray([]Object{&String{Value: "brisket"}})
+	order := NewHash()
+	order.Set(&String{Value: "items"}, inventory)
+	env.Set("inventory", inventory)
+	env.Set("order", order)
+
+	clone := env.Clone()
+	inventory.elements[0] = &String{Value: "ribs"}
+	env.Set("hp", &Integer{Value: 3})
+
+	copied, _ := clone.Get("inventory")
+	assert.Equal(t, `["brisket"]`, copied.Inspect())
+	copiedOrder, _ := clone.Get("order")
+	items, _ := copiedOrder.(*Hash).Get("items")
+	assert.Same(t, copied, items, "values reachable twice are copied once")
+	_, ok := clone.Get("hp")
+	assert.False(t, ok)
+}
+
+func TestClonedFunctionsCloseOverTheClone(t *testing.T) {
+	outer := NewEnvironment()
+	env := NewEnclosedEnvironment(outer)
+	fn := &Function{Env: env}
+	env.Set("heal", fn)
+	outer.Set("heal_outer", fn)
+
+	clone := env.Clone()
+	copied, _ := clone.Get("heal")
+	assert.NotSame(t, fn, copied)
+	assert.Same(t, clone, copied.(*Function).Env)
+	fromOuter, _ := clone.Outer().Get("heal_outer")
+	assert.Same(t, copied, fromOuter)
+}
+
+func TestCloneIntoRebindsClosures(t *testing.T) {
+	saved := NewEnvironment()
+	saved.Set("hp", &Integer{Value: 7})
+	saved.Set("heal", &Function{Env: saved})
+
+	live := NewEnvironment()
+	live.Set("name", &String{Value: "Bubba"})
+	saved.CloneInto(live)
+
+	hp, _ := live.Get("hp")
+	assert.Equal(t, "7", hp.Inspect())
+	heal, _ := live.Get("heal")
+	assert.Same(t, live, heal.(*Function).Env)
+	_, ok := live.Get("name")
+	assert.True(t, ok, "variables the clone doesn't have are kept")
+}
+
+func TestCloneValue(t *testing.T) {
+	fn := &Function{Env: NewEnvironment()}
+	state := NewHash()
+	party := NewArray([]Object{&String{Value: "Bubba"}})
+	state.Set(&String{Value: "party"}, party)
+	state.Set(&String{Value: "on_hit"}, fn)
+	// A cycle: the party array holds itself
+	party.elements = append(party.elements, party)
+
+	copied := Clone(state).(*Hash)
+	assert.NotSame(t, state, copied)
+	copiedParty, _ := copied.Get("party")
+	assert.NotSame(t, party, copiedParty)
+	assert.Same(t, copiedParty, copiedParty.(*Array).elements[1], "the cycle is copied, not followed")
+	onHit, _ := copied.Get("on_hit")
+	assert.Same(t, fn, onHit, "functions are shared")
+
+	party.elements[0] = &String{Value: "Earl"}
+	assert.Equal(t, "Bubba", copiedParty.(*Array).elements[0].Inspect())
+}
+
+func TestCloneCopiesGrids(t *testing.T) {
+	door := NewArray([]Object{&String{Value: "locked"}})
+	level := NewGrid(2, 1, door)
+
+	copied := Clone(level).(*Grid)
+	level.cells[1] = NULL
+	door.elements[0] = &String{Value: "open"}
+
+	assert.Equal(t, `["locked"]`, copied.cells[1].Inspect())
+	assert.Same(t, copied.cells[0], copied.cells[1], "a value in several cells is copied once")
+}
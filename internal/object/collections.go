@@ -0,0 +1,249 @@
+package object
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Array is an ordered list of values: [1, "two", true]. Tasks can share one:
+// its methods lock it, so the elements are only read and changed through them.
+type Array struct {
+	mu       sync.RWMutex
+	elements []Object
+}
+
+// NewArray returns an array holding elements. The array takes the slice
+// over, so the caller must not use it afterwards.
+func NewArray(elements []Object) *Array {
+	return &Array{elements: elements}
+}
+
+// Len returns the number of elements.
+func (a *Array) Len() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.elements)
+}
+
+// At returns the element at index i, or false if there is none.
+func (a *Array) At(i int) (Object, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if i < 0 || i >= len(a.elements) {
+		return nil, false
+	}
+	return a.elements[i], true
+}
+
+// Elements returns a copy of the elements, which later changes to the array
+// don't affect.
+func (a *Array) Elements() []Object {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return slices.Clone(a.elements)
+}
+
+// Push adds values to the end.
+func (a *Array) Push(values ...Object) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.elements = append(a.elements, values...)
+}
+
+// Update replaces the elements with what fn returns for them, holding the
+// lock throughout, so a change that depends on the elements - removing the
+// last one, say - can't be interleaved with another. fn must not use the
+// array itself.
+func (a *Array) Update(fn func(elements []Object) []Object) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.elements = fn(a.elements)
+}
+
+func (a *Array) Type() string {
+	return "ARRAY"
+}
+
+// Inspect shows the array's elements. An array inside itself shows as [...],
+// as in Repr.
+func (a *Array) Inspect() string {
+	return a.inspect(map[Object]bool{})
+}
+
+// inspect is Inspect for an array inside the arrays and hashes in open.
+func (a *Array) inspect(open map[Object]bool) string {
+	if open[a] {
+		return "[...]"
+	}
+	open[a] = true
+	defer delete(open, a)
+	elements := a.Elements()
+	parts := make([]string, len(elements))
+	for i, el := range elements {
+		parts[i] = inspectElement(el, open)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// HashKey identifies an entry of a Hash. Equal keys - the same type and
+// value - give the same HashKey.
+type HashKey struct {
+	Type  string
+	Value string
+}
+
+// Hashable is implemented by the values that can be hash keys: integers,
+// strings and booleans.
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: strconv.FormatInt(i.Value, 10)}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	return HashKey{Type: b.Type(), Value: strconv.FormatBool(b.Value)}
+}
+
+func (s *String) HashKey() HashKey {
+	return HashKey{Type: s.Type(), Value: s.Value}
+}
+
+// HashPair is one entry of a Hash, with the key as it was given.
+type HashPair struct {
+	Key   Hashable
+	Value Object
+}
+
+// StrMember names the member a hash can set to a function that gives the
+// hash's text: {"name": "Bubba", "__str": show_player}. The function is
+// called with the hash, or with nothing if it takes no parameters, and
+// serves a string.
+const StrMember = "__str"
+
+// CallStr calls a __str function for Inspect. The evaluator sets it, since
+// only the evaluator can run Beeflang code. It reports false if the function
+// failed or served something other than a string, and the hash is then shown
+// as usual.
+var CallStr func(fn Object, h *Hash) (string, bool)
+
+// Hash maps keys to values: {"cut": "brisket", "hours": 12}. It remembers the
+// order keys were first added in, and Inspect and Pairs follow it.
+//
+// String keys can also be read as members: order.cut is order["cut"]. Tasks
+// can share a hash: its methods lock it.
+type Hash struct {
+	mu    sync.RWMutex
+	pairs map[HashKey]HashPair
+	keys  []HashKey
+}
+
+// NewHash creates an empty hash.
+func NewHash() *Hash {
+	return &Hash{pairs: make(map[HashKey]HashPair)}
+}
+
+// Set adds or replaces the value for key. A replaced key keeps its place.
+func (h *Hash) Set(key Hashable, value Object) {
+	k := key.HashKey()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.pairs[k]; !ok {
+		h.keys = append(h.keys, k)
+	}
+	h.pairs[k] = HashPair{Key: key, Value: value}
+}
+
+// Lookup returns the value for key.
+func (h *Hash) Lookup(key Hashable) (Object, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	pair, ok := h.pairs[key.HashKey()]
+	return pair.Value, ok
+}
+
+// Delete removes the entry for key, returning its value. The other keys keep
+// their order.
+func (h *Hash) Delete(key Hashable) (Object, bool) {
+	k := key.HashKey()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	pair, ok := h.pairs[k]
+	if !ok {
+		return nil, false
+	}
+	delete(h.pairs, k)
+	h.keys = slices.DeleteFunc(h.keys, func(other HashKey) bool { return other == k })
+	return pair.Value, true
+}
+
+// Pairs returns the entries in the order their keys were added.
+func (h *Hash) Pairs() []HashPair {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	pairs := make([]HashPair, len(h.keys))
+	for i, k := range h.keys {
+		pairs[i] = h.pairs[k]
+	}
+	return pairs
+}
+
+// Len returns the number of entries.
+func (h *Hash) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.keys)
+}
+
+// Get looks up a string key, so entries can be read as members.
+func (h *Hash) Get(name string) (Object, bool) {
+	return h.Lookup(&String{Value: name})
+}
+
+func (h *Hash) Type() string {
+	return "HASH"
+}
+
+// Inspect shows the hash's pairs, or what its __str function serves. A hash
+// inside itself shows as {...}, as in Repr.
+func (h *Hash) Inspect() string {
+	return h.inspect(map[Object]bool{})
+}
+
+// inspect is Inspect for a hash inside the arrays and hashes in open.
+func (h *Hash) inspect(open map[Object]bool) string {
+	if open[h] {
+		return "{...}"
+	}
+	if fn, ok := h.Get(StrMember); ok && CallStr != nil {
+		if text, ok := CallStr(fn, h); ok {
+			return text
+		}
+	}
+	open[h] = true
+	defer delete(open, h)
+	pairs := h.Pairs()
+	parts := make([]string, len(pairs))
+	for i, pair := range pairs {
+		parts[i] = inspectElement(pair.Key, open) + ": " + inspectElement(pair.Value, open)
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// inspectElement shows a value inside an array or hash, where strings are
+// quoted so ["1"] and [1] can be told apart.
+func inspectElement(obj Object, open map[Object]bool) string {
+	switch obj := obj.(type) {
+	case *String:
+		return `"` + obj.Value + `"`
+	case *Array:
+		return obj.inspect(open)
+	case *Hash:
+		return obj.inspect(open)
+	}
+	return obj.Inspect()
+}
@@ -0,0 +1,89 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrayTypeAndInspect(t *testing.T) {
+	arr := NewArray([]Object{&Integer{Value: 1}, &String{Value: "two"}, TRUE})
+
+	assert.Equal(t, "ARRAY", arr.Type())
+	assert.Equal(t, `[1, "two", true]`, arr.Inspect())
+	assert.Equal(t, "[]", NewArray(nil).Inspect())
+}
+
+func TestInspectStopsAtCycles(t *testing.T) {
+	a := NewArray(nil)
+	a.elements = []Object{&Integer{Value: 1}, a}
+	assert.Equal(t, "[1, [...]]", a.Inspect())
+
+	h := NewHash()
+	h.Set(&String{Value: "self"}, h)
+	h.Set(&String{Value: "list"}, NewArray([]Object{h}))
+	assert.Equal(t, `{"self": {...}, "list": [{...}]}`, h.Inspect())
+
+	// The same array twice, side by side, isn't a cycle
+	inner := NewArray([]Object{TRUE})
+	assert.Equal(t, "[[true], [true]]", NewArray([]Object{inner, inner}).Inspect())
+}
+
+func TestHashKeysCompareByValue(t *testing.T) {
+	h := NewHash()
+	h.Set(&String{Value: "cut"}, &String{Value: "brisket"})
+	h.Set(&Integer{Value: 1}, TRUE)
+
+	val, ok := h.Lookup(&String{Value: "cut"})
+	assert.True(t, ok)
+	assert.Equal(t, "brisket", val.Inspect())
+
+	// The string "1" and the integer 1 are different keys
+	_, ok = h.Lookup(&String{Value: "1"})
+	assert.False(t, ok)
+	_, ok = h.Lookup(&Integer{Value: 1})
+	assert.True(t, ok)
+}
+
+func TestHashKeepsInsertionOrder(t *testing.T) {
+	h := NewHash()
+	h.Set(&String{Value: "b"}, &Integer{Value: 1})
+	h.Set(&String{Value: "a"}, &Integer{Value: 2})
+	// Replacing a value keeps the key where it was
+	h.Set(&String{Value: "b"}, &Integer{Value: 3})
+
+	assert.Equal(t, 2, h.Len())
+	assert.Equal(t, "HASH", h.Type())
+	assert.Equal(t, `{"b": 3, "a": 2}`, h.Inspect())
+}
+
+func TestHashDeleteKeepsOrder(t *testing.T) {
+	h := NewHash()
+	h.Set(&String{Value: "a"}, &Integer{Value: 1})
+	h.Set(&String{Value: "b"}, &Integer{Value: 2})
+	h.Set(&String{Value: "c"}, &Integer{Value: 3})
+
+	val, ok := h.Delete(&String{Value: "b"})
+	assert.True(t, ok)
+	assert.Equal(t, "2", val.Inspect())
+	_, ok = h.Delete(&String{Value: "b"})
+	assert.False(t, ok)
+
+	// A deleted key that comes back goes to the end
+	h.Set(&String{Value: "b"}, &Integer{Value: 4})
+	assert.Equal(t, 3, h.Len())
+	assert.Equal(t, `{"a": 1, "c": 3, "b": 4}`, h.Inspect())
+}
+
+func TestHashStringKeysAreMembers(t *testing.T) {
+	h := NewHash()
+	h.Set(&String{Value: "cut"}, &String{Value: "ribs"})
+
+	var c Container = h
+	val, ok := c.Get("cut")
+	assert.True(t, ok)
+	assert.Equal(t, "ribs", val.Inspect())
+
+	_, ok = c.Get("weight")
+	assert.False(t, ok)
+}
@@ -0,0 +1,352 @@
+package object
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+)
+
+// The values in this file are shared between tasks started with 'stampede'.
+// Each is safe for concurrent use, and misuse (unlocking a mutex that isn't
+// locked, closing a channel twice) is reported as a runtime error rather than
+// crashing the interpreter the way the Go primitives underneath would.
+
+// Channel is a channel for passing values between tasks started with
+// 'stampede'. It is a Go channel underneath: a send blocks until a receiver
+// takes the value, or until there is room in the buffer if the channel has a
+// capacity.
+type Channel struct {
+	ch chan Object
+}
+
+// NewChannel creates a channel holding up to capacity unreceived values (0 for unbuffered).
+func NewChannel(capacity int) *Channel {
+	return &Channel{ch: make(chan Object, capacity)}
+}
+
+func (c *Channel) Type() string {
+	return "CHANNEL"
+}
+
+func (c *Channel) Inspect() string {
+	return fmt.Sprintf("<channel %d/%d>", len(c.ch), cap(c.ch))
+}
+
+// Send passes val to a receiver, blocking until one takes it (or there is
+// room in the buffer). Sending on a closed channel returns an error.
+func (c *Channel) Send(val Object) (err *Error) {
+	defer func() {
+		// A send on a closed channel panics in Go
+		if recover() != nil {
+			err = &Error{Code: diagnostics.CodeClosedChannel, Message: "send on closed channel"}
+		}
+	}()
+	c.ch <- val
+	return nil
+}
+
+// Recv waits for a value and returns it. Once the channel is closed and
+// every value sent has been received, Recv returns NULL straight away.
+func (c *Channel) Recv() Object {
+	val, ok := <-c.ch
+	if !ok {
+		return NULL
+	}
+	return val
+}
+
+// Close marks the channel as done: no more values can be sent, and receivers
+// get NULL once it is drained. Closing a channel twice returns an error.
+func (c *Channel) Close() (err *Error) {
+	defer func() {
+		if recover() != nil {
+			err = &Error{Code: diagnostics.CodeClosedChannel, Message: "close of closed channel"}
+		}
+	}()
+	close(c.ch)
+	return nil
+}
+
+// Get returns the channel's methods: send(value), recv() and close().
+func (c *Channel) Get(name string) (Object, bool) {
+	switch name {
+	case "send":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("send", args, 1); err != nil {
+				return err
+			}
+			if err := c.Send(args[0]); err != nil {
+				return err
+			}
+			return NULL
+		}}, true
+	case "recv":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("recv", args, 0); err != nil {
+				return err
+			}
+			return c.Recv()
+		}}, true
+	case "close":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("close", args, 0); err != nil {
+				return err
+			}
+			if err := c.Close(); err != nil {
+				return err
+			}
+			return NULL
+		}}, true
+	}
+	return nil, false
+}
+
+// SelectCase is one channel operation offered to Select: a receive from
+// Channel, or a send of Value when Send is set.
+type SelectCase struct {
+	Channel *Channel
+	Send    bool
+	Value   Object
+}
+
+// Select waits until one of the cases can go ahead, performs it and returns
+// its index; if several are ready, one is picked at random. A receive also
+// returns the value received (NULL once the channel is closed and drained).
+// With hasDefault set Select never waits: if no case is ready it returns -1.
+func Select(cases []SelectCase, hasDefault bool) (chosen int, received Object, err *Error) {
+	defer func() {
+		// A send on a closed channel panics in Go
+		if recover() != nil {
+			err = &Error{Code: diagnostics.CodeClosedChannel, Message: "send on closed channel"}
+		}
+	}()
+
+	selectCases := make([]reflect.SelectCase, len(cases), len(cases)+1)
+	for i, c := range cases {
+		if c.Send {
+			value := c.Value
+			if value == nil {
+				value = NULL
+			}
+			selectCases[i] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(c.Channel.ch), Send: reflect.ValueOf(&value).Elem()}
+		} else {
+			selectCases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.Channel.ch)}
+		}
+	}
+	if hasDefault {
+		selectCases = append(selectCases, reflect.SelectCase{Dir: reflect.SelectDefault})
+	}
+
+	chosen, value, ok := reflect.Select(selectCases)
+	if chosen == len(cases) {
+		return -1, nil, nil
+	}
+	if cases[chosen].Send {
+		return chosen, nil, nil
+	}
+	if !ok {
+		return chosen, NULL, nil
+	}
+	return chosen, value.Interface().(Object), nil
+}
+
+// Mutex is a lock that only one task can hold at a time.
+type Mutex struct {
+	// A one-slot channel rather than sync.Mutex: unlocking an unlocked
+	// sync.Mutex is a fatal error, while this can be detected and reported.
+	held chan struct{}
+}
+
+// NewMutex creates an unlocked mutex.
+func NewMutex() *Mutex {
+	return &Mutex{held: make(chan struct{}, 1)}
+}
+
+func (m *Mutex) Type() string {
+	return "MUTEX"
+}
+
+func (m *Mutex) Inspect() string {
+	if len(m.held) > 0 {
+		return "<mutex locked>"
+	}
+	return "<mutex>"
+}
+
+// Lock waits until the mutex is free, then takes it.
+func (m *Mutex) Lock() {
+	m.held <- struct{}{}
+}
+
+// Unlock frees the mutex. Any task may unlock it, but it must be locked.
+func (m *Mutex) Unlock() *Error {
+	select {
+	case <-m.held:
+		return nil
+	default:
+		return &Error{Code: diagnostics.CodeBadSyncUse, Message: "unlock of unlocked mutex"}
+	}
+}
+
+// Get returns the mutex's methods: lock() and unlock().
+func (m *Mutex) Get(name string) (Object, bool) {
+	switch name {
+	case "lock":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("lock", args, 0); err != nil {
+				return err
+			}
+			m.Lock()
+			return NULL
+		}}, true
+	case "unlock":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("unlock", args, 0); err != nil {
+				return err
+			}
+			if err := m.Unlock(); err != nil {
+				return err
+			}
+			return NULL
+		}}, true
+	}
+	return nil, false
+}
+
+// WaitGroup waits for a number of tasks to finish: add(n) before starting
+// them, done() as each one finishes, and wait() until all have.
+type WaitGroup struct {
+	wg      sync.WaitGroup
+	pending atomic.Int64 // mirrors the WaitGroup's counter, which Go doesn't expose
+}
+
+func (w *WaitGroup) Type() string {
+	return "WAITGROUP"
+}
+
+func (w *WaitGroup) Inspect() string {
+	return fmt.Sprintf("<waitgroup %d>", w.pending.Load())
+}
+
+// Add changes the number of tasks being waited for by delta. The count may
+// not go below zero.
+func (w *WaitGroup) Add(delta int64) *Error {
+	// Reserve the change first, so tasks calling done() at the same time
+	// can't both take the count below zero
+	if w.pending.Add(delta) < 0 {
+		w.pending.Add(-delta)
+		return &Error{Code: diagnostics.CodeBadSyncUse, Message: "waitgroup count would go below zero"}
+	}
+	w.wg.Add(int(delta))
+	return nil
+}
+
+// Wait blocks until the count is zero.
+func (w *WaitGroup) Wait() {
+	w.wg.Wait()
+}
+
+// Get returns the wait group's methods: add(n), done() and wait().
+func (w *WaitGroup) Get(name string) (Object, bool) {
+	switch name {
+	case "add":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("add", args, 1); err != nil {
+				return err
+			}
+			n, err := IntegerArg("add", args[0])
+			if err != nil {
+				return err
+			}
+			if err := w.Add(n); err != nil {
+				return err
+			}
+			return NULL
+		}}, true
+	case "done":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("done", args, 0); err != nil {
+				return err
+			}
+			if err := w.Add(-1); err != nil {
+				return err
+			}
+			return NULL
+		}}, true
+	case "wait":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("wait", args, 0); err != nil {
+				return err
+			}
+			w.Wait()
+			return NULL
+		}}, true
+	}
+	return nil, false
+}
+
+// Counter is an integer that tasks can update without a mutex.
+type Counter struct {
+	value atomic.Int64
+}
+
+// NewCounter creates a counter starting at start.
+func NewCounter(start int64) *Counter {
+	c := &Counter{}
+	c.value.Store(start)
+	return c
+}
+
+func (c *Counter) Type() string {
+	return "COUNTER"
+}
+
+func (c *Counter) Inspect() string {
+	return fmt.Sprintf("<counter %d>", c.value.Load())
+}
+
+// Get returns the counter's methods: add(n) (n defaults to 1; returns the
+// new value), get() and set(n).
+func (c *Counter) Get(name string) (Object, bool) {
+	switch name {
+	case "add":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if len(args) > 1 {
+				return &Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("add takes at most 1 argument, got %d", len(args))}
+			}
+			delta := int64(1)
+			if len(args) == 1 {
+				n, err := IntegerArg("add", args[0])
+				if err != nil {
+					return err
+				}
+				delta = n
+			}
+			return &Integer{Value: c.value.Add(delta)}
+		}}, true
+	case "get":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("get", args, 0); err != nil {
+				return err
+			}
+			return &Integer{Value: c.value.Load()}
+		}}, true
+	case "set":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("set", args, 1); err != nil {
+				return err
+			}
+			n, err := IntegerArg("set", args[0])
+			if err != nil {
+				return err
+			}
+			c.value.Store(n)
+			return NULL
+		}}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,106 @@
+package object
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper function to call a method of a container value
+func callMethod(t *testing.T, c Container, name string, args ...Object) Object {
+	method, ok := c.Get(name)
+	if !assert.True(t, ok, "%s should have a %s method", c.Type(), name) {
+		return nil
+	}
+	return method.(*Builtin).Fn(args...)
+}
+
+func TestMutex(t *testing.T) {
+	m := NewMutex()
+	assert.Equal(t, "<mutex>", m.Inspect())
+
+	assert.Equal(t, NULL, callMethod(t, m, "lock"))
+	assert.Equal(t, "<mutex locked>", m.Inspect())
+	assert.Equal(t, NULL, callMethod(t, m, "unlock"))
+
+	err, ok := callMethod(t, m, "unlock").(*Error)
+	assert.True(t, ok, "unlocking an unlocked mutex should fail")
+	assert.Equal(t, diagnostics.CodeBadSyncUse, err.Code)
+}
+
+func TestMutexExcludesOtherTasks(t *testing.T) {
+	m := NewMutex()
+	total := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.Lock()
+				total++
+				m.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1000, total)
+}
+
+func TestWaitGroup(t *testing.T) {
+	w := &WaitGroup{}
+	assert.Equal(t, NULL, callMethod(t, w, "add", &Integer{Value: 2}))
+	assert.Equal(t, "<waitgroup 2>", w.Inspect())
+
+	finished := make(chan bool)
+	go func() {
+		callMethod(t, w, "wait")
+		finished <- true
+	}()
+	callMethod(t, w, "done")
+	callMethod(t, w, "done")
+	<-finished
+
+	err, ok := callMethod(t, w, "done").(*Error)
+	assert.True(t, ok, "done() with nothing pending should fail")
+	assert.Equal(t, diagnostics.CodeBadSyncUse, err.Code)
+	assert.Equal(t, "<waitgroup 0>", w.Inspect())
+
+	err, ok = callMethod(t, w, "add", &String{Value: "two"}).(*Error)
+	assert.True(t, ok)
+	assert.Equal(t, "add: expected an INTEGER, got STRING", err.Message)
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounter(5)
+
+	assert.Equal(t, int64(6), callMethod(t, c, "add").(*Integer).Value)
+	assert.Equal(t, int64(2), callMethod(t, c, "add", &Integer{Value: -4}).(*Integer).Value)
+	assert.Equal(t, NULL, callMethod(t, c, "set", &Integer{Value: 40}))
+	assert.Equal(t, int64(40), callMethod(t, c, "get").(*Integer).Value)
+	assert.Equal(t, "<counter 40>", c.Inspect())
+
+	err, ok := callMethod(t, c, "get", &Integer{Value: 1}).(*Error)
+	assert.True(t, ok)
+	assert.Equal(t, "get takes no arguments, got 1", err.Message)
+}
+
+func TestCounterIsAtomic(t *testing.T) {
+	c := NewCounter(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				callMethod(t, c, "add")
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1000), callMethod(t, c, "get").(*Integer).Value)
+}
@@ -0,0 +1,133 @@
+package object
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+)
+
+// Coroutine is a function that can pause part way and be carried on later,
+// made by coroutine.new. Each resume(value) runs the body until it calls
+// yield(value) or finishes; yield hands its value back to resume, and
+// returns the value of the next resume. The body runs on a goroutine of its
+// own, but only ever while a resume waits for it, so the body and the code
+// resuming it take turns rather than running at the same time. A coroutine
+// left paused keeps its goroutine waiting until the program exits.
+type Coroutine struct {
+	body func(first Object) Object
+
+	mu      sync.Mutex
+	started bool
+	running bool
+	done    bool
+	resumed chan Object // resume -> body: the value yield returns
+	yielded chan Object // body -> resume: a yielded value, or the body's result
+}
+
+// NewCoroutine returns a coroutine that runs body when first resumed, with
+// the value it was resumed with. body calls Yield to pause, and returns once
+// it is done.
+func NewCoroutine(body func(first Object) Object) *Coroutine {
+	return &Coroutine{
+		body:    body,
+		resumed: make(chan Object),
+		yielded: make(chan Object),
+	}
+}
+
+func (c *Coroutine) Type() string {
+	return "COROUTINE"
+}
+
+func (c *Coroutine) Inspect() string {
+	return "<coroutine>"
+}
+
+// Resume runs the body, with val, until it yields or finishes, and returns
+// the value it yielded or served - or the error it failed with. Resuming a
+// coroutine that is done, or that is running already, is an error.
+func (c *Coroutine) Resume(val Object) Object {
+	c.mu.Lock()
+	if c.done {
+		c.mu.Unlock()
+		return &Error{Code: diagnostics.CodeBadCoroutineUse, Message: "resume of finished coroutine"}
+	}
+	if c.running {
+		c.mu.Unlock()
+		return &Error{Code: diagnostics.CodeBadCoroutineUse, Message: "resume of running coroutine"}
+	}
+	c.running = true
+	started := c.started
+	c.started = true
+	c.mu.Unlock()
+
+	if started {
+		c.resumed <- val
+	} else {
+		go func() {
+			result := c.body(val)
+			c.mu.Lock()
+			c.running, c.done = false, true
+			c.mu.Unlock()
+			c.yielded <- result
+		}()
+	}
+	return <-c.yielded
+}
+
+// Yield hands val to the resume that is running the body, and waits for the
+// next resume, whose value it returns. Only the body can yield, while it
+// runs.
+func (c *Coroutine) Yield(val Object) Object {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return &Error{Code: diagnostics.CodeBadCoroutineUse, Message: "yield outside the coroutine's body"}
+	}
+	c.running = false
+	c.mu.Unlock()
+
+	c.yielded <- val
+	return <-c.resumed
+}
+
+// Done reports whether the body has finished.
+func (c *Coroutine) Done() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done
+}
+
+// Get returns the coroutine's methods: resume(value), yield(value) and
+// done(). The value of resume and yield can be left out, for null.
+func (c *Coroutine) Get(name string) (Object, bool) {
+	switch name {
+	case "resume", "yield":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if len(args) > 1 {
+				return &Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("%s takes 0 or 1 arguments, got %d", name, len(args))}
+			}
+			var val Object = NULL
+			if len(args) == 1 {
+				val = args[0]
+			}
+			if name == "resume" {
+				return c.Resume(val)
+			}
+			return c.Yield(val)
+		}}, true
+	case "done":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("done", args, 0); err != nil {
+				return err
+			}
+			if c.Done() {
+				return TRUE
+			}
+			return FALSE
+		}}, true
+	}
+	return nil, false
+}
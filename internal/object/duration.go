@@ -0,0 +1,105 @@
+package object
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Duration is a length of time, made by time.duration or a stopwatch. Like
+// integers, durations are values: + and - combine them, * and / scale them
+// by integers, and they compare with == and <. d.ms, d.seconds, d.minutes and
+// d.hours give it in whole units.
+type Duration struct {
+	Value time.Duration
+}
+
+func (d *Duration) Type() string {
+	return "DURATION"
+}
+
+// Inspect shows the duration as Go does: 1.5s, 250ms, 1m30s.
+func (d *Duration) Inspect() string {
+	return d.Value.String()
+}
+
+// Get returns the duration in whole milliseconds, seconds, minutes or hours,
+// rounded toward zero.
+func (d *Duration) Get(name string) (Object, bool) {
+	var unit time.Duration
+	switch name {
+	case "ms":
+		unit = time.Millisecond
+	case "seconds":
+		unit = time.Second
+	case "minutes":
+		unit = time.Minute
+	case "hours":
+		unit = time.Hour
+	default:
+		return nil, false
+	}
+	return &Integer{Value: int64(d.Value / unit)}, true
+}
+
+// Stopwatch measures the time since it was started or last reset, from
+// time.stopwatch(). It reads the monotonic clock, so changes to the system
+// clock don't disturb it. Tasks can share one.
+type Stopwatch struct {
+	mu    sync.Mutex
+	start time.Time
+}
+
+// NewStopwatch returns a stopwatch started now.
+func NewStopwatch() *Stopwatch {
+	return &Stopwatch{start: time.Now()}
+}
+
+func (s *Stopwatch) Type() string {
+	return "STOPWATCH"
+}
+
+func (s *Stopwatch) Inspect() string {
+	return fmt.Sprintf("<stopwatch %s>", s.Elapsed())
+}
+
+// Elapsed is the time since the stopwatch was started or last reset.
+func (s *Stopwatch) Elapsed() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Since(s.start)
+}
+
+// Reset starts the stopwatch again from zero, and returns the time it had
+// measured.
+func (s *Stopwatch) Reset() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	elapsed := now.Sub(s.start)
+	s.start = now
+	return elapsed
+}
+
+// Get returns the stopwatch's methods: elapsed(), the time since it was
+// started or reset, and reset(), which starts it again and returns the time
+// it had measured - a lap.
+func (s *Stopwatch) Get(name string) (Object, bool) {
+	switch name {
+	case "elapsed":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("elapsed", args, 0); err != nil {
+				return err
+			}
+			return &Duration{Value: s.Elapsed()}
+		}}, true
+	case "reset":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("reset", args, 0); err != nil {
+				return err
+			}
+			return &Duration{Value: s.Reset()}
+		}}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,109 @@
+package object
+
+import "sync"
+
+// Generator is what calling a generator function - one whose body contains
+// 'yield' - returns: the values the body yields, produced on demand. The body
+// runs on a goroutine of its own, which starts on the first call to Next and
+// waits at each 'yield' until the next call, so it never gets more than one
+// value ahead of the loop consuming it.
+type Generator struct {
+	body func() Object
+
+	mu      sync.Mutex // held by Next and Stop, one consumer at a time
+	started bool
+	done    bool
+	values  chan Object   // each yielded value; closed when the body ends
+	next    chan struct{} // lets the body carry on from a yield
+	stopped chan struct{} // closed by Stop
+	result  Object        // what the body returned
+}
+
+// NewGenerator returns a generator that runs body to produce its values.
+// body calls Yield for each one, and returns once it is done.
+func NewGenerator(body func() Object) *Generator {
+	return &Generator{
+		body:    body,
+		values:  make(chan Object),
+		next:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (g *Generator) Type() string {
+	return "GENERATOR"
+}
+
+func (g *Generator) Inspect() string {
+	return "<generator>"
+}
+
+// Next runs the body until it yields a value, and returns the value. It
+// returns false once the body has finished; Err then tells whether it
+// failed.
+func (g *Generator) Next() (Object, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.done {
+		return nil, false
+	}
+	if g.started {
+		g.next <- struct{}{}
+	} else {
+		g.started = true
+		go func() {
+			g.result = g.body()
+			close(g.values)
+		}()
+	}
+
+	val, ok := <-g.values
+	if !ok {
+		g.done = true
+	}
+	return val, ok
+}
+
+// Yield hands val to the consumer and waits until it asks for the next
+// value. It returns false if the generator was stopped instead, in which case
+// the body should return straight away.
+func (g *Generator) Yield(val Object) bool {
+	select {
+	case g.values <- val:
+	case <-g.stopped:
+		return false
+	}
+	select {
+	case <-g.next:
+		return true
+	case <-g.stopped:
+		return false
+	}
+}
+
+// Stop tells a body waiting at a yield that no more values are wanted, and
+// waits for it to finish. It can be called more than once.
+func (g *Generator) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.done {
+		return
+	}
+	g.done = true
+	close(g.stopped)
+	if g.started {
+		for range g.values {
+		}
+	}
+}
+
+// Err returns the error the body failed with, once Next has returned false.
+func (g *Generator) Err() *Error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.done {
+		return nil
+	}
+	err, _ := g.result.(*Error)
+	return err
+}
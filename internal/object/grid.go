@@ -0,0 +1,397 @@
+package object
+
+import (
+	"container/heap"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+)
+
+// Grid is a dense 2D grid of values, made by grid.new, for tile maps and the
+// like. The cells are one Go slice, row by row, and its methods - fills,
+// flood fills, pathfinding - run natively rather than as Beeflang loops over
+// nested arrays. Positions are (x, y), with (0, 0) the top-left cell;
+// methods that return positions return them as vectors. Tasks can share a
+// grid: its methods lock it.
+type Grid struct {
+	Width, Height int
+
+	mu    sync.RWMutex
+	cells []Object // row by row: (x, y) is cells[y*Width+x]
+}
+
+// MaxGridCells caps the size of a grid, so a typo in grid.new - or a
+// damaged save file - can't ask for more memory than the machine has.
+const MaxGridCells = 1 << 24
+
+// NewGrid returns a width by height grid with every cell set to fill.
+func NewGrid(width, height int, fill Object) *Grid {
+	g := &Grid{Width: width, Height: height, cells: make([]Object, width*height)}
+	for i := range g.cells {
+		g.cells[i] = fill
+	}
+	return g
+}
+
+func (g *Grid) Type() string {
+	return "GRID"
+}
+
+func (g *Grid) Inspect() string {
+	return fmt.Sprintf("<grid %dx%d>", g.Width, g.Height)
+}
+
+// InBounds reports whether (x, y) is a cell of the grid.
+func (g *Grid) InBounds(x, y int64) bool {
+	return x >= 0 && y >= 0 && x < int64(g.Width) && y < int64(g.Height)
+}
+
+// At returns the value at (x, y), which must be in bounds.
+func (g *Grid) At(x, y int64) Object {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.cells[y*int64(g.Width)+x]
+}
+
+// SetAt changes the value at (x, y), which must be in bounds.
+func (g *Grid) SetAt(x, y int64, value Object) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cells[y*int64(g.Width)+x] = value
+}
+
+// Cells returns a copy of the cells, row by row.
+func (g *Grid) Cells() []Object {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return slices.Clone(g.cells)
+}
+
+// Get returns the grid's size, as width and height, and its methods:
+//   - get(x, y), set(x, y, value): read or change a cell
+//   - in_bounds(x, y): whether (x, y) is a cell
+//   - fill(value), fill(x, y, w, h, value): set every cell, or those of a
+//     rectangle (clipped to the grid)
+//   - neighbors(x, y), neighbors(x, y, true): the positions next to a cell,
+//     up, left, right and down, and with true the diagonals too
+//   - region(x, y): the positions of the cells joined to (x, y) through
+//     cells equal to it, side by side - a room, say
+//   - flood(x, y, value): set the cells of region(x, y) to value; returns
+//     how many there were
+//   - path(x0, y0, x1, y1, blocked): the shortest path from (x0, y0) to
+//     (x1, y1) stepping up, left, right and down, both ends included, or
+//     null if there is none. blocked is a value, or an array of values,
+//     that cells can't be walked through
+func (g *Grid) Get(name string) (Object, bool) {
+	switch name {
+	case "width":
+		return &Integer{Value: int64(g.Width)}, true
+	case "height":
+		return &Integer{Value: int64(g.Height)}, true
+	case "get":
+		return &Builtin{Fn: func(args ...Object) Object {
+			x, y, err := g.position("get", args, 2)
+			if err != nil {
+				return err
+			}
+			return g.At(x, y)
+		}}, true
+	case "set":
+		return &Builtin{Fn: func(args ...Object) Object {
+			x, y, err := g.position("set", args, 3)
+			if err != nil {
+				return err
+			}
+			g.SetAt(x, y, args[2])
+			return NULL
+		}}, true
+	case "in_bounds":
+		return &Builtin{Fn: func(args ...Object) Object {
+			x, y, err := coordinates("in_bounds", args, 2)
+			if err != nil {
+				return err
+			}
+			return nativeBool(g.InBounds(x, y))
+		}}, true
+	case "fill":
+		return &Builtin{Fn: g.fill}, true
+	case "neighbors":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if len(args) != 2 && len(args) != 3 {
+				return &Error{Code: diagnostics.CodeBadArgument,
+					Message: fmt.Sprintf("neighbors takes 2 or 3 arguments, got %d", len(args))}
+			}
+			x, y, err := g.position("neighbors", args, len(args))
+			if err != nil {
+				return err
+			}
+			steps := sideSteps
+			if len(args) == 3 && args[2] == TRUE {
+				steps = allSteps
+			}
+			var positions []Object
+			for _, step := range steps {
+				if g.InBounds(x+step[0], y+step[1]) {
+					positions = append(positions, position(x+step[0], y+step[1]))
+				}
+			}
+			return NewArray(positions)
+		}}, true
+	case "region":
+		return &Builtin{Fn: func(args ...Object) Object {
+			x, y, err := g.position("region", args, 2)
+			if err != nil {
+				return err
+			}
+			g.mu.RLock()
+			cells := g.region(x, y)
+			g.mu.RUnlock()
+			positions := make([]Object, len(cells))
+			for i, cell := range cells {
+				positions[i] = position(int64(cell%g.Width), int64(cell/g.Width))
+			}
+			return NewArray(positions)
+		}}, true
+	case "flood":
+		return &Builtin{Fn: func(args ...Object) Object {
+			x, y, err := g.position("flood", args, 3)
+			if err != nil {
+				return err
+			}
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			cells := g.region(x, y)
+			for _, cell := range cells {
+				g.cells[cell] = args[2]
+			}
+			return &Integer{Value: int64(len(cells))}
+		}}, true
+	case "path":
+		return &Builtin{Fn: g.path}, true
+	}
+	return nil, false
+}
+
+var (
+	sideSteps = [][2]int64{{0, -1}, {-1, 0}, {1, 0}, {0, 1}}
+	allSteps  = [][2]int64{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+)
+
+// coordinates reads the integer x and y at the start of a method's want
+// arguments.
+func coordinates(name string, args []Object, want int) (int64, int64, *Error) {
+	if err := CheckArgCount(name, args, want); err != nil {
+		return 0, 0, err
+	}
+	x, err := IntegerArg(name, args[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := IntegerArg(name, args[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return x, y, nil
+}
+
+// position reads coordinates that must be a cell of the grid.
+func (g *Grid) position(name string, args []Object, want int) (int64, int64, *Error) {
+	x, y, err := coordinates(name, args, want)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !g.InBounds(x, y) {
+		return 0, 0, &Error{Code: diagnostics.CodeBadIndex,
+			Message: fmt.Sprintf("%s: (%d, %d) is outside a %dx%d grid", name, x, y, g.Width, g.Height)}
+	}
+	return x, y, nil
+}
+
+func position(x, y int64) *Vector {
+	return &Vector{Components: []int64{x, y}}
+}
+
+func (g *Grid) fill(args ...Object) Object {
+	switch len(args) {
+	case 1:
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		for i := range g.cells {
+			g.cells[i] = args[0]
+		}
+		return NULL
+	case 5:
+		var rect [4]int64
+		for i := range rect {
+			n, err := IntegerArg("fill", args[i])
+			if err != nil {
+				return err
+			}
+			rect[i] = n
+		}
+		x0, y0 := max(rect[0], 0), max(rect[1], 0)
+		x1, y1 := min(rect[0]+rect[2], int64(g.Width)), min(rect[1]+rect[3], int64(g.Height))
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				g.cells[y*int64(g.Width)+x] = args[4]
+			}
+		}
+		return NULL
+	}
+	return &Error{Code: diagnostics.CodeBadArgument,
+		Message: fmt.Sprintf("fill takes 1 or 5 arguments (value, or x, y, width, height, value), got %d", len(args))}
+}
+
+// region returns the indexes of the cells joined to (x, y) side by side
+// through cells equal to it, starting with (x, y) itself. The caller holds
+// the lock.
+func (g *Grid) region(x, y int64) []int {
+	start := int(y)*g.Width + int(x)
+	match := g.cells[start]
+	seen := map[int]bool{start: true}
+	cells := []int{start}
+	for i := 0; i < len(cells); i++ {
+		cx, cy := int64(cells[i]%g.Width), int64(cells[i]/g.Width)
+		for _, step := range sideSteps {
+			nx, ny := cx+step[0], cy+step[1]
+			next := int(ny)*g.Width + int(nx)
+			if g.InBounds(nx, ny) && !seen[next] && sameValue(g.cells[next], match) {
+				seen[next] = true
+				cells = append(cells, next)
+			}
+		}
+	}
+	return cells
+}
+
+func (g *Grid) path(args ...Object) Object {
+	if err := CheckArgCount("path", args, 5); err != nil {
+		return err
+	}
+	x0, y0, err := g.position("path", args[:2], 2)
+	if err != nil {
+		return err
+	}
+	x1, y1, err := g.position("path", args[2:4], 2)
+	if err != nil {
+		return err
+	}
+	blocked := []Object{args[4]}
+	if arr, ok := args[4].(*Array); ok {
+		blocked = arr.Elements()
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	walkable := func(cell int) bool {
+		for _, b := range blocked {
+			if sameValue(g.cells[cell], b) {
+				return false
+			}
+		}
+		return true
+	}
+
+	start, goal := int(y0)*g.Width+int(x0), int(y1)*g.Width+int(x1)
+	if !walkable(goal) {
+		return NULL
+	}
+	distance := func(cell int) int {
+		return abs(cell%g.Width-int(x1)) + abs(cell/g.Width-int(y1))
+	}
+
+	// A*, with the Manhattan distance to the goal as the estimate
+	cost := map[int]int{start: 0}
+	from := map[int]int{}
+	open := &pathQueue{{cell: start, estimate: distance(start)}}
+	pushed := 0
+	for open.Len() > 0 {
+		current := heap.Pop(open).(pathNode)
+		if current.cell == goal {
+			var positions []Object
+			for cell := goal; ; cell = from[cell] {
+				positions = append(positions, position(int64(cell%g.Width), int64(cell/g.Width)))
+				if cell == start {
+					break
+				}
+			}
+			for i, j := 0, len(positions)-1; i < j; i, j = i+1, j-1 {
+				positions[i], positions[j] = positions[j], positions[i]
+			}
+			return NewArray(positions)
+		}
+		if current.cost > cost[current.cell] {
+			continue // a shorter way here was found after this was queued
+		}
+		cx, cy := int64(current.cell%g.Width), int64(current.cell/g.Width)
+		for _, step := range sideSteps {
+			nx, ny := cx+step[0], cy+step[1]
+			next := int(ny)*g.Width + int(nx)
+			if !g.InBounds(nx, ny) || !walkable(next) {
+				continue
+			}
+			nextCost := current.cost + 1
+			if known, ok := cost[next]; ok && known <= nextCost {
+				continue
+			}
+			cost[next] = nextCost
+			from[next] = current.cell
+			pushed++
+			heap.Push(open, pathNode{cell: next, cost: nextCost, estimate: nextCost + distance(next), order: pushed})
+		}
+	}
+	return NULL
+}
+
+// pathNode is a cell waiting to be explored by path, with the cost of the
+// best way found to it and the estimated cost of a path through it.
+type pathNode struct {
+	cell, cost, estimate int
+	order                int // breaks ties, so paths don't depend on heap order
+}
+
+type pathQueue []pathNode
+
+func (q pathQueue) Len() int { return len(q) }
+func (q pathQueue) Less(i, j int) bool {
+	if q[i].estimate != q[j].estimate {
+		return q[i].estimate < q[j].estimate
+	}
+	return q[i].order < q[j].order
+}
+func (q pathQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x any)   { *q = append(*q, x.(pathNode)) }
+func (q *pathQueue) Pop() any {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
+
+// sameValue reports whether two cell values are equal: by value for those
+// that can be hash keys (integers, strings, booleans), otherwise by identity.
+func sameValue(a, b Object) bool {
+	ha, ok := a.(Hashable)
+	if !ok {
+		return a == b
+	}
+	hb, ok := b.(Hashable)
+	return ok && ha.HashKey() == hb.HashKey()
+}
+
+func nativeBool(b bool) *Boolean {
+	if b {
+		return TRUE
+	}
+	return FALSE
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
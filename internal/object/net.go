@@ -0,0 +1,267 @@
+package object
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+)
+
+// readChunk is the most read() returns at once.
+const readChunk = 4096
+
+// Connection is an open TCP connection, from net.dial or a listener's accept().
+// Like the values in concurrency.go it is safe to share between tasks: one
+// task can read while another writes.
+type Connection struct {
+	conn   net.Conn
+	readMu sync.Mutex // reads share a buffer, so take turns
+	reader *bufio.Reader
+}
+
+// NewConnection wraps an established connection.
+func NewConnection(conn net.Conn) *Connection {
+	return &Connection{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *Connection) Type() string {
+	return "CONNECTION"
+}
+
+func (c *Connection) Inspect() string {
+	return fmt.Sprintf("<connection %s>", c.conn.RemoteAddr())
+}
+
+// Read waits for data and returns what has arrived, up to readChunk bytes.
+// It returns NULL once the other end has closed the connection.
+func (c *Connection) Read() Object {
+	data, result := c.readChunk()
+	if data == nil {
+		return result
+	}
+	return &String{Value: string(data)}
+}
+
+// ReadBytes is Read for binary data: it returns what has arrived as bytes.
+func (c *Connection) ReadBytes() Object {
+	data, result := c.readChunk()
+	if data == nil {
+		return result
+	}
+	return &Bytes{Value: data}
+}
+
+// readChunk waits for data and returns it, or if there was none, what the
+// read returns instead.
+func (c *Connection) readChunk() ([]byte, Object) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	buf := make([]byte, readChunk)
+	n, err := c.reader.Read(buf)
+	if n > 0 {
+		return buf[:n], nil
+	}
+	return nil, readResult(err)
+}
+
+// ReadLine waits for a whole line and returns it without its line ending
+// ("\n" or "\r\n"). A last line the other end didn't finish before closing is
+// returned as is; after that ReadLine returns NULL.
+func (c *Connection) ReadLine() Object {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	line, err := c.reader.ReadString('\n')
+	if line != "" {
+		return &String{Value: strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")}
+	}
+	return readResult(err)
+}
+
+// readResult is what a read that got no data returns: NULL at the end of the
+// stream, an error otherwise.
+func readResult(err error) Object {
+	if err == nil || errors.Is(err, io.EOF) {
+		return NULL
+	}
+	return networkError(err)
+}
+
+// Write sends s in full.
+func (c *Connection) Write(s string) *Error {
+	if _, err := io.WriteString(c.conn, s); err != nil {
+		return networkError(err)
+	}
+	return nil
+}
+
+// WriteBytes sends data in full.
+func (c *Connection) WriteBytes(data []byte) *Error {
+	if _, err := c.conn.Write(data); err != nil {
+		return networkError(err)
+	}
+	return nil
+}
+
+// Close closes the connection. Closing it twice returns an error.
+func (c *Connection) Close() *Error {
+	if err := c.conn.Close(); err != nil {
+		return networkError(err)
+	}
+	return nil
+}
+
+// Get returns the connection's methods: read(), read_line(), write(s),
+// read_bytes(), write_bytes(b), close() and remote() (the other end's
+// address, "host:port").
+func (c *Connection) Get(name string) (Object, bool) {
+	switch name {
+	case "read":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("read", args, 0); err != nil {
+				return err
+			}
+			return c.Read()
+		}}, true
+	case "read_line":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("read_line", args, 0); err != nil {
+				return err
+			}
+			return c.ReadLine()
+		}}, true
+	case "write":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("write", args, 1); err != nil {
+				return err
+			}
+			s, err := StringArg("write", args[0])
+			if err != nil {
+				return err
+			}
+			if err := c.Write(s); err != nil {
+				return err
+			}
+			return NULL
+		}}, true
+	case "read_bytes":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("read_bytes", args, 0); err != nil {
+				return err
+			}
+			return c.ReadBytes()
+		}}, true
+	case "write_bytes":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("write_bytes", args, 1); err != nil {
+				return err
+			}
+			data, err := BytesArg("write_bytes", args[0])
+			if err != nil {
+				return err
+			}
+			if err := c.WriteBytes(data); err != nil {
+				return err
+			}
+			return NULL
+		}}, true
+	case "close":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("close", args, 0); err != nil {
+				return err
+			}
+			if err := c.Close(); err != nil {
+				return err
+			}
+			return NULL
+		}}, true
+	case "remote":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("remote", args, 0); err != nil {
+				return err
+			}
+			return &String{Value: c.conn.RemoteAddr().String()}
+		}}, true
+	}
+	return nil, false
+}
+
+// Listener accepts TCP connections on a port, from net.listen.
+type Listener struct {
+	listener net.Listener
+}
+
+// NewListener wraps a listening socket.
+func NewListener(listener net.Listener) *Listener {
+	return &Listener{listener: listener}
+}
+
+func (l *Listener) Type() string {
+	return "LISTENER"
+}
+
+func (l *Listener) Inspect() string {
+	return fmt.Sprintf("<listener %s>", l.listener.Addr())
+}
+
+// Accept waits for the next incoming connection.
+func (l *Listener) Accept() (*Connection, *Error) {
+	conn, err := l.listener.Accept()
+	if err != nil {
+		return nil, networkError(err)
+	}
+	return NewConnection(conn), nil
+}
+
+// Port is the port the listener is on, which is how a program listening on
+// port 0 finds out which free port it was given.
+func (l *Listener) Port() int {
+	if addr, ok := l.listener.Addr().(*net.TCPAddr); ok {
+		return addr.Port
+	}
+	return 0
+}
+
+// Get returns the listener's methods: accept(), port() and close().
+func (l *Listener) Get(name string) (Object, bool) {
+	switch name {
+	case "accept":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("accept", args, 0); err != nil {
+				return err
+			}
+			conn, err := l.Accept()
+			if err != nil {
+				return err
+			}
+			return conn
+		}}, true
+	case "port":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("port", args, 0); err != nil {
+				return err
+			}
+			return &Integer{Value: int64(l.Port())}
+		}}, true
+	case "close":
+		return &Builtin{Fn: func(args ...Object) Object {
+			if err := CheckArgCount("close", args, 0); err != nil {
+				return err
+			}
+			if err := l.listener.Close(); err != nil {
+				return networkError(err)
+			}
+			return NULL
+		}}, true
+	}
+	return nil, false
+}
+
+// networkError reports a failed network operation.
+func networkError(err error) *Error {
+	return &Error{Code: diagnostics.CodeNetworkError, Message: err.Error()}
+}
@@ -0,0 +1,78 @@
+package object
+
+import (
+	"net"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/stretchr/testify/assert"
+)
+
+// connectionPair returns both ends of a local TCP connection
+func connectionPair(t *testing.T) (*Connection, *Connection) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := NewConnection(client), NewConnection(server)
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+	return a, b
+}
+
+func TestConnectionReadLine(t *testing.T) {
+	client, server := connectionPair(t)
+
+	assert.Nil(t, client.Write("brisket\r\nribs\nhalf a li"))
+	assert.Nil(t, client.Write("ne"))
+	assert.Nil(t, client.conn.(*net.TCPConn).CloseWrite())
+
+	assert.Equal(t, &String{Value: "brisket"}, server.ReadLine())
+	assert.Equal(t, &String{Value: "ribs"}, server.ReadLine())
+	assert.Equal(t, &String{Value: "half a line"}, server.ReadLine())
+	assert.Equal(t, NULL, server.ReadLine())
+}
+
+func TestConnectionReadReturnsNullAtEnd(t *testing.T) {
+	client, server := connectionPair(t)
+
+	assert.Nil(t, client.Write("smoke"))
+	assert.Nil(t, client.Close())
+
+	assert.Equal(t, &String{Value: "smoke"}, server.Read())
+	assert.Equal(t, NULL, server.Read())
+}
+
+func TestConnectionBinaryData(t *testing.T) {
+	client, server := connectionPair(t)
+
+	assert.Nil(t, client.WriteBytes([]byte{0, 0xff, '\n', 7}))
+	assert.Nil(t, client.Close())
+
+	assert.Equal(t, &Bytes{Value: []byte{0, 0xff, '\n', 7}}, server.ReadBytes())
+	assert.Equal(t, NULL, server.ReadBytes())
+}
+
+func TestConnectionErrors(t *testing.T) {
+	client, _ := connectionPair(t)
+	assert.Nil(t, client.Close())
+
+	err := client.Write("late")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, diagnostics.CodeNetworkError, err.Code)
+	}
+	assert.NotNil(t, client.Close(), "closing twice should fail")
+}
@@ -2,6 +2,8 @@ package object
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/elitwilson/beeflang/internal/ast"
 )
@@ -78,14 +80,22 @@ type Function struct {
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment // Closure: captures environment where function was defined
+	Name       string       // Name the function was declared with
+	Doc        string       // Docstring, or doc comment text from above the declaration (empty if none)
+	File       string       // Source file the function was declared in (empty if unknown)
 }
 
 func (f *Function) Type() string {
 	return "FUNCTION"
 }
 
+// Inspect shows the function's name and parameters: <function add(x, y)>.
 func (f *Function) Inspect() string {
-	return "<function>"
+	params := make([]string, len(f.Parameters))
+	for i, p := range f.Parameters {
+		params[i] = p.Value
+	}
+	return "<function " + f.Name + "(" + strings.Join(params, ", ") + ")>"
 }
 
 // ReturnValue wraps a value that's being returned from a function.
@@ -114,9 +124,16 @@ func (rv *ReturnValue) Inspect() string {
 //   inner.Set("y", &Integer{Value: 20})
 //   inner.Get("x")  // finds x in outer scope
 //   inner.Get("y")  // finds y in inner scope
+//
+// Environments are safe for concurrent use: tasks started with 'stampede'
+// run in environments of their own, but still read the scopes they enclose.
 type Environment struct {
-	store map[string]Object
-	outer *Environment // pointer to enclosing (parent) scope
+	mu       sync.RWMutex
+	store    map[string]Object
+	outer    *Environment // pointer to enclosing (parent) scope
+	deferred []func() Object
+	gen      *Generator // the generator a generator function's call produces values for
+	host     any        // what runs programs in this scope, for the evaluator; see SetHost
 }
 
 // NewEnvironment creates a new environment with no outer scope (global scope).
@@ -130,14 +147,27 @@ func NewEnvironment() *Environment {
 func NewEnclosedEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
+	env.host = outer.host
 	return env
 }
 
+// SetHost records what runs programs in a global environment - the
+// evaluator's per-program state. Scopes enclosed by it share its host.
+func (e *Environment) SetHost(host any) {
+	e.host = host
+}
+
+// Host returns what SetHost recorded for the environment's global scope, or
+// nil.
+func (e *Environment) Host() any {
+	return e.host
+}
+
 // Get retrieves a variable from the environment.
 // It searches the current scope first, then walks up the outer scopes.
 // Returns (value, true) if found, (nil, false) if not found.
 func (e *Environment) Get(name string) (Object, bool) {
-	obj, ok := e.store[name]
+	obj, ok := e.GetLocal(name)
 	if !ok && e.outer != nil {
 		// Not found in current scope, check outer scope
 		obj, ok = e.outer.Get(name)
@@ -145,13 +175,94 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return obj, ok
 }
 
+// GetLocal retrieves a variable from the current scope only, ignoring outer scopes.
+func (e *Environment) GetLocal(name string) (Object, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	obj, ok := e.store[name]
+	return obj, ok
+}
+
+// Outer returns the enclosing scope, or nil for a global environment.
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
+
 // Set stores a variable in the current environment scope.
 // This does NOT modify outer scopes - it creates/updates in the current scope only.
 func (e *Environment) Set(name string, val Object) Object {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	e.store[name] = val
 	return val
 }
 
+// Bindings returns a copy of the variables defined directly in this scope.
+// Outer scopes are not included. Used to turn a module's global scope into module members.
+func (e *Environment) Bindings() map[string]Object {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	bindings := make(map[string]Object, len(e.store))
+	for name, val := range e.store {
+		bindings[name] = val
+	}
+	return bindings
+}
+
+// Snapshot returns a copy of this scope and every scope it encloses, holding
+// the same values. Changes to the copy's variables don't reach the original
+// or the other way round, but the values themselves are shared: channels and
+// counters, and arrays and hashes too, which lock themselves for that.
+func (e *Environment) Snapshot() *Environment {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	snapshot := NewEnvironment()
+	snapshot.host = e.host
+	for name, val := range e.store {
+		snapshot.store[name] = val
+	}
+	if e.outer != nil {
+		snapshot.outer = e.outer.Snapshot()
+	}
+	return snapshot
+}
+
+// Defer schedules call to run when the function call this environment belongs
+// to returns (see the 'dessert' statement).
+func (e *Environment) Defer(call func() Object) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.deferred = append(e.deferred, call)
+}
+
+// TakeDeferred removes and returns the calls scheduled with Defer, most
+// recently scheduled first.
+func (e *Environment) TakeDeferred() []func() Object {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	calls := make([]func() Object, len(e.deferred))
+	for i, call := range e.deferred {
+		calls[len(calls)-1-i] = call
+	}
+	e.deferred = nil
+	return calls
+}
+
+// SetGenerator makes this function call's environment the body of g, so
+// that its 'yield' statements produce g's values.
+func (e *Environment) SetGenerator(g *Generator) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.gen = g
+}
+
+// Generator returns the generator set with SetGenerator, if any.
+func (e *Environment) Generator() *Generator {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.gen
+}
+
 // Singleton instances used throughout the interpreter for efficiency.
 // Instead of creating new objects, we reuse these single instances.
 var (
@@ -160,11 +271,20 @@ var (
 	FALSE = &Boolean{Value: false}
 )
 
+// Container is implemented by values with members reachable through '.',
+// like modules (io.preach) and channels (ch.send).
+type Container interface {
+	Object
+	Get(name string) (Object, bool)
+}
+
 // Module represents a module/namespace containing functions and values.
 // Used for organizing standard library and user modules (e.g., io.preach).
 type Module struct {
 	Name    string
 	Members map[string]Object
+	Doc     string // module doc comment (empty for built-in modules and undocumented files)
+	Pasture string // namespace its file declared with 'pasture mygame.ai' (empty if none)
 }
 
 func (m *Module) Type() string {
@@ -186,10 +306,37 @@ func (m *Module) Set(name string, val Object) {
 	m.Members[name] = val
 }
 
+// Namespace groups the members of modules whose files declare the same
+// pasture. Wrangling a module with 'pasture mygame.ai' binds mygame, a
+// namespace whose member ai is a namespace holding the module's members,
+// alongside those of any other module in mygame.ai.
+type Namespace struct {
+	Name    string // the full dotted name, like mygame.ai
+	Members map[string]Object
+}
+
+func (n *Namespace) Type() string {
+	return "NAMESPACE"
+}
+
+func (n *Namespace) Inspect() string {
+	return fmt.Sprintf("<namespace '%s'>", n.Name)
+}
+
+// Get retrieves a member, a module's or a nested namespace, by name.
+func (n *Namespace) Get(name string) (Object, bool) {
+	obj, ok := n.Members[name]
+	return obj, ok
+}
+
 // Builtin represents a built-in function implemented in Go.
 // The Fn field is a Go function that takes Object arguments and returns an Object.
 type Builtin struct {
 	Fn func(args ...Object) Object
+	// EnvFn, if set, is used instead of Fn by calls written in the program,
+	// and is also given the environment the call is made in. Fn is still
+	// used when the builtin is called from Go, by another builtin.
+	EnvFn func(env *Environment, args ...Object) Object
 }
 
 func (b *Builtin) Type() string {
@@ -216,6 +363,10 @@ type Error struct {
 	Line    int    // Line number where error occurred (from Token)
 	Column  int    // Column number where error occurred (from Token)
 	File    string // Source file path (empty string if not from file)
+	Code    string // Stable error code like "BE0003" (empty if uncategorized)
+
+	EndLine   int // Line just past the offending source text (0 if unknown)
+	EndColumn int // Column just past the offending source text (0 if unknown)
 }
 
 func (e *Error) Type() string {
@@ -223,13 +374,17 @@ func (e *Error) Type() string {
 }
 
 func (e *Error) Inspect() string {
+	label := "Error"
+	if e.Code != "" {
+		label = "Error[" + e.Code + "]"
+	}
 	if e.File != "" {
-		return fmt.Sprintf("Error at %s:%d:%d - %s",
-			e.File, e.Line, e.Column, e.Message)
+		return fmt.Sprintf("%s at %s:%d:%d - %s",
+			label, e.File, e.Line, e.Column, e.Message)
 	}
 	if e.Line > 0 {
-		return fmt.Sprintf("Error at line %d, column %d - %s",
-			e.Line, e.Column, e.Message)
+		return fmt.Sprintf("%s at line %d, column %d - %s",
+			label, e.Line, e.Column, e.Message)
 	}
-	return "Error: " + e.Message
+	return label + ": " + e.Message
 }
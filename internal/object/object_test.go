@@ -219,6 +219,20 @@ func TestErrorObjectInspectWithFile(t *testing.T) {
 	assert.Equal(t, "Error at examples/test.beef:12:5 - type mismatch", err.Inspect())
 }
 
+func TestErrorObjectInspectWithCode(t *testing.T) {
+	err := &Error{
+		Message: "type mismatch",
+		Line:    12,
+		Column:  5,
+		File:    "examples/test.beef",
+		Code:    "BE0003",
+	}
+	assert.Equal(t, "Error[BE0003] at examples/test.beef:12:5 - type mismatch", err.Inspect())
+
+	err = &Error{Message: "type mismatch", Code: "BE0003"}
+	assert.Equal(t, "Error[BE0003]: type mismatch", err.Inspect())
+}
+
 func TestErrorImplementsObjectInterface(t *testing.T) {
 	var _ Object = &Error{}
 }
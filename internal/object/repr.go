@@ -0,0 +1,67 @@
+package object
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Repr shows a value unambiguously, for debugging: strings are quoted with
+// escapes, big integers keep their n, and a hash's __str is ignored. Values
+// with no literal form, such as functions and channels, show as a tag in
+// angle brackets. An array or hash inside itself shows as [...] or {...}.
+func Repr(obj Object) string {
+	var b strings.Builder
+	writeRepr(&b, obj, map[Object]bool{})
+	return b.String()
+}
+
+func writeRepr(b *strings.Builder, obj Object, open map[Object]bool) {
+	switch obj := obj.(type) {
+	case *String:
+		b.WriteString(strconv.Quote(obj.Value))
+	case *BigInteger:
+		b.WriteString(obj.Value.String() + "n")
+	case *Integer, *Boolean, *Null, *Function:
+		b.WriteString(obj.Inspect())
+	case *Error:
+		b.WriteString("<error: " + obj.Message + ">")
+	case *Array:
+		if open[obj] {
+			b.WriteString("[...]")
+			return
+		}
+		open[obj] = true
+		defer delete(open, obj)
+		b.WriteString("[")
+		for i, el := range obj.Elements() {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeRepr(b, el, open)
+		}
+		b.WriteString("]")
+	case *Hash:
+		if open[obj] {
+			b.WriteString("{...}")
+			return
+		}
+		open[obj] = true
+		defer delete(open, obj)
+		b.WriteString("{")
+		for i, pair := range obj.Pairs() {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeRepr(b, pair.Key, open)
+			b.WriteString(": ")
+			writeRepr(b, pair.Value, open)
+		}
+		b.WriteString("}")
+	default:
+		text := obj.Inspect()
+		if !strings.HasPrefix(text, "<") {
+			text = "<" + strings.ToLower(obj.Type()) + " " + text + ">"
+		}
+		b.WriteString(text)
+	}
+}
@@ -0,0 +1,43 @@
+package object
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepr(t *testing.T) {
+	h := NewHash()
+	h.Set(&String{Value: "name"}, &String{Value: "Bubba \"Big\" Jones"})
+	h.Set(&Integer{Value: 1}, &BigInteger{Value: big.NewInt(7)})
+	h.Set(&String{Value: StrMember}, &Function{Name: "show"})
+
+	tests := []struct {
+		obj      Object
+		expected string
+	}{
+		{&String{Value: "two\nlines\t\\"}, `"two\nlines\t\\"`},
+		{&Integer{Value: -3}, "-3"},
+		{&BigInteger{Value: big.NewInt(3)}, "3n"},
+		{NULL, "null"},
+		{NewArray([]Object{&String{Value: "1"}, &Integer{Value: 1}, TRUE}), `["1", 1, true]`},
+		{h, `{"name": "Bubba \"Big\" Jones", 1: 7n, "__str": <function show()>}`},
+		{&Module{Name: "io"}, "<module 'io'>"},
+		{&Error{Message: "boom"}, "<error: boom>"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Repr(tt.obj))
+	}
+}
+
+func TestReprStopsAtCycles(t *testing.T) {
+	a := NewArray(nil)
+	a.elements = []Object{&Integer{Value: 1}, a}
+	assert.Equal(t, "[1, [...]]", Repr(a))
+
+	// The same array twice, side by side, isn't a cycle
+	inner := NewArray([]Object{TRUE})
+	assert.Equal(t, "[[true], [true]]", Repr(NewArray([]Object{inner, inner})))
+}
@@ -0,0 +1,47 @@
+package object
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Vector is a 2D or 3D vector of integers, made by the vec module. Vectors
+// are values, like integers: the vec functions return new vectors rather than
+// changing the ones they're given, and == compares components.
+type Vector struct {
+	Components []int64 // x, y and, for a 3D vector, z
+}
+
+func (v *Vector) Type() string {
+	return "VECTOR"
+}
+
+func (v *Vector) Inspect() string {
+	parts := make([]string, len(v.Components))
+	for i, c := range v.Components {
+		parts[i] = strconv.FormatInt(c, 10)
+	}
+	return "vec(" + strings.Join(parts, ", ") + ")"
+}
+
+// Equal reports whether two vectors have the same components.
+func (v *Vector) Equal(other *Vector) bool {
+	if len(v.Components) != len(other.Components) {
+		return false
+	}
+	for i, c := range v.Components {
+		if c != other.Components[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns the vector's components: x, y and (in 3D) z.
+func (v *Vector) Get(name string) (Object, bool) {
+	i := strings.Index("xyz", name)
+	if len(name) != 1 || i < 0 || i >= len(v.Components) {
+		return nil, false
+	}
+	return &Integer{Value: v.Components[i]}, true
+}
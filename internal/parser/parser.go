@@ -2,9 +2,13 @@ package parser
 
 import (
 	"fmt"
+	"math/big"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
 	"github.com/elitwilson/beeflang/internal/lexer"
 	"github.com/elitwilson/beeflang/internal/token"
 )
@@ -15,29 +19,41 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	PIPE        // |>
 	EQUALS      // ==
 	LESSGREATER // > or <
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
-	CALL        // myFunction(X)
+	CALL        // myFunction(X) or array[X]
 	MEMBER      // object.member
 )
 
+// maxNesting bounds how deeply expressions and blocks can nest. Each operator
+// in a chain like 1 + 2 + 3 counts as a level, since it nests the tree one
+// deeper. The parser - like every tool that walks the tree - is recursive,
+// and Go can't recover from running out of stack, so absurdly deep input is
+// rejected with an error instead of crashing.
+const maxNesting = 1000
+
 var precedences = map[token.TokenType]int{
+	token.PIPE:     PIPE,
 	token.EQ:       EQUALS,
 	token.NOT_EQ:   EQUALS,
 	token.LT:       LESSGREATER,
 	token.GT:       LESSGREATER,
 	token.LTE:      LESSGREATER,
 	token.GTE:      LESSGREATER,
+	token.IN:       LESSGREATER,
 	token.PLUS:     SUM,
 	token.MINUS:    SUM,
 	token.SLASH:    PRODUCT,
 	token.ASTERISK: PRODUCT,
 	token.PERCENT:  PRODUCT,
 	token.LPAREN:   CALL,
+	token.LBRACKET: CALL,
 	token.DOT:      MEMBER,
+	token.OPT_DOT:  MEMBER,
 }
 
 // Parser uses Pratt parsing (top-down operator precedence) to build an AST.
@@ -50,12 +66,34 @@ var precedences = map[token.TokenType]int{
 // - infixParseFns: how to parse operators between expressions (like "+" in "5 + 3")
 type Parser struct {
 	l         *lexer.Lexer
-	errors    []string
+	errors    []ParseError
 	curToken  token.Token
 	peekToken token.Token
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
+
+	depth   int  // nesting level of the node being parsed (see maxNesting)
+	pasture bool // whether the file has had a pasture statement
+}
+
+// bailout is panicked to abandon the rest of a parse; ParseProgram recovers it.
+type bailout struct{}
+
+// ParseError is a syntax error together with the position of the token that caused it.
+// Keeping the position separate from the message lets tools (like --diagnostics=json)
+// report errors without having to pick apart a formatted string.
+type ParseError struct {
+	Line    int
+	Column  int
+	End     token.Position // just past the offending token
+	Code    string         // Stable error code from internal/diagnostics, e.g. "BE0101"
+	Message string
+}
+
+// String formats the error the way it has always been shown: "[line 3, col 7] message"
+func (e ParseError) String() string {
+	return fmt.Sprintf("[line %d, col %d] %s", e.Line, e.Column, e.Message)
 }
 
 type (
@@ -70,18 +108,22 @@ type (
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:      l,
-		errors: []string{},
+		errors: []ParseError{},
 	}
 
 	// Register prefix parse functions
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.BIGINT, p.parseBigIntegerLiteral)
 	p.registerPrefix(token.TRUE, p.parseBooleanLiteral)
 	p.registerPrefix(token.FALSE, p.parseBooleanLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.NOT, p.parsePrefixExpression)
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.IF, p.parseIfExpression)
 
 	// Register infix parse functions
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -96,8 +138,12 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.GT, p.parseInfixExpression)
 	p.registerInfix(token.LTE, p.parseInfixExpression)
 	p.registerInfix(token.GTE, p.parseInfixExpression)
+	p.registerInfix(token.IN, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
 	p.registerInfix(token.LPAREN, p.parseFunctionCall)
 	p.registerInfix(token.DOT, p.parseMemberAccessExpression)
+	p.registerInfix(token.OPT_DOT, p.parseMemberAccessExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 
 	// Read two tokens to initialize curToken and peekToken
 	p.nextToken()
@@ -106,11 +152,21 @@ func New(l *lexer.Lexer) *Parser {
 	return p
 }
 
-// ParseProgram parses the entire program
-func (p *Parser) ParseProgram() *ast.Program {
-	program := &ast.Program{}
+// ParseProgram parses the entire program. It never panics: if parsing has to
+// stop early, the program holds the statements parsed so far and the reason
+// is reported as a parse error.
+func (p *Parser) ParseProgram() (program *ast.Program) {
+	program = &ast.Program{}
 	program.Statements = []ast.Statement{}
 
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				p.addError(p.curToken, diagnostics.CodeInternalParser, "internal parser error: %v", r)
+			}
+		}
+	}()
+
 	for p.curToken.Type != token.EOF {
 		stmt := p.parseStatement()
 		if stmt != nil {
@@ -119,37 +175,185 @@ func (p *Parser) ParseProgram() *ast.Program {
 		p.nextToken()
 	}
 
+	for _, err := range p.l.DirectiveErrors() {
+		p.addError(err.Token, diagnostics.CodeBadDirective, "%s", err.Message)
+	}
+
+	// Comments are attached by position, which needs a complete tree
+	if len(p.errors) == 0 {
+		comments := make([]*ast.Comment, len(p.l.Comments()))
+		for i, tok := range p.l.Comments() {
+			comments[i] = &ast.Comment{Token: tok}
+		}
+		ast.AttachComments(program, comments)
+	}
+
 	return program
 }
 
-// Errors returns the list of parsing errors
+// Errors returns the list of parsing errors as formatted strings
 func (p *Parser) Errors() []string {
+	msgs := make([]string, len(p.errors))
+	for i, err := range p.errors {
+		msgs[i] = err.String()
+	}
+	return msgs
+}
+
+// ParseErrors returns the list of parsing errors with their positions
+func (p *Parser) ParseErrors() []ParseError {
 	return p.errors
 }
 
+// addError records a syntax error with its error code at the given token's position
+func (p *Parser) addError(tok token.Token, code string, format string, a ...interface{}) {
+	p.errors = append(p.errors, ParseError{
+		Line:    tok.Line,
+		Column:  tok.Column,
+		End:     tok.End,
+		Code:    code,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// parseStatement parses one statement, returning nil if it had syntax errors.
+//
+// The statement parsers return concrete pointer types, so each result is
+// checked before being converted to ast.Statement: a nil *ast.IfStatement
+// stored in the interface would not compare equal to nil, and callers would
+// append it to the tree.
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.PREP:
-		return p.parseVariableDeclaration()
+		if stmt := p.parseVariableDeclaration(); stmt != nil {
+			return stmt
+		}
 	case token.SERVE:
-		return p.parseReturnStatement()
+		if stmt := p.parseReturnStatement(); stmt != nil {
+			return stmt
+		}
+	case token.YIELD:
+		if stmt := p.parseYieldStatement(); stmt != nil {
+			return stmt
+		}
+	case token.ASSERT:
+		if stmt := p.parseAssertStatement(); stmt != nil {
+			return stmt
+		}
+	case token.STAMPEDE:
+		if stmt := p.parseStampedeStatement(); stmt != nil {
+			return stmt
+		}
+	case token.DESSERT:
+		if stmt := p.parseDessertStatement(); stmt != nil {
+			return stmt
+		}
+	case token.USING:
+		if stmt := p.parseUsingStatement(); stmt != nil {
+			return stmt
+		}
+	case token.SELECT:
+		if stmt := p.parseSelectStatement(); stmt != nil {
+			return stmt
+		}
 	case token.IF:
-		return p.parseIfStatement()
+		if stmt := p.parseIfStatement(); stmt != nil {
+			return stmt
+		}
 	case token.PRAISE:
-		return p.parseFunctionDeclaration()
+		if stmt := p.parseFunctionDeclaration(); stmt != nil {
+			return stmt
+		}
+	case token.AT:
+		if stmt := p.parseDecoratedFunction(); stmt != nil {
+			return stmt
+		}
 	case token.FEAST_WHILE:
-		return p.parseWhileLoop()
+		// "feast item in items" loops over values; anything else is a while loop
+		if p.curToken.Literal == "feast" && p.peekTokenIs(token.IDENT) {
+			if stmt := p.parseForEachLoop(); stmt != nil {
+				return stmt
+			}
+			return nil
+		}
+		if stmt := p.parseWhileLoop(); stmt != nil {
+			return stmt
+		}
 	case token.WRANGLE:
-		return p.parseWrangleStatement()
+		if stmt := p.parseWrangleStatement(); stmt != nil {
+			return stmt
+		}
+	case token.PASTURE:
+		if stmt := p.parsePastureStatement(); stmt != nil {
+			return stmt
+		}
+	case token.EXPOSE:
+		if stmt := p.parseExposeStatement(); stmt != nil {
+			return stmt
+		}
 	case token.IDENT:
+		if keyword, ok := p.misspelledKeyword(); ok {
+			p.addError(p.curToken, diagnostics.CodeKeywordMisuse, "unknown statement '%s' - did you mean '%s'?", p.curToken.Literal, keyword)
+			// Carry on as if the keyword had been spelled right, so the rest
+			// of the statement doesn't cause more errors
+			p.curToken.Type, p.curToken.Literal = token.LookupIdent(keyword), keyword
+			return p.parseStatement()
+		}
 		// Check if this is an assignment (x = value) or expression statement
 		if p.peekTokenIs(token.ASSIGN) {
-			return p.parseAssignmentStatement()
+			if stmt := p.parseAssignmentStatement(); stmt != nil {
+				return stmt
+			}
+			return nil
 		}
 		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
+	return nil
+}
+
+// statementKeywords are the keywords that start a statement, which a
+// misspelling at the start of one is checked against.
+var statementKeywords = []string{
+	"prep", "serve", "yield", "assert", "stampede", "dessert", "using",
+	"select", "if", "praise", "feast", "wrangle", "pasture", "expose",
+}
+
+// otherLanguageKeywords maps words other languages start statements with to
+// the Beeflang keyword doing the same job.
+var otherLanguageKeywords = map[string]string{
+	"return": "serve", "let": "prep", "var": "prep", "const": "prep",
+	"def": "praise", "fn": "praise", "func": "praise", "function": "praise",
+	"for": "feast", "import": "wrangle", "defer": "dessert",
+	"go": "stampede", "with": "using",
+}
+
+// misspelledKeyword reports the keyword the identifier starting a statement
+// was probably meant to be. Only identifiers followed on the same line by a
+// name or literal count, as in "serv x" or "iff x > 1:" - that can't be an
+// expression, so a keyword was clearly intended.
+func (p *Parser) misspelledKeyword() (string, bool) {
+	switch p.peekToken.Type {
+	case token.IDENT, token.INT, token.BIGINT, token.STRING, token.TRUE, token.FALSE, token.FEAST_WHILE:
+	default:
+		return "", false
+	}
+	if p.peekToken.Line != p.curToken.Line {
+		return "", false
+	}
+
+	name := p.curToken.Literal
+	if keyword, ok := otherLanguageKeywords[name]; ok {
+		return keyword, true
+	}
+	best, bestDistance := "", diagnostics.MaxTypoEdits(name)+1
+	for _, keyword := range statementKeywords {
+		if d := diagnostics.EditDistance(name, keyword); d < bestDistance {
+			best, bestDistance = keyword, d
+		}
+	}
+	return best, best != ""
 }
 
 func (p *Parser) parseVariableDeclaration() *ast.VariableDeclaration {
@@ -161,6 +365,15 @@ func (p *Parser) parseVariableDeclaration() *ast.VariableDeclaration {
 
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	// Optional type annotation: prep x: int = 42
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		stmt.Type = p.parseTypeAnnotation()
+		if stmt.Type == nil {
+			return nil
+		}
+	}
+
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -182,6 +395,154 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+// parseYieldStatement parses: yield value
+func (p *Parser) parseYieldStatement() *ast.YieldStatement {
+	stmt := &ast.YieldStatement{Token: p.curToken}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+	if stmt.Value == nil {
+		return nil
+	}
+
+	return stmt
+}
+
+// parseAssertStatement parses: assert condition [, message]
+func (p *Parser) parseAssertStatement() *ast.AssertStatement {
+	stmt := &ast.AssertStatement{Token: p.curToken}
+
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+	if stmt.Condition == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		stmt.Message = p.parseExpression(LOWEST)
+		if stmt.Message == nil {
+			return nil
+		}
+	}
+
+	return stmt
+}
+
+// parseStampedeStatement parses: stampede f(args)
+func (p *Parser) parseStampedeStatement() *ast.StampedeStatement {
+	stmt := &ast.StampedeStatement{Token: p.curToken}
+	if stmt.Call = p.parseCallAfterKeyword(); stmt.Call == nil {
+		return nil
+	}
+	return stmt
+}
+
+// parseDessertStatement parses: dessert f(args)
+func (p *Parser) parseDessertStatement() *ast.DessertStatement {
+	stmt := &ast.DessertStatement{Token: p.curToken}
+	if stmt.Call = p.parseCallAfterKeyword(); stmt.Call == nil {
+		return nil
+	}
+	return stmt
+}
+
+// parseCallAfterKeyword parses the function call that must follow a keyword
+// like 'stampede', reporting an error (and returning nil) for anything else.
+func (p *Parser) parseCallAfterKeyword() *ast.FunctionCall {
+	keyword := p.curToken.Literal
+
+	p.nextToken()
+	start := p.curToken
+	expr := p.parseExpression(LOWEST)
+	if expr == nil {
+		return nil
+	}
+	call, ok := expr.(*ast.FunctionCall)
+	if !ok {
+		p.addError(start, diagnostics.CodeUnexpectedToken, "expected a function call after '%s'", keyword)
+		return nil
+	}
+	return call
+}
+
+func (p *Parser) parseSelectStatement() *ast.SelectStatement {
+	stmt := &ast.SelectStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+	p.nextToken()
+
+	// Each case's block ends at the next 'when', the 'else' or the closing 'beef'
+	for {
+		switch p.curToken.Type {
+		case token.WHEN:
+			c := p.parseSelectCase()
+			if c == nil {
+				return nil
+			}
+			stmt.Cases = append(stmt.Cases, c)
+		case token.ELSE:
+			if stmt.Default != nil {
+				p.addError(p.curToken, diagnostics.CodeUnexpectedToken, "select can only have one 'else'")
+				return nil
+			}
+			if !p.expectPeek(token.COLON) {
+				return nil
+			}
+			stmt.Default = p.parseBlockStatement()
+		case token.BEEF:
+			stmt.Closing = p.curToken
+			return stmt
+		default:
+			p.addError(p.curToken, diagnostics.CodeUnexpectedToken,
+				"expected 'when', 'else' or 'beef' in select, got %s instead", p.curToken.Type)
+			return nil
+		}
+	}
+}
+
+// parseSelectCase parses: when [name =] ch.recv(): or when ch.send(value):
+func (p *Parser) parseSelectCase() *ast.SelectCase {
+	c := &ast.SelectCase{Token: p.curToken}
+	p.nextToken()
+
+	if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.ASSIGN) {
+		c.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		p.nextToken()
+		p.nextToken()
+	}
+
+	start := p.curToken
+	expr := p.parseExpression(LOWEST)
+	if expr == nil {
+		return nil
+	}
+	var access *ast.MemberAccessExpression
+	call, ok := expr.(*ast.FunctionCall)
+	if ok {
+		access, _ = call.Function.(*ast.MemberAccessExpression)
+	}
+	switch {
+	case access != nil && access.Member.Value == "recv" && len(call.Arguments) == 0:
+	case access != nil && access.Member.Value == "send" && len(call.Arguments) == 1 && c.Name == nil:
+	default:
+		p.addError(start, diagnostics.CodeUnexpectedToken,
+			"select cases must be 'when ch.recv():', 'when name = ch.recv():' or 'when ch.send(value):'")
+		return nil
+	}
+	c.Call = call
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+	c.Body = p.parseBlockStatement()
+
+	return c
+}
+
 func (p *Parser) parseIfStatement() *ast.IfStatement {
 	stmt := &ast.IfStatement{Token: p.curToken}
 
@@ -207,6 +568,17 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 	return stmt
 }
 
+// parseIfExpression parses an if where a value is expected. The syntax is the
+// same as the statement's; only a statement's leading 'if' is left to
+// parseStatement.
+func (p *Parser) parseIfExpression() ast.Expression {
+	stmt := p.parseIfStatement()
+	if stmt == nil {
+		return nil
+	}
+	return &ast.IfExpression{If: stmt}
+}
+
 func (p *Parser) parseFunctionDeclaration() *ast.FunctionDeclaration {
 	stmt := &ast.FunctionDeclaration{Token: p.curToken}
 
@@ -220,7 +592,16 @@ func (p *Parser) parseFunctionDeclaration() *ast.FunctionDeclaration {
 		return nil
 	}
 
-	stmt.Parameters = p.parseFunctionParameters()
+	stmt.Parameters, stmt.ParameterTypes = p.parseFunctionParameters()
+
+	// Optional return type annotation: praise f() -> int:
+	if p.peekTokenIs(token.ARROW) {
+		p.nextToken()
+		stmt.ReturnType = p.parseTypeAnnotation()
+		if stmt.ReturnType == nil {
+			return nil
+		}
+	}
 
 	if !p.expectPeek(token.COLON) {
 		return nil
@@ -231,47 +612,111 @@ func (p *Parser) parseFunctionDeclaration() *ast.FunctionDeclaration {
 	return stmt
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+// parseDecoratedFunction parses one or more @ lines and the function
+// declaration they decorate:
+//
+//	@cache.memoize
+//	praise cost(x, y):
+func (p *Parser) parseDecoratedFunction() *ast.FunctionDeclaration {
+	var decorators []*ast.Decorator
+	for p.curTokenIs(token.AT) {
+		d := &ast.Decorator{Token: p.curToken}
+		p.nextToken()
+		d.Expression = p.parseExpression(LOWEST)
+		if d.Expression == nil {
+			return nil
+		}
+		decorators = append(decorators, d)
+		p.nextToken()
+	}
+
+	if !p.curTokenIs(token.PRAISE) {
+		p.addError(p.curToken, diagnostics.CodeUnexpectedToken,
+			"a decorator must be followed by a function declaration, got %s instead", p.curToken.Type)
+		return nil
+	}
+
+	stmt := p.parseFunctionDeclaration()
+	if stmt == nil {
+		return nil
+	}
+	stmt.Decorators = decorators
+	return stmt
+}
+
+// parseFunctionParameters parses (a, b: int, ...) and returns the names plus a
+// parallel slice of their type annotations (nil where a parameter has none).
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, []*ast.TypeAnnotation) {
 	identifiers := []*ast.Identifier{}
+	types := []*ast.TypeAnnotation{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return identifiers, types
 	}
 
-	p.nextToken()
+	if !p.expectPeek(token.IDENT) {
+		return nil, nil
+	}
 
 	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	identifiers = append(identifiers, ident)
+	types = append(types, p.parseOptionalParameterType())
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
-		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil, nil
+		}
 		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 		identifiers = append(identifiers, ident)
+		types = append(types, p.parseOptionalParameterType())
 	}
 
 	if !p.expectPeek(token.RPAREN) {
+		return nil, nil
+	}
+
+	return identifiers, types
+}
+
+// parseOptionalParameterType parses the ": type" after a parameter name, if present.
+func (p *Parser) parseOptionalParameterType() *ast.TypeAnnotation {
+	if !p.peekTokenIs(token.COLON) {
 		return nil
 	}
+	p.nextToken()
+	return p.parseTypeAnnotation()
+}
 
-	return identifiers
+// parseTypeAnnotation parses the type name following a ':' or '->' (the current token).
+// Type names are plain identifiers; the type checker decides which ones are valid.
+func (p *Parser) parseTypeAnnotation() *ast.TypeAnnotation {
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	return &ast.TypeAnnotation{Token: p.curToken, Name: p.curToken.Literal}
 }
 
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	defer p.restoreDepth(p.depth)
+	p.nest()
+
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
 
 	p.nextToken()
 
-	// Stop at beef (end of block), else (if in consequence of if statement), or EOF
-	for !p.curTokenIs(token.BEEF) && !p.curTokenIs(token.ELSE) && !p.curTokenIs(token.EOF) {
+	// Stop at beef (end of block), else (if in consequence of if statement),
+	// when (the next case of a select), or EOF
+	for !p.curTokenIs(token.BEEF) && !p.curTokenIs(token.ELSE) && !p.curTokenIs(token.WHEN) && !p.curTokenIs(token.EOF) {
 		stmt := p.parseStatement()
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
 		p.nextToken()
 	}
+	block.Closing = p.curToken
 
 	return block
 }
@@ -283,6 +728,9 @@ func (p *Parser) parseExpressionStatement() ast.Statement {
 }
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
+	defer p.restoreDepth(p.depth)
+	p.nest()
+
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
 		p.noPrefixParseFnError(p.curToken.Type)
@@ -295,9 +743,16 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 		if infix == nil {
 			return leftExp
 		}
+		// A '[' starting a new line begins an array literal statement,
+		// not an index into the line before
+		if p.peekTokenIs(token.LBRACKET) && p.peekToken.Line != p.curToken.Line {
+			return leftExp
+		}
 
 		p.nextToken()
 
+		// The new node sits above leftExp, so everything inside it nests one deeper
+		p.nest()
 		leftExp = infix(leftExp)
 	}
 
@@ -313,8 +768,20 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(p.curToken, diagnostics.CodeInvalidInteger, "could not parse %q as integer", p.curToken.Literal)
+		return nil
+	}
+
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseBigIntegerLiteral() ast.Expression {
+	lit := &ast.BigIntegerLiteral{Token: p.curToken}
+
+	value, ok := new(big.Int).SetString(strings.TrimSuffix(p.curToken.Literal, "n"), 10)
+	if !ok {
+		p.addError(p.curToken, diagnostics.CodeInvalidInteger, "could not parse %q as integer", p.curToken.Literal)
 		return nil
 	}
 
@@ -363,38 +830,124 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+// parsePipeExpression parses: value |> f(args). Pipes bind more loosely than
+// any other operator, and chain from left to right.
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	expression := &ast.PipeExpression{Token: p.curToken, Left: left}
+
+	p.nextToken()
+	expression.Right = p.parseExpression(PIPE)
+	if expression.Right == nil {
+		return nil
+	}
+
+	return expression
+}
+
 func (p *Parser) parseFunctionCall(function ast.Expression) ast.Expression {
 	exp := &ast.FunctionCall{Token: p.curToken, Function: function}
 	exp.Arguments = p.parseCallArguments()
+	exp.Rparen = p.curToken
 	return exp
 }
 
 func (p *Parser) parseCallArguments() []ast.Expression {
-	args := []ast.Expression{}
+	return p.parseExpressionList(token.RPAREN)
+}
 
-	if p.peekTokenIs(token.RPAREN) {
+// parseExpressionList parses comma-separated expressions up to and including
+// the end token, as in call arguments and array literals.
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
+
+	if p.peekTokenIs(end) {
 		p.nextToken()
-		return args
+		return list
 	}
 
 	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
+	list = append(list, p.parseExpression(LOWEST))
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		list = append(list, p.parseExpression(LOWEST))
 	}
 
-	if !p.expectPeek(token.RPAREN) {
+	if !p.expectPeek(end) {
 		return nil
 	}
 
-	return args
+	return list
+}
+
+// parseArrayLiteral parses: [a, b, c]
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.curToken}
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if array.Elements == nil {
+		return nil
+	}
+	array.Rbracket = p.curToken
+	return array
+}
+
+// parseHashLiteral parses: {key: value, key: value}
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken, Pairs: []ast.HashPair{}}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+	hash.Rbrace = p.curToken
+
+	return hash
+}
+
+// parseIndexExpression parses: left[index]
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	expr := &ast.IndexExpression{Token: p.curToken, Left: left}
+
+	p.nextToken()
+	expr.Index = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	expr.Rbracket = p.curToken
+
+	return expr
 }
 
 // Helper methods
 
+// nest enters one more level of nesting, abandoning the parse with an error
+// once maxNesting is exceeded. Callers undo it with restoreDepth.
+func (p *Parser) nest() {
+	p.depth++
+	if p.depth > maxNesting {
+		p.addError(p.curToken, diagnostics.CodeNestingTooDeep,
+			"expressions and blocks nested too deeply (more than %d levels)", maxNesting)
+		panic(bailout{})
+	}
+}
+
+// restoreDepth resets the nesting level when a nested parse returns
+func (p *Parser) restoreDepth(depth int) {
+	p.depth = depth
+}
+
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
@@ -413,20 +966,27 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 		p.nextToken()
 		return true
 	}
+	// A keyword where a name belongs gets its own error, and is then read as
+	// the name so the rest of the statement parses normally
+	if t == token.IDENT && token.IsKeyword(p.peekToken.Type) {
+		p.addError(p.peekToken, diagnostics.CodeKeywordMisuse, "'%s' is a keyword and can't be used as a name", p.peekToken.Literal)
+		p.nextToken()
+		return true
+	}
 	p.peekError(t)
 	return false
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("[line %d, col %d] expected next token to be %s, got %s instead",
-		p.peekToken.Line, p.peekToken.Column, t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(p.peekToken, diagnostics.CodeUnexpectedToken, "expected next token to be %s, got %s instead", t, p.peekToken.Type)
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("[line %d, col %d] no prefix parse function for %s found",
-		p.curToken.Line, p.curToken.Column, t)
-	p.errors = append(p.errors, msg)
+	if slices.Contains(statementKeywords, p.curToken.Literal) {
+		p.addError(p.curToken, diagnostics.CodeKeywordMisuse, "'%s' starts a statement and can't be used as a value", p.curToken.Literal)
+		return
+	}
+	p.addError(p.curToken, diagnostics.CodeNoPrefixParseFn, "no prefix parse function for %s found", t)
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -486,28 +1046,176 @@ func (p *Parser) parseWhileLoop() *ast.WhileLoop {
 	return stmt
 }
 
+// parseForEachLoop parses: feast item in items: body beef
+func (p *Parser) parseForEachLoop() *ast.ForEachLoop {
+	stmt := &ast.ForEachLoop{Token: p.curToken}
+
+	p.nextToken()
+	stmt.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+	if stmt.Iterable == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	return stmt
+}
+
+// parseUsingStatement parses: using name = value: body beef
+func (p *Parser) parseUsingStatement() *ast.UsingStatement {
+	stmt := &ast.UsingStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+	if stmt.Value == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	return stmt
+}
+
 func (p *Parser) parseWrangleStatement() *ast.WrangleStatement {
 	stmt := &ast.WrangleStatement{Token: p.curToken}
 
+	// A data file: wrangle "enemies.json" as enemies. Its name can't be a
+	// variable name, so it needs an alias
+	if p.peekTokenIs(token.STRING) {
+		p.nextToken()
+		stmt.DataFile = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+		if !p.peekTokenIs(token.AS) {
+			p.addError(p.peekToken, diagnostics.CodeUnexpectedToken,
+				"wrangling a data file needs a name: wrangle %q as <name>", stmt.DataFile.Value)
+			return nil
+		}
+	} else {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.ModuleName = &ast.Identifier{
+			Token: p.curToken,
+			Value: p.curToken.Literal,
+		}
+	}
+
+	// Optional alias: wrangle io as out
+	if p.peekTokenIs(token.AS) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	// Optional member list: wrangle io expose preach, input. An expose
+	// starting the next line is a statement of its own.
+	if p.peekTokenIs(token.EXPOSE) && p.peekToken.Line == p.curToken.Line {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Exposed = append(stmt.Exposed, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			stmt.Exposed = append(stmt.Exposed, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		}
+	}
+
+	return stmt
+}
+
+// parsePastureStatement parses pasture mygame.ai, which only makes sense
+// once, at the top level of a file.
+func (p *Parser) parsePastureStatement() *ast.PastureStatement {
+	stmt := &ast.PastureStatement{Token: p.curToken}
+	if p.depth > 0 {
+		p.addError(p.curToken, diagnostics.CodeUnexpectedToken, "pasture names the namespace of a whole file, so it goes at the top level")
+		return nil
+	}
+	if p.pasture {
+		p.addError(p.curToken, diagnostics.CodeUnexpectedToken, "a file can only be in one pasture")
+		return nil
+	}
+	p.pasture = true
+
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
+	stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	for p.peekTokenIs(token.DOT) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
+	return stmt
+}
 
-	stmt.ModuleName = &ast.Identifier{
-		Token: p.curToken,
-		Value: p.curToken.Literal,
+// parseExposeStatement parses expose math, array, which re-exports modules
+// from the top level of a file.
+func (p *Parser) parseExposeStatement() *ast.ExposeStatement {
+	stmt := &ast.ExposeStatement{Token: p.curToken}
+	if p.depth > 0 {
+		p.addError(p.curToken, diagnostics.CodeUnexpectedToken, "expose adds members to the file's module, so it goes at the top level")
+		return nil
 	}
 
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Modules = append(stmt.Modules, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Modules = append(stmt.Modules, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
 	return stmt
 }
 
 func (p *Parser) parseMemberAccessExpression(left ast.Expression) ast.Expression {
 	expr := &ast.MemberAccessExpression{
-		Token:  p.curToken, // The DOT token
-		Object: left,
+		Token:    p.curToken, // The DOT or OPT_DOT token
+		Object:   left,
+		Optional: p.curTokenIs(token.OPT_DOT),
 	}
 
-	if !p.expectPeek(token.IDENT) {
+	// Keywords are fine as member names (http.serve): after a '.' nothing
+	// else could be meant
+	if token.IsKeyword(p.peekToken.Type) {
+		p.nextToken()
+	} else if !p.expectPeek(token.IDENT) {
 		return nil
 	}
 
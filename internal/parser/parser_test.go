@@ -1,10 +1,15 @@
 package parser
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
 	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/token"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,6 +33,24 @@ func TestParseIntegerLiteral(t *testing.T) {
 	assert.Equal(t, int64(42), intLiteral.Value)
 }
 
+func TestParseBigIntegerLiteral(t *testing.T) {
+	input := "123456789012345678901234567890n"
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	assert.True(t, ok, "statement should be *ast.ExpressionStatement")
+
+	lit, ok := stmt.Expression.(*ast.BigIntegerLiteral)
+	if !ok {
+		t.Fatalf("expression should be *ast.BigIntegerLiteral, got %T", stmt.Expression)
+	}
+	assert.Equal(t, "123456789012345678901234567890", lit.Value.String())
+}
+
 func TestParseIdentifier(t *testing.T) {
 	input := "foobar"
 	l := lexer.New(input)
@@ -212,6 +235,156 @@ func TestParseReturnStatement(t *testing.T) {
 	testIntegerLiteral(t, returnStmt.ReturnValue, 5)
 }
 
+func TestParseAssertStatement(t *testing.T) {
+	tests := []struct {
+		input      string
+		condition  string
+		hasMessage bool
+	}{
+		{"assert total == 10", "total == 10", false},
+		{`assert ready(), "the grill is cold"`, "ready()", true},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.AssertStatement)
+		if assert.True(t, ok, "statement should be *ast.AssertStatement, got %T", program.Statements[0]) {
+			assert.Equal(t, tt.condition, ast.Format(stmt.Condition))
+			assert.Equal(t, tt.hasMessage, stmt.Message != nil)
+			assert.Equal(t, len(tt.input)+1, stmt.End().Column)
+		}
+	}
+}
+
+func TestParseStampedeStatement(t *testing.T) {
+	input := "stampede worker.run(ch, 3)"
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assert.Len(t, program.Statements, 1)
+
+	stmt, ok := program.Statements[0].(*ast.StampedeStatement)
+	assert.True(t, ok, "statement should be *ast.StampedeStatement, got %T", program.Statements[0])
+	assert.Len(t, stmt.Call.Arguments, 2)
+	_, ok = stmt.Call.Function.(*ast.MemberAccessExpression)
+	assert.True(t, ok, "callee should be a member access")
+	assert.Equal(t, len(input)+1, stmt.End().Column)
+}
+
+func TestStampedeNeedsACall(t *testing.T) {
+	p := New(lexer.New("stampede worker\nprep x = 1"))
+	program := p.ParseProgram()
+
+	errs := p.ParseErrors()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "expected a function call after 'stampede'", errs[0].Message)
+	assert.Equal(t, 10, errs[0].Column)
+	// Parsing carries on after the bad statement
+	assert.Len(t, program.Statements, 1)
+}
+
+func TestParseDessertStatement(t *testing.T) {
+	p := New(lexer.New("praise f():\n   dessert lock.unlock()\nbeef\ndessert 5"))
+	program := p.ParseProgram()
+
+	fn := program.Statements[0].(*ast.FunctionDeclaration)
+	stmt, ok := fn.Body.Statements[0].(*ast.DessertStatement)
+	assert.True(t, ok, "statement should be *ast.DessertStatement, got %T", fn.Body.Statements[0])
+	assert.Empty(t, stmt.Call.Arguments)
+
+	errs := p.ParseErrors()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "expected a function call after 'dessert'", errs[0].Message)
+}
+
+func TestParseUsingStatement(t *testing.T) {
+	input := "using ch = chan.new(1):\n   ch.send(1)\nbeef"
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assert.Len(t, program.Statements, 1)
+	stmt, ok := program.Statements[0].(*ast.UsingStatement)
+	assert.True(t, ok, "statement should be *ast.UsingStatement, got %T", program.Statements[0])
+	assert.Equal(t, "ch", stmt.Name.Value)
+	_, ok = stmt.Value.(*ast.FunctionCall)
+	assert.True(t, ok, "value should be a call")
+	assert.Len(t, stmt.Body.Statements, 1)
+	assert.Equal(t, 3, stmt.End().Line)
+
+	p = New(lexer.New("using chan.new():\nbeef"))
+	p.ParseProgram()
+	assert.Equal(t, "expected next token to be =, got . instead", p.ParseErrors()[0].Message)
+}
+
+func TestParseSelectStatement(t *testing.T) {
+	input := `select:
+when msg = inbox.recv():
+   io.preach(msg)
+when outbox.send(1 + 2):
+when time.after(10).recv():
+   prep late = true
+else:
+   prep idle = true
+beef`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assert.Len(t, program.Statements, 1)
+	stmt, ok := program.Statements[0].(*ast.SelectStatement)
+	assert.True(t, ok, "statement should be *ast.SelectStatement, got %T", program.Statements[0])
+	assert.Len(t, stmt.Cases, 3)
+
+	assert.Equal(t, "msg", stmt.Cases[0].Name.Value)
+	channel, send := stmt.Cases[0].Channel()
+	assert.Equal(t, "inbox", channel.TokenLiteral())
+	assert.False(t, send)
+	assert.Len(t, stmt.Cases[0].Body.Statements, 1)
+
+	assert.Nil(t, stmt.Cases[1].Name)
+	_, send = stmt.Cases[1].Channel()
+	assert.True(t, send)
+	assert.Empty(t, stmt.Cases[1].Body.Statements)
+
+	_, ok = stmt.Cases[2].Call.Function.(*ast.MemberAccessExpression).Object.(*ast.FunctionCall)
+	assert.True(t, ok, "the channel can be any expression")
+
+	assert.Len(t, stmt.Default.Statements, 1)
+	assert.Equal(t, 9, stmt.End().Line)
+}
+
+func TestSelectErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+	}{
+		{"select:\nwhen ch.peek():\nbeef", "select cases must be 'when ch.recv():', 'when name = ch.recv():' or 'when ch.send(value):'"},
+		{"select:\nwhen x = ch.send(1):\nbeef", "select cases must be 'when ch.recv():', 'when name = ch.recv():' or 'when ch.send(value):'"},
+		{"select:\nwhen recv():\nbeef", "select cases must be 'when ch.recv():', 'when name = ch.recv():' or 'when ch.send(value):'"},
+		{"select:\n   prep x = 1\nbeef", "expected 'when', 'else' or 'beef' in select, got PREP instead"},
+		{"select:\nelse:\nelse:\nbeef", "select can only have one 'else'"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errs := p.ParseErrors()
+		if assert.NotEmpty(t, errs, tt.input) {
+			assert.Equal(t, tt.message, errs[0].Message, tt.input)
+		}
+	}
+}
+
 func TestParseIfStatement(t *testing.T) {
 	input := `if x > 5:
    prep y = 10
@@ -285,6 +458,30 @@ beef`
 	assert.Len(t, ifStmt.Alternative.Statements, 1, "alternative should have exactly 1 statement")
 }
 
+func TestParseIfExpression(t *testing.T) {
+	input := `prep label = if hp > 0: "alive" else: "dead" beef`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assert.Len(t, program.Statements, 1)
+
+	decl, ok := program.Statements[0].(*ast.VariableDeclaration)
+	if !ok {
+		t.Fatalf("statement should be *ast.VariableDeclaration, got %T", program.Statements[0])
+	}
+	ifExpr, ok := decl.Value.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("value should be *ast.IfExpression, got %T", decl.Value)
+	}
+	assert.Equal(t, "hp > 0", ast.Format(ifExpr.If.Condition))
+	assert.Len(t, ifExpr.If.Consequence.Statements, 1)
+	assert.NotNil(t, ifExpr.If.Alternative)
+	assert.Len(t, ifExpr.If.Alternative.Statements, 1)
+}
+
 func TestParseIfElseStatementOneLine(t *testing.T) {
 	input := "if 1 < 2: 10 else: 20 beef"
 	l := lexer.New(input)
@@ -341,6 +538,50 @@ beef`
 	assert.Len(t, whileLoop.Body.Statements, 1, "body should have 1 statement")
 }
 
+func TestParseForEachLoop(t *testing.T) {
+	input := `feast order in orders():
+   io.preach(order)
+beef`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assert.Len(t, program.Statements, 1)
+	loop, ok := program.Statements[0].(*ast.ForEachLoop)
+	assert.True(t, ok, "statement should be *ast.ForEachLoop, got %T", program.Statements[0])
+	assert.Equal(t, "order", loop.Variable.Value)
+	_, ok = loop.Iterable.(*ast.FunctionCall)
+	assert.True(t, ok, "iterable should be a call")
+	assert.Len(t, loop.Body.Statements, 1)
+	assert.Equal(t, 3, loop.End().Line)
+
+	// The iterable can use 'in' itself
+	p = New(lexer.New("feast found in x in xs:\nbeef"))
+	loop = p.ParseProgram().Statements[0].(*ast.ForEachLoop)
+	checkParserErrors(t, p)
+	assert.Equal(t, "x in xs", ast.Format(loop.Iterable))
+
+	// 'while' alone still starts a while loop
+	p = New(lexer.New("while ready:\nbeef"))
+	_, ok = p.ParseProgram().Statements[0].(*ast.WhileLoop)
+	assert.True(t, ok)
+
+	p = New(lexer.New("feast order orders:\nbeef"))
+	p.ParseProgram()
+	assert.Equal(t, "expected next token to be IN, got IDENT instead", p.ParseErrors()[0].Message)
+}
+
+func TestParseYieldStatement(t *testing.T) {
+	p := New(lexer.New("yield i * 2"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.YieldStatement)
+	if assert.True(t, ok, "statement should be *ast.YieldStatement, got %T", program.Statements[0]) {
+		assert.Equal(t, "i * 2", ast.Format(stmt.Value))
+	}
+}
+
 func TestParseFunctionDeclaration(t *testing.T) {
 	input := `praise add(x, y):
    serve x + y
@@ -363,6 +604,84 @@ beef`
 	assert.NotNil(t, fnDecl.Body)
 }
 
+func TestParseDecorators(t *testing.T) {
+	input := `# Costs are cached.
+@trace("cost")
+@cache.memoize
+praise cost(x, y):
+   serve x + y
+beef`
+	p := New(lexer.New(input))
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assert.Len(t, program.Statements, 1)
+	fnDecl, ok := program.Statements[0].(*ast.FunctionDeclaration)
+	if !ok {
+		t.Fatalf("statement should be *ast.FunctionDeclaration, got %T", program.Statements[0])
+	}
+	assert.Equal(t, "cost", fnDecl.Name.Value)
+	if assert.Len(t, fnDecl.Decorators, 2) {
+		assert.Equal(t, `trace("cost")`, ast.Format(fnDecl.Decorators[0].Expression))
+		assert.Equal(t, "cache.memoize", ast.Format(fnDecl.Decorators[1].Expression))
+	}
+	assert.Equal(t, 2, fnDecl.Start().Line, "a decorated function starts at its first decorator")
+	assert.Equal(t, "Costs are cached.", ast.CommentText(fnDecl.Doc))
+}
+
+func TestParseDecoratorWithoutFunction(t *testing.T) {
+	p := New(lexer.New("@cache.memoize\nprep x = 1"))
+	p.ParseProgram()
+
+	errs := p.ParseErrors()
+	if assert.NotEmpty(t, errs) {
+		assert.Equal(t, "a decorator must be followed by a function declaration, got PREP instead", errs[0].Message)
+		assert.Equal(t, diagnostics.CodeUnexpectedToken, errs[0].Code)
+	}
+}
+
+func TestParseTypeAnnotations(t *testing.T) {
+	input := `praise add(x: int, y) -> int:
+   prep total: int = x + y
+   serve total
+beef`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assert.Len(t, program.Statements, 1)
+
+	fnDecl, ok := program.Statements[0].(*ast.FunctionDeclaration)
+	assert.True(t, ok, "statement should be *ast.FunctionDeclaration")
+	assert.Len(t, fnDecl.Parameters, 2)
+	assert.Len(t, fnDecl.ParameterTypes, 2)
+	assert.Equal(t, "int", fnDecl.ParameterTypes[0].Name)
+	assert.Nil(t, fnDecl.ParameterTypes[1], "unannotated parameter has no type")
+	assert.Equal(t, "int", fnDecl.ReturnType.Name)
+
+	decl, ok := fnDecl.Body.Statements[0].(*ast.VariableDeclaration)
+	assert.True(t, ok, "statement should be *ast.VariableDeclaration")
+	assert.Equal(t, "total", decl.Name.Value)
+	assert.Equal(t, "int", decl.Type.Name)
+}
+
+func TestParseTypeAnnotationErrors(t *testing.T) {
+	inputs := []string{
+		"prep x: = 5",
+		"praise f(a:):\nbeef",
+		"praise f() -> :\nbeef",
+	}
+
+	for _, input := range inputs {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		assert.NotEmpty(t, p.Errors(), "Input: %s", input)
+	}
+}
+
 func TestParseFunctionCall(t *testing.T) {
 	input := "preach(42)"
 	l := lexer.New(input)
@@ -408,6 +727,77 @@ func TestParseOperatorPrecedence(t *testing.T) {
 	}
 }
 
+func TestParsePipeExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		left  string
+		right string
+	}{
+		{"x |> f", "x", "f"},
+		{"x + 1 |> f(2)", "x + 1", "f(2)"},
+		// Pipes chain from left to right
+		{"x |> f |> g(2)", "x |> f", "g(2)"},
+		{"orders |> list.sum", "orders", "list.sum"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		pipe, ok := stmt.Expression.(*ast.PipeExpression)
+		if assert.True(t, ok, "%s: expression should be *ast.PipeExpression, got %T", tt.input, stmt.Expression) {
+			assert.Equal(t, tt.left, ast.Format(pipe.Left), tt.input)
+			assert.Equal(t, tt.right, ast.Format(pipe.Right), tt.input)
+		}
+	}
+
+	p := New(lexer.New("x |> f(2)"))
+	pipe := p.ParseProgram().Statements[0].(*ast.ExpressionStatement).Expression.(*ast.PipeExpression)
+	assert.Equal(t, "f(x, 2)", ast.Format(pipe.Call()))
+}
+
+func TestParseErrorsCarryPositions(t *testing.T) {
+	input := `prep x = 1
+prep = 5`
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	errs := p.ParseErrors()
+	assert.NotEmpty(t, errs)
+	assert.Equal(t, 2, errs[0].Line)
+	assert.Equal(t, 6, errs[0].Column)
+	assert.Equal(t, "expected next token to be IDENT, got = instead", errs[0].Message)
+	assert.Equal(t, diagnostics.CodeUnexpectedToken, errs[0].Code)
+
+	// Errors() keeps the formatted form
+	assert.Equal(t, "[line 2, col 6] expected next token to be IDENT, got = instead", p.Errors()[0])
+}
+
+func TestParseErrorCodes(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedCode string
+	}{
+		{"prep = 5", diagnostics.CodeUnexpectedToken},
+		{"prep x = * 5", diagnostics.CodeNoPrefixParseFn},
+		{"prep x = 99999999999999999999", diagnostics.CodeInvalidInteger},
+		{"#when debug\nprep x = 5", diagnostics.CodeBadDirective},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errs := p.ParseErrors()
+		if assert.NotEmpty(t, errs, "Input: %s", tt.input) {
+			assert.Equal(t, tt.expectedCode, errs[0].Code, "Input: %s", tt.input)
+		}
+	}
+}
+
 // Helper functions
 
 func checkParserErrors(t *testing.T, p *Parser) {
@@ -448,6 +838,137 @@ func TestParseWrangleStatement(t *testing.T) {
 	assert.Equal(t, "io", stmt.ModuleName.Value)
 }
 
+func TestParseWrangleWithAlias(t *testing.T) {
+	input := "wrangle io as out"
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assert.Len(t, program.Statements, 1, "program should have 1 statement")
+
+	stmt, ok := program.Statements[0].(*ast.WrangleStatement)
+	assert.True(t, ok, "statement should be *ast.WrangleStatement, got %T", program.Statements[0])
+	assert.Equal(t, "io", stmt.ModuleName.Value)
+	assert.Equal(t, "out", stmt.Alias.Value)
+	assert.Empty(t, stmt.Exposed)
+}
+
+func TestParseWrangleWithExpose(t *testing.T) {
+	input := "wrangle io expose preach, input"
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assert.Len(t, program.Statements, 1, "program should have 1 statement")
+
+	stmt, ok := program.Statements[0].(*ast.WrangleStatement)
+	assert.True(t, ok, "statement should be *ast.WrangleStatement, got %T", program.Statements[0])
+	assert.Nil(t, stmt.Alias)
+	assert.Len(t, stmt.Exposed, 2)
+	assert.Equal(t, "preach", stmt.Exposed[0].Value)
+	assert.Equal(t, "input", stmt.Exposed[1].Value)
+}
+
+func TestParseWrangleExposeRequiresNames(t *testing.T) {
+	l := lexer.New("wrangle io expose")
+	p := New(l)
+	p.ParseProgram()
+
+	assert.NotEmpty(t, p.Errors(), "expose without member names should be a parse error")
+}
+
+func TestParseWrangleDataFile(t *testing.T) {
+	input := `wrangle "data/enemies.json" as enemies expose goblin`
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.WrangleStatement)
+	if !ok {
+		t.Fatalf("statement should be *ast.WrangleStatement, got %T", program.Statements[0])
+	}
+	assert.Nil(t, stmt.ModuleName)
+	assert.Equal(t, "data/enemies.json", stmt.DataFile.Value)
+	assert.Equal(t, "enemies", stmt.Alias.Value)
+	assert.Equal(t, "goblin", stmt.Exposed[0].Value)
+}
+
+func TestParseWrangleDataFileRequiresAlias(t *testing.T) {
+	p := New(lexer.New(`wrangle "enemies.json"`))
+	p.ParseProgram()
+
+	if assert.Len(t, p.Errors(), 1) {
+		assert.Contains(t, p.Errors()[0], `wrangling a data file needs a name: wrangle "enemies.json" as <name>`)
+	}
+}
+
+func TestParsePastureStatement(t *testing.T) {
+	p := New(lexer.New("pasture mygame.ai\nprep speed = 3"))
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.PastureStatement)
+	if !ok {
+		t.Fatalf("statement should be *ast.PastureStatement, got %T", program.Statements[0])
+	}
+	assert.Equal(t, "mygame.ai", stmt.Path())
+}
+
+func TestParsePastureErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"pasture mygame\npasture other", "a file can only be in one pasture"},
+		{"praise f():\n   pasture mygame\nbeef", "pasture names the namespace of a whole file, so it goes at the top level"},
+		{"pasture mygame.", "expected next token to be IDENT"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if assert.NotEmpty(t, p.Errors(), tt.input) {
+			assert.Contains(t, p.Errors()[0], tt.expected, tt.input)
+		}
+	}
+}
+
+func TestParseExposeStatement(t *testing.T) {
+	p := New(lexer.New("wrangle math\nexpose math, array"))
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if !assert.Len(t, program.Statements, 2, "an expose on the next line is not part of the wrangle") {
+		return
+	}
+	wrangle := program.Statements[0].(*ast.WrangleStatement)
+	assert.Empty(t, wrangle.Exposed)
+	stmt, ok := program.Statements[1].(*ast.ExposeStatement)
+	if !ok {
+		t.Fatalf("statement should be *ast.ExposeStatement, got %T", program.Statements[1])
+	}
+	assert.Equal(t, "math", stmt.Modules[0].Value)
+	assert.Equal(t, "array", stmt.Modules[1].Value)
+}
+
+func TestParseExposeOnlyAtTopLevel(t *testing.T) {
+	p := New(lexer.New("praise f():\n   expose math\nbeef"))
+	p.ParseProgram()
+
+	if assert.NotEmpty(t, p.Errors()) {
+		assert.Contains(t, p.Errors()[0], "expose adds members to the file's module, so it goes at the top level")
+	}
+}
+
 func TestParseMemberAccessExpression(t *testing.T) {
 	input := "io.preach"
 	l := lexer.New(input)
@@ -473,6 +994,23 @@ func TestParseMemberAccessExpression(t *testing.T) {
 	assert.Equal(t, "preach", memberAccess.Member.Value)
 }
 
+func TestParseOptionalMemberAccess(t *testing.T) {
+	p := New(lexer.New("config?.db.host"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	expr := program.Statements[0].(*ast.ExpressionStatement).Expression
+	outer, ok := expr.(*ast.MemberAccessExpression)
+	if !assert.True(t, ok, "expression should be *ast.MemberAccessExpression, got %T", expr) {
+		return
+	}
+	assert.False(t, outer.Optional)
+	inner := outer.Object.(*ast.MemberAccessExpression)
+	assert.True(t, inner.Optional)
+	assert.Equal(t, "db", inner.Member.Value)
+	assert.Equal(t, "config?.db.host", ast.Format(expr))
+}
+
 func TestParseModuleFunctionCall(t *testing.T) {
 	input := "io.preach(42)"
 	l := lexer.New(input)
@@ -504,3 +1042,221 @@ func TestParseModuleFunctionCall(t *testing.T) {
 	assert.Len(t, call.Arguments, 1, "should have 1 argument")
 	testIntegerLiteral(t, call.Arguments[0], 42)
 }
+
+func TestStatementsWithErrorsAreLeftOutOfTheTree(t *testing.T) {
+	p := New(lexer.New("prep = 1\nif x 5\nprep y = 2"))
+	program := p.ParseProgram()
+
+	assert.NotEmpty(t, p.Errors())
+	for _, stmt := range program.Statements {
+		// A nil *ast.VariableDeclaration inside the interface would pass a nil check
+		assert.NotNil(t, stmt)
+		assert.NotPanics(t, func() { stmt.Start() })
+	}
+}
+
+func TestParseFunctionParametersMustBeNames(t *testing.T) {
+	for _, input := range []string{"praise f(1):\nbeef", "praise f(a, 2):\nbeef", "praise f(a,"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		if assert.NotEmpty(t, p.ParseErrors(), "input %q", input) {
+			assert.Equal(t, diagnostics.CodeUnexpectedToken, p.ParseErrors()[0].Code)
+		}
+	}
+}
+
+func TestNestingLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"prefix operators", strings.Repeat("-", maxNesting*5) + "1"},
+		{"operator chain", "prep x = 1" + strings.Repeat(" + 1", maxNesting*5)},
+		{"call arguments", strings.Repeat("f(", maxNesting*5)},
+		{"blocks", strings.Repeat("if x:\n", maxNesting*5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			p.ParseProgram()
+
+			// Parsing stops at the first violation, so there is exactly one error
+			if assert.Len(t, p.ParseErrors(), 1) {
+				assert.Equal(t, diagnostics.CodeNestingTooDeep, p.ParseErrors()[0].Code)
+			}
+		})
+	}
+
+	// Just under the limit is fine
+	p := New(lexer.New("prep x = 1" + strings.Repeat(" + 1", maxNesting-10)))
+	p.ParseProgram()
+	checkParserErrors(t, p)
+}
+
+func TestInternalPanicBecomesParseError(t *testing.T) {
+	p := New(lexer.New("prep x = 1\nprep y = 2"))
+	p.registerPrefix(token.INT, func() ast.Expression { panic("boom") })
+
+	var program *ast.Program
+	assert.NotPanics(t, func() { program = p.ParseProgram() })
+
+	if assert.Len(t, p.ParseErrors(), 1) {
+		assert.Equal(t, diagnostics.CodeInternalParser, p.ParseErrors()[0].Code)
+		assert.Equal(t, "internal parser error: boom", p.ParseErrors()[0].Message)
+		assert.Equal(t, 1, p.ParseErrors()[0].Line)
+	}
+	assert.Empty(t, program.Statements)
+}
+
+// FuzzParseProgram parses arbitrary input: the parser must never panic, and
+// the tree it returns - even alongside errors - must be safe to walk.
+// Run with: go test ./internal/parser -fuzz=FuzzParseProgram
+func FuzzParseProgram(f *testing.F) {
+	seeds, _ := filepath.Glob("../../examples/*.beef")
+	errorSeeds, _ := filepath.Glob("../../examples/errors/*.beef")
+	for _, path := range append(seeds, errorSeeds...) {
+		if source, err := os.ReadFile(path); err == nil {
+			f.Add(string(source))
+		}
+	}
+	f.Add("praise f(a: int, b) -> int:\n   serve a\nbeef")
+	f.Add("if x:\n   prep y = (1\nelse:\n")
+	f.Add("wrangle io as out expose preach, input")
+	f.Add("feast while feast while x:")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		p := New(lexer.New(input))
+		program := p.ParseProgram()
+
+		// ParseProgram recovers from panics as BE0105, so they only show
+		// up as errors
+		for _, err := range p.ParseErrors() {
+			if err.Code == diagnostics.CodeInternalParser {
+				t.Fatalf("parser panicked on %q: %s", input, err.Message)
+			}
+		}
+
+		ast.Inspect(program, func(n ast.Node) bool {
+			if n != nil {
+				n.TokenLiteral()
+				n.Start()
+				n.End()
+			}
+			return true
+		})
+	})
+}
+
+func TestParseArrayAndHashLiterals(t *testing.T) {
+	p := New(lexer.New(`[1, "two", [x]]
+{"cut": "brisket", 2: true,}
+[]
+{}`))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	assert.Len(t, program.Statements, 4)
+
+	array := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ArrayLiteral)
+	assert.Len(t, array.Elements, 3)
+	testIntegerLiteral(t, array.Elements[0], 1)
+	assert.IsType(t, &ast.ArrayLiteral{}, array.Elements[2])
+
+	hash := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.HashLiteral)
+	if assert.Len(t, hash.Pairs, 2) {
+		assert.Equal(t, "cut", hash.Pairs[0].Key.(*ast.StringLiteral).Value)
+		testIntegerLiteral(t, hash.Pairs[1].Key, 2)
+	}
+
+	assert.Empty(t, program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.ArrayLiteral).Elements)
+	assert.Empty(t, program.Statements[3].(*ast.ExpressionStatement).Expression.(*ast.HashLiteral).Pairs)
+}
+
+func TestParseIndexExpression(t *testing.T) {
+	p := New(lexer.New("menu.cuts[i + 1][0]"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	outer := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IndexExpression)
+	testIntegerLiteral(t, outer.Index, 0)
+
+	inner, ok := outer.Left.(*ast.IndexExpression)
+	if assert.True(t, ok, "left should be *ast.IndexExpression, got %T", outer.Left) {
+		assert.IsType(t, &ast.MemberAccessExpression{}, inner.Left)
+		assert.IsType(t, &ast.InfixExpression{}, inner.Index)
+	}
+}
+
+func TestCollectionLiteralErrors(t *testing.T) {
+	for _, input := range []string{"[1, 2", `{"a" 1}`, `{"a": 1`, "x[1"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		assert.NotEmpty(t, p.Errors(), "input %q", input)
+	}
+}
+
+func TestArrayOnNewLineIsNotAnIndex(t *testing.T) {
+	p := New(lexer.New("prep n = cuts\n[1, 2]"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if assert.Len(t, program.Statements, 2) {
+		assert.IsType(t, &ast.Identifier{}, program.Statements[0].(*ast.VariableDeclaration).Value)
+	}
+}
+
+func TestKeywordsAsMemberNames(t *testing.T) {
+	p := New(lexer.New("http.serve(8080, handle)\nconfig.if"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	call := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionCall)
+	assert.Equal(t, "serve", call.Function.(*ast.MemberAccessExpression).Member.Value)
+	member := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.MemberAccessExpression)
+	assert.Equal(t, "if", member.Member.Value)
+}
+
+func TestKeywordMisuse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		column   int
+	}{
+		{"serv total", "unknown statement 'serv' - did you mean 'serve'?", 1},
+		{"prpe x = 5", "unknown statement 'prpe' - did you mean 'prep'?", 1},
+		{"iff x > 1:\n  x = 2\nbeef", "unknown statement 'iff' - did you mean 'if'?", 1},
+		{"praies cook():\nbeef", "unknown statement 'praies' - did you mean 'praise'?", 1},
+		{"fest while true:\nbeef", "unknown statement 'fest' - did you mean 'feast'?", 1},
+		{"return 5", "unknown statement 'return' - did you mean 'serve'?", 1},
+		{"prep in = 5", "'in' is a keyword and can't be used as a name", 6},
+		{"praise beef():\nbeef", "'beef' is a keyword and can't be used as a name", 8},
+		{"praise f(yield):\nbeef", "'yield' is a keyword and can't be used as a name", 10},
+		{"prep x = serve", "'serve' starts a statement and can't be used as a value", 10},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		// The misused keyword is the only error: parsing carries on as if
+		// it had been written right
+		errs := p.ParseErrors()
+		if assert.Len(t, errs, 1, tt.input) {
+			assert.Equal(t, tt.expected, errs[0].Message, tt.input)
+			assert.Equal(t, diagnostics.CodeKeywordMisuse, errs[0].Code, tt.input)
+			assert.Equal(t, tt.column, errs[0].Column, tt.input)
+		}
+	}
+}
+
+func TestNamesNearKeywordsAreFine(t *testing.T) {
+	// Only a name followed by another name or literal on the same line looks
+	// like a misspelled statement
+	for _, input := range []string{"prep serv = 1\nserv", "iff(1)", "prep sever = 2\nsever + 1", "serv\nx"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		assert.Empty(t, p.Errors(), input)
+	}
+}
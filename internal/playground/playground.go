@@ -0,0 +1,113 @@
+// Package playground runs Beeflang snippets for the browser playground. The
+// WebAssembly build of the interpreter (see the wasm directory) calls Run for
+// every snippet; nothing here needs files, a terminal or the process's
+// standard streams, none of which a browser has.
+package playground
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/elitwilson/beeflang/internal/analysis"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+)
+
+// SnippetName is the file name diagnostics give a snippet.
+const SnippetName = "snippet.beef"
+
+// Result is what running a snippet produced.
+type Result struct {
+	Output string // everything the snippet printed, with diagnostics in between
+	OK     bool   // whether it parsed and ran without an error
+}
+
+// output collects a snippet's output. Tasks started with 'stampede' write to
+// it concurrently.
+type output struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Write(p)
+}
+
+func (o *output) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.String()
+}
+
+// Run parses and runs a snippet. A snippet that declares ChurchOfBeef() runs
+// like a program; any other runs like a script, top-level statements only.
+// Standard input is empty, and wrangle finds only the built-in modules.
+func Run(source string) Result {
+	out := &output{}
+	lines := strings.Split(source, "\n")
+	renderer := diagnostics.Renderer{Source: func(file string, n int) (string, bool) {
+		if file != SnippetName || n < 1 || n > len(lines) {
+			return "", false
+		}
+		return lines[n-1], true
+	}}
+	report := func(d diagnostics.Diagnostic) {
+		out.Write([]byte(renderer.Render(d)))
+	}
+
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if errs := p.ParseErrors(); len(errs) > 0 {
+		for _, err := range errs {
+			report(diagnostics.Diagnostic{File: SnippetName, Line: err.Line, Column: err.Column,
+				EndLine: err.End.Line, EndColumn: err.End.Column,
+				Severity: diagnostics.Error, Code: err.Code, Message: err.Message})
+		}
+		return Result{Output: out.String()}
+	}
+	for _, w := range analysis.Analyze(program) {
+		report(diagnostics.Diagnostic{File: SnippetName, Line: w.Line, Column: w.Column,
+			EndLine: w.End.Line, EndColumn: w.End.Column,
+			Severity: diagnostics.Warning, Code: w.Code, Message: w.Message})
+	}
+
+	// The snippet's streams are its output, with nothing to read
+	rt := evaluator.NewRuntime()
+	rt.Stdin, rt.Stdout, rt.Stderr = strings.NewReader(""), out, out
+	rt.OnTaskError = func(err *object.Error) { report(runtimeDiagnostic(err)) }
+
+	env := rt.NewEnvironment()
+	result := evaluator.EvalFiles(env, []evaluator.SourceFile{{Name: SnippetName, Program: program}})
+	if _, isError := result.(*object.Error); !isError {
+		if _, ok := env.Get(rt.EntryPoint); ok {
+			result = evaluator.CallEntryPoint(env)
+		}
+	}
+
+	if err, isError := result.(*object.Error); isError {
+		// os.exit ends a snippet early; only a non-zero status is a failure
+		if code, ok := evaluator.ExitCode(err); ok {
+			return Result{Output: out.String(), OK: code == 0}
+		}
+		report(runtimeDiagnostic(err))
+		return Result{Output: out.String()}
+	}
+	return Result{Output: out.String(), OK: true}
+}
+
+// runtimeDiagnostic converts an evaluator error into a diagnostic.
+func runtimeDiagnostic(err *object.Error) diagnostics.Diagnostic {
+	file := err.File
+	if file == "" {
+		file = SnippetName
+	}
+	return diagnostics.Diagnostic{File: file, Line: err.Line, Column: err.Column,
+		EndLine: err.EndLine, EndColumn: err.EndColumn,
+		Severity: diagnostics.Error, Code: err.Code, Message: err.Message}
+}
@@ -0,0 +1,49 @@
+package playground
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunScript(t *testing.T) {
+	result := Run("wrangle io\nio.preach(6 * 7)")
+	assert.True(t, result.OK)
+	assert.Equal(t, "42\n", result.Output)
+}
+
+func TestRunProgramWithEntryPoint(t *testing.T) {
+	result := Run(`wrangle io
+io.preach("top level")
+praise ChurchOfBeef():
+   io.preach("entry point")
+beef`)
+	assert.True(t, result.OK)
+	assert.Equal(t, "top level\nentry point\n", result.Output)
+}
+
+func TestRunReportsErrors(t *testing.T) {
+	result := Run("wrangle io\nio.preach(1)\nio.preach(1 + true)")
+	assert.False(t, result.OK)
+	assert.Equal(t, "1\nsnippet.beef:3:13: error[BE0003]: type mismatch: INTEGER + BOOLEAN\n"+
+		" 3 | io.preach(1 + true)\n   |             ^\n", result.Output)
+
+	result = Run("prep = 1")
+	assert.False(t, result.OK)
+	assert.Contains(t, result.Output, "snippet.beef:1:6: error[BE0101]")
+}
+
+func TestRunExit(t *testing.T) {
+	result := Run("wrangle io\nwrangle os\nio.preach(1)\nos.exit(0)\nio.preach(2)")
+	assert.True(t, result.OK)
+	assert.Equal(t, "1\n", result.Output)
+
+	assert.False(t, Run("wrangle os\nos.exit(3)").OK)
+}
+
+func TestRunLeavesDefaultStreams(t *testing.T) {
+	stdout := evaluator.Default.Stdout
+	Run("wrangle io\nio.preach(1)")
+	assert.Equal(t, stdout, evaluator.Default.Stdout)
+}
@@ -0,0 +1,83 @@
+package repl
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// Complete is a word completer for the line editor. It completes the word
+// before the cursor (pos, counted in runes) to a keyword or a name defined in
+// the session, and "module.partial" to the module's public members. head and
+// tail are the parts of line around the word, which the editor keeps as-is.
+func (s *Session) Complete(line string, pos int) (head string, completions []string, tail string) {
+	runes := []rune(line)
+	if pos > len(runes) {
+		pos = len(runes)
+	}
+	before, tail := string(runes[:pos]), string(runes[pos:])
+
+	start := len(before)
+	for start > 0 && isWordByte(before[start-1]) {
+		start--
+	}
+	head, word := before[:start], before[start:]
+	if word == "" {
+		return head, nil, tail
+	}
+
+	if dot := strings.LastIndexByte(word, '.'); dot >= 0 {
+		return head, s.completeMember(word[:dot], word[dot+1:]), tail
+	}
+
+	candidates := token.Keywords()
+	for name := range s.env.Bindings() {
+		candidates = append(candidates, name)
+	}
+	return head, matching(candidates, "", word), tail
+}
+
+// completeMember lists the public members of the module bound to name that
+// start with partial, spelled "name.member".
+func (s *Session) completeMember(name, partial string) []string {
+	value, ok := s.env.Get(name)
+	if !ok {
+		return nil
+	}
+	mod, ok := value.(*object.Module)
+	if !ok {
+		return nil
+	}
+
+	var members []string
+	for member := range mod.Members {
+		// Private members can't be accessed, so don't offer them
+		if !strings.HasPrefix(member, "_") {
+			members = append(members, member)
+		}
+	}
+	return matching(members, name+".", partial)
+}
+
+// matching returns prefix+candidate for every distinct candidate starting
+// with partial, sorted.
+func matching(candidates []string, prefix, partial string) []string {
+	seen := map[string]bool{}
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, partial) && !seen[c] {
+			seen[c] = true
+			matches = append(matches, prefix+c)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// isWordByte reports whether ch can be part of a completable word: an
+// identifier, or a module member access like io.pre
+func isWordByte(ch byte) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || '0' <= ch && ch <= '9' || ch == '_' || ch == '.'
+}
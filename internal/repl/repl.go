@@ -0,0 +1,266 @@
+// Package repl implements Beeflang's interactive read-eval-print loop.
+//
+// Every statement entered is evaluated in one environment that lives for the
+// whole session, so variables and functions declared earlier stay available.
+// Statements may span several lines: while the input so far is incomplete (an
+// unclosed block, string or parenthesis) the REPL shows a continuation prompt
+// and keeps reading.
+//
+// Input comes from a LineReader: a plain line scanner for piped input, or a
+// line editor with history and tab completion on a terminal (see StartStdio).
+// Errors are shown like the interpreter's own, with the offending part of the
+// input underlined.
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+const (
+	// Prompt is shown when the REPL is ready for a new statement
+	Prompt = "beef> "
+	// ContinuationPrompt is shown while a statement is still incomplete
+	ContinuationPrompt = "....> "
+	// InputName stands in for a file name in errors about the REPL's input
+	InputName = "<repl>"
+)
+
+// ErrInterrupted is returned by a LineReader when the user cancels the line
+// being edited (Ctrl-C). The REPL drops the statement entered so far.
+var ErrInterrupted = errors.New("interrupted")
+
+// LineReader supplies the REPL's input a line at a time. Prompt shows prompt,
+// then returns the line entered without its newline, io.EOF once the input is
+// exhausted, or ErrInterrupted.
+type LineReader interface {
+	Prompt(prompt string) (string, error)
+}
+
+// scanReader is the LineReader for plain input without line editing.
+type scanReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scanReader) Prompt(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+// Session is one REPL session: an environment that lives across inputs, and
+// where results and errors are written.
+type Session struct {
+	// Color turns on ANSI colors for results and errors
+	Color bool
+
+	env   *object.Environment
+	out   io.Writer
+	input []string // lines of the statement being evaluated, for error excerpts
+}
+
+// NewSession creates a session with an empty environment.
+func NewSession(out io.Writer) *Session {
+	return &Session{env: object.NewEnvironment(), out: out}
+}
+
+// Start runs a REPL session reading plain lines from in until it is
+// exhausted, writing prompts, results and errors to out.
+func Start(in io.Reader, out io.Writer) {
+	NewSession(out).Run(&scanReader{scanner: bufio.NewScanner(in), out: out})
+}
+
+// Run reads and evaluates statements until the input ends. Two blank lines in
+// a row evaluate an incomplete statement anyway, which reports what is wrong
+// with it.
+func (s *Session) Run(input LineReader) {
+	var lines []string // the statement entered so far
+	blanks := 0        // consecutive blank lines at the end of lines
+	for {
+		prompt := Prompt
+		if len(lines) > 0 {
+			prompt = ContinuationPrompt
+		}
+		line, err := input.Prompt(prompt)
+		if err == ErrInterrupted {
+			lines, blanks = nil, 0
+			continue
+		}
+		if err != nil {
+			fmt.Fprintln(s.out)
+			return
+		}
+
+		if strings.TrimSpace(line) == "" {
+			if len(lines) == 0 {
+				continue
+			}
+			blanks++
+		} else {
+			blanks = 0
+		}
+		lines = append(lines, line)
+
+		source := strings.Join(lines, "\n")
+		l := lexer.New(source)
+		l.SetConditions(evaluator.Default.Conditions)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		if blanks < 2 && Incomplete(source, p.ParseErrors()) {
+			continue
+		}
+		s.input, lines, blanks = lines, nil, 0
+
+		if len(p.ParseErrors()) > 0 {
+			for _, err := range p.ParseErrors() {
+				s.report(diagnostics.Diagnostic{
+					File:      InputName,
+					Line:      err.Line,
+					Column:    err.Column,
+					EndLine:   err.End.Line,
+					EndColumn: err.End.Column,
+					Severity:  diagnostics.Error,
+					Message:   err.Message,
+					Code:      err.Code,
+				})
+			}
+			continue
+		}
+		s.eval(program)
+	}
+}
+
+// eval runs one complete input and prints its result (see Echo).
+func (s *Session) eval(program *ast.Program) {
+	result := evaluator.Eval(program, s.env)
+	if errObj, ok := result.(*object.Error); ok {
+		// Errors raised inside a wrangled module carry that module's path
+		file := errObj.File
+		if file == "" {
+			file = InputName
+		}
+		s.report(diagnostics.Diagnostic{
+			File:      file,
+			Line:      errObj.Line,
+			Column:    errObj.Column,
+			EndLine:   errObj.EndLine,
+			EndColumn: errObj.EndColumn,
+			Severity:  diagnostics.Error,
+			Message:   errObj.Message,
+			Code:      errObj.Code,
+		})
+		return
+	}
+	lines := Echo(program, result)
+	for i, line := range lines {
+		if i == 0 {
+			line = s.renderer().Result(line)
+		}
+		fmt.Fprintln(s.out, line)
+	}
+}
+
+// Echo returns the lines the REPL shows for result, the value of program.
+// Only expressions have their value echoed - declarations, assignments and
+// null are silent. A function's documentation is shown under it, so entering
+// a function's name is a quick way to see how to call it.
+func Echo(program *ast.Program, result object.Object) []string {
+	if len(program.Statements) == 0 || result == nil || result == object.NULL {
+		return nil
+	}
+	if _, ok := program.Statements[len(program.Statements)-1].(*ast.ExpressionStatement); !ok {
+		return nil
+	}
+	lines := []string{object.Repr(result)}
+	if fn, ok := result.(*object.Function); ok && fn.Doc != "" {
+		for _, line := range strings.Split(fn.Doc, "\n") {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
+
+// report prints an error about the last input.
+func (s *Session) report(d diagnostics.Diagnostic) {
+	fmt.Fprint(s.out, s.renderer().Render(d))
+}
+
+// renderer formats errors and results, taking source excerpts from the input
+// being evaluated or, for errors inside modules, from the module's file.
+func (s *Session) renderer() diagnostics.Renderer {
+	return diagnostics.Renderer{
+		Color: s.Color,
+		Source: func(file string, n int) (string, bool) {
+			if file != InputName {
+				return diagnostics.ReadSourceLine(file, n)
+			}
+			if n < 1 || n > len(s.input) {
+				return "", false
+			}
+			return s.input[n-1], true
+		},
+	}
+}
+
+// Incomplete reports whether source is the start of a statement that carries
+// on past the end of the input: a 'praise', 'if', 'select', 'using' or 'feast while'
+// block not yet closed with 'beef', a '#when' without its '#end', an unclosed string,
+// or a syntax error at the very
+// end of the input (a trailing operator, an open parenthesis, a block header
+// missing its ':'). errs are the parse errors for source.
+func Incomplete(source string, errs []parser.ParseError) bool {
+	l := lexer.New(source)
+	depth := 0
+	var prev, tok token.Token
+	for tok = l.NextToken(); tok.Type != token.EOF; prev, tok = tok, l.NextToken() {
+		switch tok.Type {
+		case token.PRAISE, token.IF, token.SELECT, token.USING:
+			depth++
+		case token.FEAST_WHILE:
+			// "feast while" is two FEAST_WHILE tokens but one block
+			if prev.Type != token.FEAST_WHILE {
+				depth++
+			}
+		case token.BEEF:
+			depth--
+		case token.STRING:
+			// A closed string spans its contents plus two quotes
+			if tok.End.Offset-tok.Offset == len(tok.Literal)+1 {
+				return true
+			}
+		}
+	}
+	if depth > 0 {
+		return true
+	}
+	for _, err := range l.DirectiveErrors() {
+		if err.Unclosed {
+			return true
+		}
+	}
+
+	// tok is now EOF: errors there mean the parser ran out of input mid-statement
+	for _, err := range errs {
+		if err.Line == tok.Line && err.Column == tok.Column {
+			return true
+		}
+	}
+	return false
+}
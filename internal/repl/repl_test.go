@@ -0,0 +1,194 @@
+package repl
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper function to run a REPL session and return everything it printed
+func session(input string) string {
+	var out strings.Builder
+	Start(strings.NewReader(input), &out)
+	return out.String()
+}
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"prep x = 1", false},
+		{"x + 1", false},
+		{"praise add(a, b):", true},
+		{"praise add(a, b):\n   serve a + b", true},
+		{"praise add(a, b):\n   serve a + b\nbeef", false},
+		{"if x > 1:\n   prep y = 1\nelse:", true},
+		{"if x > 1:\n   prep y = 1\nelse:\n   prep y = 2\nbeef", false},
+		{"feast while x > 0:", true},
+		{"feast while x > 0:\n   x = x - 1\nbeef", false},
+		{"praise f():\n   if x:\n   beef", true},
+		{"select:\nwhen ch.recv():\n   1\nelse:", true},
+		{"select:\nwhen ch.recv():\n   1\nbeef", false},
+		{"using ch = chan.new():", true},
+		{"using ch = chan.new():\n   ch.send(1)\nbeef", false},
+		{`prep s = "open`, true},
+		{`prep s = "closed"`, false},
+		{"prep x = 1 +", true},
+		{"io.preach(1,", true},
+		{"praise f()", true},
+		{"#when debug\nio.preach(1)", true},
+		{"#when debug\nio.preach(1)\n#end", false},
+		// Errors before the end of the input can't be fixed by reading more
+		{"prep = 1", false},
+		{"beef", false},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+		assert.Equal(t, tt.expected, Incomplete(tt.input, p.ParseErrors()), "input %q", tt.input)
+	}
+}
+
+func TestSessionKeepsStateBetweenInputs(t *testing.T) {
+	out := session("prep x = 2\nx * 21\n")
+
+	assert.Equal(t, Prompt+Prompt+"42\n"+Prompt+"\n", out)
+}
+
+func TestMultiLineStatementShowsContinuationPrompt(t *testing.T) {
+	out := session("praise add(a, b):\n   serve a + b\nbeef\nadd(1,\n 2)\n")
+
+	expected := Prompt + ContinuationPrompt + ContinuationPrompt +
+		Prompt + ContinuationPrompt + "3\n" +
+		Prompt + "\n"
+	assert.Equal(t, expected, out)
+}
+
+func TestMultiLineString(t *testing.T) {
+	out := session("\"two\nlines\"\n")
+
+	assert.Contains(t, out, `"two\nlines"`+"\n")
+}
+
+func TestFunctionsShowTheirDocs(t *testing.T) {
+	out := session("praise add(a, b):\n   \"add sums\n   two numbers.\"\n   serve a + b\nbeef\nadd\n")
+
+	assert.Contains(t, out, "<function add(a, b)>\n  add sums\n  two numbers.\n")
+}
+
+func TestDeclarationsAreNotEchoed(t *testing.T) {
+	out := session("prep x = 5\nx = 6\npraise f():\nbeef\n")
+
+	assert.NotContains(t, out, "5")
+	assert.NotContains(t, out, "6")
+}
+
+func TestErrorsDoNotEndTheSession(t *testing.T) {
+	out := session("nope\nprep = 1\n1 + 1\n")
+
+	assert.Contains(t, out, "identifier not found: nope")
+	assert.Contains(t, out, "<repl>:1:6: error[BE0101]")
+	assert.Contains(t, out, "2\n")
+}
+
+func TestTwoBlankLinesSubmitIncompleteInput(t *testing.T) {
+	out := session("prep x = 1 +\n\n\n1\n")
+
+	assert.Contains(t, out, "no prefix parse function for EOF found")
+	// The session carries on normally afterwards
+	assert.True(t, strings.HasSuffix(out, Prompt+"1\n"+Prompt+"\n"), out)
+}
+
+// scriptedReader is a LineReader that replays fixed lines and errors
+type scriptedReader struct {
+	lines []string
+	errs  []error
+}
+
+func (r *scriptedReader) Prompt(prompt string) (string, error) {
+	if len(r.lines) == 0 {
+		return "", io.EOF
+	}
+	line, err := r.lines[0], r.errs[0]
+	r.lines, r.errs = r.lines[1:], r.errs[1:]
+	return line, err
+}
+
+func TestInterruptDropsTheStatementInProgress(t *testing.T) {
+	var out strings.Builder
+	input := &scriptedReader{
+		lines: []string{"praise f():", "", "1 + 2"},
+		errs:  []error{nil, ErrInterrupted, nil},
+	}
+	NewSession(&out).Run(input)
+
+	assert.Equal(t, "3\n\n", out.String())
+}
+
+func TestCompleteKeywordsAndNames(t *testing.T) {
+	s := NewSession(io.Discard)
+	s.eval(parseProgram(t, "prep preheat = 1\npraise presentation():\nbeef"))
+
+	head, completions, tail := s.Complete("x = pre + 1", 7)
+	assert.Equal(t, "x = ", head)
+	assert.Equal(t, []string{"preheat", "prep", "presentation"}, completions)
+	assert.Equal(t, " + 1", tail)
+
+	_, completions, _ = s.Complete("wran", 4)
+	assert.Equal(t, []string{"wrangle"}, completions)
+
+	_, completions, _ = s.Complete("x = ", 4)
+	assert.Empty(t, completions)
+}
+
+func TestCompleteModuleMembers(t *testing.T) {
+	s := NewSession(io.Discard)
+	s.eval(parseProgram(t, "wrangle io"))
+
+	head, completions, tail := s.Complete("io.pr", 5)
+	assert.Equal(t, "", head)
+	assert.Equal(t, []string{"io.preach", "io.preachf"}, completions)
+	assert.Equal(t, "", tail)
+
+	_, completions, _ = s.Complete("nothing.pr", 10)
+	assert.Empty(t, completions)
+}
+
+func TestCompleteCountsCursorInRunes(t *testing.T) {
+	s := NewSession(io.Discard)
+
+	head, completions, tail := s.Complete(`"béef" + wra)`, 12)
+	assert.Equal(t, `"béef" + `, head)
+	assert.Equal(t, []string{"wrangle"}, completions)
+	assert.Equal(t, ")", tail)
+}
+
+// Helper function to parse a program that must be free of syntax errors
+func parseProgram(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	assert.Empty(t, p.Errors(), "parser errors")
+	return program
+}
+
+func TestErrorsUnderlineTheInput(t *testing.T) {
+	out := session("prep x = 1\nx +\n  \"beef\"\n")
+
+	assert.Contains(t, out, "<repl>:1:3: error[BE0003]: type mismatch: INTEGER + STRING\n 1 | x +\n   |   ^\n")
+}
+
+func TestColorResults(t *testing.T) {
+	var out strings.Builder
+	s := NewSession(&out)
+	s.Color = true
+	s.Run(&scriptedReader{lines: []string{"1 + 2"}, errs: []error{nil}})
+
+	assert.Equal(t, "\x1b[32m3\x1b[0m\n\n", out.String())
+}
@@ -0,0 +1,84 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/peterh/liner"
+)
+
+// HistoryFile is where the line editor keeps its history between sessions,
+// relative to the user's home directory.
+const HistoryFile = ".beef_history"
+
+// StartStdio runs a REPL session on the process's standard input and output.
+// On a terminal input is read with a line editor: arrow keys move through the
+// history (kept in ~/.beef_history), Ctrl-R searches it, Tab completes keywords,
+// names in scope and module members, and Ctrl-C abandons the current statement.
+// Piped input is read line by line, as Start does. color turns on ANSI colors
+// for results and errors.
+func StartStdio(color bool) {
+	session := NewSession(os.Stdout)
+	session.Color = color
+	if !isTerminal(os.Stdin) {
+		session.Run(&scanReader{scanner: bufio.NewScanner(os.Stdin), out: os.Stdout})
+		return
+	}
+
+	editor := liner.NewLiner()
+	defer editor.Close()
+	editor.SetCtrlCAborts(true)
+	editor.SetTabCompletionStyle(liner.TabPrints)
+	editor.SetWordCompleter(session.Complete)
+
+	history := historyPath()
+	if history != "" {
+		if f, err := os.Open(history); err == nil {
+			editor.ReadHistory(f)
+			f.Close()
+		}
+	}
+
+	session.Run(&editorReader{editor: editor})
+
+	if history != "" {
+		if f, err := os.Create(history); err == nil {
+			editor.WriteHistory(f)
+			f.Close()
+		}
+	}
+}
+
+// editorReader is the LineReader for a terminal, backed by the line editor.
+type editorReader struct {
+	editor *liner.State
+}
+
+func (r *editorReader) Prompt(prompt string) (string, error) {
+	line, err := r.editor.Prompt(prompt)
+	if err == liner.ErrPromptAborted {
+		return "", ErrInterrupted
+	}
+	if err == nil && strings.TrimSpace(line) != "" {
+		r.editor.AppendHistory(line)
+	}
+	return line, err
+}
+
+// historyPath returns the history file's location, or "" when there is no
+// home directory to keep it in.
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, HistoryFile)
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a pipe or file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,31 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package terminal
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Cbreak switches the terminal f is attached to into cbreak mode: input is
+// handed over a key at a time, without waiting for Enter, and isn't echoed.
+// Unlike full raw mode, Ctrl+C still interrupts the program and output is
+// still processed, so printed newlines work as usual. The returned function
+// puts the terminal back the way it was.
+func Cbreak(f *os.File) (restore func() error, err error) {
+	fd := int(f.Fd())
+	old, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := *old
+	mode.Lflag &^= unix.ICANON | unix.ECHO
+	mode.Cc[unix.VMIN] = 1
+	mode.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &mode); err != nil {
+		return nil, err
+	}
+	return func() error { return unix.IoctlSetTermios(fd, ioctlSetTermios, old) }, nil
+}
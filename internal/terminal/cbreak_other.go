@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package terminal
+
+import (
+	"errors"
+	"os"
+)
+
+// Cbreak would switch the terminal into cbreak mode, but isn't supported on
+// this platform; keys are then only seen after Enter.
+func Cbreak(f *os.File) (restore func() error, err error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,40 @@
+// Package terminal queries and controls the terminal a program runs in. The
+// platform-specific parts are in terminal_unix.go and terminal_other.go.
+package terminal
+
+import (
+	"os"
+	"strconv"
+)
+
+// Default size reported when the size of the terminal can't be found, e.g.
+// when output is piped.
+const (
+	DefaultWidth  = 80
+	DefaultHeight = 24
+)
+
+// IsTerminal reports whether f is an interactive terminal rather than a pipe or file.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Size returns the width and height of the terminal f is attached to, in
+// characters. When f isn't a terminal the COLUMNS and LINES environment
+// variables are used, and failing those DefaultWidth and DefaultHeight.
+func Size(f *os.File) (width, height int) {
+	width, height, ok := size(f)
+	if ok {
+		return width, height
+	}
+	return envSize("COLUMNS", DefaultWidth), envSize("LINES", DefaultHeight)
+}
+
+func envSize(name string, fallback int) int {
+	n, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package terminal
+
+import "os"
+
+func size(f *os.File) (width, height int, ok bool) {
+	return 0, 0, false
+}
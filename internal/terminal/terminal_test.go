@@ -0,0 +1,28 @@
+package terminal
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeFallsBackWhenNotATerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	assert.False(t, IsTerminal(f))
+
+	t.Setenv("COLUMNS", "")
+	t.Setenv("LINES", "")
+	width, height := Size(f)
+	assert.Equal(t, DefaultWidth, width)
+	assert.Equal(t, DefaultHeight, height)
+
+	t.Setenv("COLUMNS", "120")
+	t.Setenv("LINES", "nope")
+	width, height = Size(f)
+	assert.Equal(t, 120, width)
+	assert.Equal(t, DefaultHeight, height)
+}
@@ -1,5 +1,7 @@
 package token
 
+import "sort"
+
 // TokenType represents the type of a token
 type TokenType string
 
@@ -7,8 +9,23 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
-	Line    int // line number in source (for error reporting)
-	Column  int // column number in source (for error reporting)
+	Line    int      // line number in source (for error reporting)
+	Column  int      // column number in source (for error reporting)
+	Offset  int      // byte offset of the token's first character
+	End     Position // position just past the token's last character
+}
+
+// Position is a location in source code. Lines and columns start at 1 and
+// Offset (bytes from the start of the source) at 0; the zero Position means "unknown".
+type Position struct {
+	Line   int
+	Column int
+	Offset int
+}
+
+// Pos returns where the token starts.
+func (t Token) Pos() Position {
+	return Position{Line: t.Line, Column: t.Column, Offset: t.Offset}
 }
 
 // Token types
@@ -19,8 +36,12 @@ const (
 	// Identifiers and literals
 	IDENT  TokenType = "IDENT"  // variable names, function names
 	INT    TokenType = "INT"    // integer literals
+	BIGINT TokenType = "BIGINT" // big integer literals: 123n
 	STRING TokenType = "STRING" // string literals
 
+	// Comments never reach the parser; the lexer collects them separately (see Lexer.Comments)
+	COMMENT TokenType = "COMMENT"
+
 	// Operators
 	ASSIGN   TokenType = "="
 	PLUS     TokenType = "+"
@@ -43,22 +64,41 @@ const (
 	NOT TokenType = "!"
 
 	// Delimiters
-	LPAREN TokenType = "("
-	RPAREN TokenType = ")"
-	COLON  TokenType = ":"
-	COMMA  TokenType = ","
-	DOT    TokenType = "."
+	LPAREN   TokenType = "("
+	RPAREN   TokenType = ")"
+	LBRACKET TokenType = "["
+	RBRACKET TokenType = "]"
+	LBRACE   TokenType = "{"
+	RBRACE   TokenType = "}"
+	COLON    TokenType = ":"
+	COMMA    TokenType = ","
+	DOT      TokenType = "."
+	OPT_DOT  TokenType = "?." // member access that gives null on null: config?.name
+	ARROW    TokenType = "->" // return type annotation: praise f() -> int:
+	PIPE     TokenType = "|>" // pass a value to a call: x |> f(y)
+	AT       TokenType = "@"  // decorator: @cache.memoize above praise
 
 	// Keywords
 	PRAISE      TokenType = "PRAISE"      // function declaration
 	BEEF        TokenType = "BEEF"        // block terminator
 	FEAST_WHILE TokenType = "FEAST_WHILE" // while loop
+	IN          TokenType = "IN"          // feast item in items, and membership: x in items
 	IF          TokenType = "IF"
 	ELSE        TokenType = "ELSE"
-	PREP        TokenType = "PREP"    // variable declaration
-	SERVE       TokenType = "SERVE"   // return
-	WRANGLE     TokenType = "WRANGLE" // import module
-	HERD        TokenType = "HERD"    // module keyword
+	PREP        TokenType = "PREP"     // variable declaration
+	SERVE       TokenType = "SERVE"    // return
+	WRANGLE     TokenType = "WRANGLE"  // import module
+	HERD        TokenType = "HERD"     // module keyword
+	PASTURE     TokenType = "PASTURE"  // namespace of a module file (pasture mygame.ai)
+	AS          TokenType = "AS"       // module alias (wrangle io as out)
+	EXPOSE      TokenType = "EXPOSE"   // selective import (wrangle io expose preach)
+	STAMPEDE    TokenType = "STAMPEDE" // run a call concurrently
+	SELECT      TokenType = "SELECT"   // wait on several channels
+	WHEN        TokenType = "WHEN"     // a case of a select
+	DESSERT     TokenType = "DESSERT"  // run a call when the function returns
+	USING       TokenType = "USING"    // a block that closes a resource on exit
+	ASSERT      TokenType = "ASSERT"   // fail unless a condition holds
+	YIELD       TokenType = "YIELD"    // produce a generator's next value
 	TRUE        TokenType = "TRUE"
 	FALSE       TokenType = "FALSE"
 	AND_WORD    TokenType = "AND" // 'and' keyword
@@ -67,21 +107,52 @@ const (
 )
 
 var keywords = map[string]TokenType{
-	"praise":  PRAISE,
-	"beef":    BEEF,
-	"feast":   FEAST_WHILE, // Will need special handling for "feast while"
-	"while":   FEAST_WHILE,
-	"if":      IF,
-	"else":    ELSE,
-	"prep":    PREP,
-	"serve":   SERVE,
-	"wrangle": WRANGLE,
-	"herd":    HERD,
-	"true":    TRUE,
-	"false":   FALSE,
-	"and":     AND_WORD,
-	"or":      OR_WORD,
-	"not":     NOT_WORD,
+	"praise":   PRAISE,
+	"beef":     BEEF,
+	"feast":    FEAST_WHILE, // Will need special handling for "feast while"
+	"while":    FEAST_WHILE,
+	"in":       IN,
+	"if":       IF,
+	"else":     ELSE,
+	"prep":     PREP,
+	"serve":    SERVE,
+	"wrangle":  WRANGLE,
+	"herd":     HERD,
+	"pasture":  PASTURE,
+	"as":       AS,
+	"expose":   EXPOSE,
+	"stampede": STAMPEDE,
+	"select":   SELECT,
+	"when":     WHEN,
+	"dessert":  DESSERT,
+	"using":    USING,
+	"assert":   ASSERT,
+	"yield":    YIELD,
+	"true":     TRUE,
+	"false":    FALSE,
+	"and":      AND_WORD,
+	"or":       OR_WORD,
+	"not":      NOT_WORD,
+}
+
+// Keywords returns every keyword spelling, sorted. Used for tab completion.
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// IsKeyword reports whether t is the type of a keyword token.
+func IsKeyword(t TokenType) bool {
+	for _, kw := range keywords {
+		if kw == t {
+			return true
+		}
+	}
+	return false
 }
 
 // LookupIdent checks if an identifier is a keyword
@@ -0,0 +1,500 @@
+// Package typecheck is a gradual type checker for Beeflang.
+//
+// Type annotations are optional (prep x: int = 1, praise add(a: int, b: int) -> int:).
+// The checker infers the types of unannotated code where it can and treats
+// everything else as "any", which is compatible with every type. That way an
+// unannotated script never produces type errors of its own; mistakes are only
+// reported where the types involved are known for certain.
+//
+// Checking is static and separate from running: the evaluator ignores annotations.
+package typecheck
+
+import (
+	"fmt"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// Type is the static type of an expression, named the way annotations spell it.
+type Type string
+
+const (
+	Int    Type = "int"
+	BigInt Type = "bigint"
+	Bool   Type = "bool"
+	String Type = "string"
+	Null   Type = "null"
+	Array  Type = "array"
+	Hash   Type = "hash"
+	Fn     Type = "fn"
+	Module Type = "module"
+	Any    Type = "any" // unknown: compatible with everything
+)
+
+// annotationTypes are the type names that can be written in an annotation.
+var annotationTypes = map[string]Type{
+	"int":    Int,
+	"bigint": BigInt,
+	"bool":   Bool,
+	"string": String,
+	"null":   Null,
+	"array":  Array,
+	"hash":   Hash,
+	"fn":     Fn,
+	"any":    Any,
+}
+
+// TypeError is a problem found by Check, with the position of the token it is about.
+// It mirrors parser.ParseError so both can be reported the same way.
+type TypeError struct {
+	Line    int
+	Column  int
+	End     token.Position // just past the source text the error is about
+	Code    string
+	Message string
+}
+
+// String formats the error like a parse error: "[line 3, col 7] message"
+func (e TypeError) String() string {
+	return fmt.Sprintf("[line %d, col %d] %s", e.Line, e.Column, e.Message)
+}
+
+// signature is the static shape of a user-defined function.
+type signature struct {
+	params []Type
+	result Type
+}
+
+// binding is what the checker knows about a name.
+type binding struct {
+	typ       Type
+	annotated bool       // declared with an explicit type, so assignments must match
+	sig       *signature // set for functions declared with 'praise'
+}
+
+// scope mirrors the evaluator's environments: one per function call,
+// with if/while blocks sharing their function's scope.
+type scope struct {
+	parent *scope
+	names  map[string]*binding
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{parent: parent, names: map[string]*binding{}}
+}
+
+func (s *scope) lookup(name string) (*binding, bool) {
+	for cur := s; cur != nil; cur = cur.parent {
+		if b, ok := cur.names[name]; ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+type checker struct {
+	scope  *scope
+	result []Type // return type of each enclosing function, innermost last
+	errors []TypeError
+}
+
+// Check type-checks a program and returns the errors it found, in source order.
+func Check(program *ast.Program) []TypeError {
+	c := &checker{scope: newScope(nil)}
+	c.statements(program.Statements)
+	return c.errors
+}
+
+// errorf records a type error spanning the given node
+func (c *checker) errorf(node ast.Node, code string, format string, a ...interface{}) {
+	start := node.Start()
+	c.errors = append(c.errors, TypeError{
+		Line:    start.Line,
+		Column:  start.Column,
+		End:     node.End(),
+		Code:    code,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// statements checks a list of statements. Function signatures are collected
+// first so functions can be called before (textually) being declared, just as
+// ChurchOfBeef can call anything declared at the top level.
+func (c *checker) statements(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		if fn, ok := stmt.(*ast.FunctionDeclaration); ok {
+			c.scope.names[fn.Name.Value] = functionBinding(fn, c.signatureOf(fn))
+		}
+	}
+	for _, stmt := range stmts {
+		c.statement(stmt)
+	}
+}
+
+func (c *checker) statement(stmt ast.Statement) {
+	switch s := stmt.(type) {
+	case *ast.VariableDeclaration:
+		valueType := c.expression(s.Value)
+		if s.Type == nil {
+			c.scope.names[s.Name.Value] = &binding{typ: valueType}
+			return
+		}
+		declared := c.annotation(s.Type)
+		if !assignable(valueType, declared) {
+			c.errorf(s.Name, diagnostics.CodeAnnotationMismatch,
+				"cannot initialize '%s' (%s) with a %s value", s.Name.Value, declared, valueType)
+		}
+		c.scope.names[s.Name.Value] = &binding{typ: declared, annotated: true}
+
+	case *ast.AssignmentStatement:
+		valueType := c.expression(s.Value)
+		b, ok := c.scope.lookup(s.Name.Value)
+		if !ok {
+			// Assignment to an undeclared name creates it, like the evaluator does
+			c.scope.names[s.Name.Value] = &binding{typ: valueType}
+			return
+		}
+		if b.annotated {
+			if !assignable(valueType, b.typ) {
+				c.errorf(s.Name, diagnostics.CodeAnnotationMismatch,
+					"cannot assign a %s value to '%s' (%s)", valueType, s.Name.Value, b.typ)
+			}
+			return
+		}
+		// Unannotated variables may change type; stop assuming anything about them
+		if valueType != b.typ {
+			b.typ = Any
+			b.sig = nil
+		}
+
+	case *ast.ReturnStatement:
+		valueType := c.expression(s.ReturnValue)
+		if len(c.result) == 0 {
+			return
+		}
+		expected := c.result[len(c.result)-1]
+		if !assignable(valueType, expected) {
+			c.errorf(s, diagnostics.CodeAnnotationMismatch,
+				"cannot serve a %s value from a function returning %s", valueType, expected)
+		}
+
+	case *ast.YieldStatement:
+		c.expression(s.Value)
+
+	case *ast.ExpressionStatement:
+		c.expression(s.Expression)
+
+	case *ast.AssertStatement:
+		c.expression(s.Condition)
+		c.expression(s.Message)
+
+	case *ast.StampedeStatement:
+		c.expression(s.Call)
+
+	case *ast.DessertStatement:
+		c.expression(s.Call)
+
+	case *ast.SelectStatement:
+		for _, sc := range s.Cases {
+			c.expression(sc.Call)
+			if sc.Name != nil {
+				// Channels carry values of any type
+				c.scope.names[sc.Name.Value] = &binding{typ: Any}
+			}
+			c.block(sc.Body)
+		}
+		c.block(s.Default)
+
+	case *ast.UsingStatement:
+		c.scope.names[s.Name.Value] = &binding{typ: c.expression(s.Value)}
+		c.block(s.Body)
+
+	case *ast.IfStatement:
+		c.expression(s.Condition)
+		c.block(s.Consequence)
+		c.block(s.Alternative)
+
+	case *ast.WhileLoop:
+		c.expression(s.Condition)
+		c.block(s.Body)
+
+	case *ast.ForEachLoop:
+		// Element types aren't tracked
+		c.expression(s.Iterable)
+		c.scope.names[s.Variable.Value] = &binding{typ: Any}
+		c.block(s.Body)
+
+	case *ast.FunctionDeclaration:
+		c.function(s)
+
+	case *ast.WrangleStatement:
+		// Module contents aren't known statically; members come out as any
+		for _, member := range s.Exposed {
+			c.scope.names[member.Value] = &binding{typ: Any}
+		}
+		if s.Alias != nil {
+			c.scope.names[s.Alias.Value] = &binding{typ: Module}
+		} else if len(s.Exposed) == 0 {
+			c.scope.names[s.ModuleName.Value] = &binding{typ: Module}
+		}
+
+	case *ast.BlockStatement:
+		c.block(s)
+	}
+}
+
+func (c *checker) block(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	c.statements(block.Statements)
+}
+
+// function checks a function body in a new scope holding its parameters.
+func (c *checker) function(fn *ast.FunctionDeclaration) {
+	for _, ta := range fn.ParameterTypes {
+		if ta != nil {
+			c.annotation(ta)
+		}
+	}
+	if fn.ReturnType != nil {
+		c.annotation(fn.ReturnType)
+	}
+
+	for _, d := range fn.Decorators {
+		c.expression(d.Expression)
+	}
+
+	sig := c.signatureOf(fn)
+	c.scope.names[fn.Name.Value] = functionBinding(fn, sig)
+
+	c.scope = newScope(c.scope)
+	for i, param := range fn.Parameters {
+		c.scope.names[param.Value] = &binding{typ: sig.params[i], annotated: sig.params[i] != Any}
+	}
+	c.result = append(c.result, sig.result)
+
+	c.block(fn.Body)
+
+	c.result = c.result[:len(c.result)-1]
+	c.scope = c.scope.parent
+}
+
+// functionBinding is what a function declaration binds its name to. A
+// decorator can serve anything, so a decorated function's name is any.
+func functionBinding(fn *ast.FunctionDeclaration, sig *signature) *binding {
+	if len(fn.Decorators) > 0 {
+		return &binding{typ: Any}
+	}
+	return &binding{typ: Fn, sig: sig}
+}
+
+// signatureOf reads a function's annotations; unannotated parts are any.
+func (c *checker) signatureOf(fn *ast.FunctionDeclaration) *signature {
+	sig := &signature{params: make([]Type, len(fn.Parameters)), result: Any}
+	for i := range fn.Parameters {
+		sig.params[i] = Any
+		if i < len(fn.ParameterTypes) && fn.ParameterTypes[i] != nil {
+			sig.params[i] = lookupAnnotation(fn.ParameterTypes[i])
+		}
+	}
+	if fn.ReturnType != nil {
+		sig.result = lookupAnnotation(fn.ReturnType)
+	}
+	return sig
+}
+
+// annotation resolves a written type name, reporting unknown names.
+func (c *checker) annotation(ta *ast.TypeAnnotation) Type {
+	if _, ok := annotationTypes[ta.Name]; !ok {
+		c.errorf(ta, diagnostics.CodeUnknownType, "unknown type '%s'", ta.Name)
+	}
+	return lookupAnnotation(ta)
+}
+
+// lookupAnnotation resolves a written type name; unknown names become any.
+func lookupAnnotation(ta *ast.TypeAnnotation) Type {
+	if t, ok := annotationTypes[ta.Name]; ok {
+		return t
+	}
+	return Any
+}
+
+// expression infers an expression's type, reporting errors found inside it.
+func (c *checker) expression(expr ast.Expression) Type {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return Int
+	case *ast.BigIntegerLiteral:
+		return BigInt
+	case *ast.BooleanLiteral:
+		return Bool
+	case *ast.StringLiteral:
+		return String
+
+	case *ast.IfExpression:
+		// Branches aren't required to agree, so the value could be either
+		c.statement(e.If)
+		return Any
+
+	case *ast.ArrayLiteral:
+		for _, el := range e.Elements {
+			c.expression(el)
+		}
+		return Array
+
+	case *ast.HashLiteral:
+		for _, pair := range e.Pairs {
+			c.expression(pair.Key)
+			c.expression(pair.Value)
+		}
+		return Hash
+
+	case *ast.IndexExpression:
+		// Element types aren't tracked, so what comes out is any
+		c.expression(e.Left)
+		c.expression(e.Index)
+		return Any
+
+	case *ast.Identifier:
+		if b, ok := c.scope.lookup(e.Value); ok {
+			return b.typ
+		}
+		return Any
+
+	case *ast.PrefixExpression:
+		right := c.expression(e.Right)
+		switch e.Operator {
+		case "!":
+			return Bool
+		case "-":
+			if right == BigInt {
+				return BigInt
+			}
+			if right != Int && right != Any {
+				c.errorf(e, diagnostics.CodeUnknownOperator, "unknown operator: -%s", right)
+			}
+			return Int
+		}
+		return Any
+
+	case *ast.InfixExpression:
+		return c.infix(e)
+
+	case *ast.FunctionCall:
+		return c.call(e)
+
+	case *ast.PipeExpression:
+		return c.call(e.Call())
+
+	case *ast.MemberAccessExpression:
+		c.expression(e.Object)
+		return Any
+	}
+	return Any
+}
+
+// infix applies the evaluator's operator rules to static types.
+func (c *checker) infix(e *ast.InfixExpression) Type {
+	left := c.expression(e.Left)
+	right := c.expression(e.Right)
+
+	if e.Operator == "in" {
+		switch {
+		case right == String && left != Any && left != String:
+			c.errorf(e, diagnostics.CodeTypeMismatch, "type mismatch: %s in %s", left, right)
+		case right != Any && right != Array && right != Hash && right != String:
+			c.errorf(e, diagnostics.CodeUnknownOperator, "unknown operator: %s in %s", left, right)
+		}
+		return Bool
+	}
+
+	isComparison := e.Operator == "==" || e.Operator == "!=" || e.Operator == "<" ||
+		e.Operator == ">" || e.Operator == "<=" || e.Operator == ">="
+
+	if left == Any || right == Any {
+		if isComparison {
+			return Bool
+		}
+		return Any
+	}
+
+	switch {
+	case left == Int && right == Int:
+		if isComparison {
+			return Bool
+		}
+		return Int
+	case isInteger(left) && isInteger(right):
+		if isComparison {
+			return Bool
+		}
+		return BigInt
+	case left == String && right == String:
+		switch e.Operator {
+		case "+":
+			return String
+		case "==", "!=":
+			return Bool
+		}
+	case e.Operator == "==" || e.Operator == "!=":
+		return Bool
+	case left != right:
+		c.errorf(e, diagnostics.CodeTypeMismatch, "type mismatch: %s %s %s", left, e.Operator, right)
+		return Any
+	}
+	c.errorf(e, diagnostics.CodeUnknownOperator, "unknown operator: %s %s %s", left, e.Operator, right)
+	return Any
+}
+
+// isInteger reports whether t is int or bigint, which mix in arithmetic.
+func isInteger(t Type) bool {
+	return t == Int || t == BigInt
+}
+
+// call checks a call against the callee's signature when it is known.
+func (c *checker) call(e *ast.FunctionCall) Type {
+	argTypes := make([]Type, len(e.Arguments))
+	for i, arg := range e.Arguments {
+		argTypes[i] = c.expression(arg)
+	}
+
+	var sig *signature
+	callee := Any
+	if ident, ok := e.Function.(*ast.Identifier); ok {
+		if b, found := c.scope.lookup(ident.Value); found {
+			callee = b.typ
+			sig = b.sig
+		}
+	} else {
+		callee = c.expression(e.Function)
+	}
+
+	if callee != Any && callee != Fn {
+		c.errorf(e, diagnostics.CodeNotAFunction, "not a function: %s", callee)
+		return Any
+	}
+	if sig == nil {
+		return Any
+	}
+
+	if len(argTypes) != len(sig.params) {
+		c.errorf(e, diagnostics.CodeWrongArgumentCount,
+			"wrong number of arguments: want %d, got %d", len(sig.params), len(argTypes))
+		return sig.result
+	}
+	for i, argType := range argTypes {
+		if !assignable(argType, sig.params[i]) {
+			c.errorf(e.Arguments[i], diagnostics.CodeAnnotationMismatch,
+				"argument %d: cannot use a %s value as %s", i+1, argType, sig.params[i])
+		}
+	}
+	return sig.result
+}
+
+// assignable reports whether a value of type from can be used where to is expected.
+func assignable(from, to Type) bool {
+	return from == Any || to == Any || from == to
+}
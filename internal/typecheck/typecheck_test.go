@@ -0,0 +1,178 @@
+package typecheck
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper function to parse source and type-check it
+func check(t *testing.T, input string) []TypeError {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	assert.Empty(t, p.Errors(), "parser errors")
+	return Check(program)
+}
+
+func TestUnannotatedProgramHasNoErrors(t *testing.T) {
+	input := `
+wrangle io
+
+praise add(x, y):
+   serve x + y
+beef
+
+praise ChurchOfBeef():
+   prep counter = 3
+   feast while counter > 0:
+      io.preach(add(counter, "x"))
+      counter = counter - 1
+   beef
+   counter = "done"
+   io.preach(counter + "!")
+beef
+`
+	assert.Empty(t, check(t, input))
+}
+
+func TestAnnotatedProgramHasNoErrors(t *testing.T) {
+	input := `
+praise add(a: int, b: int) -> int:
+   prep sum: int = a + b
+   serve sum
+beef
+
+praise greet(name: string) -> string:
+   serve "Hello, " + name
+beef
+
+praise ChurchOfBeef():
+   prep total: int = add(1, 2)
+   prep ok: bool = total > 2
+   prep message: string = greet("Beef")
+   prep anything: any = 5
+   anything = "five"
+beef
+`
+	assert.Empty(t, check(t, input))
+}
+
+func TestAnnotationMismatches(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`prep x: int = "five"`, "cannot initialize 'x' (int) with a string value"},
+		{"prep x: int = 5\nx = true", "cannot assign a bool value to 'x' (int)"},
+		{"praise f() -> int:\n   serve \"no\"\nbeef", "cannot serve a string value from a function returning int"},
+		{"praise f(n: int):\n   serve n\nbeef\nf(\"two\")", "argument 1: cannot use a string value as int"},
+		{"praise f(n: int) -> string:\n   serve \"x\"\nbeef\nprep y: int = f(1)", "cannot initialize 'y' (int) with a string value"},
+		{"prep ok: int = 1 in [1]", "cannot initialize 'ok' (int) with a bool value"},
+		{"praise f(n: int, s: string):\nbeef\n\"two\" |> f(\"s\")", "argument 1: cannot use a string value as int"},
+		{"praise f(n: int) -> string:\n   serve \"x\"\nbeef\nprep y: int = 1 |> f", "cannot initialize 'y' (int) with a string value"},
+	}
+
+	for _, tt := range tests {
+		errs := check(t, tt.input)
+		if assert.Len(t, errs, 1, tt.input) {
+			assert.Equal(t, diagnostics.CodeAnnotationMismatch, errs[0].Code, tt.input)
+			assert.Equal(t, tt.expected, errs[0].Message)
+		}
+	}
+}
+
+func TestInferredTypesCatchOperatorErrors(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedCode string
+		expected     string
+	}{
+		{"prep s = \"HP: \"\nprep n = 10\ns + n", diagnostics.CodeTypeMismatch, "type mismatch: string + int"},
+		{"true + false", diagnostics.CodeUnknownOperator, "unknown operator: bool + bool"},
+		{"-\"beef\"", diagnostics.CodeUnknownOperator, "unknown operator: -string"},
+		{"prep x = 5\nx(1)", diagnostics.CodeNotAFunction, "not a function: int"},
+		{"1 in \"abc\"", diagnostics.CodeTypeMismatch, "type mismatch: int in string"},
+		{"prep n = 1\nn in 5", diagnostics.CodeUnknownOperator, "unknown operator: int in int"},
+		{"prep score = 1n * 2\nscore + \"pts\"", diagnostics.CodeTypeMismatch, "type mismatch: bigint + string"},
+		{"praise f(n: int):\nbeef\nf(-5n)", diagnostics.CodeAnnotationMismatch, "argument 1: cannot use a bigint value as int"},
+		{"praise add(a, b):\n   serve a + b\nbeef\nadd(1)", diagnostics.CodeWrongArgumentCount, "wrong number of arguments: want 2, got 1"},
+	}
+
+	for _, tt := range tests {
+		errs := check(t, tt.input)
+		if assert.Len(t, errs, 1, tt.input) {
+			assert.Equal(t, tt.expectedCode, errs[0].Code, tt.input)
+			assert.Equal(t, tt.expected, errs[0].Message)
+		}
+	}
+}
+
+func TestUnknownTypeName(t *testing.T) {
+	errs := check(t, "praise f(a: str) -> number:\n   serve a\nbeef")
+
+	assert.Len(t, errs, 2)
+	for _, err := range errs {
+		assert.Equal(t, diagnostics.CodeUnknownType, err.Code)
+	}
+	assert.Equal(t, "unknown type 'str'", errs[0].Message)
+	assert.Equal(t, 1, errs[0].Line)
+	assert.Equal(t, 13, errs[0].Column)
+	assert.Equal(t, "unknown type 'number'", errs[1].Message)
+}
+
+func TestFunctionsCanBeCalledBeforeDeclaration(t *testing.T) {
+	input := `praise ChurchOfBeef():
+   prep n: int = half(10)
+beef
+
+praise half(n: int) -> int:
+   serve n / 2
+beef`
+	assert.Empty(t, check(t, input))
+}
+
+func TestDecoratedFunctionsAreAny(t *testing.T) {
+	// The decorator could serve anything, so calls aren't checked against
+	// the declared signature, but the body still is
+	input := `praise wrap(fn):
+   serve fn
+beef
+
+@wrap
+praise half(n: int) -> int:
+   serve n / 2
+beef
+
+prep s: string = half("ten")`
+	assert.Empty(t, check(t, input))
+
+	errs := check(t, `@wrap
+praise bad() -> int:
+   serve "ten"
+beef`)
+	assert.Len(t, errs, 1)
+}
+
+func TestModuleMembersAreAny(t *testing.T) {
+	input := `wrangle io expose input
+prep name: string = input()
+prep age: int = input()`
+	assert.Empty(t, check(t, input))
+}
+
+func TestCollections(t *testing.T) {
+	assert.Empty(t, check(t, `prep cuts: array = ["brisket", "ribs"]
+prep prices: hash = {"brisket": 20}
+prep first: string = cuts[0]
+prep total: int = prices["brisket"] + 1`))
+
+	errs := check(t, `prep cuts: array = {"a": 1}
+prep n = [1] + 2`)
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, "cannot initialize 'cuts' (array) with a hash value", errs[0].Message)
+		assert.Equal(t, "type mismatch: array + int", errs[1].Message)
+	}
+}
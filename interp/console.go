@@ -0,0 +1,88 @@
+package interp
+
+import (
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/elitwilson/beeflang/internal/repl"
+)
+
+// ConsoleName stands in for a file name in errors about console input.
+const ConsoleName = "<console>"
+
+// Console reads statements a line at a time into an interpreter's globals,
+// the way the REPL does, for a game's debug console or a tool's command box:
+//
+//	console := in.Console()
+//	out, err := console.Exec(`io.preach(player["hp"])`)
+//
+// A statement can span several Exec calls - an unclosed block, string or
+// parenthesis waits for the lines that finish it (see Incomplete). The
+// console shares the interpreter's globals, so it sees and can change the
+// state of the program the interpreter ran. A Console is for one goroutine
+// at a time; the interpreter itself can be busy in another, running a game
+// loop through an Execution.
+type Console struct {
+	in    *Interpreter
+	lines []string // the statement entered so far
+}
+
+// Console returns a console over the interpreter's globals.
+func (in *Interpreter) Console() *Console {
+	return &Console{in: in}
+}
+
+// Exec adds a line of input. Once it completes a statement, the statement
+// runs and output is everything it printed with io.preach and friends,
+// followed by its value as the REPL would echo it. A statement that doesn't
+// parse returns an ErrorList, one that fails the *Error that stopped it -
+// with whatever it printed before failing as output - and one that calls
+// os.exit with a non-zero status an *ExitError. While the statement is
+// incomplete, Exec returns "" and nil.
+func (c *Console) Exec(line string) (output string, err error) {
+	c.lines = append(c.lines, line)
+	source := strings.Join(c.lines, "\n")
+	l := lexer.New(source)
+	l.SetConditions(c.in.rt.Conditions)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if repl.Incomplete(source, p.ParseErrors()) {
+		return "", nil
+	}
+	c.lines = nil
+
+	if errs := p.ParseErrors(); len(errs) > 0 {
+		return "", errorList(ConsoleName, errs)
+	}
+
+	var out strings.Builder
+	result := c.in.exec(func() object.Object {
+		stdout := c.in.rt.Stdout
+		c.in.rt.Stdout = &out
+		defer func() { c.in.rt.Stdout = stdout }()
+		return evaluator.Eval(program, c.in.env)
+	})
+	if err := runError(ConsoleName, result); err != nil {
+		return out.String(), err
+	}
+	if _, failed := result.(*object.Error); !failed {
+		for _, line := range repl.Echo(program, result) {
+			out.WriteString(line + "\n")
+		}
+	}
+	return out.String(), nil
+}
+
+// Incomplete reports whether the console is part way through a statement,
+// waiting for more lines - to show a continuation prompt, say.
+func (c *Console) Incomplete() bool {
+	return len(c.lines) > 0
+}
+
+// Reset drops a statement entered part way, like Ctrl-C in the REPL.
+func (c *Console) Reset() {
+	c.lines = nil
+}
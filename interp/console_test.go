@@ -0,0 +1,103 @@
+package interp_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/elitwilson/beeflang/interp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleExec(t *testing.T) {
+	var stdout bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &stdout, Script: true})
+	assert.NoError(t, in.Run("game.beef", "prep player = {\"hp\": 10}\n"))
+	console := in.Console()
+
+	// Output and the echoed value are returned, not written to Stdout
+	out, err := console.Exec(`wrangle io`)
+	assert.NoError(t, err)
+	assert.Equal(t, "", out)
+	out, err = console.Exec(`io.preach(player["hp"])`)
+	assert.NoError(t, err)
+	assert.Equal(t, "10\n", out)
+	out, err = console.Exec(`player["hp"] * 2`)
+	assert.NoError(t, err)
+	assert.Equal(t, "20\n", out)
+	out, err = console.Exec(`"brisket"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "\"brisket\"\n", out)
+	assert.Empty(t, stdout.String())
+
+	// The console changes the program's globals
+	_, err = console.Exec(`player = {"hp": 99}`)
+	assert.NoError(t, err)
+	assert.NoError(t, in.Run("check.beef", "wrangle io\nio.preach(player[\"hp\"])\n"))
+	assert.Equal(t, "99\n", stdout.String())
+}
+
+func TestConsoleMultiLineStatements(t *testing.T) {
+	console := interp.New(interp.Options{Script: true}).Console()
+
+	for _, line := range []string{"praise add(a, b):", "   serve a + b"} {
+		out, err := console.Exec(line)
+		assert.NoError(t, err)
+		assert.Equal(t, "", out)
+		assert.True(t, console.Incomplete())
+	}
+	out, err := console.Exec("beef")
+	assert.NoError(t, err)
+	assert.Equal(t, "", out)
+	assert.False(t, console.Incomplete())
+
+	out, err = console.Exec("add(1, 2)")
+	assert.NoError(t, err)
+	assert.Equal(t, "3\n", out)
+
+	// Reset drops a half-entered statement
+	_, _ = console.Exec("if true:")
+	console.Reset()
+	assert.False(t, console.Incomplete())
+	out, err = console.Exec("5")
+	assert.NoError(t, err)
+	assert.Equal(t, "5\n", out)
+}
+
+func TestConsoleErrors(t *testing.T) {
+	console := interp.New(interp.Options{Script: true}).Console()
+
+	_, err := console.Exec("prep = 1")
+	var list interp.ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("expected an ErrorList, got %v", err)
+	}
+	assert.Equal(t, interp.ConsoleName, list[0].File)
+
+	// What was printed before the error is kept
+	out, err := console.Exec("wrangle io\nio.preach(\"before\")\nmissing")
+	var runErr *interp.Error
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected an Error, got %v", err)
+	}
+	assert.Equal(t, "before\n", out)
+	assert.Equal(t, "<console>:3:1: error[BE0002]: identifier not found: missing", err.Error())
+
+	// The console carries on after an error
+	out, err = console.Exec("1 + 1")
+	assert.NoError(t, err)
+	assert.Equal(t, "2\n", out)
+}
+
+func TestConsoleWrongNumberOfArguments(t *testing.T) {
+	console := interp.New(interp.Options{Script: true}).Console()
+	_, err := console.Exec("praise add(a, b):\n   serve a + b\nbeef")
+	assert.NoError(t, err)
+
+	_, err = console.Exec("add(1)")
+	var runErr *interp.Error
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected an Error, got %v", err)
+	}
+	assert.Equal(t, "<console>:1:4: error[BE0035]: add() takes 2 arguments, got 1", err.Error())
+}
@@ -0,0 +1,97 @@
+package interp
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// EventsName stands in for a file name in errors from event handlers that
+// have no file of their own, such as builtins.
+const EventsName = "<events>"
+
+// Emit queues an event for the handlers the program registered with
+// events.on:
+//
+//	in.Emit("enemy_killed", map[string]any{"kind": "goblin", "xp": 15})
+//
+// The handlers don't run yet: they run when the events are dispatched,
+// either by Dispatch or by the program calling events.dispatch from its own
+// loop. So Emit can be called from any goroutine, while a program runs. The
+// payload is converted to a Beeflang value: nil, a bool, string, int, int32
+// or int64, a []string or []int, or a []any or map[string]any of those (a
+// hash with its keys in sorted order). Emit returns an error, queuing
+// nothing, if it holds anything else.
+func (in *Interpreter) Emit(name string, payload any) error {
+	value, err := toValue(payload)
+	if err != nil {
+		return fmt.Errorf("emitting %s: %w", name, err)
+	}
+	in.rt.Emit(name, value)
+	return nil
+}
+
+// Dispatch calls the handlers of the events emitted so far, in the order they
+// were emitted - once a frame, say. Like Reload it can be called while an
+// Execution is paused between slices. A handler that fails stops the
+// dispatch and Dispatch returns its *Error; the events after it stay queued
+// for the next one.
+func (in *Interpreter) Dispatch() error {
+	return runError(EventsName, in.exec(in.rt.DispatchEvents))
+}
+
+// toValue converts a Go value to the Beeflang value it stands for.
+func toValue(v any) (object.Object, error) {
+	switch v := v.(type) {
+	case nil:
+		return object.NULL, nil
+	case bool:
+		if v {
+			return object.TRUE, nil
+		}
+		return object.FALSE, nil
+	case string:
+		return &object.String{Value: v}, nil
+	case int:
+		return &object.Integer{Value: int64(v)}, nil
+	case int32:
+		return &object.Integer{Value: int64(v)}, nil
+	case int64:
+		return &object.Integer{Value: v}, nil
+	case []any:
+		elements := make([]object.Object, len(v))
+		for i, el := range v {
+			value, err := toValue(el)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = value
+		}
+		return object.NewArray(elements), nil
+	case []string:
+		elements := make([]object.Object, len(v))
+		for i, s := range v {
+			elements[i] = &object.String{Value: s}
+		}
+		return object.NewArray(elements), nil
+	case []int:
+		elements := make([]object.Object, len(v))
+		for i, n := range v {
+			elements[i] = &object.Integer{Value: int64(n)}
+		}
+		return object.NewArray(elements), nil
+	case map[string]any:
+		hash := object.NewHash()
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			value, err := toValue(v[key])
+			if err != nil {
+				return nil, err
+			}
+			hash.Set(&object.String{Value: key}, value)
+		}
+		return hash, nil
+	}
+	return nil, fmt.Errorf("%T has no Beeflang value", v)
+}
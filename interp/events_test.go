@@ -0,0 +1,141 @@
+package interp_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/elitwilson/beeflang/interp"
+	"github.com/stretchr/testify/assert"
+)
+
+const killTracker = `wrangle io
+wrangle events
+praise killed(enemy):
+   io.preach(enemy["kind"] + " for " + enemy["xp"])
+beef
+events.on("enemy_killed", killed)
+`
+
+func TestEmitQueuesUntilDispatch(t *testing.T) {
+	var out bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &out, Script: true})
+	if err := in.Run("tracker.beef", killTracker); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, in.Emit("enemy_killed", map[string]any{"kind": "goblin", "xp": "15"}))
+	assert.NoError(t, in.Emit("level_up", nil))
+	assert.Empty(t, out.String(), "handlers wait for Dispatch")
+
+	assert.NoError(t, in.Dispatch())
+	assert.Equal(t, "goblin for 15\n", out.String())
+	assert.NoError(t, in.Dispatch())
+	assert.Equal(t, "goblin for 15\n", out.String(), "each event is dispatched once")
+}
+
+func TestEmitFromManyGoroutines(t *testing.T) {
+	var out bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &out, Script: true})
+	if err := in.Run("tracker.beef", killTracker); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, in.Emit("enemy_killed", map[string]any{"kind": "rat", "xp": "1"}))
+		}()
+	}
+	wg.Wait()
+
+	assert.NoError(t, in.Dispatch())
+	assert.Equal(t, 10, bytes.Count(out.Bytes(), []byte("rat for 1\n")))
+}
+
+func TestEmitPayloads(t *testing.T) {
+	var out bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &out, Script: true})
+	if err := in.Run("show.beef", "wrangle io\nwrangle events\nevents.on(\"show\", io.inspect)\npraise show(v):\n   io.preach(io.inspect(v))\nbeef\nevents.on(\"show\", show)\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	payloads := []any{nil, true, 7, int64(8), "hi", []int{1, 2}, []string{"a"},
+		[]any{1, "b", false}, map[string]any{"b": 2, "a": []any{nil}}}
+	for _, payload := range payloads {
+		assert.NoError(t, in.Emit("show", payload))
+	}
+	assert.NoError(t, in.Dispatch())
+	assert.Equal(t, `null
+true
+7
+8
+"hi"
+[1, 2]
+["a"]
+[1, "b", false]
+{"a": [null], "b": 2}
+`, out.String())
+
+	err := in.Emit("show", 1.5)
+	assert.EqualError(t, err, "emitting show: float64 has no Beeflang value")
+	err = in.Emit("show", []any{struct{}{}})
+	assert.EqualError(t, err, "emitting show: struct {} has no Beeflang value")
+}
+
+func TestDispatchReportsFailingHandler(t *testing.T) {
+	var out bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &out, Script: true})
+	if err := in.Run("hp.beef", "wrangle io\nwrangle events\npraise hit(n):\n   io.preach(n + 1)\nbeef\nevents.on(\"hit\", hit)\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, in.Emit("hit", 1))
+	assert.NoError(t, in.Emit("hit", "lots"))
+	assert.NoError(t, in.Emit("hit", 3))
+	err := in.Dispatch()
+	var runErr *interp.Error
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected an *interp.Error, got %v", err)
+	}
+	assert.Equal(t, "hp.beef", runErr.File)
+	assert.Equal(t, 4, runErr.Line)
+	assert.Equal(t, "2\n", out.String())
+
+	// The event after the failing one waited for the next dispatch
+	assert.NoError(t, in.Dispatch())
+	assert.Equal(t, "2\n4\n", out.String())
+}
+
+func TestProgramDispatchesItsOwnEvents(t *testing.T) {
+	var out bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &out, Script: true})
+	ex, err := in.Start("loop.beef", `wrangle io
+wrangle events
+wrangle array
+prep quit = []
+praise stop():
+   array.push(quit, true)
+beef
+events.on("quit", stop)
+feast while array.contains(quit, true) == false:
+   events.dispatch()
+beef
+io.preach("bye")
+`, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, _ := ex.Resume()
+	assert.False(t, done)
+	assert.NoError(t, in.Emit("quit", nil))
+	for !done {
+		done, err = ex.Resume()
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, "bye\n", out.String())
+}
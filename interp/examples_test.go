@@ -0,0 +1,79 @@
+package interp_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/elitwilson/beeflang/interp"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the .expected files from the examples' output")
+
+// lockedBuffer collects output written from several tasks at once.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// runExample runs testdata/examples/NAME.beef, with NAME.stdin as its input
+// if there is one, and returns what it printed followed by the error that
+// stopped it, if any - the contents of NAME.expected.
+func runExample(t *testing.T, path string) string {
+	source, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	stdin, err := os.ReadFile(strings.TrimSuffix(path, ".beef") + ".stdin")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	out := &lockedBuffer{}
+	in := interp.New(interp.Options{
+		Stdout:     out,
+		Stderr:     out,
+		Stdin:      bytes.NewReader(stdin),
+		ModulePath: []string{filepath.Join("testdata", "modules")},
+	})
+	if err := in.Run(filepath.Base(path), string(source)); err != nil {
+		out.Write([]byte("error: " + err.Error() + "\n"))
+	}
+	return out.buf.String()
+}
+
+// TestExamples runs every program in testdata/examples, a tour of the
+// language, and compares its output with NAME.expected. After a deliberate
+// change, go test ./interp -update rewrites the .expected files.
+func TestExamples(t *testing.T) {
+	examples, err := filepath.Glob(filepath.Join("testdata", "examples", "*.beef"))
+	assert.NoError(t, err)
+	if len(examples) == 0 {
+		t.Fatal("no examples in testdata/examples")
+	}
+
+	for _, example := range examples {
+		expected := strings.TrimSuffix(example, ".beef") + ".expected"
+		t.Run(strings.TrimSuffix(filepath.Base(example), ".beef"), func(t *testing.T) {
+			got := runExample(t, example)
+			if *update {
+				assert.NoError(t, os.WriteFile(expected, []byte(got), 0o644))
+				return
+			}
+			want, err := os.ReadFile(expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, string(want), got)
+		})
+	}
+}
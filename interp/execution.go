@@ -0,0 +1,66 @@
+package interp
+
+import (
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// Execution is a program running a slice at a time, for a game loop that
+// gives scripts a bounded amount of work each frame:
+//
+//	ex, err := in.Start("ai.beef", source, 1000)
+//	...
+//	for !done { // once a frame
+//	    done, err = ex.Resume()
+//	}
+//
+// Between slices the program is paused where it was, deep in a loop or a
+// function call, with its variables intact. The interpreter's other methods
+// - Snapshot, Reload - can be called while it is paused; its Run and Start
+// wait until it has finished, so call Stop on executions you give up on.
+type Execution struct {
+	name   string
+	slicer *evaluator.Slicer
+}
+
+// Start prepares a program to be run by Resume, steps evaluation steps (AST
+// nodes, roughly expressions) per slice. It only parses the program, so it
+// returns an ErrorList if it doesn't parse; nothing runs until the first
+// Resume.
+func (in *Interpreter) Start(name, source string, steps int) (*Execution, error) {
+	program, err := in.parse(name, source)
+	if err != nil {
+		return nil, err
+	}
+
+	slicer := evaluator.NewSlicer(in.rt, int64(max(steps, 1)))
+	slicer.Start(func() object.Object {
+		return in.exec(func() object.Object {
+			return slicer.Run(func() object.Object {
+				result := evaluator.EvalFiles(in.env, []evaluator.SourceFile{{Name: name, Program: program}})
+				if _, failed := result.(*object.Error); !failed && !in.opts.Script {
+					result = evaluator.CallEntryPoint(in.env)
+				}
+				return result
+			})
+		})
+	})
+	return &Execution{name: name, slicer: slicer}, nil
+}
+
+// Resume runs the program for one more slice. done reports whether the
+// program has finished, in which case err is what Run would have returned
+// for it. Resuming a finished execution returns the same again.
+func (ex *Execution) Resume() (done bool, err error) {
+	done, result := ex.slicer.Resume()
+	if !done {
+		return false, nil
+	}
+	return true, runError(ex.name, result)
+}
+
+// Stop ends the program where it is paused, as if interrupted, and waits for
+// it to unwind. Resume then reports it done.
+func (ex *Execution) Stop() {
+	ex.slicer.Stop()
+}
@@ -0,0 +1,92 @@
+package interp_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/elitwilson/beeflang/interp"
+	"github.com/stretchr/testify/assert"
+)
+
+const countdown = `wrangle io
+prep n = 3
+praise ChurchOfBeef():
+   feast while n > 0:
+      io.preach(n)
+      n = n - 1
+   beef
+   io.preach("liftoff")
+beef
+`
+
+func TestExecutionRunsASliceAtATime(t *testing.T) {
+	var out bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &out})
+	ex, err := in.Start("countdown.beef", countdown, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frames := 0
+	for done := false; !done; frames++ {
+		done, err = ex.Resume()
+		if frames == 0 {
+			assert.Empty(t, out.String(), "the first slice is spent before the countdown")
+		}
+	}
+	assert.NoError(t, err)
+	assert.Greater(t, frames, 3)
+	assert.Equal(t, "3\n2\n1\nliftoff\n", out.String())
+}
+
+func TestExecutionPausedStateIsReachable(t *testing.T) {
+	var out bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &out, Script: true})
+	ex, err := in.Start("countdown.beef", "wrangle io\nprep n = 3\nfeast while n > 0:\n   io.preach(n)\n   n = n - 1\nbeef\nio.preach(\"liftoff\")\n", 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, _ := ex.Resume()
+	assert.False(t, done)
+
+	// Between frames the host can change the program's state, and it carries
+	// on with the change
+	var saved bytes.Buffer
+	assert.NoError(t, in.Snapshot().Encode(&saved))
+	assert.Contains(t, saved.String(), `"n"`)
+	s, err := interp.DecodeSnapshot(strings.NewReader(`{"format": 1, "globals": {"n": {"int": 7}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.Restore(s)
+
+	for !done {
+		done, err = ex.Resume()
+	}
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "6\n5\n4\n3\n2\n1\nliftoff\n")
+}
+
+func TestExecutionStartAndStop(t *testing.T) {
+	in := interp.New(interp.Options{Script: true})
+	_, err := in.Start("broken.beef", "prep = 1\n", 10)
+	assert.IsType(t, interp.ErrorList{}, err)
+
+	ex, err := in.Start("forever.beef", "feast while true:\n   prep x = 1\nbeef\n", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, _ := ex.Resume()
+	assert.False(t, done)
+	ex.Stop()
+
+	done, err = ex.Resume()
+	assert.True(t, done)
+	if assert.IsType(t, &interp.Error{}, err) {
+		assert.Equal(t, "BE0017", err.(*interp.Error).Code)
+	}
+
+	// The interpreter is free for other runs again
+	assert.NoError(t, in.Run("after.beef", "prep y = 2\n"))
+}
@@ -0,0 +1,290 @@
+// Package interp embeds the Beeflang interpreter in Go programs: a game, a
+// tool, or a test that wants to run .beef scripts with its own input and
+// output.
+//
+//	in := interp.New(interp.Options{Stdout: &buf, ModulePath: []string{"scripts"}})
+//	if err := in.RunFile("scripts/ai.beef"); err != nil {
+//	    log.Println(err) // scripts/ai.beef:12:9: error[BE0003]: type mismatch: ...
+//	}
+//
+// An Interpreter keeps its global variables from one Run to the next, so a
+// program can be loaded once, its state saved and restored (see Snapshot),
+// and its code replaced while it runs (see Reload). Interpreters share
+// nothing - each has its own settings, globals and loaded modules - so
+// different Interpreters can run programs at the same time, one per
+// goroutine:
+//
+//	for _, npc := range npcs {
+//	    go func() { npc.err = interp.New(opts).Run(npc.name, npc.script) }()
+//	}
+//
+// One Interpreter runs one program at a time; its methods wait for a run
+// in progress to finish, except while an Execution is paused between slices.
+package interp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/ast"
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/elitwilson/beeflang/internal/lexer"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+)
+
+// Options configure an Interpreter. The zero value runs programs like the
+// command line does: on the process's standard streams, from ChurchOfBeef().
+type Options struct {
+	Stdout io.Writer // where io.preach writes; os.Stdout if nil
+	Stderr io.Writer // where errors of 'stampede' tasks are printed; os.Stderr if nil
+	Stdin  io.Reader // what io.input reads; os.Stdin if nil
+
+	// ModulePath lists the directories searched, in order, for modules that
+	// aren't built in. ${NAME} in a directory is replaced by that environment
+	// variable.
+	ModulePath []string
+
+	Args       []string // the program's os.args; the first is conventionally its name
+	EntryPoint string   // the function programs start in; ChurchOfBeef if empty
+	Script     bool     // run only the top-level statements, with no entry point
+	Strict     bool     // strict mode, as --strict
+
+	// Location is the time zone the time module reads and writes dates in;
+	// the machine's local time zone if nil.
+	Location *time.Location
+
+	// Capabilities, all off unless set: what programs may reach outside the
+	// interpreter. A builtin used without its capability fails with a
+	// "capability denied" error (BE0021).
+	AllowFS       bool // the fs module, as --allow-fs
+	AllowNet      bool // the net and http modules, as --allow-net
+	AllowProcess  bool // the process module, as --allow-process
+	AllowEnv      bool // os.getenv and os.expand, as --allow-env
+	AllowEval     bool // meta.eval, as --allow-eval
+	AllowParallel bool // the parallel module, as --allow-parallel
+
+	// Conditions are what '#when' directives test, as --define sets them.
+	// platform is the operating system unless it is set here.
+	Conditions map[string]string
+
+	// Stats counts what each Run or Reload costs; see Interpreter.Stats.
+	Stats bool
+}
+
+// Interpreter runs Beeflang programs with a fixed set of Options, in one
+// global environment.
+type Interpreter struct {
+	opts Options
+	rt   *evaluator.Runtime // its settings, and the modules its programs have wrangled
+	env  *object.Environment
+
+	runMu   sync.Mutex // held for a run, so that runs take turns
+	mu      sync.Mutex
+	running bool  // whether one of its calls holds runMu
+	stats   Stats // of the last run, with Options.Stats
+}
+
+// New returns an interpreter using opts, with no globals yet.
+func New(opts Options) *Interpreter {
+	rt := evaluator.NewRuntime()
+	if opts.Stdin != nil {
+		rt.Stdin = opts.Stdin
+	}
+	if opts.Stdout != nil {
+		rt.Stdout = opts.Stdout
+	}
+	if opts.Stderr != nil {
+		rt.Stderr = opts.Stderr
+	}
+	rt.SearchPath = opts.ModulePath
+	rt.Args = opts.Args
+	if opts.EntryPoint != "" {
+		rt.EntryPoint = opts.EntryPoint
+	}
+	rt.Strict = opts.Strict
+	rt.Location = opts.Location
+	rt.Allowed = evaluator.Capabilities{Filesystem: opts.AllowFS, Network: opts.AllowNet,
+		Process: opts.AllowProcess, Env: opts.AllowEnv, Eval: opts.AllowEval, Parallel: opts.AllowParallel}
+	for name, value := range opts.Conditions {
+		rt.Conditions[name] = value
+	}
+	rt.CollectStats = opts.Stats
+	return &Interpreter{opts: opts, rt: rt, env: rt.NewEnvironment()}
+}
+
+// exec runs f as a run of this interpreter, waiting for its other runs to
+// finish. If a run is in progress - a game loop, with Reload called from
+// another goroutine - f runs alongside it instead (between the slices of an
+// Execution, without using them up).
+func (in *Interpreter) exec(f func() object.Object) object.Object {
+	in.mu.Lock()
+	if in.running {
+		defer in.mu.Unlock()
+		return in.rt.Unsliced(f)
+	}
+	in.mu.Unlock()
+
+	in.runMu.Lock()
+	defer in.runMu.Unlock()
+	in.setRunning(true)
+	in.rt.ResetStats()
+	in.rt.ResetInterrupt()
+	start := time.Now()
+	defer in.finish(start)
+	return f()
+}
+
+func (in *Interpreter) setRunning(running bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.running = running
+}
+
+// finish ends a run that started at start, recording its Stats if asked to.
+func (in *Interpreter) finish(start time.Time) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.running = false
+	if in.opts.Stats {
+		in.stats = newStats(in.rt.ReadStats(), time.Since(start))
+	}
+}
+
+// Error is an error that stopped a program, at a position in its source.
+type Error struct {
+	File    string
+	Line    int // 0 when the error has no position
+	Column  int
+	Code    string // BE0003 and so on; see `beeflang explain`
+	Message string
+}
+
+// Error formats the error like the command line's diagnostics:
+// "file:line:col: error[CODE]: message".
+func (e *Error) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	}
+	return fmt.Sprintf("%s: error[%s]: %s", location, e.Code, e.Message)
+}
+
+// ErrorList is every syntax error in a program that failed to parse.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	messages := make([]string, len(l))
+	for i, err := range l {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// ExitError reports a program that called os.exit with a non-zero status.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// RunFile reads and runs the program in a file.
+func (in *Interpreter) RunFile(path string) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return in.Run(path, string(source))
+}
+
+// Run parses and runs a program, naming it name in errors. Its top-level
+// declarations join the interpreter's globals. It returns nil if
+// the program finished (os.exit(0) included), an ErrorList if it didn't parse,
+// an *ExitError for os.exit with another status, or the *Error that stopped it.
+func (in *Interpreter) Run(name, source string) error {
+	program, err := in.parse(name, source)
+	if err != nil {
+		return err
+	}
+
+	return runError(name, in.exec(func() object.Object {
+		result := evaluator.EvalFiles(in.env, []evaluator.SourceFile{{Name: name, Program: program}})
+		if _, failed := result.(*object.Error); !failed && !in.opts.Script {
+			result = evaluator.CallEntryPoint(in.env)
+		}
+		return result
+	}))
+}
+
+// ReloadFile reads a program's file again and reloads it (see Reload).
+func (in *Interpreter) ReloadFile(path string) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return in.Reload(path, string(source))
+}
+
+// Reload applies an edited version of a program that has already run,
+// keeping its state: functions are redefined from the new source, so code
+// that calls them - a game loop running in another goroutine, or the next
+// Run - gets the new behavior, while variables keep their current values.
+// Only variables new to the source are initialized, and no entry point is
+// called. A source with syntax errors changes nothing.
+func (in *Interpreter) Reload(name, source string) error {
+	program, err := in.parse(name, source)
+	if err != nil {
+		return err
+	}
+
+	return runError(name, in.exec(func() object.Object {
+		return evaluator.Reload(in.env, []evaluator.SourceFile{{Name: name, Program: program}})
+	}))
+}
+
+// parse parses a program, returning its syntax errors as an ErrorList.
+func (in *Interpreter) parse(name, source string) (*ast.Program, error) {
+	l := lexer.New(source)
+	l.SetConditions(in.rt.Conditions)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if errs := p.ParseErrors(); len(errs) > 0 {
+		return nil, errorList(name, errs)
+	}
+	return program, nil
+}
+
+// errorList converts the syntax errors in a file called name to an ErrorList.
+func errorList(name string, errs []parser.ParseError) ErrorList {
+	list := make(ErrorList, len(errs))
+	for i, err := range errs {
+		list[i] = &Error{File: name, Line: err.Line, Column: err.Column, Code: err.Code, Message: err.Message}
+	}
+	return list
+}
+
+// runError converts the result of running a program to the error Run
+// returns for it.
+func runError(name string, result object.Object) error {
+	errObj, failed := result.(*object.Error)
+	if !failed {
+		return nil
+	}
+	if code, ok := evaluator.ExitCode(errObj); ok {
+		if code == 0 {
+			return nil
+		}
+		return &ExitError{Code: code}
+	}
+	file := errObj.File
+	if file == "" {
+		file = name
+	}
+	return &Error{File: file, Line: errObj.Line, Column: errObj.Column, Code: errObj.Code, Message: errObj.Message}
+}
@@ -0,0 +1,161 @@
+package interp_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elitwilson/beeflang/interp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrorsAreAnErrorList(t *testing.T) {
+	err := interp.New(interp.Options{}).Run("broken.beef", "prep = 5\n")
+
+	var list interp.ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("expected an ErrorList, got %v", err)
+	}
+	assert.Equal(t, "broken.beef", list[0].File)
+	assert.Equal(t, 1, list[0].Line)
+	assert.Contains(t, err.Error(), "broken.beef:1:")
+}
+
+func TestExitStatus(t *testing.T) {
+	run := func(code string) error {
+		source := "wrangle os\npraise ChurchOfBeef():\n   os.exit(" + code + ")\nbeef\n"
+		return interp.New(interp.Options{}).Run("exit.beef", source)
+	}
+
+	assert.NoError(t, run("0"))
+	var exit *interp.ExitError
+	if !errors.As(run("4"), &exit) {
+		t.Fatal("expected an ExitError")
+	}
+	assert.Equal(t, 4, exit.Code)
+}
+
+func TestScriptAndEntryPointOptions(t *testing.T) {
+	source := "wrangle io\nio.preach(\"top\")\npraise ChurchOfBeef():\n   io.preach(\"church\")\nbeef\npraise smoke():\n   io.preach(\"smoke\")\nbeef\n"
+	run := func(opts interp.Options) string {
+		var out bytes.Buffer
+		opts.Stdout = &out
+		assert.NoError(t, interp.New(opts).Run("bbq.beef", source))
+		return out.String()
+	}
+
+	assert.Equal(t, "top\nchurch\n", run(interp.Options{}))
+	assert.Equal(t, "top\n", run(interp.Options{Script: true}))
+	assert.Equal(t, "top\nsmoke\n", run(interp.Options{EntryPoint: "smoke"}))
+}
+
+func TestMissingEntryPoint(t *testing.T) {
+	err := interp.New(interp.Options{}).Run("empty.beef", "prep x = 1\n")
+
+	var runErr *interp.Error
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected an Error, got %v", err)
+	}
+	assert.Equal(t, "BE0201", runErr.Code)
+	assert.Equal(t, "empty.beef: error[BE0201]: no ChurchOfBeef() entry point function found", err.Error())
+}
+
+func TestWrongNumberOfArguments(t *testing.T) {
+	source := "praise add(a, b):\n   serve a + b\nbeef\npraise ChurchOfBeef():\n   prep x = 5 |> add\nbeef\n"
+	err := interp.New(interp.Options{}).Run("add.beef", source)
+
+	var runErr *interp.Error
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected an Error, got %v", err)
+	}
+	assert.Equal(t, "add.beef:5:15: error[BE0035]: add() takes 2 arguments, got 1", err.Error())
+}
+
+func TestCapabilityOptions(t *testing.T) {
+	t.Setenv("BEEF_CUT", "brisket")
+	source := "wrangle io\nwrangle os\nio.preach(os.getenv(\"BEEF_CUT\"))\n"
+
+	var out bytes.Buffer
+	assert.NoError(t, interp.New(interp.Options{Stdout: &out, Script: true, AllowEnv: true}).Run("env.beef", source))
+	assert.Equal(t, "brisket\n", out.String())
+
+	var denied *interp.Error
+	err := interp.New(interp.Options{Script: true, AllowFS: true, AllowNet: true, AllowProcess: true}).Run("env.beef", source)
+	if !errors.As(err, &denied) {
+		t.Fatalf("expected an Error, got %v", err)
+	}
+	assert.Equal(t, "BE0021", denied.Code)
+}
+
+func TestInterpretersRunConcurrently(t *testing.T) {
+	dir := t.TempDir()
+	module := "praise greet(name):\n   serve \"hi \" + name\nbeef\n"
+	if err := os.WriteFile(filepath.Join(dir, "npc.beef"), []byte(module), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source := `wrangle io
+wrangle os
+wrangle sync
+wrangle npc
+prep total = 0
+prep i = 0
+feast while i < 100:
+   total = total + i
+   i = i + 1
+beef
+prep wg = sync.waitgroup()
+wg.add(1)
+praise fail():
+   wg.done()
+   missing()
+beef
+stampede fail()
+wg.wait()
+io.preach(npc.greet(os.args[1]), total)
+`
+
+	// Run with go test -race: each interpreter has its own streams, settings
+	// and modules, and none of them may touch another's
+	const count = 8
+	outputs := make([]*interp.Output, count)
+	errs := make([]error, count)
+	var wg sync.WaitGroup
+	for i := range count {
+		name := fmt.Sprintf("npc%d", i)
+		in := interp.New(interp.Options{Script: true, ModulePath: []string{dir}, Args: []string{"npc.beef", name}, Strict: i%2 == 0})
+		outputs[i] = in.CaptureOutput()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = in.Run(name+".beef", source)
+		}()
+	}
+	wg.Wait()
+
+	for i, out := range outputs {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, fmt.Sprintf("hi npc%d\n4950\n", i), out.Stdout())
+		assert.Eventually(t, func() bool {
+			return strings.Contains(out.Stderr(), "identifier not found: missing")
+		}, time.Second, time.Millisecond)
+	}
+}
+
+func TestConditionsOption(t *testing.T) {
+	source := "wrangle io\n#when debug\nio.preach(\"debug\")\n#else\nio.preach(\"release\")\n#end\n#when platform == \"plan9\"\nio.preach(\"plan9\")\n#end\n"
+	run := func(conditions map[string]string) string {
+		var out bytes.Buffer
+		in := interp.New(interp.Options{Stdout: &out, Script: true, Conditions: conditions})
+		assert.NoError(t, in.Run("when.beef", source))
+		return out.String()
+	}
+
+	assert.Equal(t, "release\n", run(nil))
+	assert.Equal(t, "debug\nplan9\n", run(map[string]string{"debug": "true", "platform": "plan9"}))
+}
@@ -0,0 +1,103 @@
+package interp
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// Output is what an interpreter's programs have written, collected by
+// CaptureOutput. It is safe to read while a program runs, and for tasks
+// started with 'stampede' to write to at once.
+type Output struct {
+	mu     sync.Mutex
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+	onLine func(stream, line string)
+	// partial holds the unfinished last line of each stream, for onLine
+	partial map[string]string
+}
+
+// CaptureOutput sends the standard output and error of the interpreter's
+// programs to an Output instead of Options.Stdout and Options.Stderr, so a
+// test can check what a script printed:
+//
+//	in := interp.New(interp.Options{Script: true})
+//	out := in.CaptureOutput()
+//	in.Run("greet.beef", `wrangle io
+//	io.preach("hi")`)
+//	out.Stdout() // "hi\n"
+//
+// Call it before running anything.
+func (in *Interpreter) CaptureOutput() *Output {
+	out := &Output{partial: map[string]string{}}
+	in.opts.Stdout = streamWriter{out, "stdout"}
+	in.opts.Stderr = streamWriter{out, "stderr"}
+	in.rt.Stdout, in.rt.Stderr = in.opts.Stdout, in.opts.Stderr
+	return out
+}
+
+// Stdout returns everything written to standard output so far.
+func (o *Output) Stdout() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stdout.String()
+}
+
+// Stderr returns everything written to standard error so far.
+func (o *Output) Stderr() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stderr.String()
+}
+
+// OnLine calls fn with each complete line written from now on, without its
+// newline. stream is "stdout" or "stderr". Lines are still collected for
+// Stdout and Stderr too. fn is called while a program runs, so it shouldn't
+// run Beeflang code itself.
+func (o *Output) OnLine(fn func(stream, line string)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.onLine = fn
+}
+
+// Reset forgets what has been written so far.
+func (o *Output) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.stdout.Reset()
+	o.stderr.Reset()
+	clear(o.partial)
+}
+
+func (o *Output) write(stream string, p []byte) {
+	o.mu.Lock()
+	if stream == "stderr" {
+		o.stderr.Write(p)
+	} else {
+		o.stdout.Write(p)
+	}
+	onLine := o.onLine
+	var lines []string
+	if onLine != nil {
+		lines = strings.Split(o.partial[stream]+string(p), "\n")
+		o.partial[stream] = lines[len(lines)-1]
+		lines = lines[:len(lines)-1]
+	}
+	o.mu.Unlock()
+
+	for _, line := range lines {
+		onLine(stream, line)
+	}
+}
+
+// streamWriter writes to one stream of an Output.
+type streamWriter struct {
+	out    *Output
+	stream string
+}
+
+func (w streamWriter) Write(p []byte) (int, error) {
+	w.out.write(w.stream, p)
+	return len(p), nil
+}
@@ -0,0 +1,43 @@
+package interp_test
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/interp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureOutput(t *testing.T) {
+	in := interp.New(interp.Options{Script: true})
+	out := in.CaptureOutput()
+
+	assert.NoError(t, in.Run("greet.beef", "wrangle io\nio.preach(\"hi\")\nio.preach(\"there\")\n"))
+	assert.Equal(t, "hi\nthere\n", out.Stdout())
+	assert.Equal(t, "", out.Stderr())
+
+	out.Reset()
+	assert.Equal(t, "", out.Stdout())
+	assert.Equal(t, "", out.Stderr())
+}
+
+func TestCaptureOutputLines(t *testing.T) {
+	in := interp.New(interp.Options{Script: true})
+	out := in.CaptureOutput()
+	var lines []string
+	out.OnLine(func(stream, line string) {
+		lines = append(lines, stream+": "+line)
+	})
+
+	// term.write doesn't end the line, so the callback waits for the rest
+	source := `wrangle io
+wrangle term
+term.write("cooking ")
+term.write("brisket")
+io.preach("")
+io.preach("done")
+term.write("no newline")
+`
+	assert.NoError(t, in.Run("cook.beef", source))
+	assert.Equal(t, []string{"stdout: cooking brisket", "stdout: done"}, lines)
+	assert.Equal(t, "cooking brisket\ndone\nno newline", out.Stdout())
+}
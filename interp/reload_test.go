@@ -0,0 +1,47 @@
+package interp_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/elitwilson/beeflang/interp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadKeepsState(t *testing.T) {
+	var out bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &out, Script: true})
+	script(t, in, &out, "wrangle io\nprep hp = 10\npraise report():\n   io.preach(hp)\nbeef\n")
+	script(t, in, &out, "hp = 4\n")
+
+	assert.NoError(t, in.Reload("game.beef", "wrangle io\nprep hp = 10\nprep name = \"Bubba\"\npraise report():\n   io.preach(name, hp)\nbeef\n"))
+	assert.Equal(t, "Bubba\n4\n", script(t, in, &out, "report()\n"))
+}
+
+func TestReloadWithSyntaxErrorsChangesNothing(t *testing.T) {
+	var out bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &out, Script: true})
+	script(t, in, &out, "wrangle io\npraise report():\n   io.preach(\"old\")\nbeef\n")
+
+	err := in.Reload("game.beef", "praise report(:\nbeef\n")
+	assert.IsType(t, interp.ErrorList{}, err)
+	assert.Equal(t, "old\n", script(t, in, &out, "report()\n"))
+}
+
+func TestReloadWhileRunning(t *testing.T) {
+	in := interp.New(interp.Options{})
+	done := make(chan error)
+	go func() {
+		done <- in.Run("loop.beef", "wrangle time\npraise running():\n   serve true\nbeef\npraise ChurchOfBeef():\n   feast while running():\n      time.sleep(1)\n   beef\nbeef\n")
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, in.Reload("loop.beef", "praise running():\n   serve false\nbeef\n"))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the running program never saw the reloaded function")
+	}
+}
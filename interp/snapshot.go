@@ -0,0 +1,247 @@
+package interp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// snapshotFormat is bumped whenever the encoding of snapshots changes.
+const snapshotFormat = 1
+
+// Snapshot is a copy of an interpreter's global variables, for save games and
+// for carrying state across a reload. Arrays and hashes are copied, so later
+// changes to the interpreter don't reach the snapshot, and functions keep
+// their closures. Modules, channels and other live values are shared with the
+// interpreter rather than copied.
+type Snapshot struct {
+	env *object.Environment
+}
+
+// Snapshot copies the interpreter's globals. It can be called while a
+// program runs, from another goroutine.
+func (in *Interpreter) Snapshot() *Snapshot {
+	return &Snapshot{env: in.env.Clone()}
+}
+
+// Restore sets the interpreter's globals to the values in s. Globals the
+// snapshot doesn't have keep their values, so a snapshot decoded from a save
+// file - which holds data but no functions - can be restored into an
+// interpreter that has just run the program again. s can be restored again
+// later.
+func (in *Interpreter) Restore(s *Snapshot) {
+	s.env.CloneInto(in.env)
+}
+
+// encodedSnapshot is the JSON form of a snapshot.
+type encodedSnapshot struct {
+	Format  int                     `json:"format"`
+	Globals map[string]encodedValue `json:"globals"`
+}
+
+// encodedValue is one value in a snapshot. Exactly one field is set.
+type encodedValue struct {
+	Int      *int64          `json:"int,omitempty"`
+	BigInt   *big.Int        `json:"bigint,omitempty"`
+	Bool     *bool           `json:"bool,omitempty"`
+	String   *string         `json:"string,omitempty"`
+	Null     bool            `json:"null,omitempty"`
+	Vector   *[]int64        `json:"vector,omitempty"`
+	Bytes    *[]byte         `json:"bytes,omitempty"`
+	Duration *int64          `json:"duration,omitempty"` // in nanoseconds
+	Array    *[]encodedValue `json:"array,omitempty"`
+	Hash     *[]encodedPair  `json:"hash,omitempty"`
+	Grid     *encodedGrid    `json:"grid,omitempty"`
+}
+
+type encodedGrid struct {
+	Width  int            `json:"width"`
+	Height int            `json:"height"`
+	Cells  []encodedValue `json:"cells"`
+}
+
+type encodedPair struct {
+	Key   encodedValue `json:"key"`
+	Value encodedValue `json:"value"`
+}
+
+// Encode writes the snapshot's data as JSON: every global holding an integer,
+// big integer, boolean, string, null, vector, bytes, duration, or an array,
+// hash or grid of those.
+// Globals holding anything else - functions, modules, channels - are left
+// out; running the program again brings the functions back. So are globals
+// holding an array, hash or grid that contains itself, which JSON can't show.
+func (s *Snapshot) Encode(w io.Writer) error {
+	encoded := encodedSnapshot{Format: snapshotFormat, Globals: map[string]encodedValue{}}
+	for name, val := range s.env.Bindings() {
+		if value, ok := encodeValue(val, map[object.Object]bool{}); ok {
+			encoded.Globals[name] = value
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(encoded)
+}
+
+// DecodeSnapshot reads a snapshot written by Encode.
+func DecodeSnapshot(r io.Reader) (*Snapshot, error) {
+	var encoded encodedSnapshot
+	if err := json.NewDecoder(r).Decode(&encoded); err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+	if encoded.Format != snapshotFormat {
+		return nil, fmt.Errorf("reading snapshot: unsupported format %d", encoded.Format)
+	}
+	env := object.NewEnvironment()
+	for name, value := range encoded.Globals {
+		val, err := decodeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("reading snapshot: %s: %w", name, err)
+		}
+		env.Set(name, val)
+	}
+	return &Snapshot{env: env}, nil
+}
+
+// encodeValue converts a value to its JSON form, if it has one. open holds
+// the arrays, hashes and grids being encoded around val, so one inside
+// itself is found rather than followed forever.
+func encodeValue(val object.Object, open map[object.Object]bool) (encodedValue, bool) {
+	switch val.(type) {
+	case *object.Array, *object.Hash, *object.Grid:
+		if open[val] {
+			return encodedValue{}, false
+		}
+		open[val] = true
+		defer delete(open, val)
+	}
+
+	switch val := val.(type) {
+	case *object.Integer:
+		return encodedValue{Int: &val.Value}, true
+	case *object.BigInteger:
+		return encodedValue{BigInt: val.Value}, true
+	case *object.Boolean:
+		return encodedValue{Bool: &val.Value}, true
+	case *object.String:
+		return encodedValue{String: &val.Value}, true
+	case *object.Null:
+		return encodedValue{Null: true}, true
+	case *object.Vector:
+		return encodedValue{Vector: &val.Components}, true
+	case *object.Bytes:
+		return encodedValue{Bytes: &val.Value}, true
+	case *object.Duration:
+		ns := int64(val.Value)
+		return encodedValue{Duration: &ns}, true
+	case *object.Array:
+		values := val.Elements()
+		elements := make([]encodedValue, len(values))
+		for i, el := range values {
+			encoded, ok := encodeValue(el, open)
+			if !ok {
+				return encodedValue{}, false
+			}
+			elements[i] = encoded
+		}
+		return encodedValue{Array: &elements}, true
+	case *object.Hash:
+		pairs := make([]encodedPair, 0, val.Len())
+		for _, pair := range val.Pairs() {
+			key, _ := encodeValue(pair.Key, open)
+			value, ok := encodeValue(pair.Value, open)
+			if !ok {
+				return encodedValue{}, false
+			}
+			pairs = append(pairs, encodedPair{Key: key, Value: value})
+		}
+		return encodedValue{Hash: &pairs}, true
+	case *object.Grid:
+		values := val.Cells()
+		cells := make([]encodedValue, len(values))
+		for i, cell := range values {
+			encoded, ok := encodeValue(cell, open)
+			if !ok {
+				return encodedValue{}, false
+			}
+			cells[i] = encoded
+		}
+		return encodedValue{Grid: &encodedGrid{Width: val.Width, Height: val.Height, Cells: cells}}, true
+	}
+	return encodedValue{}, false
+}
+
+// decodeValue converts a value's JSON form back to the value.
+func decodeValue(value encodedValue) (object.Object, error) {
+	switch {
+	case value.Int != nil:
+		return &object.Integer{Value: *value.Int}, nil
+	case value.BigInt != nil:
+		return &object.BigInteger{Value: value.BigInt}, nil
+	case value.Bool != nil:
+		if *value.Bool {
+			return object.TRUE, nil
+		}
+		return object.FALSE, nil
+	case value.String != nil:
+		return &object.String{Value: *value.String}, nil
+	case value.Null:
+		return object.NULL, nil
+	case value.Vector != nil:
+		return &object.Vector{Components: *value.Vector}, nil
+	case value.Bytes != nil:
+		return &object.Bytes{Value: *value.Bytes}, nil
+	case value.Duration != nil:
+		return &object.Duration{Value: time.Duration(*value.Duration)}, nil
+	case value.Array != nil:
+		elements := make([]object.Object, len(*value.Array))
+		for i, el := range *value.Array {
+			decoded, err := decodeValue(el)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = decoded
+		}
+		return object.NewArray(elements), nil
+	case value.Hash != nil:
+		hash := object.NewHash()
+		for _, pair := range *value.Hash {
+			key, err := decodeValue(pair.Key)
+			if err != nil {
+				return nil, err
+			}
+			hashable, ok := key.(object.Hashable)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a valid hash key", key.Type())
+			}
+			val, err := decodeValue(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			hash.Set(hashable, val)
+		}
+		return hash, nil
+	case value.Grid != nil:
+		grid := value.Grid
+		if grid.Width <= 0 || grid.Height <= 0 || grid.Width > object.MaxGridCells/grid.Height {
+			return nil, fmt.Errorf("can't make a %dx%d grid", grid.Width, grid.Height)
+		}
+		if len(grid.Cells) != grid.Width*grid.Height {
+			return nil, fmt.Errorf("grid of %d cells is not %dx%d", len(grid.Cells), grid.Width, grid.Height)
+		}
+		decoded := object.NewGrid(grid.Width, grid.Height, object.NULL)
+		for i, cell := range grid.Cells {
+			value, err := decodeValue(cell)
+			if err != nil {
+				return nil, err
+			}
+			decoded.SetAt(int64(i%grid.Width), int64(i/grid.Width), value)
+		}
+		return decoded, nil
+	}
+	return nil, fmt.Errorf("value with no type")
+}
@@ -0,0 +1,108 @@
+package interp_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/elitwilson/beeflang/interp"
+	"github.com/stretchr/testify/assert"
+)
+
+const game = `wrangle io
+prep hp = 10
+prep inventory = ["brisket"]
+prep stats = {"level": 1, "name": "Bubba"}
+
+praise report():
+   io.preach(hp, inventory, stats)
+beef
+`
+
+// script runs source in interpreter in script mode and returns its output.
+func script(t *testing.T, in *interp.Interpreter, out *bytes.Buffer, source string) string {
+	out.Reset()
+	assert.NoError(t, in.Run("console.beef", source))
+	return out.String()
+}
+
+func newGame(t *testing.T) (*interp.Interpreter, *bytes.Buffer) {
+	var out bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &out, Script: true})
+	script(t, in, &out, game)
+	return in, &out
+}
+
+func TestSnapshotAndRestore(t *testing.T) {
+	in, out := newGame(t)
+	saved := in.Snapshot()
+
+	script(t, in, out, "hp = 3\ninventory = []\n")
+	assert.Equal(t, "3\n[]\n{\"level\": 1, \"name\": \"Bubba\"}\n", script(t, in, out, "report()\n"))
+
+	in.Restore(saved)
+	assert.Equal(t, "10\n[\"brisket\"]\n{\"level\": 1, \"name\": \"Bubba\"}\n", script(t, in, out, "report()\n"))
+
+	// Restored functions see the interpreter's globals, not the snapshot's
+	script(t, in, out, "hp = 6\n")
+	assert.Equal(t, "6\n", script(t, in, out, "io.preach(hp)\n"))
+	assert.True(t, strings.HasPrefix(script(t, in, out, "report()\n"), "6\n"))
+	in.Restore(saved)
+	assert.Equal(t, "10\n", script(t, in, out, "io.preach(hp)\n"))
+}
+
+func TestEncodedSnapshotRestoresIntoAFreshInterpreter(t *testing.T) {
+	in, out := newGame(t)
+	script(t, in, out, "wrangle vec\nhp = 3\nstats = {\"level\": 2, true: inventory[9], \"gold\": 99999999999999999999n, \"pos\": vec.new(4, -1)}\nwrangle grid\nmap = grid.new(2, 1, \"floor\")\nmap.set(1, 0, [3])\nwrangle bytes\nsave = bytes.from_hex(\"00ff\")\nwrangle time\ncooldown = time.duration(\"1.5s\")\n")
+
+	var saved bytes.Buffer
+	assert.NoError(t, in.Snapshot().Encode(&saved))
+	assert.NotContains(t, saved.String(), "report", "functions aren't saved")
+
+	loaded, err := interp.DecodeSnapshot(&saved)
+	assert.NoError(t, err)
+	fresh, freshOut := newGame(t)
+	fresh.Restore(loaded)
+	assert.Equal(t, "3\n[\"brisket\"]\n{\"level\": 2, true: null, \"gold\": 99999999999999999999, \"pos\": vec(4, -1)}\n", script(t, fresh, freshOut, "report()\n"))
+	assert.Equal(t, "floor\n[3]\n<bytes 00 ff>\n1.5s\n", script(t, fresh, freshOut, "io.preach(map.get(0, 0), map.get(1, 0), save, cooldown)\n"))
+}
+
+func TestEncodeLeavesOutValuesInsideThemselves(t *testing.T) {
+	in, out := newGame(t)
+	script(t, in, out, "wrangle array\nprep loop = []\narray.push(loop, loop)\nprep list = []\nprep owner = {\"list\": list}\narray.push(list, owner)\nprep twice = [inventory, inventory]\n")
+
+	var saved bytes.Buffer
+	assert.NoError(t, in.Snapshot().Encode(&saved))
+	assert.NotContains(t, saved.String(), `"loop"`)
+	assert.NotContains(t, saved.String(), `"owner"`)
+	assert.NotContains(t, saved.String(), `"list"`)
+
+	// An array reached twice, but not inside itself, is saved
+	loaded, err := interp.DecodeSnapshot(&saved)
+	assert.NoError(t, err)
+	fresh, freshOut := newGame(t)
+	fresh.Restore(loaded)
+	assert.Equal(t, "[[\"brisket\"], [\"brisket\"]]\n", script(t, fresh, freshOut, "io.preach(twice)\n"))
+}
+
+func TestDecodeSnapshotRejectsOtherFormats(t *testing.T) {
+	_, err := interp.DecodeSnapshot(strings.NewReader(`{"format": 99, "globals": {}}`))
+	assert.ErrorContains(t, err, "unsupported format 99")
+}
+
+func TestDecodeSnapshotRejectsBadGrids(t *testing.T) {
+	tests := []struct {
+		grid     string
+		expected string
+	}{
+		// 2^32 * 2^32 wraps to 0 cells
+		{`{"width": 4294967296, "height": 4294967296, "cells": []}`, "can't make a 4294967296x4294967296 grid"},
+		{`{"width": 0, "height": 3, "cells": []}`, "can't make a 0x3 grid"},
+		{`{"width": 2, "height": 2, "cells": [{"int": 1}]}`, "grid of 1 cells is not 2x2"},
+	}
+
+	for _, tt := range tests {
+		_, err := interp.DecodeSnapshot(strings.NewReader(`{"format": 1, "globals": {"map": {"grid": ` + tt.grid + `}}}`))
+		assert.ErrorContains(t, err, tt.expected, tt.grid)
+	}
+}
@@ -0,0 +1,36 @@
+package interp
+
+import (
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/evaluator"
+)
+
+// Stats are what a run cost, for budgeting scripts - how much AI code a game
+// can afford per frame, say. Counts are of the interpreter's own work, so they
+// are the same from one machine to the next; only the times vary.
+type Stats struct {
+	Duration     time.Duration // wall-clock time of the run
+	Steps        int64         // AST nodes evaluated
+	Allocations  int64         // values created by literals, operators and declarations, plus one scope per function call
+	PeakEnvDepth int           // the deepest chain of nested scopes a function call ran in (the global scope is 1; 0 if nothing was called)
+
+	// ModuleTime is the time spent loading each module wrangled - parsing it
+	// and running its top-level code, modules it wrangles included - by
+	// module name. Modules loaded by an earlier run aren't loaded again.
+	ModuleTime map[string]time.Duration
+}
+
+func newStats(s evaluator.Stats, d time.Duration) Stats {
+	return Stats{Duration: d, Steps: s.Steps, Allocations: s.Allocations,
+		PeakEnvDepth: s.PeakEnvDepth, ModuleTime: s.ModuleTime}
+}
+
+// Stats returns the statistics of the interpreter's last Run or Reload, if
+// Options.Stats is set; otherwise they are all zero. A Reload made while a
+// program runs counts towards that run.
+func (in *Interpreter) Stats() Stats {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.stats
+}
@@ -0,0 +1,27 @@
+package interp_test
+
+import (
+	"testing"
+
+	"github.com/elitwilson/beeflang/interp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	in := interp.New(interp.Options{Script: true, Stats: true})
+	assert.NoError(t, in.Run("count.beef", "prep i = 0\nfeast while i < 10:\n   i = i + 1\nbeef\n"))
+	first := in.Stats()
+	assert.Positive(t, first.Steps)
+	assert.Positive(t, first.Allocations)
+	assert.Positive(t, first.Duration)
+
+	// Each run is counted on its own
+	assert.NoError(t, in.Run("count.beef", "prep j = 1\n"))
+	assert.Less(t, in.Stats().Steps, first.Steps)
+}
+
+func TestStatsAreOffByDefault(t *testing.T) {
+	in := interp.New(interp.Options{Script: true})
+	assert.NoError(t, in.Run("count.beef", "prep j = 1\n"))
+	assert.Zero(t, in.Stats())
+}
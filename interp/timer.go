@@ -0,0 +1,30 @@
+package interp
+
+import (
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/object"
+)
+
+// TimerName stands in for a file name in errors from timer functions that
+// have no file of their own, such as builtins.
+const TimerName = "<timer>"
+
+// Tick moves the clock of the program's timers (timer.after and timer.every)
+// on by elapsed and calls the functions that have come due - once a frame,
+// with the frame's length, say:
+//
+//	if err := in.Tick(delta); err != nil {
+//	    log.Println(err)
+//	}
+//
+// Timers only move when ticked, by Tick or by the program calling
+// timer.tick, so a game that stops ticking while paused pauses its scripts'
+// timers too. Like Dispatch it can be called while an Execution is paused
+// between slices. A function that fails stops the tick and Tick returns its
+// *Error.
+func (in *Interpreter) Tick(elapsed time.Duration) error {
+	return runError(TimerName, in.exec(func() object.Object {
+		return in.rt.Tick(elapsed)
+	}))
+}
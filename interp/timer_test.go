@@ -0,0 +1,50 @@
+package interp_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elitwilson/beeflang/interp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTickRunsTimers(t *testing.T) {
+	var out bytes.Buffer
+	in := interp.New(interp.Options{Stdout: &out, Script: true})
+	if err := in.Run("waves.beef", `wrangle io
+wrangle timer
+praise wave():
+   io.preach("wave")
+beef
+praise boss():
+   io.preach("boss")
+beef
+timer.every(1000, wave)
+timer.after(2500, boss)
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		assert.NoError(t, in.Tick(900*time.Millisecond))
+	}
+	assert.Equal(t, "wave\nwave\nboss\n", out.String())
+}
+
+func TestTickReportsFailingTimer(t *testing.T) {
+	in := interp.New(interp.Options{Script: true})
+	if err := in.Run("broken.beef", "wrangle timer\npraise broken():\n   serve missing\nbeef\ntimer.after(10, broken)\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := in.Tick(10 * time.Millisecond)
+	var runErr *interp.Error
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected an *interp.Error, got %v", err)
+	}
+	assert.Equal(t, "broken.beef", runErr.File)
+	assert.Equal(t, 3, runErr.Line)
+	assert.NoError(t, in.Tick(time.Second), "a timer that ran once doesn't run again")
+}
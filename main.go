@@ -1,102 +1,497 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
 
+	"github.com/elitwilson/beeflang/internal/analysis"
+	"github.com/elitwilson/beeflang/internal/astcache"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
 	"github.com/elitwilson/beeflang/internal/evaluator"
 	"github.com/elitwilson/beeflang/internal/lexer"
 	"github.com/elitwilson/beeflang/internal/object"
 	"github.com/elitwilson/beeflang/internal/parser"
-	"github.com/elitwilson/beeflang/internal/token"
+	"github.com/elitwilson/beeflang/internal/repl"
 )
 
+// pathList collects repeated --path flags. Each value may itself hold several
+// directories separated by the OS path list separator, just like BEEF_PATH.
+type pathList []string
+
+func (p *pathList) String() string {
+	return strings.Join(*p, string(os.PathListSeparator))
+}
+
+func (p *pathList) Set(value string) error {
+	*p = append(*p, filepath.SplitList(value)...)
+	return nil
+}
+
+// defines sets '#when' conditions from --define flags: name=value, or just
+// name for name=true.
+type defines lexer.Conditions
+
+func (d defines) String() string {
+	pairs := make([]string, 0, len(d))
+	for name, value := range d {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (d defines) Set(value string) error {
+	name, setTo, ok := strings.Cut(value, "=")
+	if !ok {
+		setTo = "true"
+	}
+	if name == "" {
+		return fmt.Errorf("--define needs a name, like --define debug or --define platform=windows")
+	}
+	d[name] = setTo
+	return nil
+}
+
+// newLexer returns a lexer for source that reads '#when' directives with
+// the conditions set by --define.
+func newLexer(source string) *lexer.Lexer {
+	l := lexer.New(source)
+	l.SetConditions(evaluator.Default.Conditions)
+	return l
+}
+
+// capabilityFlags defines the --allow-* flags on fs, which fill in the
+// capabilities they return.
+func capabilityFlags(fs *flag.FlagSet) *evaluator.Capabilities {
+	allowed := &evaluator.Capabilities{}
+	fs.BoolVar(&allowed.Filesystem, "allow-fs", false, "let the program read and write files through the fs module")
+	fs.BoolVar(&allowed.Network, "allow-net", false, "let the program use the network through the net and http modules")
+	fs.BoolVar(&allowed.Process, "allow-process", false, "let the program run other programs through the process module")
+	fs.BoolVar(&allowed.Env, "allow-env", false, "let the program read environment variables with os.getenv and os.expand")
+	fs.BoolVar(&allowed.Eval, "allow-eval", false, "let the program run code from strings with meta.eval")
+	fs.BoolVar(&allowed.Parallel, "allow-parallel", false, "let the program spread work over several CPUs with the parallel module")
+	return allowed
+}
+
+// scriptMode runs a program's top-level statements without requiring a
+// ChurchOfBeef() entry point. Set by --script, and always on for -e code and
+// programs read from stdin.
+var scriptMode = false
+
+// stdinName is the file name that reads the program from stdin, and
+// stdinLabel what its diagnostics call it.
+const (
+	stdinName  = "-"
+	stdinLabel = "<stdin>"
+	evalLabel  = "<-e>"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  go run . [run] [--path dir] [--watch [--hot]] [--strict] [--script] [--entry name] [--define name[=value]] [--allow-fs] [--allow-net] [--allow-process] [--allow-env] [--allow-eval] [--allow-parallel] [--stats] [--no-cache] [--no-color] <file.beef|dir>... [args]")
+	fmt.Println("  go run . [run] [flags] -e <code> [args]")
+	fmt.Println("  go run . [run] [flags] - [args]          (read the program from stdin)")
+	fmt.Println("  go run . [run] [flags]                   (run the entry file named in beef.toml)")
+	fmt.Println("  go run . repl [--path dir] [--strict] [--define name[=value]] [--allow-fs] [--allow-net] [--allow-process] [--allow-env] [--allow-eval] [--allow-parallel] [--no-color]")
+	fmt.Println("  go run . --dump-tokens [--format text|json|tsv] <file.beef>")
+	fmt.Println("  go run . --check [--define name[=value]] <file.beef|dir>...")
+	fmt.Println("  go run . check <file.beef|dir>...")
+	fmt.Println("  go run . vet <file.beef|dir>...")
+	fmt.Println("  go run . explain [code]")
+	fmt.Println("  go run . playground [--port n]")
+	fmt.Println("  go run . --version")
+	fmt.Println("  go run . doc [--format markdown|html] <file.beef|dir>...")
+	fmt.Println("  go run . highlight [--format ansi|html] [--page] <file.beef>")
+	fmt.Println("  go run . get [--name name] <git-url>@<version>")
+	fmt.Println("  go run . install [--update]")
+	fmt.Println("  go run . bundle [--path dir] [--script] [--strict] [--define name[=value]] [--allow-fs] [--allow-net] [--allow-process] [--allow-env] [--allow-eval] [--allow-parallel] [--entry name] -o <output> <file.beef|dir>...")
+	fmt.Println()
+	fmt.Println("Flags:")
+	flag.PrintDefaults()
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage:")
-		fmt.Println("  go run main.go <file.beef>")
-		fmt.Println("  go run main.go --dump-tokens <file.beef>")
+	// An executable made by `bundle` runs the program packed into it
+	if archive, err := openBundle(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
+	} else if archive != nil {
+		os.Exit(runBundle(archive))
 	}
 
-	// Check for --dump-tokens flag
-	dumpTokens := false
-	filename := os.Args[1]
+	var modulePaths pathList
+	flag.Var(&modulePaths, "path", "directory to search for wrangled modules (repeatable; searched before BEEF_PATH)")
+	dumpTokens := flag.Bool("dump-tokens", false, "print the token stream instead of running the program")
+	flag.StringVar(&tokenFormat, "format", "text", "--dump-tokens output format: text, json (JSON Lines) or tsv")
+	check := flag.Bool("check", false, "only lex and parse the given files/directories and report syntax errors")
+	watch := flag.Bool("watch", false, "re-run the program whenever it (or a module it wrangles) changes")
+	hot := flag.Bool("hot", false, "with --watch, apply edits to the running program instead of restarting it: functions are redefined and variables keep their values")
+	strict := flag.Bool("strict", false, "turn lenient behaviors (undeclared assignment, shadowing, NULL arithmetic, missing module members) into errors")
+	flag.StringVar(&diagnosticsFormat, "diagnostics", "text", "error output format: text or json (JSON Lines on stderr)")
+	allowed := capabilityFlags(flag.CommandLine)
+	flag.Var(defines(evaluator.Default.Conditions), "define", "set a condition for #when directives: name=value, or name for name=true (repeatable)")
+	flag.StringVar(&evaluator.Default.EntryPoint, "entry", evaluator.DefaultEntryPoint, "name of the function the program starts in; it gets the script's arguments if it takes parameters")
+	flag.BoolVar(&scriptMode, "script", false, "run top-level statements in order without requiring a ChurchOfBeef() entry point")
+	evalCode := flag.String("e", "", "run the given code instead of a file (implies --script)")
+	showVersion := flag.Bool("version", false, "print the interpreter's version, git commit and Go version, and exit")
+	noCache := flag.Bool("no-cache", false, "parse every wrangled module afresh instead of using (and filling) the parsed module cache")
+	showStats := flag.Bool("stats", false, "print what the program cost when it ends: time, steps evaluated, allocations, scope depth and time per module")
+	noColor := flag.Bool("no-color", false, "never color error and REPL output (also set by the NO_COLOR environment variable)")
+	flag.Usage = usage
 
-	if os.Args[1] == "--dump-tokens" {
-		if len(os.Args) < 3 {
-			fmt.Println("Error: --dump-tokens requires a filename")
+	// "run" is an optional subcommand: `beeflang run file.beef` == `beeflang file.beef`
+	args := os.Args[1:]
+	// "explain" documents error codes and takes no flags
+	if len(args) > 0 && args[0] == "explain" {
+		os.Exit(explainCode(args[1:]))
+	}
+	// "doc" has its own flags (--format)
+	if len(args) > 0 && args[0] == "doc" {
+		os.Exit(generateDocs(args[1:]))
+	}
+	// "bundle" packs a program into a standalone executable
+	if len(args) > 0 && args[0] == "bundle" {
+		os.Exit(bundleFiles(args[1:]))
+	}
+	// "get" adds a dependency to beef.toml and "install" fetches them all
+	if len(args) > 0 && args[0] == "get" {
+		os.Exit(getDependency(args[1:]))
+	}
+	if len(args) > 0 && args[0] == "install" {
+		os.Exit(installDependencies(args[1:]))
+	}
+	// "playground" serves the WebAssembly build of the interpreter
+	if len(args) > 0 && args[0] == "playground" {
+		os.Exit(servePlayground(args[1:]))
+	}
+	// "highlight" also has its own flags (--format, --page)
+	if len(args) > 0 && args[0] == "highlight" {
+		os.Exit(highlightFile(args[1:]))
+	}
+	if len(args) > 0 && args[0] == "run" {
+		args = args[1:]
+	}
+	// "vet" reports static analysis warnings and "check" type-checks;
+	// neither runs anything. "repl" starts an interactive session.
+	vet, typeCheck, interactive := false, false, false
+	if len(args) > 0 && args[0] == "vet" {
+		vet = true
+		args = args[1:]
+	} else if len(args) > 0 && args[0] == "check" {
+		typeCheck = true
+		args = args[1:]
+	} else if len(args) > 0 && args[0] == "repl" {
+		interactive = true
+		args = args[1:]
+	}
+	flag.CommandLine.Parse(args)
+
+	if *showVersion {
+		fmt.Println(versionString())
+		return
+	}
+
+	renderer.Color = !*noColor && diagnostics.ShouldColor(os.Stderr)
+
+	evaluator.Default.Allowed = *allowed
+	if !*noCache {
+		astcache.Dir = astcache.DefaultDir()
+	}
+
+	if interactive {
+		evaluator.Default.Strict = *strict
+		// Modules are looked up relative to the current directory
+		evaluator.Default.SearchPath = mustSearchPath(modulePaths, ".")
+		fmt.Println("Beeflang REPL - statements may span lines; Ctrl-D to exit")
+		repl.StartStdio(!*noColor && diagnostics.ShouldColor(os.Stdout))
+		return
+	}
+
+	// With no program named, run the project's, from its beef.toml
+	programArgs := flag.Args()
+	if len(programArgs) < 1 && *evalCode == "" {
+		entry, err := projectEntry()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if entry == "" {
+			usage()
 			os.Exit(1)
 		}
-		dumpTokens = true
-		filename = os.Args[2]
+		programArgs = []string{entry}
+	}
+	if !validDiagnosticsFormat(diagnosticsFormat) {
+		fmt.Printf("Error: unknown --diagnostics format %q (want text or json)\n", diagnosticsFormat)
+		os.Exit(1)
+	}
+	if !validTokenFormat(tokenFormat) {
+		fmt.Printf("Error: unknown --format %q (want text, json or tsv)\n", tokenFormat)
+		os.Exit(1)
+	}
+
+	if *evalCode != "" {
+		evaluator.Default.Strict = *strict
+		evaluator.Default.Args = append([]string{evalLabel}, flag.Args()...)
+		evaluator.Default.SearchPath = mustSearchPath(modulePaths, ".")
+		inlineSources[evalLabel] = *evalCode
+		scriptMode = true
+		os.Exit(runInterruptible(withStats(*showStats, func() int {
+			return runSources([]namedSource{{evalLabel, strings.NewReader(*evalCode)}})
+		})))
+	}
+
+	// Check mode: syntax-only validation, nothing is executed
+	if *check || typeCheck {
+		os.Exit(checkSyntax(programArgs, typeCheck))
+	}
+	if vet {
+		os.Exit(vetFiles(programArgs))
 	}
 
-	// Read source file
-	source, err := os.ReadFile(filename)
+	filename := programArgs[0]
+
+	// Dump tokens mode
+	if *dumpTokens {
+		os.Exit(dumpTokenStream(filename))
+	}
+
+	files, scriptArgs, err := programFiles(programArgs)
 	if err != nil {
-		fmt.Printf("Error reading file: %v\n", err)
+		reportError(filename, diagnostics.CodeUnreadableFile, err.Error())
 		os.Exit(1)
 	}
 
-	// Dump tokens mode
-	if dumpTokens {
-		l := lexer.New(string(source))
-		fmt.Printf("Tokens for %s:\n", filename)
-		fmt.Println("---")
-		for {
-			tok := l.NextToken()
-			fmt.Printf("%-15s %-10s (line %d, col %d)\n", tok.Type, tok.Literal, tok.Line, tok.Column)
-			if tok.Type == token.EOF {
-				break
-			}
-		}
+	evaluator.Default.Strict = *strict
+	// The script sees its own path and the arguments after it as os.args
+	evaluator.Default.Args = append([]string{filename}, scriptArgs...)
+
+	// Module search path: --path first, then BEEF_PATH, then the script's own
+	// directory, then the project's dependencies
+	evaluator.Default.SearchPath = mustSearchPath(modulePaths, filepath.Dir(files[0]))
+
+	if *hot && !*watch {
+		fmt.Println("Error: --hot only works with --watch")
+		os.Exit(1)
+	}
+	if *watch {
+		watchAndRun(files, *hot)
 		return
 	}
 
-	// Normal interpreter mode - run the program!
-	l := lexer.New(string(source))
-	p := parser.New(l)
-	program := p.ParseProgram()
+	os.Exit(runInterruptible(withStats(*showStats, func() int { return runFiles(files) })))
+}
+
+// programFiles splits the command line into the files of the program and the
+// arguments for the script. As with `go run`, the leading arguments naming
+// .beef files or directories are the program, and a directory stands for
+// every .beef file directly inside it, in name order. The first argument is
+// always part of the program, whatever its name.
+func programFiles(args []string) (files []string, rest []string, err error) {
+	for i, arg := range args {
+		info, statErr := os.Stat(arg)
+		isDir := statErr == nil && info.IsDir()
+		if i > 0 && !isDir && filepath.Ext(arg) != evaluator.ModuleExtension {
+			return files, args[i:], nil
+		}
+		if !isDir {
+			files = append(files, arg)
+			continue
+		}
 
-	// Check for parser errors
-	if len(p.Errors()) > 0 {
-		fmt.Println("Parser errors:")
-		for _, msg := range p.Errors() {
-			fmt.Printf("  %s\n", msg)
+		matches, globErr := filepath.Glob(filepath.Join(arg, "*"+evaluator.ModuleExtension))
+		if globErr != nil {
+			return nil, nil, globErr
+		}
+		if len(matches) == 0 {
+			return nil, nil, fmt.Errorf("no %s files in directory %s", evaluator.ModuleExtension, arg)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil, nil
+}
+
+// exitInterrupted is the exit code of a program stopped by Ctrl+C (128 + SIGINT),
+// as shells use for processes killed by the signal.
+const exitInterrupted = 130
+
+// runInterruptible runs a program with Ctrl+C handled: the first SIGINT
+// interrupts the evaluator, which unwinds the program like a runtime error and
+// reports where it stopped. A second SIGINT exits straight away, for programs
+// stuck waiting where the interrupt can't reach them.
+func runInterruptible(run func() int) int {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		evaluator.Default.Interrupt()
+		<-signals
+		evaluator.Default.RestoreTerminal()
+		os.Exit(exitInterrupted)
+	}()
+
+	code := run()
+	if evaluator.Default.Interrupted() {
+		return exitInterrupted
+	}
+	return code
+}
+
+// namedSource is program text along with the name errors should give it.
+type namedSource struct {
+	name   string
+	source io.Reader
+}
+
+// runFiles runs a program made of one or more files, or read from stdin for "-".
+// Errors are reported here; the return value is the process exit code.
+func runFiles(filenames []string) int {
+	if len(filenames) == 1 && filenames[0] == stdinName {
+		// Keep the text for error excerpts; the program can't be read twice
+		source, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			reportError(stdinLabel, diagnostics.CodeUnreadableFile, fmt.Sprintf("reading stdin: %v", err))
+			return 1
+		}
+		inlineSources[stdinLabel] = string(source)
+		scriptMode = true
+		return runSources([]namedSource{{stdinLabel, strings.NewReader(string(source))}})
+	}
+
+	// Open the source files; the lexer streams them rather than reading them all up front
+	sources := make([]namedSource, len(filenames))
+	for i, filename := range filenames {
+		file, err := os.Open(filename)
+		if err != nil {
+			reportError(filename, diagnostics.CodeUnreadableFile, fmt.Sprintf("reading file: %v", err))
+			return 1
+		}
+		defer file.Close()
+		sources[i] = namedSource{filename, file}
+	}
+	return runSources(sources)
+}
+
+// runSources parses and evaluates a program, then calls its entry point
+// (ChurchOfBeef() by default) unless in script mode. A program in several
+// sources runs as one: their top levels are evaluated in order, in a shared
+// environment.
+func runSources(sources []namedSource) int {
+	// io.getch may have left the terminal in cbreak mode
+	defer evaluator.Default.RestoreTerminal()
+
+	filename := sources[0].name
+	files := make([]evaluator.SourceFile, len(sources))
+	failed := false
+	for i, src := range sources {
+		l := lexer.NewReader(src.source)
+		l.SetConditions(evaluator.Default.Conditions)
+		p := parser.New(l)
+		program := p.ParseProgram()
+
+		if err := l.Err(); err != nil {
+			reportError(src.name, diagnostics.CodeUnreadableFile, fmt.Sprintf("reading file: %v", err))
+			return 1
+		}
+
+		// Check for parser errors, in every file before giving up
+		if len(p.ParseErrors()) > 0 {
+			reportParseErrors(src.name, p.ParseErrors())
+			failed = true
+			continue
+		}
+
+		// Warnings don't stop the program, but show them before it runs
+		// (unless --strict made one of them an error)
+		if warnings := analysis.Analyze(program); reportAnalysis && len(warnings) > 0 {
+			reportWarnings(src.name, warnings)
+			for _, w := range warnings {
+				if strictError(w) {
+					failed = true
+				}
+			}
+		}
+		files[i] = evaluator.SourceFile{Name: src.name, Program: program}
+	}
+	if failed {
+		return 1
+	}
+
+	// Tasks started with 'stampede' report their own errors; any failure
+	// still makes the run exit non-zero
+	var taskFailed atomic.Bool
+	evaluator.Default.OnTaskError = func(err *object.Error) {
+		// os.exit in a task ends the whole program, as os.Exit does in Go
+		if code, ok := evaluator.ExitCode(err); ok {
+			evaluator.Default.RestoreTerminal()
+			os.Exit(code)
+		}
+		taskFailed.Store(true)
+		// On Ctrl+C every task stops; the interrupt is reported once, below
+		if err.Code != diagnostics.CodeInterrupted {
+			reportRuntimeError(filename, err)
 		}
-		os.Exit(1)
 	}
 
 	// Evaluate the program (this loads all function/variable declarations)
 	env := object.NewEnvironment()
-	result := evaluator.Eval(program, env)
+	runningEnv.Store(env)
+	result := evaluator.EvalFiles(env, files)
 
 	// Check for errors during program evaluation
-	if result != nil && result.Type() == "ERROR" {
-		fmt.Fprintf(os.Stderr, "%s\n", result.Inspect())
-		os.Exit(1)
+	if errObj, ok := result.(*object.Error); ok {
+		return reportResult(filename, errObj)
 	}
 
-	// Auto-call ChurchOfBeef() if it exists (entry point function)
-	if entryPoint, ok := env.Get("ChurchOfBeef"); ok {
-		if fn, ok := entryPoint.(*object.Function); ok {
-			// Create new environment for ChurchOfBeef() execution
-			entryEnv := object.NewEnclosedEnvironment(fn.Env)
-			// Execute ChurchOfBeef() body
-			result := evaluator.Eval(fn.Body, entryEnv)
+	// In script mode the top-level statements were the whole program
+	if scriptMode {
+		if taskFailed.Load() {
+			return 1
+		}
+		return 0
+	}
 
-			// Check for errors during ChurchOfBeef() execution
-			if result != nil && result.Type() == "ERROR" {
-				fmt.Fprintf(os.Stderr, "%s\n", result.Inspect())
-				os.Exit(1)
-			}
-		} else {
-			fmt.Println("Error: ChurchOfBeef is not a function")
-			os.Exit(1)
+	if _, ok := env.Get(evaluator.Default.EntryPoint); !ok {
+		reportError(filename, diagnostics.CodeNoEntryPoint, fmt.Sprintf(
+			"no %s() entry point function found (run with --script to run top-level statements only)", evaluator.Default.EntryPoint))
+		return 1
+	}
+
+	// Call the entry point (ChurchOfBeef() unless --entry says otherwise);
+	// one taking parameters gets the script's arguments, as strings
+	var args []object.Object
+	if len(evaluator.Default.Args) > 1 {
+		for _, arg := range evaluator.Default.Args[1:] {
+			args = append(args, &object.String{Value: arg})
 		}
-	} else {
-		fmt.Println("Error: no ChurchOfBeef() entry point function found")
-		os.Exit(1)
 	}
+	result = evaluator.CallEntryPoint(env, args...)
+
+	// Check for errors during the entry point's execution
+	if errObj, ok := result.(*object.Error); ok {
+		return reportResult(filename, errObj)
+	}
+	if taskFailed.Load() {
+		return 1
+	}
+	return 0
+}
+
+// reportResult reports the error a program ended with and returns the exit
+// code for it. A program that called os.exit isn't reported; it exits with
+// the code it asked for.
+func reportResult(filename string, errObj *object.Error) int {
+	if code, ok := evaluator.ExitCode(errObj); ok {
+		return code
+	}
+	reportRuntimeError(filename, errObj)
+	return 1
 }
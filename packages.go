@@ -0,0 +1,167 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/elitwilson/beeflang/internal/manifest"
+)
+
+// getDependency implements `beeflang get [--name name] <git-url>@<version>`:
+// it adds a dependency to the beef.toml in the current directory (creating
+// the manifest if need be) and installs it. Returns the process exit code.
+func getDependency(args []string) int {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	name := fs.String("name", "", "what to call the dependency; the last element of the URL if empty")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: go run . get [--name name] <git-url>@<version>")
+		return 1
+	}
+
+	url, version := splitVersion(fs.Arg(0))
+	if version == "" {
+		fmt.Fprintf(os.Stderr, "Error: %s has no version: add @ and a tag or branch, like %s@v1.0.0\n", url, url)
+		return 1
+	}
+	if *name == "" {
+		*name = strings.TrimSuffix(url[strings.LastIndexAny(url, "/:")+1:], ".git")
+	}
+	dep := manifest.Dependency{Git: url, Version: version}
+
+	// Check before fetching, so a name already in use keeps its files
+	if _, err := os.Stat(manifest.FileName); err == nil {
+		m, err := manifest.Load(manifest.FileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if _, ok := m.Dependencies[*name]; ok {
+			fmt.Fprintf(os.Stderr, "Error: %s already depends on %s; edit it to change the version, then run install\n", manifest.FileName, *name)
+			return 1
+		}
+	}
+	lock, err := manifest.LoadLock(manifest.LockFileName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := manifest.Install(".", *name, dep, lock); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := manifest.AddDependency(manifest.FileName, *name, dep); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := lock.Write(manifest.LockFileName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Added %s %s to %s\n", *name, version, manifest.FileName)
+	return 0
+}
+
+// splitVersion splits "url@version" at its last @, if that comes after the
+// URL's path - git@github.com:someone/pathfinding has an @ of its own.
+func splitVersion(arg string) (string, string) {
+	at := strings.LastIndex(arg, "@")
+	if at < 0 || at < strings.LastIndexAny(arg, "/:") {
+		return arg, ""
+	}
+	return arg[:at], arg[at+1:]
+}
+
+// installDependencies implements `beeflang install [--update]`: it fetches
+// every dependency listed in the beef.toml in the current directory into
+// beef_modules, at the commits beef.lock records, and brings beef.lock up to
+// date with the manifest. --update ignores the recorded commits, to take the
+// code versions point at now. Returns the process exit code.
+func installDependencies(args []string) int {
+	fs := flag.NewFlagSet("install", flag.ContinueOnError)
+	update := fs.Bool("update", false, "fetch what each version names now, replacing the commits in beef.lock")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: go run . install [--update]")
+		return 1
+	}
+	m, err := manifest.Load(manifest.FileName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	lockPath := filepath.Join(m.Dir, manifest.LockFileName)
+	lock := &manifest.Lock{}
+	if !*update {
+		if lock, err = manifest.LoadLock(lockPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+	lock.Prune(m)
+
+	status := 0
+	for _, name := range m.DependencyNames() {
+		dep := m.Dependencies[name]
+		if err := manifest.Install(m.Dir, name, dep, lock); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			status = 1
+			continue
+		}
+		fmt.Printf("Installed %s %s (commit %.12s)\n", name, dep.Version, lock.Find(name).Commit)
+	}
+	// Dependencies that couldn't be installed keep their old entries
+	if err := lock.Write(lockPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return status
+}
+
+// searchPath is the module search path for a program in scriptDir: --path,
+// BEEF_PATH and scriptDir (see evaluator.BuildSearchPath), then the
+// dependencies of the project scriptDir is in, if it has a beef.toml. Those
+// must be installed as beef.lock records.
+func searchPath(flagPaths []string, scriptDir string) ([]string, error) {
+	dirs := evaluator.BuildSearchPath(flagPaths, os.Getenv("BEEF_PATH"), scriptDir)
+	m, err := manifest.Find(scriptDir)
+	if err != nil || m == nil {
+		return dirs, err
+	}
+	if err := m.Verify(); err != nil {
+		return nil, err
+	}
+	return append(dirs, m.ModuleDirs()...), nil
+}
+
+// mustSearchPath is searchPath for the run commands, which can't go on with
+// a broken manifest.
+func mustSearchPath(flagPaths []string, scriptDir string) []string {
+	dirs, err := searchPath(flagPaths, scriptDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return dirs
+}
+
+// projectEntry returns the program file of the project in the current
+// directory, from its beef.toml, or "" if there is no manifest naming one.
+func projectEntry() (string, error) {
+	if _, err := os.Stat(manifest.FileName); err != nil {
+		return "", nil
+	}
+	m, err := manifest.Load(manifest.FileName)
+	if err != nil || m.Entry == "" {
+		return "", err
+	}
+	return filepath.Join(m.Dir, m.Entry), nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// playgroundPage is the playground's web page; the interpreter it runs is
+// the wasm command, built when the playground starts.
+//
+//go:embed wasm/index.html
+var playgroundPage []byte
+
+// playgroundPackage is the WebAssembly build of the interpreter.
+const playgroundPackage = "github.com/elitwilson/beeflang/wasm"
+
+// servePlayground implements `beeflang playground [--port n]`. It builds the
+// interpreter for WebAssembly (which needs the Go toolchain and this module's
+// source) and serves a page on localhost where snippets run in the browser.
+// It stops on Ctrl+C. Returns the process exit code.
+func servePlayground(args []string) int {
+	fs := flag.NewFlagSet("playground", flag.ContinueOnError)
+	port := fs.Int("port", 8080, "port to serve the playground on")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	dir, err := os.MkdirTemp("", "beeflang-playground")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	fmt.Println("Building the interpreter for WebAssembly...")
+	wasmFile := filepath.Join(dir, "beeflang.wasm")
+	build := exec.Command("go", "build", "-o", wasmFile, playgroundPackage)
+	build.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	build.Stdout, build.Stderr = os.Stderr, os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: building %s: %v\n", playgroundPackage, err)
+		return 1
+	}
+	support, err := wasmExecJS()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(playgroundPage)
+	})
+	mux.HandleFunc("/wasm_exec.js", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, support)
+	})
+	mux.HandleFunc("/beeflang.wasm", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, wasmFile)
+	})
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("localhost", strconv.Itoa(*port)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	server := &http.Server{Handler: mux}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	fmt.Printf("Playground running at http://localhost:%d/ (Ctrl+C to stop)\n", listener.Addr().(*net.TCPAddr).Port)
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// wasmExecJS finds the JavaScript support file that ships with the Go
+// toolchain and loads programs built for GOOS=js.
+func wasmExecJS() (string, error) {
+	out, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		return "", fmt.Errorf("finding GOROOT: %w", err)
+	}
+	goroot := strings.TrimSpace(string(out))
+	// Go 1.24 moved it from misc/wasm to lib/wasm
+	for _, dir := range []string{"lib", "misc"} {
+		file := filepath.Join(goroot, dir, "wasm", "wasm_exec.js")
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("wasm_exec.js not found in %s", goroot)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/elitwilson/beeflang/internal/analysis"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+	"github.com/elitwilson/beeflang/internal/typecheck"
+)
+
+// diagnosticsFormat selects how errors are reported: "text" (default, for humans)
+// or "json" (JSON Lines on stderr, for editors and CI). Set from --diagnostics.
+var diagnosticsFormat = "text"
+
+// validDiagnosticsFormat reports whether --diagnostics got a supported value.
+func validDiagnosticsFormat(format string) bool {
+	return format == "text" || format == "json"
+}
+
+// renderer formats text diagnostics, with a source excerpt under each one.
+// Color is switched on in main when stderr is a terminal and neither
+// --no-color nor NO_COLOR asks otherwise.
+var renderer = diagnostics.Renderer{Source: sourceLine}
+
+// inlineSources holds programs that aren't files on disk (-e code, a script
+// piped to stdin), by the name their diagnostics use, so excerpts can still
+// be shown for them.
+var inlineSources = map[string]string{}
+
+// sourceLine returns line n of a file or inline program, for the renderer.
+func sourceLine(file string, n int) (string, bool) {
+	source, ok := inlineSources[file]
+	if !ok {
+		return diagnostics.ReadSourceLine(file, n)
+	}
+	lines := strings.Split(source, "\n")
+	if n < 1 || n > len(lines) {
+		return "", false
+	}
+	return lines[n-1], true
+}
+
+// printDiagnostics writes text diagnostics to stderr, so they never mix with
+// the program's own output.
+func printDiagnostics(diags []diagnostics.Diagnostic) {
+	for _, d := range diags {
+		fmt.Fprint(os.Stderr, renderer.Render(d))
+	}
+}
+
+// fromParseErrors converts parser errors into diagnostics for a file.
+func fromParseErrors(file string, errs []parser.ParseError) []diagnostics.Diagnostic {
+	diags := make([]diagnostics.Diagnostic, len(errs))
+	for i, err := range errs {
+		diags[i] = diagnostics.Diagnostic{
+			File:      file,
+			Line:      err.Line,
+			Column:    err.Column,
+			EndLine:   err.End.Line,
+			EndColumn: err.End.Column,
+			Severity:  diagnostics.Error,
+			Message:   err.Message,
+			Code:      err.Code,
+		}
+	}
+	return diags
+}
+
+// fromTypeErrors converts type checker errors into diagnostics for a file.
+func fromTypeErrors(file string, errs []typecheck.TypeError) []diagnostics.Diagnostic {
+	diags := make([]diagnostics.Diagnostic, len(errs))
+	for i, err := range errs {
+		diags[i] = diagnostics.Diagnostic{
+			File:      file,
+			Line:      err.Line,
+			Column:    err.Column,
+			EndLine:   err.End.Line,
+			EndColumn: err.End.Column,
+			Severity:  diagnostics.Error,
+			Message:   err.Message,
+			Code:      err.Code,
+		}
+	}
+	return diags
+}
+
+// fromWarnings converts static analysis warnings into diagnostics for a file.
+func fromWarnings(file string, warnings []analysis.Warning) []diagnostics.Diagnostic {
+	diags := make([]diagnostics.Diagnostic, len(warnings))
+	for i, w := range warnings {
+		severity := diagnostics.Warning
+		if strictError(w) {
+			severity = diagnostics.Error
+		}
+		diags[i] = diagnostics.Diagnostic{
+			File:      file,
+			Line:      w.Line,
+			Column:    w.Column,
+			EndLine:   w.End.Line,
+			EndColumn: w.End.Column,
+			Severity:  severity,
+			Message:   w.Message,
+			Code:      w.Code,
+		}
+	}
+	return diags
+}
+
+// strictError reports whether --strict turns a warning into an error that
+// stops the program from running: a name declared twice in the same block.
+func strictError(w analysis.Warning) bool {
+	return evaluator.Default.Strict && w.Code == diagnostics.CodeRedeclared
+}
+
+// fromRuntimeError converts an evaluator error into a diagnostic.
+// Errors raised inside a wrangled module already carry that module's path.
+func fromRuntimeError(file string, err *object.Error) diagnostics.Diagnostic {
+	if err.File != "" {
+		file = err.File
+	}
+	return diagnostics.Diagnostic{
+		File:      file,
+		Line:      err.Line,
+		Column:    err.Column,
+		EndLine:   err.EndLine,
+		EndColumn: err.EndColumn,
+		Severity:  diagnostics.Error,
+		Message:   err.Message,
+		Code:      err.Code,
+	}
+}
+
+// reportParseErrors prints the syntax errors found in a file.
+func reportParseErrors(file string, errs []parser.ParseError) {
+	if diagnosticsFormat == "json" {
+		diagnostics.WriteJSON(os.Stderr, fromParseErrors(file, errs))
+		return
+	}
+
+	printDiagnostics(fromParseErrors(file, errs))
+}
+
+// reportWarnings prints static analysis warnings.
+func reportWarnings(file string, warnings []analysis.Warning) {
+	if diagnosticsFormat == "json" {
+		diagnostics.WriteJSON(os.Stderr, fromWarnings(file, warnings))
+		return
+	}
+
+	printDiagnostics(fromWarnings(file, warnings))
+}
+
+// reportRuntimeError prints an error raised while evaluating a file.
+func reportRuntimeError(file string, err *object.Error) {
+	if diagnosticsFormat == "json" {
+		diagnostics.WriteJSON(os.Stderr, []diagnostics.Diagnostic{fromRuntimeError(file, err)})
+		return
+	}
+
+	printDiagnostics([]diagnostics.Diagnostic{fromRuntimeError(file, err)})
+}
+
+// reportError prints a problem that isn't tied to a source position
+// (unreadable file, missing entry point, ...).
+func reportError(file string, code string, message string) {
+	d := diagnostics.Diagnostic{
+		File:     file,
+		Severity: diagnostics.Error,
+		Message:  message,
+		Code:     code,
+	}
+	if diagnosticsFormat == "json" {
+		diagnostics.WriteJSON(os.Stderr, []diagnostics.Diagnostic{d})
+		return
+	}
+
+	printDiagnostics([]diagnostics.Diagnostic{d})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/elitwilson/beeflang/internal/token"
+)
+
+// tokenFormat selects how --dump-tokens prints the token stream: "text" (a
+// table for people), "json" (JSON Lines) or "tsv". Set from --format.
+var tokenFormat = "text"
+
+// validTokenFormat reports whether --format got a supported value.
+func validTokenFormat(format string) bool {
+	return format == "text" || format == "json" || format == "tsv"
+}
+
+// tokenRecord is one token in the structured (json and tsv) dumps. Lines and
+// columns start at 1, offsets (in bytes) at 0; the end is just past the token.
+type tokenRecord struct {
+	Type      token.TokenType `json:"type"`
+	Literal   string          `json:"literal"`
+	Line      int             `json:"line"`
+	Column    int             `json:"column"`
+	Offset    int             `json:"offset"`
+	EndLine   int             `json:"endLine"`
+	EndColumn int             `json:"endColumn"`
+	EndOffset int             `json:"endOffset"`
+}
+
+func newTokenRecord(tok token.Token) tokenRecord {
+	return tokenRecord{
+		Type:      tok.Type,
+		Literal:   tok.Literal,
+		Line:      tok.Line,
+		Column:    tok.Column,
+		Offset:    tok.Offset,
+		EndLine:   tok.End.Line,
+		EndColumn: tok.End.Column,
+		EndOffset: tok.End.Offset,
+	}
+}
+
+// tsvHeader names the columns of a tsv dump.
+const tsvHeader = "type\tliteral\tline\tcolumn\toffset\tendLine\tendColumn\tendOffset"
+
+// dumpTokenStream prints every token in a file, one per line.
+func dumpTokenStream(filename string) int {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Error reading file: %v\n", err)
+		return 1
+	}
+
+	l := newLexer(string(source))
+	if tokenFormat == "text" {
+		fmt.Printf("Tokens for %s:\n", filename)
+		fmt.Println("---")
+		for {
+			tok := l.NextToken()
+			fmt.Printf("%-15s %-10s (line %d, col %d)\n", tok.Type, tok.Literal, tok.Line, tok.Column)
+			if tok.Type == token.EOF {
+				break
+			}
+		}
+		return 0
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	if err := writeTokens(out, tokenFormat, l.AllTokens()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// writeTokens writes tokens as JSON Lines (one object per token) or as
+// tab-separated values with a header row.
+func writeTokens(w io.Writer, format string, tokens []token.Token) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		for _, tok := range tokens {
+			if err := enc.Encode(newTokenRecord(tok)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w, tsvHeader); err != nil {
+		return err
+	}
+	for _, tok := range tokens {
+		r := newTokenRecord(tok)
+		_, err := fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\n",
+			tsvField(string(r.Type)), tsvField(r.Literal), r.Line, r.Column, r.Offset, r.EndLine, r.EndColumn, r.EndOffset)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tsvField escapes a value for a tsv column: Go string escapes without the
+// quotes, so tabs, newlines and backslashes can't break up the row.
+func tsvField(s string) string {
+	quoted := strconv.Quote(s)
+	return quoted[1 : len(quoted)-1]
+}
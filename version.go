@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata, injected at build time with -ldflags, e.g.
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD)" .
+//
+// (dev.sh build does this). Without them the commit is taken from the VCS
+// information Go embeds in binaries built inside a git checkout.
+var (
+	version = "dev"
+	commit  = ""
+)
+
+// buildCommit returns the git commit the interpreter was built from, or
+// "unknown". A build from a checkout with local changes is marked "-dirty".
+func buildCommit() string {
+	if commit != "" {
+		return commit
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	revision, dirty := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			dirty = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "unknown"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if dirty {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// versionString describes this build in one line, for --version and bug reports.
+func versionString() string {
+	return fmt.Sprintf("beeflang %s (commit %s, %s %s/%s)",
+		version, buildCommit(), runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
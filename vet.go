@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/elitwilson/beeflang/internal/analysis"
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/parser"
+)
+
+// vetFiles parses every given file (directories are searched recursively) and
+// reports the static analysis warnings for each one, plus any syntax errors
+// that stopped a file from being analyzed. Nothing is executed.
+// Returns the process exit code: 0 when nothing was found, 1 otherwise.
+func vetFiles(paths []string) int {
+	files, err := collectSourceFiles(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	diags := []diagnostics.Diagnostic{}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			diags = append(diags, diagnostics.Diagnostic{
+				File:     file,
+				Severity: diagnostics.Error,
+				Message:  fmt.Sprintf("reading file: %v", err),
+				Code:     diagnostics.CodeUnreadableFile,
+			})
+			continue
+		}
+
+		p := parser.New(newLexer(string(source)))
+		program := p.ParseProgram()
+		if len(p.ParseErrors()) > 0 {
+			diags = append(diags, fromParseErrors(file, p.ParseErrors())...)
+			continue
+		}
+		diags = append(diags, fromWarnings(file, analysis.Analyze(program))...)
+	}
+
+	if diagnosticsFormat == "json" {
+		diagnostics.WriteJSON(os.Stderr, diags)
+	} else {
+		printDiagnostics(diags)
+	}
+
+	if len(diags) > 0 {
+		return 1
+	}
+	return 0
+}
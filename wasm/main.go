@@ -0,0 +1,45 @@
+//go:build js && wasm
+
+// Command wasm is the interpreter compiled to WebAssembly, for the browser
+// playground (`beeflang playground` builds and serves it). It registers a
+// global beeflang.run(source) function, which returns a promise of
+// {output, ok}; see playground.Run.
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/elitwilson/beeflang/internal/playground"
+)
+
+func main() {
+	js.Global().Set("beeflang", js.ValueOf(map[string]any{
+		"run": js.FuncOf(run),
+	}))
+	// The functions above are only callable while the program is running
+	select {}
+}
+
+// run starts a snippet and returns a promise of its result. The snippet runs
+// on a goroutine of its own: a JS callback must not block, and snippets can
+// sleep and wait on channels.
+func run(this js.Value, args []js.Value) any {
+	source := ""
+	if len(args) > 0 {
+		source = args[0].String()
+	}
+
+	executor := js.FuncOf(func(this js.Value, promise []js.Value) any {
+		resolve := promise[0]
+		go func() {
+			result := playground.Run(source)
+			resolve.Invoke(js.ValueOf(map[string]any{
+				"output": result.Output,
+				"ok":     result.OK,
+			}))
+		}()
+		return nil
+	})
+	defer executor.Release()
+	return js.Global().Get("Promise").New(executor)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/elitwilson/beeflang/internal/diagnostics"
+	"github.com/elitwilson/beeflang/internal/evaluator"
+	"github.com/elitwilson/beeflang/internal/object"
+	"github.com/elitwilson/beeflang/internal/parser"
+)
+
+// watchInterval is how often watch mode polls file modification times.
+// Polling keeps us dependency-free and works the same on every OS.
+const watchInterval = 500 * time.Millisecond
+
+// clearScreen is the ANSI sequence for "cursor home, erase display".
+const clearScreen = "\033[H\033[2J"
+
+// runningEnv is the global environment of the program running now, which
+// hot reload applies edits to.
+var runningEnv atomic.Pointer[object.Environment]
+
+// watchAndRun runs the program, then re-runs it every time one of its files or
+// any module it wrangled is saved. With hot set, edits saved while the program
+// runs are applied to it instead (see runHot); it is only re-run once it has
+// ended. It never returns; stop it with Ctrl+C.
+func watchAndRun(filenames []string, hot bool) {
+	for {
+		fmt.Print(clearScreen)
+
+		evaluator.Default.ResetModuleCache()
+		var code int
+		if hot {
+			code = runHot(filenames)
+		} else {
+			code = runFiles(filenames)
+		}
+
+		// The set of watched files can change between runs (new wrangles)
+		watched := watchedFiles(filenames)
+		fmt.Printf("\n[watch] exited with code %d - watching %d file(s) for changes...\n", code, len(watched))
+
+		waitForChange(snapshotModTimes(watched))
+	}
+}
+
+// watchedFiles returns the program's files plus every module file it loaded.
+func watchedFiles(filenames []string) []string {
+	files := make([]string, len(filenames))
+	for i, filename := range filenames {
+		files[i] = filename
+		if abs, err := filepath.Abs(filename); err == nil {
+			files[i] = abs
+		}
+	}
+	return append(files, evaluator.Default.LoadedModuleFiles()...)
+}
+
+// snapshotModTimes records the modification time of every file.
+// Missing files are recorded with a zero time, so creating them counts as a change.
+func snapshotModTimes(files []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			times[file] = info.ModTime()
+		} else {
+			times[file] = time.Time{}
+		}
+	}
+	return times
+}
+
+// waitForChange blocks until any file's modification time differs from the snapshot.
+func waitForChange(before map[string]time.Time) {
+	files := make([]string, 0, len(before))
+	for file := range before {
+		files = append(files, file)
+	}
+
+	for {
+		time.Sleep(watchInterval)
+		now := snapshotModTimes(files)
+		for _, file := range files {
+			if !now[file].Equal(before[file]) {
+				return
+			}
+		}
+	}
+}
+
+// runHot runs the program like runFiles, and every time one of its files or
+// modules is saved while it runs, reloads it in place: functions are redefined
+// and variables keep their values, so a running game picks up tweaked logic
+// without losing its state (see evaluator.Reload).
+func runHot(filenames []string) int {
+	done := make(chan int, 1)
+	go func() { done <- runFiles(filenames) }()
+
+	before := snapshotModTimes(watchedFiles(filenames))
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case code := <-done:
+			return code
+		case <-ticker.C:
+		}
+
+		// Modules wrangled since the last look are watched from now on
+		now := snapshotModTimes(watchedFiles(filenames))
+		var changed []string
+		for file, modTime := range now {
+			if previous, ok := before[file]; ok && !previous.Equal(modTime) {
+				changed = append(changed, file)
+			}
+		}
+		before = now
+		if len(changed) > 0 {
+			hotReload(filenames, changed)
+		}
+	}
+}
+
+// hotReload applies the program's current source to the running program.
+// Changed module files are loaded afresh when their wrangles run again; a
+// program file with syntax errors leaves the program as it was.
+func hotReload(filenames []string, changed []string) {
+	env := runningEnv.Load()
+	if env == nil {
+		return
+	}
+	for _, file := range changed {
+		evaluator.Default.ForgetModule(file)
+	}
+
+	files := make([]evaluator.SourceFile, len(filenames))
+	for i, filename := range filenames {
+		source, err := os.ReadFile(filename)
+		if err != nil {
+			reportError(filename, diagnostics.CodeUnreadableFile, fmt.Sprintf("reading file: %v", err))
+			return
+		}
+		p := parser.New(newLexer(string(source)))
+		program := p.ParseProgram()
+		if len(p.ParseErrors()) > 0 {
+			reportParseErrors(filename, p.ParseErrors())
+			fmt.Fprintln(os.Stderr, "[hot] not reloaded - fix the errors and save again")
+			return
+		}
+		files[i] = evaluator.SourceFile{Name: filename, Program: program}
+	}
+
+	if errObj, ok := evaluator.Reload(env, files).(*object.Error); ok {
+		reportRuntimeError(filenames[0], errObj)
+		return
+	}
+	names := make([]string, len(changed))
+	for i, file := range changed {
+		names[i] = filepath.Base(file)
+	}
+	fmt.Fprintf(os.Stderr, "[hot] reloaded %s\n", strings.Join(names, ", "))
+}